@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/database"
+	"open-news/internal/dryrun"
+	"open-news/internal/models"
+)
+
+// runBackfill imports recent posts from one or all sources through the firehose pipeline
+func runBackfill(args []string) error {
+	// Command line flags
+	flags := newFlagSet("backfill", "Walk sources' author feeds back a number of days, importing their posts like the firehose would.")
+	handle := flags.String("handle", "", "Handle or DID of the source to backfill")
+	all := flags.Bool("all", false, "Backfill every source")
+	days := flags.Int("days", 30, "How many days of posts to backfill")
+	maxPages := flags.Int("max-pages", 0, "Stop after this many pages of posts per source (0 for no limit)")
+	dryRun := flags.Bool("dry-run", false, "Report what would be imported without saving it")
+	reportPath := flags.String("report", "", "Write a JSON summary to this file, or - for stdout (default - with -dry-run)")
+	flags.Parse(args)
+	if *dryRun && *reportPath == "" {
+		*reportPath = "-"
+	}
+
+	if (*handle == "") == !*all {
+		return fmt.Errorf("specify either -handle or -all")
+	}
+	if *days <= 0 {
+		return fmt.Errorf("-days must be positive")
+	}
+
+	// Connect to database
+	if err := connectDatabase(false); err != nil {
+		return err
+	}
+	defer database.Close()
+	if *reportPath == "-" {
+		dryrun.LogToStderr(database.DB)
+	}
+
+	// getAuthorFeed requires an authenticated client
+	blueskyClient, err := newBlueskyClient(true)
+	if err != nil {
+		return err
+	}
+
+	// Load the sources to backfill
+	var sources []models.Source
+	query := database.DB.Order("handle")
+	if *handle != "" {
+		actor := strings.TrimPrefix(*handle, "@")
+		query = query.Where("handle = ? OR blue_sky_d_id = ?", actor, actor)
+	}
+	if err := query.Find(&sources).Error; err != nil {
+		return fmt.Errorf("failed to load sources: %w", err)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no source found for %q; add it as a source first", *handle)
+	}
+
+	// Stop between posts on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A dry run imports in a transaction that's rolled back at the end
+	session, err := dryrun.Start(database.DB, "backfill", *dryRun)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Println("🧪 Dry run: nothing will be saved")
+	}
+
+	// Posts go through the same pipeline as the firehose
+	consumer := bluesky.NewFirehoseConsumer(session.DB, blueskyClient)
+	options := bluesky.BackfillOptions{
+		Since:    time.Now().AddDate(0, 0, -*days),
+		MaxPages: *maxPages,
+		Delay:    200 * time.Millisecond,
+	}
+
+	log.Printf("📥 Backfilling %d days of posts from %d sources...", *days, len(sources))
+	totalShares, failed := 0, 0
+	for i := range sources {
+		result, err := consumer.Backfill(ctx, &sources[i], options)
+		if ctx.Err() != nil {
+			return fmt.Errorf("interrupted")
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to backfill %s: %v", sources[i].Handle, err)
+			session.Report.Fail(err)
+			failed++
+			continue
+		}
+		for _, skipped := range result.Skipped {
+			session.Report.Skip("posts", skipped.URI, skipped.Reason)
+		}
+		totalShares += result.Shares
+	}
+
+	if err := session.Finish(*reportPath); err != nil {
+		return err
+	}
+
+	verb := "Backfill complete"
+	if *dryRun {
+		verb = "Dry run complete, nothing saved"
+	}
+	log.Printf("✅ %s: %d new shares from %d sources (%d failed)", verb, totalShares, len(sources)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d sources failed", failed)
+	}
+	return nil
+}
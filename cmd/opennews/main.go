@@ -0,0 +1,175 @@
+// Command opennews runs the Open News server and its maintenance tasks. Every
+// subcommand reads the same .env and environment settings:
+//
+//	opennews serve            Run the HTTP server and background workers
+//	opennews migrate          Run database migrations
+//	opennews seed             Seed the database with users, sources and articles
+//	opennews backfill         Import recent posts from sources
+//	opennews replay           Record Jetstream events, or replay them through the pipeline
+//	opennews export           Export the global feeds, articles and sources as a snapshot
+//	opennews import           Import a snapshot written by export
+//	opennews refresh-follows  Refresh the follows of one or all users
+//	opennews publish-feed     Publish feed generator records to Bluesky
+//	opennews publish-labeler  Publish the labeler service record to Bluesky
+//	opennews tenant           List tenants, or add one with its own feeds and admin account
+//
+// Run "opennews help <command>" for a command's flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/cache"
+	"open-news/internal/database"
+
+	"github.com/joho/godotenv"
+)
+
+// command is a subcommand of opennews
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands returns the subcommands in the order help lists them
+func commands() []command {
+	return []command{
+		{"serve", "Run the HTTP server and background workers", runServe},
+		{"migrate", "Run database migrations", runMigrate},
+		{"seed", "Seed the database with users, sources and articles", runSeed},
+		{"backfill", "Import recent posts from sources", runBackfill},
+		{"replay", "Record Jetstream events, or replay them through the pipeline", runReplay},
+		{"export", "Export the global feeds, articles and sources as a snapshot", runExport},
+		{"import", "Import a snapshot written by export", runImport},
+		{"refresh-follows", "Refresh the follows of one or all users", runRefreshFollows},
+		{"publish-feed", "Publish feed generator records to Bluesky", runPublishFeed},
+		{"publish-labeler", "Publish the labeler service record to Bluesky", runPublishLabeler},
+		{"tenant", "List tenants, or add one with its own feeds and admin account", runTenant},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 0 {
+			if cmd, ok := findCommand(args[0]); ok {
+				cmd.run([]string{"-h"})
+				return
+			}
+		}
+		usage()
+		return
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "opennews: unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	if err := cmd.run(args); err != nil {
+		log.Fatalf("❌ %s: %v", cmd.name, err)
+	}
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usage lists the subcommands on stderr
+func usage() {
+	var b strings.Builder
+	b.WriteString("Usage: opennews <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(&b, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	b.WriteString("\nRun \"opennews help <command>\" for a command's flags.\n")
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// newFlagSet creates the flag set of a subcommand, exiting on -h or invalid flags
+func newFlagSet(name, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: opennews %s [flags]\n\n%s\n\nFlags:\n", name, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// connectDatabase connects to the database configured by the DB_* settings,
+// running migrations first when migrate is set. Callers close it with database.Close.
+func connectDatabase(migrate bool) error {
+	if err := database.Connect(database.LoadConfig()); err != nil {
+		return err
+	}
+	if migrate {
+		if err := database.Migrate(); err != nil {
+			database.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// newBlueskyClient creates a client for BLUESKY_BASE_URL, signed in as
+// BLUESKY_IDENTIFIER when credentials are set. With requireAuth, missing
+// credentials or a failed sign-in are errors; otherwise the client falls back
+// to the public API.
+func newBlueskyClient(requireAuth bool) (*bluesky.Client, error) {
+	return newBlueskyClientAs(os.Getenv("BLUESKY_IDENTIFIER"), os.Getenv("BLUESKY_PASSWORD"), requireAuth)
+}
+
+// newBlueskyClientAs creates a client for BLUESKY_BASE_URL signed in with the
+// given credentials, such as a tenant's, as newBlueskyClient does
+func newBlueskyClientAs(identifier, password string, requireAuth bool) (*bluesky.Client, error) {
+	baseURL := os.Getenv("BLUESKY_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://bsky.social"
+	}
+	client := bluesky.NewClient(baseURL)
+	client.SetCache(cache.Shared())
+
+	if identifier == "" || password == "" {
+		if requireAuth {
+			return nil, fmt.Errorf("BLUESKY_IDENTIFIER and BLUESKY_PASSWORD environment variables required")
+		}
+		log.Printf("💡 No Bluesky credentials configured, using public API")
+		return client, nil
+	}
+
+	log.Printf("🔐 Authenticating Bluesky client for %s...", identifier)
+	if err := client.CreateSession(identifier, password); err != nil {
+		if requireAuth {
+			return nil, fmt.Errorf("failed to authenticate with Bluesky: %w", err)
+		}
+		log.Printf("⚠️  Failed to authenticate with Bluesky: %v", err)
+		return client, nil
+	}
+	log.Printf("✅ Successfully authenticated with Bluesky")
+	return client, nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+
+	"open-news/internal/database"
+)
+
+// runMigrate runs the database migrations
+func runMigrate(args []string) error {
+	flags := newFlagSet("migrate", "Create and update the database tables.")
+	flags.Parse(args)
+
+	log.Println("🔄 Running database migrations...")
+	if err := connectDatabase(true); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	log.Println("✅ Database migrations completed successfully")
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/database"
+	"open-news/internal/didweb"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+)
+
+// runPublishFeed publishes a feed generator record for each active feed definition,
+// so the feeds can be found and pinned in Bluesky apps
+func runPublishFeed(args []string) error {
+	flags := newFlagSet("publish-feed", "Publish app.bsky.feed.generator records for the active feeds under the BLUESKY_IDENTIFIER account.\nRunning it again updates the records in place.")
+	generatorDID := flags.String("did", "", "DID of the feed generator service (defaults to the tenant's, then FEED_GENERATOR_DID)")
+	rkey := flags.String("rkey", "", "Record key of a single feed to publish, e.g. open-news-global (optional, publishes all if not specified)")
+	check := flags.Bool("check", false, "Check the published records against the active feeds and FEED_GENERATOR_DID instead of publishing")
+	tenantSlug := flags.String("tenant", models.DefaultTenantSlug, "Slug of the tenant whose feeds to publish with its Bluesky account")
+	flags.Parse(args)
+
+	if err := connectDatabase(false); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	tenant, err := services.NewTenantService(database.DB).BySlug(*tenantSlug)
+	if err != nil {
+		return fmt.Errorf("failed to find tenant %s: %w", *tenantSlug, err)
+	}
+	if *generatorDID == "" {
+		*generatorDID = tenant.FeedGeneratorDID
+	}
+	if *generatorDID == "" {
+		*generatorDID = os.Getenv("FEED_GENERATOR_DID")
+	}
+	if *generatorDID == "" {
+		return fmt.Errorf("a feed generator DID is required, set -did or FEED_GENERATOR_DID")
+	}
+	identifier, password := tenantCredentials(tenant)
+
+	registry := feeds.NewRegistry(database.DB).ForTenant(tenant.ID)
+	if err := registry.EnsureDefaults(); err != nil {
+		return err
+	}
+
+	if *check {
+		identity := didweb.LoadConfig()
+		identity.DID = *generatorDID
+		if tenant.BlueskyIdentifier != "" {
+			identity.Publisher = tenant.BlueskyIdentifier
+		}
+		if _, err := didweb.Build(identity); err != nil {
+			return err
+		}
+		client, err := newBlueskyClientAs(identifier, password, false)
+		if err != nil {
+			return err
+		}
+		problems, err := checkPublishedFeeds(client, registry, identity)
+		if err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			log.Printf("⚠️  %s", problem)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("the published feeds don't match %s", identity.DID)
+		}
+		log.Printf("✅ The published feeds match %s", identity.DID)
+		return nil
+	}
+	definitions, err := registry.List()
+	if err != nil {
+		return fmt.Errorf("failed to list feeds: %w", err)
+	}
+	if *rkey != "" {
+		definitions = filterDefinitions(definitions, *rkey)
+		if len(definitions) == 0 {
+			return fmt.Errorf("no active feed with record key %q", *rkey)
+		}
+	}
+
+	// Records are written to the signed-in account's repository
+	client, err := newBlueskyClientAs(identifier, password, true)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, def := range definitions {
+		put, err := client.PutFeedGenerator(def.RKey, bluesky.FeedGenerator{
+			DID:         *generatorDID,
+			DisplayName: def.DisplayName,
+			Description: def.Description,
+		})
+		if err != nil {
+			log.Printf("⚠️  %v", err)
+			failed++
+			continue
+		}
+		log.Printf("📡 Published %s: %s", def.DisplayName, put.URI)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to publish %d of %d feeds", failed, len(definitions))
+	}
+	log.Printf("✅ Published %d feeds served by %s", len(definitions), *generatorDID)
+	return nil
+}
+
+// tenantCredentials returns the Bluesky account a tenant publishes its feeds
+// with, falling back to BLUESKY_IDENTIFIER and BLUESKY_PASSWORD
+func tenantCredentials(tenant *models.Tenant) (string, string) {
+	if tenant.BlueskyIdentifier != "" && tenant.BlueskyPassword != "" {
+		return tenant.BlueskyIdentifier, tenant.BlueskyPassword
+	}
+	return os.Getenv("BLUESKY_IDENTIFIER"), os.Getenv("BLUESKY_PASSWORD")
+}
+
+// checkPublishedFeeds compares the feed generator records the publisher account
+// has published with the active feeds of registry and the feed generator's DID
+func checkPublishedFeeds(client *bluesky.Client, registry *feeds.Registry, identity didweb.Config) ([]string, error) {
+	definitions, err := registry.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	rkeys := make([]string, len(definitions))
+	for i, def := range definitions {
+		rkeys[i] = def.RKey
+	}
+	return didweb.CheckPublished(client, identity, rkeys)
+}
+
+// filterDefinitions returns the definitions with the given record key
+func filterDefinitions(definitions []models.FeedDefinition, rkey string) []models.FeedDefinition {
+	var matched []models.FeedDefinition
+	for _, def := range definitions {
+		if def.RKey == rkey {
+			matched = append(matched, def)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/labeler"
+)
+
+// runPublishLabeler publishes the labeler service record that lets Bluesky users
+// subscribe to the labels open.news issues
+func runPublishLabeler(args []string) error {
+	flags := newFlagSet("publish-labeler", "Publish the app.bsky.labeler.service record for the BLUESKY_IDENTIFIER account, which must be LABELER_DID.\nRunning it again updates the record in place.")
+	generateKey := flags.Bool("generate-key", false, "Print a new LABELER_SIGNING_KEY and its public key, then exit")
+	endpoint := flags.String("endpoint", os.Getenv("PUBLIC_BASE_URL"), "URL serving the label endpoints, for the DID document (defaults to PUBLIC_BASE_URL)")
+	flags.Parse(args)
+
+	if *generateKey {
+		key, err := labeler.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("failed to generate a key: %w", err)
+		}
+		parsed, err := labeler.ParseKey(key)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("LABELER_SIGNING_KEY=%s\n", key)
+		fmt.Printf("# #atproto_label public key: %s\n", labeler.DIDKey(&parsed.PublicKey))
+		return nil
+	}
+
+	signer, err := labeler.NewSigner(labeler.LoadConfig())
+	if err != nil {
+		return err
+	}
+	if signer == nil {
+		return fmt.Errorf("LABELER_DID is required, run with -generate-key to create a signing key first")
+	}
+
+	// The record is written to the signed-in account's repository, which has
+	// to be the account labels are issued as
+	client, err := newBlueskyClient(true)
+	if err != nil {
+		return err
+	}
+	if client.SessionDID() != signer.DID() {
+		return fmt.Errorf("signed in as %s, but LABELER_DID is %s", client.SessionDID(), signer.DID())
+	}
+
+	definitions := labeler.Definitions()
+	values := make([]string, len(definitions))
+	for i, definition := range definitions {
+		values[i] = definition.Identifier
+	}
+	put, err := client.PutLabelerService(bluesky.LabelerPolicies{LabelValues: values, LabelValueDefinitions: definitions})
+	if err != nil {
+		return err
+	}
+	log.Printf("🏷️  Published labeler service: %s", put.URI)
+
+	log.Printf("💡 The DID document of %s needs:", signer.DID())
+	log.Printf("   verification method #atproto_label with public key %s", strings.TrimPrefix(signer.PublicKey(), "did:key:"))
+	if *endpoint != "" {
+		log.Printf("   service #atproto_labeler of type AtprotoLabeler at %s", strings.TrimSuffix(*endpoint, "/"))
+	} else {
+		log.Printf("   service #atproto_labeler of type AtprotoLabeler at this server's public URL")
+	}
+	log.Printf("✅ Labeler %s publishes %s", signer.DID(), strings.Join(values, ", "))
+	return nil
+}
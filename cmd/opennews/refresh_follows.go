@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/models"
+	"open-news/internal/services"
+)
+
+// runRefreshFollows re-imports the follows of one user, or of every user
+func runRefreshFollows(args []string) error {
+	// Command line flags
+	flags := newFlagSet("refresh-follows", "Re-import users' follows from Bluesky now, ignoring the refresh interval.")
+	userDID := flags.String("user", "", "User DID to refresh follows for (optional, refreshes all if not specified)")
+	flags.Parse(args)
+
+	// Connect to database
+	if err := connectDatabase(false); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Follow imports need an authenticated client
+	blueskyClient, err := newBlueskyClient(true)
+	if err != nil {
+		return err
+	}
+
+	// Initialize user follows service
+	userFollowsService := services.NewUserFollowsService(database.DB, blueskyClient)
+
+	// Force refresh config (ignore time limits)
+	config := services.RefreshConfig{
+		RefreshInterval: 0, // Force immediate refresh
+		BatchSize:       50,
+		RateLimit:       100 * time.Millisecond,
+	}
+
+	if *userDID == "" {
+		// Refresh all users
+		log.Println("🔄 Refreshing follows for all users...")
+		if err := userFollowsService.RefreshBatch(config); err != nil {
+			return fmt.Errorf("failed to refresh follows: %w", err)
+		}
+		log.Println("✅ Successfully refreshed follows for all users")
+		return nil
+	}
+
+	// Refresh specific user
+	log.Printf("🔄 Refreshing follows for user: %s", *userDID)
+	var user models.User
+	if err := database.DB.Where("blue_sky_d_id = ?", *userDID).First(&user).Error; err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if err := userFollowsService.ImportUserFollows(&user, config); err != nil {
+		return fmt.Errorf("failed to refresh follows: %w", err)
+	}
+	log.Printf("✅ Successfully refreshed follows for user %s", user.Handle)
+	return nil
+}
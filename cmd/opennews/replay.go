@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/database"
+	"open-news/internal/dryrun"
+)
+
+// runReplay records Jetstream events to a file, or pushes a recorded file
+// through the firehose pipeline
+func runReplay(args []string) error {
+	// Command line flags
+	flags := newFlagSet("replay", "Push Jetstream events recorded one per line through the firehose pipeline, or record them with -capture.")
+	file := flags.String("file", "", "File of recorded events to replay")
+	capture := flags.String("capture", "", "Record events from Jetstream to this file instead of replaying")
+	max := flags.Int("max", 0, "Stop after this many events (0 for no limit)")
+	duration := flags.Duration("duration", 0, "With -capture, stop recording after this long (0 for no limit)")
+	allAccounts := flags.Bool("all-accounts", false, "With -capture, record posts from every account instead of the followed sources")
+	speed := flags.Float64("speed", 0, "Replay at this multiple of the recorded pace (0 for as fast as possible)")
+	dryRun := flags.Bool("dry-run", false, "Report what the replay would store without saving it")
+	reportPath := flags.String("report", "", "Write a JSON summary to this file, or - for stdout (default - with -dry-run)")
+	flags.Parse(args)
+	if *dryRun && *reportPath == "" {
+		*reportPath = "-"
+	}
+
+	if (*file == "") == (*capture == "") {
+		return fmt.Errorf("specify either -file or -capture")
+	}
+	if *capture != "" && *dryRun {
+		return fmt.Errorf("-dry-run only applies to a replay")
+	}
+	if *speed < 0 {
+		return fmt.Errorf("-speed can't be negative")
+	}
+
+	// Connect to database
+	if err := connectDatabase(false); err != nil {
+		return err
+	}
+	defer database.Close()
+	if *reportPath == "-" {
+		dryrun.LogToStderr(database.DB)
+	}
+
+	// Stop between events on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *capture != "" {
+		out, err := os.Create(*capture)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *capture, err)
+		}
+		defer out.Close()
+
+		consumer := bluesky.NewFirehoseConsumer(database.DB, nil)
+		log.Printf("🎙️  Recording Jetstream events to %s (Ctrl-C to stop)...", *capture)
+		written, err := consumer.Capture(ctx, out, bluesky.CaptureOptions{
+			Max:         *max,
+			Duration:    *duration,
+			AllAccounts: *allAccounts,
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Recorded %d events to %s", written, *capture)
+		return nil
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *file, err)
+	}
+	defer in.Close()
+
+	// Reposts look up the reposted post, which the public API allows
+	blueskyClient, err := newBlueskyClient(false)
+	if err != nil {
+		return err
+	}
+
+	// A dry run replays in a transaction that's rolled back at the end
+	session, err := dryrun.Start(database.DB, "replay", *dryRun)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Println("🧪 Dry run: nothing will be saved")
+	}
+
+	consumer := bluesky.NewFirehoseConsumer(session.DB, blueskyClient)
+	log.Printf("▶️  Replaying events from %s...", *file)
+	result, err := consumer.Replay(ctx, in, bluesky.ReplayOptions{Speed: *speed, Max: *max})
+	if err != nil {
+		session.Report.Fail(err)
+	}
+	if finishErr := session.Finish(*reportPath); finishErr != nil {
+		return finishErr
+	}
+	if err != nil {
+		return err
+	}
+
+	rate := 0.0
+	if seconds := result.Duration.Seconds(); seconds > 0 {
+		rate = float64(result.Events) / seconds
+	}
+	log.Printf("✅ Replayed %d events in %v (%.0f/s): %d failed, %d invalid lines",
+		result.Events, result.Duration.Round(time.Millisecond), rate, result.Failed, result.Invalid)
+	return nil
+}
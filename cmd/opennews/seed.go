@@ -1,7 +1,7 @@
 package main
 
 import (
-	"flag"
+	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -9,21 +9,55 @@ import (
 
 	"open-news/internal/bluesky"
 	"open-news/internal/database"
+	"open-news/internal/domains"
+	"open-news/internal/dryrun"
 	"open-news/internal/models"
 	"open-news/internal/services"
-
-	"github.com/joho/godotenv"
 )
 
-// This is a simple utility script to seed the database with some initial sources
+// This is a simple utility to seed the database with some initial sources
 // In a production system, this would be done through the API or admin interface
 
-func main() {
+// report records what the seeder created and skipped
+var report *dryrun.Report
+
+// runSeed seeds the database with a test user, sources and articles
+func runSeed(args []string) error {
 	// Parse command line flags
-	var userHandle = flag.String("handle", "", "Bluesky handle to seed as user (leave empty for mock data only)")
-	var userDID = flag.String("did", "did:plc:z72i7hdynmk6r22z27h6tvur", "DID of the test user (optional)")
-	var articlesOnly = flag.Bool("articles-only", false, "Only seed articles, skip users and sources")
-	flag.Parse()
+	flags := newFlagSet("seed", "Seed the database with a user and their follows, or mock sources, and recent articles.")
+	var userHandle = flags.String("handle", "", "Bluesky handle to seed as user (leave empty for mock data only)")
+	var userDID = flags.String("did", "did:plc:z72i7hdynmk6r22z27h6tvur", "DID of the test user (optional)")
+	var articlesOnly = flags.Bool("articles-only", false, "Only seed articles, skip users and sources")
+	var dryRun = flags.Bool("dry-run", false, "Report what would be created without saving it")
+	var reportPath = flags.String("report", "", "Write a JSON summary to this file, or - for stdout (default - with -dry-run)")
+	var syntheticArticles = flags.Int("synthetic", 0, "Generate this many synthetic articles instead of importing real ones")
+	var syntheticSeed = flags.Int64("synthetic-seed", 0, "Seed for -synthetic, to generate the same articles again (0 for random)")
+	var syntheticDomains = flags.String("synthetic-domains", "", "Sites for -synthetic as domain:weight pairs, e.g. reuters.com:3,example.com:1 (default: a mix of publishers)")
+	var syntheticQuality = flags.Float64("synthetic-quality", 0.7, "Average quality score of -synthetic articles")
+	var syntheticQualitySpread = flags.Float64("synthetic-quality-spread", 0.15, "Standard deviation of -synthetic quality scores")
+	var syntheticDays = flags.Int("synthetic-days", 7, "Days before now -synthetic articles are published within, most of them recently")
+	flags.Parse(args)
+	if *dryRun && *reportPath == "" {
+		*reportPath = "-"
+	}
+
+	synthetic := services.SyntheticConfig{
+		Articles:      *syntheticArticles,
+		Seed:          *syntheticSeed,
+		QualityMean:   *syntheticQuality,
+		QualitySpread: *syntheticQualitySpread,
+		TimeSpread:    time.Duration(*syntheticDays) * 24 * time.Hour,
+	}
+	if *syntheticArticles < 0 || *syntheticDays <= 0 || *syntheticQuality <= 0 || *syntheticQuality > 1 || *syntheticQualitySpread < 0 {
+		return fmt.Errorf("-synthetic and -synthetic-days must be positive, -synthetic-quality within (0, 1] and -synthetic-quality-spread at least 0")
+	}
+	if *syntheticDomains != "" {
+		domains, err := services.ParseDomainWeights(*syntheticDomains)
+		if err != nil {
+			return fmt.Errorf("invalid -synthetic-domains: %w", err)
+		}
+		synthetic.Domains = domains
+	}
 	
 	log.Printf("🌱 Open News Database Seeder")
 	log.Printf("============================")
@@ -33,52 +67,60 @@ func main() {
 		log.Printf("Mode: Mock data only")
 	}
 	
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
-
-	// Connect to database
-	dbConfig := database.LoadConfig()
-	if err := database.Connect(dbConfig); err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	// Connect to database, running migrations except in a dry run, which expects
+	// a migrated database
+	if err := connectDatabase(!*dryRun); err != nil {
+		return err
 	}
 	defer database.Close()
+	if *reportPath == "-" {
+		dryrun.LogToStderr(database.DB)
+	}
+	if *dryRun {
+		log.Printf("🧪 Dry run: nothing will be saved")
+	}
 
-	// Run migrations
-	if err := database.Migrate(); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+	// Seed through a session that records what's created. In a dry run that's a
+	// transaction, rolled back once seeding is done.
+	db := database.DB
+	session, err := dryrun.Start(db, "seed", *dryRun)
+	if err != nil {
+		return err
 	}
+	report = session.Report
+	database.DB = session.DB
 
 	// Initialize Bluesky client for potential authentication
 	var authenticatedClient *bluesky.Client
-	identifier := os.Getenv("BLUESKY_IDENTIFIER")
-	password := os.Getenv("BLUESKY_PASSWORD")
-	
-	if identifier != "" && password != "" {
-		client := bluesky.NewClient("https://bsky.social")
-		log.Printf("🔐 Authenticating with Bluesky as %s...", identifier)
-		if err := client.CreateSession(identifier, password); err != nil {
-			log.Printf("⚠️  Authentication failed: %v", err)
-			log.Printf("💡 Will use mock data instead")
-		} else {
-			log.Printf("✅ Successfully authenticated with Bluesky")
-			authenticatedClient = client
-		}
+	if client, err := newBlueskyClient(false); err == nil && client.IsAuthenticated() {
+		authenticatedClient = client
+	} else {
+		log.Printf("💡 Will use mock data where real data needs authentication")
 	}
 
 	if *articlesOnly {
 		// Only seed articles
 		log.Printf("📰 Articles-only seeding mode")
-		seedArticles(authenticatedClient, *userHandle)
+		seedArticles(authenticatedClient, *userHandle, synthetic)
 	} else {
-		// Full seeding: users, sources, and articles
+		// Full seeding: domains, users, sources, and articles
+		seedDomains()
+
 		// Seed a test user with a real Bluesky handle
 		// This user's follows will be automatically imported when they access their personalized feed
 		seedTestUser(*userHandle, *userDID)
 		
 		// Seed articles for testing
-		seedArticles(authenticatedClient, *userHandle)
+		seedArticles(authenticatedClient, *userHandle, synthetic)
+	}
+
+	database.DB = db
+	if err := session.Finish(*reportPath); err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Printf("✅ Dry run completed, nothing saved: %d rows would be created", len(report.Created))
+		return nil
 	}
 
 	log.Println("✅ Database seeding completed")
@@ -104,6 +146,22 @@ func main() {
 	log.Println("   • Beautiful documentation with proper styling")
 	log.Println("   • Copy-paste development commands")
 	log.Println("   • Live API endpoint testing")
+	return nil
+}
+
+// seedDomains fills an empty domains table with the curated news sites
+func seedDomains() {
+	seeded, err := domains.NewRegistry(database.DB).EnsureDefaults()
+	if err != nil {
+		log.Printf("⚠️ Failed to seed domains: %v", err)
+		report.Fail(err)
+		return
+	}
+	if seeded == 0 {
+		report.Skip("domains", "curated", "domains table already has entries")
+		return
+	}
+	log.Printf("🌐 Seeded %d news domains", seeded)
 }
 
 func seedTestUser(handle, did string) {
@@ -140,7 +198,7 @@ func seedTestUser(handle, did string) {
 	realDID, err := client.ResolveHandle(handle)
 	if err != nil {
 		log.Printf("❌ Handle '%s' is not a valid Bluesky account: %v", handle, err)
-		log.Printf("💡 To seed mock data instead, run: go run cmd/seed.go (without -handle flag)")
+		log.Printf("💡 To seed mock data instead, run: opennews seed (without -handle flag)")
 		log.Fatalf("Invalid Bluesky handle: %s", handle)
 	}
 	
@@ -170,6 +228,7 @@ func seedTestUser(handle, did string) {
 		
 	} else {
 		log.Printf("✅ User already exists: %s", existingUser.Handle)
+		report.Skip("users", existingUser.Handle, "already exists")
 		
 		// Check if they have follows imported
 		var followCount int64
@@ -396,20 +455,34 @@ func seedPopularSources() {
 			}
 		} else {
 			log.Printf("✅ Source already exists: %s", source.Handle)
+			report.Skip("sources", source.Handle, "already exists")
 		}
 	}
 }
 
-// seedArticles seeds the database with test articles
-func seedArticles(authenticatedClient *bluesky.Client, handle string) {
+// seedArticles seeds the database with test articles, generating
+// synthetic.Articles of them when set
+func seedArticles(authenticatedClient *bluesky.Client, handle string, synthetic services.SyntheticConfig) {
 	log.Printf("📰 Seeding articles...")
 	
+	// Synthetic articles are added alongside any already stored
+	if synthetic.Articles > 0 {
+		log.Printf("🧪 Generating %d synthetic articles...", synthetic.Articles)
+		articlesService := services.NewArticlesService(database.DB, authenticatedClient)
+		if err := articlesService.CreateMockArticles(services.ArticleSeedConfig{Synthetic: synthetic}); err != nil {
+			log.Printf("❌ Failed to create synthetic articles: %v", err)
+			report.Fail(err)
+		}
+		return
+	}
+	
 	// Check if we already have articles
 	var articleCount int64
 	database.DB.Model(&models.Article{}).Count(&articleCount)
 	
 	if articleCount > 0 {
 		log.Printf("✅ Database already has %d articles, skipping article seeding", articleCount)
+		report.Skip("articles", "all", fmt.Sprintf("database already has %d articles", articleCount))
 		return
 	}
 	
@@ -431,6 +504,9 @@ func seedArticles(authenticatedClient *bluesky.Client, handle string) {
 		TimeWindow:    24 * time.Hour,         // Look back 24 hours
 		RateLimit:     100 * time.Millisecond, // Fast for seeding
 		SampleSources: 10,                     // Sample from 10 sources
+		OnSkip: func(url, reason string) {
+			report.Skip("articles", url, reason)
+		},
 	}
 	
 	// Try to import real articles from Bluesky first
@@ -460,7 +536,7 @@ func seedArticles(authenticatedClient *bluesky.Client, handle string) {
 				}
 			} else {
 				log.Printf("ℹ️  No mock articles created with real handle - this ensures data integrity")
-				log.Printf("💡 To test the UI with sample data, run: go run cmd/seed.go (without -handle)")
+				log.Printf("💡 To test the UI with sample data, run: opennews seed (without -handle)")
 			}
 		} else {
 			log.Printf("✅ Found %d real articles from followed sources", articleCount)
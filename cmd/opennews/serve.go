@@ -0,0 +1,459 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	opennews "open-news"
+	"open-news/internal/bluesky"
+	"open-news/internal/cache"
+	"open-news/internal/database"
+	"open-news/internal/didweb"
+	"open-news/internal/domains"
+	"open-news/internal/errreport"
+	"open-news/internal/feeds"
+	"open-news/internal/handlers"
+	"open-news/internal/labeler"
+	"open-news/internal/mailer"
+	"open-news/internal/models"
+	"open-news/internal/services"
+	"open-news/internal/tracing"
+	"open-news/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runServe runs the HTTP server and background workers until it's signalled to stop
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "Run migrations, then serve the feeds, web interface and API on PORT with the background workers.\nWith -validate, check the feed skeletons of the active feeds against the getFeedSkeleton lexicon and exit instead.")
+	validate := flags.Bool("validate", false, "Check every active feed's getFeedSkeleton responses against the lexicon, then exit; fails if any feed doesn't conform")
+	flags.Parse(args)
+
+	// Export traces when an OpenTelemetry collector is configured
+	if err := tracing.Init(); err != nil {
+		log.Printf("⚠️  Tracing disabled: %v", err)
+	}
+	defer tracing.Shutdown()
+
+	// Report panics and failures to the configured error tracker
+	errreport.Init()
+	defer errreport.Flush(5 * time.Second)
+
+	// Connect to database and run migrations
+	if err := connectDatabase(true); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Create the first admin account on a new install
+	if err := services.NewAdminUserService(database.DB).EnsureBootstrapAdmin(); err != nil {
+		return fmt.Errorf("failed to create admin account: %w", err)
+	}
+
+	// Register the default feed definitions
+	if err := feeds.NewRegistry(database.DB).EnsureDefaults(); err != nil {
+		return fmt.Errorf("failed to register feed definitions: %w", err)
+	}
+
+	// Seed domain reputations on a new install
+	if seeded, err := domains.NewRegistry(database.DB).EnsureDefaults(); err != nil {
+		return err
+	} else if seeded > 0 {
+		log.Printf("🌐 Seeded %d news domains", seeded)
+	}
+
+	// Initialize and start background workers
+	workerService := worker.NewWorkerService()
+	if *validate {
+		r, err := newRouter(workerService)
+		if err != nil {
+			return err
+		}
+		return validateFeedSkeletons(r)
+	}
+	if err := workerService.Start(); err != nil {
+		return fmt.Errorf("failed to start background workers: %w", err)
+	}
+
+	// Setup graceful shutdown
+	setupGracefulShutdown(workerService)
+
+	// Setup HTTP server
+	return setupServer(workerService)
+}
+
+func setupGracefulShutdown(workerService *worker.WorkerService) {
+	// Setup signal handling for graceful shutdown
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		log.Println("Received shutdown signal, gracefully shutting down...")
+		
+		// Stop background workers
+		workerService.Stop()
+		
+		// Close database connection
+		database.Close()
+
+		// Send the last traces and error reports
+		tracing.Shutdown()
+		errreport.Flush(5 * time.Second)
+		
+		log.Println("Shutdown complete")
+		os.Exit(0)
+	}()
+}
+
+func setupServer(workerService *worker.WorkerService) error {
+	r, err := newRouter(workerService)
+	if err != nil {
+		return err
+	}
+
+	// Get port from environment or default to 8080
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	log.Printf("Server starting on port %s", port)
+	if err := r.Run(":" + port); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	return nil
+}
+
+// newRouter sets up the routes of the feeds, web interface and API
+func newRouter(workerService *worker.WorkerService) (*gin.Engine, error) {
+	// Set Gin mode based on environment
+	if os.Getenv("GIN_MODE") == "release" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Create router, logging each request with its trace ID, tracing it
+	// (continuing callers' traces) and reporting panics
+	r := gin.New()
+
+	// Client IPs are read from X-Forwarded-For only behind the proxies listed in
+	// TRUSTED_PROXIES, so other clients can't pick the IP they're rate limited as
+	if err := r.SetTrustedProxies(handlers.LoadTrustedProxies("TRUSTED_PROXIES")); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	r.Use(handlers.RequestLogger())
+	r.Use(handlers.TracingMiddleware()...)
+	r.Use(handlers.RecoveryMiddleware())
+	r.NoRoute(handlers.NotFoundHandler())
+
+	// Content-Security-Policy, framing (only widgets can be embedded), referrer and HSTS headers
+	r.Use(handlers.SecurityHeadersMiddleware())
+
+	// CORS middleware (CORS_ORIGINS is a comma-separated list, default "*")
+	r.Use(handlers.CORSMiddleware(handlers.LoadCORSConfig("CORS_ORIGINS", "*")))
+
+	// Requests are served for the tenant whose hostname they're for, or the default tenant
+	tenantService := services.NewTenantService(database.DB)
+	r.Use(handlers.TenantMiddleware(tenantService))
+
+	// Initialize handlers
+	feedHandler := handlers.NewFeedHandler(database.DB, workerService)
+	feedPageHandler := handlers.NewFeedPageHandler(database.DB, opennews.Static)
+	
+	// Initialize Bluesky client for admin operations
+	blueskyBaseURL := os.Getenv("BLUESKY_BASE_URL")
+	if blueskyBaseURL == "" {
+		blueskyBaseURL = "https://bsky.social"
+	}
+	blueskyClient := bluesky.NewClient(blueskyBaseURL)
+	blueskyClient.SetCache(cache.Shared())
+
+	// The did:web document is generated from FEED_GENERATOR_DID and
+	// FEED_SERVICE_ENDPOINT, and the published feed records are checked against it
+	identity := didweb.LoadConfig()
+	didDocument, err := didweb.Build(identity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed generator identity: %w", err)
+	}
+	if identity.DID != "" {
+		go func() {
+			problems, err := checkPublishedFeeds(blueskyClient, feeds.NewRegistry(database.DB), identity)
+			if err != nil {
+				log.Printf("⚠️  Couldn't check the published feeds: %v", err)
+				return
+			}
+			for _, problem := range problems {
+				log.Printf("⚠️  Published feeds: %s", problem)
+			}
+			if len(problems) == 0 {
+				log.Printf("✅ The published feeds match %s", identity.DID)
+			}
+		}()
+	}
+	
+	// Initialize services for admin handler
+	articlesService := services.NewArticlesService(database.DB, blueskyClient)
+	adminHandler := handlers.NewAdminHandler(database.DB, workerService.GetUserFollowsService(), articlesService, blueskyClient, tenantService)
+	
+	docsHandler := handlers.NewDocsHandler(opennews.Docs)
+	widgetHandler := handlers.NewWidgetHandler(database.DB)
+	articleHandler := handlers.NewArticleHandler(database.DB)
+	entityHandler := handlers.NewEntityHandler(database.DB)
+	meHandler := handlers.NewMeHandler(database.DB)
+	eventsHandler := handlers.NewEventsHandler(database.DB)
+	clickHandler := handlers.NewClickHandler(database.DB)
+	labelerHandler := handlers.NewLabelerHandler(database.DB)
+	optOutHandler := handlers.NewOptOutHandler(database.DB)
+	wellKnownHandler := handlers.NewWellKnownHandler(didDocument, opennews.WellKnown)
+
+	// Email digest subscriptions send confirmation emails with the MAIL_* settings
+	emailMailer, err := mailer.New(mailer.LoadConfig())
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail settings: %w", err)
+	}
+	emailHandler := handlers.NewEmailHandler(services.NewEmailDigestService(database.DB, emailMailer))
+	
+	// Initialize Bluesky feed handler
+	blueskyFeedHandler := handlers.NewBlueSkyFeedHandler(database.DB, blueskyClient)
+
+	// Health check
+	r.GET("/health", feedHandler.HealthCheck)
+
+	// Serve the DID document, generated for a did:web or else from static files.
+	// Static files and docs are embedded in the binary.
+	r.GET("/.well-known/*path", wellKnownHandler.ServeWellKnown)
+	r.StaticFS("/static", http.FS(opennews.Static))
+	
+	// Serve documentation and home page
+	r.StaticFS("/docs", http.FS(opennews.Docs))
+	staticFile(r, "/", opennews.Static, "index.html")
+	staticFile(r, "/index.html", opennews.Static, "index.html")
+	staticFile(r, "/widget-examples.html", opennews.Static, "widget-examples.html")
+	
+	// Feed web interface
+	r.GET("/feeds", feedPageHandler.ServeMainFeedPage)
+	r.GET("/feed/global", feedPageHandler.ServeGlobalFeedHTML)
+	r.GET("/feed/personal", feedPageHandler.ServePersonalFeedHTML)
+	r.GET("/feed/custom/:rkey", feedPageHandler.ServeCustomFeedHTML)
+	
+	// Embeddable widgets
+	r.GET("/widget/global", feedPageHandler.ServeGlobalWidget)
+	r.GET("/widget/personal", feedPageHandler.ServePersonalWidget)
+	
+	// Tracked redirects from feed pages and widgets to articles
+	r.GET("/r/:article_id", clickHandler.Redirect)
+
+	// Article permalinks and the sitemap listing them
+	r.GET("/article/:id", articleHandler.ServeLandingPage)
+	r.GET("/sitemap.xml", articleHandler.ServeSitemap)
+
+	// Email digest confirm and unsubscribe links
+	r.GET("/email/confirm/:token", emailHandler.Confirm)
+	r.GET("/email/unsubscribe/:token", emailHandler.ServeUnsubscribePage)
+	r.POST("/email/unsubscribe/:token", emailHandler.Unsubscribe)
+	
+	// Serve Markdown documentation as HTML
+	r.GET("/doc/:doc", docsHandler.ServeMarkdownAsHTML)
+
+	// Rate limits for the public endpoints: per client IP, and per requesting DID for
+	// authenticated feed requests (RATE_LIMIT_IP_* and RATE_LIMIT_DID_* settings)
+	ipLimiter := handlers.NewRateLimiter(handlers.LoadRateLimitConfig("RATE_LIMIT_IP", 10, 40))
+	didLimiter := handlers.NewRateLimiter(handlers.LoadRateLimitConfig("RATE_LIMIT_DID", 2, 10))
+
+	// AT Protocol custom feed endpoints
+	xrpc := r.Group("/xrpc", handlers.RateLimitMiddleware(ipLimiter, didLimiter, blueskyFeedHandler.RequesterDID))
+	{
+		xrpc.GET("/app.bsky.feed.getFeedSkeleton", blueskyFeedHandler.GetFeedSkeleton)
+		
+		xrpc.GET("/app.bsky.feed.describeFeedGenerator", blueskyFeedHandler.GetFeedInfo)
+
+		// Labels issued when the labeler is configured (LABELER_DID)
+		if labeler.LoadConfig().DID != "" {
+			xrpc.GET("/com.atproto.label.queryLabels", labelerHandler.QueryLabels)
+			xrpc.GET("/com.atproto.label.subscribeLabels", labelerHandler.SubscribeLabels)
+		}
+	}
+
+	// API routes
+	api := r.Group("/api", handlers.RateLimitMiddleware(ipLimiter, nil, nil))
+	{
+		feeds := api.Group("/feeds")
+		{
+			feeds.GET("/global", feedHandler.GetGlobalFeed)
+			feeds.GET("/personalized", feedHandler.GetPersonalizedFeed)
+		}
+		
+		articles := api.Group("/articles")
+		{
+			articles.GET("/search", articleHandler.SearchArticles)
+			articles.GET("/:id/score-breakdown", articleHandler.GetScoreBreakdown)
+			articles.GET("/:id/shares", articleHandler.GetShares)
+			articles.GET("/:id/history", articleHandler.GetScoreHistory)
+			articles.GET("/:id/related", articleHandler.GetRelated)
+		}
+		
+		entities := api.Group("/entities")
+		{
+			entities.GET("", entityHandler.ListEntities)
+			entities.GET("/:name", entityHandler.GetEntity)
+		}
+		
+		me := api.Group("/me", meHandler.Auth())
+		{
+			me.GET("", meHandler.GetProfile)
+			me.DELETE("", meHandler.DeleteAccount)
+			me.GET("/export", meHandler.RequestExport)
+			me.GET("/export/:id/download", meHandler.DownloadExport)
+			me.GET("/preferences", meHandler.GetPreferences)
+			me.PUT("/preferences", meHandler.UpdatePreferences)
+			me.PUT("/topics/:topic", meHandler.FollowTopic)
+			me.DELETE("/topics/:topic", meHandler.UnfollowTopic)
+			me.PUT("/languages", meHandler.SetLanguages)
+			me.GET("/feeds", meHandler.ListCustomFeeds)
+			me.POST("/feeds", meHandler.CreateCustomFeed)
+			me.PUT("/feeds/:id", meHandler.UpdateCustomFeed)
+			me.DELETE("/feeds/:id", meHandler.DeleteCustomFeed)
+		}
+		
+		api.GET("/events/breaking", eventsHandler.StreamBreaking)
+		
+		api.POST("/email/subscriptions", emailHandler.Subscribe)
+		
+		api.POST("/publishers/opt-out", optOutHandler.RequestOptOut)
+		api.POST("/publishers/opt-out/verify", optOutHandler.VerifyOptOut)
+		
+		widget := api.Group("/widget", widgetHandler.WidgetAuth())
+		{
+			widget.GET("/global", widgetHandler.GetGlobalWidget)
+		}
+		
+		worker := api.Group("/worker")
+		{
+			worker.GET("/status", feedHandler.WorkerStatus)
+		}
+	}
+
+	// Admin sign-in
+	r.GET("/admin/login", adminHandler.ServeLoginPage)
+	r.POST("/admin/login", adminHandler.Login)
+	r.POST("/admin/logout", adminHandler.Logout)
+
+	// Admin routes (signed-in accounts; viewers can read, moderators can change
+	// content and run maintenance, admins can manage accounts and API keys)
+	admin := r.Group("/admin", adminHandler.AdminAuth())
+	{
+		admin.GET("/", adminHandler.ServeAdminDashboard)
+		admin.GET("/users", adminHandler.ServeUsersPage)
+		admin.GET("/users/:id/feed", adminHandler.ServeUserFeedPreview)
+		admin.GET("/sources", adminHandler.ServeSourcesPage)
+		admin.GET("/articles", adminHandler.ServeArticlesPage)
+		admin.GET("/articles/:id", adminHandler.ServeArticleInspection)
+		admin.GET("/jobs", adminHandler.ServeJobsPage)
+		admin.GET("/feed-health", adminHandler.ServeFeedHealthPage)
+		admin.GET("/inspect", adminHandler.InspectURL)
+		admin.GET("/analytics/clicks", adminHandler.GetClickAnalytics)
+		admin.GET("/api/sources/verification", adminHandler.ListPendingVerifications)
+		admin.GET("/api/domains", adminHandler.ListDomains)
+		admin.GET("/api/opt-outs", adminHandler.ListOptOuts)
+		admin.GET("/api/sources/spam", adminHandler.ListFlaggedSources)
+		admin.GET("/api/articles/duplicates", adminHandler.ListDuplicateClusters)
+		admin.GET("/api/feed-health", adminHandler.GetFeedHealth)
+		admin.GET("/api/retention", adminHandler.GetRetention)
+		admin.GET("/api/snapshot", adminHandler.ExportSnapshot)
+		admin.GET("/api/tenant", adminHandler.GetTenant)
+
+		moderator := admin.Group("", adminHandler.RequireRole(models.AdminRoleModerator))
+		{
+			moderator.POST("/users/:id/seen-filter", adminHandler.SetSeenFilter)
+			moderator.POST("/users/:id/digest", adminHandler.SetDigestSubscription)
+			moderator.POST("/api/sources", adminHandler.AddSource)
+			moderator.POST("/api/sources/:id/verify", adminHandler.VerifySource)
+			moderator.POST("/api/sources/:id/verification", adminHandler.ReviewSourceVerification)
+			moderator.POST("/api/sources/:id/spam", adminHandler.ReviewSourceSpam)
+			moderator.POST("/api/domains", adminHandler.SaveDomain)
+			moderator.DELETE("/api/domains/:domain", adminHandler.DeleteDomain)
+			moderator.POST("/api/opt-outs", adminHandler.AddOptOut)
+			moderator.DELETE("/api/opt-outs/:domain", adminHandler.DeleteOptOut)
+			moderator.POST("/articles/:id/refetch", adminHandler.RefetchArticle)
+			moderator.POST("/articles/:id/facts", adminHandler.ExtractArticleFacts)
+			moderator.POST("/articles/:id/summary", adminHandler.SummarizeArticle)
+			moderator.POST("/articles/:id/pin", adminHandler.PinArticle)
+			moderator.POST("/articles/:id/boost", adminHandler.BoostArticle)
+			moderator.POST("/api/articles/bulk-delete", adminHandler.BulkDeleteArticles)
+			moderator.POST("/api/articles/bulk-refetch", adminHandler.BulkRefetchArticles)
+			moderator.POST("/api/articles/bulk-rescore", adminHandler.BulkRescoreArticles)
+			moderator.POST("/api/articles/bulk-not-news", adminHandler.BulkMarkNotNews)
+			moderator.POST("/jobs/:id/retry", adminHandler.RetryJob)
+			moderator.POST("/refresh-follows", adminHandler.RefreshAllUserFollows)
+			moderator.POST("/refresh-follows/:user", adminHandler.RefreshUserFollows)
+			moderator.POST("/validate-articles", adminHandler.ValidateArticles)
+		}
+
+		owner := admin.Group("", adminHandler.RequireRole(models.AdminRoleAdmin))
+		{
+			owner.GET("/accounts", adminHandler.ServeAccountsPage)
+			owner.POST("/api/accounts", adminHandler.CreateAccount)
+			owner.POST("/api/accounts/:id", adminHandler.UpdateAccount)
+			owner.PUT("/api/tenant", adminHandler.UpdateTenant)
+		}
+
+		// Settings shared by every tenant are managed from the default tenant
+		operator := owner.Group("", adminHandler.RequireDefaultTenant())
+		{
+			operator.GET("/api-keys", adminHandler.ListAPIKeys)
+			operator.POST("/api-keys", adminHandler.CreateAPIKey)
+			operator.POST("/api-keys/:id/revoke", adminHandler.RevokeAPIKey)
+			operator.POST("/api/snapshot", adminHandler.ImportSnapshot)
+		}
+	}
+
+	return r, nil
+}
+
+// staticFile serves a file of fsys at relativePath, like gin's StaticFile does from disk
+func staticFile(r gin.IRoutes, relativePath string, fsys fs.FS, name string) {
+	handler := func(c *gin.Context) {
+		http.ServeFileFS(c.Writer, c.Request, fsys, name)
+	}
+	r.GET(relativePath, handler)
+	r.HEAD(relativePath, handler)
+}
+
+// validateFeedSkeletons serves the router on a local port and checks every
+// active feed's getFeedSkeleton responses against the lexicon, following cursors
+// for a few pages. It fails when any feed doesn't conform.
+func validateFeedSkeletons(r http.Handler) error {
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	generatorDID := os.Getenv("FEED_GENERATOR_DID")
+	if generatorDID == "" {
+		generatorDID = "did:web:localhost"
+	}
+	definitions, err := feeds.NewRegistry(database.DB).List()
+	if err != nil {
+		return fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	failed := 0
+	for _, def := range definitions {
+		feedURI := fmt.Sprintf("at://%s/app.bsky.feed.generator/%s", generatorDID, def.RKey)
+		if err := bluesky.CheckFeedSkeleton(server.Client(), server.URL, feedURI, 30, 5); err != nil {
+			failed++
+			log.Printf("❌ %s doesn't conform to the getFeedSkeleton lexicon:\n%v", def.RKey, err)
+			continue
+		}
+		log.Printf("✅ %s conforms to the getFeedSkeleton lexicon", def.RKey)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d feeds don't conform to the getFeedSkeleton lexicon", failed, len(definitions))
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/dryrun"
+	"open-news/internal/services"
+)
+
+// runExport writes the global feeds, their articles and sources to a snapshot
+// another instance can import
+func runExport(args []string) error {
+	// Command line flags
+	flags := newFlagSet("export", "Export the global feeds, their articles and sources as a portable snapshot.")
+	output := flags.String("o", "-", "File to write the snapshot to, or - for stdout")
+	format := flags.String("format", services.SnapshotNDJSON, "Snapshot format: ndjson (one record per line) or json")
+	all := flags.Bool("all", false, "Export every article instead of only those in the global feeds")
+	days := flags.Int("days", 0, "With -all, only export articles from the last N days (0 for all)")
+	content := flags.Bool("content", false, "Include cached article pages")
+	flags.Parse(args)
+
+	if *format != services.SnapshotNDJSON && *format != services.SnapshotJSON {
+		return fmt.Errorf("-format must be ndjson or json")
+	}
+	if *days < 0 {
+		return fmt.Errorf("-days can't be negative")
+	}
+	options := services.ExportOptions{Format: *format, AllArticles: *all, IncludeContent: *content}
+	if *days > 0 {
+		options.Since = time.Now().AddDate(0, 0, -*days)
+	}
+
+	// Connect to database
+	if err := connectDatabase(false); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := services.NewSnapshotService(database.DB).Export(out, options); err != nil {
+		return err
+	}
+	if *output != "-" {
+		log.Printf("✅ Exported snapshot to %s", *output)
+	}
+	return nil
+}
+
+// runImport loads a snapshot written by export
+func runImport(args []string) error {
+	// Command line flags
+	flags := newFlagSet("import", "Import a snapshot written by export, keeping articles and sources already stored.")
+	file := flags.String("file", "-", "Snapshot to import, or - for stdin")
+	dryRun := flags.Bool("dry-run", false, "Report what would be imported without saving it")
+	reportPath := flags.String("report", "", "Write a JSON summary to this file, or - for stdout (default - with -dry-run)")
+	flags.Parse(args)
+	if *dryRun && *reportPath == "" {
+		*reportPath = "-"
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", *file, err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// Connect to database
+	if err := connectDatabase(true); err != nil {
+		return err
+	}
+	defer database.Close()
+	if *reportPath == "-" {
+		dryrun.LogToStderr(database.DB)
+	}
+
+	// A dry run imports in a transaction that's rolled back at the end
+	session, err := dryrun.Start(database.DB, "import", *dryRun)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Println("🧪 Dry run: nothing will be saved")
+	}
+
+	result, err := services.NewSnapshotService(session.DB).Import(in)
+	if err != nil {
+		session.Report.Fail(err)
+	}
+	if finishErr := session.Finish(*reportPath); finishErr != nil {
+		return finishErr
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Imported %d sources, %d articles, %d shares and %d feeds (%d items); %d already stored, %d skipped",
+		result.Sources, result.Articles, result.Shares, result.Feeds, result.FeedItems, result.Existing, result.Skipped)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"open-news/internal/database"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+)
+
+// runTenant lists the tenants of the deployment, or adds one with the default
+// feeds and a first admin account
+func runTenant(args []string) error {
+	flags := newFlagSet("tenant", "List the tenants hosted by this deployment, or add one with -create.\nA new tenant gets the default feeds and an admin account, and is served on its -hosts.")
+	create := flags.String("create", "", "Slug of a tenant to add, e.g. citynews")
+	name := flags.String("name", "", "With -create, the name shown on the tenant's pages (defaults to the slug)")
+	hosts := flags.String("hosts", "", "With -create, comma-separated hostnames the tenant is served on")
+	did := flags.String("did", "", "With -create, the DID the tenant's feed generator is served as, e.g. did:web:feeds.citynews.example")
+	identifier := flags.String("bluesky-identifier", "", "With -create, the Bluesky account publishing the tenant's feeds")
+	password := flags.String("bluesky-password", "", "With -create, that account's app password")
+	adminUsername := flags.String("admin-username", "admin", "With -create, username of the tenant's first admin account")
+	adminPassword := flags.String("admin-password", "", "With -create, password of that account (required)")
+	flags.Parse(args)
+
+	if err := connectDatabase(true); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	tenants := services.NewTenantService(database.DB)
+	if *create == "" {
+		return listTenants(tenants)
+	}
+
+	if *adminPassword == "" {
+		return fmt.Errorf("-admin-password is required for the tenant's first admin account")
+	}
+	tenant := models.Tenant{
+		Slug:              *create,
+		Name:              *name,
+		FeedGeneratorDID:  strings.TrimSpace(*did),
+		BlueskyIdentifier: strings.TrimSpace(*identifier),
+		BlueskyPassword:   *password,
+	}
+	for _, host := range strings.Split(*hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			tenant.Hostnames = append(tenant.Hostnames, host)
+		}
+	}
+
+	created, err := tenants.Create(tenant)
+	if err != nil {
+		return err
+	}
+	if err := feeds.NewRegistry(database.DB).ForTenant(created.ID).EnsureDefaults(); err != nil {
+		return err
+	}
+	if _, err := services.NewAdminUserService(database.DB).ForTenant(created.ID).CreateUser(*adminUsername, *adminPassword, models.AdminRoleAdmin); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Added tenant %s with admin account %q", created.Slug, *adminUsername)
+	if len(created.Hostnames) == 0 {
+		log.Printf("💡 Tenant %s has no hostnames, so it isn't served yet - add them in its admin settings", created.Slug)
+	}
+	return nil
+}
+
+// listTenants prints the tenants as a table
+func listTenants(tenants *services.TenantService) error {
+	list, err := tenants.List()
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SLUG\tNAME\tHOSTS\tFEED GENERATOR\tACTIVE")
+	for _, tenant := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\n", tenant.Slug, tenant.Name, strings.Join(tenant.Hostnames, ","), tenant.FeedGeneratorDID, tenant.IsActive)
+	}
+	return w.Flush()
+}
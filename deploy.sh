@@ -36,7 +36,7 @@ echo "📁 File Check"
 echo "============="
 
 REQUIRED_FILES=(
-    "cmd/main.go"
+    "cmd/opennews/main.go"
     "internal/handlers/bluesky_feed.go"
     "internal/auth/jwt.go"
     "go.mod"
@@ -58,7 +58,7 @@ echo "=============="
 
 # Test build
 echo "Testing build..."
-if go build -o bin/open-news-test cmd/main.go; then
+if go build -o bin/open-news-test ./cmd/opennews; then
     echo "✅ Build successful"
     rm -f bin/open-news-test
 else
@@ -139,7 +139,7 @@ if [ "$PRODUCTION_MODE" = "production" ]; then
     echo "🏗️  Building Production Binary"
     echo "============================="
     
-    if go build -ldflags="-s -w" -o bin/open-news-prod cmd/main.go; then
+    if go build -ldflags="-s -w" -o bin/open-news-prod ./cmd/opennews; then
         echo "✅ Production build successful"
     else
         echo "❌ Production build failed"
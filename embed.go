@@ -0,0 +1,34 @@
+// Package opennews holds the files the server ships with, embedded in the
+// binary so it runs from any working directory. The other .go files in this
+// directory are one-off scripts, excluded from the build and run with go run.
+package opennews
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// static is the static directory. Files starting with a dot are included so
+// a hand-written static/.well-known/did.json is served.
+//
+//go:embed all:static
+var static embed.FS
+
+// Docs are the Markdown documents served under /docs and rendered under /doc
+//
+//go:embed README.md DEVELOPMENT.md TESTING.md PRODUCTION_DEPLOYMENT.md BLUESKY_FEEDS.md QUICK_DEPLOY.md STATUS.md
+var Docs embed.FS
+
+// Static is the contents of the static directory, served under /static
+var Static = mustSub(static, "static")
+
+// WellKnown is the contents of static/.well-known, served under /.well-known
+var WellKnown = mustSub(Static, ".well-known")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,19 @@
+package opennews
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedFiles(t *testing.T) {
+	for _, name := range []string{"index.html", "feed.html", "widget-examples.html", "widget.js", "theme.css"} {
+		_, err := fs.Stat(Static, name)
+		assert.NoError(t, err, name)
+	}
+	for _, name := range []string{"README.md", "BLUESKY_FEEDS.md", "STATUS.md"} {
+		_, err := fs.Stat(Docs, name)
+		assert.NoError(t, err, name)
+	}
+}
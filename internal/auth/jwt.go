@@ -8,14 +8,23 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// jwksRefreshInterval is the least time between JWKS fetches. Tokens with a kid
+// that isn't cached fail without a fetch until it has passed, so clients sending
+// made-up tokens can't trigger an outbound request each.
+const jwksRefreshInterval = time.Minute
+
 // JWTVerifier handles Bluesky JWT token verification
 type JWTVerifier struct {
+	mu         sync.Mutex // Guards publicKeys and lastFetch
 	publicKeys map[string]*rsa.PublicKey
+	lastFetch  time.Time // When the JWKS was last requested, successfully or not
+	jwksURL    string
 	client     *http.Client
 }
 
@@ -23,6 +32,9 @@ type JWTVerifier struct {
 func NewJWTVerifier() *JWTVerifier {
 	return &JWTVerifier{
 		publicKeys: make(map[string]*rsa.PublicKey),
+		// Bluesky's JWKS endpoint (this is a placeholder - you'll need the actual endpoint)
+		// For production, you should get this from Bluesky's documentation
+		jwksURL: "https://bsky.social/.well-known/jwks.json",
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -100,12 +112,21 @@ func (v *JWTVerifier) ExtractDIDFromToken(tokenString string) (string, error) {
 	return sub, nil
 }
 
-// getPublicKey fetches and caches the public key for a given kid
+// getPublicKey fetches and caches the public key for a given kid. The JWKS is
+// fetched at most once per jwksRefreshInterval.
 func (v *JWTVerifier) getPublicKey(kid string) (*rsa.PublicKey, error) {
 	// Check cache first
+	v.mu.Lock()
 	if key, exists := v.publicKeys[kid]; exists {
+		v.mu.Unlock()
 		return key, nil
 	}
+	if !v.lastFetch.IsZero() && time.Since(v.lastFetch) < jwksRefreshInterval {
+		v.mu.Unlock()
+		return nil, fmt.Errorf("public key not found for kid: %s", kid)
+	}
+	v.lastFetch = time.Now()
+	v.mu.Unlock()
 
 	// Fetch JWKS from Bluesky
 	jwks, err := v.fetchJWKS()
@@ -122,7 +143,9 @@ func (v *JWTVerifier) getPublicKey(kid string) (*rsa.PublicKey, error) {
 			}
 			
 			// Cache the key
+			v.mu.Lock()
 			v.publicKeys[kid] = publicKey
+			v.mu.Unlock()
 			return publicKey, nil
 		}
 	}
@@ -132,11 +155,7 @@ func (v *JWTVerifier) getPublicKey(kid string) (*rsa.PublicKey, error) {
 
 // fetchJWKS fetches the JSON Web Key Set from Bluesky
 func (v *JWTVerifier) fetchJWKS() (*BlueSkyJWKS, error) {
-	// Bluesky's JWKS endpoint (this is a placeholder - you'll need the actual endpoint)
-	// For production, you should get this from Bluesky's documentation
-	jwksURL := "https://bsky.social/.well-known/jwks.json"
-	
-	resp, err := v.client.Get(jwksURL)
+	resp, err := v.client.Get(v.jwksURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
 	}
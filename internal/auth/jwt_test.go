@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestValidateTokenLimitsJWKSFetches(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	verifier := NewJWTVerifier()
+	verifier.jwksURL = server.URL
+
+	// Every request carries a token with a kid never seen before
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "did:plc:fake"})
+			token.Header["kid"] = uuid.NewString()
+			signed, err := token.SignedString([]byte("not a bluesky key"))
+			if err != nil {
+				t.Errorf("Failed to sign token: %v", err)
+				return
+			}
+			if did, ok := verifier.ValidateToken("Bearer " + signed); ok {
+				t.Errorf("Expected the made-up token to fail, got %s", did)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("Expected one JWKS fetch, got %d", n)
+	}
+}
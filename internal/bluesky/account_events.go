@@ -0,0 +1,93 @@
+package bluesky
+
+import (
+	"fmt"
+	"log"
+
+	"open-news/internal/models"
+)
+
+// processAccountEvent deactivates the sources and users of an account that was
+// deactivated, suspended, taken down or deleted, and reactivates them when it returns
+func (fc *FirehoseConsumer) processAccountEvent(account *JetstreamAccount) error {
+	status := account.Status
+	if account.Active {
+		status = ""
+	} else if status == "" {
+		status = "deactivated"
+	}
+
+	sources := fc.db.Model(&models.Source{}).
+		Where("blue_sky_d_id = ?", account.DID).
+		Updates(map[string]interface{}{"is_active": account.Active, "account_status": status})
+	if sources.Error != nil {
+		return fmt.Errorf("failed to update source account status: %w", sources.Error)
+	}
+
+	users := fc.db.Model(&models.User{}).
+		Where("blue_sky_d_id = ?", account.DID).
+		Update("is_active", account.Active)
+	if users.Error != nil {
+		return fmt.Errorf("failed to update user account status: %w", users.Error)
+	}
+
+	if sources.RowsAffected > 0 || users.RowsAffected > 0 {
+		log.Printf("Account %s is now %s (active: %v)", account.DID, accountStatusLabel(status), account.Active)
+		fc.forgetProfile(account.DID)
+	}
+	return nil
+}
+
+// processIdentityEvent updates stored handles when an account changes its handle
+func (fc *FirehoseConsumer) processIdentityEvent(identity *JetstreamIdentity) error {
+	if identity.Handle == "" {
+		return nil
+	}
+
+	var previous []string
+	err := fc.db.Model(&models.Source{}).
+		Where("blue_sky_d_id = ? AND handle <> ?", identity.DID, identity.Handle).
+		Pluck("handle", &previous).Error
+	if err != nil {
+		return fmt.Errorf("failed to look up source handle: %w", err)
+	}
+
+	sources := fc.db.Model(&models.Source{}).
+		Where("blue_sky_d_id = ? AND handle <> ?", identity.DID, identity.Handle).
+		Update("handle", identity.Handle)
+	if sources.Error != nil {
+		return fmt.Errorf("failed to update source handle: %w", sources.Error)
+	}
+
+	users := fc.db.Model(&models.User{}).
+		Where("blue_sky_d_id = ? AND handle <> ?", identity.DID, identity.Handle).
+		Update("handle", identity.Handle)
+	if users.Error != nil {
+		return fmt.Errorf("failed to update user handle: %w", users.Error)
+	}
+
+	if sources.RowsAffected > 0 || users.RowsAffected > 0 {
+		log.Printf("Handle for %s changed to %s", identity.DID, identity.Handle)
+		fc.forgetProfile(identity.DID, previous...)
+	}
+	return nil
+}
+
+// forgetProfile drops cached lookups for an account whose details changed
+func (fc *FirehoseConsumer) forgetProfile(did string, oldHandles ...string) {
+	if fc.client == nil {
+		return
+	}
+	fc.client.ForgetProfile(did)
+	for _, handle := range oldHandles {
+		fc.client.ForgetHandle(handle)
+	}
+}
+
+// accountStatusLabel describes an account status for logging
+func accountStatusLabel(status string) string {
+	if status == "" {
+		return "active"
+	}
+	return status
+}
@@ -0,0 +1,53 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"testing"
+
+	"open-news/internal/models"
+)
+
+func TestProcessAccountAndIdentityEvents(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	send := func(event JetstreamEvent) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("Failed to marshal test event: %v", err)
+		}
+		if err := consumer.processJetstreamMessage(data); err != nil {
+			t.Fatalf("processJetstreamMessage failed: %v", err)
+		}
+	}
+	reload := func() models.Source {
+		var updated models.Source
+		db.First(&updated, "id = ?", source.ID)
+		return updated
+	}
+
+	// Deleting the account deactivates the source
+	send(JetstreamEvent{DID: source.BlueSkyDID, Kind: "account", Account: &JetstreamAccount{
+		DID: source.BlueSkyDID, Active: false, Status: "deleted",
+	}})
+	if updated := reload(); updated.IsActive || updated.AccountStatus != "deleted" {
+		t.Errorf("Expected source to be inactive with status deleted, got active=%v status=%q", updated.IsActive, updated.AccountStatus)
+	}
+
+	// Reactivation restores it
+	send(JetstreamEvent{DID: source.BlueSkyDID, Kind: "account", Account: &JetstreamAccount{
+		DID: source.BlueSkyDID, Active: true,
+	}})
+	if updated := reload(); !updated.IsActive || updated.AccountStatus != "" {
+		t.Errorf("Expected source to be active again, got active=%v status=%q", updated.IsActive, updated.AccountStatus)
+	}
+
+	// Identity events carry handle changes
+	send(JetstreamEvent{DID: source.BlueSkyDID, Kind: "identity", Identity: &JetstreamIdentity{
+		DID: source.BlueSkyDID, Handle: "newsdesk.example.com",
+	}})
+	if updated := reload(); updated.Handle != "newsdesk.example.com" {
+		t.Errorf("Expected handle to be updated, got %q", updated.Handle)
+	}
+}
@@ -0,0 +1,216 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+)
+
+const (
+	reasonRepost = "app.bsky.feed.defs#reasonRepost"
+	reasonPin    = "app.bsky.feed.defs#reasonPin"
+)
+
+// BackfillOptions controls how far back Backfill walks an author feed
+type BackfillOptions struct {
+	Since    time.Time     // Stop at the first post older than this
+	PageSize int           // Posts per getAuthorFeed request (default: 50, max: 100)
+	MaxPages int           // Stop after this many pages; 0 for no limit
+	Delay    time.Duration // Pause between pages, to stay under rate limits
+}
+
+// BackfillResult summarizes the backfill of one source
+type BackfillResult struct {
+	Source  string         `json:"source"`
+	Pages   int            `json:"pages"`
+	Posts   int            `json:"posts"`  // Posts and reposts in the window
+	Shares  int            `json:"shares"` // New shares recorded
+	Skipped []BackfillSkip `json:"skipped"`
+}
+
+// BackfillSkip is a post in the window that didn't add a share
+type BackfillSkip struct {
+	URI    string `json:"uri"`
+	Reason string `json:"reason"`
+}
+
+// Backfill walks a source's author feed back to opts.Since, ingesting its posts
+// and reposts as if they had arrived from Jetstream, so a newly added source
+// contributes its recent shares right away. Posts already recorded are skipped.
+func (fc *FirehoseConsumer) Backfill(ctx context.Context, source *models.Source, opts BackfillOptions) (*BackfillResult, error) {
+	if fc.client == nil {
+		return nil, fmt.Errorf("backfill requires a Bluesky client")
+	}
+	if opts.PageSize <= 0 || opts.PageSize > 100 {
+		opts.PageSize = 50
+	}
+
+	var before int64
+	if err := fc.db.Model(&models.SourceArticle{}).Where("source_id = ?", source.ID).Count(&before).Error; err != nil {
+		return nil, fmt.Errorf("failed to count shares: %w", err)
+	}
+
+	result := &BackfillResult{Source: source.Handle, Skipped: []BackfillSkip{}}
+	cursor := ""
+	for done := false; !done; {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		page, err := fc.client.GetAuthorFeedPage(source.BlueSkyDID, opts.PageSize, cursor)
+		if err != nil {
+			return result, fmt.Errorf("failed to get posts from %s: %w", source.Handle, err)
+		}
+		result.Pages++
+
+		for _, item := range page.Feed {
+			event, at, reason := feedItemEvent(source.BlueSkyDID, item)
+			if at.IsZero() {
+				continue // Pinned posts are listed first, out of order
+			}
+			if at.Before(opts.Since) {
+				done = true
+				break
+			}
+
+			result.Posts++
+			if event == nil {
+				result.Skipped = append(result.Skipped, BackfillSkip{URI: item.Post.URI, Reason: reason})
+				continue
+			}
+			if reason := fc.backfillEvent(event); reason != "" {
+				result.Skipped = append(result.Skipped, BackfillSkip{URI: commitURI(event), Reason: reason})
+			}
+		}
+
+		cursor = page.Cursor
+		if cursor == "" || (opts.MaxPages > 0 && result.Pages >= opts.MaxPages) {
+			break
+		}
+		if !done && opts.Delay > 0 {
+			select {
+			case <-time.After(opts.Delay):
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+		}
+	}
+
+	var after int64
+	if err := fc.db.Model(&models.SourceArticle{}).Where("source_id = ?", source.ID).Count(&after).Error; err != nil {
+		return result, fmt.Errorf("failed to count shares: %w", err)
+	}
+	result.Shares = int(after - before)
+
+	log.Printf("📥 Backfilled %s: %d posts on %d pages, %d new shares", source.Handle, result.Posts, result.Pages, result.Shares)
+	return result, nil
+}
+
+// backfillEvent runs an event made by feedItemEvent through the firehose handlers,
+// returning why it didn't add a share, or "" when it did
+func (fc *FirehoseConsumer) backfillEvent(event *JetstreamEvent) string {
+	uri := commitURI(event)
+	var existing int64
+	if err := fc.db.Model(&models.SourceArticle{}).Where("post_uri = ?", uri).Count(&existing).Error; err != nil {
+		return fmt.Sprintf("failed to check for existing shares: %v", err)
+	}
+	if existing > 0 {
+		return "already recorded"
+	}
+
+	var err error
+	if event.Commit.Collection == jetstreamRepostCollection {
+		err = fc.processRepostCommit(event)
+	} else {
+		var post PostRecord
+		if data, marshalErr := json.Marshal(event.Commit.Record); marshalErr == nil && json.Unmarshal(data, &post) == nil &&
+			len(fc.extractLinksFromPost(&post)) == 0 && post.Embed.QuotedURI() == "" {
+			return "no links"
+		}
+		err = fc.processPostCommit(event)
+	}
+	if err != nil {
+		log.Printf("Error backfilling %s: %v", uri, err)
+		return err.Error()
+	}
+	if fc.shares != nil {
+		fc.shares.flush()
+	}
+
+	var added int64
+	if err := fc.db.Model(&models.SourceArticle{}).Where("post_uri = ?", uri).Count(&added).Error; err != nil {
+		return fmt.Sprintf("failed to check for new shares: %v", err)
+	}
+	if added == 0 {
+		return "no links to news articles"
+	}
+	return ""
+}
+
+// feedItemEvent converts an author feed item of the given DID into the Jetstream
+// commit that created it, with the time it was posted or reposted. The event is nil,
+// with the reason, for items that can't be attributed to a record by the author,
+// such as reposts without the repost record's URI; the time is zero for pinned posts.
+func feedItemEvent(did string, item FeedViewPost) (*JetstreamEvent, time.Time, string) {
+	post := item.Post
+	switch {
+	case item.Reason != nil && item.Reason.Type == reasonPin:
+		return nil, time.Time{}, "pinned"
+
+	case item.Reason != nil && item.Reason.Type == reasonRepost:
+		at := item.Reason.IndexedAt
+		if item.Reason.URI == "" {
+			return nil, at, "repost record not available"
+		}
+		return &JetstreamEvent{
+			DID:  did,
+			Kind: "commit",
+			Commit: &JetstreamCommit{
+				Operation:  "create",
+				Collection: jetstreamRepostCollection,
+				RKey:       recordKey(item.Reason.URI),
+				CID:        item.Reason.CID,
+				Record: map[string]interface{}{
+					"$type":     jetstreamRepostCollection,
+					"subject":   map[string]interface{}{"uri": post.URI, "cid": post.CID},
+					"createdAt": at.Format(time.RFC3339Nano),
+				},
+			},
+		}, at, ""
+	}
+
+	at := post.Record.CreatedAt
+	if at.IsZero() {
+		at = post.IndexedAt
+	}
+	if post.Author.DID != did || !strings.Contains(post.URI, "/"+jetstreamPostCollection+"/") {
+		return nil, at, "not posted by the source"
+	}
+
+	var record map[string]interface{}
+	data, err := json.Marshal(post.Record)
+	if err != nil || json.Unmarshal(data, &record) != nil {
+		return nil, at, "invalid post record"
+	}
+	return &JetstreamEvent{
+		DID:  did,
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  "create",
+			Collection: jetstreamPostCollection,
+			RKey:       recordKey(post.URI),
+			CID:        post.CID,
+			Record:     record,
+		},
+	}, at, ""
+}
+
+// recordKey returns the last segment of an AT URI
+func recordKey(uri string) string {
+	return uri[strings.LastIndex(uri, "/")+1:]
+}
@@ -0,0 +1,100 @@
+package bluesky
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedItemEvent(t *testing.T) {
+	did := "did:plc:source"
+	posted := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	post := Post{
+		URI:    "at://did:plc:source/app.bsky.feed.post/abc",
+		CID:    "bafypost",
+		Author: Author{DID: did},
+		Record: Record{Text: "Read this", CreatedAt: posted, Embed: &Embed{External: &ExternalEmbed{URI: "https://example.com/story"}}},
+	}
+
+	event, at, _ := feedItemEvent(did, FeedViewPost{Post: post})
+	require.NotNil(t, event)
+	assert.Equal(t, posted, at)
+	assert.Equal(t, "at://did:plc:source/app.bsky.feed.post/abc", commitURI(event))
+	assert.Equal(t, "Read this", event.Commit.Record["text"])
+
+	// Reposts are attributed to the repost record
+	reposted := posted.Add(time.Hour)
+	other := post
+	other.Author = Author{DID: "did:plc:other"}
+	event, at, _ = feedItemEvent(did, FeedViewPost{Post: other, Reason: &FeedReason{Type: reasonRepost, URI: "at://did:plc:source/app.bsky.feed.repost/rp", IndexedAt: reposted}})
+	require.NotNil(t, event)
+	assert.Equal(t, reposted, at)
+	assert.Equal(t, "at://did:plc:source/app.bsky.feed.repost/rp", commitURI(event))
+	assert.Equal(t, post.URI, event.Commit.Record["subject"].(map[string]interface{})["uri"])
+
+	event, at, reason := feedItemEvent(did, FeedViewPost{Post: other, Reason: &FeedReason{Type: reasonRepost, IndexedAt: reposted}})
+	assert.Nil(t, event, "reposts without the record URI can't be attributed")
+	assert.Equal(t, reposted, at)
+	assert.Equal(t, "repost record not available", reason)
+
+	event, at, _ = feedItemEvent(did, FeedViewPost{Post: post, Reason: &FeedReason{Type: reasonPin}})
+	assert.Nil(t, event)
+	assert.True(t, at.IsZero(), "pinned posts don't end the walk")
+}
+
+func TestBackfill(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+
+	// A tracked post by another account, which the source reposted
+	original := models.Source{BlueSkyDID: "did:plc:backfilloriginal", Handle: "original.bsky.social"}
+	db.Create(&original)
+	article := models.Article{URL: "https://example.com/backfilled", Title: "Backfilled story"}
+	db.Create(&article)
+	originalURI := "at://did:plc:backfilloriginal/app.bsky.feed.post/orig"
+	db.Create(&models.SourceArticle{SourceID: original.ID, ArticleID: article.ID, PostURI: originalURI, PostText: "Big news", PostedAt: time.Now()})
+
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("cursor"))
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprintf(w, `{"cursor":"page2","feed":[{"post":{"uri":%q,"cid":"bafyorig","author":{"did":"did:plc:backfilloriginal"},"record":{"text":"Big news","createdAt":%q}},
+				"reason":{"$type":"app.bsky.feed.defs#reasonRepost","uri":"at://%s/app.bsky.feed.repost/rp","cid":"bafyrp","indexedAt":%q}}]}`,
+				originalURI, recent, source.BlueSkyDID, recent)
+			return
+		}
+		fmt.Fprintf(w, `{"cursor":"page3","feed":[{"post":{"uri":"at://%s/app.bsky.feed.post/old","author":{"did":%q},"record":{"text":"Old","createdAt":%q}}}]}`,
+			source.BlueSkyDID, source.BlueSkyDID, old)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.session = &Session{AccessJWT: "token"}
+	consumer := &FirehoseConsumer{db: db, client: client}
+
+	result, err := consumer.Backfill(context.Background(), source, BackfillOptions{Since: time.Now().Add(-30 * 24 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "page2"}, pages, "the walk stops at the first post before Since")
+	assert.Equal(t, 1, result.Posts)
+	assert.Equal(t, 1, result.Shares)
+
+	var repost models.SourceArticle
+	require.NoError(t, db.Where("source_id = ? AND article_id = ?", source.ID, article.ID).First(&repost).Error)
+	assert.True(t, repost.IsRepost)
+
+	// Running again skips what's already recorded
+	result, err = consumer.Backfill(context.Background(), source, BackfillOptions{Since: time.Now().Add(-30 * 24 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Shares)
+	assert.Equal(t, []BackfillSkip{{URI: repost.PostURI, Reason: "already recorded"}}, result.Skipped)
+}
@@ -2,11 +2,30 @@ package bluesky
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"open-news/internal/cache"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	// handleCacheTTL is how long handle to DID resolutions are reused
+	handleCacheTTL = time.Hour
+
+	// profileCacheTTL is how long fetched profiles are reused
+	profileCacheTTL = 15 * time.Minute
+
+	// MaxProfilesPerRequest is the most actors app.bsky.actor.getProfiles accepts
+	MaxProfilesPerRequest = 25
 )
 
 // Client represents a Bluesky API client
@@ -14,6 +33,8 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	session    *Session
+	cache      cache.Cache     // Optional cache for handle resolutions and profiles
+	ctx        context.Context // Context requests are made with; see WithContext
 }
 
 // Session represents an authenticated Bluesky session
@@ -34,23 +55,39 @@ type Post struct {
 	RepostCount int       `json:"repostCount"`
 	LikeCount   int       `json:"likeCount"`
 	IndexedAt   time.Time `json:"indexedAt"`
+	Labels      []Label   `json:"labels,omitempty"` // Applied by labelers, including the AppView's own
 }
 
-// Author represents a post author
+// Author represents a post author. Counts are only set on profiles fetched with GetProfile or GetProfiles.
 type Author struct {
-	DID         string `json:"did"`
-	Handle      string `json:"handle"`
-	DisplayName string `json:"displayName,omitempty"`
-	Avatar      string `json:"avatar,omitempty"`
+	DID            string  `json:"did"`
+	Handle         string  `json:"handle"`
+	DisplayName    string  `json:"displayName,omitempty"`
+	Avatar         string  `json:"avatar,omitempty"`
+	Description    string  `json:"description,omitempty"`
+	FollowersCount int     `json:"followersCount,omitempty"`
+	FollowsCount   int     `json:"followsCount,omitempty"`
+	PostsCount     int     `json:"postsCount,omitempty"`
+	Labels         []Label `json:"labels,omitempty"`
+}
+
+// Label is a moderation label applied to an account or record
+type Label struct {
+	Src string `json:"src"`
+	URI string `json:"uri"`
+	Val string `json:"val"`
+	Neg bool   `json:"neg,omitempty"`
 }
 
 // Record represents the content of a post
 type Record struct {
-	Type      string    `json:"$type"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"createdAt"`
-	Facets    []Facet   `json:"facets,omitempty"`
-	Embed     *Embed    `json:"embed,omitempty"`
+	Type      string      `json:"$type"`
+	Text      string      `json:"text"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Facets    []Facet     `json:"facets,omitempty"`
+	Embed     *Embed      `json:"embed,omitempty"`
+	Reply     *Reply      `json:"reply,omitempty"`
+	Labels    *SelfLabels `json:"labels,omitempty"`
 }
 
 // Facet represents a facet in a post (links, mentions, etc.)
@@ -78,6 +115,45 @@ type Embed struct {
 	Type     string         `json:"$type"`
 	External *ExternalEmbed `json:"external,omitempty"`
 	Images   []ImageEmbed   `json:"images,omitempty"`
+	Record   *RecordEmbed   `json:"record,omitempty"` // Quoted post (app.bsky.embed.record and recordWithMedia)
+	Media    *Embed         `json:"media,omitempty"`  // Images or link card alongside a quote (recordWithMedia)
+}
+
+// RecordEmbed references a quoted record. app.bsky.embed.record sets URI and CID
+// directly; app.bsky.embed.recordWithMedia nests them under Record.
+type RecordEmbed struct {
+	URI    string       `json:"uri,omitempty"`
+	CID    string       `json:"cid,omitempty"`
+	Record *RecordEmbed `json:"record,omitempty"`
+}
+
+// QuotedURI returns the AT URI of the post quoted by an embed, or "" when it doesn't
+// quote one. Embedded feeds, lists and other records are ignored.
+func (e *Embed) QuotedURI() string {
+	if e == nil || e.Record == nil {
+		return ""
+	}
+	uri := e.Record.URI
+	if e.Record.Record != nil {
+		uri = e.Record.Record.URI
+	}
+	if !strings.Contains(uri, "/app.bsky.feed.post/") {
+		return ""
+	}
+	return uri
+}
+
+// ExternalURI returns the link card URI of an embed, including one shown alongside a quote
+func (e *Embed) ExternalURI() string {
+	switch {
+	case e == nil:
+		return ""
+	case e.External != nil:
+		return e.External.URI
+	case e.Media != nil && e.Media.External != nil:
+		return e.Media.External.URI
+	}
+	return ""
 }
 
 // ExternalEmbed represents an external link embed
@@ -116,11 +192,34 @@ func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: otelhttp.NewTransport(nil),
 		},
 	}
 }
 
+// WithContext returns a client making its requests with ctx, so they're traced
+// as part of the request or job ctx belongs to. It shares the session and cache.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	scoped := *c
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// context returns the context requests are made with
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// SetCache makes the client reuse handle resolutions and profiles stored in c,
+// which may be shared with other instances
+func (c *Client) SetCache(store cache.Cache) {
+	c.cache = store
+}
+
 // CreateSession authenticates with Bluesky and creates a session
 func (c *Client) CreateSession(identifier, password string) error {
 	reqBody := map[string]string{
@@ -172,7 +271,7 @@ func (c *Client) GetTimeline(limit int, cursor string) (*Timeline, error) {
 		url += "&cursor=" + cursor
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -204,9 +303,13 @@ func (c *Client) GetTimeline(limit int, cursor string) (*Timeline, error) {
 
 // GetProfile retrieves a user's profile
 func (c *Client) GetProfile(handle string) (*Author, error) {
+	if profile, ok := c.cachedProfile(handle); ok {
+		return profile, nil
+	}
+
 	url := fmt.Sprintf("%s/xrpc/app.bsky.actor.getProfile?actor=%s", c.baseURL, handle)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -235,9 +338,166 @@ func (c *Client) GetProfile(handle string) (*Author, error) {
 		return nil, err
 	}
 
+	c.storeProfile(handle, &profile)
+
 	return &profile, nil
 }
 
+// GetProfiles retrieves the profiles of several actors (handles or DIDs), asking for
+// up to 25 per request. Actors whose profiles can't be found are left out.
+func (c *Client) GetProfiles(actors []string) ([]Author, error) {
+	profiles := make([]Author, 0, len(actors))
+	var missing []string
+	for _, actor := range actors {
+		if profile, ok := c.cachedProfile(actor); ok {
+			profiles = append(profiles, *profile)
+		} else {
+			missing = append(missing, actor)
+		}
+	}
+
+	for start := 0; start < len(missing); start += MaxProfilesPerRequest {
+		end := start + MaxProfilesPerRequest
+		if end > len(missing) {
+			end = len(missing)
+		}
+
+		batch, err := c.getProfilesBatch(missing[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			c.storeProfile(batch[i].DID, &batch[i])
+		}
+		profiles = append(profiles, batch...)
+	}
+
+	return profiles, nil
+}
+
+// getProfilesBatch makes a single app.bsky.actor.getProfiles request
+func (c *Client) getProfilesBatch(actors []string) ([]Author, error) {
+	query := url.Values{}
+	for _, actor := range actors {
+		query.Add("actors", actor)
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+"/xrpc/app.bsky.actor.getProfiles?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.session != nil {
+		req.Header.Set("Authorization", "Bearer "+c.session.AccessJWT)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get profiles: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Profiles []Author `json:"profiles"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Profiles, nil
+}
+
+// GetPosts retrieves posts by their AT URIs (up to 25 per call). Deleted or hidden
+// posts are left out.
+func (c *Client) GetPosts(uris []string) ([]Post, error) {
+	query := url.Values{}
+	for _, uri := range uris {
+		query.Add("uris", uri)
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+"/xrpc/app.bsky.feed.getPosts?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.session != nil {
+		req.Header.Set("Authorization", "Bearer "+c.session.AccessJWT)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get posts: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Posts []Post `json:"posts"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Posts, nil
+}
+
+// cachedProfile returns a profile stored by an earlier lookup of actor
+func (c *Client) cachedProfile(actor string) (*Author, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	data, ok := c.cache.Get("bluesky:profile:" + actor)
+	if !ok {
+		return nil, false
+	}
+	var profile Author
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, false
+	}
+	return &profile, true
+}
+
+// ForgetProfile drops the cached profile of an actor whose details changed
+func (c *Client) ForgetProfile(actor string) {
+	if c.cache != nil {
+		c.cache.Delete("bluesky:profile:" + actor)
+	}
+}
+
+// ForgetHandle drops a cached handle resolution, e.g. after the account moved to a new handle
+func (c *Client) ForgetHandle(handle string) {
+	if c.cache != nil {
+		c.cache.Delete("bluesky:handle:" + handle)
+	}
+}
+
+// storeProfile caches a fetched profile under the actor it was looked up by
+func (c *Client) storeProfile(actor string, profile *Author) {
+	if c.cache == nil {
+		return
+	}
+	if data, err := json.Marshal(profile); err == nil {
+		c.cache.Set("bluesky:profile:"+actor, data, profileCacheTTL)
+	}
+}
+
 // ExtractLinks extracts URLs from a post's text and embeds
 func ExtractLinks(post *Post) []string {
 	var links []string
@@ -252,8 +512,8 @@ func ExtractLinks(post *Post) []string {
 	}
 
 	// Extract from embeds
-	if post.Record.Embed != nil && post.Record.Embed.External != nil {
-		links = append(links, post.Record.Embed.External.URI)
+	if uri := post.Record.Embed.ExternalURI(); uri != "" {
+		links = append(links, uri)
 	}
 
 	return links
@@ -273,7 +533,7 @@ func (c *Client) GetFollows(actor string, limit int, cursor string) (*FollowsRes
 		url += "&cursor=" + cursor
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -307,9 +567,16 @@ func (c *Client) GetFollows(actor string, limit int, cursor string) (*FollowsRes
 
 // ResolveHandle resolves a handle to a DID
 func (c *Client) ResolveHandle(handle string) (string, error) {
+	cacheKey := "bluesky:handle:" + handle
+	if c.cache != nil {
+		if did, ok := c.cache.Get(cacheKey); ok {
+			return string(did), nil
+		}
+	}
+
 	url := fmt.Sprintf("%s/xrpc/com.atproto.identity.resolveHandle?handle=%s", c.baseURL, handle)
 	
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -336,27 +603,62 @@ func (c *Client) ResolveHandle(handle string) (string, error) {
 		return "", err
 	}
 	
+	if c.cache != nil && result.DID != "" {
+		c.cache.Set(cacheKey, []byte(result.DID), handleCacheTTL)
+	}
+	
 	return result.DID, nil
 }
 
 // AuthorFeedResponse represents the response from getAuthorFeed
 type AuthorFeedResponse struct {
-	Feed   []Post `json:"feed"`
-	Cursor string `json:"cursor,omitempty"`
+	Feed   []FeedViewPost `json:"feed"`
+	Cursor string         `json:"cursor,omitempty"` // Empty on the last page
+}
+
+// FeedViewPost is an item of an author feed: a post by the author, or a post they
+// reposted or pinned, as described by Reason
+type FeedViewPost struct {
+	Post   Post        `json:"post"`
+	Reason *FeedReason `json:"reason,omitempty"`
+}
+
+// FeedReason explains why a post is in an author feed
+type FeedReason struct {
+	Type      string    `json:"$type"` // app.bsky.feed.defs#reasonRepost or #reasonPin
+	By        *Author   `json:"by,omitempty"`
+	URI       string    `json:"uri,omitempty"` // The repost record, when the AppView includes it
+	CID       string    `json:"cid,omitempty"`
+	IndexedAt time.Time `json:"indexedAt"`
 }
 
 // GetAuthorFeed retrieves posts from a specific author
 func (c *Client) GetAuthorFeed(actor string, limit int, cursor string) ([]Post, error) {
+	page, err := c.GetAuthorFeedPage(actor, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]Post, len(page.Feed))
+	for i, item := range page.Feed {
+		posts[i] = item.Post
+	}
+	return posts, nil
+}
+
+// GetAuthorFeedPage retrieves a page of an author feed, newest first, with the
+// cursor for the next page
+func (c *Client) GetAuthorFeedPage(actor string, limit int, cursor string) (*AuthorFeedResponse, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
-	url := fmt.Sprintf("%s/xrpc/app.bsky.feed.getAuthorFeed?actor=%s&limit=%d", c.baseURL, actor, limit)
+	query := url.Values{"actor": {actor}, "limit": {strconv.Itoa(limit)}}
 	if cursor != "" {
-		url += "&cursor=" + cursor
+		query.Set("cursor", cursor)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+"/xrpc/app.bsky.feed.getAuthorFeed?"+query.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -383,7 +685,7 @@ func (c *Client) GetAuthorFeed(actor string, limit int, cursor string) ([]Post,
 		return nil, err
 	}
 
-	return response.Feed, nil
+	return &response, nil
 }
 
 // ExtractLinksFromPost extracts all links from a Bluesky post
@@ -0,0 +1,102 @@
+package bluesky
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"open-news/internal/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CachesLookups(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/xrpc/com.atproto.identity.resolveHandle":
+			fmt.Fprint(w, `{"did":"did:plc:reporter"}`)
+		case "/xrpc/app.bsky.actor.getProfile":
+			fmt.Fprint(w, `{"did":"did:plc:reporter","handle":"reporter.bsky.social","displayName":"Reporter"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetCache(cache.NewMemory(10))
+
+	for i := 0; i < 2; i++ {
+		did, err := client.ResolveHandle("reporter.bsky.social")
+		require.NoError(t, err)
+		assert.Equal(t, "did:plc:reporter", did)
+
+		profile, err := client.GetProfile("did:plc:reporter")
+		require.NoError(t, err)
+		assert.Equal(t, "Reporter", profile.DisplayName)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// Without a cache every lookup goes to the API
+	uncached := NewClient(server.URL)
+	_, err := uncached.ResolveHandle("reporter.bsky.social")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestClient_GetProfilesBatches(t *testing.T) {
+	var batchSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actors := r.URL.Query()["actors"]
+		batchSizes = append(batchSizes, len(actors))
+
+		fmt.Fprint(w, `{"profiles":[`)
+		for i, actor := range actors {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"did":%q,"handle":"h","followersCount":%d}`, actor, i)
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	defer server.Close()
+
+	actors := make([]string, 30)
+	for i := range actors {
+		actors[i] = fmt.Sprintf("did:plc:%d", i)
+	}
+
+	client := NewClient(server.URL)
+	client.SetCache(cache.NewMemory(100))
+
+	profiles, err := client.GetProfiles(actors)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 30)
+	assert.Equal(t, []int{MaxProfilesPerRequest, 5}, batchSizes)
+
+	// Cached profiles aren't requested again
+	profiles, err = client.GetProfiles(append(actors[:2:2], "did:plc:new"))
+	require.NoError(t, err)
+	assert.Len(t, profiles, 3)
+	assert.Equal(t, []int{MaxProfilesPerRequest, 5, 1}, batchSizes)
+}
+
+func TestClient_GetPosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xrpc/app.bsky.feed.getPosts", r.URL.Path)
+		assert.Equal(t, []string{"at://did:plc:a/app.bsky.feed.post/1"}, r.URL.Query()["uris"])
+		fmt.Fprint(w, `{"posts":[{"uri":"at://did:plc:a/app.bsky.feed.post/1","record":{"text":"Read this",
+			"embed":{"$type":"app.bsky.embed.external","external":{"uri":"https://example.com/story"}}}}]}`)
+	}))
+	defer server.Close()
+
+	posts, err := NewClient(server.URL).GetPosts([]string{"at://did:plc:a/app.bsky.feed.post/1"})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.Equal(t, []string{"https://example.com/story"}, ExtractLinks(&posts[0]))
+}
@@ -0,0 +1,91 @@
+package bluesky
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// commitURI builds the AT URI of the record a Jetstream commit refers to
+func commitURI(event *JetstreamEvent) string {
+	return fmt.Sprintf("at://%s/%s/%s", event.DID, event.Commit.Collection, event.Commit.RKey)
+}
+
+// processPostDelete removes the shares recorded for a deleted post or repost, along with the
+// reposts and quotes of a deleted post, which no longer resolve on Bluesky. Deleted posts are
+// removed outright rather than tombstoned, so the post text isn't kept after the author
+// deleted it, and the articles they pointed to are rescored without them.
+func (fc *FirehoseConsumer) processPostDelete(event *JetstreamEvent) error {
+	uri := commitURI(event)
+
+	// Shares still waiting in the buffer are dropped before they're written
+	if fc.shares != nil {
+		if discarded := fc.shares.discard(uri); discarded > 0 {
+			log.Printf("Dropped %d buffered shares of deleted post %s", discarded, uri)
+		}
+	}
+
+	var articleIDs []uuid.UUID
+	if err := fc.db.Model(&models.SourceArticle{}).Where("post_uri = ? OR original_uri = ?", uri, uri).Distinct().Pluck("article_id", &articleIDs).Error; err != nil {
+		return fmt.Errorf("failed to look up shares of deleted post: %w", err)
+	}
+	if len(articleIDs) == 0 {
+		return nil // Not a post we track
+	}
+
+	var sourceIDs []uuid.UUID
+	if err := fc.db.Model(&models.SourceArticle{}).Where("post_uri = ? OR original_uri = ?", uri, uri).Distinct().Pluck("source_id", &sourceIDs).Error; err != nil {
+		return fmt.Errorf("failed to look up sources of deleted post: %w", err)
+	}
+
+	result := fc.db.Where("post_uri = ? OR original_uri = ?", uri, uri).Delete(&models.SourceArticle{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete shares of deleted post: %w", result.Error)
+	}
+	log.Printf("Removed %d shares of deleted post %s", result.RowsAffected, uri)
+
+	if err := models.AggregateEngagement(fc.db, articleIDs...); err != nil {
+		log.Printf("Failed to aggregate engagement after deleted post %s: %v", uri, err)
+	}
+
+	// The author's source score waits for the next metrics update
+	if err := models.MarkForRescore(fc.db, nil, sourceIDs); err != nil {
+		log.Printf("Failed to flag the source of deleted post %s for rescoring: %v", uri, err)
+	}
+
+	for _, articleID := range articleIDs {
+		if err := fc.rescoreArticle(articleID); err != nil {
+			log.Printf("Failed to rescore article %s: %v", articleID, err)
+		}
+	}
+	return nil
+}
+
+// rescoreArticle recalculates an article's stored scores after its shares changed.
+// It matches QualityScoreService.UpdateSingleArticleScore, which can't be used here
+// because the services package depends on this one.
+func (fc *FirehoseConsumer) rescoreArticle(articleID uuid.UUID) error {
+	return fc.scoreArticle(fc.db, articleID)
+}
+
+// scoreArticle recalculates an article's scores using db, which may be a transaction
+func (fc *FirehoseConsumer) scoreArticle(db *gorm.DB, articleID uuid.UUID) error {
+	var article models.Article
+	if err := db.Preload("SourceArticles.Source").Where("id = ?", articleID).First(&article).Error; err != nil {
+		return err
+	}
+
+	breakdown := ranking.Get(ranking.Default).Explain(article, ranking.Signals{Now: time.Now(), Domain: fc.domains.Lookup(article)})
+
+	return db.Model(&article).Updates(map[string]interface{}{
+		"quality_score":   breakdown.QualityScore,
+		"trending_score":  breakdown.TrendingScore,
+		"score_breakdown": breakdown.Encode(),
+	}).Error
+}
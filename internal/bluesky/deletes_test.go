@@ -0,0 +1,94 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+)
+
+func TestCommitURI(t *testing.T) {
+	event := &JetstreamEvent{
+		DID:    "did:plc:abc",
+		Commit: &JetstreamCommit{Collection: "app.bsky.feed.post", RKey: "3kxyz"},
+	}
+	if uri := commitURI(event); uri != "at://did:plc:abc/app.bsky.feed.post/3kxyz" {
+		t.Errorf("Unexpected URI %q", uri)
+	}
+}
+
+func TestProcessPostDelete(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	article := models.Article{URL: "https://example.com/deleted-share", Title: "Story"}
+	db.Create(&article)
+	kept := models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: "at://" + source.BlueSkyDID + "/app.bsky.feed.post/kept", PostedAt: time.Now()}
+	deleted := models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: "at://" + source.BlueSkyDID + "/app.bsky.feed.post/gone", PostText: "Read this", PostedAt: time.Now()}
+	db.Create(&kept)
+	db.Create(&deleted)
+
+	data, _ := json.Marshal(JetstreamEvent{
+		DID:  source.BlueSkyDID,
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  "delete",
+			Collection: "app.bsky.feed.post",
+			RKey:       "gone",
+		},
+	})
+	if err := consumer.processJetstreamMessage(data); err != nil {
+		t.Fatalf("processJetstreamMessage failed: %v", err)
+	}
+
+	var remaining []models.SourceArticle
+	db.Where("article_id = ?", article.ID).Find(&remaining)
+	if len(remaining) != 1 || remaining[0].PostURI != kept.PostURI {
+		t.Errorf("Expected only the kept share to remain, got %d shares", len(remaining))
+	}
+
+	var rescored models.Article
+	db.First(&rescored, "id = ?", article.ID)
+	if rescored.ScoreBreakdownData == "" {
+		t.Error("Expected the article to be rescored")
+	}
+}
+
+func TestProcessPostDeleteRemovesReposts(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	reposter := models.Source{Handle: "reposter.bsky.social", BlueSkyDID: "did:plc:reposter", IsVerified: true}
+	db.Create(&reposter)
+	consumer := &FirehoseConsumer{db: db}
+
+	article := models.Article{URL: "https://example.com/reposted-then-deleted", Title: "Story"}
+	db.Create(&article)
+	originalURI := "at://" + source.BlueSkyDID + "/app.bsky.feed.post/original"
+	original := models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: originalURI, PostedAt: time.Now()}
+	repost := models.SourceArticle{SourceID: reposter.ID, ArticleID: article.ID, PostURI: "at://did:plc:reposter/app.bsky.feed.repost/r1", ShareType: models.ShareTypeRepost, IsRepost: true, OriginalURI: originalURI, PostedAt: time.Now()}
+	quote := models.SourceArticle{SourceID: reposter.ID, ArticleID: article.ID, PostURI: "at://did:plc:reposter/app.bsky.feed.post/q1", ShareType: models.ShareTypeQuote, OriginalURI: originalURI, PostedAt: time.Now()}
+	db.Create(&original)
+	db.Create(&repost)
+	db.Create(&quote)
+
+	data, _ := json.Marshal(JetstreamEvent{
+		DID:  source.BlueSkyDID,
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  "delete",
+			Collection: "app.bsky.feed.post",
+			RKey:       "original",
+		},
+	})
+	if err := consumer.processJetstreamMessage(data); err != nil {
+		t.Fatalf("processJetstreamMessage failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.SourceArticle{}).Where("article_id = ?", article.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected the post's reposts and quotes to be removed with it, %d shares remain", count)
+	}
+}
@@ -0,0 +1,67 @@
+// Package fake serves the Bluesky XRPC endpoints the client uses from fixtures,
+// so tests can exercise code built on bluesky.Client without network access or
+// credentials.
+package fake
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"open-news/internal/bluesky"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+// Fixtures are the accounts a Server knows about
+type Fixtures struct {
+	Accounts []Account `json:"accounts"`
+}
+
+// Account is a Bluesky account: its profile, the password that signs in as it,
+// the accounts it follows and its author feed
+type Account struct {
+	bluesky.Author
+	Password string                 `json:"password,omitempty"` // Empty accounts can't sign in
+	Follows  []string               `json:"follows,omitempty"`  // Handles or DIDs of followed accounts, in listing order
+	Feed     []bluesky.FeedViewPost `json:"feed,omitempty"`     // Newest first; posts without an author are the account's own
+}
+
+// DefaultFixtures returns a reader account following two news sources and a
+// personal account, each source with posts linking to articles. Every password
+// is "password".
+func DefaultFixtures() *Fixtures {
+	fixtures, err := LoadFixtures(bytes.NewReader(defaultFixtures))
+	if err != nil {
+		panic(fmt.Sprintf("fake: invalid default fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// LoadFixtures reads fixtures written as JSON
+func LoadFixtures(r io.Reader) (*Fixtures, error) {
+	var fixtures Fixtures
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("failed to decode fixtures: %w", err)
+	}
+	for i, account := range fixtures.Accounts {
+		if account.DID == "" || account.Handle == "" {
+			return nil, fmt.Errorf("account %d needs a did and a handle", i+1)
+		}
+	}
+	return &fixtures, nil
+}
+
+// LoadFixturesFile reads fixtures from a JSON file
+func LoadFixturesFile(path string) (*Fixtures, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadFixtures(file)
+}
@@ -0,0 +1,350 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"open-news/internal/bluesky"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// Server is a Bluesky AppView and PDS on a local httptest server. It implements
+// createSession, resolveHandle, getProfile, getProfiles, getFollows,
+// getAuthorFeed and getPosts over its accounts, and counts the requests to each.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts []*Account
+	sessions map[string]string // Access token to DID
+	requests map[string]int    // Requests by XRPC method
+}
+
+// NewServer starts a server with the accounts in fixtures, which may be nil.
+// Close it when done.
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{
+		sessions: make(map[string]string),
+		requests: make(map[string]int),
+	}
+	if fixtures != nil {
+		for _, account := range fixtures.Accounts {
+			s.AddAccount(account)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/xrpc/com.atproto.server.createSession", s.createSession)
+	mux.HandleFunc("/xrpc/com.atproto.identity.resolveHandle", s.resolveHandle)
+	mux.HandleFunc("/xrpc/app.bsky.actor.getProfile", s.getProfile)
+	mux.HandleFunc("/xrpc/app.bsky.actor.getProfiles", s.getProfiles)
+	mux.HandleFunc("/xrpc/app.bsky.graph.getFollows", s.getFollows)
+	mux.HandleFunc("/xrpc/app.bsky.feed.getAuthorFeed", s.getAuthorFeed)
+	mux.HandleFunc("/xrpc/app.bsky.feed.getPosts", s.getPosts)
+	s.Server = httptest.NewServer(s.count(mux))
+	return s
+}
+
+// Client returns a Bluesky client for the server, not signed in
+func (s *Server) Client() *bluesky.Client {
+	return bluesky.NewClient(s.URL)
+}
+
+// AddAccount adds an account, replacing one with the same DID. Posts in its
+// feed without an author are given the account as author.
+func (s *Server) AddAccount(account Account) {
+	account.Feed = append([]bluesky.FeedViewPost(nil), account.Feed...)
+	for i := range account.Feed {
+		if account.Feed[i].Post.Author.DID == "" {
+			account.Feed[i].Post.Author = account.profile()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.accounts {
+		if existing.DID == account.DID {
+			s.accounts[i] = &account
+			return
+		}
+	}
+	s.accounts = append(s.accounts, &account)
+}
+
+// Requests returns how many requests were made to an XRPC method, such as
+// app.bsky.graph.getFollows
+func (s *Server) Requests(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method]
+}
+
+// count records each request's XRPC method before serving it
+func (s *Server) count(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[strings.TrimPrefix(r.URL.Path, "/xrpc/")]++
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// profile returns the account's profile, counting its follows and posts when
+// the fixture doesn't set them
+func (a *Account) profile() bluesky.Author {
+	profile := a.Author
+	if profile.FollowsCount == 0 {
+		profile.FollowsCount = len(a.Follows)
+	}
+	if profile.PostsCount == 0 {
+		profile.PostsCount = len(a.Feed)
+	}
+	return profile
+}
+
+// lookup finds an account by handle or DID. The caller holds s.mu.
+func (s *Server) lookup(actor string) *Account {
+	actor = strings.TrimPrefix(actor, "@")
+	for _, account := range s.accounts {
+		if account.DID == actor || strings.EqualFold(account.Handle, actor) {
+			return account
+		}
+	}
+	return nil
+}
+
+// authorize checks the bearer token of a request, returning the signed in DID,
+// or "" when the request has none. An unknown token is answered with 401 and
+// ok is false.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) (did string, ok bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return "", true
+	}
+	s.mu.Lock()
+	did, ok = s.sessions[token]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "InvalidToken", "Token could not be verified")
+	}
+	return did, ok
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "InvalidRequest", "Method not allowed")
+		return
+	}
+	var body struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := s.lookup(body.Identifier)
+	if account == nil || account.Password == "" || account.Password != body.Password {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+		return
+	}
+	token := "fake-access-" + account.DID + "-" + strconv.Itoa(len(s.sessions)+1)
+	s.sessions[token] = account.DID
+	writeJSON(w, bluesky.Session{
+		AccessJWT:  token,
+		RefreshJWT: "fake-refresh-" + account.DID,
+		DID:        account.DID,
+		Handle:     account.Handle,
+	})
+}
+
+func (s *Server) resolveHandle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handle := r.URL.Query().Get("handle")
+	account := s.lookup(handle)
+	if account == nil || strings.HasPrefix(handle, "did:") {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Unable to resolve handle")
+		return
+	}
+	writeJSON(w, map[string]string{"did": account.DID})
+}
+
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r); !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := s.lookup(r.URL.Query().Get("actor"))
+	if account == nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+	writeJSON(w, account.profile())
+}
+
+func (s *Server) getProfiles(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r); !ok {
+		return
+	}
+	actors := r.URL.Query()["actors"]
+	if len(actors) > bluesky.MaxProfilesPerRequest {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Too many actors")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profiles := []bluesky.Author{}
+	for _, actor := range actors {
+		if account := s.lookup(actor); account != nil {
+			profiles = append(profiles, account.profile())
+		}
+	}
+	writeJSON(w, map[string]interface{}{"profiles": profiles})
+}
+
+func (s *Server) getFollows(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r); !ok {
+		return
+	}
+	start, end, ok := page(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := s.lookup(r.URL.Query().Get("actor"))
+	if account == nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+
+	response := bluesky.FollowsResponse{Subject: account.profile(), Follows: []bluesky.Author{}}
+	for i := start; i < end && i < len(account.Follows); i++ {
+		if followed := s.lookup(account.Follows[i]); followed != nil {
+			profile := followed.profile()
+			// Listings don't include counts
+			profile.FollowersCount, profile.FollowsCount, profile.PostsCount = 0, 0, 0
+			response.Follows = append(response.Follows, profile)
+		}
+	}
+	if end < len(account.Follows) {
+		response.Cursor = strconv.Itoa(end)
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) getAuthorFeed(w http.ResponseWriter, r *http.Request) {
+	did, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+	if did == "" {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Authentication Required")
+		return
+	}
+	start, end, ok := page(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := s.lookup(r.URL.Query().Get("actor"))
+	if account == nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+
+	response := bluesky.AuthorFeedResponse{Feed: []bluesky.FeedViewPost{}}
+	if start < len(account.Feed) {
+		response.Feed = account.Feed[start:min(end, len(account.Feed))]
+	}
+	if end < len(account.Feed) {
+		response.Cursor = strconv.Itoa(end)
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) getPosts(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.authorize(w, r); !ok {
+		return
+	}
+	uris := r.URL.Query()["uris"]
+	if len(uris) > 25 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Too many uris")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := []bluesky.Post{}
+	for _, uri := range uris {
+		if post := s.findPost(uri); post != nil {
+			posts = append(posts, *post)
+		}
+	}
+	writeJSON(w, map[string]interface{}{"posts": posts})
+}
+
+// findPost finds a post in any author feed by its AT URI. The caller holds s.mu.
+func (s *Server) findPost(uri string) *bluesky.Post {
+	for _, account := range s.accounts {
+		for i := range account.Feed {
+			if account.Feed[i].Post.URI == uri {
+				return &account.Feed[i].Post
+			}
+		}
+	}
+	return nil
+}
+
+// page reads the limit and cursor of a paginated request. Cursors are the
+// offset of the next item.
+func page(w http.ResponseWriter, r *http.Request) (start, end int, ok bool) {
+	limit := defaultPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "limit must be between 1 and 100")
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		parsed, err := strconv.Atoi(cursor)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "Malformed cursor")
+			return 0, 0, false
+		}
+		start = parsed
+	}
+	return start, start + limit, true
+}
+
+// writeJSON writes a 200 response with value as its body
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an XRPC error response
+func writeError(w http.ResponseWriter, status int, name, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": name, "message": message})
+}
@@ -0,0 +1,120 @@
+package fake
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"open-news/internal/bluesky"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+	client := server.Client()
+
+	if err := client.CreateSession("reader.test", "wrong"); err == nil {
+		t.Error("Expected a wrong password to be rejected")
+	}
+	if _, err := client.GetAuthorFeedPage("news.test", 10, ""); err == nil {
+		t.Error("Expected getAuthorFeed to need a session")
+	}
+	if err := client.CreateSession("reader.test", "password"); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	// Follows are listed a page at a time
+	var follows []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("Expected the follows to end")
+		}
+		response, err := client.GetFollows("did:plc:fakereader", 2, cursor)
+		if err != nil {
+			t.Fatalf("GetFollows failed: %v", err)
+		}
+		for _, follow := range response.Follows {
+			follows = append(follows, follow.Handle)
+		}
+		if response.Cursor == "" {
+			break
+		}
+		cursor = response.Cursor
+	}
+	if strings.Join(follows, ",") != "news.test,wire.test,friend.test" {
+		t.Errorf("Expected the reader's follows in order, got %v", follows)
+	}
+	if got := server.Requests("app.bsky.graph.getFollows"); got != 2 {
+		t.Errorf("Expected 2 getFollows requests, got %d", got)
+	}
+
+	profile, err := client.GetProfile("news.test")
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if profile.DID != "did:plc:fakenews" || profile.FollowersCount != 125000 || profile.PostsCount != 2 {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+	if _, err := client.GetProfile("missing.test"); err == nil {
+		t.Error("Expected a missing profile to fail")
+	}
+	profiles, err := client.GetProfiles([]string{"did:plc:fakewire", "missing.test", "friend.test"})
+	if err != nil || len(profiles) != 2 {
+		t.Errorf("Expected 2 profiles, got %d (%v)", len(profiles), err)
+	}
+	if did, err := client.ResolveHandle("wire.test"); err != nil || did != "did:plc:fakewire" {
+		t.Errorf("Expected wire.test to resolve, got %q (%v)", did, err)
+	}
+
+	feed, err := client.GetAuthorFeedPage("wire.test", 1, "")
+	if err != nil {
+		t.Fatalf("GetAuthorFeedPage failed: %v", err)
+	}
+	if len(feed.Feed) != 1 || feed.Feed[0].Reason == nil || feed.Cursor == "" {
+		t.Fatalf("Expected the repost first with a cursor, got %+v", feed)
+	}
+	feed, _ = client.GetAuthorFeedPage("wire.test", 1, feed.Cursor)
+	if len(feed.Feed) != 1 || feed.Feed[0].Post.Author.Handle != "wire.test" || feed.Cursor != "" {
+		t.Errorf("Expected the wire's own post on the last page, got %+v", feed)
+	}
+	if links := bluesky.ExtractLinks(&feed.Feed[0].Post); len(links) != 1 {
+		t.Errorf("Expected the post to link to an article, got %v", links)
+	}
+
+	posts, err := client.GetPosts([]string{"at://did:plc:fakenews/app.bsky.feed.post/3kfakenews1"})
+	if err != nil || len(posts) != 1 || posts[0].LikeCount != 95 {
+		t.Errorf("Expected the post to be found, got %+v (%v)", posts, err)
+	}
+}
+
+func TestServerInvalidToken(t *testing.T) {
+	server := NewServer(nil)
+	defer server.Close()
+	server.AddAccount(Account{Author: bluesky.Author{DID: "did:plc:added", Handle: "added.test"}})
+
+	req, _ := http.NewRequest("GET", server.URL+"/xrpc/app.bsky.actor.getProfile?actor=added.test", nil)
+	req.Header.Set("Authorization", "Bearer forged")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown token to be rejected, got %s", resp.Status)
+	}
+
+	if profile, err := server.Client().GetProfile("added.test"); err != nil || profile.DID != "did:plc:added" {
+		t.Errorf("Expected the added account, got %+v (%v)", profile, err)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	if _, err := LoadFixtures(strings.NewReader(`{"accounts":[{"handle":"nodid.test"}]}`)); err == nil {
+		t.Error("Expected an account without a DID to be rejected")
+	}
+	fixtures := DefaultFixtures()
+	if len(fixtures.Accounts) != 4 {
+		t.Errorf("Expected 4 default accounts, got %d", len(fixtures.Accounts))
+	}
+}
@@ -3,19 +3,29 @@ package bluesky
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"golang.org/x/net/html"
-	"io"
 	"log"
-	"net/http"
 	"net/url"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"open-news/internal/cache"
+	"open-news/internal/domains"
+	"open-news/internal/errreport"
+	"open-news/internal/fetcher"
 	"open-news/internal/metadata"
 	"open-news/internal/models"
+	"open-news/internal/ranking"
+	"open-news/internal/topics"
+	"open-news/internal/tracing"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +35,23 @@ type FirehoseConsumer struct {
 	client            *Client
 	dialer            *websocket.Dialer
 	metadataExtractor *metadata.MetadataExtractor
+	pageFetcher       *fetcher.Fetcher
+	domains           *domains.Registry           // Site reputations used when rescoring, and per-site schema requirements
+	schemaRequirement metadata.SchemaRequirement  // How pages of sites without their own requirement are checked for a NewsArticle schema
+	skipSensitive     bool                        // Drop shares in posts with a sensitive label instead of flagging them
+	replyLinks        ranking.ReplyLinkPolicy     // Whether links in replies from untrusted sources are skipped
+	shares            *shareWriter                // Buffers new shares while consuming; nil writes them right away
+	sources           *sourceSet                  // Source DIDs matched in memory while consuming; nil looks up every account
+	ingestConfig      ingestConfig                // Queue size and lag alert thresholds
+	ingest            atomic.Pointer[ingestQueue] // Messages waiting to be processed while consuming, also read by status handlers; nil processes them as they're read
+	canonicalURLs     cache.Cache                 // Canonical URLs of AMP and mobile links, so each is fetched once; nil fetches them every time
+}
+
+// newValidationFetcher creates the fetcher used for the quick NewsArticle check
+func newValidationFetcher() *fetcher.Fetcher {
+	options := fetcher.DefaultOptions()
+	options.Timeout = 10 * time.Second
+	return fetcher.NewFetcher(options)
 }
 
 // NewFirehoseConsumer creates a new firehose consumer
@@ -34,6 +61,13 @@ func NewFirehoseConsumer(db *gorm.DB, client *Client) *FirehoseConsumer {
 		client:            client,
 		dialer:            websocket.DefaultDialer,
 		metadataExtractor: metadata.NewMetadataExtractor(),
+		pageFetcher:       newValidationFetcher(),
+		domains:           domains.NewRegistry(db),
+		schemaRequirement: metadata.LoadSchemaRequirement(),
+		skipSensitive:     os.Getenv("SKIP_SENSITIVE_POSTS") == "true",
+		replyLinks:        ranking.ReplyLinks(),
+		ingestConfig:      loadIngestConfig(),
+		canonicalURLs:     cache.Shared(),
 	}
 }
 
@@ -60,6 +94,7 @@ type JetstreamCommit struct {
 // JetstreamAccount represents an account event
 type JetstreamAccount struct {
 	Active bool      `json:"active"`
+	Status string    `json:"status,omitempty"` // Why an inactive account is inactive: deactivated, deleted, suspended or takendown
 	DID    string    `json:"did"`
 	Seq    int64     `json:"seq"`
 	Time   time.Time `json:"time"`
@@ -75,13 +110,14 @@ type JetstreamIdentity struct {
 
 // PostRecord represents a post record from Jetstream
 type PostRecord struct {
-	Type      string    `json:"$type"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"createdAt"`
-	Facets    []Facet   `json:"facets,omitempty"`
-	Embed     *Embed    `json:"embed,omitempty"`
-	Reply     *Reply    `json:"reply,omitempty"`
-	Langs     []string  `json:"langs,omitempty"`
+	Type      string      `json:"$type"`
+	Text      string      `json:"text"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Facets    []Facet     `json:"facets,omitempty"`
+	Embed     *Embed      `json:"embed,omitempty"`
+	Reply     *Reply      `json:"reply,omitempty"`
+	Langs     []string    `json:"langs,omitempty"`
+	Labels    *SelfLabels `json:"labels,omitempty"` // Labels the author put on the post
 }
 
 // Reply represents reply information
@@ -96,20 +132,82 @@ type RecordRef struct {
 	CID string `json:"cid"`
 }
 
+// wantedDIDsRefreshInterval is how often the followed source DIDs are reloaded
+// and, when they changed, sent to Jetstream
+const wantedDIDsRefreshInterval = time.Minute
+
+// jetstreamURL returns the Jetstream subscribe URL. Jetstream is used instead of
+// the raw firehose, and requireHello holds events back until the options_update
+// naming the followed source DIDs has been sent.
+func jetstreamURL() string {
+	query := url.Values{"wantedCollections": jetstreamCollections, "requireHello": {"true"}}
+	return "wss://jetstream2.us-east.bsky.network/subscribe?" + query.Encode()
+}
+
 // StartConsuming starts consuming the Bluesky Jetstream
 func (fc *FirehoseConsumer) StartConsuming(ctx context.Context) error {
-	// Use Jetstream endpoint instead of raw firehose
-	jetstreamURL := "wss://jetstream2.us-east.bsky.network/subscribe?wantedCollections=app.bsky.feed.post"
+	subscribeURL := jetstreamURL()
+	log.Printf("Connecting to Bluesky Jetstream: %s", subscribeURL)
+
+	// Shares are written in batches while consuming, and the last batch is
+	// written before returning, once the last message has been processed
+	ctx, stop := context.WithCancel(ctx)
+	sharesCtx, stopShares := context.WithCancel(context.Background())
+	fc.shares = newShareWriter(fc.db)
+	flushed := make(chan struct{})
+	go func() {
+		fc.shares.run(sharesCtx)
+		close(flushed)
+	}()
+
+	// Posts are matched to sources in memory. If the sources can't be loaded,
+	// every post's author is looked up instead. The set is in place before
+	// processing starts and cleared only once processing has stopped.
+	sources := newSourceSet(fc.db)
+	if err := sources.reload(); err != nil {
+		log.Printf("Failed to load source DIDs, looking up each post's author: %v", err)
+	} else {
+		log.Printf("Matching posts from %d sources", sources.size())
+		fc.sources = sources
+		go sources.run(ctx)
+	}
 
-	log.Printf("Connecting to Bluesky Jetstream: %s", jetstreamURL)
+	// Messages are read into a bounded queue and processed on their own
+	// goroutine, so a slow database holds the reader back and then drops
+	// messages instead of silently falling behind
+	ingest := newIngestQueue(fc.ingestConfig)
+	fc.ingest.Store(ingest)
+	processed := make(chan struct{})
+	go func() {
+		ingest.process(ctx, fc.processJetstreamMessage)
+		close(processed)
+	}()
+	go ingest.monitor(ctx)
+
+	defer func() {
+		stop()
+		<-processed
+		fc.ingest.Store(nil)
+		fc.sources = nil
+		stopShares()
+		<-flushed
+		fc.shares = nil
+	}()
+
+	return consumeWithRetry(ctx, func() error {
+		return fc.connectAndConsume(ctx, subscribeURL)
+	})
+}
 
-	// Retry logic for connection
+// consumeWithRetry runs connect until the context is cancelled, reconnecting
+// 10 seconds after each connection error
+func consumeWithRetry(ctx context.Context, connect func() error) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			if err := fc.connectAndConsume(ctx, jetstreamURL); err != nil {
+			if err := connect(); err != nil {
 				log.Printf("Jetstream connection error: %v. Reconnecting in 10 seconds...", err)
 
 				// Wait before reconnecting
@@ -134,6 +232,42 @@ func (fc *FirehoseConsumer) connectAndConsume(ctx context.Context, jetstreamURL
 
 	log.Println("Successfully connected to Bluesky Jetstream")
 
+	// The ping and DID refresh goroutines write alongside each other
+	var writeMu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteMessage(messageType, data)
+	}
+
+	dids, err := fc.loadWantedDIDs()
+	if err != nil {
+		log.Printf("Failed to load source DIDs, receiving all posts: %v", err)
+	}
+	hello, err := optionsUpdateMessage(dids)
+	if err != nil {
+		return fmt.Errorf("failed to encode Jetstream options: %w", err)
+	}
+	if err := write(websocket.TextMessage, hello); err != nil {
+		return fmt.Errorf("failed to send Jetstream options: %w", err)
+	}
+	log.Printf("Subscribed to posts from %d source DIDs", len(dids))
+
+	done := make(chan struct{})
+	defer close(done)
+	go fc.refreshWantedDIDs(ctx, done, dids, write)
+
+	return readMessages(ctx, conn, write, func(message []byte) error {
+		if ingest := fc.ingest.Load(); ingest != nil {
+			return ingest.enqueue(ctx, message)
+		}
+		return fc.processJetstreamMessage(message)
+	})
+}
+
+// readMessages keeps a Jetstream connection alive with pings and passes each message
+// to handle until the connection fails or the context is cancelled
+func readMessages(ctx context.Context, conn *websocket.Conn, write func(int, []byte) error, handle func([]byte) error) error {
 	// Set up ping/pong handler to keep connection alive
 	conn.SetPongHandler(func(string) error {
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -149,7 +283,7 @@ func (fc *FirehoseConsumer) connectAndConsume(ctx context.Context, jetstreamURL
 		for {
 			select {
 			case <-ticker.C:
-				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				if err := write(websocket.PingMessage, nil); err != nil {
 					log.Printf("Failed to send ping: %v", err)
 					return
 				}
@@ -172,7 +306,7 @@ func (fc *FirehoseConsumer) connectAndConsume(ctx context.Context, jetstreamURL
 				return fmt.Errorf("failed to read message: %w", err)
 			}
 
-			if err := fc.processJetstreamMessage(message); err != nil {
+			if err := handle(message); err != nil {
 				log.Printf("Error processing Jetstream message: %v", err)
 				// Continue processing other messages even if one fails
 			}
@@ -180,19 +314,70 @@ func (fc *FirehoseConsumer) connectAndConsume(ctx context.Context, jetstreamURL
 	}
 }
 
+// refreshWantedDIDs periodically reloads the followed source DIDs and updates the
+// subscription filter on the open connection when they change
+func (fc *FirehoseConsumer) refreshWantedDIDs(ctx context.Context, done <-chan struct{}, current []string, write func(int, []byte) error) {
+	ticker := time.NewTicker(wantedDIDsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			dids, err := fc.loadWantedDIDs()
+			if err != nil {
+				log.Printf("Failed to reload source DIDs: %v", err)
+				continue
+			}
+			if sameDIDs(dids, current) {
+				continue
+			}
+
+			message, err := optionsUpdateMessage(dids)
+			if err != nil {
+				log.Printf("Failed to encode Jetstream options: %v", err)
+				continue
+			}
+			if err := write(websocket.TextMessage, message); err != nil {
+				log.Printf("Failed to update Jetstream options: %v", err)
+				return
+			}
+			log.Printf("Updated Jetstream subscription: %d source DIDs (was %d)", len(dids), len(current))
+			current = dids
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // processJetstreamMessage processes a single message from Jetstream
 func (fc *FirehoseConsumer) processJetstreamMessage(data []byte) error {
 	var event JetstreamEvent
 	if err := json.Unmarshal(data, &event); err != nil {
 		return fmt.Errorf("failed to unmarshal Jetstream event: %w", err)
 	}
+	if ingest := fc.ingest.Load(); ingest != nil {
+		ingest.observe(event.TimeUS, time.Now())
+	}
 
-	// Only process commit events for posts
-	if event.Kind == "commit" && event.Commit != nil &&
-		event.Commit.Collection == "app.bsky.feed.post" &&
-		event.Commit.Operation == "create" {
-
+	switch {
+	case event.Kind == "commit" && event.Commit != nil &&
+		event.Commit.Collection == jetstreamPostCollection &&
+		event.Commit.Operation == "create":
 		return fc.processPostCommit(&event)
+	case event.Kind == "commit" && event.Commit != nil &&
+		event.Commit.Collection == jetstreamRepostCollection &&
+		event.Commit.Operation == "create":
+		return fc.processRepostCommit(&event)
+	case event.Kind == "commit" && event.Commit != nil &&
+		(event.Commit.Collection == jetstreamPostCollection || event.Commit.Collection == jetstreamRepostCollection) &&
+		event.Commit.Operation == "delete":
+		return fc.processPostDelete(&event)
+	case event.Kind == "account" && event.Account != nil:
+		return fc.processAccountEvent(event.Account)
+	case event.Kind == "identity" && event.Identity != nil:
+		return fc.processIdentityEvent(event.Identity)
 	}
 
 	return nil
@@ -213,24 +398,54 @@ func (fc *FirehoseConsumer) processPostCommit(event *JetstreamEvent) error {
 
 	// Extract links from the post
 	links := fc.extractLinksFromPost(&postRecord)
-	if len(links) == 0 {
+	quotedURI := postRecord.Embed.QuotedURI()
+	if len(links) == 0 && quotedURI == "" {
 		return nil // No links to process
 	}
 
 	// Check if this DID belongs to a source we're following
-	var source models.Source
-	result := fc.db.Where("blue_sky_d_id = ?", event.DID).First(&source)
-	if result.Error != nil {
+	source := fc.lookupSource(event.DID)
+	if source == nil {
 		// This source is not in our database, skip
 		return nil
 	}
 
-	log.Printf("Found post with links from followed source %s: %v", source.Handle, links)
+	// Links dropped in reply threads are left out with REPLY_LINKS=skip, unless
+	// the source is trusted
+	if postRecord.Reply != nil && fc.replyLinks.Skips(*source) {
+		log.Printf("Skipping links in a reply by %s (REPLY_LINKS=skip)", source.Handle)
+		return nil
+	}
+
+	if len(links) > 0 {
+		log.Printf("Found post with links from followed source %s: %v", source.Handle, links)
+	}
 
-	// Process each link in the post
+	// Process each link in the post, each in a trace of its own
 	for _, link := range links {
-		if err := fc.processLink(link, &source, &postRecord, event); err != nil {
+		ctx, span := tracing.Start(context.Background(), "firehose link",
+			attribute.String("url.full", link),
+			attribute.String("source.did", event.DID),
+		)
+		err := fc.processLink(ctx, link, source, &postRecord, event)
+		tracing.End(span, err)
+		if err != nil {
 			log.Printf("Error processing link %s: %v", link, err)
+			errreport.Capture(err, errreport.Tags{"article.url": link, "source.did": event.DID})
+		}
+	}
+
+	// A quote shares whatever the quoted post links to
+	if quotedURI != "" {
+		ctx, span := tracing.Start(context.Background(), "firehose quote",
+			attribute.String("post.uri", quotedURI),
+			attribute.String("source.did", event.DID),
+		)
+		err := fc.processQuote(ctx, source, &postRecord, event, quotedURI)
+		tracing.End(span, err)
+		if err != nil {
+			log.Printf("Error processing quoted post %s: %v", quotedURI, err)
+			errreport.Capture(err, errreport.Tags{"post.uri": quotedURI, "source.did": event.DID})
 		}
 	}
 
@@ -250,9 +465,9 @@ func (fc *FirehoseConsumer) extractLinksFromPost(post *PostRecord) []string {
 		}
 	}
 
-	// Extract from external embeds
-	if post.Embed != nil && post.Embed.External != nil {
-		links = append(links, post.Embed.External.URI)
+	// Extract from external embeds, including link cards shown alongside a quote
+	if uri := post.Embed.ExternalURI(); uri != "" {
+		links = append(links, uri)
 	}
 
 	// Simple URL extraction from text as fallback
@@ -282,35 +497,115 @@ func (fc *FirehoseConsumer) extractLinksFromPost(post *PostRecord) []string {
 	return uniqueLinks
 }
 
-// processLink processes a single article link from a post
-func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, post *PostRecord, event *JetstreamEvent) error {
+// processLink processes a single article link from a post, running its fetches
+// and queries with ctx
+func (fc *FirehoseConsumer) processLink(ctx context.Context, linkURL string, source *models.Source, post *PostRecord, event *JetstreamEvent) error {
+	article, err := fc.resolveArticle(ctx, linkURL, source.BlueSkyDID)
+	if err != nil || article == nil {
+		return err
+	}
+
+	return fc.recordShare(ctx, source, article, share{
+		PostURI:   fmt.Sprintf("at://%s/%s/%s", event.DID, jetstreamPostCollection, event.Commit.RKey),
+		PostCID:   event.Commit.CID,
+		Text:      post.Text,
+		ShareType: ShareType(post.Reply, post.Embed),
+		PostedAt:  post.CreatedAt,
+		Labels:    labelValues(post.Labels, nil),
+	})
+}
+
+// canonicalURLTTL is how long the canonical URL of an AMP or mobile link is reused
+const canonicalURLTTL = 24 * time.Hour
+
+// desktopURL returns the canonical page an AMP or mobile link is a version of.
+// AMP cache links are decoded; other AMP and mobile pages are fetched for the
+// <link rel="canonical"> they name, or the URL they redirect to. Other links,
+// and pages that fail to fetch, are returned as they are.
+func (fc *FirehoseConsumer) desktopURL(ctx context.Context, linkURL string) string {
+	if origin, ok := metadata.AMPCacheOrigin(linkURL); ok {
+		linkURL = origin
+	}
+	if !metadata.IsAlternateURL(linkURL) {
+		return linkURL
+	}
+
+	cacheKey := "firehose:canonical:" + linkURL
+	if fc.canonicalURLs != nil {
+		if cached, ok := fc.canonicalURLs.Get(cacheKey); ok {
+			return string(cached)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	page, err := fc.pageFetcher.FetchHTML(ctx, linkURL)
+	if err != nil {
+		return linkURL
+	}
+	desktop := metadata.DesktopURL(page.Root, page.URL)
+	if !metadata.SameSite(desktop, linkURL) {
+		desktop = linkURL
+	}
+	if desktop != linkURL {
+		log.Printf("Resolved %s to its canonical page %s", linkURL, desktop)
+	}
+	if fc.canonicalURLs != nil {
+		fc.canonicalURLs.Set(cacheKey, []byte(desktop), canonicalURLTTL)
+	}
+	return desktop
+}
+
+// share describes a post or repost by a source that links to an article
+type share struct {
+	PostURI     string
+	PostCID     string
+	Text        string
+	ShareType   string // See the models.ShareType* constants; empty is an original post
+	OriginalURI string // For reposts and quotes, the reposted or quoted post
+	PostedAt    time.Time
+	Labels      []string // Label values on the post
+}
+
+// resolveArticle returns the stored article for a link, refreshing stale metadata, or
+// a new article when the page is a NewsArticle. New articles aren't stored yet and have
+// no ID: recordShare stores them together with their first share. It returns nil when
+// the link isn't tracked. sharedBy is the DID of the account whose post links to
+// it, reported with pages that keep failing to fetch.
+func (fc *FirehoseConsumer) resolveArticle(ctx context.Context, linkURL, sharedBy string) (*models.Article, error) {
 	// Validate and normalize URL
 	parsedURL, err := url.Parse(linkURL)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Skip non-HTTP(S) URLs
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return nil
+		return nil, nil
 	}
 
-	canonicalURL := parsedURL.String()
+	if fc.domains.OptedOut(parsedURL.String()) {
+		return nil, nil // The publisher asked for their pages not to be fetched
+	}
+
+	// AMP and mobile pages are stored under the page they're a version of
+	canonicalURL := fc.desktopURL(ctx, parsedURL.String())
 
 	// Check if article already exists
+	db := fc.db.WithContext(ctx)
 	var article models.Article
-	err = fc.db.Where("url = ?", canonicalURL).First(&article).Error
+	err = db.Where("url = ?", canonicalURL).First(&article).Error
 
 	if err == gorm.ErrRecordNotFound {
 		// Article doesn't exist, first check if it's a NewsArticle
 		log.Printf("New article discovered, checking if it's a NewsArticle: %s", canonicalURL)
 		
 		// Create context for NewsArticle validation
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
 		
 		// Check if the URL contains NewsArticle schema
-		isNewsArticle, validationErr := fc.checkIfNewsArticle(ctx, canonicalURL)
+		isNewsArticle, validationErr := fc.checkIfNewsArticle(checkCtx, canonicalURL)
 		
 		// Handle different types of errors
 		if validationErr != nil {
@@ -330,23 +625,19 @@ func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, p
 					LastFetchAt:    &[]time.Time{time.Now()}[0],
 				}
 				
-				if err := fc.db.Create(&article).Error; err != nil {
-					return fmt.Errorf("failed to create unreachable article: %w", err)
-				}
-				
-				log.Printf("Stored unreachable article for background processing: %s", canonicalURL)
+				log.Printf("Unreachable article will be stored for background processing: %s", canonicalURL)
 			} else {
 				log.Printf("Content validation failed (likely not a news article), skipping: %s", canonicalURL)
-				return nil // Skip this article - it's not a valid news article
+				return nil, nil // Skip this article - it's not a valid news article
 			}
 		} else if !isNewsArticle {
 			log.Printf("Skipping URL (not a NewsArticle): %s", canonicalURL)
-			return nil // Skip this article
+			return nil, nil // Skip this article
 		} else {
 			log.Printf("Confirmed as NewsArticle, extracting metadata: %s", canonicalURL)
 			
 			// Create context for metadata extraction
-			ctx2, cancel2 := context.WithTimeout(context.Background(), 30*time.Second)
+			ctx2, cancel2 := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel2()
 			
 			// Extract metadata from the URL
@@ -369,36 +660,40 @@ func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, p
 			} else {
 				// Create article with extracted metadata
 				article = models.Article{
-					URL:          canonicalURL,
-					Title:        metadata.Title,
-					Description:  metadata.Description,
-					Author:       metadata.Author,
-					SiteName:     metadata.SiteName,
-					ImageURL:     metadata.ImageURL,
-					PublishedAt:  metadata.PublishedAt,
-					JSONLDData:   metadata.JSONLDData,
-					OGData:       metadata.OGData,
-					HTMLContent:  metadata.HTMLContent,
-					TextContent:  metadata.TextContent,
-					WordCount:    int(metadata.WordCount),
-					ReadingTime:  int(metadata.ReadingTime),
-					Language:     metadata.Language,
-					IsCached:     true,
-					IsReachable:  true,
-					CachedAt:     &now,
-					LastFetchAt:  &now,
-					CreatedAt:    time.Now(),
+					URL:            canonicalURL,
+					Title:          metadata.Title,
+					Description:    metadata.Description,
+					Author:         metadata.Author,
+					Authors:        metadata.Authors,
+					SiteName:       metadata.SiteName,
+					ImageURL:       metadata.ImageURL,
+					PublishedAt:    metadata.PublishedAt,
+					DateConfidence: string(metadata.DateConfidence),
+					JSONLDData:     metadata.JSONLDData,
+					SchemaType:     metadata.SchemaType,
+					OGData:         metadata.OGData,
+					HTMLContent:    metadata.HTMLContent,
+					TextContent:    metadata.TextContent,
+					WordCount:      int(metadata.WordCount),
+					ReadingTime:    int(metadata.ReadingTime),
+					ReadingGrade:   metadata.ReadingGrade,
+					Sentiment:      metadata.Sentiment,
+					Language:       metadata.Language,
+					NoArchive:      metadata.NoArchive,
+					IsCached:       !metadata.NoArchive,
+					IsReachable:    true,
+					CachedAt:       &now,
+					LastFetchAt:    &now,
+					CreatedAt:      time.Now(),
 				}
+				fc.classifyTopics(&article)
 			}
-			
-			if err := fc.db.Create(&article).Error; err != nil {
-				return fmt.Errorf("failed to create article: %w", err)
-			}
-
-			log.Printf("New NewsArticle created with metadata: %s (title: %s)", canonicalURL, article.Title)
 		}
 	} else if err != nil {
-		return fmt.Errorf("failed to query article: %w", err)
+		return nil, fmt.Errorf("failed to query article: %w", err)
+	} else if article.IsNotNews {
+		log.Printf("Skipping URL (marked not news): %s", canonicalURL)
+		return nil, nil
 	} else {
 		// Article exists - check if we should refresh metadata for unreachable articles
 		// or articles that haven't been fetched recently
@@ -424,11 +719,11 @@ func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, p
 			log.Printf("Refreshing metadata for existing article: %s", canonicalURL)
 			
 			// Create context for metadata extraction
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			refreshCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
 			
 			// Extract metadata from the URL
-			metadata, err := fc.metadataExtractor.ExtractMetadata(ctx, canonicalURL)
+			metadata, err := fc.metadataExtractor.ExtractMetadata(refreshCtx, canonicalURL)
 			
 			if err != nil {
 				log.Printf("Failed to refresh metadata for %s: %v", canonicalURL, err)
@@ -438,30 +733,38 @@ func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, p
 				article.FetchRetries++
 				article.LastFetchError = &now
 				article.LastFetchAt = &now
+				reportFetchFailure(&article, sharedBy, err)
 			} else {
 				// Update article with refreshed metadata
 				article.Title = metadata.Title
 				article.Description = metadata.Description
 				article.Author = metadata.Author
+				article.Authors = metadata.Authors
 				article.SiteName = metadata.SiteName
 				article.ImageURL = metadata.ImageURL
 				article.PublishedAt = metadata.PublishedAt
+				article.DateConfidence = string(metadata.DateConfidence)
 				article.JSONLDData = metadata.JSONLDData
+				article.SchemaType = metadata.SchemaType
 				article.OGData = metadata.OGData
 				article.HTMLContent = metadata.HTMLContent
 				article.TextContent = metadata.TextContent
 				article.WordCount = int(metadata.WordCount)
 				article.ReadingTime = int(metadata.ReadingTime)
+				article.ReadingGrade = metadata.ReadingGrade
+				article.Sentiment = metadata.Sentiment
 				article.Language = metadata.Language
-				article.IsCached = true
+				article.NoArchive = metadata.NoArchive
+				article.IsCached = !metadata.NoArchive
 				article.IsReachable = true
 				article.FetchError = "" // Clear any previous error
 				article.CachedAt = &now
 				article.LastFetchAt = &now
+				fc.classifyTopics(&article)
 			}
 			
 			// Save the updated article
-			if err := fc.db.Save(&article).Error; err != nil {
+			if err := db.Save(&article).Error; err != nil {
 				log.Printf("Failed to update article %s: %v", canonicalURL, err)
 			} else {
 				log.Printf("Updated article metadata: %s (reachable: %v)", canonicalURL, article.IsReachable)
@@ -469,212 +772,129 @@ func (fc *FirehoseConsumer) processLink(linkURL string, source *models.Source, p
 		}
 	}
 
-	// Create post URI from Jetstream data
-	postURI := fmt.Sprintf("at://%s/app.bsky.feed.post/%s", event.DID, event.Commit.RKey)
-
-	// Check if this source article already exists (avoid duplicates)
-	var existing models.SourceArticle
-	err = fc.db.Where("source_id = ? AND article_id = ? AND post_uri = ?",
-		source.ID, article.ID, postURI).First(&existing).Error
-
-	if err == gorm.ErrRecordNotFound {
-		// Create new source article record
-		sourceArticle := models.SourceArticle{
-			SourceID:     source.ID,
-			ArticleID:    article.ID,
-			PostURI:      postURI,
-			PostCID:      event.Commit.CID,
-			PostText:     post.Text,
-			IsRepost:     fc.isRepost(post),
-			PostedAt:     post.CreatedAt,
-			LikesCount:   0, // Will be updated by engagement tracking
-			RepostsCount: 0, // Will be updated by engagement tracking
-			RepliesCount: 0, // Will be updated by engagement tracking
-		}
-
-		if err := fc.db.Create(&sourceArticle).Error; err != nil {
-			return fmt.Errorf("failed to create source article: %w", err)
-		}
-
-		log.Printf("New share tracked: %s shared %s", source.Handle, canonicalURL)
-
-		// TODO: Trigger article content fetching and feed updates
-		// This could be done via a message queue or channel
-
-	} else if err != nil {
-		return fmt.Errorf("failed to query existing source article: %w", err)
-	}
-
-	return nil
+	return &article, nil
 }
 
-// isRepost determines if a post is a repost
-func (fc *FirehoseConsumer) isRepost(post *PostRecord) bool {
-	// A post is a repost if it has a reply parent or if it's very short and contains a link
-	return post.Reply != nil || (len(strings.TrimSpace(post.Text)) < 50 && len(post.Facets) > 0)
+// reportFetchFailure reports a page that keeps failing to fetch
+func reportFetchFailure(article *models.Article, sharedBy string, err error) {
+	if !errreport.RepeatedFetchFailure(article.FetchRetries) {
+		return
+	}
+	errreport.Capture(fmt.Errorf("failed to fetch article %d times in a row: %w", article.FetchRetries, err), errreport.Tags{
+		"article.id":  article.ID.String(),
+		"article.url": article.URL,
+		"source.did":  sharedBy,
+	})
 }
 
-// checkIfNewsArticle validates if a URL contains NewsArticle JSON-LD schema
-func (fc *FirehoseConsumer) checkIfNewsArticle(ctx context.Context, articleURL string) (bool, error) {
-	// Create a temporary ArticlesService-like client for validation
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
-			}
-			return nil
-		},
+// recordShare stores a share of an article by a source unless it was already
+// recorded. A new article is stored with the share in one transaction. Shares
+// of stored articles are buffered while consuming Jetstream and written with the
+// next batch; otherwise they're written right away.
+func (fc *FirehoseConsumer) recordShare(ctx context.Context, source *models.Source, article *models.Article, share share) error {
+	sensitive := IsSensitive(share.Labels)
+	if sensitive && fc.skipSensitive {
+		log.Printf("Skipping share of %s by %s labeled %v", article.URL, source.Handle, share.Labels)
+		return nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", articleURL, nil)
-	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
+	if share.ShareType == "" {
+		share.ShareType = models.ShareTypePost
 	}
-
-	// Set a reasonable User-Agent
-	req.Header.Set("User-Agent", "OpenNews/1.0 (+https://opennews.social)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("failed to fetch URL: %w", err)
+	sourceArticle := models.SourceArticle{
+		SourceID:     source.ID,
+		ArticleID:    article.ID,
+		PostURI:      share.PostURI,
+		PostCID:      share.PostCID,
+		PostText:     share.Text,
+		ShareType:    share.ShareType,
+		IsRepost:     share.ShareType == models.ShareTypeRepost,
+		OriginalURI:  share.OriginalURI,
+		PostedAt:     share.PostedAt,
+		Labels:       share.Labels,
+		IsSensitive:  sensitive,
+		LikesCount:   0, // Will be updated by engagement tracking
+		RepostsCount: 0, // Will be updated by engagement tracking
+		RepliesCount: 0, // Will be updated by engagement tracking
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	if article.ID == uuid.Nil {
+		return fc.storeNewArticle(ctx, article, &sourceArticle)
 	}
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, fmt.Errorf("failed to read response body: %w", err)
+	if fc.shares != nil {
+		fc.shares.add(sourceArticle)
+		return nil
 	}
 
-	htmlContent := string(body)
-	
-	// Parse HTML and extract JSON-LD
-	doc, err := html.Parse(strings.NewReader(htmlContent))
+	created, err := models.InsertShare(fc.db.WithContext(ctx), &sourceArticle)
 	if err != nil {
-		return false, fmt.Errorf("failed to parse HTML: %w", err)
-	}
-
-	jsonldData := fc.extractJSONLD(doc)
-	return fc.isNewsArticle(jsonldData), nil
-}
-
-// extractJSONLD extracts JSON-LD structured data from HTML
-func (fc *FirehoseConsumer) extractJSONLD(n *html.Node) string {
-	var jsonldContent string
-	fc.findJSONLDScripts(n, &jsonldContent)
-	return jsonldContent
-}
-
-// findJSONLDScripts recursively searches for script tags with JSON-LD content
-func (fc *FirehoseConsumer) findJSONLDScripts(n *html.Node, jsonldData *string) {
-	if n.Type == html.ElementNode && n.Data == "script" {
-		typeAttr := fc.getAttributeValue(n, "type")
-		if typeAttr == "application/ld+json" {
-			jsonContent := fc.getTextContent(n)
-			if jsonContent != "" && *jsonldData == "" {
-				*jsonldData = jsonContent
-			}
-		}
+		return fmt.Errorf("failed to create source article: %w", err)
 	}
-
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		fc.findJSONLDScripts(c, jsonldData)
-	}
-}
-
-// isNewsArticle checks if the JSON-LD data contains a NewsArticle schema type
-func (fc *FirehoseConsumer) isNewsArticle(jsonldData string) bool {
-	if jsonldData == "" {
-		return false
+	if created {
+		log.Printf("New share tracked: %s shared %s", source.Handle, article.URL)
 	}
 
-	// Parse JSON-LD data
-	var jsonLD interface{}
-	if err := json.Unmarshal([]byte(jsonldData), &jsonLD); err != nil {
-		return false
-	}
-
-	// Check if it's an array of JSON-LD objects
-	if jsonArray, ok := jsonLD.([]interface{}); ok {
-		for _, item := range jsonArray {
-			if fc.checkForNewsArticleType(item) {
-				return true
-			}
-		}
-		return false
-	}
+	// TODO: Trigger article content fetching and feed updates
+	// This could be done via a message queue or channel
 
-	// Check if it's a single JSON-LD object
-	return fc.checkForNewsArticleType(jsonLD)
+	return nil
 }
 
-// checkForNewsArticleType checks if a JSON-LD object has @type of NewsArticle
-func (fc *FirehoseConsumer) checkForNewsArticleType(obj interface{}) bool {
-	jsonObj, ok := obj.(map[string]interface{})
-	if !ok {
-		return false
-	}
-
-	// Check for @graph structure (common in JSON-LD)
-	if graphField, hasGraph := jsonObj["@graph"]; hasGraph {
-		if graphArray, isArray := graphField.([]interface{}); isArray {
-			for _, graphItem := range graphArray {
-				if fc.checkForNewsArticleType(graphItem) {
-					return true
-				}
-			}
+// storeNewArticle stores a new article, its first share and its score in one
+// transaction, so a failure can't leave behind an article nobody shared or one
+// without a score. When another worker stored the same URL first, the share is
+// added to its article instead.
+func (fc *FirehoseConsumer) storeNewArticle(ctx context.Context, article *models.Article, sourceArticle *models.SourceArticle) error {
+	created := false
+	err := fc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if created, err = models.InsertArticle(tx, article); err != nil {
+			return fmt.Errorf("failed to create article: %w", err)
 		}
-	}
-
-	// Check @type field
-	typeField, exists := jsonObj["@type"]
-	if !exists {
-		return false
-	}
-
-	// @type can be a string or array of strings
-	switch t := typeField.(type) {
-	case string:
-		return t == "NewsArticle"
-	case []interface{}:
-		for _, typeName := range t {
-			if typeStr, ok := typeName.(string); ok && typeStr == "NewsArticle" {
-				return true
-			}
+		sourceArticle.ArticleID = article.ID
+		if _, err := models.InsertShare(tx, sourceArticle); err != nil {
+			return fmt.Errorf("failed to create source article: %w", err)
 		}
+		return fc.scoreArticle(tx, article.ID)
+	})
+	if err != nil {
+		// Nothing was stored, so the article is still new if it's shared again
+		article.ID = uuid.Nil
+		return err
 	}
 
-	return false
+	if created {
+		log.Printf("New article stored: %s (title: %s, reachable: %v)", article.URL, article.Title, article.IsReachable)
+	}
+	return nil
 }
 
-// getTextContent recursively extracts text content from HTML nodes
-func (fc *FirehoseConsumer) getTextContent(n *html.Node) string {
-	if n.Type == html.TextNode {
-		return n.Data
-	}
+// classifyTopics tags an article with the topics its content matches
+func (fc *FirehoseConsumer) classifyTopics(article *models.Article) {
+	article.Tags = topics.MergeTags(article.Tags, topics.Classify(topics.Input{
+		Title:       article.Title,
+		Description: article.Description,
+		Text:        article.TextContent,
+		JSONLD:      article.JSONLDData,
+	}))
+}
 
-	var text strings.Builder
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		text.WriteString(fc.getTextContent(c))
+// checkIfNewsArticle decides whether a URL is a news article from its NewsArticle
+// JSON-LD schema, or its fallbacks when its site doesn't require the schema
+func (fc *FirehoseConsumer) checkIfNewsArticle(ctx context.Context, articleURL string) (bool, error) {
+	page, err := fc.pageFetcher.FetchHTML(ctx, articleURL)
+	if err != nil {
+		return false, err
 	}
 
-	return text.String()
-}
-
-// getAttributeValue gets the value of an HTML attribute
-func (fc *FirehoseConsumer) getAttributeValue(n *html.Node, attrName string) string {
-	for _, attr := range n.Attr {
-		if attr.Key == attrName {
-			return attr.Val
-		}
+	// Every ld+json script counts, since the article's isn't always the first
+	_, weight := metadata.PageSchemaType(page.Root)
+	signals := metadata.DetectSignals(page.Root, weight > 0)
+	accepted, reason := fc.domains.SchemaRequirement(articleURL, fc.schemaRequirement).Accepts(signals)
+	if accepted && !signals.NewsSchema {
+		log.Printf("Accepting %s without a NewsArticle schema: %s", articleURL, reason)
 	}
-	return ""
+	return accepted, nil
 }
 
 // isReachabilityError determines if an error is due to network/reachability issues
@@ -684,6 +904,11 @@ func (fc *FirehoseConsumer) isReachabilityError(err error) bool {
 		return false
 	}
 	
+	// Oversized or non-HTML responses are content problems, not reachability problems
+	if errors.Is(err, fetcher.ErrBodyTooLarge) || errors.Is(err, fetcher.ErrUnsupportedContentType) {
+		return false
+	}
+	
 	errStr := err.Error()
 	
 	// Network/connectivity issues
@@ -3,6 +3,7 @@
 package bluesky
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"testing"
@@ -169,7 +170,7 @@ func TestIntegrationArticleMetadataExtraction(t *testing.T) {
 	}
 
 	// Process the link directly
-	err := consumer.processLink(testURL, source, post, event)
+	err := consumer.processLink(context.Background(), testURL, source, post, event)
 	if err != nil {
 		t.Errorf("processLink failed: %v", err)
 	}
@@ -250,12 +251,12 @@ func TestIntegrationDuplicateArticleHandling(t *testing.T) {
 	}
 
 	// Process both links
-	err1 := consumer.processLink(testURL, source, post1, event1)
+	err1 := consumer.processLink(context.Background(), testURL, source, post1, event1)
 	if err1 != nil {
 		t.Errorf("First processLink failed: %v", err1)
 	}
 
-	err2 := consumer.processLink(testURL, source, post2, event2)
+	err2 := consumer.processLink(context.Background(), testURL, source, post2, event2)
 	if err2 != nil {
 		t.Errorf("Second processLink failed: %v", err2)
 	}
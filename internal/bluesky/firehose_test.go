@@ -1,14 +1,20 @@
 package bluesky
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
+	"open-news/internal/cache"
 	"open-news/internal/database"
+	"open-news/internal/fetcher"
 	"open-news/internal/metadata"
 	"open-news/internal/models"
+	"open-news/internal/ranking"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -80,6 +86,7 @@ func TestProcessJetstreamMessage(t *testing.T) {
 		db:                db,
 		client:            nil, // Not needed for this test
 		metadataExtractor: metadata.NewMetadataExtractor(), // Create real metadata extractor
+		pageFetcher:       newValidationFetcher(),
 	}
 
 	// Create a test Jetstream event
@@ -274,7 +281,7 @@ func TestProcessLinkDuplicateArticle(t *testing.T) {
 	}
 
 	// Process the same URL again
-	err := consumer.processLink("https://example.com/existing-article", source, post, event)
+	err := consumer.processLink(context.Background(), "https://example.com/existing-article", source, post, event)
 	if err != nil {
 		t.Errorf("processLink failed: %v", err)
 	}
@@ -294,59 +301,100 @@ func TestProcessLinkDuplicateArticle(t *testing.T) {
 	}
 }
 
-func TestIsRepost(t *testing.T) {
-	consumer := &FirehoseConsumer{}
+func TestProcessPostCommitSkipsReplyLinks(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	article := models.Article{URL: "https://example.com/replied-article", Title: "Replied Article", IsCached: true}
+	db.Create(&article)
 
+	consumer := &FirehoseConsumer{
+		db:         db,
+		replyLinks: ranking.ReplyLinkPolicy{Mode: ranking.ReplyLinksSkip, TrustedQuality: 0.8},
+	}
+	commit := func(rkey string, quality float64) {
+		db.Model(source).Update("quality_score", quality)
+		record, _ := json.Marshal(PostRecord{
+			Text:      "Here's the story https://example.com/replied-article",
+			CreatedAt: time.Now(),
+			Reply:     &Reply{Parent: RecordRef{URI: "at://did:plc:other/app.bsky.feed.post/parent"}},
+		})
+		var fields map[string]interface{}
+		json.Unmarshal(record, &fields)
+		event := &JetstreamEvent{DID: source.BlueSkyDID, Commit: &JetstreamCommit{RKey: rkey, CID: "bafy" + rkey, Record: fields}}
+		if err := consumer.processPostCommit(event); err != nil {
+			t.Fatalf("processPostCommit failed: %v", err)
+		}
+	}
+
+	commit("untrusted", 0.5)
+	var count int64
+	db.Model(&models.SourceArticle{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Expected the reply by an untrusted source to be skipped, got %d shares", count)
+	}
+
+	commit("trusted", 0.9)
+	var shares []models.SourceArticle
+	db.Find(&shares)
+	if len(shares) != 1 || shares[0].ShareType != models.ShareTypeReply {
+		t.Errorf("Expected the trusted source's reply to be stored as a reply, got %+v", shares)
+	}
+}
+
+func TestShareType(t *testing.T) {
 	tests := []struct {
 		name     string
 		post     *PostRecord
-		expected bool
+		expected string
 	}{
 		{
 			name: "Regular post",
 			post: &PostRecord{
-				Text:  "This is a regular post with some content that is longer than 50 characters to test the repost detection",
-				Reply: nil,
+				Text: "This is a regular post with some content that is longer than 50 characters",
 			},
-			expected: false,
+			expected: models.ShareTypePost,
 		},
 		{
-			name: "Short post with link (likely repost)",
+			name: "Short post with link",
 			post: &PostRecord{
-				Text: "Check this out: https://example.com",
-				Facets: []Facet{
-					{}, // Mock facet to indicate links
-				},
+				Text:   "Check this out: https://example.com",
+				Facets: []Facet{{Features: []Feature{{Type: "app.bsky.richtext.facet#link", URI: "https://example.com"}}}},
 			},
-			expected: true,
+			expected: models.ShareTypePost,
 		},
 		{
-			name: "Reply post",
+			name: "Reply with link",
 			post: &PostRecord{
-				Text: "This is a reply to another post",
+				Text: "Here's the story: https://example.com",
 				Reply: &Reply{
-					Root: RecordRef{URI: "at://did:plc:test/app.bsky.feed.post/abc"},
+					Root:   RecordRef{URI: "at://did:plc:test/app.bsky.feed.post/abc"},
+					Parent: RecordRef{URI: "at://did:plc:test/app.bsky.feed.post/abc"},
 				},
 			},
-			expected: true,
+			expected: models.ShareTypeReply,
 		},
 		{
-			name: "Long post with link",
+			name: "Quote",
 			post: &PostRecord{
-				Text: "This is a longer post that provides substantial commentary and analysis about the article being shared, which makes it more than just a simple repost: https://example.com",
-				Facets: []Facet{
-					{}, // Mock facet
-				},
+				Text:  "Worth reading",
+				Embed: &Embed{Type: "app.bsky.embed.record", Record: &RecordEmbed{URI: "at://did:plc:test/app.bsky.feed.post/abc"}},
 			},
-			expected: false,
+			expected: models.ShareTypeQuote,
+		},
+		{
+			name: "Link card",
+			post: &PostRecord{
+				Text:  "New today",
+				Embed: &Embed{Type: "app.bsky.embed.external", External: &ExternalEmbed{URI: "https://example.com"}},
+			},
+			expected: models.ShareTypePost,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := consumer.isRepost(tt.post)
-			if result != tt.expected {
-				t.Errorf("Expected isRepost = %v, got %v", tt.expected, result)
+			if result := ShareType(tt.post.Reply, tt.post.Embed); result != tt.expected {
+				t.Errorf("Expected share type %q, got %q", tt.expected, result)
 			}
 		})
 	}
@@ -371,6 +419,7 @@ func TestFetchStatusTracking(t *testing.T) {
 		db:                db,
 		client:            nil, // Not needed for this test
 		metadataExtractor: metadata.NewMetadataExtractor(),
+		pageFetcher:       newValidationFetcher(),
 	}
 
 	// Create test event with invalid URL
@@ -443,3 +492,126 @@ func TestFetchStatusTracking(t *testing.T) {
 		t.Error("Expected article to not be marked as cached when fetch fails")
 	}
 }
+
+func TestEmbedQuotedURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		embed    string
+		quoted   string
+		external string
+	}{
+		{
+			name:   "quote",
+			embed:  `{"$type":"app.bsky.embed.record","record":{"uri":"at://did:plc:a/app.bsky.feed.post/1","cid":"c"}}`,
+			quoted: "at://did:plc:a/app.bsky.feed.post/1",
+		},
+		{
+			name:     "quote with link card",
+			embed:    `{"$type":"app.bsky.embed.recordWithMedia","record":{"record":{"uri":"at://did:plc:a/app.bsky.feed.post/2","cid":"c"}},"media":{"$type":"app.bsky.embed.external","external":{"uri":"https://example.com/card"}}}`,
+			quoted:   "at://did:plc:a/app.bsky.feed.post/2",
+			external: "https://example.com/card",
+		},
+		{
+			name:  "embedded feed",
+			embed: `{"$type":"app.bsky.embed.record","record":{"uri":"at://did:plc:a/app.bsky.feed.generator/news","cid":"c"}}`,
+		},
+		{
+			name:     "link card",
+			embed:    `{"$type":"app.bsky.embed.external","external":{"uri":"https://example.com/story"}}`,
+			external: "https://example.com/story",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var embed Embed
+			if err := json.Unmarshal([]byte(tt.embed), &embed); err != nil {
+				t.Fatalf("Failed to unmarshal embed: %v", err)
+			}
+			if got := embed.QuotedURI(); got != tt.quoted {
+				t.Errorf("Expected quoted URI %q, got %q", tt.quoted, got)
+			}
+			if got := embed.ExternalURI(); got != tt.external {
+				t.Errorf("Expected external URI %q, got %q", tt.external, got)
+			}
+		})
+	}
+
+	var none *Embed
+	if none.QuotedURI() != "" || none.ExternalURI() != "" {
+		t.Error("Expected a nil embed to have no URIs")
+	}
+}
+
+func TestCheckIfNewsArticleJSONLD(t *testing.T) {
+	pages := map[string]string{
+		// The article is in a @graph in the second of several ld+json scripts
+		"/graph": `<html><head>
+			<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Example News"}</script>
+			<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "WebPage"}, {"@type": ["NewsArticle"], "headline": "Story"}]}</script>
+			</head><body><p>Story</p></body></html>`,
+		"/array": `<html><head>
+			<script type="application/ld+json">[{"@type": "BreadcrumbList"}, {"@type": "LiveBlogPosting"}]</script>
+			</head><body><p>Updates</p></body></html>`,
+		"/none": `<html><head>
+			<script type="application/ld+json">{"@type": "WebSite"}</script>
+			<script type="application/ld+json">{"@type": "Organization"}</script>
+			</head><body><p>Home</p></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	options := fetcher.DefaultOptions()
+	options.AllowPrivateNetworks = true
+	consumer := &FirehoseConsumer{pageFetcher: fetcher.NewFetcher(options)}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/graph", true},
+		{"/array", true},
+		{"/none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			isNewsArticle, err := consumer.checkIfNewsArticle(context.Background(), server.URL+tt.path)
+			if err != nil {
+				t.Fatalf("checkIfNewsArticle failed: %v", err)
+			}
+			if isNewsArticle != tt.expected {
+				t.Errorf("Expected %s to be a news article: %v, got %v", tt.path, tt.expected, isNewsArticle)
+			}
+		})
+	}
+}
+
+func TestDesktopURL(t *testing.T) {
+	var fetches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html amp><head><link rel="canonical" href="/world/story"></head></html>`))
+	}))
+	defer server.Close()
+
+	options := fetcher.DefaultOptions()
+	options.AllowPrivateNetworks = true
+	consumer := &FirehoseConsumer{pageFetcher: fetcher.NewFetcher(options), canonicalURLs: cache.NewMemory(10)}
+
+	if got := consumer.desktopURL(context.Background(), server.URL+"/world/story/amp"); got != server.URL+"/world/story" {
+		t.Errorf("Expected the AMP page's canonical URL, got %s", got)
+	}
+	consumer.desktopURL(context.Background(), server.URL+"/world/story/amp")
+	if fetches != 1 {
+		t.Errorf("Expected the canonical URL to be fetched once, got %d fetches", fetches)
+	}
+
+	if got := consumer.desktopURL(context.Background(), server.URL+"/world/story"); got != server.URL+"/world/story" || fetches != 1 {
+		t.Errorf("Expected other links to be kept without fetching them, got %s", got)
+	}
+}
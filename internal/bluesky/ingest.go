@@ -0,0 +1,242 @@
+package bluesky
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultIngestQueueSize is how many Jetstream messages can wait to be
+	// processed before the reader is held back
+	defaultIngestQueueSize = 1000
+
+	// ingestEnqueueTimeout is how long the reader waits for room in a full
+	// queue before dropping the message
+	ingestEnqueueTimeout = 5 * time.Second
+
+	// ingestMonitorInterval is how often lag and drops are checked and logged
+	ingestMonitorInterval = 30 * time.Second
+
+	defaultLagWarning  = 30 * time.Second
+	defaultLagCritical = 5 * time.Minute
+)
+
+// Lag levels reported in IngestStats
+const (
+	LagOK       = "ok"
+	LagWarning  = "warning"
+	LagCritical = "critical"
+)
+
+// ingestConfig holds the Jetstream queue and lag alert settings
+type ingestConfig struct {
+	QueueSize   int           // Messages buffered between reading and processing (JETSTREAM_QUEUE_SIZE)
+	LagWarning  time.Duration // Lag that logs a warning (JETSTREAM_LAG_WARN_SECONDS)
+	LagCritical time.Duration // Lag that logs a critical alert (JETSTREAM_LAG_CRITICAL_SECONDS)
+}
+
+// loadIngestConfig reads the queue and lag settings from the environment
+func loadIngestConfig() ingestConfig {
+	config := ingestConfig{
+		QueueSize:   defaultIngestQueueSize,
+		LagWarning:  defaultLagWarning,
+		LagCritical: defaultLagCritical,
+	}
+	if value := os.Getenv("JETSTREAM_QUEUE_SIZE"); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			config.QueueSize = size
+		} else {
+			log.Printf("Invalid JETSTREAM_QUEUE_SIZE %q, using %d", value, config.QueueSize)
+		}
+	}
+	if value := os.Getenv("JETSTREAM_LAG_WARN_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			config.LagWarning = time.Duration(seconds) * time.Second
+		} else {
+			log.Printf("Invalid JETSTREAM_LAG_WARN_SECONDS %q, using %v", value, config.LagWarning)
+		}
+	}
+	if value := os.Getenv("JETSTREAM_LAG_CRITICAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			config.LagCritical = time.Duration(seconds) * time.Second
+		} else {
+			log.Printf("Invalid JETSTREAM_LAG_CRITICAL_SECONDS %q, using %v", value, config.LagCritical)
+		}
+	}
+	return config
+}
+
+// IngestStats describes how the Jetstream consumer is keeping up
+type IngestStats struct {
+	Queued     int     `json:"queued"`      // Messages waiting to be processed
+	QueueSize  int     `json:"queue_size"`  // Messages the queue holds before the reader is held back
+	Received   int64   `json:"received"`    // Messages read since consuming started
+	Processed  int64   `json:"processed"`   // Messages processed since consuming started
+	Stalls     int64   `json:"stalls"`      // Times the reader found the queue full
+	Dropped    int64   `json:"dropped"`     // Messages dropped after waiting for room
+	LagSeconds float64 `json:"lag_seconds"` // Wall clock time minus the time_us of the last processed event
+	LagLevel   string  `json:"lag_level"`   // ok, warning or critical
+}
+
+// ingestQueue sits between the Jetstream reader and the processing of each
+// message. A slow database or fetch holds the reader back for a while once the
+// queue is full, then messages are dropped and counted rather than letting the
+// connection fall further and further behind without anyone noticing.
+type ingestQueue struct {
+	config    ingestConfig
+	messages  chan []byte
+	wait      time.Duration // How long enqueue waits for room
+	received  atomic.Int64
+	processed atomic.Int64
+	stalls    atomic.Int64
+	dropped   atomic.Int64
+	lag       atomic.Int64 // Nanoseconds behind as of the last processed event
+
+	mu    sync.Mutex
+	level string // Lag level as of the last check
+}
+
+// newIngestQueue creates an empty queue
+func newIngestQueue(config ingestConfig) *ingestQueue {
+	return &ingestQueue{
+		config:   config,
+		messages: make(chan []byte, config.QueueSize),
+		wait:     ingestEnqueueTimeout,
+		level:    LagOK,
+	}
+}
+
+// enqueue hands a message to the processor. When the queue is full it waits
+// up to ingestEnqueueTimeout for room, then drops the message.
+func (q *ingestQueue) enqueue(ctx context.Context, message []byte) error {
+	q.received.Add(1)
+	select {
+	case q.messages <- message:
+		return nil
+	default:
+	}
+
+	q.stalls.Add(1)
+	timer := time.NewTimer(q.wait)
+	defer timer.Stop()
+	select {
+	case q.messages <- message:
+	case <-timer.C:
+		q.dropped.Add(1)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// process passes queued messages to handle until the context is cancelled
+func (q *ingestQueue) process(ctx context.Context, handle func([]byte) error) {
+	for {
+		select {
+		case message := <-q.messages:
+			if err := handle(message); err != nil {
+				log.Printf("Error processing Jetstream message: %v", err)
+			}
+			q.processed.Add(1)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// observe records the lag of an event from its Jetstream time in microseconds
+func (q *ingestQueue) observe(timeUS int64, now time.Time) {
+	if timeUS <= 0 {
+		return
+	}
+	q.lag.Store(int64(now.Sub(time.UnixMicro(timeUS))))
+}
+
+// lagLevel returns the alert level of lag
+func (q *ingestQueue) lagLevel(lag time.Duration) string {
+	switch {
+	case lag >= q.config.LagCritical:
+		return LagCritical
+	case lag >= q.config.LagWarning:
+		return LagWarning
+	default:
+		return LagOK
+	}
+}
+
+// stats returns the queue's counters and current lag
+func (q *ingestQueue) stats() IngestStats {
+	lag := time.Duration(q.lag.Load())
+	return IngestStats{
+		Queued:     len(q.messages),
+		QueueSize:  cap(q.messages),
+		Received:   q.received.Load(),
+		Processed:  q.processed.Load(),
+		Stalls:     q.stalls.Load(),
+		Dropped:    q.dropped.Load(),
+		LagSeconds: lag.Seconds(),
+		LagLevel:   q.lagLevel(lag),
+	}
+}
+
+// monitor checks the queue every ingestMonitorInterval until the context is
+// cancelled
+func (q *ingestQueue) monitor(ctx context.Context) {
+	ticker := time.NewTicker(ingestMonitorInterval)
+	defer ticker.Stop()
+
+	var last IngestStats
+	for {
+		select {
+		case <-ticker.C:
+			last = q.check(last)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check logs drops and stalls since the previous stats, and alerts while the
+// consumer is behind or when it catches up again. It returns the current stats.
+func (q *ingestQueue) check(previous IngestStats) IngestStats {
+	current := q.stats()
+	if dropped := current.Dropped - previous.Dropped; dropped > 0 {
+		log.Printf("Dropped %d Jetstream events while the queue was full (now %d/%d)", dropped, current.Queued, current.QueueSize)
+	} else if stalls := current.Stalls - previous.Stalls; stalls > 0 {
+		log.Printf("Jetstream reader held back %d times by a full queue (now %d/%d)", stalls, current.Queued, current.QueueSize)
+	}
+
+	lag := time.Duration(current.LagSeconds * float64(time.Second)).Round(time.Second)
+	q.mu.Lock()
+	previousLevel := q.level
+	q.level = current.LagLevel
+	q.mu.Unlock()
+
+	switch current.LagLevel {
+	case LagCritical:
+		log.Printf("CRITICAL: Jetstream consumer is %v behind (threshold %v)", lag, q.config.LagCritical)
+	case LagWarning:
+		log.Printf("WARNING: Jetstream consumer is %v behind (threshold %v)", lag, q.config.LagWarning)
+	default:
+		if previousLevel != LagOK {
+			log.Printf("Jetstream consumer caught up, %v behind", lag)
+		}
+	}
+	return current
+}
+
+// IngestStats returns how the Jetstream consumer is keeping up, or nil when it
+// isn't consuming
+func (fc *FirehoseConsumer) IngestStats() *IngestStats {
+	queue := fc.ingest.Load()
+	if queue == nil {
+		return nil
+	}
+	stats := queue.stats()
+	return &stats
+}
@@ -0,0 +1,166 @@
+package bluesky
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"open-news/internal/database"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestIngestQueueBackpressure(t *testing.T) {
+	queue := newIngestQueue(ingestConfig{QueueSize: 2, LagWarning: 30 * time.Second, LagCritical: 5 * time.Minute})
+	queue.wait = 10 * time.Millisecond
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := queue.enqueue(ctx, []byte("{}")); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+	stats := queue.stats()
+	if stats.Queued != 2 || stats.Received != 3 || stats.Dropped != 1 || stats.Stalls != 1 {
+		t.Errorf("Expected 2 queued and 1 dropped of 3, got %+v", stats)
+	}
+
+	// Room made while the reader waits lets the message in
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		<-queue.messages
+	}()
+	queue.wait = time.Second
+	queue.enqueue(ctx, []byte("{}"))
+	if stats := queue.stats(); stats.Dropped != 1 || stats.Queued != 2 {
+		t.Errorf("Expected the waiting message to be queued, got %+v", stats)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := queue.enqueue(cancelled, []byte("{}")); err == nil {
+		t.Error("Expected enqueue to stop when the context is cancelled")
+	}
+}
+
+func TestIngestQueueLag(t *testing.T) {
+	queue := newIngestQueue(ingestConfig{QueueSize: 10, LagWarning: 30 * time.Second, LagCritical: 5 * time.Minute})
+	now := time.Now()
+
+	tests := []struct {
+		behind time.Duration
+		level  string
+	}{
+		{time.Second, LagOK},
+		{time.Minute, LagWarning},
+		{10 * time.Minute, LagCritical},
+	}
+	var stats IngestStats
+	for _, tt := range tests {
+		queue.observe(now.Add(-tt.behind).UnixMicro(), now)
+		stats = queue.check(stats)
+		if stats.LagLevel != tt.level {
+			t.Errorf("%v behind: expected %s, got %s", tt.behind, tt.level, stats.LagLevel)
+		}
+		if stats.LagSeconds < tt.behind.Seconds()-1 || stats.LagSeconds > tt.behind.Seconds()+1 {
+			t.Errorf("%v behind: got lag of %.1f seconds", tt.behind, stats.LagSeconds)
+		}
+	}
+
+	// Events without a time leave the lag alone
+	queue.observe(0, now)
+	if level := queue.stats().LagLevel; level != LagCritical {
+		t.Errorf("Expected the lag to be kept, got %s", level)
+	}
+}
+
+func TestIngestQueueProcess(t *testing.T) {
+	queue := newIngestQueue(ingestConfig{QueueSize: 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	handled := make(chan string, 10)
+	done := make(chan struct{})
+	go func() {
+		queue.process(ctx, func(message []byte) error {
+			handled <- string(message)
+			return nil
+		})
+		close(done)
+	}()
+
+	queue.enqueue(ctx, []byte("one"))
+	queue.enqueue(ctx, []byte("two"))
+	for _, want := range []string{"one", "two"} {
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the queue to be processed")
+		}
+	}
+	cancel()
+	<-done
+	if processed := queue.stats().Processed; processed != 2 {
+		t.Errorf("Expected 2 processed messages, got %d", processed)
+	}
+}
+
+func TestIngestStatsWhileStartingAndStopping(t *testing.T) {
+	if err := database.Connect(&database.Config{Driver: database.DriverSQLite, Path: ":memory:"}); err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+
+	// The consumer never connects, so it sits in its retry loop until cancelled
+	consumer := NewFirehoseConsumer(database.DB, nil)
+	consumer.dialer = &websocket.Dialer{NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("offline")
+	}}
+
+	// Status handlers read the stats at any time
+	done := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				consumer.IngestStats()
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		<-polled
+	}()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error, 1)
+		go func() { stopped <- consumer.StartConsuming(ctx) }()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for consumer.IngestStats() == nil {
+			if time.Now().After(deadline) {
+				cancel()
+				t.Fatal("Timed out waiting for the consumer to start")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-stopped; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the consumer to stop when cancelled, got %v", err)
+		}
+		if stats := consumer.IngestStats(); stats != nil {
+			t.Errorf("Expected no stats once stopped, got %+v", stats)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package bluesky
+
+import "strings"
+
+// SensitiveLabels are the label values that mark a post as adult or graphic. Shares
+// in posts carrying one are flagged, or skipped with SKIP_SENSITIVE_POSTS=true.
+var SensitiveLabels = map[string]bool{
+	"porn":          true,
+	"sexual":        true,
+	"nudity":        true,
+	"graphic-media": true,
+	"gore":          true, // Replaced by graphic-media, still found on older posts
+}
+
+// SelfLabels are the labels an author puts on their own record
+// (com.atproto.label.defs#selfLabels)
+type SelfLabels struct {
+	Type   string      `json:"$type,omitempty"`
+	Values []SelfLabel `json:"values"`
+}
+
+// SelfLabel is a single self-label value
+type SelfLabel struct {
+	Val string `json:"val"`
+}
+
+// labelValues merges a record's self-labels with labels applied by labelers,
+// leaving out labels a labeler negated
+func labelValues(self *SelfLabels, labels []Label) []string {
+	var values []string
+	if self != nil {
+		for _, label := range self.Values {
+			values = append(values, label.Val)
+		}
+	}
+	negated := make(map[string]bool)
+	for _, label := range labels {
+		if label.Neg {
+			negated[label.Val] = true
+		}
+	}
+	for _, label := range labels {
+		if !label.Neg && !negated[label.Val] {
+			values = append(values, label.Val)
+		}
+	}
+	return mergeLabels(values)
+}
+
+// mergeLabels combines lists of label values, lowercased and without duplicates
+func mergeLabels(lists ...[]string) []string {
+	var values []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, value := range list {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value != "" && !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// IsSensitive reports whether any of the label values is in SensitiveLabels
+func IsSensitive(labels []string) bool {
+	for _, label := range labels {
+		if SensitiveLabels[label] {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelValues returns the labels of a post fetched from the AppView: its author's
+// self-labels and those applied by labelers
+func (p *Post) LabelValues() []string {
+	return labelValues(p.Record.Labels, p.Labels)
+}
@@ -0,0 +1,79 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"open-news/internal/models"
+)
+
+func TestLabelValues(t *testing.T) {
+	self := &SelfLabels{Values: []SelfLabel{{Val: "Nudity"}, {Val: "porn"}}}
+	labels := []Label{
+		{Src: "did:plc:labeler", Val: "porn"},
+		{Src: "did:plc:labeler", Val: "graphic-media"},
+		{Src: "did:plc:labeler", Val: "spam"},
+		{Src: "did:plc:labeler", Val: "spam", Neg: true},
+	}
+
+	got := labelValues(self, labels)
+	want := []string{"nudity", "porn", "graphic-media"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("labelValues() = %v, want %v", got, want)
+	}
+	if labelValues(nil, nil) != nil {
+		t.Error("Expected no labels for an unlabeled post")
+	}
+}
+
+func TestIsSensitive(t *testing.T) {
+	if !IsSensitive([]string{"!no-unauthenticated", "graphic-media"}) {
+		t.Error("Expected graphic-media to be sensitive")
+	}
+	if IsSensitive([]string{"!no-unauthenticated"}) || IsSensitive(nil) {
+		t.Error("Expected posts without adult or graphic labels not to be sensitive")
+	}
+}
+
+func TestPostRecordSelfLabels(t *testing.T) {
+	data := `{"$type":"app.bsky.feed.post","text":"Story","createdAt":"2024-01-01T00:00:00Z",
+		"labels":{"$type":"com.atproto.label.defs#selfLabels","values":[{"val":"porn"}]}}`
+	var post PostRecord
+	if err := json.Unmarshal([]byte(data), &post); err != nil {
+		t.Fatalf("Failed to unmarshal post: %v", err)
+	}
+	if got := labelValues(post.Labels, nil); !reflect.DeepEqual(got, []string{"porn"}) {
+		t.Errorf("Expected the self-label to be read, got %v", got)
+	}
+}
+
+func TestRecordShareSensitive(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	article := models.Article{URL: "https://example.com/labeled", Title: "Labeled story"}
+	db.Create(&article)
+
+	consumer := &FirehoseConsumer{db: db}
+	if err := consumer.recordShare(context.Background(), source, &article, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/l1", Labels: []string{"graphic-media"}}); err != nil {
+		t.Fatalf("recordShare failed: %v", err)
+	}
+	var flagged models.SourceArticle
+	if err := db.Where("post_uri = ?", "at://did:plc:test123456789/app.bsky.feed.post/l1").First(&flagged).Error; err != nil {
+		t.Fatalf("Expected the labeled share to be recorded: %v", err)
+	}
+	if !flagged.IsSensitive || len(flagged.Labels) != 1 {
+		t.Errorf("Expected the share to be flagged sensitive with its label, got sensitive=%v labels=%v", flagged.IsSensitive, flagged.Labels)
+	}
+
+	consumer.skipSensitive = true
+	if err := consumer.recordShare(context.Background(), source, &article, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/l2", Labels: []string{"porn"}}); err != nil {
+		t.Fatalf("recordShare failed: %v", err)
+	}
+	var count int64
+	db.Model(&models.SourceArticle{}).Where("post_uri = ?", "at://did:plc:test123456789/app.bsky.feed.post/l2").Count(&count)
+	if count != 0 {
+		t.Error("Expected the labeled share to be skipped")
+	}
+}
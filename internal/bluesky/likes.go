@@ -0,0 +1,243 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// jetstreamLikeCollection is the collection carrying likes
+const jetstreamLikeCollection = "app.bsky.feed.like"
+
+const (
+	// likeTrackingWindow is how long after a share its post keeps collecting likes.
+	// Nearly all likes arrive in the first days, and a short window keeps the set small.
+	likeTrackingWindow = 7 * 24 * time.Hour
+
+	// trackedPostsRefreshInterval is how often the tracked post URIs are reloaded
+	trackedPostsRefreshInterval = time.Minute
+
+	// likeFlushInterval is how often counted likes are written to the database
+	likeFlushInterval = 10 * time.Second
+)
+
+// LikeRecord represents an app.bsky.feed.like record from Jetstream
+type LikeRecord struct {
+	Subject   RecordRef `json:"subject"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// likeCounter counts likes of the posts recorded as shares and adds them to the
+// stored like counts in batches, so the like stream doesn't cost a write per event
+type likeCounter struct {
+	db      *gorm.DB
+	rescore func(articleID uuid.UUID) error
+	mu      sync.Mutex
+	tracked map[string]bool
+	pending map[string]int
+}
+
+// newLikeCounter creates a like counter with no tracked posts
+func newLikeCounter(db *gorm.DB, rescore func(articleID uuid.UUID) error) *likeCounter {
+	return &likeCounter{
+		db:      db,
+		rescore: rescore,
+		tracked: make(map[string]bool),
+		pending: make(map[string]int),
+	}
+}
+
+// StartConsumingLikes counts likes of tracked posts from a second Jetstream connection.
+// Likes come from every account, not just followed sources, so they can't share the
+// DID-filtered connection. Only likes are counted: deleting a like sends no subject,
+// so unlikes aren't subtracted.
+func (fc *FirehoseConsumer) StartConsumingLikes(ctx context.Context) error {
+	query := url.Values{"wantedCollections": {jetstreamLikeCollection}}
+	jetstreamURL := "wss://jetstream2.us-east.bsky.network/subscribe?" + query.Encode()
+
+	counter := newLikeCounter(fc.db, fc.rescoreArticle)
+	if err := counter.refreshTracked(); err != nil {
+		log.Printf("Failed to load tracked posts: %v", err)
+	}
+	go counter.run(ctx)
+
+	log.Printf("Connecting to Bluesky Jetstream for likes: %s", jetstreamURL)
+
+	return consumeWithRetry(ctx, func() error {
+		conn, _, err := fc.dialer.DialContext(ctx, jetstreamURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Jetstream: %w", err)
+		}
+		defer conn.Close()
+
+		log.Println("Successfully connected to Bluesky Jetstream for likes")
+
+		var writeMu sync.Mutex
+		write := func(messageType int, data []byte) error {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			return conn.WriteMessage(messageType, data)
+		}
+		return readMessages(ctx, conn, write, counter.processMessage)
+	})
+}
+
+// run reloads the tracked posts and flushes counted likes until the context is
+// cancelled, flushing once more on the way out
+func (lc *likeCounter) run(ctx context.Context) {
+	refresh := time.NewTicker(trackedPostsRefreshInterval)
+	defer refresh.Stop()
+	flush := time.NewTicker(likeFlushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-refresh.C:
+			if err := lc.refreshTracked(); err != nil {
+				log.Printf("Failed to reload tracked posts: %v", err)
+			}
+		case <-flush.C:
+			lc.flush()
+		case <-ctx.Done():
+			lc.flush()
+			return
+		}
+	}
+}
+
+// refreshTracked loads the URIs of posts shared by sources within the tracking window.
+// Reposts are left out because likes go to the reposted post, not the repost.
+func (lc *likeCounter) refreshTracked() error {
+	var uris []string
+	err := lc.db.Model(&models.SourceArticle{}).
+		Where("is_repost = ? AND post_uri <> '' AND posted_at > ?", false, time.Now().Add(-likeTrackingWindow)).
+		Distinct().
+		Pluck("post_uri", &uris).Error
+	if err != nil {
+		return err
+	}
+
+	tracked := make(map[string]bool, len(uris))
+	for _, uri := range uris {
+		tracked[uri] = true
+	}
+
+	lc.mu.Lock()
+	lc.tracked = tracked
+	lc.mu.Unlock()
+	return nil
+}
+
+// processMessage counts a like event whose subject is a tracked post
+func (lc *likeCounter) processMessage(data []byte) error {
+	var event JetstreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal Jetstream event: %w", err)
+	}
+	if event.Kind != "commit" || event.Commit == nil ||
+		event.Commit.Collection != jetstreamLikeCollection || event.Commit.Operation != "create" {
+		return nil
+	}
+
+	recordBytes, err := json.Marshal(event.Commit.Record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+	var like LikeRecord
+	if err := json.Unmarshal(recordBytes, &like); err != nil {
+		return fmt.Errorf("failed to unmarshal like record: %w", err)
+	}
+
+	lc.count(like.Subject.URI)
+	return nil
+}
+
+// count adds one like to a post if it's tracked
+func (lc *likeCounter) count(postURI string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.tracked[postURI] {
+		lc.pending[postURI]++
+	}
+}
+
+// flush adds the counted likes to the shares of each post and the articles they
+// point to, then rescores those articles
+func (lc *likeCounter) flush() {
+	lc.mu.Lock()
+	pending := lc.pending
+	lc.pending = make(map[string]int)
+	lc.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	rescore := make(map[uuid.UUID]bool)
+	for postURI, likes := range pending {
+		articleIDs, err := lc.addLikes(postURI, likes)
+		if err != nil {
+			log.Printf("Failed to add %d likes to %s: %v", likes, postURI, err)
+			continue
+		}
+		for _, id := range articleIDs {
+			rescore[id] = true
+		}
+	}
+
+	for articleID := range rescore {
+		if err := lc.rescore(articleID); err != nil {
+			log.Printf("Failed to rescore article %s: %v", articleID, err)
+		}
+	}
+}
+
+// addLikes increments the like counts of a post's shares and their articles,
+// returning the affected article IDs. Only posts within the tracking window
+// are counted, which limits the lookups to the latest source_articles partitions.
+func (lc *likeCounter) addLikes(postURI string, likes int) ([]uuid.UUID, error) {
+	since := time.Now().Add(-likeTrackingWindow)
+	var articleIDs []uuid.UUID
+	err := lc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SourceArticle{}).
+			Where("post_uri = ? AND is_repost = ? AND posted_at > ?", postURI, false, since).
+			Pluck("article_id", &articleIDs).Error; err != nil {
+			return err
+		}
+		if len(articleIDs) == 0 {
+			return nil // The share was deleted since the tracked posts were loaded
+		}
+
+		if err := tx.Model(&models.SourceArticle{}).
+			Where("post_uri = ? AND is_repost = ? AND posted_at > ?", postURI, false, since).
+			Update("likes_count", gorm.Expr("likes_count + ?", likes)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Article{}).
+			Where("id IN ?", articleIDs).
+			Update("likes_count", gorm.Expr("likes_count + ?", likes)).Error; err != nil {
+			return err
+		}
+
+		// The articles are rescored right after, but the sharing sources' scores,
+		// which depend on the engagement their shares get, wait for the next
+		// metrics update
+		var sourceIDs []uuid.UUID
+		if err := tx.Model(&models.SourceArticle{}).
+			Where("post_uri = ? AND is_repost = ? AND posted_at > ?", postURI, false, since).
+			Distinct().Pluck("source_id", &sourceIDs).Error; err != nil {
+			return err
+		}
+		return models.MarkForRescore(tx, nil, sourceIDs)
+	})
+	return articleIDs, err
+}
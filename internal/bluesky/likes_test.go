@@ -0,0 +1,98 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+)
+
+func likeMessage(t *testing.T, operation, subjectURI string) []byte {
+	t.Helper()
+	event := JetstreamEvent{
+		DID:  "did:plc:liker",
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  operation,
+			Collection: jetstreamLikeCollection,
+			RKey:       "3klike",
+		},
+	}
+	if operation == "create" {
+		event.Commit.Record = map[string]interface{}{
+			"$type":     jetstreamLikeCollection,
+			"subject":   map[string]interface{}{"uri": subjectURI, "cid": "bafy"},
+			"createdAt": time.Now().Format(time.RFC3339),
+		}
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Failed to encode like event: %v", err)
+	}
+	return data
+}
+
+func TestLikeCounter_ProcessMessage(t *testing.T) {
+	tracked := "at://did:plc:source/app.bsky.feed.post/tracked"
+	counter := newLikeCounter(nil, nil)
+	counter.tracked[tracked] = true
+
+	for _, data := range [][]byte{
+		likeMessage(t, "create", tracked),
+		likeMessage(t, "create", tracked),
+		likeMessage(t, "create", "at://did:plc:other/app.bsky.feed.post/untracked"),
+		likeMessage(t, "delete", ""),
+	} {
+		if err := counter.processMessage(data); err != nil {
+			t.Fatalf("processMessage failed: %v", err)
+		}
+	}
+
+	if len(counter.pending) != 1 || counter.pending[tracked] != 2 {
+		t.Errorf("Expected 2 pending likes for the tracked post, got %v", counter.pending)
+	}
+}
+
+func TestLikeCounter_Flush(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	article := models.Article{URL: "https://example.com/liked-story", Title: "Story"}
+	db.Create(&article)
+	postURI := "at://" + source.BlueSkyDID + "/app.bsky.feed.post/liked"
+	db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: postURI, LikesCount: 3, PostedAt: time.Now()})
+	oldURI := "at://" + source.BlueSkyDID + "/app.bsky.feed.post/old"
+	db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: oldURI, PostedAt: time.Now().Add(-2 * likeTrackingWindow)})
+
+	counter := newLikeCounter(db, consumer.rescoreArticle)
+	if err := counter.refreshTracked(); err != nil {
+		t.Fatalf("refreshTracked failed: %v", err)
+	}
+	if !counter.tracked[postURI] || counter.tracked[oldURI] {
+		t.Fatalf("Expected only the recent post to be tracked, got %v", counter.tracked)
+	}
+
+	counter.count(postURI)
+	counter.count(postURI)
+	counter.flush()
+
+	var share models.SourceArticle
+	db.Where("post_uri = ?", postURI).First(&share)
+	if share.LikesCount != 5 {
+		t.Errorf("Expected share likes_count 5, got %d", share.LikesCount)
+	}
+
+	var updated models.Article
+	db.First(&updated, "id = ?", article.ID)
+	if updated.LikesCount != 2 {
+		t.Errorf("Expected article likes_count 2, got %d", updated.LikesCount)
+	}
+	if updated.ScoreBreakdownData == "" {
+		t.Error("Expected the article to be rescored")
+	}
+	if len(counter.pending) != 0 {
+		t.Errorf("Expected pending likes to be cleared, got %v", counter.pending)
+	}
+}
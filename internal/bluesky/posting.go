@@ -0,0 +1,312 @@
+package bluesky
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"open-news/internal/labeler"
+)
+
+const (
+	// MaxPostGraphemes is the longest text a post may have
+	MaxPostGraphemes = 300
+
+	// MaxMessageGraphemes is the longest text a direct message may have
+	MaxMessageGraphemes = 1000
+
+	// maxFeedDisplayName is the longest display name a feed generator record may have
+	maxFeedDisplayName = 24
+
+	// chatServiceProxy routes chat requests through the PDS to the Bluesky chat service
+	chatServiceProxy = "did:web:api.bsky.chat#bsky_chat"
+)
+
+// NewPost is a post to publish from the authenticated account
+type NewPost struct {
+	Text     string
+	Reply    *ReplyRefs     // Set to post as a reply in a thread
+	External *ExternalEmbed // Optional link card
+}
+
+// ReplyRefs points a reply at its thread's first post and the post it answers
+type ReplyRefs struct {
+	Root   RecordRef `json:"root"`
+	Parent RecordRef `json:"parent"`
+}
+
+// postRecord is an app.bsky.feed.post record as written with createRecord
+type postRecord struct {
+	Type      string     `json:"$type"`
+	Text      string     `json:"text"`
+	CreatedAt string     `json:"createdAt"`
+	Facets    []Facet    `json:"facets,omitempty"`
+	Reply     *ReplyRefs `json:"reply,omitempty"`
+	Embed     *Embed     `json:"embed,omitempty"`
+}
+
+// linkPattern finds URLs in post text
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"]+[^\s<>".,;:!?)\]]`)
+
+// LinkFacets returns link facets for the URLs in text, since posts only render
+// links that are marked up
+func LinkFacets(text string) []Facet {
+	var facets []Facet
+	for _, match := range linkPattern.FindAllStringIndex(text, -1) {
+		facets = append(facets, Facet{
+			Index:    ByteSlice{ByteStart: match[0], ByteEnd: match[1]},
+			Features: []Feature{{Type: "app.bsky.richtext.facet#link", URI: text[match[0]:match[1]]}},
+		})
+	}
+	return facets
+}
+
+// IsAuthenticated reports whether the client has a session to act as an account
+func (c *Client) IsAuthenticated() bool {
+	return c.session != nil
+}
+
+// SessionDID returns the DID of the authenticated account, or "" without a session
+func (c *Client) SessionDID() string {
+	if c.session == nil {
+		return ""
+	}
+	return c.session.DID
+}
+
+// CreatePost publishes a post from the authenticated account and returns its reference
+func (c *Client) CreatePost(post NewPost) (*RecordRef, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	record := postRecord{
+		Type:      "app.bsky.feed.post",
+		Text:      post.Text,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Facets:    LinkFacets(post.Text),
+		Reply:     post.Reply,
+	}
+	if post.External != nil {
+		record.Embed = &Embed{Type: "app.bsky.embed.external", External: post.External}
+	}
+
+	var created RecordRef
+	err := c.postJSON("/xrpc/com.atproto.repo.createRecord", "", map[string]interface{}{
+		"repo":       c.session.DID,
+		"collection": "app.bsky.feed.post",
+		"record":     record,
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	return &created, nil
+}
+
+// SendDirectMessage sends a chat message from the authenticated account to an
+// account, starting a conversation if there isn't one. The account's app password
+// needs direct message access.
+func (c *Client) SendDirectMessage(recipientDID, text string) error {
+	if c.session == nil {
+		return fmt.Errorf("not authenticated")
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+"/xrpc/chat.bsky.convo.getConvoForMembers?"+url.Values{"members": {recipientDID}}.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.session.AccessJWT)
+	req.Header.Set("Atproto-Proxy", chatServiceProxy)
+
+	var convo struct {
+		Convo struct {
+			ID string `json:"id"`
+		} `json:"convo"`
+	}
+	if err := c.doJSON(req, &convo); err != nil {
+		return fmt.Errorf("failed to open conversation with %s: %w", recipientDID, err)
+	}
+
+	err = c.postJSON("/xrpc/chat.bsky.convo.sendMessage", chatServiceProxy, map[string]interface{}{
+		"convoId": convo.Convo.ID,
+		"message": map[string]interface{}{
+			"text":   text,
+			"facets": LinkFacets(text),
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", recipientDID, err)
+	}
+	return nil
+}
+
+// postJSON sends an authenticated XRPC procedure call, decoding the response into
+// result when it's not nil
+func (c *Client) postJSON(path, proxy string, body, result interface{}) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "POST", c.baseURL+path, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.session.AccessJWT)
+	if proxy != "" {
+		req.Header.Set("Atproto-Proxy", proxy)
+	}
+	return c.doJSON(req, result)
+}
+
+// doJSON sends a request and decodes its JSON response into result when it's not nil
+func (c *Client) doJSON(req *http.Request, result interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+// FeedGenerator is an app.bsky.feed.generator record, which lists a custom feed
+// on Bluesky under the account that publishes it
+type FeedGenerator struct {
+	DID         string // The feed generator service that serves the feed, e.g. did:web:example.com
+	DisplayName string // At most 24 characters
+	Description string
+}
+
+// PutFeedGenerator creates or replaces the authenticated account's feed generator
+// record with the given record key
+func (c *Client) PutFeedGenerator(rkey string, feed FeedGenerator) (*RecordRef, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if n := utf8.RuneCountInString(feed.DisplayName); n > maxFeedDisplayName {
+		return nil, fmt.Errorf("feed display name %q is %d characters, the limit is %d", feed.DisplayName, n, maxFeedDisplayName)
+	}
+
+	record := map[string]interface{}{
+		"$type":       "app.bsky.feed.generator",
+		"did":         feed.DID,
+		"displayName": feed.DisplayName,
+		"createdAt":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if feed.Description != "" {
+		record["description"] = feed.Description
+	}
+
+	var put RecordRef
+	err := c.postJSON("/xrpc/com.atproto.repo.putRecord", "", map[string]interface{}{
+		"repo":       c.session.DID,
+		"collection": "app.bsky.feed.generator",
+		"rkey":       rkey,
+		"record":     record,
+	}, &put)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put feed generator %s: %w", rkey, err)
+	}
+	return &put, nil
+}
+
+// PublishedFeedGenerator is an app.bsky.feed.generator record in an account's repository
+type PublishedFeedGenerator struct {
+	URI         string
+	RKey        string
+	DID         string // The feed generator service the record points at
+	DisplayName string
+}
+
+// ListFeedGenerators returns the feed generator records an account has published.
+// Repositories are public, so it doesn't need a session.
+func (c *Client) ListFeedGenerators(repo string) ([]PublishedFeedGenerator, error) {
+	var generators []PublishedFeedGenerator
+	cursor := ""
+	for {
+		query := url.Values{"repo": {repo}, "collection": {"app.bsky.feed.generator"}, "limit": {"100"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		req, err := http.NewRequestWithContext(c.context(), "GET", c.baseURL+"/xrpc/com.atproto.repo.listRecords?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Records []struct {
+				URI   string `json:"uri"`
+				Value struct {
+					DID         string `json:"did"`
+					DisplayName string `json:"displayName"`
+				} `json:"value"`
+			} `json:"records"`
+			Cursor string `json:"cursor"`
+		}
+		if err := c.doJSON(req, &page); err != nil {
+			return nil, fmt.Errorf("failed to list feed generators of %s: %w", repo, err)
+		}
+		for _, record := range page.Records {
+			generators = append(generators, PublishedFeedGenerator{
+				URI:         record.URI,
+				RKey:        record.URI[strings.LastIndex(record.URI, "/")+1:],
+				DID:         record.Value.DID,
+				DisplayName: record.Value.DisplayName,
+			})
+		}
+		if page.Cursor == "" || len(page.Records) == 0 {
+			return generators, nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+// LabelerPolicies lists the label values a labeler emits, with their definitions,
+// in an app.bsky.labeler.service record
+type LabelerPolicies struct {
+	LabelValues           []string                  `json:"labelValues"`
+	LabelValueDefinitions []labeler.ValueDefinition `json:"labelValueDefinitions,omitempty"`
+}
+
+// PutLabelerService creates or replaces the authenticated account's
+// app.bsky.labeler.service record, which makes the account a labeler users can
+// subscribe to
+func (c *Client) PutLabelerService(policies LabelerPolicies) (*RecordRef, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var put RecordRef
+	err := c.postJSON("/xrpc/com.atproto.repo.putRecord", "", map[string]interface{}{
+		"repo":       c.session.DID,
+		"collection": "app.bsky.labeler.service",
+		"rkey":       "self",
+		"record": map[string]interface{}{
+			"$type":     "app.bsky.labeler.service",
+			"policies":  policies,
+			"createdAt": time.Now().UTC().Format(time.RFC3339),
+		},
+	}, &put)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put labeler service: %w", err)
+	}
+	return &put, nil
+}
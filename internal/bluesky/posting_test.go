@@ -0,0 +1,114 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkFacets(t *testing.T) {
+	text := "1. Café owners rally — https://example.com/story?id=1. More at https://open.news"
+	facets := LinkFacets(text)
+
+	require.Len(t, facets, 2)
+	assert.Equal(t, "https://example.com/story?id=1", facets[0].Features[0].URI)
+	assert.Equal(t, "https://example.com/story?id=1", text[facets[0].Index.ByteStart:facets[0].Index.ByteEnd])
+	assert.Equal(t, "https://open.news", facets[1].Features[0].URI)
+	assert.Empty(t, LinkFacets("No links here"))
+}
+
+func TestClient_CreatePost(t *testing.T) {
+	var record map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xrpc/com.atproto.repo.createRecord", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var body struct {
+			Repo   string                 `json:"repo"`
+			Record map[string]interface{} `json:"record"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "did:plc:bot", body.Repo)
+		record = body.Record
+		fmt.Fprint(w, `{"uri":"at://did:plc:bot/app.bsky.feed.post/2","cid":"bafy2"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.CreatePost(NewPost{Text: "Hello"})
+	assert.Error(t, err, "posting requires a session")
+
+	client.session = &Session{AccessJWT: "token", DID: "did:plc:bot"}
+	root := RecordRef{URI: "at://did:plc:bot/app.bsky.feed.post/1", CID: "bafy1"}
+	created, err := client.CreatePost(NewPost{
+		Text:     "Read https://example.com/story",
+		Reply:    &ReplyRefs{Root: root, Parent: root},
+		External: &ExternalEmbed{URI: "https://example.com/story", Title: "Story"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "at://did:plc:bot/app.bsky.feed.post/2", created.URI)
+
+	assert.Equal(t, "app.bsky.feed.post", record["$type"])
+	assert.Len(t, record["facets"], 1)
+	assert.Equal(t, root.URI, record["reply"].(map[string]interface{})["parent"].(map[string]interface{})["uri"])
+	assert.Equal(t, "app.bsky.embed.external", record["embed"].(map[string]interface{})["$type"])
+}
+
+func TestClient_SendDirectMessage(t *testing.T) {
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, chatServiceProxy, r.Header.Get("Atproto-Proxy"))
+		switch r.URL.Path {
+		case "/xrpc/chat.bsky.convo.getConvoForMembers":
+			assert.Equal(t, "did:plc:reader", r.URL.Query().Get("members"))
+			fmt.Fprint(w, `{"convo":{"id":"convo1"}}`)
+		case "/xrpc/chat.bsky.convo.sendMessage":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+			fmt.Fprint(w, `{"id":"msg1"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.session = &Session{AccessJWT: "token", DID: "did:plc:bot"}
+	require.NoError(t, client.SendDirectMessage("did:plc:reader", "Top stories"))
+	assert.Equal(t, "convo1", sent["convoId"])
+	assert.Equal(t, "Top stories", sent["message"].(map[string]interface{})["text"])
+}
+
+func TestClient_PutFeedGenerator(t *testing.T) {
+	var body struct {
+		Repo       string                 `json:"repo"`
+		Collection string                 `json:"collection"`
+		RKey       string                 `json:"rkey"`
+		Record     map[string]interface{} `json:"record"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xrpc/com.atproto.repo.putRecord", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"uri":"at://did:plc:bot/app.bsky.feed.generator/open-news-global","cid":"bafyfeed"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.session = &Session{AccessJWT: "token", DID: "did:plc:bot"}
+
+	_, err := client.PutFeedGenerator("open-news-long", FeedGenerator{DID: "did:web:open.news", DisplayName: "Open News - A Very Long Feed Name"})
+	assert.Error(t, err, "display names are limited to 24 characters")
+
+	put, err := client.PutFeedGenerator("open-news-global", FeedGenerator{DID: "did:web:open.news", DisplayName: "Open News - Global", Description: "Top stories"})
+	require.NoError(t, err)
+	assert.Equal(t, "at://did:plc:bot/app.bsky.feed.generator/open-news-global", put.URI)
+	assert.Equal(t, "did:plc:bot", body.Repo)
+	assert.Equal(t, "app.bsky.feed.generator", body.Collection)
+	assert.Equal(t, "open-news-global", body.RKey)
+	assert.Equal(t, "did:web:open.news", body.Record["did"])
+	assert.Equal(t, "Top stories", body.Record["description"])
+}
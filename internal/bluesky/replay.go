@@ -0,0 +1,169 @@
+package bluesky
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxReplayEventSize is the longest line Replay reads as one event
+const maxReplayEventSize = 4 << 20
+
+// CaptureOptions controls how much of the stream Capture records
+type CaptureOptions struct {
+	URL         string        // Jetstream subscribe URL (default: the one StartConsuming uses)
+	Max         int           // Stop after this many events; 0 for no limit
+	Duration    time.Duration // Stop after this long; 0 for no limit
+	AllAccounts bool          // Record posts from every account instead of the followed sources
+}
+
+// Capture records raw Jetstream events to w, one JSON event per line, in the
+// format Replay reads. It stops when opts.Max events have been written,
+// opts.Duration has passed or the context is cancelled, and returns how many
+// events were written.
+func (fc *FirehoseConsumer) Capture(ctx context.Context, w io.Writer, opts CaptureOptions) (int, error) {
+	if opts.URL == "" {
+		opts.URL = jetstreamURL()
+	}
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	conn, _, err := fc.dialer.DialContext(ctx, opts.URL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to Jetstream: %w", err)
+	}
+	defer conn.Close()
+	// Unblock the read when capture stops
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var dids []string
+	if !opts.AllAccounts {
+		if dids, err = fc.loadWantedDIDs(); err != nil {
+			return 0, fmt.Errorf("failed to load source DIDs: %w", err)
+		}
+	}
+	hello, err := optionsUpdateMessage(dids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode Jetstream options: %w", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, hello); err != nil {
+		return 0, fmt.Errorf("failed to send Jetstream options: %w", err)
+	}
+	log.Printf("Capturing Jetstream events from %d source DIDs", len(dids))
+
+	out := bufio.NewWriter(w)
+	written := 0
+	err = readMessages(ctx, conn, conn.WriteMessage, func(message []byte) error {
+		if _, err := out.Write(append(message, '\n')); err != nil {
+			stop()
+			return err
+		}
+		written++
+		if opts.Max > 0 && written >= opts.Max {
+			stop()
+		}
+		return nil
+	})
+	if flushErr := out.Flush(); flushErr != nil {
+		return written, fmt.Errorf("failed to write events: %w", flushErr)
+	}
+	if ctx.Err() != nil {
+		// Stopping is the expected way out, which shows up as a cancelled
+		// context or a read from the closed connection
+		return written, nil
+	}
+	return written, err
+}
+
+// ReplayOptions controls the pace of Replay
+type ReplayOptions struct {
+	Speed float64 // Multiple of the recorded pace, from the events' time_us; 0 replays as fast as possible
+	Max   int     // Stop after this many events; 0 for no limit
+}
+
+// ReplayResult summarizes a replay
+type ReplayResult struct {
+	Events   int           `json:"events"`  // Events passed to the pipeline
+	Failed   int           `json:"failed"`  // Events the pipeline returned an error for
+	Invalid  int           `json:"invalid"` // Lines that weren't JSON events
+	Duration time.Duration `json:"duration"`
+}
+
+// Replay reads Jetstream events written by Capture, one JSON event per line,
+// and processes each as if it had just arrived from Jetstream. Blank lines are
+// skipped. Processing errors are counted and logged rather than stopping the
+// replay, as they are when consuming.
+func (fc *FirehoseConsumer) Replay(ctx context.Context, r io.Reader, opts ReplayOptions) (*ReplayResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxReplayEventSize)
+
+	result := &ReplayResult{}
+	started := time.Now()
+	var firstEventUS int64
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			result.Duration = time.Since(started)
+			return result, err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var header struct {
+			TimeUS int64 `json:"time_us"`
+		}
+		if err := json.Unmarshal(line, &header); err != nil {
+			result.Invalid++
+			continue
+		}
+
+		// Keep to the recorded pace, scaled by opts.Speed
+		if opts.Speed > 0 && header.TimeUS > 0 {
+			if firstEventUS == 0 {
+				firstEventUS = header.TimeUS
+			}
+			due := started.Add(time.Duration(float64(header.TimeUS-firstEventUS) * float64(time.Microsecond) / opts.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					result.Duration = time.Since(started)
+					return result, ctx.Err()
+				}
+			}
+		}
+
+		result.Events++
+		if err := fc.processJetstreamMessage(line); err != nil {
+			log.Printf("Error processing replayed event %d: %v", result.Events, err)
+			result.Failed++
+		}
+		if opts.Max > 0 && result.Events >= opts.Max {
+			break
+		}
+	}
+	result.Duration = time.Since(started)
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return result, fmt.Errorf("event %d is longer than %d bytes", result.Events+result.Invalid+1, maxReplayEventSize)
+		}
+		return result, fmt.Errorf("failed to read events: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package bluesky
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCapture(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Events are held back until the options arrive, as with requireHello
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		for i := 1; i <= 5; i++ {
+			event := fmt.Sprintf(`{"did":"did:plc:capture","time_us":%d,"kind":"identity","identity":{"did":"did:plc:capture","handle":"h%d.test"}}`, i, i)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+				return
+			}
+		}
+		conn.ReadMessage() // Wait for the client to hang up
+	}))
+	defer server.Close()
+
+	consumer := &FirehoseConsumer{dialer: websocket.DefaultDialer}
+	var out bytes.Buffer
+	written, err := consumer.Capture(context.Background(), &out, CaptureOptions{
+		URL:         "ws" + strings.TrimPrefix(server.URL, "http"),
+		Max:         3,
+		AllAccounts: true,
+	})
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if written != 3 || len(lines) != 3 {
+		t.Fatalf("Expected 3 events, got %d written and %d lines", written, len(lines))
+	}
+	if !strings.Contains(lines[2], `"handle":"h3.test"`) {
+		t.Errorf("Expected events in order, got %s", lines[2])
+	}
+}
+
+func TestReplay(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	start := time.Now().UnixMicro()
+	recording := strings.Join([]string{
+		fmt.Sprintf(`{"did":%q,"time_us":%d,"kind":"identity","identity":{"did":%q,"handle":"replayed-1.test"}}`, source.BlueSkyDID, start, source.BlueSkyDID),
+		"",
+		"not an event",
+		fmt.Sprintf(`{"did":%q,"time_us":%d,"kind":"identity","identity":{"did":%q,"handle":"replayed-2.test"}}`, source.BlueSkyDID, start+50000, source.BlueSkyDID),
+		fmt.Sprintf(`{"did":%q,"time_us":%d,"kind":"identity","identity":{"did":%q,"handle":"replayed-3.test"}}`, source.BlueSkyDID, start+100000, source.BlueSkyDID),
+	}, "\n")
+
+	// At the recorded pace the events span 100ms
+	result, err := consumer.Replay(context.Background(), strings.NewReader(recording), ReplayOptions{Speed: 1, Max: 2})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.Events != 2 || result.Invalid != 1 || result.Failed != 0 {
+		t.Errorf("Expected 2 events and 1 invalid line, got %+v", result)
+	}
+	if result.Duration < 50*time.Millisecond {
+		t.Errorf("Expected the replay to keep to the recorded pace, took %v", result.Duration)
+	}
+
+	var updated models.Source
+	db.First(&updated, "id = ?", source.ID)
+	if updated.Handle != "replayed-2.test" {
+		t.Errorf("Expected the replayed events to be processed in order, got handle %q", updated.Handle)
+	}
+
+	// As fast as possible, every event is processed
+	result, err = consumer.Replay(context.Background(), strings.NewReader(recording), ReplayOptions{})
+	if err != nil || result.Events != 3 {
+		t.Errorf("Expected 3 events, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,162 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RepostRecord represents an app.bsky.feed.repost record from Jetstream
+type RepostRecord struct {
+	Subject   RecordRef `json:"subject"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// processRepostCommit records a repost by a followed source as a share of every
+// article the reposted post links to, in a trace of its own
+func (fc *FirehoseConsumer) processRepostCommit(event *JetstreamEvent) (err error) {
+	source := fc.lookupSource(event.DID)
+	if source == nil {
+		return nil // Not a source we follow
+	}
+	ctx, span := tracing.Start(context.Background(), "firehose repost", attribute.String("source.did", event.DID))
+	defer func() { tracing.End(span, err) }()
+
+	recordBytes, err := json.Marshal(event.Commit.Record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+	var repost RepostRecord
+	if err := json.Unmarshal(recordBytes, &repost); err != nil {
+		return fmt.Errorf("failed to unmarshal repost record: %w", err)
+	}
+	if repost.Subject.URI == "" {
+		return nil
+	}
+
+	linked, err := fc.linkedArticles(ctx, repost.Subject.URI)
+	if err != nil {
+		return err
+	}
+	if len(linked.Articles) == 0 {
+		return nil // The reposted post doesn't link to a news article
+	}
+
+	postedAt := repost.CreatedAt
+	if postedAt.IsZero() {
+		postedAt = time.Now()
+	}
+	for i := range linked.Articles {
+		err := fc.recordShare(ctx, source, &linked.Articles[i], share{
+			PostURI:     commitURI(event),
+			PostCID:     event.Commit.CID,
+			Text:        linked.Text,
+			ShareType:   models.ShareTypeRepost,
+			OriginalURI: repost.Subject.URI,
+			PostedAt:    postedAt,
+			Labels:      linked.Labels,
+		})
+		if err != nil {
+			log.Printf("Error recording repost of %s by %s: %v", repost.Subject.URI, source.Handle, err)
+		}
+	}
+	return nil
+}
+
+// linkedPost is a reposted or quoted post with the articles it links to
+type linkedPost struct {
+	Articles []models.Article
+	Text     string
+	Labels   []string
+}
+
+// linkedArticles returns the articles a reposted or quoted post links to, with the post's
+// text and labels. Posts already shared by a source are resolved from the database; others
+// are fetched from Bluesky and their links processed like any new post.
+func (fc *FirehoseConsumer) linkedArticles(ctx context.Context, subjectURI string) (linkedPost, error) {
+	var linked linkedPost
+	var shares []models.SourceArticle
+	if err := fc.db.WithContext(ctx).Preload("Article").Where("post_uri = ?", subjectURI).Find(&shares).Error; err != nil {
+		return linked, fmt.Errorf("failed to look up post %s: %w", subjectURI, err)
+	}
+	if len(shares) > 0 {
+		linked.Articles = make([]models.Article, len(shares))
+		for i, sa := range shares {
+			linked.Articles[i] = sa.Article
+		}
+		linked.Text, linked.Labels = shares[0].PostText, shares[0].Labels
+		return linked, nil
+	}
+
+	if fc.client == nil {
+		return linked, nil
+	}
+	posts, err := fc.client.WithContext(ctx).GetPosts([]string{subjectURI})
+	if err != nil {
+		return linked, fmt.Errorf("failed to fetch post %s: %w", subjectURI, err)
+	}
+	if len(posts) == 0 {
+		return linked, nil // Deleted or not visible
+	}
+
+	for _, link := range ExtractLinks(&posts[0]) {
+		article, err := fc.resolveArticle(ctx, link, posts[0].Author.DID)
+		if err != nil {
+			log.Printf("Error processing link %s from %s: %v", link, subjectURI, err)
+			continue
+		}
+		if article != nil {
+			linked.Articles = append(linked.Articles, *article)
+		}
+	}
+	linked.Text, linked.Labels = posts[0].Record.Text, posts[0].LabelValues()
+	return linked, nil
+}
+
+// processQuote records a quote post by a followed source as a share of every article
+// the quoted post links to, attributed to the quoting source with its own text
+func (fc *FirehoseConsumer) processQuote(ctx context.Context, source *models.Source, post *PostRecord, event *JetstreamEvent, quotedURI string) error {
+	linked, err := fc.linkedArticles(ctx, quotedURI)
+	if err != nil {
+		return err
+	}
+
+	// The quoted post is shown with the quote, so its labels apply too
+	labels := mergeLabels(labelValues(post.Labels, nil), linked.Labels)
+	for i := range linked.Articles {
+		err := fc.recordShare(ctx, source, &linked.Articles[i], share{
+			PostURI:     fmt.Sprintf("at://%s/%s/%s", event.DID, jetstreamPostCollection, event.Commit.RKey),
+			PostCID:     event.Commit.CID,
+			Text:        post.Text,
+			ShareType:   ShareType(post.Reply, post.Embed),
+			OriginalURI: quotedURI,
+			PostedAt:    post.CreatedAt,
+			Labels:      labels,
+		})
+		if err != nil {
+			log.Printf("Error recording quote of %s by %s: %v", quotedURI, source.Handle, err)
+		}
+	}
+	return nil
+}
+
+// ShareType returns how a post shares the articles it links to, from the
+// structure of its record: a reply when it answers another post, a quote when it
+// embeds one, and an original post otherwise. Reposts are records of their own,
+// see processRepostCommit.
+func ShareType(reply *Reply, embed *Embed) string {
+	switch {
+	case reply != nil:
+		return models.ShareTypeReply
+	case embed.QuotedURI() != "":
+		return models.ShareTypeQuote
+	}
+	return models.ShareTypePost
+}
@@ -0,0 +1,96 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+)
+
+func TestProcessRepostCommit(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	// Another account's post linking to an article we already track
+	original := models.Source{BlueSkyDID: "did:plc:testoriginal", Handle: "original.bsky.social"}
+	db.Create(&original)
+	article := models.Article{URL: "https://example.com/reposted", Title: "Reposted story"}
+	db.Create(&article)
+	originalURI := "at://did:plc:testoriginal/app.bsky.feed.post/orig"
+	db.Create(&models.SourceArticle{SourceID: original.ID, ArticleID: article.ID, PostURI: originalURI, PostText: "Big news", PostedAt: time.Now()})
+
+	data, _ := json.Marshal(JetstreamEvent{
+		DID:  source.BlueSkyDID,
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  "create",
+			Collection: "app.bsky.feed.repost",
+			RKey:       "rp1",
+			CID:        "bafyrepost",
+			Record: map[string]interface{}{
+				"$type":     "app.bsky.feed.repost",
+				"subject":   map[string]interface{}{"uri": originalURI, "cid": "bafyorig"},
+				"createdAt": time.Now().Format(time.RFC3339),
+			},
+		},
+	})
+	if err := consumer.processJetstreamMessage(data); err != nil {
+		t.Fatalf("processJetstreamMessage failed: %v", err)
+	}
+
+	var repost models.SourceArticle
+	if err := db.Where("source_id = ? AND article_id = ?", source.ID, article.ID).First(&repost).Error; err != nil {
+		t.Fatalf("Expected the repost to be recorded as a share: %v", err)
+	}
+	if !repost.IsRepost || repost.ShareType != models.ShareTypeRepost || repost.OriginalURI != originalURI || repost.PostText != "Big news" {
+		t.Errorf("Unexpected repost share: repost=%v type=%q original=%q text=%q", repost.IsRepost, repost.ShareType, repost.OriginalURI, repost.PostText)
+	}
+	if repost.PostURI != "at://"+source.BlueSkyDID+"/app.bsky.feed.repost/rp1" {
+		t.Errorf("Unexpected repost URI %q", repost.PostURI)
+	}
+}
+
+func TestProcessQuotePost(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db}
+
+	original := models.Source{BlueSkyDID: "did:plc:testquoted", Handle: "quoted.bsky.social"}
+	db.Create(&original)
+	article := models.Article{URL: "https://example.com/quoted", Title: "Quoted story"}
+	db.Create(&article)
+	quotedURI := "at://did:plc:testquoted/app.bsky.feed.post/q"
+	db.Create(&models.SourceArticle{SourceID: original.ID, ArticleID: article.ID, PostURI: quotedURI, PostedAt: time.Now()})
+
+	data, _ := json.Marshal(JetstreamEvent{
+		DID:  source.BlueSkyDID,
+		Kind: "commit",
+		Commit: &JetstreamCommit{
+			Operation:  "create",
+			Collection: "app.bsky.feed.post",
+			RKey:       "quote1",
+			Record: map[string]interface{}{
+				"$type":     "app.bsky.feed.post",
+				"text":      "This is the story everyone should read",
+				"createdAt": time.Now().Format(time.RFC3339),
+				"embed": map[string]interface{}{
+					"$type":  "app.bsky.embed.record",
+					"record": map[string]interface{}{"uri": quotedURI, "cid": "bafyq"},
+				},
+			},
+		},
+	})
+	if err := consumer.processJetstreamMessage(data); err != nil {
+		t.Fatalf("processJetstreamMessage failed: %v", err)
+	}
+
+	var quote models.SourceArticle
+	if err := db.Where("source_id = ? AND article_id = ?", source.ID, article.ID).First(&quote).Error; err != nil {
+		t.Fatalf("Expected the quote to be recorded as a share: %v", err)
+	}
+	if quote.ShareType != models.ShareTypeQuote || quote.IsRepost || quote.OriginalURI != quotedURI || quote.PostText != "This is the story everyone should read" {
+		t.Errorf("Unexpected quote share: type=%q original=%q text=%q", quote.ShareType, quote.OriginalURI, quote.PostText)
+	}
+}
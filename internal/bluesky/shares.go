@@ -0,0 +1,144 @@
+package bluesky
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// shareBatchSize is how many buffered shares trigger a write right away
+	shareBatchSize = 100
+
+	// shareFlushInterval is the longest a share waits in the buffer
+	shareFlushInterval = 2 * time.Second
+)
+
+// shareWriter buffers the shares recorded from Jetstream and inserts them in
+// batches, so a burst of posts costs one insert per batch instead of a lookup
+// and an insert per link. Shares already stored are skipped by the unique
+// index rather than checked for first.
+type shareWriter struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending []models.SourceArticle
+}
+
+// newShareWriter creates an empty share buffer
+func newShareWriter(db *gorm.DB) *shareWriter {
+	return &shareWriter{db: db}
+}
+
+// run flushes the buffer every shareFlushInterval until the context is
+// cancelled, flushing once more on the way out
+func (w *shareWriter) run(ctx context.Context) {
+	ticker := time.NewTicker(shareFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-ctx.Done():
+			w.flush()
+			return
+		}
+	}
+}
+
+// add buffers a share, writing the buffer when it's full
+func (w *shareWriter) add(share models.SourceArticle) {
+	w.mu.Lock()
+	w.pending = append(w.pending, share)
+	full := len(w.pending) >= shareBatchSize
+	w.mu.Unlock()
+
+	if full {
+		w.flush()
+	}
+}
+
+// discard drops the buffered shares of a post and its reposts and quotes,
+// returning how many there were
+func (w *shareWriter) discard(postURI string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	kept := w.pending[:0]
+	for _, share := range w.pending {
+		if share.PostURI != postURI && share.OriginalURI != postURI {
+			kept = append(kept, share)
+		}
+	}
+	discarded := len(w.pending) - len(kept)
+	w.pending = kept
+	return discarded
+}
+
+// flush inserts the buffered shares and returns how many were new. When the
+// batch fails, for example because an article was deleted in the meantime,
+// the shares are inserted one at a time so only the bad ones are lost.
+func (w *shareWriter) flush() int {
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return 0
+	}
+
+	result := w.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&batch)
+	if result.Error == nil {
+		log.Printf("Recorded %d new shares (%d buffered)", result.RowsAffected, len(batch))
+		if result.RowsAffected > 0 {
+			w.markShared(batch)
+		}
+		return int(result.RowsAffected)
+	}
+
+	log.Printf("Failed to insert %d shares, retrying one at a time: %v", len(batch), result.Error)
+	inserted := 0
+	var recorded []models.SourceArticle
+	for i := range batch {
+		result := w.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&batch[i])
+		if result.Error != nil {
+			log.Printf("Failed to record share %s: %v", batch[i].PostURI, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			recorded = append(recorded, batch[i])
+		}
+		inserted += int(result.RowsAffected)
+	}
+	w.markShared(recorded)
+	return inserted
+}
+
+// markShared clears IsUnshared on the articles of recorded shares, such as
+// ones ingested from their site's feeds, rolls up their engagement and flags
+// the articles and their sources for rescoring
+func (w *shareWriter) markShared(shares []models.SourceArticle) {
+	articleIDs := make([]uuid.UUID, 0, len(shares))
+	sourceIDs := make([]uuid.UUID, 0, len(shares))
+	for _, share := range shares {
+		articleIDs = append(articleIDs, share.ArticleID)
+		sourceIDs = append(sourceIDs, share.SourceID)
+	}
+	if err := models.MarkShared(w.db, articleIDs...); err != nil {
+		log.Printf("Failed to mark shared articles: %v", err)
+	}
+	if err := models.AggregateEngagement(w.db, articleIDs...); err != nil {
+		log.Printf("Failed to aggregate engagement of shared articles: %v", err)
+	}
+	if err := models.MarkForRescore(w.db, articleIDs, sourceIDs); err != nil {
+		log.Printf("Failed to flag shared articles for rescoring: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package bluesky
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestShareWriterBatches(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	article := models.Article{URL: "https://example.com/batched", Title: "Batched story"}
+	db.Create(&article)
+
+	consumer := &FirehoseConsumer{db: db, shares: newShareWriter(db)}
+	postedAt := time.Now().Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		uri := fmt.Sprintf("at://did:plc:test123456789/app.bsky.feed.post/b%d", i)
+		if err := consumer.recordShare(context.Background(), source, &article, share{PostURI: uri, PostedAt: postedAt}); err != nil {
+			t.Fatalf("recordShare failed: %v", err)
+		}
+	}
+	// The same post arriving twice, as on a reconnect
+	consumer.recordShare(context.Background(), source, &article, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/b0", PostedAt: postedAt})
+
+	var count int64
+	db.Model(&models.SourceArticle{}).Where("article_id = ?", article.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("Expected shares to wait in the buffer, found %d", count)
+	}
+
+	if discarded := consumer.shares.discard("at://did:plc:test123456789/app.bsky.feed.post/b2"); discarded != 1 {
+		t.Errorf("Expected the deleted post's share to be discarded, got %d", discarded)
+	}
+	if inserted := consumer.shares.flush(); inserted != 2 {
+		t.Errorf("Expected 2 new shares, got %d", inserted)
+	}
+	db.Model(&models.SourceArticle{}).Where("article_id = ?", article.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 stored shares, got %d", count)
+	}
+
+	// A share that can't be stored doesn't take the rest of its batch with it
+	consumer.recordShare(context.Background(), source, &models.Article{ID: uuid.New()}, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/orphan", PostedAt: postedAt})
+	consumer.recordShare(context.Background(), source, &article, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/b3", PostedAt: postedAt})
+	if inserted := consumer.shares.flush(); inserted != 1 {
+		t.Errorf("Expected the valid share to be stored, got %d", inserted)
+	}
+}
+
+func TestRecordShareNewArticle(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+	consumer := &FirehoseConsumer{db: db, shares: newShareWriter(db)}
+
+	article := models.Article{URL: "https://example.com/new-story", Title: "New story", IsReachable: true}
+	if err := consumer.recordShare(context.Background(), source, &article, share{PostURI: "at://did:plc:test123456789/app.bsky.feed.post/n1", PostedAt: time.Now()}); err != nil {
+		t.Fatalf("recordShare failed: %v", err)
+	}
+	var stored models.Article
+	if err := db.Preload("SourceArticles").Where("url = ?", article.URL).First(&stored).Error; err != nil {
+		t.Fatalf("Expected the new article to be stored: %v", err)
+	}
+	if len(stored.SourceArticles) != 1 {
+		t.Errorf("Expected the new article to be stored with its share, not buffered, got %d shares", len(stored.SourceArticles))
+	}
+	if stored.QualityScore == 0 {
+		t.Error("Expected the new article to be scored")
+	}
+
+	// A share that can't be stored takes its new article with it
+	unknown := &models.Source{ID: uuid.New(), Handle: "unknown.test"}
+	orphan := models.Article{URL: "https://example.com/orphan-story", Title: "Orphan"}
+	if err := consumer.recordShare(context.Background(), unknown, &orphan, share{PostURI: "at://did:plc:unknown/app.bsky.feed.post/n2", PostedAt: time.Now()}); err == nil {
+		t.Fatal("Expected the share of an unknown source to fail")
+	}
+	if orphan.ID != uuid.Nil {
+		t.Error("Expected the article to be left unstored")
+	}
+	var count int64
+	db.Model(&models.Article{}).Where("url = ?", orphan.URL).Count(&count)
+	if count != 0 {
+		t.Error("Expected the article to be rolled back with its share")
+	}
+}
@@ -0,0 +1,287 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSkeletonLimit is the largest limit app.bsky.feed.getFeedSkeleton accepts
+	MaxSkeletonLimit = 100
+
+	// maxFeedContext is the lexicon's length limit for a skeleton item's feedContext
+	maxFeedContext = 2000
+
+	// SkeletonReasonRepost marks a skeleton item served because of a repost
+	SkeletonReasonRepost = "app.bsky.feed.defs#skeletonReasonRepost"
+
+	// SkeletonReasonPin marks a skeleton item pinned to the top of the feed
+	SkeletonReasonPin = "app.bsky.feed.defs#skeletonReasonPin"
+)
+
+var (
+	// didPattern matches the DID syntax of the AT Protocol
+	didPattern = regexp.MustCompile(`^did:[a-z]+:[a-zA-Z0-9._:%-]*[a-zA-Z0-9._-]$`)
+
+	// recordKeyPattern matches the record key syntax of the AT Protocol
+	recordKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_~.:-]{1,512}$`)
+)
+
+// FeedSkeleton is the output of app.bsky.feed.getFeedSkeleton
+type FeedSkeleton struct {
+	Feed   []SkeletonFeedPost `json:"feed"`
+	Cursor string             `json:"cursor,omitempty"` // Set when there are more items
+}
+
+// SkeletonFeedPost is an app.bsky.feed.defs#skeletonFeedPost: a post the
+// AppView hydrates, and optionally why it's in the feed
+type SkeletonFeedPost struct {
+	Post        string          `json:"post"` // AT URI of an app.bsky.feed.post record
+	Reason      *SkeletonReason `json:"reason,omitempty"`
+	FeedContext string          `json:"feedContext,omitempty"` // Passed back in interactions, at most 2000 characters
+}
+
+// SkeletonReason is one of the skeleton reasons, a repost or a pin
+type SkeletonReason struct {
+	Type   string `json:"$type"`
+	Repost string `json:"repost,omitempty"` // AT URI of the repost, for SkeletonReasonRepost
+}
+
+// PostURI returns the AT URI of a post record
+func PostURI(did, rkey string) string {
+	return "at://" + did + "/app.bsky.feed.post/" + rkey
+}
+
+// IsPostURI reports whether uri is the AT URI of a post record in a DID's
+// repository, the only kind of URI a feed skeleton can serve
+func IsPostURI(uri string) bool {
+	did, collection, rkey, ok := splitRecordURI(uri)
+	return ok && collection == "app.bsky.feed.post" && didPattern.MatchString(did) && validRecordKey(rkey)
+}
+
+// splitRecordURI splits at://<authority>/<collection>/<rkey>
+func splitRecordURI(uri string) (authority, collection, rkey string, ok bool) {
+	rest, found := strings.CutPrefix(uri, "at://")
+	if !found || strings.ContainsAny(rest, "?#") {
+		return "", "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// validRecordKey reports whether rkey is a valid record key
+func validRecordKey(rkey string) bool {
+	return recordKeyPattern.MatchString(rkey) && rkey != "." && rkey != ".."
+}
+
+// ValidateSkeleton checks a getFeedSkeleton response body against the lexicon:
+// a feed array of at most limit items, each with the AT URI of a post and
+// optionally a known reason and a short feedContext, and a non-empty cursor
+// when there is one. Posts may not repeat. It returns every violation found.
+func ValidateSkeleton(body []byte, limit int) error {
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal(body, &output); err != nil {
+		return fmt.Errorf("response isn't a JSON object: %w", err)
+	}
+
+	var problems []error
+	rawFeed, ok := output["feed"]
+	if !ok {
+		return errors.New("response has no feed")
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(rawFeed, &items); err != nil || items == nil {
+		return errors.New("feed isn't an array")
+	}
+	if len(items) > limit {
+		problems = append(problems, fmt.Errorf("feed has %d items, more than the limit of %d", len(items), limit))
+	}
+
+	seen := make(map[string]bool, len(items))
+	for i, raw := range items {
+		post, err := validateSkeletonItem(raw)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("feed[%d]: %w", i, err))
+			continue
+		}
+		if seen[post] {
+			problems = append(problems, fmt.Errorf("feed[%d]: post %s is repeated", i, post))
+		}
+		seen[post] = true
+	}
+
+	if rawCursor, ok := output["cursor"]; ok {
+		var cursor string
+		if err := json.Unmarshal(rawCursor, &cursor); err != nil {
+			problems = append(problems, errors.New("cursor isn't a string"))
+		} else if cursor == "" {
+			problems = append(problems, errors.New("cursor is empty; leave it out when there are no more items"))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// validateSkeletonItem checks one skeletonFeedPost and returns its post URI
+func validateSkeletonItem(raw json.RawMessage) (string, error) {
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return "", errors.New("item isn't an object")
+	}
+
+	var post string
+	if err := json.Unmarshal(item["post"], &post); err != nil {
+		return "", errors.New("post must be an AT URI string")
+	}
+	if !IsPostURI(post) {
+		return "", fmt.Errorf("post %q isn't the AT URI of a post", post)
+	}
+
+	if rawReason, ok := item["reason"]; ok {
+		var reason SkeletonReason
+		if err := json.Unmarshal(rawReason, &reason); err != nil {
+			return "", errors.New("reason isn't an object")
+		}
+		switch reason.Type {
+		case SkeletonReasonPin:
+		case SkeletonReasonRepost:
+			if _, collection, rkey, ok := splitRecordURI(reason.Repost); !ok || collection != "app.bsky.feed.repost" || !validRecordKey(rkey) {
+				return "", fmt.Errorf("repost reason has invalid repost URI %q", reason.Repost)
+			}
+		default:
+			return "", fmt.Errorf("unknown reason type %q", reason.Type)
+		}
+	}
+
+	if rawContext, ok := item["feedContext"]; ok {
+		var feedContext string
+		if err := json.Unmarshal(rawContext, &feedContext); err != nil {
+			return "", errors.New("feedContext isn't a string")
+		}
+		if utf8.RuneCountInString(feedContext) > maxFeedContext {
+			return "", fmt.Errorf("feedContext is longer than %d characters", maxFeedContext)
+		}
+	}
+	return post, nil
+}
+
+// CheckFeedSkeleton requests a feed from a feed generator at baseURL and checks
+// what a client relies on: each page passes ValidateSkeleton, following cursors
+// for up to maxPages pages never repeats a post or a cursor, limit is honored,
+// and an unknown feed is answered with an XRPC error. Feeds that need a signed-in
+// user are only checked for asking for authentication.
+func CheckFeedSkeleton(httpClient *http.Client, baseURL, feedURI string, limit, maxPages int) error {
+	status, body, err := getSkeleton(httpClient, baseURL, feedURI, limit, "")
+	if err != nil {
+		return err
+	}
+	if status == http.StatusUnauthorized {
+		return validateXRPCError(body)
+	}
+
+	var problems []error
+	served := make(map[string]int)
+	cursors := make(map[string]bool)
+	for page := 1; ; page++ {
+		if status != http.StatusOK {
+			problems = append(problems, fmt.Errorf("page %d: status %d", page, status))
+			break
+		}
+		if err := ValidateSkeleton(body, limit); err != nil {
+			problems = append(problems, fmt.Errorf("page %d: %w", page, err))
+			break
+		}
+		var skeleton FeedSkeleton
+		if err := json.Unmarshal(body, &skeleton); err != nil {
+			problems = append(problems, fmt.Errorf("page %d: %w", page, err))
+			break
+		}
+		for _, item := range skeleton.Feed {
+			if first, ok := served[item.Post]; ok {
+				problems = append(problems, fmt.Errorf("page %d: post %s was already served on page %d", page, item.Post, first))
+			} else {
+				served[item.Post] = page
+			}
+		}
+
+		if skeleton.Cursor == "" || page >= maxPages {
+			break
+		}
+		if len(skeleton.Feed) == 0 {
+			problems = append(problems, fmt.Errorf("page %d: empty page has a cursor, so clients never stop paging", page))
+			break
+		}
+		if cursors[skeleton.Cursor] {
+			problems = append(problems, fmt.Errorf("page %d: cursor %q was already returned", page, skeleton.Cursor))
+			break
+		}
+		cursors[skeleton.Cursor] = true
+
+		if status, body, err = getSkeleton(httpClient, baseURL, feedURI, limit, skeleton.Cursor); err != nil {
+			return err
+		}
+	}
+
+	// One item per page when asking for one
+	if status, body, err := getSkeleton(httpClient, baseURL, feedURI, 1, ""); err != nil {
+		return err
+	} else if status != http.StatusOK {
+		problems = append(problems, fmt.Errorf("limit=1: status %d", status))
+	} else if err := ValidateSkeleton(body, 1); err != nil {
+		problems = append(problems, fmt.Errorf("limit=1: %w", err))
+	}
+
+	// An unknown feed gets an XRPC error rather than an empty feed
+	if did, _, _, ok := splitRecordURI(feedURI); ok {
+		unknown := "at://" + did + "/app.bsky.feed.generator/does-not-exist"
+		if status, body, err := getSkeleton(httpClient, baseURL, unknown, limit, ""); err != nil {
+			return err
+		} else if status < 400 || status >= 500 {
+			problems = append(problems, fmt.Errorf("unknown feed: status %d, expected a 4xx XRPC error", status))
+		} else if err := validateXRPCError(body); err != nil {
+			problems = append(problems, fmt.Errorf("unknown feed: %w", err))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// getSkeleton requests one page of a feed skeleton
+func getSkeleton(httpClient *http.Client, baseURL, feedURI string, limit int, cursor string) (int, []byte, error) {
+	query := url.Values{"feed": {feedURI}, "limit": {strconv.Itoa(limit)}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	resp, err := httpClient.Get(baseURL + "/xrpc/app.bsky.feed.getFeedSkeleton?" + query.Encode())
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to request feed skeleton: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read feed skeleton: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// validateXRPCError checks an error body has the XRPC shape, a string error
+// name and optionally a string message
+func validateXRPCError(body []byte) error {
+	var output struct {
+		Error   *string `json:"error"`
+		Message *string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &output); err != nil || output.Error == nil || *output.Error == "" {
+		return fmt.Errorf("error body %s doesn't name the error in a string \"error\" field", body)
+	}
+	return nil
+}
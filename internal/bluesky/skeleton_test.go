@@ -0,0 +1,112 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPostURI(t *testing.T) {
+	assert.True(t, IsPostURI("at://did:plc:abc123/app.bsky.feed.post/3kq2xyz"))
+	assert.True(t, IsPostURI(PostURI("did:web:news.example", "3kq2xyz")))
+	assert.False(t, IsPostURI("at://reuters.com/app.bsky.feed.post/3kq2xyz"), "handles aren't hydrated")
+	assert.False(t, IsPostURI("at://did:plc:abc123/app.bsky.feed.like/3kq2xyz"))
+	assert.False(t, IsPostURI("at://did:plc:abc123/app.bsky.feed.post/"))
+	assert.False(t, IsPostURI("at://did:plc:abc123/app.bsky.feed.post/a/b"))
+	assert.False(t, IsPostURI("https://bsky.app/profile/did:plc:abc123/post/3kq2xyz"))
+}
+
+func TestValidateSkeleton(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limit   int
+		problem string // Expected in the error; empty when the body conforms
+	}{
+		{"empty feed", `{"feed":[]}`, 30, ""},
+		{"posts with cursor", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1"},{"post":"at://did:plc:b/app.bsky.feed.post/2","feedContext":"Shared by @reuters"}],"cursor":"2"}`, 30, ""},
+		{"pin and repost reasons", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1","reason":{"$type":"app.bsky.feed.defs#skeletonReasonPin"}},{"post":"at://did:plc:a/app.bsky.feed.post/2","reason":{"$type":"app.bsky.feed.defs#skeletonReasonRepost","repost":"at://did:plc:c/app.bsky.feed.repost/9"}}]}`, 30, ""},
+		{"not an object", `[]`, 30, "isn't a JSON object"},
+		{"missing feed", `{"cursor":"1"}`, 30, "no feed"},
+		{"null feed", `{"feed":null}`, 30, "isn't an array"},
+		{"hydrated post", `{"feed":[{"post":{"uri":"at://did:plc:a/app.bsky.feed.post/1"}}]}`, 30, "post must be an AT URI string"},
+		{"handle post URI", `{"feed":[{"post":"at://reuters.com/app.bsky.feed.post/1"}]}`, 30, "isn't the AT URI of a post"},
+		{"over limit", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1"},{"post":"at://did:plc:a/app.bsky.feed.post/2"}]}`, 1, "more than the limit"},
+		{"repeated post", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1"},{"post":"at://did:plc:a/app.bsky.feed.post/1"}]}`, 30, "repeated"},
+		{"unknown reason", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1","reason":{"$type":"app.bsky.feed.defs#reasonRepost"}}]}`, 30, "unknown reason"},
+		{"repost without URI", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1","reason":{"$type":"app.bsky.feed.defs#skeletonReasonRepost"}}]}`, 30, "invalid repost URI"},
+		{"long feed context", `{"feed":[{"post":"at://did:plc:a/app.bsky.feed.post/1","feedContext":"` + strings.Repeat("x", 2001) + `"}]}`, 30, "feedContext is longer"},
+		{"numeric cursor", `{"feed":[],"cursor":2}`, 30, "cursor isn't a string"},
+		{"empty cursor", `{"feed":[],"cursor":""}`, 30, "cursor is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSkeleton([]byte(tt.body), tt.limit)
+			if tt.problem == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.problem)
+		})
+	}
+}
+
+// skeletonServer serves a feed of total posts in pages, offset cursors and the
+// XRPC errors of the lexicon. repeatPage makes every cursor serve the first page.
+func skeletonServer(t *testing.T, total int, repeatPage bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xrpc/app.bsky.feed.getFeedSkeleton", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Query().Get("feed"), "/news") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"UnknownFeed","message":"Feed not found"}`))
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		if repeatPage {
+			offset = 0
+		}
+
+		skeleton := FeedSkeleton{Feed: []SkeletonFeedPost{}}
+		for i := offset; i < total && i < offset+limit; i++ {
+			skeleton.Feed = append(skeleton.Feed, SkeletonFeedPost{Post: PostURI("did:plc:source", strconv.Itoa(i))})
+		}
+		if offset+limit < total {
+			skeleton.Cursor = strconv.Itoa(offset + limit)
+			if repeatPage {
+				skeleton.Cursor = r.URL.Query().Get("cursor") + "x"
+			}
+		}
+		json.NewEncoder(w).Encode(skeleton)
+	}))
+}
+
+func TestCheckFeedSkeleton(t *testing.T) {
+	server := skeletonServer(t, 25, false)
+	defer server.Close()
+	assert.NoError(t, CheckFeedSkeleton(server.Client(), server.URL, "at://did:web:feeds.example/app.bsky.feed.generator/news", 10, 5))
+
+	repeating := skeletonServer(t, 25, true)
+	defer repeating.Close()
+	err := CheckFeedSkeleton(repeating.Client(), repeating.URL, "at://did:web:feeds.example/app.bsky.feed.generator/news", 10, 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already served on page 1")
+
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Authentication required"}}`))
+	}))
+	defer unauthorized.Close()
+	err = CheckFeedSkeleton(unauthorized.Client(), unauthorized.URL, "at://did:web:feeds.example/app.bsky.feed.generator/news", 10, 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `string "error" field`)
+}
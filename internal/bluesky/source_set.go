@@ -0,0 +1,160 @@
+package bluesky
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// sourceSetDeltaInterval is how often sources added since the last check
+	// are picked up
+	sourceSetDeltaInterval = 5 * time.Second
+
+	// sourceSetReloadInterval is how often the whole set is reloaded, which
+	// drops deleted sources
+	sourceSetReloadInterval = 10 * time.Minute
+
+	// sourceSetOverlap is how far before the newest source already seen each
+	// delta looks again, so a source committed after a newer one isn't missed
+	sourceSetOverlap = time.Minute
+)
+
+// sourceSet holds the DIDs of every source in memory, so events from accounts
+// that aren't sources are dropped without a query. It's kept current with a
+// small query for new sources every few seconds and a full reload now and then.
+type sourceSet struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	dids     map[string]bool
+	newestAt time.Time // Creation time of the newest source loaded
+}
+
+// sourceDID is the part of a source the set loads
+type sourceDID struct {
+	BlueSkyDID string
+	CreatedAt  time.Time
+}
+
+// newSourceSet creates an empty source set; call reload to fill it
+func newSourceSet(db *gorm.DB) *sourceSet {
+	return &sourceSet{db: db, dids: make(map[string]bool)}
+}
+
+// has reports whether did belongs to a source
+func (s *sourceSet) has(did string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dids[did]
+}
+
+// size returns the number of source DIDs in the set
+func (s *sourceSet) size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.dids)
+}
+
+// reload replaces the set with every source DID in the database
+func (s *sourceSet) reload() error {
+	var sources []sourceDID
+	if err := s.query().Find(&sources).Error; err != nil {
+		return err
+	}
+
+	dids := make(map[string]bool, len(sources))
+	var newestAt time.Time
+	for _, source := range sources {
+		dids[source.BlueSkyDID] = true
+		if source.CreatedAt.After(newestAt) {
+			newestAt = source.CreatedAt
+		}
+	}
+
+	s.mu.Lock()
+	s.dids = dids
+	s.newestAt = newestAt
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh adds the sources created since the last load and returns how many
+// weren't in the set yet
+func (s *sourceSet) refresh() (int, error) {
+	s.mu.RLock()
+	since := s.newestAt.Add(-sourceSetOverlap)
+	s.mu.RUnlock()
+
+	var sources []sourceDID
+	if err := s.query().Where("created_at > ?", since).Find(&sources).Error; err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added := 0
+	for _, source := range sources {
+		if !s.dids[source.BlueSkyDID] {
+			s.dids[source.BlueSkyDID] = true
+			added++
+		}
+		if source.CreatedAt.After(s.newestAt) {
+			s.newestAt = source.CreatedAt
+		}
+	}
+	return added, nil
+}
+
+// query selects the DIDs and creation times of sources
+func (s *sourceSet) query() *gorm.DB {
+	return s.db.Model(&models.Source{}).
+		Select("blue_sky_d_id, created_at").
+		Where("blue_sky_d_id <> ''")
+}
+
+// run keeps the set current until the context is cancelled
+func (s *sourceSet) run(ctx context.Context) {
+	delta := time.NewTicker(sourceSetDeltaInterval)
+	defer delta.Stop()
+	reload := time.NewTicker(sourceSetReloadInterval)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-delta.C:
+			added, err := s.refresh()
+			if err != nil {
+				log.Printf("Failed to check for new sources: %v", err)
+				continue
+			}
+			if added > 0 {
+				log.Printf("Matching posts from %d new sources", added)
+			}
+		case <-reload.C:
+			if err := s.reload(); err != nil {
+				log.Printf("Failed to reload source DIDs: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lookupSource loads the source with did, or returns nil when the account
+// isn't a source. While consuming, accounts missing from the source set are
+// ruled out without a query.
+func (fc *FirehoseConsumer) lookupSource(did string) *models.Source {
+	if sources := fc.sources; sources != nil && !sources.has(did) {
+		return nil
+	}
+	var source models.Source
+	if err := fc.db.Where("blue_sky_d_id = ?", did).First(&source).Error; err != nil {
+		return nil
+	}
+	return &source
+}
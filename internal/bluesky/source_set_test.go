@@ -0,0 +1,52 @@
+package bluesky
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+)
+
+func TestSourceSet(t *testing.T) {
+	db := setupTestDB(t)
+	source := createTestSource(t, db)
+
+	sources := newSourceSet(db)
+	if err := sources.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if !sources.has(source.BlueSkyDID) {
+		t.Error("Expected the source to be loaded")
+	}
+
+	// A source added while consuming is picked up by the next refresh
+	added := models.Source{BlueSkyDID: "did:plc:testaddedsource", Handle: "added.test", CreatedAt: time.Now()}
+	db.Create(&added)
+	if sources.has(added.BlueSkyDID) {
+		t.Fatal("Expected the new source to be missing before a refresh")
+	}
+	if count, err := sources.refresh(); err != nil || count != 1 {
+		t.Fatalf("Expected 1 new source, got %d (%v)", count, err)
+	}
+	if !sources.has(added.BlueSkyDID) {
+		t.Error("Expected the new source after a refresh")
+	}
+	if count, _ := sources.refresh(); count != 0 {
+		t.Errorf("Expected no new sources on the second refresh, got %d", count)
+	}
+
+	// Deleted sources only leave with a full reload
+	db.Delete(&added)
+	sources.reload()
+	if sources.has(added.BlueSkyDID) {
+		t.Error("Expected the deleted source to be dropped by a reload")
+	}
+
+	consumer := &FirehoseConsumer{db: db, sources: sources}
+	if found := consumer.lookupSource(source.BlueSkyDID); found == nil || found.ID != source.ID {
+		t.Errorf("Expected the source to be found, got %v", found)
+	}
+	if found := consumer.lookupSource("did:plc:notasource"); found != nil {
+		t.Errorf("Expected no source for an unknown account, got %v", found)
+	}
+}
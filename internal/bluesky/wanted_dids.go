@@ -0,0 +1,92 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	"open-news/internal/models"
+)
+
+// jetstreamMaxWantedDIDs is the most DIDs Jetstream accepts in a subscription filter
+const jetstreamMaxWantedDIDs = 10000
+
+// Collections the consumer subscribes to
+const (
+	jetstreamPostCollection   = "app.bsky.feed.post"
+	jetstreamRepostCollection = "app.bsky.feed.repost"
+)
+
+// jetstreamCollections lists every collection requested from Jetstream
+var jetstreamCollections = []string{jetstreamPostCollection, jetstreamRepostCollection}
+
+// jetstreamOptionsUpdate is the subscriber message that sets Jetstream filters on an
+// open connection, so the followed DID set can change without reconnecting
+type jetstreamOptionsUpdate struct {
+	Type    string           `json:"type"`
+	Payload jetstreamOptions `json:"payload"`
+}
+
+// jetstreamOptions filters the events Jetstream sends. An empty WantedDIDs list
+// means every account.
+type jetstreamOptions struct {
+	WantedCollections []string `json:"wantedCollections"`
+	WantedDIDs        []string `json:"wantedDids"`
+}
+
+// loadWantedDIDs returns the sorted DIDs of every source and user in the database.
+// Users are included so their account and identity events arrive too.
+func (fc *FirehoseConsumer) loadWantedDIDs() ([]string, error) {
+	var sourceDIDs, userDIDs []string
+	if err := fc.db.Model(&models.Source{}).Where("blue_sky_d_id <> ''").Pluck("blue_sky_d_id", &sourceDIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := fc.db.Model(&models.User{}).Where("blue_sky_d_id LIKE 'did:%'").Pluck("blue_sky_d_id", &userDIDs).Error; err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]bool, len(sourceDIDs)+len(userDIDs))
+	dids := make([]string, 0, len(sourceDIDs)+len(userDIDs))
+	for _, did := range append(sourceDIDs, userDIDs...) {
+		if !unique[did] {
+			unique[did] = true
+			dids = append(dids, did)
+		}
+	}
+	sort.Strings(dids)
+	return dids, nil
+}
+
+// optionsUpdateMessage encodes an options_update restricting the stream to records by
+// the given DIDs. Past Jetstream's limit the DID filter is dropped and posts from
+// every account are received, as before filtering existed.
+func optionsUpdateMessage(dids []string) ([]byte, error) {
+	if len(dids) > jetstreamMaxWantedDIDs {
+		log.Printf("Following %d source DIDs, more than Jetstream's limit of %d; receiving all posts", len(dids), jetstreamMaxWantedDIDs)
+		dids = nil
+	}
+	if dids == nil {
+		dids = []string{}
+	}
+
+	return json.Marshal(jetstreamOptionsUpdate{
+		Type: "options_update",
+		Payload: jetstreamOptions{
+			WantedCollections: jetstreamCollections,
+			WantedDIDs:        dids,
+		},
+	})
+}
+
+// sameDIDs reports whether two sorted DID lists are identical
+func sameDIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsUpdateMessage(t *testing.T) {
+	decode := func(t *testing.T, dids []string) jetstreamOptionsUpdate {
+		data, err := optionsUpdateMessage(dids)
+		require.NoError(t, err)
+		var message jetstreamOptionsUpdate
+		require.NoError(t, json.Unmarshal(data, &message))
+		return message
+	}
+
+	message := decode(t, []string{"did:plc:a", "did:plc:b"})
+	assert.Equal(t, "options_update", message.Type)
+	assert.Equal(t, []string{jetstreamPostCollection, jetstreamRepostCollection}, message.Payload.WantedCollections)
+	assert.Equal(t, []string{"did:plc:a", "did:plc:b"}, message.Payload.WantedDIDs)
+
+	// Past Jetstream's limit the DID filter is dropped rather than truncated
+	tooMany := make([]string, jetstreamMaxWantedDIDs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("did:plc:%d", i)
+	}
+	assert.Empty(t, decode(t, tooMany).Payload.WantedDIDs)
+
+	data, err := optionsUpdateMessage(nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"wantedDids":[]`)
+}
+
+func TestSameDIDs(t *testing.T) {
+	assert.True(t, sameDIDs(nil, []string{}))
+	assert.True(t, sameDIDs([]string{"did:plc:a"}, []string{"did:plc:a"}))
+	assert.False(t, sameDIDs([]string{"did:plc:a"}, []string{"did:plc:b"}))
+	assert.False(t, sameDIDs([]string{"did:plc:a"}, []string{"did:plc:a", "did:plc:b"}))
+}
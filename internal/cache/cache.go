@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Cache stores encoded values with a time to live. Implementations treat backend
+// failures as misses, so callers can always fall back to the source of truth.
+type Cache interface {
+	// Get returns the value stored under key if it hasn't expired
+	Get(key string) ([]byte, bool)
+	// Set stores a value under key for ttl; ttl <= 0 stores nothing
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored under key
+	Delete(key string)
+	// DeletePrefix removes every value whose key starts with prefix
+	DeletePrefix(prefix string)
+}
+
+// defaultMaxEntries bounds the in-memory cache used when Redis isn't configured
+const defaultMaxEntries = 5000
+
+var (
+	sharedOnce  sync.Once
+	sharedCache Cache
+)
+
+// FromEnv returns a Redis cache when REDIS_URL is set, so horizontally scaled
+// instances share cached feeds and Bluesky lookups, or an in-memory cache holding
+// at most maxEntries values otherwise.
+func FromEnv(maxEntries int) Cache {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return NewMemory(maxEntries)
+	}
+
+	redis, err := NewRedis(redisURL)
+	if err != nil {
+		log.Printf("Invalid REDIS_URL, falling back to in-memory cache: %v", err)
+		return NewMemory(maxEntries)
+	}
+	log.Printf("Using Redis cache at %s", redis.addr)
+	return redis
+}
+
+// Shared returns the process-wide cache, created from the environment on first use
+// so values loaded from .env at startup are picked up
+func Shared() Cache {
+	sharedOnce.Do(func() {
+		sharedCache = FromEnv(defaultMaxEntries)
+	})
+	return sharedCache
+}
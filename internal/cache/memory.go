@@ -0,0 +1,98 @@
+// Package cache provides short-lived caching of encoded values so hot endpoints
+// such as getFeedSkeleton don't hit the database, and Bluesky lookups aren't
+// repeated, on every request. Values live in process memory or, when REDIS_URL is
+// set, in Redis so horizontally scaled instances share them.
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process cache with per-entry expiry, safe for concurrent use
+type Memory struct {
+	mu         sync.RWMutex
+	entries    map[string]entry
+	maxEntries int
+}
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory creates an in-memory cache holding at most maxEntries values
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{
+		entries:    make(map[string]entry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get returns the value stored under key if it hasn't expired
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	e, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores a value under key for ttl. Values are not copied, so callers must not modify them afterwards.
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+	m.entries[key] = entry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// Delete removes the value stored under key
+func (m *Memory) Delete(key string) {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+}
+
+// DeletePrefix removes every value whose key starts with prefix
+func (m *Memory) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// evict makes room for a new entry by dropping expired entries, or the entry
+// closest to expiring when none have expired. The caller must hold the lock.
+func (m *Memory) evict() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range m.entries {
+		if now.After(e.expiresAt) {
+			delete(m.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, e.expiresAt
+		}
+	}
+
+	if len(m.entries) >= m.maxEntries && oldestKey != "" {
+		delete(m.entries, oldestKey)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory_GetSet(t *testing.T) {
+	m := NewMemory(10)
+
+	m.Set("global:30:0", []byte("feed"), time.Minute)
+	value, ok := m.Get("global:30:0")
+	assert.True(t, ok)
+	assert.Equal(t, "feed", string(value))
+
+	_, ok = m.Get("missing")
+	assert.False(t, ok)
+
+	m.Set("expired", []byte("old"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, ok = m.Get("expired")
+	assert.False(t, ok, "expired values are not returned")
+
+	m.Set("disabled", []byte("value"), 0)
+	_, ok = m.Get("disabled")
+	assert.False(t, ok, "a zero TTL doesn't cache")
+}
+
+func TestMemory_DeletePrefix(t *testing.T) {
+	m := NewMemory(10)
+	m.Set("global:30:0", []byte("a"), time.Minute)
+	m.Set("global:30:30", []byte("b"), time.Minute)
+	m.Set("filtered:tech:30:0", []byte("c"), time.Minute)
+
+	m.DeletePrefix("global:")
+
+	_, ok := m.Get("global:30:0")
+	assert.False(t, ok)
+	_, ok = m.Get("global:30:30")
+	assert.False(t, ok)
+	_, ok = m.Get("filtered:tech:30:0")
+	assert.True(t, ok)
+}
+
+func TestMemory_Eviction(t *testing.T) {
+	m := NewMemory(2)
+	m.Set("first", []byte("1"), time.Minute)
+	m.Set("second", []byte("2"), 2*time.Minute)
+	m.Set("third", []byte("3"), 3*time.Minute)
+
+	_, ok := m.Get("first")
+	assert.False(t, ok, "the entry closest to expiring is evicted")
+	_, ok = m.Get("second")
+	assert.True(t, ok)
+	_, ok = m.Get("third")
+	assert.True(t, ok)
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeyPrefix namespaces keys so the Redis instance can be shared with other applications
+	redisKeyPrefix = "open-news:"
+
+	// redisTimeout bounds dialing and each command round trip
+	redisTimeout = 2 * time.Second
+
+	// redisScanCount is the SCAN batch size used when deleting by prefix
+	redisScanCount = 200
+)
+
+// Redis is a cache backed by a Redis server, shared by every instance pointed at it
+type Redis struct {
+	addr   string
+	client *redis.Client
+}
+
+// NewRedis creates a Redis cache from a URL such as redis://:password@localhost:6379/0.
+// rediss:// connects over TLS. Connections are opened on first use.
+func NewRedis(rawURL string) (*Redis, error) {
+	// go-redis defaults a missing host to localhost, which is more likely a
+	// mistake than a choice here
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() == "" {
+		return nil, errors.New("redis URL has no host")
+	}
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+	}
+	options.DialTimeout = redisTimeout
+	options.ReadTimeout = redisTimeout
+	options.WriteTimeout = redisTimeout
+	return &Redis{addr: options.Addr, client: redis.NewClient(options)}, nil
+}
+
+// Get returns the value stored under key
+func (r *Redis) Get(key string) ([]byte, bool) {
+	value, err := r.client.Get(context.Background(), redisKeyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Redis GET %s failed: %v", key, err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores a value under key for ttl
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	if err := r.client.Set(context.Background(), redisKeyPrefix+key, value, ttl).Err(); err != nil {
+		log.Printf("Redis SET %s failed: %v", key, err)
+	}
+}
+
+// Delete removes the value stored under key
+func (r *Redis) Delete(key string) {
+	if err := r.client.Del(context.Background(), redisKeyPrefix+key).Err(); err != nil {
+		log.Printf("Redis DEL %s failed: %v", key, err)
+	}
+}
+
+// DeletePrefix removes every value whose key starts with prefix, scanning the
+// keyspace in batches so the server isn't blocked
+func (r *Redis) DeletePrefix(prefix string) {
+	ctx := context.Background()
+	pattern := redisKeyPrefix + escapeGlob(prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, redisScanCount).Result()
+		if err != nil {
+			log.Printf("Redis SCAN %s failed: %v", prefix, err)
+			return
+		}
+		if len(keys) > 0 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				log.Printf("Redis DEL %s* failed: %v", prefix, err)
+				return
+			}
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as glob syntax
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -10,13 +10,22 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 // DB holds the database connection
 var DB *gorm.DB
 
+// Supported values of DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // Config holds database configuration
 type Config struct {
+	Driver   string // "postgres" (default) or "sqlite"
+	Path     string // SQLite database file, or ":memory:"
 	Host     string
 	Port     string
 	User     string
@@ -28,6 +37,8 @@ type Config struct {
 // LoadConfig loads database configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
+		Driver:   getEnv("DB_DRIVER", DriverPostgres),
+		Path:     getEnv("DB_PATH", "open_news.db"),
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnv("DB_PORT", "5432"),
 		User:     getEnv("DB_USER", "postgres"),
@@ -37,8 +48,68 @@ func LoadConfig() *Config {
 	}
 }
 
-// Connect establishes a connection to the PostgreSQL database
+// Connect establishes a connection to the configured database
 func Connect(config *Config) error {
+	dialector, err := config.dialector()
+	if err != nil {
+		return err
+	}
+
+	DB, err = gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Statements are recorded as spans, as children of the span in their context,
+	// e.g. db.WithContext(c.Request.Context()). The DSN and query parameters,
+	// which can hold passwords and user data, are left out.
+	if err := DB.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutServerAddress(), gormtracing.WithoutQueryVariables())); err != nil {
+		return fmt.Errorf("failed to set up query tracing: %w", err)
+	}
+
+	if IsSQLite(DB) {
+		if err := configureSQLite(DB, config.Path); err != nil {
+			return fmt.Errorf("failed to configure SQLite: %w", err)
+		}
+	}
+
+	log.Println("Successfully connected to database")
+	return nil
+}
+
+// IsSQLite reports whether db is a SQLite database. Queries that Postgres and
+// SQLite write differently check this.
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverSQLite
+}
+
+// ArrayContains returns a condition on a text array column that matches rows
+// whose array holds the bound value. SQLite stores arrays in the text form
+// lib/pq writes, {"a","b"}, so the quoted element is looked for in that.
+func ArrayContains(db *gorm.DB, column string) string {
+	if IsSQLite(db) {
+		return fmt.Sprintf(`instr(%s, '"' || ? || '"') > 0`, column)
+	}
+	return fmt.Sprintf("? = ANY(%s)", column)
+}
+
+// dialector returns the GORM dialector for the configured driver
+func (config *Config) dialector() (gorm.Dialector, error) {
+	switch config.Driver {
+	case DriverPostgres:
+		return postgres.Open(config.postgresDSN()), nil
+	case DriverSQLite:
+		return sqliteDialector(config.Path), nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q", config.Driver)
+	}
+}
+
+// postgresDSN returns the PostgreSQL connection string
+func (config *Config) postgresDSN() string {
 	// Build DSN without empty password parameter
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s dbname=%s sslmode=%s",
@@ -52,18 +123,7 @@ func Connect(config *Config) error {
 			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
 		)
 	}
-
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	log.Println("Successfully connected to database")
-	return nil
+	return dsn
 }
 
 // Migrate runs database migrations
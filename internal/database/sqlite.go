@@ -0,0 +1,136 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// sqliteDriverName is the SQLite driver with the functions queries use that
+// SQLite doesn't have built in
+const sqliteDriverName = "sqlite3_open_news"
+
+var registerSQLiteDriver sync.Once
+
+// sqliteDialector opens the SQLite database file at path. Foreign keys are
+// enforced as in Postgres, and transactions take the write lock when they
+// begin so two writers wait for each other instead of failing part way.
+func sqliteDialector(path string) gorm.Dialector {
+	registerSQLiteDriver.Do(func() {
+		sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				return conn.RegisterFunc("url_host", urlHost, true)
+			},
+		})
+	})
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", path)
+	return sqliteDialect{sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: dsn}).(*sqlite.Dialector)}
+}
+
+// urlHost returns the lowercased host of a URL without "www.", or "" when it
+// has none. SQLite queries call it as url_host.
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// sqliteDialect is the SQLite dialector with a migrator that understands the
+// Postgres column types the models declare
+type sqliteDialect struct {
+	*sqlite.Dialector
+}
+
+// Migrator returns the SQLite migrator for db
+func (d sqliteDialect) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqliteMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+// sqliteMigrator creates tables from models written for Postgres. Arrays are
+// stored in their Postgres text form, which lib/pq reads back, and UUID
+// primary keys are generated by assignUUIDs instead of a column default.
+type sqliteMigrator struct {
+	sqlite.Migrator
+}
+
+// FullDataTypeOf returns the SQLite column definition of field
+func (m sqliteMigrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	expr := m.Migrator.FullDataTypeOf(field)
+	if dataType := m.DataTypeOf(field); strings.HasSuffix(dataType, "[]") {
+		expr.SQL = "text" + strings.TrimPrefix(expr.SQL, dataType)
+	}
+	expr.SQL = strings.Replace(expr.SQL, " DEFAULT gen_random_uuid()", "", 1)
+	return expr
+}
+
+// MigrateColumn updates the column of field if its definition changed. SQLite
+// reports a default of 0.0 as 0, which would otherwise rebuild every table
+// with such a column on each start.
+func (m sqliteMigrator) MigrateColumn(value interface{}, field *schema.Field, columnType gorm.ColumnType) error {
+	if current, ok := columnType.DefaultValue(); ok && field.GORMDataType == schema.Float && current != field.DefaultValue {
+		stored, err1 := strconv.ParseFloat(current, 64)
+		declared, err2 := strconv.ParseFloat(field.DefaultValue, 64)
+		if err1 == nil && err2 == nil && stored == declared {
+			unchanged := *field
+			unchanged.DefaultValue = current
+			field = &unchanged
+		}
+	}
+	return m.Migrator.MigrateColumn(value, field, columnType)
+}
+
+// configureSQLite prepares a new SQLite connection. An in-memory database only
+// lives as long as its connection, so the pool is kept to one.
+func configureSQLite(db *gorm.DB, path string) error {
+	if path == ":memory:" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return db.Callback().Create().Before("gorm:create").Register("open_news:assign_uuids", assignUUIDs)
+}
+
+// uuidType is the type of the models' primary keys
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// assignUUIDs gives the rows being created a random UUID primary key when they
+// don't have one, which Postgres does with gen_random_uuid()
+func assignUUIDs(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || field.FieldType != uuidType {
+		return
+	}
+
+	ctx := db.Statement.Context
+	assign := func(row reflect.Value) {
+		if _, zero := field.ValueOf(ctx, row); zero {
+			db.AddError(field.Set(ctx, row, uuid.New()))
+		}
+	}
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			assign(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		assign(value)
+	}
+}
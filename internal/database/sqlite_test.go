@@ -0,0 +1,90 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func TestSQLite(t *testing.T) {
+	if err := Connect(&Config{Driver: DriverSQLite, Path: ":memory:"}); err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	defer Close()
+	if !IsSQLite(DB) {
+		t.Fatal("Expected a SQLite connection")
+	}
+
+	// Migrating an up to date schema leaves it alone
+	for i := 0; i < 2; i++ {
+		if err := Migrate(); err != nil {
+			t.Fatalf("Migration %d failed: %v", i+1, err)
+		}
+	}
+
+	source := models.Source{BlueSkyDID: "did:plc:sqlite", Handle: "sqlite.test"}
+	if _, err := models.InsertSource(DB, &source); err != nil {
+		t.Fatalf("Failed to insert source: %v", err)
+	}
+	if source.ID == uuid.Nil {
+		t.Fatal("Expected the source to get a UUID")
+	}
+
+	articles := []models.Article{
+		{URL: "https://example.com/one", Title: "One", Tags: pq.StringArray{"politics", "world"}},
+		{URL: "https://example.com/two", Title: "Two"},
+	}
+	if err := DB.Create(&articles).Error; err != nil {
+		t.Fatalf("Failed to create articles: %v", err)
+	}
+	if articles[0].ID == articles[1].ID {
+		t.Fatal("Expected every article to get its own UUID")
+	}
+
+	share := models.SourceArticle{SourceID: source.ID, ArticleID: articles[0].ID, PostURI: "at://did:plc:sqlite/app.bsky.feed.post/1", PostedAt: time.Now(), LikesCount: 4}
+	for i, want := range []bool{true, false} {
+		if created, err := models.InsertShare(DB, &share); err != nil || created != want {
+			t.Fatalf("Insert %d: expected created %v, got %v (%v)", i+1, want, created, err)
+		}
+	}
+
+	var stored models.Article
+	if err := DB.Preload("SourceArticles").First(&stored, "id = ?", articles[0].ID).Error; err != nil {
+		t.Fatalf("Failed to load article: %v", err)
+	}
+	if len(stored.Tags) != 2 || stored.Tags[1] != "world" {
+		t.Errorf("Expected tags to round-trip, got %v", stored.Tags)
+	}
+	if len(stored.SourceArticles) != 1 {
+		t.Errorf("Expected 1 share, got %d", len(stored.SourceArticles))
+	}
+	if stored.SharesCount != 1 || stored.LikesCount != 4 {
+		t.Errorf("Expected the share's engagement to be rolled up, got %d shares and %d likes", stored.SharesCount, stored.LikesCount)
+	}
+
+	var count int64
+	DB.Model(&models.Article{}).Where(ArrayContains(DB, "tags"), "world").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 article tagged world, got %d", count)
+	}
+	DB.Model(&models.Article{}).Where(ArrayContains(DB, "tags"), "wor").Count(&count)
+	if count != 0 {
+		t.Errorf("Expected whole tags to be matched, got %d articles", count)
+	}
+
+	var host string
+	DB.Raw("SELECT url_host(?)", "https://WWW.Example.com:8080/story?id=1").Scan(&host)
+	if host != "example.com" {
+		t.Errorf("Expected url_host to return example.com, got %q", host)
+	}
+
+	// Foreign keys are enforced
+	orphan := models.SourceArticle{SourceID: source.ID, ArticleID: uuid.New(), PostURI: "at://did:plc:sqlite/app.bsky.feed.post/2", PostedAt: time.Now()}
+	if err := DB.Create(&orphan).Error; err == nil {
+		t.Error("Expected a share of a missing article to be rejected")
+	}
+}
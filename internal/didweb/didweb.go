@@ -0,0 +1,183 @@
+// Package didweb builds the did:web document of the feed generator from its
+// settings, and checks it against the feed generator records published on
+// Bluesky, so the identity Bluesky resolves always matches the server.
+package didweb
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/labeler"
+)
+
+// Config holds the feed generator's identity, read from FEED_* settings
+type Config struct {
+	DID             string // FEED_GENERATOR_DID, e.g. did:web:feeds.example.com
+	ServiceEndpoint string // FEED_SERVICE_ENDPOINT: URL serving the feeds (default: https:// and the DID's host)
+	LabelerKey      string // did:key of the labeler's signing key, when the labeler uses the same DID
+	Publisher       string // FEED_PUBLISHER_DID: account the feed generator records are published under (default: BLUESKY_IDENTIFIER)
+}
+
+// LoadConfig reads the identity settings from the environment. The labeler's
+// key and endpoint are included when LABELER_DID is the feed generator's DID.
+func LoadConfig() Config {
+	config := Config{
+		DID:             strings.TrimSpace(os.Getenv("FEED_GENERATOR_DID")),
+		ServiceEndpoint: strings.TrimSuffix(strings.TrimSpace(os.Getenv("FEED_SERVICE_ENDPOINT")), "/"),
+		Publisher:       strings.TrimSpace(os.Getenv("FEED_PUBLISHER_DID")),
+	}
+	if config.Publisher == "" {
+		config.Publisher = strings.TrimSpace(os.Getenv("BLUESKY_IDENTIFIER"))
+	}
+	if signer := labeler.FromEnv(); signer != nil && signer.DID() == config.DID {
+		config.LabelerKey = signer.PublicKey()
+	}
+	return config
+}
+
+// Document is a DID document
+type Document struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod,omitempty"`
+	Service            []Service            `json:"service"`
+}
+
+// VerificationMethod is a public key in a DID document
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+}
+
+// Service is an endpoint in a DID document
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// Host returns the host a did:web resolves at, or "" for other DIDs. Ports are
+// encoded as %3A, and did:web DIDs with paths aren't supported.
+func Host(did string) string {
+	host, ok := strings.CutPrefix(did, "did:web:")
+	if !ok || host == "" || strings.Contains(host, ":") {
+		return ""
+	}
+	return strings.ReplaceAll(host, "%3A", ":")
+}
+
+// Build returns the DID document for a config, or nil when the DID isn't a
+// did:web and so is published elsewhere
+func Build(config Config) (*Document, error) {
+	if !strings.HasPrefix(config.DID, "did:web:") {
+		return nil, nil
+	}
+	host := Host(config.DID)
+	if host == "" {
+		return nil, fmt.Errorf("FEED_GENERATOR_DID %q isn't a did:web for a host", config.DID)
+	}
+
+	endpoint := config.ServiceEndpoint
+	if endpoint == "" {
+		endpoint = "https://" + host
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+		return nil, fmt.Errorf("FEED_SERVICE_ENDPOINT %q must be a URL without a path", endpoint)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && isLocal(parsed.Hostname())) {
+		return nil, fmt.Errorf("FEED_SERVICE_ENDPOINT %q must use https", endpoint)
+	}
+	endpoint = parsed.Scheme + "://" + parsed.Host
+
+	document := &Document{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		ID:      config.DID,
+		Service: []Service{{ID: "#bsky_fg", Type: "BskyFeedGenerator", ServiceEndpoint: endpoint}},
+	}
+	if config.LabelerKey != "" {
+		key, ok := strings.CutPrefix(config.LabelerKey, "did:key:")
+		if !ok {
+			return nil, fmt.Errorf("labeler key %q isn't a did:key", config.LabelerKey)
+		}
+		document.Context = append(document.Context, "https://w3id.org/security/multikey/v1")
+		document.VerificationMethod = append(document.VerificationMethod, VerificationMethod{
+			ID:                 config.DID + "#atproto_label",
+			Type:               "Multikey",
+			Controller:         config.DID,
+			PublicKeyMultibase: key,
+		})
+		document.Service = append(document.Service, Service{ID: "#atproto_labeler", Type: "AtprotoLabeler", ServiceEndpoint: endpoint})
+	}
+	return document, nil
+}
+
+// isLocal reports whether a host is this machine, where plain http is allowed
+// for development
+func isLocal(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// PublisherDID returns the DID of the account the feed generator records are
+// published under, resolving a handle
+func PublisherDID(client *bluesky.Client, publisher string) (string, error) {
+	switch {
+	case publisher == "":
+		return "", fmt.Errorf("set FEED_PUBLISHER_DID to the account that publishes the feeds")
+	case strings.HasPrefix(publisher, "did:"):
+		return publisher, nil
+	case strings.Contains(publisher, "@"):
+		return "", fmt.Errorf("BLUESKY_IDENTIFIER is an email address, set FEED_PUBLISHER_DID to the account that publishes the feeds")
+	}
+	did, err := client.ResolveHandle(strings.TrimPrefix(publisher, "@"))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve publisher %s: %w", publisher, err)
+	}
+	return did, nil
+}
+
+// CheckPublished lists the feed generator records of the publisher and compares
+// them with the active feeds, as CheckRecords does
+func CheckPublished(client *bluesky.Client, config Config, activeRKeys []string) ([]string, error) {
+	publisher, err := PublisherDID(client, config.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	records, err := client.ListFeedGenerators(publisher)
+	if err != nil {
+		return nil, err
+	}
+	return CheckRecords(config.DID, records, activeRKeys), nil
+}
+
+// CheckRecords compares the published feed generator records with the active
+// feeds, returning a problem for each record that points at another service,
+// each active feed that isn't published and each record without an active feed
+func CheckRecords(did string, records []bluesky.PublishedFeedGenerator, activeRKeys []string) []string {
+	var problems []string
+	published := make(map[string]bool, len(records))
+	active := make(map[string]bool, len(activeRKeys))
+	for _, rkey := range activeRKeys {
+		active[rkey] = true
+	}
+	for _, record := range records {
+		published[record.RKey] = true
+		if record.DID != did {
+			problems = append(problems, fmt.Sprintf("%s points at %s instead of %s", record.URI, record.DID, did))
+		}
+		if !active[record.RKey] {
+			problems = append(problems, fmt.Sprintf("%s has no active feed, so it's not served", record.URI))
+		}
+	}
+	for _, rkey := range activeRKeys {
+		if !published[rkey] {
+			problems = append(problems, fmt.Sprintf("feed %s isn't published, run opennews publish-feed", rkey))
+		}
+	}
+	return problems
+}
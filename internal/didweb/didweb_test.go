@@ -0,0 +1,70 @@
+package didweb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-news/internal/bluesky"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	document, err := Build(Config{DID: "did:plc:notweb"})
+	require.NoError(t, err)
+	assert.Nil(t, document, "only did:web documents are generated")
+
+	document, err = Build(Config{DID: "did:web:feeds.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "did:web:feeds.example.com", document.ID)
+	assert.Equal(t, []Service{{ID: "#bsky_fg", Type: "BskyFeedGenerator", ServiceEndpoint: "https://feeds.example.com"}}, document.Service)
+	assert.Empty(t, document.VerificationMethod)
+
+	document, err = Build(Config{DID: "did:web:localhost%3A8080", ServiceEndpoint: "http://localhost:8080/", LabelerKey: "did:key:zDnaeExample"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", document.Service[0].ServiceEndpoint)
+	require.Len(t, document.VerificationMethod, 1)
+	assert.Equal(t, "did:web:localhost%3A8080#atproto_label", document.VerificationMethod[0].ID)
+	assert.Equal(t, "zDnaeExample", document.VerificationMethod[0].PublicKeyMultibase)
+	assert.Equal(t, "#atproto_labeler", document.Service[1].ID)
+
+	for _, config := range []Config{
+		{DID: "did:web:"},
+		{DID: "did:web:example.com:feeds"},
+		{DID: "did:web:example.com", ServiceEndpoint: "http://example.com"},
+		{DID: "did:web:example.com", ServiceEndpoint: "https://example.com/feeds"},
+	} {
+		_, err := Build(config)
+		assert.Error(t, err, "%+v", config)
+	}
+}
+
+func TestCheckPublished(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xrpc/com.atproto.repo.listRecords", r.URL.Path)
+		assert.Equal(t, "did:plc:publisher", r.URL.Query().Get("repo"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"records": []map[string]interface{}{
+				{"uri": "at://did:plc:publisher/app.bsky.feed.generator/global", "value": map[string]string{"did": "did:web:feeds.example.com"}},
+				{"uri": "at://did:plc:publisher/app.bsky.feed.generator/tech", "value": map[string]string{"did": "did:web:old.example.com"}},
+				{"uri": "at://did:plc:publisher/app.bsky.feed.generator/retired", "value": map[string]string{"did": "did:web:feeds.example.com"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	config := Config{DID: "did:web:feeds.example.com", Publisher: "did:plc:publisher"}
+	problems, err := CheckPublished(bluesky.NewClient(server.URL), config, []string{"global", "tech", "science"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"at://did:plc:publisher/app.bsky.feed.generator/tech points at did:web:old.example.com instead of did:web:feeds.example.com",
+		"at://did:plc:publisher/app.bsky.feed.generator/retired has no active feed, so it's not served",
+		"feed science isn't published, run opennews publish-feed",
+	}, problems)
+
+	_, err = CheckPublished(bluesky.NewClient(server.URL), Config{DID: config.DID, Publisher: "me@example.com"}, nil)
+	assert.ErrorContains(t, err, "FEED_PUBLISHER_DID")
+}
@@ -0,0 +1,296 @@
+// Package domains keeps the reputation of news sites in the domains table: a
+// score used when ranking their articles, and a category and country feeds can
+// be filtered by. Admins edit the table through the admin API; a curated list
+// seeds it on a new install.
+package domains
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"open-news/internal/metadata"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultScore is the reputation of sites missing from the domains table
+const DefaultScore = 0.5
+
+// reloadInterval is how long a registry serves its copy of the table before
+// reading it again, so admin edits made by other processes are picked up
+const reloadInterval = time.Minute
+
+// ErrInvalidDomain is returned when a domain can't be parsed from the given value
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ErrInvalidScore is returned for a reputation outside 0 to 1
+var ErrInvalidScore = errors.New("score must be between 0 and 1")
+
+// ErrInvalidSchemaRequirement is returned for a schema requirement other than
+// require, prefer or ignore
+var ErrInvalidSchemaRequirement = errors.New("schema_requirement must be require, prefer, ignore or empty")
+
+// ErrInvalidFeedURL is returned for a feed URL that isn't an absolute http(s) URL
+var ErrInvalidFeedURL = errors.New("feed_urls must be absolute http or https URLs")
+
+// Curated is the list of publishers a new install starts with
+var Curated = []models.Domain{
+	{Domain: "reuters.com", Name: "Reuters", Score: 1.0, Category: "wire", Country: "GB"},
+	{Domain: "apnews.com", Name: "Associated Press", Score: 0.95, Category: "wire", Country: "US"},
+	{Domain: "bbc.co.uk", Name: "BBC News", Score: 0.95, Category: "broadcaster", Country: "GB"},
+	{Domain: "bbc.com", Name: "BBC News", Score: 0.95, Category: "broadcaster", Country: "GB"},
+	{Domain: "theguardian.com", Name: "The Guardian", Score: 0.9, Category: "newspaper", Country: "GB"},
+	{Domain: "nytimes.com", Name: "The New York Times", Score: 0.92, Category: "newspaper", Country: "US"},
+	{Domain: "washingtonpost.com", Name: "The Washington Post", Score: 0.9, Category: "newspaper", Country: "US"},
+	{Domain: "nature.com", Name: "Nature", Score: 0.98, Category: "science", Country: "GB"},
+	{Domain: "arxiv.org", Name: "arXiv", Score: 0.9, Category: "science", Country: "US"},
+	{Domain: "economist.com", Name: "The Economist", Score: 0.88, Category: "magazine", Country: "GB"},
+	{Domain: "wired.com", Name: "WIRED", Score: 0.85, Category: "tech", Country: "US"},
+	{Domain: "bloomberg.com", Name: "Bloomberg", Score: 0.85, Category: "business", Country: "US"},
+	{Domain: "techcrunch.com", Name: "TechCrunch", Score: 0.8, Category: "tech", Country: "US"},
+	{Domain: "cnn.com", Name: "CNN", Score: 0.75, Category: "broadcaster", Country: "US"},
+	{Domain: "forbes.com", Name: "Forbes", Score: 0.7, Category: "business", Country: "US"},
+}
+
+// Table is a snapshot of the domains table
+type Table struct {
+	byDomain map[string]*models.Domain
+	byName   map[string]*models.Domain
+	optedOut map[string]bool // Domains of active publisher opt-outs
+}
+
+// NewTable indexes domains by domain and by publisher name
+func NewTable(list []models.Domain) Table {
+	table := Table{
+		byDomain: make(map[string]*models.Domain, len(list)),
+		byName:   make(map[string]*models.Domain, len(list)),
+	}
+	for i := range list {
+		domain := &list[i]
+		table.byDomain[domain.Domain] = domain
+		if name := strings.ToLower(domain.Name); name != "" {
+			if _, exists := table.byName[name]; !exists {
+				table.byName[name] = domain
+			}
+		}
+	}
+	return table
+}
+
+// Lookup finds the domain of an article: the closest entry for its URL's host or
+// a parent domain of it, or failing that an entry with the article's site name.
+// It returns nil for unknown sites.
+func (t Table) Lookup(article models.Article) *models.Domain {
+	for host := Normalize(article.URL); host != ""; {
+		if domain, ok := t.byDomain[host]; ok {
+			return domain
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		host = parent
+	}
+	if article.SiteName != "" {
+		return t.byName[strings.ToLower(article.SiteName)]
+	}
+	return nil
+}
+
+// OptedOut reports whether a URL's host, or a parent domain of it, opted out
+func (t Table) OptedOut(pageURL string) bool {
+	for host := Normalize(pageURL); host != ""; {
+		if t.optedOut[host] {
+			return true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		host = parent
+	}
+	return false
+}
+
+// Normalize returns the lowercased host of a URL or bare domain, without "www."
+func Normalize(value string) string {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
+
+// Registry serves the domains table, and the domains of publishers that opted
+// out, from memory, reloading them every minute
+type Registry struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	table    Table
+	loadedAt time.Time
+
+	ownership *ownership // How opt-out requests are verified; nil uses defaultOwnership
+}
+
+// NewRegistry creates a registry for the domains table. The table is read on
+// first use.
+func NewRegistry(db *gorm.DB) *Registry {
+	return &Registry{db: db}
+}
+
+// NewStaticRegistry creates a registry serving a fixed list of domains, without a database
+func NewStaticRegistry(list []models.Domain) *Registry {
+	return &Registry{table: NewTable(list), loadedAt: time.Now()}
+}
+
+// Lookup finds the domain of an article, as Table.Lookup does. A nil registry
+// knows no domains.
+func (r *Registry) Lookup(article models.Article) *models.Domain {
+	if r == nil {
+		return nil
+	}
+	return r.current().Lookup(article)
+}
+
+// SchemaRequirement returns how pages at a URL are checked for a NewsArticle
+// schema: as their domain's entry says, or as fallback says for sites without
+// their own requirement
+func (r *Registry) SchemaRequirement(pageURL string, fallback metadata.SchemaRequirement) metadata.SchemaRequirement {
+	if domain := r.Lookup(models.Article{URL: pageURL}); domain != nil && domain.SchemaRequirement != "" {
+		return metadata.SchemaRequirement(domain.SchemaRequirement)
+	}
+	return fallback
+}
+
+// current returns the table, reloading it when it's older than reloadInterval.
+// A failed reload keeps serving the previous table.
+func (r *Registry) current() Table {
+	r.mu.RLock()
+	table, fresh := r.table, r.db == nil || time.Since(r.loadedAt) < reloadInterval
+	r.mu.RUnlock()
+	if fresh {
+		return table
+	}
+
+	list, err := r.List()
+	var optedOut []string
+	if err == nil {
+		optedOut, err = r.activeOptOuts()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loadedAt = time.Now()
+	if err != nil {
+		log.Printf("Failed to load domains, keeping previous reputations: %v", err)
+		return r.table
+	}
+	r.table = NewTable(list)
+	r.table.optedOut = make(map[string]bool, len(optedOut))
+	for _, domain := range optedOut {
+		r.table.optedOut[domain] = true
+	}
+	return r.table
+}
+
+// invalidate makes the next lookup read the table again
+func (r *Registry) invalidate() {
+	r.mu.Lock()
+	r.loadedAt = time.Time{}
+	r.mu.Unlock()
+}
+
+// List returns every domain in alphabetical order
+func (r *Registry) List() ([]models.Domain, error) {
+	var list []models.Domain
+	err := r.db.Order("domain ASC").Find(&list).Error
+	return list, err
+}
+
+// Save creates a domain or replaces the entry with the same domain
+func (r *Registry) Save(domain models.Domain) (*models.Domain, error) {
+	domain.Domain = Normalize(domain.Domain)
+	if domain.Domain == "" || !strings.Contains(domain.Domain, ".") {
+		return nil, ErrInvalidDomain
+	}
+	if domain.Score < 0 || domain.Score > 1 {
+		return nil, ErrInvalidScore
+	}
+	domain.Country = strings.ToUpper(strings.TrimSpace(domain.Country))
+	domain.Category = strings.ToLower(strings.TrimSpace(domain.Category))
+	if domain.SchemaRequirement != "" {
+		requirement, ok := metadata.ParseSchemaRequirement(domain.SchemaRequirement)
+		if !ok {
+			return nil, ErrInvalidSchemaRequirement
+		}
+		domain.SchemaRequirement = string(requirement)
+	}
+	for i, feedURL := range domain.FeedURLs {
+		parsed, err := url.Parse(strings.TrimSpace(feedURL))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, ErrInvalidFeedURL
+		}
+		domain.FeedURLs[i] = parsed.String()
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "domain"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "score", "category", "country", "is_blocked", "schema_requirement", "ingest_feeds", "feed_urls", "updated_at"}),
+	}).Create(&domain).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save domain %s: %w", domain.Domain, err)
+	}
+	r.invalidate()
+
+	// The upsert doesn't return the existing row's ID
+	if err := r.db.Where("domain = ?", domain.Domain).First(&domain).Error; err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// Delete removes a domain, which then scores as unknown. It returns
+// gorm.ErrRecordNotFound when there's no such domain.
+func (r *Registry) Delete(domain string) error {
+	result := r.db.Where("domain = ?", Normalize(domain)).Delete(&models.Domain{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete domain: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.invalidate()
+	return nil
+}
+
+// EnsureDefaults seeds the curated domains into an empty table and returns how
+// many were added. A table with entries is left alone, so domains an admin
+// deleted aren't brought back.
+func (r *Registry) EnsureDefaults() (int, error) {
+	var count int64
+	if err := r.db.Model(&models.Domain{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count domains: %w", err)
+	}
+	if count > 0 {
+		return 0, nil
+	}
+
+	seeded := make([]models.Domain, len(Curated))
+	copy(seeded, Curated)
+	if err := r.db.Create(&seeded).Error; err != nil {
+		return 0, fmt.Errorf("failed to seed domains: %w", err)
+	}
+	r.invalidate()
+	return len(seeded), nil
+}
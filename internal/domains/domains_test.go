@@ -0,0 +1,101 @@
+package domains
+
+import (
+	"os"
+	"testing"
+
+	"open-news/internal/database"
+	"open-news/internal/metadata"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "reuters.com", Normalize("https://www.Reuters.com/world/story?id=1"))
+	assert.Equal(t, "news.bbc.co.uk", Normalize("news.bbc.co.uk"))
+	assert.Equal(t, "example.com", Normalize(" www.example.com/path "))
+	assert.Empty(t, Normalize(""))
+}
+
+func TestTable_Lookup(t *testing.T) {
+	table := NewTable([]models.Domain{
+		{Domain: "bbc.co.uk", Name: "BBC News", Score: 0.95},
+		{Domain: "sport.bbc.co.uk", Name: "BBC Sport", Score: 0.8},
+		{Domain: "reuters.com", Name: "Reuters", Score: 1.0},
+	})
+
+	assert.Equal(t, "bbc.co.uk", table.Lookup(models.Article{URL: "https://www.bbc.co.uk/news/1"}).Domain)
+	assert.Equal(t, "bbc.co.uk", table.Lookup(models.Article{URL: "https://news.bbc.co.uk/1"}).Domain, "subdomains match their parent")
+	assert.Equal(t, "sport.bbc.co.uk", table.Lookup(models.Article{URL: "https://sport.bbc.co.uk/1"}).Domain, "the closest entry wins")
+	assert.Equal(t, "reuters.com", table.Lookup(models.Article{URL: "https://reut.rs/abc", SiteName: "reuters"}).Domain, "falls back to the site name")
+	assert.Nil(t, table.Lookup(models.Article{URL: "https://co.uk/story"}))
+	assert.Nil(t, table.Lookup(models.Article{URL: "https://unknown.example/story", SiteName: "Unknown"}))
+
+	var registry *Registry
+	assert.Nil(t, registry.Lookup(models.Article{URL: "https://reuters.com/story"}), "a nil registry knows no domains")
+}
+
+func TestRegistry_SchemaRequirement(t *testing.T) {
+	registry := NewStaticRegistry([]models.Domain{
+		{Domain: "smallblog.example", SchemaRequirement: "prefer"},
+		{Domain: "reuters.com"},
+	})
+
+	assert.Equal(t, metadata.SchemaPrefer, registry.SchemaRequirement("https://news.smallblog.example/post", metadata.SchemaRequire))
+	assert.Equal(t, metadata.SchemaRequire, registry.SchemaRequirement("https://www.reuters.com/world", metadata.SchemaRequire), "entries without a requirement use the default")
+	assert.Equal(t, metadata.SchemaIgnore, registry.SchemaRequirement("https://unknown.example/story", metadata.SchemaIgnore))
+
+	var none *Registry
+	assert.Equal(t, metadata.SchemaRequire, none.SchemaRequirement("https://smallblog.example/post", metadata.SchemaRequire))
+}
+
+func TestRegistry(t *testing.T) {
+	os.Setenv("DB_USER", "mterenzi")
+	os.Setenv("DB_NAME", "open_news_test")
+	if err := database.Connect(database.LoadConfig()); err != nil {
+		t.Skipf("Skipping test - PostgreSQL test database not available: %v", err)
+	}
+	db := database.DB
+	require.NoError(t, db.AutoMigrate(&models.Domain{}))
+	db.Exec("DELETE FROM domains")
+
+	registry := NewRegistry(db)
+	seeded, err := registry.EnsureDefaults()
+	require.NoError(t, err)
+	assert.Equal(t, len(Curated), seeded)
+	assert.Equal(t, 1.0, registry.Lookup(models.Article{URL: "https://www.reuters.com/world"}).Score)
+
+	// Edits replace the entry and are visible to the next lookup
+	saved, err := registry.Save(models.Domain{Domain: "https://www.Reuters.com", Name: "Reuters", Score: 0.4, Category: "Wire", Country: "gb", IsBlocked: true})
+	require.NoError(t, err)
+	assert.Equal(t, "reuters.com", saved.Domain)
+	assert.Equal(t, "wire", saved.Category)
+	assert.Equal(t, "GB", saved.Country)
+	assert.Equal(t, 0.4, registry.Lookup(models.Article{URL: "https://www.reuters.com/world"}).Score)
+
+	_, err = registry.Save(models.Domain{Domain: "example.com", Score: 1.5})
+	assert.ErrorIs(t, err, ErrInvalidScore)
+	_, err = registry.Save(models.Domain{Domain: "localhost", Score: 0.5})
+	assert.ErrorIs(t, err, ErrInvalidDomain)
+	_, err = registry.Save(models.Domain{Domain: "example.com", Score: 0.5, SchemaRequirement: "sometimes"})
+	assert.ErrorIs(t, err, ErrInvalidSchemaRequirement)
+	saved, err = registry.Save(models.Domain{Domain: "smallblog.example", Score: 0.5, SchemaRequirement: " Prefer "})
+	require.NoError(t, err)
+	assert.Equal(t, "prefer", saved.SchemaRequirement)
+	_, err = registry.Save(models.Domain{Domain: "example.com", Score: 0.5, FeedURLs: []string{"feed.xml"}})
+	assert.ErrorIs(t, err, ErrInvalidFeedURL)
+	saved, err = registry.Save(models.Domain{Domain: "smallblog.example", Score: 0.5, IngestFeeds: true, FeedURLs: []string{" https://smallblog.example/rss "}})
+	require.NoError(t, err)
+	assert.True(t, saved.IngestFeeds)
+	assert.Equal(t, []string{"https://smallblog.example/rss"}, []string(saved.FeedURLs))
+
+	require.NoError(t, registry.Delete("reuters.com"))
+	assert.Nil(t, registry.Lookup(models.Article{URL: "https://www.reuters.com/world"}))
+	assert.Error(t, registry.Delete("reuters.com"))
+
+	seeded, err = registry.EnsureDefaults()
+	require.NoError(t, err)
+	assert.Zero(t, seeded, "a table with entries isn't seeded again")
+}
@@ -0,0 +1,204 @@
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"open-news/internal/fetcher"
+	"open-news/internal/metadata"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OptOutVerificationName is the name of the <meta> tag on a site's home page,
+// and the prefix of the DNS TXT record, that proves a publisher controls the
+// domain they asked to opt out: <meta name="open-news-verification"
+// content="TOKEN">, or a TXT record "open-news-verification=TOKEN".
+const OptOutVerificationName = "open-news-verification"
+
+// ErrOptOutNotVerified is returned when neither the home page nor the DNS
+// records of a domain carry its opt-out token
+var ErrOptOutNotVerified = errors.New("the verification token wasn't found on the home page or in the domain's TXT records")
+
+// ownership checks that whoever asked for an opt-out controls the domain
+type ownership struct {
+	fetcher   *fetcher.Fetcher
+	homePage  func(domain string) string
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+}
+
+// defaultOwnership checks https home pages and the system's DNS resolver
+func defaultOwnership() ownership {
+	return ownership{
+		fetcher:   fetcher.NewFetcher(fetcher.DefaultOptions()),
+		homePage:  func(domain string) string { return "https://" + domain + "/" },
+		lookupTXT: net.DefaultResolver.LookupTXT,
+	}
+}
+
+// OptedOut reports whether a URL is on a site whose publisher opted out, or a
+// subdomain of one. A nil registry knows no opt-outs.
+func (r *Registry) OptedOut(pageURL string) bool {
+	if r == nil {
+		return false
+	}
+	return r.current().OptedOut(pageURL)
+}
+
+// OptOuts returns every opt-out, pending or active, in alphabetical order
+func (r *Registry) OptOuts() ([]models.PublisherOptOut, error) {
+	var list []models.PublisherOptOut
+	err := r.db.Order("domain ASC").Find(&list).Error
+	return list, err
+}
+
+// activeOptOuts returns the domains of active opt-outs
+func (r *Registry) activeOptOuts() ([]string, error) {
+	var list []string
+	err := r.db.Model(&models.PublisherOptOut{}).Where("status = ?", models.OptOutActive).Pluck("domain", &list).Error
+	return list, err
+}
+
+// RequestOptOut records a publisher's request to opt a domain out, pending
+// until VerifyOptOut finds its token on the site. A domain with a request
+// already keeps it, and its token.
+func (r *Registry) RequestOptOut(domain, contact, reason string) (*models.PublisherOptOut, error) {
+	domain = Normalize(domain)
+	if domain == "" || !strings.Contains(domain, ".") {
+		return nil, ErrInvalidDomain
+	}
+	token, err := newOptOutToken()
+	if err != nil {
+		return nil, err
+	}
+
+	optOut := models.PublisherOptOut{
+		Domain:  domain,
+		Status:  models.OptOutPending,
+		Contact: strings.TrimSpace(contact),
+		Reason:  strings.TrimSpace(reason),
+		Token:   token,
+	}
+	err = r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "domain"}}, DoNothing: true}).Create(&optOut).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record the opt-out of %s: %w", domain, err)
+	}
+	if err := r.db.Where("domain = ?", domain).First(&optOut).Error; err != nil {
+		return nil, err
+	}
+	return &optOut, nil
+}
+
+// VerifyOptOut activates a pending opt-out once its token is published in a
+// <meta name="open-news-verification"> tag on the site's home page, or in a
+// TXT record of the domain. It returns gorm.ErrRecordNotFound when the domain
+// has no opt-out and ErrOptOutNotVerified when the token isn't found. Active
+// opt-outs are returned as they are.
+func (r *Registry) VerifyOptOut(ctx context.Context, domain string) (*models.PublisherOptOut, error) {
+	var optOut models.PublisherOptOut
+	if err := r.db.Where("domain = ?", Normalize(domain)).First(&optOut).Error; err != nil {
+		return nil, err
+	}
+	if optOut.Status == models.OptOutActive {
+		return &optOut, nil
+	}
+
+	check := r.ownership
+	if check == nil {
+		defaults := defaultOwnership()
+		check = &defaults
+	}
+	method := check.verify(ctx, optOut.Domain, optOut.Token)
+	if method == "" {
+		return nil, ErrOptOutNotVerified
+	}
+	return r.activateOptOut(optOut, method)
+}
+
+// AddOptOut opts a domain out on a publisher's behalf, without verification
+func (r *Registry) AddOptOut(domain, contact, reason string) (*models.PublisherOptOut, error) {
+	optOut, err := r.RequestOptOut(domain, contact, reason)
+	if err != nil {
+		return nil, err
+	}
+	return r.activateOptOut(*optOut, "admin")
+}
+
+// activateOptOut makes an opt-out take effect: the domain's stored articles
+// lose their cached content, and links to it are skipped from the next lookup
+func (r *Registry) activateOptOut(optOut models.PublisherOptOut, method string) (*models.PublisherOptOut, error) {
+	now := time.Now()
+	optOut.Status = models.OptOutActive
+	optOut.VerifiedBy = method
+	optOut.VerifiedAt = &now
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&optOut).Select("status", "verified_by", "verified_at").Updates(&optOut).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Article{}).Scopes(OnSites([]string{optOut.Domain})).Updates(map[string]interface{}{
+			"html_content": "",
+			"text_content": "",
+			"is_cached":    false,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate the opt-out of %s: %w", optOut.Domain, err)
+	}
+	r.invalidate()
+	return &optOut, nil
+}
+
+// DeleteOptOut removes a domain's opt-out, so its links are stored again. It
+// returns gorm.ErrRecordNotFound when there's no such opt-out.
+func (r *Registry) DeleteOptOut(domain string) error {
+	result := r.db.Where("domain = ?", Normalize(domain)).Delete(&models.PublisherOptOut{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete opt-out: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.invalidate()
+	return nil
+}
+
+// verify returns how a domain proved it published token, "meta" or "dns", or
+// "" when it didn't
+func (o *ownership) verify(ctx context.Context, domain, token string) string {
+	if page, err := o.fetcher.FetchHTML(ctx, o.homePage(domain)); err == nil && SameDomain(page.URL, domain) {
+		if metadata.ReadMetaTags(page.Root)[OptOutVerificationName] == token {
+			return "meta"
+		}
+	}
+	if records, err := o.lookupTXT(ctx, domain); err == nil {
+		for _, record := range records {
+			if strings.TrimSpace(record) == OptOutVerificationName+"="+token {
+				return "dns"
+			}
+		}
+	}
+	return ""
+}
+
+// SameDomain reports whether a URL is on a domain or one of its subdomains
+func SameDomain(pageURL, domain string) bool {
+	host := Normalize(pageURL)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// newOptOutToken returns a random token for a publisher to publish on their site
+func newOptOutToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate a verification token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
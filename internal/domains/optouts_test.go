@@ -0,0 +1,116 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"open-news/internal/database"
+	"open-news/internal/fetcher"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTable_OptedOut(t *testing.T) {
+	table := NewTable(nil)
+	table.optedOut = map[string]bool{"example.com": true}
+
+	assert.True(t, table.OptedOut("https://www.example.com/story"))
+	assert.True(t, table.OptedOut("https://news.example.com/story"), "subdomains are opted out with their parent")
+	assert.False(t, table.OptedOut("https://notexample.com/story"))
+	assert.False(t, table.OptedOut("https://com/story"))
+
+	var registry *Registry
+	assert.False(t, registry.OptedOut("https://example.com/story"), "a nil registry knows no opt-outs")
+}
+
+func TestSameDomain(t *testing.T) {
+	assert.True(t, SameDomain("https://www.example.com/story", "example.com"))
+	assert.True(t, SameDomain("https://news.example.com/story", "example.com"))
+	assert.False(t, SameDomain("https://notexample.com/story", "example.com"))
+	assert.False(t, SameDomain("https://example.com.evil.test/story", "example.com"))
+}
+
+func TestOwnershipVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><meta name="open-news-verification" content="pagetoken"></head></html>`))
+	}))
+	defer server.Close()
+
+	check := ownership{
+		fetcher:  fetcher.NewFetcher(fetcher.Options{AllowPrivateNetworks: true}),
+		homePage: func(string) string { return server.URL + "/" },
+		lookupTXT: func(_ context.Context, name string) ([]string, error) {
+			if name != "127.0.0.1" {
+				return nil, errors.New("no such host")
+			}
+			return []string{"v=spf1 -all", "open-news-verification=dnstoken"}, nil
+		},
+	}
+
+	assert.Equal(t, "meta", check.verify(context.Background(), "127.0.0.1", "pagetoken"))
+	assert.Equal(t, "dns", check.verify(context.Background(), "127.0.0.1", "dnstoken"))
+	assert.Empty(t, check.verify(context.Background(), "127.0.0.1", "othertoken"))
+	assert.Empty(t, check.verify(context.Background(), "example.com", "pagetoken"), "the home page has to be on the domain")
+}
+
+func TestRegistry_OptOuts(t *testing.T) {
+	os.Setenv("DB_USER", "mterenzi")
+	os.Setenv("DB_NAME", "open_news_test")
+	if err := database.Connect(database.LoadConfig()); err != nil {
+		t.Skipf("Skipping test - PostgreSQL test database not available: %v", err)
+	}
+	db := database.DB
+	require.NoError(t, db.AutoMigrate(&models.Domain{}, &models.PublisherOptOut{}, &models.Article{}))
+	db.Exec("DELETE FROM publisher_opt_outs")
+	db.Exec("DELETE FROM articles WHERE url LIKE 'https://optout.example/%'")
+
+	article := models.Article{URL: "https://optout.example/story", Title: "Story", HTMLContent: "<html></html>", TextContent: "Story", IsCached: true}
+	require.NoError(t, db.Create(&article).Error)
+
+	registry := NewRegistry(db)
+	registry.ownership = &ownership{
+		fetcher:   fetcher.NewFetcher(fetcher.Options{}),
+		homePage:  func(string) string { return "http://localhost.invalid/" },
+		lookupTXT: func(context.Context, string) ([]string, error) { return nil, errors.New("no such host") },
+	}
+
+	_, err := registry.RequestOptOut("localhost", "", "")
+	assert.ErrorIs(t, err, ErrInvalidDomain)
+	requested, err := registry.RequestOptOut("https://www.optout.example/", "legal@optout.example", "No thanks")
+	require.NoError(t, err)
+	assert.Equal(t, models.OptOutPending, requested.Status)
+	assert.Len(t, requested.Token, 32)
+	again, err := registry.RequestOptOut("optout.example", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, requested.Token, again.Token, "a repeated request keeps its token")
+	assert.False(t, registry.OptedOut("https://optout.example/story"), "pending opt-outs don't take effect")
+
+	_, err = registry.VerifyOptOut(context.Background(), "optout.example")
+	assert.ErrorIs(t, err, ErrOptOutNotVerified)
+	_, err = registry.VerifyOptOut(context.Background(), "unknown.example")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	added, err := registry.AddOptOut("optout.example", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.OptOutActive, added.Status)
+	assert.Equal(t, "admin", added.VerifiedBy)
+	assert.True(t, registry.OptedOut("https://optout.example/story"))
+
+	require.NoError(t, db.First(&article, "id = ?", article.ID).Error)
+	assert.Empty(t, article.HTMLContent, "cached content is dropped")
+	assert.Empty(t, article.TextContent)
+	assert.False(t, article.IsCached)
+
+	require.NoError(t, registry.DeleteOptOut("optout.example"))
+	assert.False(t, registry.OptedOut("https://optout.example/story"))
+	assert.ErrorIs(t, registry.DeleteOptOut("optout.example"), gorm.ErrRecordNotFound)
+	db.Delete(&article)
+}
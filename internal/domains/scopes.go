@@ -0,0 +1,75 @@
+package domains
+
+import (
+	"strings"
+
+	"open-news/internal/database"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// articleHostSQL is the lowercased host of articles.url without "www."
+const articleHostSQL = `regexp_replace(lower(substring(articles.url from '^[a-zA-Z]+://([^/:?#]+)')), '^www\.', '')`
+
+// sqliteArticleHostSQL is articleHostSQL on SQLite, which has no regular
+// expressions
+const sqliteArticleHostSQL = `url_host(articles.url)`
+
+// matchesDomainSQL matches an article to a domains row for its host or a parent domain
+func matchesDomainSQL(db *gorm.DB) string {
+	return matchesColumnSQL(db, "domains.domain")
+}
+
+// matchesColumnSQL matches an article to a row whose column holds its host or
+// a parent domain
+func matchesColumnSQL(db *gorm.DB, column string) string {
+	host := articleHostSQL
+	if database.IsSQLite(db) {
+		host = sqliteArticleHostSQL
+	}
+	return `(` + host + ` = ` + column + ` OR ` + host + ` LIKE '%.' || ` + column + `)`
+}
+
+// NotBlocked is a query scope on articles that leaves out articles from blocked
+// domains and from publishers that opted out
+func NotBlocked(db *gorm.DB) *gorm.DB {
+	return db.Where(`NOT EXISTS (SELECT 1 FROM domains WHERE domains.is_blocked AND `+matchesDomainSQL(db)+`)`).
+		Where(`NOT EXISTS (SELECT 1 FROM publisher_opt_outs WHERE publisher_opt_outs.status = ? AND `+matchesColumnSQL(db, "publisher_opt_outs.domain")+`)`, models.OptOutActive)
+}
+
+// OnSites returns a query scope on articles that keeps articles from the given
+// sites or their subdomains, whether or not they're in the domains table
+func OnSites(sites []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		host := articleHostSQL
+		if database.IsSQLite(db) {
+			host = sqliteArticleHostSQL
+		}
+		conditions := make([]string, len(sites))
+		args := make([]interface{}, 0, 2*len(sites))
+		for i, site := range sites {
+			conditions[i] = `(` + host + ` = ? OR ` + host + ` LIKE ?)`
+			args = append(args, site, "%."+site)
+		}
+		return db.Where(`(`+strings.Join(conditions, " OR ")+`)`, args...)
+	}
+}
+
+// Matching returns a query scope on articles that keeps articles from domains with
+// the given category and country. Empty values match any domain in the table.
+func Matching(category, country string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		conditions := []string{matchesDomainSQL(db)}
+		var args []interface{}
+		if category != "" {
+			conditions = append(conditions, "domains.category = ?")
+			args = append(args, strings.ToLower(category))
+		}
+		if country != "" {
+			conditions = append(conditions, "domains.country = ?")
+			args = append(args, strings.ToUpper(country))
+		}
+		return db.Where(`EXISTS (SELECT 1 FROM domains WHERE `+strings.Join(conditions, " AND ")+`)`, args...)
+	}
+}
@@ -0,0 +1,186 @@
+// Package dryrun lets commands that write to the database run without committing.
+// The command works in a transaction that's rolled back, and a Report records the
+// rows it would have created and the items it skipped, as JSON for CI checks.
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Item is a row that would be created, or an input that was skipped
+type Item struct {
+	Kind   string `json:"kind"` // Table name, or the kind of input for skipped items
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"` // Why a skipped item was skipped
+}
+
+// Report collects what a command created and skipped
+type Report struct {
+	Command string         `json:"command"`
+	DryRun  bool           `json:"dry_run"`
+	Counts  map[string]int `json:"counts"` // Rows created per table
+	Created []Item         `json:"created"`
+	Skipped []Item         `json:"skipped"`
+	Errors  []string       `json:"errors"`
+
+	mu sync.Mutex
+}
+
+// NewReport creates an empty report for a command
+func NewReport(command string, dryRun bool) *Report {
+	return &Report{
+		Command: command,
+		DryRun:  dryRun,
+		Counts:  make(map[string]int),
+		Created: []Item{},
+		Skipped: []Item{},
+		Errors:  []string{},
+	}
+}
+
+// Create records a created row
+func (r *Report) Create(kind, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Counts[kind]++
+	r.Created = append(r.Created, Item{Kind: kind, Name: name})
+}
+
+// Skip records an input that wasn't imported and why
+func (r *Report) Skip(kind, name, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Skipped = append(r.Skipped, Item{Kind: kind, Name: name, Reason: reason})
+}
+
+// Fail records a failure
+func (r *Report) Fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// Track records every row created through db in the report, and every failed
+// create as an error. Callbacks apply to sessions and transactions of db too.
+func (r *Report) Track(db *gorm.DB) error {
+	return db.Callback().Create().After("gorm:create").Register("dryrun:report", func(tx *gorm.DB) {
+		table := tx.Statement.Table
+		if tx.Error != nil {
+			r.Fail(fmt.Errorf("failed to create %s: %w", table, tx.Error))
+			return
+		}
+		value := reflect.Indirect(tx.Statement.ReflectValue)
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				r.Create(table, Describe(value.Index(i)))
+			}
+		case reflect.Struct:
+			r.Create(table, Describe(value))
+		}
+	})
+}
+
+// describeFields are the fields that identify a row, in order of preference
+var describeFields = []string{"Handle", "URL", "PostURI", "Email", "Slug", "Name", "ID"}
+
+// Describe names a model by its handle, URL or other identifying field
+func Describe(value reflect.Value) string {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return fmt.Sprint(value.Interface())
+	}
+	for _, name := range describeFields {
+		field := value.FieldByName(name)
+		if field.IsValid() && !field.IsZero() {
+			return fmt.Sprint(field.Interface())
+		}
+	}
+	return value.Type().Name()
+}
+
+// Session is the database a command writes to and the report of what it wrote
+type Session struct {
+	DB     *gorm.DB // A transaction that's rolled back when dry running
+	Report *Report
+}
+
+// Start begins a session for a command. Rows created through db are recorded in
+// the report; in a dry run, they're created in a transaction that Finish rolls back.
+func Start(db *gorm.DB, command string, dryRun bool) (*Session, error) {
+	session := &Session{DB: db, Report: NewReport(command, dryRun)}
+	if err := session.Report.Track(db); err != nil {
+		return nil, fmt.Errorf("failed to track created rows: %w", err)
+	}
+	if dryRun {
+		session.DB = db.Begin()
+		if session.DB.Error != nil {
+			return nil, fmt.Errorf("failed to start dry run transaction: %w", session.DB.Error)
+		}
+	}
+	return session, nil
+}
+
+// LogToStderr moves SQL logging to stderr and down to warnings, so it doesn't mix
+// with a report written to stdout
+func LogToStderr(db *gorm.DB) {
+	db.Logger = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+	})
+}
+
+// Finish rolls back a dry run and writes the report as JSON to path, "-" for
+// stdout, or nowhere when path is empty
+func (s *Session) Finish(path string) error {
+	if s.Report.DryRun {
+		if err := s.DB.Rollback().Error; err != nil {
+			return fmt.Errorf("failed to roll back dry run: %w", err)
+		}
+	}
+
+	switch path {
+	case "":
+		return nil
+	case "-":
+		return s.Report.WriteJSON(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := s.Report.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteJSON writes the report as indented JSON, with created and skipped items
+// sorted so reports of the same data can be diffed
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, items := range [][]Item{r.Created, r.Skipped} {
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Kind != items[j].Kind {
+				return items[i].Kind < items[j].Kind
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
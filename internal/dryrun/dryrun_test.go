@@ -0,0 +1,64 @@
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	type source struct {
+		ID     uuid.UUID
+		Handle string
+	}
+	type article struct {
+		ID  uuid.UUID
+		URL string
+	}
+	type userSource struct {
+		UserID uuid.UUID
+	}
+
+	id := uuid.New()
+	assert.Equal(t, "reporter.bsky.social", Describe(reflect.ValueOf(&source{ID: id, Handle: "reporter.bsky.social"})))
+	assert.Equal(t, "https://example.com/story", Describe(reflect.ValueOf(article{URL: "https://example.com/story"})))
+	assert.Equal(t, id.String(), Describe(reflect.ValueOf(source{ID: id})), "falls back to the ID")
+	assert.Equal(t, "userSource", Describe(reflect.ValueOf(userSource{UserID: id})))
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	report := NewReport("seed", true)
+	report.Create("sources", "b.bsky.social")
+	report.Create("sources", "a.bsky.social")
+	report.Skip("posts", "at://did:plc:a/app.bsky.feed.post/1", "no links")
+	report.Fail(errors.New("failed to create articles: duplicate key"))
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+
+	var decoded struct {
+		Command string         `json:"command"`
+		DryRun  bool           `json:"dry_run"`
+		Counts  map[string]int `json:"counts"`
+		Created []Item         `json:"created"`
+		Skipped []Item         `json:"skipped"`
+		Errors  []string       `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.True(t, decoded.DryRun)
+	assert.Equal(t, map[string]int{"sources": 2}, decoded.Counts)
+	assert.Equal(t, []Item{{Kind: "sources", Name: "a.bsky.social"}, {Kind: "sources", Name: "b.bsky.social"}}, decoded.Created)
+	assert.Equal(t, "no links", decoded.Skipped[0].Reason)
+	assert.Len(t, decoded.Errors, 1)
+
+	// Empty lists are written as [], which is easier for CI scripts to check
+	buf.Reset()
+	require.NoError(t, NewReport("backfill", false).WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"skipped": []`)
+}
@@ -0,0 +1,105 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxAPIInput caps the characters of each text sent to the API
+const maxAPIInput = 8000
+
+// APIProvider computes embeddings with an OpenAI-compatible embeddings API
+type APIProvider struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewAPIProvider creates a provider that calls the embeddings endpoint at url
+func NewAPIProvider(url, apiKey, model string) *APIProvider {
+	return &APIProvider{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name identifies the provider and model
+func (p *APIProvider) Name() string {
+	return "api:" + p.model
+}
+
+// Embed sends the texts to the API in one request and returns unit vectors in
+// the order of the texts
+func (p *APIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	input := make([]string, len(texts))
+	for i, text := range texts {
+		if len(text) > maxAPIInput {
+			text = text[:maxAPIInput]
+		}
+		if strings.TrimSpace(text) == "" {
+			text = " " // The API rejects empty input
+		}
+		input[i] = text
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": input,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("embeddings response has unexpected index %d", item.Index)
+		}
+		vectors[item.Index] = Normalize(item.Embedding)
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("embeddings response is missing text %d", i)
+		}
+	}
+	return vectors, nil
+}
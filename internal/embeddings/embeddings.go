@@ -0,0 +1,123 @@
+// Package embeddings turns article text into vectors for similarity search
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Provider computes embeddings of texts
+type Provider interface {
+	// Name identifies the provider and model in article_embeddings.model.
+	// Vectors from different providers can't be compared.
+	Name() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Config selects and configures a provider, read from EMBEDDINGS_* settings
+type Config struct {
+	Provider   string // EMBEDDINGS_PROVIDER: "" (disabled), "hash" or "api"
+	Dimensions int    // EMBEDDINGS_DIMENSIONS for the hash provider (default: 256)
+	APIURL     string // EMBEDDINGS_API_URL (default: OpenAI's embeddings endpoint)
+	APIKey     string // EMBEDDINGS_API_KEY, or OPENAI_API_KEY
+	Model      string // EMBEDDINGS_MODEL
+}
+
+const (
+	// defaultAPIURL is the embeddings endpoint used without EMBEDDINGS_API_URL
+	defaultAPIURL = "https://api.openai.com/v1/embeddings"
+
+	// defaultDimensions is the size of hash provider vectors
+	defaultDimensions = 256
+)
+
+// LoadConfig reads the provider settings from the environment
+func LoadConfig() Config {
+	config := Config{
+		Provider:   os.Getenv("EMBEDDINGS_PROVIDER"),
+		Dimensions: defaultDimensions,
+		APIURL:     os.Getenv("EMBEDDINGS_API_URL"),
+		APIKey:     os.Getenv("EMBEDDINGS_API_KEY"),
+		Model:      os.Getenv("EMBEDDINGS_MODEL"),
+	}
+	if value := os.Getenv("EMBEDDINGS_DIMENSIONS"); value != "" {
+		if dimensions, err := strconv.Atoi(value); err == nil && dimensions > 0 {
+			config.Dimensions = dimensions
+		} else {
+			log.Printf("Invalid EMBEDDINGS_DIMENSIONS %q, using %d", value, defaultDimensions)
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = defaultAPIURL
+	}
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	return config
+}
+
+// New creates the provider a config selects, or nil when embeddings are disabled
+func New(config Config) (Provider, error) {
+	switch config.Provider {
+	case "", "none":
+		return nil, nil
+	case "hash":
+		return NewHashProvider(config.Dimensions), nil
+	case "api":
+		if config.APIKey == "" || config.Model == "" {
+			return nil, fmt.Errorf("EMBEDDINGS_API_KEY and EMBEDDINGS_MODEL are required for the api provider")
+		}
+		return NewAPIProvider(config.APIURL, config.APIKey, config.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown EMBEDDINGS_PROVIDER %q", config.Provider)
+	}
+}
+
+// FromEnv creates the provider EMBEDDINGS_* selects, or nil when embeddings are
+// disabled or the settings are invalid
+func FromEnv() Provider {
+	provider, err := New(LoadConfig())
+	if err != nil {
+		log.Printf("⚠️  %v, embeddings disabled", err)
+		return nil
+	}
+	return provider
+}
+
+// Normalize scales a vector to unit length in place, so cosine similarity is a dot product
+func Normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// Cosine returns the cosine similarity of two vectors of the same size
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,85 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashProvider(t *testing.T) {
+	provider := NewHashProvider(256)
+	assert.Equal(t, "hash-256", provider.Name())
+
+	vectors, err := provider.Embed(context.Background(), []string{
+		"European Central Bank raises interest rates again",
+		"ECB raises interest rates for the tenth time",
+		"Local football team wins the cup final",
+		"",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+	assert.Len(t, vectors[0], 256)
+	assert.InDelta(t, 1.0, Cosine(vectors[0], vectors[0]), 1e-6, "vectors are normalized")
+	assert.Greater(t, Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2]), "the same story is nearer than another")
+	assert.Equal(t, 0.0, Cosine(vectors[0], vectors[3]))
+}
+
+func TestAPIProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+		assert.Equal(t, []string{"first", " "}, req.Input)
+
+		// Out of order, as the API allows
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"index": 1, "embedding": []float32{0, 2}},
+				{"index": 0, "embedding": []float32{3, 4}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewAPIProvider(server.URL, "test-key", "test-model")
+	assert.Equal(t, "api:test-model", provider.Name())
+
+	vectors, err := provider.Embed(context.Background(), []string{"first", ""})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.6, 0.8}, {0, 1}}, vectors)
+}
+
+func TestAPIProvider_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewAPIProvider(server.URL, "key", "model").Embed(context.Background(), []string{"text"})
+	assert.ErrorContains(t, err, "429")
+}
+
+func TestNew(t *testing.T) {
+	provider, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, provider, "embeddings are disabled by default")
+
+	provider, err = New(Config{Provider: "hash", Dimensions: 64})
+	require.NoError(t, err)
+	assert.Equal(t, "hash-64", provider.Name())
+
+	_, err = New(Config{Provider: "api", APIURL: defaultAPIURL})
+	assert.Error(t, err, "the api provider needs a key and model")
+
+	_, err = New(Config{Provider: "magic"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// HashProvider embeds text locally by hashing its words and word pairs into a
+// fixed number of dimensions. It needs no external service and finds articles
+// sharing vocabulary, but doesn't know synonyms the way a trained model does.
+type HashProvider struct {
+	dimensions int
+}
+
+// NewHashProvider creates a hash provider producing vectors of the given size
+func NewHashProvider(dimensions int) *HashProvider {
+	if dimensions <= 0 {
+		dimensions = defaultDimensions
+	}
+	return &HashProvider{dimensions: dimensions}
+}
+
+// Name identifies the provider and vector size
+func (p *HashProvider) Name() string {
+	return fmt.Sprintf("hash-%d", p.dimensions)
+}
+
+// Embed hashes each text into a unit vector
+func (p *HashProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = p.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed adds each word, and each pair of adjacent words at half weight, to the
+// dimension its hash selects. A second hash bit picks the sign, so collisions
+// tend to cancel out rather than add up.
+func (p *HashProvider) embed(text string) []float32 {
+	vector := make([]float32, p.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[sum%uint64(p.dimensions)] += weight
+	}
+	for i, word := range words {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		add(word, 1)
+		if i+1 < len(words) {
+			add(word+" "+words[i+1], 0.5)
+		}
+	}
+	return Normalize(vector)
+}
+
+// stopWords are common English words that say nothing about a story
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "can": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "has": true, "have": true, "his": true, "how": true,
+	"its": true, "new": true, "now": true, "who": true, "why": true, "with": true,
+	"that": true, "this": true, "from": true, "they": true, "will": true, "what": true,
+	"when": true, "were": true, "been": true, "after": true, "about": true, "over": true,
+	"into": true, "than": true, "their": true, "there": true, "says": true, "said": true,
+}
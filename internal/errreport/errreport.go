@@ -0,0 +1,217 @@
+// Package errreport sends errors that need an operator's attention, such as
+// panics, failed jobs and pages that keep failing to fetch, to an error
+// tracker. Sentry is supported; the log reporter prints them instead.
+package errreport
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tags describe where an error happened, e.g. the article URL or source DID
+type Tags map[string]string
+
+// Event is an error to report
+type Event struct {
+	Message   string
+	Type      string // The error's Go type, or "panic"
+	Panic     bool
+	Stack     []byte      // Stack of the goroutine the error happened on, for panics
+	Err       error       // The reported error, so trackers can read its chain
+	Recovered interface{} // The value a panic was recovered with
+	Tags      Tags
+	Time      time.Time
+}
+
+// Reporter delivers events to an error tracker
+type Reporter interface {
+	Report(event Event)
+	// Flush waits up to timeout for reported events to be delivered
+	Flush(timeout time.Duration)
+}
+
+// Config selects and configures a reporter, read from ERROR_REPORTER and
+// SENTRY_* settings
+type Config struct {
+	Reporter    string // ERROR_REPORTER: "sentry", "log" or "none" (default: sentry when SENTRY_DSN is set, otherwise log)
+	DSN         string // SENTRY_DSN
+	Environment string // SENTRY_ENVIRONMENT, e.g. "production"
+	Release     string // SENTRY_RELEASE, e.g. a git commit
+
+	// FetchFailures is how many times in a row a page fails to fetch before
+	// it's reported, and again every as many failures after that
+	// (ERROR_REPORT_FETCH_FAILURES, default 3)
+	FetchFailures int
+}
+
+// defaultFetchFailures is how many failed fetches of a page are reported
+// without ERROR_REPORT_FETCH_FAILURES
+const defaultFetchFailures = 3
+
+// LoadConfig reads the reporter settings from the environment
+func LoadConfig() Config {
+	config := Config{
+		Reporter:      os.Getenv("ERROR_REPORTER"),
+		DSN:           os.Getenv("SENTRY_DSN"),
+		Environment:   os.Getenv("SENTRY_ENVIRONMENT"),
+		Release:       os.Getenv("SENTRY_RELEASE"),
+		FetchFailures: defaultFetchFailures,
+	}
+	if config.Reporter == "" {
+		config.Reporter = "log"
+		if config.DSN != "" {
+			config.Reporter = "sentry"
+		}
+	}
+	if value := os.Getenv("ERROR_REPORT_FETCH_FAILURES"); value != "" {
+		if failures, err := strconv.Atoi(value); err == nil && failures > 0 {
+			config.FetchFailures = failures
+		} else {
+			log.Printf("Invalid ERROR_REPORT_FETCH_FAILURES %q, using %d", value, defaultFetchFailures)
+		}
+	}
+	return config
+}
+
+// New creates the reporter a config selects, or nil when reporting is off
+func New(config Config) (Reporter, error) {
+	switch config.Reporter {
+	case "none":
+		return nil, nil
+	case "log":
+		return LogReporter{}, nil
+	case "sentry":
+		if config.DSN == "" {
+			return nil, fmt.Errorf("SENTRY_DSN is required for the sentry error reporter")
+		}
+		return NewSentryReporter(config.DSN, config.Environment, config.Release)
+	default:
+		return nil, fmt.Errorf("unknown ERROR_REPORTER %q", config.Reporter)
+	}
+}
+
+var (
+	mu            sync.RWMutex
+	active        Reporter = LogReporter{}
+	fetchFailures          = defaultFetchFailures
+)
+
+// Init makes the reporter ERROR_REPORTER and SENTRY_* select the one errors are
+// reported to. Invalid settings fall back to logging errors.
+func Init() {
+	config := LoadConfig()
+	reporter, err := New(config)
+	if err != nil {
+		log.Printf("⚠️  %v, logging errors instead", err)
+		reporter = LogReporter{}
+	} else if _, ok := reporter.(*SentryReporter); ok {
+		log.Printf("🚨 Reporting errors to Sentry")
+	}
+	SetReporter(reporter)
+
+	mu.Lock()
+	fetchFailures = config.FetchFailures
+	mu.Unlock()
+}
+
+// SetReporter sets the reporter errors are reported to; nil turns reporting off
+func SetReporter(reporter Reporter) {
+	mu.Lock()
+	active = reporter
+	mu.Unlock()
+}
+
+// current returns the active reporter, or nil when reporting is off
+func current() Reporter {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Capture reports an error. A nil err is ignored.
+func Capture(err error, tags Tags) {
+	if err == nil {
+		return
+	}
+	if reporter := current(); reporter != nil {
+		reporter.Report(Event{Message: err.Error(), Type: fmt.Sprintf("%T", err), Err: err, Tags: tags, Time: time.Now()})
+	}
+}
+
+// CapturePanic reports a recovered panic with the stack it unwound
+func CapturePanic(value interface{}, stack []byte, tags Tags) {
+	if reporter := current(); reporter != nil {
+		reporter.Report(Event{Message: fmt.Sprint(value), Type: "panic", Panic: true, Stack: stack, Recovered: value, Tags: tags, Time: time.Now()})
+	}
+}
+
+// Crash reports a panic unwinding the calling goroutine, waits for the report
+// to be sent, then lets the panic carry on. Defer it at the top of goroutines
+// whose panics take the process down.
+func Crash(tags Tags) {
+	if r := recover(); r != nil {
+		CapturePanic(r, debug.Stack(), tags)
+		Flush(5 * time.Second)
+		panic(r)
+	}
+}
+
+// Flush waits up to timeout for reported errors to be delivered. Call it before
+// exiting.
+func Flush(timeout time.Duration) {
+	if reporter := current(); reporter != nil {
+		reporter.Flush(timeout)
+	}
+}
+
+// RepeatedFetchFailure reports whether a page that has failed to fetch failures
+// times in a row should be reported: when it reaches ERROR_REPORT_FETCH_FAILURES,
+// and again every as many failures after that
+func RepeatedFetchFailure(failures int) bool {
+	mu.RLock()
+	threshold := fetchFailures
+	mu.RUnlock()
+	return failures > 0 && failures%threshold == 0
+}
+
+// LogReporter prints reported errors to the server log
+type LogReporter struct{}
+
+// Report implements Reporter
+func (LogReporter) Report(event Event) {
+	kind := "Error"
+	if event.Panic {
+		kind = "Panic"
+	}
+	log.Printf("🚨 %s: %s%s", kind, event.Message, event.Tags)
+	if len(event.Stack) > 0 {
+		log.Printf("%s", event.Stack)
+	}
+}
+
+// Flush implements Reporter
+func (LogReporter) Flush(time.Duration) {}
+
+// String formats tags as " (key=value, ...)" in key order, or "" without tags
+func (tags Tags) String() string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + tags[key]
+	}
+	return " (" + strings.Join(pairs, ", ") + ")"
+}
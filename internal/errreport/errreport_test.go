@@ -0,0 +1,166 @@
+package errreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepeatedFetchFailure(t *testing.T) {
+	assert.False(t, RepeatedFetchFailure(0))
+	assert.False(t, RepeatedFetchFailure(2))
+	assert.True(t, RepeatedFetchFailure(3))
+	assert.False(t, RepeatedFetchFailure(4))
+	assert.True(t, RepeatedFetchFailure(6))
+}
+
+func TestNew(t *testing.T) {
+	reporter, err := New(Config{Reporter: "none"})
+	assert.NoError(t, err)
+	assert.Nil(t, reporter)
+
+	_, err = New(Config{Reporter: "sentry"})
+	assert.Error(t, err, "sentry needs a DSN")
+
+	for _, dsn := range []string{"https://o42.ingest.sentry.io/1234", "https://key@o42.ingest.sentry.io/", "not a dsn"} {
+		_, err = New(Config{Reporter: "sentry", DSN: dsn})
+		assert.Error(t, err, dsn)
+	}
+
+	_, err = New(Config{Reporter: "pagerduty"})
+	assert.Error(t, err)
+}
+
+// recorder is a reporter keeping the events reported to it
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Report(event Event) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *recorder) Flush(time.Duration) {}
+
+func TestCrash(t *testing.T) {
+	rec := &recorder{}
+	SetReporter(rec)
+	defer SetReporter(LogReporter{})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer Crash(Tags{"worker": "test"})
+		panic("boom")
+	}, "the panic carries on after it's reported")
+
+	require.Len(t, rec.events, 1)
+	event := rec.events[0]
+	assert.True(t, event.Panic)
+	assert.Equal(t, "boom", event.Message)
+	assert.Equal(t, "test", event.Tags["worker"])
+	assert.Contains(t, string(event.Stack), "TestCrash")
+
+	Capture(nil, nil)
+	assert.Len(t, rec.events, 1, "nil errors aren't reported")
+}
+
+// sentryEnvelopeEvent is the part of an event in a Sentry envelope the tests check
+type sentryEnvelopeEvent struct {
+	Level       string            `json:"level"`
+	Environment string            `json:"environment"`
+	Release     string            `json:"release"`
+	Tags        map[string]string `json:"tags"`
+	Message     string            `json:"message"`
+	Exception   []struct {
+		Type       string `json:"type"`
+		Value      string `json:"value"`
+		Stacktrace *struct {
+			Frames []struct {
+				Function string `json:"function"`
+				InApp    bool   `json:"in_app"`
+			} `json:"frames"`
+		} `json:"stacktrace"`
+	} `json:"exception"`
+}
+
+func TestSentryReporter(t *testing.T) {
+	var mu sync.Mutex
+	var auth []string
+	var events []sentryEnvelopeEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/envelope/", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		// An envelope is a header line, then an item header and payload line per item
+		lines := strings.Split(string(body), "\n")
+		mu.Lock()
+		defer mu.Unlock()
+		auth = append(auth, r.Header.Get("X-Sentry-Auth"))
+		for i := 1; i+1 < len(lines); i += 2 {
+			if !strings.Contains(lines[i], `"type":"event"`) {
+				continue
+			}
+			var event sentryEnvelopeEvent
+			require.NoError(t, json.Unmarshal([]byte(lines[i+1]), &event))
+			events = append(events, event)
+		}
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "http://", "http://publickey@", 1) + "/42"
+	reporter, err := NewSentryReporter(dsn, "production", "abc123")
+	require.NoError(t, err)
+	SetReporter(reporter)
+	defer SetReporter(LogReporter{})
+
+	Capture(fmt.Errorf("article 3 failed: %w", errors.New("connection refused")), Tags{"article.url": "https://example.com/story"})
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				CapturePanic(r, nil, Tags{"worker": "test"})
+			}
+		}()
+		panic("nil map")
+	}()
+	Flush(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+	assert.Contains(t, auth[0], "sentry_key=publickey")
+
+	errorEvent, panicEvent := events[0], events[1]
+	if errorEvent.Level == "fatal" {
+		errorEvent, panicEvent = panicEvent, errorEvent
+	}
+
+	assert.Equal(t, "error", errorEvent.Level)
+	assert.Equal(t, "production", errorEvent.Environment)
+	assert.Equal(t, "abc123", errorEvent.Release)
+	assert.Equal(t, "https://example.com/story", errorEvent.Tags["article.url"])
+	require.NotEmpty(t, errorEvent.Exception)
+	outer := errorEvent.Exception[len(errorEvent.Exception)-1]
+	assert.Equal(t, "article 3 failed: connection refused", outer.Value)
+	require.NotNil(t, outer.Stacktrace, "the stack is attached to the error")
+	inApp := false
+	for _, frame := range outer.Stacktrace.Frames {
+		inApp = inApp || (frame.InApp && strings.Contains(frame.Function, "TestSentryReporter"))
+	}
+	assert.True(t, inApp, "the test's own frames are in-app")
+
+	assert.Equal(t, "fatal", panicEvent.Level)
+	assert.Equal(t, "nil map", panicEvent.Message)
+	assert.Equal(t, "test", panicEvent.Tags["worker"])
+	assert.NotContains(t, panicEvent.Tags, "article.url", "tags don't leak between events")
+}
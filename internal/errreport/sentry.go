@@ -0,0 +1,58 @@
+package errreport
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryReporter sends events to Sentry with its SDK, which queues them in the
+// background, honors Sentry's rate limits and marks this module's stack frames
+// as in-app
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter creates a reporter sending to the project of a Sentry DSN,
+// e.g. https://<key>@o0.ingest.sentry.io/<project>
+func NewSentryReporter(dsn, environment, release string) (*SentryReporter, error) {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		Release:          release,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SENTRY_DSN %q: %w", dsn, err)
+	}
+	return &SentryReporter{hub: sentry.CurrentHub()}, nil
+}
+
+// Report implements Reporter. Each event gets its own scope, so the tags of
+// errors reported at the same time don't mix.
+func (r *SentryReporter) Report(event Event) {
+	hub := r.hub.Clone()
+	hub.Scope().SetTags(event.Tags)
+	switch {
+	case event.Panic:
+		recovered := event.Recovered
+		if recovered == nil {
+			recovered = event.Message
+		}
+		hub.Recover(recovered)
+	case event.Err != nil:
+		hub.CaptureException(event.Err)
+	default:
+		hub.CaptureException(errors.New(event.Message))
+	}
+}
+
+// Flush implements Reporter
+func (r *SentryReporter) Flush(timeout time.Duration) {
+	if !r.hub.Flush(timeout) {
+		log.Printf("⚠️  Timed out sending error reports")
+	}
+}
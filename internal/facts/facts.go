@@ -0,0 +1,130 @@
+// Package facts extracts key claims, quotes, statistics and named entities from
+// article text. The rule-based extractor needs no setup; the llm extractor sends
+// the text to an OpenAI-compatible chat completions API.
+package facts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"open-news/internal/llm"
+)
+
+// Fact types, stored in article_facts.fact_type
+const (
+	TypeClaim        = "claim"
+	TypeQuote        = "quote"
+	TypeStatistic    = "statistic"
+	TypePerson       = "person"
+	TypeOrganization = "organization"
+	TypePlace        = "place"
+)
+
+// EntityTypes are the fact types that name a person, organization or place
+var EntityTypes = []string{TypePerson, TypeOrganization, TypePlace}
+
+// IsEntity reports whether a fact type names an entity
+func IsEntity(factType string) bool {
+	for _, entityType := range EntityTypes {
+		if factType == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+// Fact is a claim, quote, statistic or entity found in an article
+type Fact struct {
+	Type       string
+	Text       string
+	Context    string  // Sentence the fact was found in
+	Confidence float64 // 0 to 1
+	Start, End int     // Byte offsets in the text, both -1 when the fact wasn't located
+}
+
+// Input is the article content facts are extracted from
+type Input struct {
+	Title       string
+	Description string
+	Text        string // Extracted page text; Description is used when it's empty
+}
+
+// content returns the text facts are extracted from
+func (in Input) content() string {
+	if strings.TrimSpace(in.Text) != "" {
+		return in.Text
+	}
+	return in.Description
+}
+
+// Extractor derives facts from an article
+type Extractor interface {
+	// Name identifies the extractor in article_facts.extracted_by
+	Name() string
+	Extract(ctx context.Context, input Input) ([]Fact, error)
+}
+
+// Config selects and configures an extractor, read from FACTS_* settings
+type Config struct {
+	Driver    string // FACTS_EXTRACTOR: "rules" (default) or "llm"
+	LLMURL    string // FACTS_LLM_URL (default: OpenAI's chat completions endpoint)
+	LLMAPIKey string // FACTS_LLM_API_KEY
+	LLMModel  string // FACTS_LLM_MODEL
+}
+
+// defaultLLMURL is the chat completions endpoint used without FACTS_LLM_URL
+const defaultLLMURL = llm.DefaultURL
+
+// LoadConfig reads the extractor settings from the environment
+func LoadConfig() Config {
+	config := Config{
+		Driver:    os.Getenv("FACTS_EXTRACTOR"),
+		LLMURL:    os.Getenv("FACTS_LLM_URL"),
+		LLMAPIKey: os.Getenv("FACTS_LLM_API_KEY"),
+		LLMModel:  os.Getenv("FACTS_LLM_MODEL"),
+	}
+	if config.Driver == "" {
+		config.Driver = "rules"
+	}
+	if config.LLMURL == "" {
+		config.LLMURL = defaultLLMURL
+	}
+	return config
+}
+
+// New creates the extractor a config selects
+func New(config Config) (Extractor, error) {
+	switch config.Driver {
+	case "rules":
+		return NewRuleExtractor(), nil
+	case "llm":
+		if config.LLMAPIKey == "" || config.LLMModel == "" {
+			return nil, fmt.Errorf("FACTS_LLM_API_KEY and FACTS_LLM_MODEL are required for the llm extractor")
+		}
+		return NewLLMExtractor(config.LLMURL, config.LLMAPIKey, config.LLMModel), nil
+	default:
+		return nil, fmt.Errorf("unknown FACTS_EXTRACTOR %q", config.Driver)
+	}
+}
+
+// FromEnv creates the extractor FACTS_* selects, falling back to the rule-based
+// extractor when the settings are invalid
+func FromEnv() Extractor {
+	extractor, err := New(LoadConfig())
+	if err != nil {
+		log.Printf("⚠️  %v, extracting facts with rules", err)
+		return NewRuleExtractor()
+	}
+	return extractor
+}
+
+// locate sets a fact's offsets to where its text first appears in content
+func locate(fact *Fact, content string) {
+	fact.Start, fact.End = -1, -1
+	if i := strings.Index(content, fact.Text); i >= 0 && fact.Text != "" {
+		fact.Start, fact.End = i, i+len(fact.Text)
+	}
+}
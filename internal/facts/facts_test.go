@@ -0,0 +1,122 @@
+package facts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleText = `The European Central Bank raised interest rates by 0.25 percent on Thursday. ` +
+	`"We will do whatever it takes to bring inflation back to target," President Christine Lagarde said in Frankfurt. ` +
+	`Economists at Deutsche Bank said the decision was widely expected. ` +
+	`Mr. Olaf Scholz told reporters the government supports the bank.`
+
+// byType groups facts by type, keyed by their text
+func byType(found []Fact) map[string]map[string]Fact {
+	grouped := make(map[string]map[string]Fact)
+	for _, fact := range found {
+		if grouped[fact.Type] == nil {
+			grouped[fact.Type] = make(map[string]Fact)
+		}
+		grouped[fact.Type][fact.Text] = fact
+	}
+	return grouped
+}
+
+func TestRuleExtractor(t *testing.T) {
+	found, err := NewRuleExtractor().Extract(context.Background(), Input{Text: sampleText})
+	require.NoError(t, err)
+	grouped := byType(found)
+
+	quote, ok := grouped[TypeQuote]["We will do whatever it takes to bring inflation back to target,"]
+	require.True(t, ok, "expected the quotation, got %v", grouped[TypeQuote])
+	assert.Equal(t, 0.9, quote.Confidence, "attributed quotes are more confident")
+	assert.Equal(t, quote.Text, sampleText[quote.Start:quote.End])
+
+	assert.Contains(t, grouped[TypeStatistic], "The European Central Bank raised interest rates by 0.25 percent on Thursday.")
+	assert.Contains(t, grouped[TypeClaim], "Economists at Deutsche Bank said the decision was widely expected.")
+
+	assert.Contains(t, grouped[TypeOrganization], "European Central Bank")
+	assert.Contains(t, grouped[TypeOrganization], "Deutsche Bank")
+	assert.Contains(t, grouped[TypePerson], "Christine Lagarde", "titles mark a person")
+	assert.Contains(t, grouped[TypePerson], "Olaf Scholz", "abbreviated titles don't end the sentence")
+	assert.Contains(t, grouped[TypePlace], "Frankfurt")
+	assert.NotContains(t, grouped[TypePlace], "Thursday")
+
+	person := grouped[TypePerson]["Christine Lagarde"]
+	assert.Equal(t, "Christine Lagarde", sampleText[person.Start:person.End])
+}
+
+func TestRuleExtractor_Empty(t *testing.T) {
+	found, err := NewRuleExtractor().Extract(context.Background(), Input{})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences("Dr. Jane Doe arrived. She left! Did she return? Yes.")
+	texts := make([]string, len(sentences))
+	for i, s := range sentences {
+		texts[i] = s.text
+	}
+	assert.Equal(t, []string{"Dr. Jane Doe arrived.", "She left!", "Did she return?", "Yes."}, texts)
+	assert.Equal(t, 22, sentences[1].start)
+}
+
+func TestLLMExtractor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+
+		content := `{"facts":[
+			{"type":"Person","text":"Christine Lagarde","context":"","confidence":0.95},
+			{"type":"statistic","text":"0.25 percent","confidence":1.7},
+			{"type":"rumor","text":"ignored","confidence":0.5},
+			{"type":"claim","text":"","confidence":0.5}]}`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer server.Close()
+
+	extractor := NewLLMExtractor(server.URL, "test-key", "test-model")
+	assert.Equal(t, "llm:test-model", extractor.Name())
+
+	found, err := extractor.Extract(context.Background(), Input{Title: "Rates", Text: sampleText})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, TypePerson, found[0].Type)
+	assert.Equal(t, "Christine Lagarde", sampleText[found[0].Start:found[0].End])
+	assert.Equal(t, 1.0, found[1].Confidence)
+}
+
+func TestLLMExtractor_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewLLMExtractor(server.URL, "key", "model").Extract(context.Background(), Input{Text: sampleText})
+	assert.ErrorContains(t, err, "429")
+}
+
+func TestNew(t *testing.T) {
+	extractor, err := New(Config{Driver: "rules"})
+	require.NoError(t, err)
+	assert.Equal(t, "rules", extractor.Name())
+
+	_, err = New(Config{Driver: "llm", LLMURL: defaultLLMURL})
+	assert.Error(t, err, "the llm extractor needs a key and model")
+
+	_, err = New(Config{Driver: "magic"})
+	assert.Error(t, err)
+}
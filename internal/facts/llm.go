@@ -0,0 +1,106 @@
+package facts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"open-news/internal/llm"
+)
+
+// llmPrompt asks for the facts as a JSON object
+const llmPrompt = `Extract the key facts from the news article below. Return a JSON object with a "facts" array.
+Each fact has "type" (one of claim, quote, statistic, person, organization, place), "text" (the claim,
+the exact quoted words, the statistic, or the entity's name as written), "context" (the sentence it
+appears in) and "confidence" (0 to 1). Include at most 8 claims, 8 quotes, 8 statistics and 20 entities.
+Only include facts stated in the article.`
+
+// LLMExtractor extracts facts with an OpenAI-compatible chat completions API
+type LLMExtractor struct {
+	client *llm.Client
+}
+
+// NewLLMExtractor creates an extractor that calls the chat completions endpoint at url
+func NewLLMExtractor(url, apiKey, model string) *LLMExtractor {
+	return &LLMExtractor{client: llm.NewClient(url, apiKey, model)}
+}
+
+// Name identifies the extractor and model
+func (e *LLMExtractor) Name() string {
+	return "llm:" + e.client.Model()
+}
+
+// llmFact is a fact as the model returns it
+type llmFact struct {
+	Type       string  `json:"type"`
+	Text       string  `json:"text"`
+	Context    string  `json:"context"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Extract sends the article to the API and returns the facts it found. Facts of
+// unknown types or without text are dropped.
+func (e *LLMExtractor) Extract(ctx context.Context, input Input) ([]Fact, error) {
+	content := input.content()
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	reply, err := e.client.Complete(ctx, llm.Prompt{
+		System: llmPrompt,
+		User:   llm.ArticleMessage(input.Title, content),
+		JSON:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fact extraction %w", err)
+	}
+
+	var result struct {
+		Facts []llmFact `json:"facts"`
+	}
+	if err := json.Unmarshal([]byte(reply), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted facts: %w", err)
+	}
+	return parseLLMFacts(result.Facts, content), nil
+}
+
+// parseLLMFacts validates the facts a model returned and locates them in the content
+func parseLLMFacts(found []llmFact, content string) []Fact {
+	var facts []Fact
+	for _, f := range found {
+		factType := strings.ToLower(strings.TrimSpace(f.Type))
+		text := strings.TrimSpace(f.Text)
+		if text == "" || !knownType(factType) {
+			continue
+		}
+		fact := Fact{
+			Type:       factType,
+			Text:       text,
+			Context:    strings.TrimSpace(f.Context),
+			Confidence: clamp(f.Confidence),
+		}
+		locate(&fact, content)
+		facts = append(facts, fact)
+	}
+	return facts
+}
+
+// knownType reports whether a fact type is one this package defines
+func knownType(factType string) bool {
+	switch factType {
+	case TypeClaim, TypeQuote, TypeStatistic:
+		return true
+	}
+	return IsEntity(factType)
+}
+
+// clamp limits a confidence to 0 to 1
+func clamp(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,306 @@
+package facts
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxFactsPerType caps the claims, quotes and statistics taken from one article
+const maxFactsPerType = 8
+
+// maxEntities caps the entities taken from one article, most mentioned first
+const maxEntities = 20
+
+var (
+	// boundaryPattern ends a sentence: terminal punctuation, closing quotes, then space
+	boundaryPattern = regexp.MustCompile(`[.!?]["”’)]*\s+`)
+
+	// quotePattern matches a quotation long enough to be a statement
+	quotePattern = regexp.MustCompile(`[“"]([^”"]{20,400})[”"]`)
+
+	// statisticPattern matches percentages, amounts of money and large counts
+	statisticPattern = regexp.MustCompile(`(?i)(\b\d[\d,.]*\s?(%|percent\b|per cent\b|million\b|billion\b|trillion\b|thousand\b))|([$€£]\s?\d)`)
+
+	// attributionPattern matches verbs that attribute a statement to someone
+	attributionPattern = regexp.MustCompile(`(?i)\b(said|says|announced|according to|reported|confirmed|claimed|told|warned|estimated)\b`)
+
+	// namePattern matches runs of capitalized words, allowing "of", "for", "the" and "and" inside
+	namePattern = regexp.MustCompile(`\b[A-Z][\w'’&-]*(?:\s+(?:(?:of|for|the|and)\s+)?[A-Z][\w'’&-]*)*`)
+
+	// speakerAfterPattern matches a reporting verb right after a name
+	speakerAfterPattern = regexp.MustCompile(`^,?\s+(said|says|told|added|wrote|argued)\b`)
+
+	// saidBeforePattern matches a reporting verb right before a name
+	saidBeforePattern = regexp.MustCompile(`\b(said|says|according to)\s+$`)
+
+	// placeBeforePattern matches a preposition that introduces a place
+	placeBeforePattern = regexp.MustCompile(`\b(in|from|near|across)\s+$`)
+)
+
+// abbreviations end in a period without ending the sentence
+var abbreviations = wordSet("Mr", "Mrs", "Ms", "Dr", "St", "Sen", "Rep", "Gov", "Gen", "Lt", "Col", "Jr", "Sr", "Prof", "U.S", "U.K", "No", "vs", "Inc", "Corp", "Co")
+
+// titles precede a person's name
+var titles = wordSet("President", "Prime", "Minister", "Mr", "Mrs", "Ms", "Dr", "Prof", "Professor", "Senator", "Sen",
+	"Rep", "Representative", "Governor", "Gov", "Mayor", "Judge", "Justice", "Chancellor", "King", "Queen", "Prince",
+	"Princess", "Pope", "Secretary", "General", "Chief", "Executive", "CEO", "Ambassador", "Commissioner", "Coach")
+
+// organizationWords mark a name as an organization
+var organizationWords = wordSet("Inc", "Corp", "Corporation", "Company", "Co", "Ltd", "LLC", "Group", "Bank", "University",
+	"College", "Institute", "Foundation", "Association", "Ministry", "Department", "Agency", "Commission", "Council",
+	"Committee", "Party", "Parliament", "Congress", "Senate", "Court", "Police", "Army", "Navy", "Union", "Organization",
+	"Organisation", "Authority", "Board", "Federation", "Network", "News", "Times", "Post", "Journal", "Reserve")
+
+// placeAcronyms are all-caps names of places rather than organizations
+var placeAcronyms = wordSet("US", "USA", "UK", "UAE", "DRC")
+
+// stopWords are capitalized words that don't name an entity on their own
+var stopWords = wordSet("The", "A", "An", "This", "That", "These", "Those", "It", "Its", "He", "She", "They", "We", "I",
+	"His", "Her", "Their", "Our", "But", "And", "Or", "If", "When", "While", "After", "Before", "In", "On", "At", "For",
+	"From", "With", "As", "By", "To", "Of", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday",
+	"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November",
+	"December", "Today", "Yesterday", "Tomorrow", "Read", "More", "Photo", "Image", "Advertisement")
+
+// wordSet builds a lookup set from words
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// RuleExtractor finds facts with patterns: quotations, sentences with figures or
+// attributed statements, and capitalized names classified by the words around them
+type RuleExtractor struct{}
+
+// NewRuleExtractor creates a rule-based extractor
+func NewRuleExtractor() *RuleExtractor {
+	return &RuleExtractor{}
+}
+
+// Name identifies the extractor
+func (e *RuleExtractor) Name() string {
+	return "rules"
+}
+
+// Extract returns the quotes, statistics, claims and entities in the input
+func (e *RuleExtractor) Extract(ctx context.Context, input Input) ([]Fact, error) {
+	content := input.content()
+	sentences := splitSentences(content)
+
+	var facts []Fact
+	facts = append(facts, extractQuotes(content, sentences)...)
+
+	// A sentence is a statistic or a claim, not both
+	statistics, claims := 0, 0
+	for _, s := range sentences {
+		if len(s.text) > 400 || quotePattern.MatchString(s.text) {
+			continue
+		}
+		switch {
+		case statisticPattern.MatchString(s.text) && statistics < maxFactsPerType:
+			facts = append(facts, s.fact(TypeStatistic, 0.7))
+			statistics++
+		case attributionPattern.MatchString(s.text) && claims < maxFactsPerType:
+			facts = append(facts, s.fact(TypeClaim, 0.6))
+			claims++
+		}
+	}
+
+	facts = append(facts, extractEntities(sentences)...)
+	return facts, ctx.Err()
+}
+
+// sentence is a sentence of the content with its byte offset
+type sentence struct {
+	text  string
+	start int
+}
+
+// fact returns the whole sentence as a fact
+func (s sentence) fact(factType string, confidence float64) Fact {
+	return Fact{Type: factType, Text: s.text, Context: s.text, Confidence: confidence, Start: s.start, End: s.start + len(s.text)}
+}
+
+// splitSentences splits content at sentence boundaries, skipping the periods of
+// common abbreviations
+func splitSentences(content string) []sentence {
+	var sentences []sentence
+	add := func(start, end int) {
+		text := content[start:end]
+		trimmed := strings.TrimLeft(text, " \t\r\n")
+		start += len(text) - len(trimmed)
+		if trimmed = strings.TrimSpace(trimmed); trimmed != "" {
+			sentences = append(sentences, sentence{text: trimmed, start: start})
+		}
+	}
+
+	start := 0
+	for _, loc := range boundaryPattern.FindAllStringIndex(content, -1) {
+		words := strings.Fields(content[start:loc[0]])
+		if len(words) > 0 && content[loc[0]] == '.' && abbreviations[strings.TrimLeft(words[len(words)-1], "(\"“")] {
+			continue
+		}
+		add(start, loc[1])
+		start = loc[1]
+	}
+	add(start, len(content))
+	return sentences
+}
+
+// Sentences splits text into sentences the way the rule-based extractor does
+func Sentences(text string) []string {
+	split := splitSentences(text)
+	sentences := make([]string, len(split))
+	for i, s := range split {
+		sentences[i] = s.text
+	}
+	return sentences
+}
+
+// sentenceAt returns the sentence containing a byte offset
+func sentenceAt(sentences []sentence, offset int) string {
+	for _, s := range sentences {
+		if offset >= s.start && offset < s.start+len(s.text) {
+			return s.text
+		}
+	}
+	return ""
+}
+
+// extractQuotes returns quotations, more confident when they're attributed
+func extractQuotes(content string, sentences []sentence) []Fact {
+	var quotes []Fact
+	for _, loc := range quotePattern.FindAllStringSubmatchIndex(content, -1) {
+		if len(quotes) == maxFactsPerType {
+			break
+		}
+		context := sentenceAt(sentences, loc[0])
+		confidence := 0.6
+		if attributionPattern.MatchString(content[loc[1]:min(len(content), loc[1]+60)]) || attributionPattern.MatchString(context) {
+			confidence = 0.9
+		}
+		quotes = append(quotes, Fact{
+			Type:       TypeQuote,
+			Text:       strings.TrimSpace(content[loc[2]:loc[3]]),
+			Context:    context,
+			Confidence: confidence,
+			Start:      loc[2],
+			End:        loc[3],
+		})
+	}
+	return quotes
+}
+
+// entity accumulates the mentions of a name
+type entity struct {
+	fact     Fact
+	mentions int
+}
+
+// extractEntities returns the people, organizations and places named in the
+// sentences, most mentioned first
+func extractEntities(sentences []sentence) []Fact {
+	found := make(map[string]*entity)
+	var order []string
+	for _, s := range sentences {
+		for _, loc := range namePattern.FindAllStringIndex(s.text, -1) {
+			name, offset := s.text[loc[0]:loc[1]], loc[0]
+			before, after := s.text[:loc[0]], s.text[loc[1]:]
+
+			// Titles that start the match describe the person rather than name them
+			words := strings.Fields(name)
+			titled := titles[strings.TrimSuffix(lastWord(before), ".")]
+			for len(words) > 1 && (titles[words[0]] || words[0] == "The") {
+				titled = titled || titles[words[0]]
+				offset += strings.Index(s.text[offset:], words[1])
+				words = words[1:]
+			}
+			name = strings.Join(words, " ")
+			if stopWords[words[0]] && len(words) == 1 || loc[0] == 0 && len(words) == 1 && !isAcronym(name) {
+				continue
+			}
+
+			factType, confidence := classifyName(words, titled, before, after)
+			if factType == "" {
+				continue
+			}
+			key := factType + ":" + strings.ToLower(name)
+			if e, ok := found[key]; ok {
+				e.mentions++
+				continue
+			}
+			found[key] = &entity{
+				fact:     Fact{Type: factType, Text: name, Context: s.text, Confidence: confidence, Start: s.start + offset, End: s.start + offset + len(name)},
+				mentions: 1,
+			}
+			order = append(order, key)
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return found[order[i]].mentions > found[order[j]].mentions })
+	if len(order) > maxEntities {
+		order = order[:maxEntities]
+	}
+	entities := make([]Fact, len(order))
+	for i, key := range order {
+		e := found[key]
+		e.fact.Confidence = math.Min(0.95, e.fact.Confidence+0.05*float64(e.mentions-1))
+		entities[i] = e.fact
+	}
+	return entities
+}
+
+// classifyName decides whether a name is a person, organization or place from
+// its words and the text around it. It returns "" for names it can't classify.
+func classifyName(words []string, titled bool, before, after string) (string, float64) {
+	name := strings.Join(words, " ")
+	for _, word := range words {
+		if organizationWords[strings.TrimSuffix(word, ".")] {
+			return TypeOrganization, 0.8
+		}
+	}
+	if len(words) == 1 && isAcronym(name) {
+		if placeAcronyms[name] {
+			return TypePlace, 0.6
+		}
+		return TypeOrganization, 0.5
+	}
+
+	personLike := len(words) >= 2 && len(words) <= 3
+	switch {
+	case titled && len(words) <= 3:
+		return TypePerson, 0.8
+	case personLike && (speakerAfterPattern.MatchString(after) || saidBeforePattern.MatchString(before)):
+		return TypePerson, 0.7
+	case len(words) <= 3 && placeBeforePattern.MatchString(before):
+		return TypePlace, 0.5
+	}
+	return "", 0
+}
+
+// isAcronym reports whether a word is an all-caps abbreviation such as "NASA"
+func isAcronym(word string) bool {
+	if len(word) < 2 || len(word) > 6 {
+		return false
+	}
+	for _, r := range word {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// lastWord returns the last word of text
+func lastWord(text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
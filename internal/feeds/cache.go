@@ -0,0 +1,112 @@
+package feeds
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"open-news/internal/cache"
+)
+
+// defaultResponseCacheTTL is how long anonymous feed responses are reused
+const defaultResponseCacheTTL = 30 * time.Second
+
+// globalCachePrefix prefixes cached pages of the precomputed global feed
+const globalCachePrefix = "feeds:global:"
+
+var (
+	responseCacheOnce sync.Once
+
+	// responseCache holds encoded anonymous feed responses shared by every FeedService,
+	// and by every instance when Redis is configured
+	responseCache cache.Cache
+
+	// responseCacheTTL is read from FEED_CACHE_TTL_SECONDS; 0 disables caching
+	responseCacheTTL time.Duration
+)
+
+// responseStore returns the feed response cache and TTL, set up on first use so
+// settings loaded from .env at startup are picked up
+func responseStore() (cache.Cache, time.Duration) {
+	responseCacheOnce.Do(func() {
+		responseCache = cache.Shared()
+		responseCacheTTL = cacheTTLFromEnv()
+	})
+	return responseCache, responseCacheTTL
+}
+
+// cacheTTLFromEnv reads FEED_CACHE_TTL_SECONDS, falling back to the default
+func cacheTTLFromEnv() time.Duration {
+	value := os.Getenv("FEED_CACHE_TTL_SECONDS")
+	if value == "" {
+		return defaultResponseCacheTTL
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		log.Printf("Invalid FEED_CACHE_TTL_SECONDS %q, using %v", value, defaultResponseCacheTTL)
+		return defaultResponseCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// cachedResponse returns a decoded copy of a cached feed response, so callers
+// can attach per-user context without affecting other requests
+func cachedResponse(key string) (*FeedResponse, bool) {
+	store, _ := responseStore()
+	data, ok := store.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var response FeedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		log.Printf("Failed to decode cached feed %s: %v", key, err)
+		store.Delete(key)
+		return nil, false
+	}
+	return &response, true
+}
+
+// storeResponse caches an anonymous feed response
+func storeResponse(key string, response *FeedResponse) {
+	store, ttl := responseStore()
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Failed to encode feed %s for caching: %v", key, err)
+		return
+	}
+	store.Set(key, data, ttl)
+}
+
+// globalCacheKey identifies a page of the precomputed global feed
+func globalCacheKey(limit, offset int) string {
+	return fmt.Sprintf("%s%d:%d", globalCachePrefix, limit, offset)
+}
+
+// filteredCacheKey identifies a page of an anonymous filtered feed. The time window
+// is left out: it slides with every request, and entries only live for a few seconds.
+func filteredCacheKey(filter FeedFilter, limit, offset int) string {
+	ranker := ""
+	if filter.Ranker != nil {
+		ranker = filter.Ranker.Name()
+	}
+	return fmt.Sprintf("feeds:filtered:%s:%s:%s:%s:%s:%s:%s:%s:%d:%g:%s:%t:%d:%g:%s:%d:%d:%d:%d:%d", filter.FeedType, filter.Name, filter.Topic, filter.Language, filter.DomainCategory, filter.Country,
+		strings.Join(filter.Topics, ","), strings.Join(filter.Sites, ","), filter.MinShares, filter.MinQualityScore, ranker, filter.SafeMode,
+		filter.MinWordCount, filter.MaxReadingGrade, filter.Sentiment, filter.Diversity.MaxPerSource, filter.Diversity.MaxPerDomain, filter.Diversity.MaxPerCluster, limit, offset)
+}
+
+// InvalidateGlobalFeed drops cached pages of the global feed. With Redis this
+// reaches every instance; otherwise other processes sharing the database pick up
+// a regenerated feed once their entries expire.
+func InvalidateGlobalFeed() {
+	store, _ := responseStore()
+	store.DeletePrefix(globalCachePrefix)
+}
@@ -0,0 +1,58 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache_ReturnsIndependentCopies(t *testing.T) {
+	item := FeedItemDetails{
+		Article: Article{ID: uuid.New(), URL: "https://example.com/story", Title: "Story"},
+		Source:  Source{ID: uuid.New(), Handle: "reporter.bsky.social"},
+	}
+	item.Position = 1
+	item.AddedAt = time.Now().UTC().Truncate(time.Second)
+
+	key := globalCacheKey(30, 0)
+	storeResponse(key, &FeedResponse{Items: []FeedItemDetails{item}, Meta: FeedMeta{TotalItems: 1}})
+	defer InvalidateGlobalFeed()
+
+	first, ok := cachedResponse(key)
+	require.True(t, ok)
+	require.Len(t, first.Items, 1)
+	assert.Equal(t, item.Article, first.Items[0].Article)
+	assert.Equal(t, item.Source, first.Items[0].Source)
+	assert.Equal(t, 1, first.Items[0].Position)
+	assert.True(t, item.AddedAt.Equal(first.Items[0].AddedAt))
+
+	// Share context attached for one user must not leak into the next request
+	first.Items[0].Reason = "Shared by @friend"
+	second, ok := cachedResponse(key)
+	require.True(t, ok)
+	assert.Empty(t, second.Items[0].Reason)
+
+	InvalidateGlobalFeed()
+	_, ok = cachedResponse(key)
+	assert.False(t, ok)
+}
+
+func TestFilteredCacheKey(t *testing.T) {
+	tech := FeedFilter{FeedType: BuilderGlobal, Name: "Open News - Technology", Topic: "tech"}
+	science := FeedFilter{FeedType: BuilderGlobal, Name: "Open News - Science", Topic: "science"}
+
+	assert.NotEqual(t, filteredCacheKey(tech, 30, 0), filteredCacheKey(science, 30, 0))
+	assert.NotEqual(t, filteredCacheKey(tech, 30, 0), filteredCacheKey(tech, 30, 30))
+
+	// The sliding time window doesn't split the cache
+	later := tech
+	later.Since = time.Now()
+	assert.Equal(t, filteredCacheKey(tech, 30, 0), filteredCacheKey(later, 30, 0))
+
+	longReads := tech
+	longReads.MinWordCount = LongReadWords
+	assert.NotEqual(t, filteredCacheKey(tech, 30, 0), filteredCacheKey(longReads, 30, 0))
+}
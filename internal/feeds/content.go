@@ -0,0 +1,88 @@
+package feeds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Article tones a ContentFilter can select
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+const (
+	// LongReadWords is the word count of a long read
+	LongReadWords = 1500
+
+	// sentimentThreshold is the score beyond which an article counts as positive or negative
+	sentimentThreshold = 0.2
+)
+
+// ContentFilter narrows a feed by the length, reading level and tone of its articles
+type ContentFilter struct {
+	MinWordCount    int     // Only articles with at least this many words
+	MaxReadingGrade float64 // Only articles at or below this Flesch-Kincaid grade; 0 for any
+	Sentiment       string  // SentimentPositive, SentimentNeutral or SentimentNegative; empty for any
+}
+
+// IsZero reports whether the filter lets every article through
+func (f ContentFilter) IsZero() bool {
+	return f == ContentFilter{}
+}
+
+// Validate checks the filter's values
+func (f ContentFilter) Validate() error {
+	if f.MinWordCount < 0 {
+		return fmt.Errorf("min_words must not be negative")
+	}
+	if f.MaxReadingGrade < 0 {
+		return fmt.Errorf("max_grade must not be negative")
+	}
+	switch f.Sentiment {
+	case "", SentimentPositive, SentimentNeutral, SentimentNegative:
+		return nil
+	default:
+		return fmt.Errorf("sentiment must be positive, neutral or negative")
+	}
+}
+
+// apply adds the filter's conditions to an articles query
+func (f ContentFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.MinWordCount > 0 {
+		query = query.Where("articles.word_count >= ?", f.MinWordCount)
+	}
+	if f.MaxReadingGrade > 0 {
+		query = query.Where("articles.reading_grade > 0 AND articles.reading_grade <= ?", f.MaxReadingGrade)
+	}
+	switch f.Sentiment {
+	case SentimentPositive:
+		query = query.Where("articles.sentiment >= ?", sentimentThreshold)
+	case SentimentNegative:
+		query = query.Where("articles.sentiment <= ?", -sentimentThreshold)
+	case SentimentNeutral:
+		query = query.Where("articles.sentiment > ? AND articles.sentiment < ?", -sentimentThreshold, sentimentThreshold)
+	}
+	return query
+}
+
+// GetContentFilteredFeed ranks the articles of the last week that pass a content
+// filter, from every source or, with a user, from the user's follows
+func (fs *FeedService) GetContentFilteredFeed(content ContentFilter, userID *uuid.UUID, limit, offset int) (*FeedResponse, error) {
+	filter := FeedFilter{
+		Name:          "Top Stories",
+		FeedType:      BuilderGlobal,
+		Since:         time.Now().Add(-defaultTimeWindow),
+		ContentFilter: content,
+	}
+	if userID != nil {
+		filter.Name = "Personal Feed"
+		filter.FeedType = BuilderPersonalized
+		filter.UserID = userID
+	}
+	return fs.GetFilteredFeed(filter, limit, offset)
+}
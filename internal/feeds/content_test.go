@@ -0,0 +1,17 @@
+package feeds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentFilter_Validate(t *testing.T) {
+	assert.True(t, ContentFilter{}.IsZero())
+	assert.NoError(t, ContentFilter{}.Validate())
+	assert.NoError(t, ContentFilter{MinWordCount: LongReadWords, MaxReadingGrade: 10, Sentiment: SentimentPositive}.Validate())
+
+	assert.Error(t, ContentFilter{MinWordCount: -1}.Validate())
+	assert.Error(t, ContentFilter{MaxReadingGrade: -2}.Validate())
+	assert.Error(t, ContentFilter{Sentiment: "angry"}.Validate())
+}
@@ -0,0 +1,177 @@
+package feeds
+
+import (
+	"strings"
+	"unicode"
+
+	"open-news/internal/domains"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// DiversityPageSize is the page of feed items diversity limits apply to. It
+// matches the default page size of the feed APIs.
+const DiversityPageSize = 20
+
+const (
+	// diversityCandidateFactor is how many more candidates than items a
+	// diversified feed considers, so capped outlets and stories can be replaced
+	diversityCandidateFactor = 3
+
+	// minClusterOverlap is the share of the shorter title's words two articles
+	// must have in common to cover the same story
+	minClusterOverlap = 0.6
+
+	// minClusterWords is the fewest title words two articles must share to
+	// cover the same story
+	minClusterWords = 3
+)
+
+// Diversity limits how many items on one page of a feed can come from one
+// source, one site or one story. Zero limits are unlimited.
+type Diversity struct {
+	MaxPerSource  int // Items attributed to one source
+	MaxPerDomain  int // Items from one site
+	MaxPerCluster int // Items covering one story, judged by headline overlap
+}
+
+// IsZero reports whether the diversity limits are all unlimited
+func (d Diversity) IsZero() bool {
+	return d.MaxPerSource <= 0 && d.MaxPerDomain <= 0 && d.MaxPerCluster <= 0
+}
+
+// feedDiversity returns the diversity limits of a precomputed feed
+func feedDiversity(feed models.Feed) Diversity {
+	return Diversity{MaxPerSource: feed.MaxPerSource, MaxPerDomain: feed.MaxPerDomain, MaxPerCluster: feed.MaxPerCluster}
+}
+
+// diversityFor returns the diversity limits of a feed definition
+func diversityFor(def *models.FeedDefinition) Diversity {
+	return Diversity{MaxPerSource: def.MaxPerSource, MaxPerDomain: def.MaxPerDomain, MaxPerCluster: def.MaxPerCluster}
+}
+
+// diversify returns the order to show articles in, best first, so that no page
+// of DiversityPageSize items breaks the limits. Articles over a limit move down
+// to the next page with room for them and none are dropped; a page that can't
+// be filled within the limits ends with the best of them. The first fixed articles,
+// such as pinned ones, keep their places but count towards the limits.
+func diversify(articles []models.Article, d Diversity, fixed int) []int {
+	order := make([]int, 0, len(articles))
+	remaining := make([]int, len(articles))
+	for i := range articles {
+		remaining[i] = i
+	}
+	if d.IsZero() {
+		return remaining
+	}
+
+	sources := make([]uuid.UUID, len(articles))
+	hosts := make([]string, len(articles))
+	for i, article := range articles {
+		if len(article.SourceArticles) > 0 {
+			sources[i] = article.SourceArticles[0].SourceID
+		}
+		hosts[i] = domains.Normalize(article.URL)
+	}
+	clusters := storyClusters(articles)
+
+	for len(remaining) > 0 {
+		perSource := make(map[uuid.UUID]int)
+		perDomain := make(map[string]int)
+		perCluster := make(map[int]int)
+		allowed := func(i int) bool {
+			return i < fixed ||
+				((d.MaxPerSource <= 0 || sources[i] == uuid.Nil || perSource[sources[i]] < d.MaxPerSource) &&
+					(d.MaxPerDomain <= 0 || hosts[i] == "" || perDomain[hosts[i]] < d.MaxPerDomain) &&
+					(d.MaxPerCluster <= 0 || perCluster[clusters[i]] < d.MaxPerCluster))
+		}
+
+		page := make([]int, 0, DiversityPageSize)
+		deferred := make([]int, 0, len(remaining))
+		for _, i := range remaining {
+			if len(page) == DiversityPageSize || !allowed(i) {
+				deferred = append(deferred, i)
+				continue
+			}
+			page = append(page, i)
+			perSource[sources[i]]++
+			perDomain[hosts[i]]++
+			perCluster[clusters[i]]++
+		}
+		// Fill a short page in rank order rather than shifting later pages
+		for len(page) < DiversityPageSize && len(deferred) > 0 {
+			page = append(page, deferred[0])
+			deferred = deferred[1:]
+		}
+
+		order = append(order, page...)
+		remaining = deferred
+	}
+	return order
+}
+
+// storyClusters groups articles whose headlines share most of their words and
+// returns each article's cluster number
+func storyClusters(articles []models.Article) []int {
+	words := make([]map[string]bool, len(articles))
+	for i, article := range articles {
+		words[i] = headlineWords(article.Title)
+	}
+
+	clusters := make([]int, len(articles))
+	for i := range clusters {
+		clusters[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if clusters[i] != i {
+			clusters[i] = find(clusters[i])
+		}
+		return clusters[i]
+	}
+
+	for i := range articles {
+		for j := i + 1; j < len(articles); j++ {
+			if sameStory(words[i], words[j]) {
+				clusters[find(j)] = find(i)
+			}
+		}
+	}
+	for i := range clusters {
+		clusters[i] = find(i)
+	}
+	return clusters
+}
+
+// sameStory reports whether two headlines share enough words to cover one story
+func sameStory(a, b map[string]bool) bool {
+	shorter := len(a)
+	if len(b) < shorter {
+		shorter = len(b)
+	}
+	if shorter == 0 {
+		return false
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return shared >= minClusterWords && float64(shared)/float64(shorter) >= minClusterOverlap
+}
+
+// headlineWords returns the distinct lowercased words of a headline, leaving
+// out words under four letters
+func headlineWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 4 {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,68 @@
+package feeds
+
+import (
+	"fmt"
+	"testing"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// articleFrom returns an article on a site with a distinct headline
+func articleFrom(site string, n int) models.Article {
+	return models.Article{URL: fmt.Sprintf("https://www.%s/story-%d", site, n), Title: fmt.Sprintf("Headline number %d", n)}
+}
+
+func TestDiversify_MaxPerDomain(t *testing.T) {
+	var articles []models.Article
+	for i := 0; i < 5; i++ {
+		articles = append(articles, articleFrom("bignews.com", i))
+	}
+	for i := 5; i < DiversityPageSize+5; i++ {
+		articles = append(articles, articleFrom(fmt.Sprintf("site%d.com", i), i))
+	}
+
+	order := diversify(articles, Diversity{MaxPerDomain: 2}, 0)
+	assert.Len(t, order, len(articles), "no article is dropped")
+	assert.Equal(t, []int{0, 1, 5, 6}, order[:4], "the third bignews.com story moves down")
+
+	firstPage := 0
+	for _, i := range order[:DiversityPageSize] {
+		if i < 5 {
+			firstPage++
+		}
+	}
+	assert.Equal(t, 2, firstPage)
+	assert.Equal(t, 2, order[DiversityPageSize], "deferred stories lead the next page")
+}
+
+func TestDiversify_Fixed(t *testing.T) {
+	articles := []models.Article{articleFrom("a.com", 0), articleFrom("a.com", 1), articleFrom("a.com", 2), articleFrom("b.com", 3)}
+	assert.Equal(t, []int{0, 1, 3, 2}, diversify(articles, Diversity{MaxPerDomain: 1}, 2), "pinned articles keep their places")
+	assert.Equal(t, []int{0, 1, 2, 3}, diversify(articles, Diversity{}, 0))
+}
+
+func TestDiversify_ShortPage(t *testing.T) {
+	articles := []models.Article{articleFrom("a.com", 0), articleFrom("a.com", 1), articleFrom("a.com", 2)}
+	assert.Equal(t, []int{0, 1, 2}, diversify(articles, Diversity{MaxPerDomain: 1}, 0),
+		"a page that can't meet the limits is filled in rank order")
+}
+
+func TestStoryClusters(t *testing.T) {
+	clusters := storyClusters([]models.Article{
+		{Title: "Central bank raises interest rates again"},
+		{Title: "Interest rates raised again by central bank"},
+		{Title: "Local team wins the championship final"},
+	})
+	assert.Equal(t, clusters[0], clusters[1])
+	assert.NotEqual(t, clusters[0], clusters[2])
+
+	articles := []models.Article{
+		{URL: "https://a.com/1", Title: "Central bank raises interest rates again"},
+		{URL: "https://b.com/2", Title: "Interest rates raised again by central bank"},
+		{URL: "https://c.com/3", Title: "Central bank interest rates decision explained"},
+		{URL: "https://d.com/4", Title: "Local team wins the championship final"},
+	}
+	assert.Equal(t, []int{0, 3, 1, 2}, diversify(articles, Diversity{MaxPerCluster: 1}, 0))
+}
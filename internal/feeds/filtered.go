@@ -0,0 +1,205 @@
+package feeds
+
+import (
+	"strings"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/domains"
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+
+	"github.com/google/uuid"
+)
+
+// FeedFilter narrows the article set for feeds built on the fly
+type FeedFilter struct {
+	Name            string           // Feed name reported in the response
+	FeedType        string           // Builder the filter came from
+	Topic           string           // Topic tag articles must carry
+	Language        string           // Language prefix, e.g. "en" matches "en-US"
+	DomainCategory  string           // Category of the article's domain in the domains table
+	Country         string           // Country of the article's domain in the domains table
+	Topics          []string         // Topic tags articles must carry at least one of
+	Sites           []string         // Sites articles must come from, e.g. "reuters.com"; subdomains match too
+	MinShares       int              // Minimum number of shares
+	Since           time.Time        // Only articles created after this time
+	MinQualityScore float64          // Minimum article quality score
+	UserID          *uuid.UUID       // Restrict to articles shared by this user's follows or on topics they follow
+	Ranker          ranking.Ranker   // Reorders candidates instead of the stored scores; nil uses the stored scores
+	Viewer          *uuid.UUID       // Requesting user, for rankers that use the follow graph
+	Preferences     *UserPreferences // UserID's topics, languages and topic weights; loaded when nil
+	SafeMode        bool             // Leave out articles shared in posts with a sensitive label
+	Diversity       Diversity        // Limits on items per source, site and story on each page
+	ContentFilter                    // Length, reading level and tone of the articles
+}
+
+// GetFilteredFeed ranks matching articles directly instead of reading precomputed feed items.
+// Feeds that don't depend on the requesting user are cached for FEED_CACHE_TTL_SECONDS.
+func (fs *FeedService) GetFilteredFeed(filter FeedFilter, limit, offset int) (*FeedResponse, error) {
+	cacheKey := ""
+	if filter.UserID == nil && filter.Viewer == nil {
+		cacheKey = filteredCacheKey(filter, limit, offset)
+		if cached, ok := cachedResponse(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	if filter.UserID != nil && filter.Preferences == nil {
+		preferences, err := fs.UserPreferences(*filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+		filter.Preferences = &preferences
+	}
+
+	query := fs.db.Model(&models.Article{}).
+		Where("articles.created_at > ? AND articles.quality_score > ?", filter.Since, filter.MinQualityScore).
+		Where("articles.is_not_news = ? AND articles.is_unshared = ?", false, false).
+		Scopes(domains.NotBlocked)
+
+	if filter.Topic != "" {
+		query = query.Where(database.ArrayContains(fs.db, "articles.tags"), filter.Topic)
+	}
+	if len(filter.Topics) > 0 {
+		conditions := make([]string, len(filter.Topics))
+		args := make([]interface{}, len(filter.Topics))
+		for i, topic := range filter.Topics {
+			conditions[i], args[i] = database.ArrayContains(fs.db, "articles.tags"), topic
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	if len(filter.Sites) > 0 {
+		query = query.Scopes(domains.OnSites(filter.Sites))
+	}
+	if filter.MinShares > 0 {
+		query = query.Where("articles.shares_count >= ?", filter.MinShares)
+	}
+	if filter.Language != "" {
+		query = query.Where("LOWER(articles.language) LIKE ?", strings.ToLower(filter.Language)+"%")
+	}
+	if filter.DomainCategory != "" || filter.Country != "" {
+		query = query.Scopes(domains.Matching(filter.DomainCategory, filter.Country))
+	}
+	query = filter.ContentFilter.apply(query)
+	if filter.SafeMode {
+		query = query.Where(`NOT EXISTS (
+			SELECT 1 FROM source_articles
+			WHERE source_articles.article_id = articles.id AND source_articles.is_sensitive)`)
+	}
+	if filter.UserID != nil {
+		// Articles a followed source shared or tagged with a followed topic
+		conditions := []string{`EXISTS (
+			SELECT 1 FROM source_articles
+			JOIN user_sources ON user_sources.source_id = source_articles.source_id
+			WHERE source_articles.article_id = articles.id AND user_sources.user_id = ?)`}
+		args := []interface{}{*filter.UserID}
+		for _, topic := range filter.Preferences.Topics {
+			conditions = append(conditions, database.ArrayContains(fs.db, "articles.tags"))
+			args = append(args, topic)
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		query = filter.Preferences.apply(query)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, err
+	}
+
+	// Personal feeds favor the topics the user follows or has shown interest in
+	if filter.Preferences != nil && len(filter.Preferences.TopicAffinity) > 0 {
+		filter.Ranker = ranking.Personalized(filter.Ranker)
+	}
+
+	var ranked []rankedArticle
+	if filter.Ranker != nil || !filter.Diversity.IsZero() {
+		var err error
+		if ranked, err = fs.rankArticles(query, filter); err != nil {
+			return nil, err
+		}
+		ranked = pageOf(ranked, limit, offset)
+		if totalCount > rankCandidatePool {
+			totalCount = rankCandidatePool
+		}
+	} else {
+		var articles []models.Article
+		err := query.Preload("SourceArticles.Source").
+			Order("articles.quality_score DESC, articles.trending_score DESC, articles.created_at DESC").
+			Limit(limit).
+			Offset(offset).
+			Find(&articles).Error
+		if err != nil {
+			return nil, err
+		}
+		ranked = make([]rankedArticle, len(articles))
+		for i, article := range articles {
+			ranked[i] = rankedArticle{Article: article, Score: article.QualityScore + (article.TrendingScore * 0.3)}
+		}
+	}
+
+	now := time.Now()
+	items := make([]FeedItemDetails, len(ranked))
+	for i, candidate := range ranked {
+		article := candidate.Article
+		var source Source
+		if len(article.SourceArticles) > 0 {
+			source = toFeedSource(article.SourceArticles[0].Source)
+		}
+
+		items[i] = FeedItemDetails{
+			FeedItem: models.FeedItem{
+				ArticleID: article.ID,
+				UserID:    filter.UserID,
+				Position:  offset + i + 1,
+				Score:     candidate.Score,
+				Relevance: article.QualityScore,
+				AddedAt:   article.CreatedAt,
+			},
+			Article: Article{
+				ID:           article.ID,
+				URL:          article.URL,
+				Title:        article.Title,
+				Description:  article.Blurb(),
+				ImageURL:     article.ImageURL,
+				PublishedAt:  article.PublishedAt,
+				SiteName:     article.SiteName,
+				Authors:      article.Authors,
+				QualityScore: article.QualityScore,
+			},
+			Source: source,
+		}
+	}
+
+	if filter.UserID != nil {
+		if err := fs.AttachShareContext(*filter.UserID, items); err != nil {
+			return nil, err
+		}
+	}
+
+	perPage := limit
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	response := &FeedResponse{
+		Feed: models.Feed{
+			Name:     filter.Name,
+			FeedType: filter.FeedType,
+			IsActive: true,
+			MaxItems: limit,
+		},
+		Items: items,
+		Meta: FeedMeta{
+			TotalItems:    int(totalCount),
+			Page:          offset/perPage + 1,
+			PerPage:       limit,
+			LastUpdatedAt: now,
+		},
+	}
+	if cacheKey != "" {
+		storeResponse(cacheKey, response)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,45 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFilteredFeedPersonal(t *testing.T) {
+	db := setupTestDB(t)
+
+	user := models.User{BlueSkyDID: "did:plc:reader", Handle: "reader.test"}
+	require.NoError(t, db.Create(&user).Error)
+	followed := models.Source{BlueSkyDID: "did:plc:followed", Handle: "followed.test"}
+	require.NoError(t, db.Create(&followed).Error)
+	require.NoError(t, db.Create(&models.UserSource{UserID: user.ID, SourceID: followed.ID}).Error)
+
+	shared := models.Article{URL: "https://example.com/shared", Title: "Shared", QualityScore: 0.8}
+	tagged := models.Article{URL: "https://example.com/climate", Title: "Tagged", QualityScore: 0.7, Tags: pq.StringArray{"climate", "science"}}
+	other := models.Article{URL: "https://example.com/other", Title: "Other", QualityScore: 0.9, Tags: pq.StringArray{"sports"}}
+	for _, article := range []*models.Article{&shared, &tagged, &other} {
+		require.NoError(t, db.Create(article).Error)
+	}
+	require.NoError(t, db.Create(&models.SourceArticle{SourceID: followed.ID, ArticleID: shared.ID,
+		PostURI: "at://did:plc:followed/app.bsky.feed.post/1", PostedAt: time.Now()}).Error)
+
+	response, err := NewFeedService(db).GetFilteredFeed(FeedFilter{
+		Since:       time.Now().Add(-time.Hour),
+		UserID:      &user.ID,
+		Preferences: &UserPreferences{Topics: []string{"climate"}},
+	}, 10, 0)
+	require.NoError(t, err)
+
+	var ids []uuid.UUID
+	for _, item := range response.Items {
+		ids = append(ids, item.Article.ID)
+	}
+	assert.Equal(t, []uuid.UUID{shared.ID, tagged.ID}, ids, "articles a followed source shared or on a followed topic")
+}
@@ -0,0 +1,45 @@
+package feeds
+
+import (
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetFollowedTopStories returns the global top stories shared by sources the user follows.
+// It backs personalized feeds when the user has no personalized feed items.
+func (fs *FeedService) GetFollowedTopStories(userID uuid.UUID, limit int) (*FeedResponse, error) {
+	// Get the global feed for metadata
+	var globalFeed models.Feed
+	if err := fs.db.Where("feed_type = ? AND name = ?", "global", "Top Stories").First(&globalFeed).Error; err != nil {
+		return nil, err
+	}
+
+	// Get global feed items shared by at least one of the user's sources
+	items, err := loadFeedItems(fs.feedItemQuery().
+		Where("feed_items.feed_id = ?", globalFeed.ID).
+		Where(`EXISTS (
+			SELECT 1 FROM source_articles
+			JOIN user_sources ON user_sources.source_id = source_articles.source_id
+			WHERE source_articles.article_id = feed_items.article_id AND user_sources.user_id = ?)`, userID).
+		Order("feed_items.position ASC").
+		Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.AttachShareContext(userID, items); err != nil {
+		return nil, err
+	}
+
+	return &FeedResponse{
+		Feed:  globalFeed,
+		Items: items,
+		Meta: FeedMeta{
+			TotalItems:    len(items),
+			Page:          1,
+			PerPage:       limit,
+			LastUpdatedAt: globalFeed.UpdatedAt,
+		},
+	}, nil
+}
@@ -0,0 +1,47 @@
+package feeds
+
+import (
+	"time"
+
+	"open-news/internal/models"
+)
+
+// feedMaxAge returns how long after their creation articles stay in a
+// precomputed feed
+func feedMaxAge(feed models.Feed) time.Duration {
+	if feed.MaxAgeHours > 0 {
+		return time.Duration(feed.MaxAgeHours) * time.Hour
+	}
+	return defaultTimeWindow
+}
+
+// pruneSuperseded leaves out articles on a story a newer article covers, so a
+// story is shown by its latest coverage. The first fixed articles, such as
+// pinned ones, are always kept but can supersede the others.
+func pruneSuperseded(articles []models.Article, fixed int) []models.Article {
+	clusters := storyClusters(articles)
+	newest := make(map[int]int, len(articles))
+	for i, article := range articles {
+		j, ok := newest[clusters[i]]
+		if !ok || storyTime(article).After(storyTime(articles[j])) {
+			newest[clusters[i]] = i
+		}
+	}
+
+	kept := make([]models.Article, 0, len(articles))
+	for i, article := range articles {
+		if i < fixed || newest[clusters[i]] == i {
+			kept = append(kept, article)
+		}
+	}
+	return kept
+}
+
+// storyTime is when an article was published, or stored when its page gives
+// no date
+func storyTime(article models.Article) time.Time {
+	if article.PublishedAt != nil {
+		return *article.PublishedAt
+	}
+	return article.CreatedAt
+}
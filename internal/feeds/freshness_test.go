@@ -0,0 +1,44 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedMaxAge(t *testing.T) {
+	assert.Equal(t, defaultTimeWindow, feedMaxAge(models.Feed{}))
+	assert.Equal(t, 18*time.Hour, feedMaxAge(models.Feed{MaxAgeHours: 18}))
+}
+
+func TestPruneSuperseded(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-6 * time.Hour)
+	article := func(title string, createdAt time.Time) models.Article {
+		return models.Article{Title: title, CreatedAt: createdAt}
+	}
+	articles := []models.Article{
+		article("Parliament passes sweeping climate bill after marathon debate", now.Add(-8*time.Hour)), // pinned
+		article("Storm floods coastal towns overnight", now.Add(-5*time.Hour)),
+		article("Parliament climate bill passes marathon debate", now.Add(-7*time.Hour)),
+		article("Storm floods coastal towns, thousands evacuated", now.Add(-time.Hour)),
+		article("Central bank holds interest rates steady", now.Add(-3*time.Hour)),
+	}
+	articles[1].PublishedAt = &earlier
+
+	kept := pruneSuperseded(articles, 1)
+
+	titles := make([]string, len(kept))
+	for i, a := range kept {
+		titles[i] = a.Title
+	}
+	assert.Equal(t, []string{
+		"Parliament passes sweeping climate bill after marathon debate",
+		"Parliament climate bill passes marathon debate",
+		"Storm floods coastal towns, thousands evacuated",
+		"Central bank holds interest rates steady",
+	}, titles, "the older storm article is superseded; the pinned article is kept though newer coverage exists")
+}
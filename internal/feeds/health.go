@@ -0,0 +1,125 @@
+package feeds
+
+import (
+	"fmt"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// staleRefreshes is how many refresh intervals a feed can go without being
+	// regenerated before it's reported stale
+	staleRefreshes = 3
+
+	// scoreBucketWidth and scoreBuckets shape the score histogram: 0 to 2 in
+	// steps of 0.2, with scores past the last bucket counted in it
+	scoreBucketWidth = 0.2
+	scoreBuckets     = 10
+)
+
+// FeedHealth summarizes a stored feed for operators: when it was last
+// regenerated, what its items look like and whether regenerating it fails
+type FeedHealth struct {
+	Feed            models.Feed   `json:"feed"`
+	Stale           bool          `json:"stale"`   // Not regenerated for three refresh intervals, or ever
+	Failing         bool          `json:"failing"` // The last regeneration failed
+	Items           int           `json:"items"`
+	WithImage       float64       `json:"with_image"`        // Percentage of items with an image
+	WithPublishedAt float64       `json:"with_published_at"` // Percentage of items with a publication date
+	Scores          []ScoreBucket `json:"scores"`            // Histogram of item scores
+}
+
+// MaxBucket returns the count of the fullest score bucket
+func (h FeedHealth) MaxBucket() int {
+	fullest := 0
+	for _, bucket := range h.Scores {
+		if bucket.Count > fullest {
+			fullest = bucket.Count
+		}
+	}
+	return fullest
+}
+
+// ScoreBucket counts the feed items scored from Min up to Max
+type ScoreBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// Label names the bucket's range
+func (b ScoreBucket) Label() string {
+	return fmt.Sprintf("%.1f–%.1f", b.Min, b.Max)
+}
+
+// healthItem is what the health report reads of a feed item
+type healthItem struct {
+	FeedID         uuid.UUID
+	Score          float64
+	HasImage       bool
+	HasPublishedAt bool
+}
+
+// Health reports on every stored feed, global feeds first
+func (fs *FeedService) Health(now time.Time) ([]FeedHealth, error) {
+	var feeds []models.Feed
+	if err := fs.db.Order("feed_type ASC, name ASC").Find(&feeds).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	var items []healthItem
+	err := fs.db.Table("feed_items").
+		Select("feed_items.feed_id, feed_items.score, COALESCE(articles.image_url, '') <> '' AS has_image, articles.published_at IS NOT NULL AS has_published_at").
+		Joins("JOIN articles ON articles.id = feed_items.article_id").
+		Scan(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed items: %w", err)
+	}
+	byFeed := make(map[uuid.UUID][]healthItem, len(feeds))
+	for _, item := range items {
+		byFeed[item.FeedID] = append(byFeed[item.FeedID], item)
+	}
+
+	report := make([]FeedHealth, len(feeds))
+	for i, feed := range feeds {
+		report[i] = newFeedHealth(feed, byFeed[feed.ID], now)
+	}
+	return report, nil
+}
+
+// newFeedHealth summarizes a feed and its items
+func newFeedHealth(feed models.Feed, items []healthItem, now time.Time) FeedHealth {
+	health := FeedHealth{Feed: feed, Items: len(items), Scores: make([]ScoreBucket, scoreBuckets)}
+
+	refresh := time.Duration(feed.RefreshRate) * time.Second
+	health.Stale = feed.RegeneratedAt == nil || now.Sub(*feed.RegeneratedAt) > staleRefreshes*refresh
+	health.Failing = feed.LastErrorAt != nil && (feed.RegeneratedAt == nil || feed.LastErrorAt.After(*feed.RegeneratedAt))
+
+	for i := range health.Scores {
+		health.Scores[i] = ScoreBucket{Min: float64(i) * scoreBucketWidth, Max: float64(i+1) * scoreBucketWidth}
+	}
+	withImage, withPublishedAt := 0, 0
+	for _, item := range items {
+		bucket := int(item.Score / scoreBucketWidth)
+		if bucket < 0 {
+			bucket = 0
+		} else if bucket >= scoreBuckets {
+			bucket = scoreBuckets - 1
+		}
+		health.Scores[bucket].Count++
+		if item.HasImage {
+			withImage++
+		}
+		if item.HasPublishedAt {
+			withPublishedAt++
+		}
+	}
+	if len(items) > 0 {
+		health.WithImage = 100 * float64(withImage) / float64(len(items))
+		health.WithPublishedAt = 100 * float64(withPublishedAt) / float64(len(items))
+	}
+	return health
+}
@@ -0,0 +1,43 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFeedHealth(t *testing.T) {
+	now := time.Now()
+	regenerated := now.Add(-10 * time.Minute)
+	feed := models.Feed{Name: "Top Stories", FeedType: "global", RefreshRate: 300, RegeneratedAt: &regenerated}
+	items := []healthItem{
+		{Score: 0.05, HasImage: true, HasPublishedAt: true},
+		{Score: 0.95, HasImage: true},
+		{Score: 0.9},
+		{Score: 3.2, HasPublishedAt: true},
+	}
+
+	health := newFeedHealth(feed, items, now)
+	assert.Equal(t, 4, health.Items)
+	assert.InDelta(t, 50, health.WithImage, 1e-9)
+	assert.InDelta(t, 50, health.WithPublishedAt, 1e-9)
+	assert.Equal(t, 1, health.Scores[0].Count)
+	assert.Equal(t, 2, health.Scores[4].Count)
+	assert.Equal(t, 1, health.Scores[scoreBuckets-1].Count, "scores past the last bucket are counted in it")
+	assert.Equal(t, 2, health.MaxBucket())
+	assert.False(t, health.Stale, "regenerated within three refresh intervals")
+	assert.False(t, health.Failing)
+
+	failed := now.Add(-time.Minute)
+	feed.LastError, feed.LastErrorAt = "connection refused", &failed
+	health = newFeedHealth(feed, items, now.Add(time.Hour))
+	assert.True(t, health.Stale)
+	assert.True(t, health.Failing, "the last regeneration failed")
+
+	health = newFeedHealth(models.Feed{RefreshRate: 300}, nil, now)
+	assert.True(t, health.Stale, "never regenerated")
+	assert.Zero(t, health.WithImage)
+}
@@ -0,0 +1,117 @@
+package feeds
+
+import (
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// feedItemColumns selects a feed item with the article fields feeds display and the
+// article's primary source, so a page of items loads in one query instead of a
+// Preload per relationship. Summarized articles show their summary as the description.
+const feedItemColumns = `feed_items.*,
+	articles.url AS article_url,
+	articles.title AS article_title,
+	COALESCE(NULLIF(articles.summary, ''), articles.description) AS article_description,
+	articles.image_url AS article_image_url,
+	articles.published_at AS article_published_at,
+	articles.site_name AS article_site_name,
+	articles.authors AS article_authors,
+	articles.quality_score AS article_quality_score,
+	primary_source.id AS source_id,
+	primary_source.handle AS source_handle,
+	primary_source.display_name AS source_display_name,
+	primary_source.avatar AS source_avatar,
+	primary_source.quality_score AS source_quality_score`
+
+// primarySourceJoin picks the first source that shared each article. It joins
+// on a correlated subquery rather than LATERAL, which SQLite doesn't support.
+const primarySourceJoin = `LEFT JOIN sources AS primary_source ON primary_source.id = (
+	SELECT source_articles.source_id
+	FROM source_articles
+	WHERE source_articles.article_id = articles.id
+	ORDER BY source_articles.created_at ASC
+	LIMIT 1
+)`
+
+// feedItemRow is a row selected with feedItemColumns
+type feedItemRow struct {
+	models.FeedItem
+
+	ArticleURL          string
+	ArticleTitle        string
+	ArticleDescription  string
+	ArticleImageURL     string
+	ArticlePublishedAt  *time.Time
+	ArticleSiteName     string
+	ArticleAuthors      pq.StringArray
+	ArticleQualityScore float64
+
+	SourceID           *uuid.UUID
+	SourceHandle       *string
+	SourceDisplayName  *string
+	SourceAvatar       *string
+	SourceQualityScore *float64
+}
+
+// feedItemQuery starts a query over feed items joined to their articles and primary sources
+func (fs *FeedService) feedItemQuery() *gorm.DB {
+	return fs.db.Table("feed_items").
+		Select(feedItemColumns).
+		Joins("JOIN articles ON articles.id = feed_items.article_id").
+		Joins(primarySourceJoin)
+}
+
+// loadFeedItems runs a feedItemQuery and converts the rows to the feed response format
+func loadFeedItems(query *gorm.DB) ([]FeedItemDetails, error) {
+	var rows []feedItemRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]FeedItemDetails, len(rows))
+	for i, row := range rows {
+		items[i] = FeedItemDetails{
+			FeedItem: row.FeedItem,
+			Article: Article{
+				ID:           row.ArticleID,
+				URL:          row.ArticleURL,
+				Title:        row.ArticleTitle,
+				Description:  row.ArticleDescription,
+				ImageURL:     row.ArticleImageURL,
+				PublishedAt:  row.ArticlePublishedAt,
+				SiteName:     row.ArticleSiteName,
+				Authors:      row.ArticleAuthors,
+				QualityScore: row.ArticleQualityScore,
+			},
+			Source: row.source(),
+		}
+	}
+	return items, nil
+}
+
+// source returns the row's primary source, or an empty source when nobody shared the article
+func (row feedItemRow) source() Source {
+	if row.SourceID == nil {
+		return Source{}
+	}
+
+	source := Source{ID: *row.SourceID}
+	if row.SourceHandle != nil {
+		source.Handle = *row.SourceHandle
+	}
+	if row.SourceDisplayName != nil {
+		source.DisplayName = *row.SourceDisplayName
+	}
+	if row.SourceAvatar != nil {
+		source.Avatar = *row.SourceAvatar
+	}
+	if row.SourceQualityScore != nil {
+		source.QualityScore = *row.SourceQualityScore
+	}
+	return source
+}
@@ -0,0 +1,97 @@
+package feeds
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// followedTopicWeight is the topic weight of a topic the user follows
+const followedTopicWeight = 1.0
+
+// UserPreferences are the topics and languages a user chose and the topic
+// weights learned from their activity
+type UserPreferences struct {
+	Topics        []string           // Topics the user follows
+	Languages     []string           // Language codes the user reads; empty for any
+	TopicAffinity map[string]float64 // Learned topic weights, with followed topics at full weight
+}
+
+// IsZero reports whether the user has no preferences, chosen or learned
+func (p UserPreferences) IsZero() bool {
+	return len(p.Topics) == 0 && len(p.Languages) == 0 && len(p.TopicAffinity) == 0
+}
+
+// apply narrows a personal feed query to the user's languages, with or without
+// a region such as en-GB. Articles whose language wasn't detected are kept.
+func (p UserPreferences) apply(query *gorm.DB) *gorm.DB {
+	if len(p.Languages) == 0 {
+		return query
+	}
+	conditions := []string{"COALESCE(articles.language, '') = ''", "LOWER(articles.language) IN ?"}
+	args := []interface{}{p.Languages}
+	for _, language := range p.Languages {
+		conditions = append(conditions, "LOWER(articles.language) LIKE ?")
+		args = append(args, language+"-%")
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// UserPreferences loads a user's chosen topics and languages and learned topic weights
+func (fs *FeedService) UserPreferences(userID uuid.UUID) (UserPreferences, error) {
+	var chosen struct {
+		PreferredTopics    pq.StringArray
+		PreferredLanguages pq.StringArray
+	}
+	err := fs.db.Table("user_feed_preferences").
+		Select("preferred_topics, preferred_languages").
+		Where("user_id = ?", userID).
+		Row().
+		Scan(&chosen.PreferredTopics, &chosen.PreferredLanguages)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return UserPreferences{}, fmt.Errorf("failed to load feed settings: %w", err)
+	}
+
+	var affinities []models.UserTopicAffinity
+	if err := fs.db.Where("user_id = ? AND weight > 0", userID).Find(&affinities).Error; err != nil {
+		return UserPreferences{}, fmt.Errorf("failed to load topic preferences: %w", err)
+	}
+
+	preferences := UserPreferences{
+		Topics:        chosen.PreferredTopics,
+		Languages:     chosen.PreferredLanguages,
+		TopicAffinity: make(map[string]float64, len(affinities)+len(chosen.PreferredTopics)),
+	}
+	for _, affinity := range affinities {
+		preferences.TopicAffinity[affinity.Topic] = affinity.Weight
+	}
+	for _, topic := range chosen.PreferredTopics {
+		preferences.TopicAffinity[topic] = followedTopicWeight
+	}
+	return preferences, nil
+}
+
+// preferredPersonalFeed builds the last week of a user's personal feed on the fly
+// with their topics, languages and topic weights. It returns nil when the user
+// has no preferences.
+func (fs *FeedService) preferredPersonalFeed(userID uuid.UUID, limit, offset int) (*FeedResponse, error) {
+	preferences, err := fs.UserPreferences(userID)
+	if err != nil || preferences.IsZero() {
+		return nil, err
+	}
+	return fs.GetFilteredFeed(FeedFilter{
+		Name:        "Personal Feed",
+		FeedType:    BuilderPersonalized,
+		Since:       time.Now().Add(-defaultTimeWindow),
+		UserID:      &userID,
+		Preferences: &preferences,
+	}, limit, offset)
+}
@@ -0,0 +1,131 @@
+package feeds
+
+import (
+	"fmt"
+	"sort"
+
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// rankCandidatePool is how many of the best-scored matching articles a custom ranker reorders
+const rankCandidatePool = 300
+
+// rankedArticle is an article with the score its feed is ordered by
+type rankedArticle struct {
+	Article models.Article
+	Score   float64
+}
+
+// rankArticles loads the candidate articles matching a filter query, orders them
+// with filter.Ranker (or the stored scores without one) and applies filter.Diversity
+func (fs *FeedService) rankArticles(query *gorm.DB, filter FeedFilter) ([]rankedArticle, error) {
+	var articles []models.Article
+	err := query.Preload("SourceArticles.Source").
+		Order("articles.quality_score DESC, articles.trending_score DESC, articles.created_at DESC").
+		Limit(rankCandidatePool).
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := filter.Viewer
+	if viewer == nil {
+		viewer = filter.UserID
+	}
+	var sharers map[uuid.UUID]int
+	if filter.Ranker != nil && viewer != nil && len(articles) > 0 {
+		if sharers, err = fs.followedSharerCounts(*viewer, articles); err != nil {
+			return nil, err
+		}
+	}
+
+	ranked := make([]rankedArticle, len(articles))
+	for i, article := range articles {
+		if filter.Ranker == nil {
+			ranked[i] = rankedArticle{Article: article, Score: article.QualityScore + (article.TrendingScore * 0.3)}
+			continue
+		}
+		breakdown := filter.Ranker.Explain(article, ranking.Signals{
+			FollowedSharers: sharers[article.ID],
+			Domain:          fs.domains.Lookup(article),
+			TopicAffinity:   filter.topicAffinity(),
+		})
+		ranked[i] = rankedArticle{Article: article, Score: filter.Ranker.Rank(breakdown)}
+	}
+
+	if filter.Ranker != nil {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].Score > ranked[j].Score
+		})
+	}
+	return diversifyRanked(ranked, filter.Diversity), nil
+}
+
+// diversifyRanked reorders ranked articles to meet diversity limits
+func diversifyRanked(ranked []rankedArticle, diversity Diversity) []rankedArticle {
+	if diversity.IsZero() {
+		return ranked
+	}
+	articles := make([]models.Article, len(ranked))
+	for i, candidate := range ranked {
+		articles[i] = candidate.Article
+	}
+	diversified := make([]rankedArticle, 0, len(ranked))
+	for _, i := range diversify(articles, diversity, 0) {
+		diversified = append(diversified, ranked[i])
+	}
+	return diversified
+}
+
+// followedSharerCounts counts how many of the user's follows shared each article
+func (fs *FeedService) followedSharerCounts(userID uuid.UUID, articles []models.Article) (map[uuid.UUID]int, error) {
+	articleIDs := make([]uuid.UUID, len(articles))
+	for i, article := range articles {
+		articleIDs[i] = article.ID
+	}
+
+	var rows []struct {
+		ArticleID uuid.UUID
+		Sharers   int
+	}
+	err := fs.db.Table("source_articles").
+		Select("source_articles.article_id, COUNT(DISTINCT source_articles.source_id) AS sharers").
+		Joins("JOIN user_sources ON user_sources.source_id = source_articles.source_id").
+		Where("user_sources.user_id = ?", userID).
+		Where("source_articles.article_id IN ?", articleIDs).
+		Group("source_articles.article_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count followed sharers: %w", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.ArticleID] = row.Sharers
+	}
+	return counts, nil
+}
+
+// topicAffinity returns the topic weights of the filter's user, if any
+func (f FeedFilter) topicAffinity() map[string]float64 {
+	if f.Preferences == nil {
+		return nil
+	}
+	return f.Preferences.TopicAffinity
+}
+
+// pageOf returns the ranked articles for one page
+func pageOf(ranked []rankedArticle, limit, offset int) []rankedArticle {
+	if offset >= len(ranked) {
+		return nil
+	}
+	ranked = ranked[offset:]
+	if limit >= 0 && limit < len(ranked) {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
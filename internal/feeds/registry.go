@@ -0,0 +1,357 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Feed builders a FeedDefinition can use
+const (
+	BuilderGlobal       = "global"
+	BuilderPersonalized = "personalized"
+)
+
+// What personalized feeds serve requests without a valid token, set per feed
+// with anonymous_fallback
+const (
+	FallbackNone     = ""         // Such requests get an AuthenticationRequired error
+	FallbackGlobal   = "global"   // Serve the global feed
+	FallbackTrending = "trending" // Serve the fastest-moving stories of the last day
+)
+
+// trendingFallbackHours is how far back the trending fallback looks
+const trendingFallbackHours = 24
+
+// defaultTimeWindow matches the window used when regenerating the global feed
+const defaultTimeWindow = 7 * 24 * time.Hour
+
+// ErrFeedNotFound is returned when no active definition matches a feed URI
+var ErrFeedNotFound = errors.New("feed not found")
+
+// ErrAuthRequired is returned when a feed needs a requesting user but none was given
+var ErrAuthRequired = errors.New("authentication required")
+
+// DefaultFeedDefinitions are the feeds every installation serves: the global
+// and personal feeds plus one global feed per topic (open-news-tech, ...)
+func DefaultFeedDefinitions() []models.FeedDefinition {
+	definitions := []models.FeedDefinition{
+		{
+			RKey:        "open-news-global",
+			DisplayName: "Open News - Global",
+			Description: "Top stories from across the Bluesky network, ranked by engagement and quality.",
+			Builder:     BuilderGlobal,
+			IsActive:    true,
+		},
+		{
+			RKey:        "open-news-personal",
+			DisplayName: "Open News - Personal",
+			Description: "Personalized news feed based on accounts you follow on Bluesky.",
+			Builder:     BuilderPersonalized,
+			IsActive:    true,
+
+			AnonymousFallback: FallbackGlobal,
+		},
+	}
+
+	for _, topic := range topics.All {
+		definitions = append(definitions, TopicFeedDefinition(topic))
+	}
+
+	return definitions
+}
+
+// TopicFeedDefinition returns the global feed definition for a single topic
+func TopicFeedDefinition(topic topics.Topic) models.FeedDefinition {
+	return models.FeedDefinition{
+		RKey:        "open-news-" + topic.Slug,
+		DisplayName: "Open News - " + topic.Name,
+		Description: fmt.Sprintf("Top %s stories shared across the Bluesky network, ranked by engagement and quality.", strings.ToLower(topic.Name)),
+		Builder:     BuilderGlobal,
+		Topic:       topic.Slug,
+		IsActive:    true,
+	}
+}
+
+// Registry resolves feed URIs to definitions and builds their contents. Its
+// definitions are those of one tenant: the default tenant unless ForTenant
+// selected another.
+type Registry struct {
+	db          *gorm.DB
+	feedService *FeedService
+	seenWindow  time.Duration // How long served articles are downranked in personal feeds
+	tenantID    *uuid.UUID    // nil for the default tenant
+}
+
+// NewRegistry creates a new feed definition registry.
+// SEEN_FILTER_WINDOW_HOURS (default 24, 0 disables) sets how long articles already
+// served to a user are pushed behind fresh ones in their personal feeds.
+func NewRegistry(db *gorm.DB) *Registry {
+	return &Registry{
+		db:          db,
+		feedService: NewFeedService(db),
+		seenWindow:  seenWindowFromEnv(),
+	}
+}
+
+// WithContext returns a registry running its queries with ctx, so they're
+// traced as part of the request ctx belongs to
+func (r *Registry) WithContext(ctx context.Context) *Registry {
+	scoped := *r
+	scoped.db = r.db.WithContext(ctx)
+	scoped.feedService = r.feedService.WithContext(ctx)
+	return &scoped
+}
+
+// ForTenant returns a registry serving the feed definitions of a tenant
+func (r *Registry) ForTenant(tenantID uuid.UUID) *Registry {
+	scoped := *r
+	scoped.tenantID = &tenantID
+	return &scoped
+}
+
+// tenant returns the ID of the registry's tenant, or nil on databases without
+// tenants, such as test databases migrating only some models
+func (r *Registry) tenant() *uuid.UUID {
+	if r.tenantID != nil {
+		return r.tenantID
+	}
+	return models.DefaultTenantID(r.db)
+}
+
+// scoped returns a query limited to a tenant's definitions
+func (r *Registry) scoped(tenantID *uuid.UUID) *gorm.DB {
+	if tenantID == nil {
+		return r.db
+	}
+	return r.db.Where("tenant_id = ?", *tenantID)
+}
+
+// EnsureDefaults creates the default feed definitions if they don't exist yet
+func (r *Registry) EnsureDefaults() error {
+	return r.ensureDefinitions(DefaultFeedDefinitions())
+}
+
+// ensureDefinitions inserts definitions whose rkey isn't registered, leaving existing rows untouched
+func (r *Registry) ensureDefinitions(definitions []models.FeedDefinition) error {
+	tenantID := r.tenant()
+	for _, def := range definitions {
+		def := def
+		def.TenantID = tenantID
+		if err := r.scoped(tenantID).Where("rkey = ?", def.RKey).FirstOrCreate(&def).Error; err != nil {
+			return fmt.Errorf("failed to ensure feed definition %s: %w", def.RKey, err)
+		}
+	}
+	return nil
+}
+
+// List returns the active feed definitions of the operator, leaving out the
+// feeds users defined for themselves
+func (r *Registry) List() ([]models.FeedDefinition, error) {
+	var definitions []models.FeedDefinition
+	err := r.scoped(r.tenant()).Where("is_active = ? AND owner_id IS NULL", true).Order("created_at ASC").Find(&definitions).Error
+	return definitions, err
+}
+
+// Lookup finds the active definition for a feed URI or bare record key
+func (r *Registry) Lookup(feedURI string) (*models.FeedDefinition, error) {
+	rkey := FeedRKey(feedURI)
+	if rkey == "" {
+		return nil, ErrFeedNotFound
+	}
+
+	var def models.FeedDefinition
+	err := r.scoped(r.tenant()).Where("rkey = ? AND is_active = ?", rkey, true).First(&def).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrFeedNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up feed %s: %w", rkey, err)
+	}
+
+	return &def, nil
+}
+
+// Build returns the feed contents for a definition. userID may be nil for
+// anonymous requests; personalized builders return ErrAuthRequired without it.
+// Global feeds only use userID for rankers that take the follow graph into account.
+func (r *Registry) Build(def *models.FeedDefinition, userID *uuid.UUID, limit, offset int) (*FeedResponse, error) {
+	precomputed := !def.HasFilters() && !def.HasDiversity() && rankerFor(def) == nil
+
+	switch def.Builder {
+	case BuilderGlobal:
+		if precomputed {
+			return r.feedService.GetGlobalFeed(limit, offset)
+		}
+		filter := filterFor(def, nil)
+		// Only follow-graph ranking depends on who is asking; other feeds are cached for everyone
+		if filter.Ranker != nil && filter.Ranker.Name() == ranking.FollowGraph {
+			filter.Viewer = userID
+		}
+		return r.feedService.GetFilteredFeed(filter, limit, offset)
+
+	case BuilderPersonalized:
+		if userID == nil {
+			return nil, ErrAuthRequired
+		}
+		if precomputed {
+			return r.feedService.GetPersonalizedFeed(*userID, limit, offset)
+		}
+		return r.feedService.GetFilteredFeed(filterFor(def, userID), limit, offset)
+	}
+
+	return nil, fmt.Errorf("unknown feed builder %q for feed %s", def.Builder, def.RKey)
+}
+
+// RequiresUser reports whether a definition can only be served to an authenticated user
+func RequiresUser(def *models.FeedDefinition) bool {
+	return def.Builder == BuilderPersonalized
+}
+
+// AnonymousFallback returns the definition served in place of a personalized
+// feed to requests without a valid token, or nil when the feed refuses them.
+// The fallback keeps the feed's filters, so a personal feed limited to one
+// language still is for logged-out readers.
+func AnonymousFallback(def *models.FeedDefinition) *models.FeedDefinition {
+	switch def.AnonymousFallback {
+	case FallbackNone:
+		return nil
+	case FallbackGlobal, FallbackTrending:
+	default:
+		log.Printf("Unknown anonymous fallback %q for feed %s, refusing anonymous requests", def.AnonymousFallback, def.RKey)
+		return nil
+	}
+
+	fallback := *def
+	fallback.Builder = BuilderGlobal
+	if def.AnonymousFallback == FallbackTrending {
+		fallback.Ranker = ranking.Engagement
+		fallback.TimeWindowHours = trendingFallbackHours
+	}
+	return &fallback
+}
+
+// FeedRKey extracts the record key from a feed generator URI such as
+// at://did:plc:example/app.bsky.feed.generator/open-news-global
+func FeedRKey(feedURI string) string {
+	feedURI = strings.TrimSpace(feedURI)
+	if i := strings.LastIndex(feedURI, "/"); i >= 0 {
+		return feedURI[i+1:]
+	}
+	return feedURI
+}
+
+// filterFor converts a definition's parameters into an article filter
+func filterFor(def *models.FeedDefinition, userID *uuid.UUID) FeedFilter {
+	window := defaultTimeWindow
+	if def.TimeWindowHours > 0 {
+		window = time.Duration(def.TimeWindowHours) * time.Hour
+	}
+
+	return FeedFilter{
+		Name:            def.DisplayName,
+		FeedType:        def.Builder,
+		Topic:           def.Topic,
+		Language:        def.Language,
+		DomainCategory:  def.DomainCategory,
+		Country:         def.Country,
+		Topics:          def.Topics,
+		Sites:           def.Domains,
+		MinShares:       def.MinShares,
+		Since:           time.Now().Add(-window),
+		MinQualityScore: def.MinQualityScore,
+		UserID:          userID,
+		Ranker:          rankerFor(def),
+		SafeMode:        def.SafeMode,
+		Diversity:       diversityFor(def),
+	}
+}
+
+// rankerFor returns the custom ranker a definition selects, or nil when the feed
+// is ordered by the stored (default ranker) scores
+func rankerFor(def *models.FeedDefinition) ranking.Ranker {
+	if def.Ranker == "" || def.Ranker == ranking.Default {
+		return nil
+	}
+	if !ranking.Exists(def.Ranker) {
+		log.Printf("Unknown ranker %q for feed %s, using stored scores", def.Ranker, def.RKey)
+		return nil
+	}
+	return ranking.Get(def.Ranker)
+}
+
+// BuildSkeleton builds a page of a feed exactly as getFeedSkeleton serves it to
+// userID, starting offset items in, and sets where the next page starts.
+// Personalized feeds fall back to top stories from the user's follows when they
+// can't be built and push articles the user was recently served behind fresh
+// ones on their first page; later pages continue from where it ended.
+// Other feeds explain which of the user's follows shared each item.
+func (r *Registry) BuildSkeleton(def *models.FeedDefinition, userID *uuid.UUID, limit, offset int) (*FeedResponse, error) {
+	if RequiresUser(def) {
+		// Build extra items so seen ones can be replaced
+		var seen map[uuid.UUID]bool
+		if offset == 0 {
+			seen = r.seenArticles(def, userID)
+		}
+		candidates := limit
+		if len(seen) > 0 {
+			candidates = limit + len(seen)
+			if candidates > maxSkeletonCandidates {
+				candidates = maxSkeletonCandidates
+			}
+		}
+
+		feedResponse, err := r.Build(def, userID, candidates, offset)
+		if err != nil {
+			if userID == nil || offset > 0 {
+				return nil, err
+			}
+			log.Printf("Failed to build feed %s, falling back to followed top stories: %v", def.RKey, err)
+			if feedResponse, err = r.feedService.GetFollowedTopStories(*userID, candidates); err != nil {
+				return nil, err
+			}
+			// The fallback has no further pages
+			if len(feedResponse.Items) > limit {
+				feedResponse.Items = feedResponse.Items[:limit]
+			}
+			return feedResponse, nil
+		}
+
+		consumed := len(feedResponse.Items)
+		if len(seen) > 0 {
+			feedResponse.Items, consumed = downrankSeen(feedResponse.Items, seen, limit)
+			feedResponse.Meta.PerPage = limit
+		}
+		setNextOffset(feedResponse, limit, offset+consumed)
+		return feedResponse, nil
+	}
+
+	feedResponse, err := r.Build(def, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil {
+		if err := r.feedService.AttachShareContext(*userID, feedResponse.Items); err != nil {
+			log.Printf("Failed to attach share context for feed %s: %v", def.RKey, err)
+		}
+	}
+	setNextOffset(feedResponse, limit, offset+len(feedResponse.Items))
+	return feedResponse, nil
+}
+
+// setNextOffset records where the page after a full page starts
+func setNextOffset(feedResponse *FeedResponse, limit, next int) {
+	feedResponse.NextOffset = 0
+	if len(feedResponse.Items) >= limit {
+		feedResponse.NextOffset = next
+	}
+}
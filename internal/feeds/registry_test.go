@@ -0,0 +1,99 @@
+package feeds
+
+import (
+	"testing"
+
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymousFallback(t *testing.T) {
+	def := &models.FeedDefinition{RKey: "open-news-personal", Builder: BuilderPersonalized, Language: "en"}
+	assert.Nil(t, AnonymousFallback(def), "feeds refuse anonymous requests by default")
+
+	def.AnonymousFallback = "everything"
+	assert.Nil(t, AnonymousFallback(def))
+
+	def.AnonymousFallback = FallbackGlobal
+	fallback := AnonymousFallback(def)
+	require.NotNil(t, fallback)
+	assert.Equal(t, BuilderGlobal, fallback.Builder)
+	assert.Equal(t, "en", fallback.Language, "the feed's filters are kept")
+	assert.Empty(t, fallback.Ranker)
+	assert.Equal(t, BuilderPersonalized, def.Builder, "the definition itself is unchanged")
+
+	def.AnonymousFallback = FallbackTrending
+	fallback = AnonymousFallback(def)
+	require.NotNil(t, fallback)
+	assert.False(t, RequiresUser(fallback))
+	assert.Equal(t, ranking.Engagement, fallback.Ranker)
+	assert.Equal(t, trendingFallbackHours, fallback.TimeWindowHours)
+}
+
+func TestFeedRKey(t *testing.T) {
+	tests := map[string]string{
+		"at://did:plc:example/app.bsky.feed.generator/open-news-global": "open-news-global",
+		"open-news-tech":   "open-news-tech",
+		" open-news-tech ": "open-news-tech",
+		"at://did:plc:example/app.bsky.feed.generator/": "",
+		"": "",
+	}
+	for uri, rkey := range tests {
+		assert.Equal(t, rkey, FeedRKey(uri), uri)
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	db := setupTestDB(t)
+	registry := NewRegistry(db)
+	require.NoError(t, registry.EnsureDefaults())
+	require.NoError(t, registry.EnsureDefaults(), "seeding again leaves the feeds alone")
+
+	definitions, err := registry.List()
+	require.NoError(t, err)
+	assert.Len(t, definitions, len(DefaultFeedDefinitions()), "the default feeds are seeded once")
+
+	def, err := registry.Lookup("at://did:plc:example/app.bsky.feed.generator/open-news-global")
+	require.NoError(t, err)
+	assert.Equal(t, BuilderGlobal, def.Builder)
+	assert.Empty(t, def.Topic)
+	assert.False(t, RequiresUser(def))
+
+	def, err = registry.Lookup("at://did:plc:example/app.bsky.feed.generator/open-news-personal")
+	require.NoError(t, err)
+	assert.Equal(t, BuilderPersonalized, def.Builder)
+	assert.True(t, RequiresUser(def))
+	_, err = registry.Build(def, nil, 10, 0)
+	assert.ErrorIs(t, err, ErrAuthRequired, "personal feeds need a requesting user")
+
+	topic := topics.All[0]
+	def, err = registry.Lookup("open-news-" + topic.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, BuilderGlobal, def.Builder)
+	assert.Equal(t, topic.Slug, def.Topic, "topic feeds carry their topic")
+
+	_, err = registry.Lookup("at://did:plc:example/app.bsky.feed.generator/unknown")
+	assert.ErrorIs(t, err, ErrFeedNotFound)
+	_, err = registry.Lookup("")
+	assert.ErrorIs(t, err, ErrFeedNotFound)
+
+	require.NoError(t, db.Model(&models.FeedDefinition{}).Where("rkey = ?", "open-news-global").Update("is_active", false).Error)
+	_, err = registry.Lookup("open-news-global")
+	assert.ErrorIs(t, err, ErrFeedNotFound, "inactive feeds aren't served")
+
+	// Feeds users define are served but not listed as the operator's
+	owner := uuid.New()
+	require.NoError(t, db.Create(&models.FeedDefinition{RKey: "my-feed", Builder: BuilderGlobal, IsActive: true, OwnerID: &owner, TenantID: models.DefaultTenantID(db)}).Error)
+	def, err = registry.Lookup("my-feed")
+	require.NoError(t, err)
+	assert.Equal(t, "my-feed", def.RKey)
+	definitions, err = registry.List()
+	require.NoError(t, err)
+	assert.Len(t, definitions, len(DefaultFeedDefinitions())-1)
+}
@@ -0,0 +1,100 @@
+package feeds
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// defaultSeenWindow is how long an article served in a personal feed counts as seen
+const defaultSeenWindow = 24 * time.Hour
+
+// maxSkeletonCandidates caps how many items are built to replace seen ones
+const maxSkeletonCandidates = 200
+
+// seenWindowFromEnv reads SEEN_FILTER_WINDOW_HOURS; 0 turns seen filtering off
+func seenWindowFromEnv() time.Duration {
+	value := os.Getenv("SEEN_FILTER_WINDOW_HOURS")
+	if value == "" {
+		return defaultSeenWindow
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours < 0 {
+		log.Printf("Invalid SEEN_FILTER_WINDOW_HOURS %q, using %v", value, defaultSeenWindow)
+		return defaultSeenWindow
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// seenArticles returns the articles already served to a user in a feed within the
+// seen window. It returns nil when filtering is off or the user has opted out.
+func (r *Registry) seenArticles(def *models.FeedDefinition, userID *uuid.UUID) map[uuid.UUID]bool {
+	if userID == nil || r.seenWindow <= 0 {
+		return nil
+	}
+
+	// Only the opt-out flag is read; the preference arrays need driver-specific scanning
+	var optedOut []bool
+	err := r.db.Model(&models.UserFeedPreference{}).
+		Where("user_id = ?", *userID).
+		Pluck("show_seen_articles", &optedOut).Error
+	if err != nil {
+		log.Printf("Failed to load feed preferences for user %s: %v", *userID, err)
+		return nil
+	}
+	if len(optedOut) > 0 && optedOut[0] {
+		return nil
+	}
+
+	var articleIDs []uuid.UUID
+	err = r.db.Model(&models.Impression{}).
+		Distinct("article_id").
+		Where("user_id = ? AND feed = ? AND created_at > ?", *userID, def.RKey, time.Now().Add(-r.seenWindow)).
+		Pluck("article_id", &articleIDs).Error
+	if err != nil {
+		log.Printf("Failed to load seen articles for user %s: %v", *userID, err)
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		seen[id] = true
+	}
+	return seen
+}
+
+// downrankSeen moves items the user has already been served behind unseen ones,
+// keeps the first limit items and renumbers their positions. It also returns how
+// many of the candidate items the page accounts for: the next page starts after
+// them, so no unseen item is skipped or served twice.
+func downrankSeen(items []FeedItemDetails, seen map[uuid.UUID]bool, limit int) ([]FeedItemDetails, int) {
+	order := make([]int, 0, len(items))
+	var repeats []int
+	for i, item := range items {
+		if seen[item.Article.ID] {
+			repeats = append(repeats, i)
+		} else {
+			order = append(order, i)
+		}
+	}
+	order = append(order, repeats...)
+
+	if len(order) > limit {
+		order = order[:limit]
+	}
+	ranked := make([]FeedItemDetails, len(order))
+	consumed := 0
+	for i, index := range order {
+		ranked[i] = items[index]
+		ranked[i].Position = i + 1
+		if index >= consumed {
+			consumed = index + 1
+		}
+	}
+	return ranked, consumed
+}
@@ -0,0 +1,55 @@
+package feeds
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownrankSeen(t *testing.T) {
+	items := make([]FeedItemDetails, 5)
+	for i := range items {
+		items[i].Article.ID = uuid.New()
+		items[i].Position = i + 1
+	}
+	seen := map[uuid.UUID]bool{
+		items[0].Article.ID: true,
+		items[2].Article.ID: true,
+	}
+
+	ranked, consumed := downrankSeen(items, seen, 4)
+
+	assert.Len(t, ranked, 4)
+	assert.Equal(t, []uuid.UUID{items[1].Article.ID, items[3].Article.ID, items[4].Article.ID, items[0].Article.ID},
+		[]uuid.UUID{ranked[0].Article.ID, ranked[1].Article.ID, ranked[2].Article.ID, ranked[3].Article.ID})
+	for i, item := range ranked {
+		assert.Equal(t, i+1, item.Position)
+	}
+	assert.Equal(t, 5, consumed, "the page accounts for every candidate up to the last one served")
+
+	// A page of unseen items ends at the last of them, skipping only seen ones
+	ranked, consumed = downrankSeen(items, seen, 2)
+	assert.Equal(t, []uuid.UUID{items[1].Article.ID, items[3].Article.ID}, []uuid.UUID{ranked[0].Article.ID, ranked[1].Article.ID})
+	assert.Equal(t, 4, consumed)
+}
+
+func TestSeenWindowFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", "24h0m0s"},
+		{"0", "0s"},
+		{"6", "6h0m0s"},
+		{"-1", "24h0m0s"},
+		{"soon", "24h0m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("SEEN_FILTER_WINDOW_HOURS", tt.value)
+			assert.Equal(t, tt.expected, seenWindowFromEnv().String())
+		})
+	}
+}
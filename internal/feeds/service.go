@@ -1,6 +1,9 @@
 package feeds
 
 import (
+	"context"
+	"log"
+	"open-news/internal/domains"
 	"open-news/internal/models"
 	"time"
 
@@ -10,12 +13,21 @@ import (
 
 // FeedService handles feed operations
 type FeedService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	domains *domains.Registry // Site reputations used by custom rankers
 }
 
 // NewFeedService creates a new feed service
 func NewFeedService(db *gorm.DB) *FeedService {
-	return &FeedService{db: db}
+	return &FeedService{db: db, domains: domains.NewRegistry(db)}
+}
+
+// WithContext returns a feed service running its queries with ctx, so they're
+// traced as part of the request ctx belongs to
+func (fs *FeedService) WithContext(ctx context.Context) *FeedService {
+	scoped := *fs
+	scoped.db = fs.db.WithContext(ctx)
+	return &scoped
 }
 
 // FeedResponse represents the structure returned by feed endpoints
@@ -23,6 +35,10 @@ type FeedResponse struct {
 	Feed  models.Feed       `json:"feed"`
 	Items []FeedItemDetails `json:"items"`
 	Meta  FeedMeta          `json:"meta"`
+
+	// NextOffset is where the next page of a skeleton built by BuildSkeleton
+	// starts, or 0 when there are no more items
+	NextOffset int `json:"-"`
 }
 
 // FeedItemDetails includes article and source information for feed items
@@ -30,6 +46,11 @@ type FeedItemDetails struct {
 	models.FeedItem
 	Article Article `json:"article"`
 	Source  Source  `json:"source"`
+
+	// Share context for the requesting user (personalized requests only)
+	SharedBy      []Source `json:"shared_by,omitempty"`
+	SharedByCount int      `json:"shared_by_count,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
 }
 
 // Article represents simplified article data for feed responses
@@ -41,6 +62,7 @@ type Article struct {
 	ImageURL    string     `json:"image_url"`
 	PublishedAt *time.Time `json:"published_at"`
 	SiteName    string     `json:"site_name"`
+	Authors     []string   `json:"authors"`
 	QualityScore float64   `json:"quality_score"`
 }
 
@@ -61,8 +83,14 @@ type FeedMeta struct {
 	LastUpdatedAt time.Time `json:"last_updated_at"`
 }
 
-// GetGlobalFeed returns the global top stories feed
+// GetGlobalFeed returns the global top stories feed. Pages are cached for
+// FEED_CACHE_TTL_SECONDS and dropped when the feed is regenerated.
 func (fs *FeedService) GetGlobalFeed(limit, offset int) (*FeedResponse, error) {
+	cacheKey := globalCacheKey(limit, offset)
+	if cached, ok := cachedResponse(cacheKey); ok {
+		return cached, nil
+	}
+
 	// Get or create global feed
 	var globalFeed models.Feed
 	err := fs.db.Where("feed_type = ? AND name = ?", "global", "Top Stories").
@@ -84,57 +112,22 @@ func (fs *FeedService) GetGlobalFeed(limit, offset int) (*FeedResponse, error) {
 		return nil, err
 	}
 
-	// Get feed items with articles and sources
-	var feedItems []models.FeedItem
-	err = fs.db.Preload("Article").
-		Preload("Article.SourceArticles.Source").
-		Where("feed_id = ?", globalFeed.ID).
-		Order("position ASC").
+	// Get feed items with their articles and primary sources in one query
+	items, err := loadFeedItems(fs.feedItemQuery().
+		Where("feed_items.feed_id = ?", globalFeed.ID).
+		Order("feed_items.position ASC").
 		Limit(limit).
-		Offset(offset).
-		Find(&feedItems).Error
+		Offset(offset))
 	
 	if err != nil {
 		return nil, err
 	}
 
-	// Transform to response format
-	items := make([]FeedItemDetails, len(feedItems))
-	for i, item := range feedItems {
-		// Get the primary source for this article (first one for now)
-		var source Source
-		if len(item.Article.SourceArticles) > 0 {
-			src := item.Article.SourceArticles[0].Source
-			source = Source{
-				ID:           src.ID,
-				Handle:       src.Handle,
-				DisplayName:  src.DisplayName,
-				Avatar:       src.Avatar,
-				QualityScore: src.QualityScore,
-			}
-		}
-
-		items[i] = FeedItemDetails{
-			FeedItem: item,
-			Article: Article{
-				ID:           item.Article.ID,
-				URL:          item.Article.URL,
-				Title:        item.Article.Title,
-				Description:  item.Article.Description,
-				ImageURL:     item.Article.ImageURL,
-				PublishedAt:  item.Article.PublishedAt,
-				SiteName:     item.Article.SiteName,
-				QualityScore: item.Article.QualityScore,
-			},
-			Source: source,
-		}
-	}
-
 	// Get total count
 	var totalCount int64
 	fs.db.Model(&models.FeedItem{}).Where("feed_id = ?", globalFeed.ID).Count(&totalCount)
 
-	return &FeedResponse{
+	response := &FeedResponse{
 		Feed:  globalFeed,
 		Items: items,
 		Meta: FeedMeta{
@@ -143,11 +136,20 @@ func (fs *FeedService) GetGlobalFeed(limit, offset int) (*FeedResponse, error) {
 			PerPage:       limit,
 			LastUpdatedAt: globalFeed.UpdatedAt,
 		},
-	}, nil
+	}
+	storeResponse(cacheKey, response)
+
+	return response, nil
 }
 
-// GetPersonalizedFeed returns a personalized feed for a specific user
+// GetPersonalizedFeed returns a personalized feed for a specific user. Users who
+// chose topics or languages, or whose topic preferences have been learned, get
+// the feed built on the fly with them.
 func (fs *FeedService) GetPersonalizedFeed(userID uuid.UUID, limit, offset int) (*FeedResponse, error) {
+	if preferred, err := fs.preferredPersonalFeed(userID, limit, offset); err != nil || preferred != nil {
+		return preferred, err
+	}
+
 	// Get or create personalized feed for user
 	var personalizedFeed models.Feed
 	err := fs.db.Where("feed_type = ? AND name = ?", "personalized", "Personal Feed").
@@ -169,49 +171,20 @@ func (fs *FeedService) GetPersonalizedFeed(userID uuid.UUID, limit, offset int)
 		return nil, err
 	}
 
-	// Get feed items for this user
-	var feedItems []models.FeedItem
-	err = fs.db.Preload("Article").
-		Preload("Article.SourceArticles.Source").
-		Where("feed_id = ? AND user_id = ?", personalizedFeed.ID, userID).
-		Order("position ASC").
+	// Get feed items for this user with their articles and primary sources
+	items, err := loadFeedItems(fs.feedItemQuery().
+		Where("feed_items.feed_id = ? AND feed_items.user_id = ?", personalizedFeed.ID, userID).
+		Order("feed_items.position ASC").
 		Limit(limit).
-		Offset(offset).
-		Find(&feedItems).Error
+		Offset(offset))
 	
 	if err != nil {
 		return nil, err
 	}
 
-	// Transform to response format (same as global feed)
-	items := make([]FeedItemDetails, len(feedItems))
-	for i, item := range feedItems {
-		var source Source
-		if len(item.Article.SourceArticles) > 0 {
-			src := item.Article.SourceArticles[0].Source
-			source = Source{
-				ID:           src.ID,
-				Handle:       src.Handle,
-				DisplayName:  src.DisplayName,
-				Avatar:       src.Avatar,
-				QualityScore: src.QualityScore,
-			}
-		}
-
-		items[i] = FeedItemDetails{
-			FeedItem: item,
-			Article: Article{
-				ID:           item.Article.ID,
-				URL:          item.Article.URL,
-				Title:        item.Article.Title,
-				Description:  item.Article.Description,
-				ImageURL:     item.Article.ImageURL,
-				PublishedAt:  item.Article.PublishedAt,
-				SiteName:     item.Article.SiteName,
-				QualityScore: item.Article.QualityScore,
-			},
-			Source: source,
-		}
+	// Explain which followed sources shared each article
+	if err := fs.AttachShareContext(userID, items); err != nil {
+		return nil, err
 	}
 
 	// Get total count
@@ -247,6 +220,10 @@ func (fs *FeedService) RegenerateGlobalFeed() error {
 			FeedType:    "global",
 			MaxItems:    100,
 			RefreshRate: 300,
+			MaxPerSource:  3,
+			MaxPerDomain:  3,
+			MaxPerCluster: 2,
+			PruneSuperseded: true,
 		}
 		if err := fs.db.Create(&globalFeed).Error; err != nil {
 			return err
@@ -255,24 +232,72 @@ func (fs *FeedService) RegenerateGlobalFeed() error {
 		return err
 	}
 
+	if err := fs.regenerateGlobalFeed(&globalFeed); err != nil {
+		fs.recordRegenerationError(&globalFeed, err)
+		return err
+	}
+	return nil
+}
+
+// regenerateGlobalFeed replaces the items of the global feed with the pinned
+// and top articles
+func (fs *FeedService) regenerateGlobalFeed(globalFeed *models.Feed) error {
 	// Clear existing feed items for this feed
 	if err := fs.db.Where("feed_id = ?", globalFeed.ID).Delete(&models.FeedItem{}).Error; err != nil {
 		return err
 	}
 
-	// Get top articles from the last 7 days with quality scores > 0
-	cutoffDate := time.Now().AddDate(0, 0, -7)
+	// Pinned articles lead the feed, most recently pinned first, whatever their age or score
 	var articles []models.Article
-	
-	err = fs.db.Where("created_at > ? AND quality_score > 0", cutoffDate).
-		Order("quality_score DESC, trending_score DESC, created_at DESC").
+	err := fs.db.Preload("SourceArticles").
+		Where("is_pinned = ? AND is_not_news = ?", true, false).
+		Order("pinned_at DESC").
 		Limit(100).
 		Find(&articles).Error
-	
 	if err != nil {
 		return err
 	}
 
+	pinned := len(articles)
+
+	// Fill the rest with top articles younger than the feed's max age with quality
+	// scores > 0, considering extra candidates to replace those over the diversity
+	// limits or superseded by newer coverage
+	diversity := feedDiversity(*globalFeed)
+	cutoffDate := time.Now().Add(-feedMaxAge(*globalFeed))
+	if len(articles) < 100 {
+		candidates := 100 - len(articles)
+		if !diversity.IsZero() || globalFeed.PruneSuperseded {
+			candidates *= diversityCandidateFactor
+		}
+		var topArticles []models.Article
+		err = fs.db.Preload("SourceArticles").
+			Where("created_at > ? AND quality_score > 0 AND is_not_news = ? AND is_unshared = ? AND is_pinned = ?", cutoffDate, false, false, false).
+			Scopes(domains.NotBlocked).
+			Order("quality_score DESC, trending_score DESC, created_at DESC").
+			Limit(candidates).
+			Find(&topArticles).Error
+		if err != nil {
+			return err
+		}
+		articles = append(articles, topArticles...)
+	}
+
+	// Show each story by its latest coverage
+	if globalFeed.PruneSuperseded {
+		articles = pruneSuperseded(articles, pinned)
+	}
+
+	// Keep one outlet or story from taking over a page
+	diversified := make([]models.Article, 0, len(articles))
+	for _, i := range diversify(articles, diversity, pinned) {
+		diversified = append(diversified, articles[i])
+	}
+	articles = diversified
+	if len(articles) > 100 {
+		articles = articles[:100]
+	}
+
 	// Create feed items for each article
 	var feedItems []models.FeedItem
 	for i, article := range articles {
@@ -303,10 +328,28 @@ func (fs *FeedService) RegenerateGlobalFeed() error {
 	}
 
 	// Update feed timestamp
-	globalFeed.UpdatedAt = time.Now()
-	if err := fs.db.Save(&globalFeed).Error; err != nil {
+	now := time.Now()
+	globalFeed.UpdatedAt = now
+	globalFeed.RegeneratedAt = &now
+	if err := fs.db.Save(globalFeed).Error; err != nil {
 		return err
 	}
 
+	// Serve the new ranking right away instead of waiting for cached pages to expire
+	InvalidateGlobalFeed()
+
 	return nil
 }
+
+// recordRegenerationError stores why a feed failed to regenerate, for the feed
+// health report
+func (fs *FeedService) recordRegenerationError(feed *models.Feed, regenerateErr error) {
+	now := time.Now()
+	err := fs.db.Model(feed).UpdateColumns(map[string]interface{}{
+		"last_error":    regenerateErr.Error(),
+		"last_error_at": now,
+	}).Error
+	if err != nil {
+		log.Printf("Failed to record the regeneration error of feed %s: %v", feed.Name, err)
+	}
+}
@@ -0,0 +1,128 @@
+package feeds
+
+import (
+	"fmt"
+	"sort"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// maxSharedBy is the number of sharers returned per feed item
+const maxSharedBy = 5
+
+// shareRow is a followed source that shared one of the feed's articles
+type shareRow struct {
+	ArticleID      uuid.UUID
+	SourceID       uuid.UUID
+	Handle         string
+	DisplayName    string
+	Avatar         string
+	FollowersCount int
+	IsVerified     bool
+	QualityScore   float64
+}
+
+// AttachShareContext fills in SharedBy and Reason for each item, based on which
+// of the user's followed sources shared the article
+func (fs *FeedService) AttachShareContext(userID uuid.UUID, items []FeedItemDetails) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	articleIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		articleIDs[i] = item.Article.ID
+	}
+
+	var rows []shareRow
+	err := fs.db.Table("source_articles").
+		Select("DISTINCT source_articles.article_id, sources.id AS source_id, sources.handle, sources.display_name, "+
+			"sources.avatar, sources.followers_count, sources.is_verified, sources.quality_score").
+		Joins("JOIN sources ON sources.id = source_articles.source_id").
+		Joins("JOIN user_sources ON user_sources.source_id = sources.id").
+		Where("user_sources.user_id = ?", userID).
+		Where("source_articles.article_id IN ?", articleIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to load share context: %w", err)
+	}
+
+	sharers := make(map[uuid.UUID][]models.Source)
+	for _, row := range rows {
+		sharers[row.ArticleID] = append(sharers[row.ArticleID], models.Source{
+			ID:             row.SourceID,
+			Handle:         row.Handle,
+			DisplayName:    row.DisplayName,
+			Avatar:         row.Avatar,
+			FollowersCount: row.FollowersCount,
+			IsVerified:     row.IsVerified,
+			QualityScore:   row.QualityScore,
+		})
+	}
+
+	for i := range items {
+		sources := sharers[items[i].Article.ID]
+		if len(sources) == 0 {
+			continue
+		}
+
+		sortSourcesByReach(sources)
+
+		shown := sources
+		if len(shown) > maxSharedBy {
+			shown = shown[:maxSharedBy]
+		}
+
+		items[i].SharedBy = make([]Source, len(shown))
+		for j, src := range shown {
+			items[i].SharedBy[j] = toFeedSource(src)
+		}
+		items[i].SharedByCount = len(sources)
+		items[i].Reason = FormatShareReason(sources)
+	}
+
+	return nil
+}
+
+// FormatShareReason builds a human-readable reason such as
+// "Shared by @reuters and 3 people you follow"
+func FormatShareReason(sources []models.Source) string {
+	switch len(sources) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("Shared by @%s", sources[0].Handle)
+	case 2:
+		return fmt.Sprintf("Shared by @%s and @%s", sources[0].Handle, sources[1].Handle)
+	}
+
+	others := len(sources) - 1
+	return fmt.Sprintf("Shared by @%s and %d people you follow", sources[0].Handle, others)
+}
+
+// sortSourcesByReach orders sources so the most prominent sharer comes first
+func sortSourcesByReach(sources []models.Source) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		if a.IsVerified != b.IsVerified {
+			return a.IsVerified
+		}
+		if a.FollowersCount != b.FollowersCount {
+			return a.FollowersCount > b.FollowersCount
+		}
+		return a.QualityScore > b.QualityScore
+	})
+}
+
+// toFeedSource converts a source model to the feed response format
+func toFeedSource(src models.Source) Source {
+	return Source{
+		ID:           src.ID,
+		Handle:       src.Handle,
+		DisplayName:  src.DisplayName,
+		Avatar:       src.Avatar,
+		QualityScore: src.QualityScore,
+	}
+}
@@ -0,0 +1,90 @@
+package feeds
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestDB returns a migrated in-memory SQLite database
+func setupTestDB(t *testing.T) *gorm.DB {
+	require.NoError(t, database.Connect(&database.Config{Driver: database.DriverSQLite, Path: ":memory:"}))
+	t.Cleanup(func() { database.Close() })
+	require.NoError(t, database.Migrate())
+	return database.DB
+}
+
+func TestFormatShareReason(t *testing.T) {
+	sources := func(handles ...string) []models.Source {
+		list := make([]models.Source, len(handles))
+		for i, handle := range handles {
+			list[i].Handle = handle
+		}
+		return list
+	}
+
+	tests := []struct {
+		name     string
+		sources  []models.Source
+		expected string
+	}{
+		{"none", nil, ""},
+		{"one", sources("reuters"), "Shared by @reuters"},
+		{"two", sources("reuters", "apnews"), "Shared by @reuters and @apnews"},
+		{"three", sources("reuters", "apnews", "bbc"), "Shared by @reuters and 2 people you follow"},
+		{"many", sources("reuters", "a", "b", "c", "d", "e"), "Shared by @reuters and 5 people you follow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatShareReason(tt.sources))
+		})
+	}
+}
+
+func TestAttachShareContext(t *testing.T) {
+	db := setupTestDB(t)
+
+	user := models.User{BlueSkyDID: "did:plc:reader", Handle: "reader.test"}
+	require.NoError(t, db.Create(&user).Error)
+	article := models.Article{URL: "https://example.com/story", Title: "Story"}
+	require.NoError(t, db.Create(&article).Error)
+
+	// Three followed sharers of differing reach, and one the user doesn't follow
+	sharers := []models.Source{
+		{Handle: "small.test", FollowersCount: 10},
+		{Handle: "verified.test", FollowersCount: 5, IsVerified: true},
+		{Handle: "big.test", FollowersCount: 5000},
+		{Handle: "stranger.test", FollowersCount: 1000000, IsVerified: true},
+	}
+	for i := range sharers {
+		sharers[i].BlueSkyDID = fmt.Sprintf("did:plc:sharer%d", i)
+		require.NoError(t, db.Create(&sharers[i]).Error)
+		require.NoError(t, db.Create(&models.SourceArticle{
+			SourceID:  sharers[i].ID,
+			ArticleID: article.ID,
+			PostURI:   fmt.Sprintf("at://%s/app.bsky.feed.post/1", sharers[i].BlueSkyDID),
+			PostedAt:  time.Now(),
+		}).Error)
+		if sharers[i].Handle != "stranger.test" {
+			require.NoError(t, db.Create(&models.UserSource{UserID: user.ID, SourceID: sharers[i].ID}).Error)
+		}
+	}
+
+	items := []FeedItemDetails{{Article: Article{ID: article.ID}}}
+	require.NoError(t, NewFeedService(db).AttachShareContext(user.ID, items))
+
+	var handles []string
+	for _, source := range items[0].SharedBy {
+		handles = append(handles, source.Handle)
+	}
+	assert.Equal(t, []string{"verified.test", "big.test", "small.test"}, handles, "only followed sources, most prominent first")
+	assert.Equal(t, 3, items[0].SharedByCount)
+	assert.Equal(t, "Shared by @verified.test and 2 people you follow", items[0].Reason)
+}
@@ -0,0 +1,113 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// metaPrescanLength is how far into a page a <meta charset> is looked for, as
+// browsers do
+const metaPrescanLength = 1024
+
+// sniffLength is how much of a page without a declared charset is checked for
+// being UTF-8
+const sniffLength = 16 << 10
+
+// decodeBody converts a page to UTF-8 from its charset, which is taken from, in
+// order: a byte order mark, the Content-Type header, a <meta> in the first
+// 1024 bytes, and otherwise sniffed, UTF-8 when the start of the page is valid
+// UTF-8 and windows-1252 (which browsers use for ISO-8859-1 too) when it isn't.
+// It returns the decoding reader and the charset's name.
+func decodeBody(body io.Reader, contentType string) (io.Reader, string, error) {
+	buffered := bufio.NewReaderSize(body, sniffLength)
+	head, err := buffered.Peek(sniffLength)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, "", err
+	}
+
+	e, name := detectCharset(head, contentType)
+	if name == "utf-8" {
+		return buffered, name, nil
+	}
+	return transform.NewReader(buffered, e.NewDecoder()), name, nil
+}
+
+// detectCharset returns the encoding of a page starting with head
+func detectCharset(head []byte, contentType string) (encoding.Encoding, string) {
+	// Byte order marks and Content-Type charsets are certain
+	if e, name, certain := charset.DetermineEncoding(head, contentType); certain {
+		return e, name
+	}
+
+	if label := metaCharset(head); label != "" {
+		if e, name := charset.Lookup(label); e != nil {
+			return e, name
+		}
+	}
+
+	if looksLikeUTF8(head) {
+		return encoding.Nop, "utf-8"
+	}
+	e, name := charset.Lookup("windows-1252")
+	return e, name
+}
+
+// metaCharset returns the charset declared by a <meta charset> or <meta
+// http-equiv="Content-Type"> near the start of a page, or ""
+func metaCharset(head []byte) string {
+	if len(head) > metaPrescanLength {
+		head = head[:metaPrescanLength]
+	}
+	tokenizer := html.NewTokenizer(bytes.NewReader(head))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "meta" {
+				continue
+			}
+			var httpEquiv, content string
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "charset":
+					return strings.TrimSpace(attr.Val)
+				case "http-equiv":
+					httpEquiv = attr.Val
+				case "content":
+					content = attr.Val
+				}
+			}
+			if strings.EqualFold(httpEquiv, "content-type") {
+				if _, params, err := mime.ParseMediaType(content); err == nil && params["charset"] != "" {
+					return params["charset"]
+				}
+			}
+		}
+	}
+}
+
+// looksLikeUTF8 reports whether the start of a page is valid UTF-8, ignoring a
+// character cut off at its end
+func looksLikeUTF8(head []byte) bool {
+	for i := len(head) - 1; i >= 0 && i > len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				head = head[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(head)
+}
@@ -0,0 +1,436 @@
+// Package fetcher provides the shared HTTP layer used to download article pages.
+// It enforces response size limits and content-type checks so that arbitrary
+// links shared on Bluesky can't exhaust memory or pull down PDFs and videos,
+// and it refuses to connect to private, loopback or link-local addresses.
+// Pages in other charsets, such as ISO-8859-1 or Shift_JIS, are decoded to UTF-8.
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"open-news/internal/pool"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/html"
+)
+
+// DefaultMaxBodySize is the largest response body we'll read (5 MB)
+const DefaultMaxBodySize int64 = 5 << 20
+
+var (
+	// ErrBodyTooLarge is returned when a response body exceeds the configured limit
+	ErrBodyTooLarge = errors.New("response body too large")
+
+	// ErrUnsupportedContentType is returned when a response isn't an HTML document
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+
+	// ErrBlockedAddress is returned when a URL resolves to a private, loopback or link-local address
+	ErrBlockedAddress = errors.New("blocked address")
+)
+
+// StatusError is returned when the remote server responds with a non-200 status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
+}
+
+// ContentTypeError is returned when the response Content-Type is not text/html,
+// or XML for feeds
+type ContentTypeError struct {
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("%s: %q", ErrUnsupportedContentType, e.ContentType)
+}
+
+// Unwrap allows errors.Is(err, ErrUnsupportedContentType)
+func (e *ContentTypeError) Unwrap() error {
+	return ErrUnsupportedContentType
+}
+
+// BodyTooLargeError is returned when the response body exceeds MaxBodySize
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("%s (limit %d bytes)", ErrBodyTooLarge, e.Limit)
+}
+
+// Unwrap allows errors.Is(err, ErrBodyTooLarge)
+func (e *BodyTooLargeError) Unwrap() error {
+	return ErrBodyTooLarge
+}
+
+// RequestError is a failed fetch with the ID of its request, which publishers
+// can find in their logs under the X-Request-Id header
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v [request %s]", e.Err, e.RequestID)
+}
+
+// Unwrap allows errors.Is and errors.As to see the underlying error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// defaultContactURL is where publishers learn about the crawler without FETCH_CONTACT_URL
+const defaultContactURL = "https://opennews.social"
+
+// Identity is how the crawler introduces itself to publishers, so they can
+// tell its requests apart and contact the operator about them
+type Identity struct {
+	UserAgent  string // FETCH_USER_AGENT; built from the contact URL when unset
+	From       string // FETCH_FROM: an email address sent in the From header; none when unset
+	ContactURL string // FETCH_CONTACT_URL: a page about the crawler (default https://opennews.social)
+}
+
+// LoadIdentity reads the crawler's identity from the environment
+func LoadIdentity() Identity {
+	identity := Identity{
+		UserAgent:  os.Getenv("FETCH_USER_AGENT"),
+		From:       os.Getenv("FETCH_FROM"),
+		ContactURL: os.Getenv("FETCH_CONTACT_URL"),
+	}
+	if identity.ContactURL == "" {
+		identity.ContactURL = defaultContactURL
+	}
+	if identity.UserAgent == "" {
+		identity.UserAgent = "Mozilla/5.0 (compatible; OpenNewsBot/1.0; +" + identity.ContactURL + ")"
+	}
+	return identity
+}
+
+// Options configures a Fetcher
+type Options struct {
+	MaxBodySize          int64         // Maximum number of body bytes to read
+	Timeout              time.Duration // Overall request timeout
+	MaxRedirects         int           // Maximum redirects to follow
+	UserAgent            string        // User-Agent header sent with every request
+	From                 string        // From header sent with every request; none when empty
+	AllowPrivateNetworks bool          // Disable the SSRF guard (local development and tests only)
+	PerHost              int           // Fetches from one host at once; 0 shares FETCH_CONCURRENCY_PER_DOMAIN with every other fetcher
+}
+
+// DefaultOptions returns the default fetch options, honoring FETCH_MAX_BODY_BYTES,
+// FETCH_ALLOW_PRIVATE_NETWORKS and the crawler identity settings
+func DefaultOptions() Options {
+	maxBody := DefaultMaxBodySize
+	if value := os.Getenv("FETCH_MAX_BODY_BYTES"); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			maxBody = parsed
+		}
+	}
+
+	identity := LoadIdentity()
+	return Options{
+		MaxBodySize:  maxBody,
+		Timeout:      30 * time.Second,
+		MaxRedirects: 10,
+		UserAgent:    identity.UserAgent,
+		From:         identity.From,
+
+		AllowPrivateNetworks: os.Getenv("FETCH_ALLOW_PRIVATE_NETWORKS") == "true",
+	}
+}
+
+// sharedHosts bounds fetches from one host across every fetcher in the process,
+// so the firehose, site feed polls and refetches together don't hammer a site
+var sharedHosts = sync.OnceValue(func() *pool.DomainLimiter {
+	return pool.NewDomainLimiter(pool.Shared().PerDomain)
+})
+
+// Fetcher downloads HTML documents with size and content-type guards
+type Fetcher struct {
+	httpClient *http.Client
+	options    Options
+	hosts      *pool.DomainLimiter // Bounds fetches from one host at once
+}
+
+// NewFetcher creates a new fetcher with the given options
+func NewFetcher(options Options) *Fetcher {
+	defaults := DefaultOptions()
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = defaults.MaxBodySize
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaults.Timeout
+	}
+	if options.MaxRedirects <= 0 {
+		options.MaxRedirects = defaults.MaxRedirects
+	}
+	if options.UserAgent == "" {
+		options.UserAgent = defaults.UserAgent
+	}
+	if options.From == "" {
+		options.From = defaults.From
+	}
+
+	hosts := sharedHosts()
+	if options.PerHost > 0 {
+		hosts = pool.NewDomainLimiter(options.PerHost)
+	}
+
+	maxRedirects := options.MaxRedirects
+	return &Fetcher{
+		options: options,
+		hosts:   hosts,
+		httpClient: &http.Client{
+			Timeout:   options.Timeout,
+			Transport: otelhttp.NewTransport(newTransport(options.AllowPrivateNetworks)),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return checkURL(req.URL, options.AllowPrivateNetworks)
+			},
+		},
+	}
+}
+
+// Document is a fetched and parsed HTML page
+type Document struct {
+	URL       string // Final URL after redirects
+	RequestID string // Sent as X-Request-Id, to correlate with the publisher's logs
+	Charset   string // Charset the page was decoded from, e.g. "windows-1252"
+	Header    http.Header
+	Root      *html.Node // Parsed HTML tree
+	Content   string     // Raw HTML as received, converted to UTF-8
+}
+
+// FetchHTML downloads an HTML page and parses it while it streams in, decoding
+// pages in other charsets to UTF-8 first. The HTML is captured alongside the
+// parse so callers can cache it. It waits while the host's limit of fetches
+// is running.
+// Errors are *RequestErrors carrying the ID the request was sent with.
+func (f *Fetcher) FetchHTML(ctx context.Context, pageURL string) (*Document, error) {
+	requestID := newRequestID()
+	var doc *Document
+	err := f.hosts.Do(ctx, host(pageURL), func() (err error) {
+		doc, err = f.fetchHTML(ctx, pageURL, requestID)
+		return err
+	})
+	if err != nil {
+		return nil, &RequestError{RequestID: requestID, Err: err}
+	}
+	return doc, nil
+}
+
+// fetchHTML downloads and parses a page, sending requestID with the request
+func (f *Fetcher) fetchHTML(ctx context.Context, pageURL, requestID string) (*Document, error) {
+	resp, err := f.open(ctx, pageURL, requestID, htmlAccept, checkContentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, charsetName, err := decodeBody(f.limitBody(resp.Body), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	var raw bytes.Buffer
+	root, err := html.Parse(io.TeeReader(body, &raw))
+	if err != nil {
+		var tooLarge *BodyTooLargeError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	return &Document{
+		URL:       resp.Request.URL.String(),
+		RequestID: requestID,
+		Charset:   charsetName,
+		Header:    resp.Header,
+		Root:      root,
+		Content:   raw.String(),
+	}, nil
+}
+
+// FetchFeed downloads an RSS or Atom feed or an XML sitemap, with the same
+// guards as FetchHTML. The body is returned as received, since XML declares its
+// own encoding.
+// Errors are *RequestErrors carrying the ID the request was sent with.
+func (f *Fetcher) FetchFeed(ctx context.Context, feedURL string) ([]byte, error) {
+	requestID := newRequestID()
+	var body []byte
+	err := f.hosts.Do(ctx, host(feedURL), func() (err error) {
+		body, err = f.fetchFeed(ctx, feedURL, requestID)
+		return err
+	})
+	if err != nil {
+		return nil, &RequestError{RequestID: requestID, Err: err}
+	}
+	return body, nil
+}
+
+// fetchFeed downloads a feed, sending requestID with the request
+func (f *Fetcher) fetchFeed(ctx context.Context, feedURL, requestID string) ([]byte, error) {
+	resp, err := f.open(ctx, feedURL, requestID, feedAccept, checkFeedContentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(f.limitBody(resp.Body))
+}
+
+// host returns the lowercased host of a URL, which fetches from it are bounded
+// by. A redirect elsewhere still counts against the host first asked for.
+func host(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// newRequestID returns a random ID for a request
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Accept headers sent for pages and for feeds
+const (
+	htmlAccept = "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1"
+	feedAccept = "application/rss+xml,application/atom+xml,application/xml;q=0.9,text/xml;q=0.9,*/*;q=0.1"
+)
+
+// open performs the GET request and validates the status, and the content type
+// with checkType
+func (f *Fetcher) open(ctx context.Context, pageURL, requestID, accept string, checkType func(string) error) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := checkURL(req.URL, f.options.AllowPrivateNetworks); err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", f.options.UserAgent)
+	if f.options.From != "" {
+		req.Header.Set("From", f.options.From)
+	}
+	req.Header.Set("X-Request-Id", requestID)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	if err := checkType(resp.Header.Get("Content-Type")); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Reject early when the server announces an oversized body
+	if resp.ContentLength > f.options.MaxBodySize {
+		resp.Body.Close()
+		return nil, &BodyTooLargeError{Limit: f.options.MaxBodySize}
+	}
+
+	return resp, nil
+}
+
+// limitBody wraps a response body so reads fail once MaxBodySize is exceeded
+func (f *Fetcher) limitBody(body io.Reader) io.Reader {
+	return &limitedReader{reader: body, remaining: f.options.MaxBodySize, limit: f.options.MaxBodySize}
+}
+
+// checkContentType accepts HTML documents and responses without a Content-Type
+func checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &ContentTypeError{ContentType: contentType}
+	}
+
+	switch strings.ToLower(mediaType) {
+	case "text/html", "application/xhtml+xml":
+		return nil
+	}
+
+	return &ContentTypeError{ContentType: mediaType}
+}
+
+// checkFeedContentType accepts XML documents, including RSS and Atom feeds,
+// and responses without a Content-Type
+func checkFeedContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &ContentTypeError{ContentType: contentType}
+	}
+
+	mediaType = strings.ToLower(mediaType)
+	if mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml") {
+		return nil
+	}
+
+	return &ContentTypeError{ContentType: mediaType}
+}
+
+// limitedReader is like io.LimitedReader but reports an error instead of a silent EOF
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, &BodyTooLargeError{Limit: l.limit}
+	}
+
+	// Read one byte past the limit so we can tell an exact-size body from an oversized one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), &BodyTooLargeError{Limit: l.limit}
+	}
+	return n, err
+}
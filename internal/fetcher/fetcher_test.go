@@ -0,0 +1,224 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestLoadIdentity(t *testing.T) {
+	t.Setenv("FETCH_USER_AGENT", "")
+	t.Setenv("FETCH_FROM", "")
+	t.Setenv("FETCH_CONTACT_URL", "https://news.example/bot")
+	identity := LoadIdentity()
+	assert.Equal(t, "Mozilla/5.0 (compatible; OpenNewsBot/1.0; +https://news.example/bot)", identity.UserAgent)
+	assert.Equal(t, "", identity.From)
+
+	t.Setenv("FETCH_USER_AGENT", "ExampleNewsBot/2.0")
+	t.Setenv("FETCH_FROM", "crawler@news.example")
+	identity = LoadIdentity()
+	assert.Equal(t, "ExampleNewsBot/2.0", identity.UserAgent)
+	assert.Equal(t, "crawler@news.example", identity.From)
+}
+
+func TestFetchHTMLIdentifiesRequests(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Story</title></head></html>"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(Options{UserAgent: "ExampleNewsBot/2.0", From: "crawler@news.example", AllowPrivateNetworks: true})
+
+	doc, err := f.FetchHTML(context.Background(), server.URL+"/story")
+	require.NoError(t, err)
+	assert.Equal(t, "ExampleNewsBot/2.0", received.Get("User-Agent"))
+	assert.Equal(t, "crawler@news.example", received.Get("From"))
+	assert.Len(t, doc.RequestID, 32)
+	assert.Equal(t, doc.RequestID, received.Get("X-Request-Id"))
+	firstID := doc.RequestID
+
+	_, err = f.FetchHTML(context.Background(), server.URL+"/missing")
+	var requestErr *RequestError
+	require.ErrorAs(t, err, &requestErr)
+	assert.Equal(t, received.Get("X-Request-Id"), requestErr.RequestID)
+	assert.NotEqual(t, firstID, requestErr.RequestID, "every request has its own ID")
+	assert.Contains(t, err.Error(), "[request "+requestErr.RequestID+"]")
+
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr), "the underlying error is still visible")
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestFetchHTMLDecodesCharsets(t *testing.T) {
+	pages := map[string]struct {
+		contentType string
+		body        []byte
+	}{
+		// "Café déjà vu" in ISO-8859-1, declared in the header
+		"/header": {"text/html; charset=ISO-8859-1", []byte("<html><head><title>Caf\xe9 d\xe9j\xe0 vu</title></head></html>")},
+		// "Новости" in Windows-1251, declared in a <meta charset>
+		"/meta": {"text/html", []byte("<html><head><meta charset=\"windows-1251\"><title>\xcd\xee\xe2\xee\xf1\xf2\xe8</title></head></html>")},
+		// "ニュース" in Shift_JIS, declared in a <meta http-equiv>
+		"/http-equiv": {"text/html", []byte("<html><head><meta http-equiv=\"Content-Type\" content=\"text/html; charset=Shift_JIS\"><title>\x83j\x83\x85\x81[\x83X</title></head></html>")},
+		// Undeclared, and not UTF-8
+		"/sniffed": {"text/html", []byte("<html><head><title>Caf\xe9</title></head></html>")},
+		// Undeclared UTF-8
+		"/utf8": {"", []byte("<html><head><title>Café</title></head></html>")},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages[r.URL.Path]
+		if page.contentType != "" {
+			w.Header().Set("Content-Type", page.contentType)
+		}
+		w.Write(page.body)
+	}))
+	defer server.Close()
+
+	f := NewFetcher(Options{AllowPrivateNetworks: true})
+
+	tests := []struct {
+		path    string
+		charset string
+		title   string
+	}{
+		{"/header", "windows-1252", "Café déjà vu"},
+		{"/meta", "windows-1251", "Новости"},
+		{"/http-equiv", "shift_jis", "ニュース"},
+		{"/sniffed", "windows-1252", "Café"},
+		{"/utf8", "utf-8", "Café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			doc, err := f.FetchHTML(context.Background(), server.URL+tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.charset, doc.Charset)
+			assert.Contains(t, doc.Content, "<title>"+tt.title+"</title>", "the cached HTML is UTF-8")
+			assert.Equal(t, tt.title, title(doc.Root))
+		})
+	}
+}
+
+// title returns the text of a page's <title>
+func title(n *html.Node) string {
+	if n.Type == html.ElementNode && n.Data == "title" && n.FirstChild != nil {
+		return n.FirstChild.Data
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if text := title(c); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func TestFetchFeed(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/rss":
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			w.Write([]byte(`<rss version="2.0"><channel></channel></rss>`))
+		case "/sitemap.xml":
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<urlset></urlset>`))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer server.Close()
+
+	f := NewFetcher(Options{AllowPrivateNetworks: true})
+
+	body, err := f.FetchFeed(context.Background(), server.URL+"/rss")
+	require.NoError(t, err)
+	assert.Equal(t, `<rss version="2.0"><channel></channel></rss>`, string(body))
+	assert.Contains(t, accept, "application/rss+xml")
+
+	_, err = f.FetchFeed(context.Background(), server.URL+"/sitemap.xml")
+	assert.NoError(t, err)
+
+	_, err = f.FetchFeed(context.Background(), server.URL+"/page")
+	var contentTypeErr *ContentTypeError
+	require.ErrorAs(t, err, &contentTypeErr)
+	assert.Equal(t, "text/html", contentTypeErr.ContentType)
+}
+
+func TestFetchHTMLBoundsFetchesPerHost(t *testing.T) {
+	var mu sync.Mutex
+	running := make(map[string]int)
+	most := make(map[string]int)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hostname := strings.Split(r.Host, ":")[0]
+		mu.Lock()
+		requests++
+		running[hostname]++
+		most[hostname] = max(most[hostname], running[hostname])
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		running[hostname]--
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Story</title></head></html>"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(Options{AllowPrivateNetworks: true, PerHost: 2})
+
+	// The server answers as 127.0.0.1 and as localhost, two hosts to the fetcher
+	other := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		for _, base := range []string{server.URL, other} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := f.FetchHTML(context.Background(), base+"/story")
+				assert.NoError(t, err)
+			}()
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, most["127.0.0.1"], "no more than two fetches from one host at once")
+	assert.Equal(t, 2, most["localhost"], "each host has its own limit")
+
+	// A fetch waiting for a slot gives up, without a request, when its context ends
+	started, release := make(chan struct{}), make(chan struct{})
+	blocked := NewFetcher(Options{AllowPrivateNetworks: true, PerHost: 1})
+	go blocked.hosts.Do(context.Background(), "127.0.0.1", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	defer close(release)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := blocked.FetchHTML(ctx, server.URL+"/story")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 12, requests)
+}
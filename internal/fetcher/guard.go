@@ -0,0 +1,135 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// BlockedAddressError is returned when an outbound request targets a non-public address
+type BlockedAddressError struct {
+	Host    string
+	Address string
+}
+
+func (e *BlockedAddressError) Error() string {
+	if e.Address != "" && e.Address != e.Host {
+		return fmt.Sprintf("%s: %s resolves to %s", ErrBlockedAddress, e.Host, e.Address)
+	}
+	return fmt.Sprintf("%s: %s", ErrBlockedAddress, e.Host)
+}
+
+// Unwrap allows errors.Is(err, ErrBlockedAddress)
+func (e *BlockedAddressError) Unwrap() error {
+	return ErrBlockedAddress
+}
+
+// blockedPrefixes are special-purpose ranges not covered by the netip helpers
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+}
+
+// IsBlockedIP reports whether an IP is loopback, private, link-local or otherwise non-public
+func IsBlockedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return true
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL rejects non-HTTP schemes and hosts that are literal non-public IPs.
+// Hostnames are checked again after DNS resolution when the connection is dialed.
+func checkURL(u *url.URL, allowPrivate bool) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	if allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+
+	lowerHost := strings.ToLower(host)
+	if lowerHost == "localhost" || strings.HasSuffix(lowerHost, ".localhost") {
+		return &BlockedAddressError{Host: host}
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil && IsBlockedIP(ip) {
+		return &BlockedAddressError{Host: host, Address: ip.String()}
+	}
+
+	return nil
+}
+
+// newTransport returns a transport whose dialer refuses non-public addresses.
+// The check runs on the resolved IP, so DNS names pointing at internal hosts
+// (and redirects to them) are caught as well.
+func newTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if IsBlockedIP(ip) {
+				return &BlockedAddressError{Host: host, Address: ip.String()}
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Never route through an environment proxy; the guard must see the real destination
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, address)
+		var blocked *BlockedAddressError
+		if errors.As(err, &blocked) {
+			// Report the requested hostname alongside the resolved IP
+			blocked.Host, _, _ = net.SplitHostPort(address)
+			return nil, blocked
+		}
+		return conn, err
+	}
+	return transport
+}
@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			result := IsBlockedIP(netip.MustParseAddr(tt.ip))
+			if result != tt.expected {
+				t.Errorf("IsBlockedIP(%s) = %v, expected %v", tt.ip, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		blocked bool
+		wantErr bool
+	}{
+		{"https://example.com/article", false, false},
+		{"http://localhost:8080/", true, true},
+		{"http://api.localhost/", true, true},
+		{"http://169.254.169.254/latest/meta-data/", true, true},
+		{"http://[::1]/", true, true},
+		{"file:///etc/passwd", false, true},
+		{"ftp://example.com/", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("Failed to parse URL: %v", err)
+			}
+
+			err = checkURL(u, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkURL(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if errors.Is(err, ErrBlockedAddress) != tt.blocked {
+				t.Errorf("checkURL(%s) blocked = %v, expected %v", tt.url, errors.Is(err, ErrBlockedAddress), tt.blocked)
+			}
+		})
+	}
+}
@@ -1,14 +1,24 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"open-news/internal/bluesky"
+	"open-news/internal/domains"
+	"open-news/internal/embeddings"
+	"open-news/internal/facts"
+	"open-news/internal/feeds"
 	"open-news/internal/models"
 	"open-news/internal/services"
+	"open-news/internal/summary"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,31 +30,113 @@ type AdminHandler struct {
 	db                 *gorm.DB
 	userFollowsService *services.UserFollowsService
 	articlesService    *services.ArticlesService
+	apiKeyService      *services.APIKeyService
+	analyticsService   *services.AnalyticsService
+	preferencesService *services.PreferencesService
+	profileService     *services.SourceProfileService
+	verification       *services.SourceVerificationService
+	spam               *services.SpamService
+	facts              *services.FactsService
+	embeddings         *services.EmbeddingService
+	summaries          *services.SummaryService
+	domains            *domains.Registry
+	jobService         *services.JobService
+	adminUsers         *services.AdminUserService
+	registry           *feeds.Registry
+	feedService        *feeds.FeedService
+	retention          *services.RetentionService
+	snapshots          *services.SnapshotService
+	scoreHistory       *services.ScoreHistoryService
+	tenants            *services.TenantService
 }
 
-// NewAdminHandler creates a new admin handler
-func NewAdminHandler(db *gorm.DB, userFollowsService *services.UserFollowsService, articlesService *services.ArticlesService) *AdminHandler {
+// NewAdminHandler creates a new admin handler. tenants is shared with
+// TenantMiddleware, so tenant changes are served right away.
+func NewAdminHandler(db *gorm.DB, userFollowsService *services.UserFollowsService, articlesService *services.ArticlesService, blueskyClient *bluesky.Client, tenants *services.TenantService) *AdminHandler {
 	return &AdminHandler{
 		db:                 db,
 		userFollowsService: userFollowsService,
 		articlesService:    articlesService,
+		apiKeyService:      services.NewAPIKeyService(db),
+		analyticsService:   services.NewAnalyticsService(db),
+		preferencesService: services.NewPreferencesService(db),
+		profileService:     services.NewSourceProfileService(db, blueskyClient),
+		verification:       services.NewSourceVerificationService(db),
+		spam:               services.NewSpamService(db),
+		retention:          services.NewRetentionService(db, services.LoadRetentionConfig()),
+		facts:              services.NewFactsService(db, facts.FromEnv()),
+		embeddings:         services.NewEmbeddingService(db, embeddings.FromEnv()),
+		summaries:          services.NewSummaryService(db, summary.FromEnv()),
+		domains:            domains.NewRegistry(db),
+		jobService:         services.NewJobService(db),
+		adminUsers:         services.NewAdminUserService(db),
+		registry:           feeds.NewRegistry(db),
+		feedService:        feeds.NewFeedService(db),
+		snapshots:          services.NewSnapshotService(db),
+		scoreHistory:       services.NewScoreHistoryService(db),
+		tenants:            tenants,
 	}
 }
 
-// AdminAuth middleware for basic password protection
-func (h *AdminHandler) AdminAuth() gin.HandlerFunc {
-	return gin.BasicAuth(gin.Accounts{
-		"admin": getAdminPassword(),
-	})
+// adminPage holds the fields the admin layout needs on every page
+type adminPage struct {
+	Title      string
+	ActivePath string
+	Theme      Theme
+	Username   string // Signed-in account; empty on the login page
+	Role       string
+	Brand      branding // The tenant's name, logo and accent color
+}
+
+// newAdminPage builds the layout data for an admin page
+func newAdminPage(c *gin.Context, title, activePath string) adminPage {
+	page := adminPage{
+		Title:      title,
+		ActivePath: activePath,
+		Theme:      themeFromRequest(c),
+		Brand:      brandingFor(c),
+	}
+	if user := currentAdmin(c); user != nil {
+		page.Username, page.Role = user.Username, user.Role
+	}
+	return page
+}
+
+// IsAdmin reports whether the signed-in account has the admin role
+func (p adminPage) IsAdmin() bool {
+	return p.Role == models.AdminRoleAdmin
+}
+
+// adminPagination is the data passed to the admin_pagination partial
+type adminPagination struct {
+	Page       int
+	TotalPages int
+	Total      int64
+	BasePath   string
+}
+
+// newAdminPagination computes page counts for a paginated admin list
+func newAdminPagination(page, limit int, total int64, basePath string) adminPagination {
+	return adminPagination{
+		Page:       page,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		Total:      total,
+		BasePath:   basePath,
+	}
 }
 
-// getAdminPassword returns the admin password from environment or default
-func getAdminPassword() string {
-	password := os.Getenv("ADMIN_PASSWORD")
-	if password == "" {
-		password = "admin123" // Default password for development
+func (p adminPagination) HasPrev() bool { return p.Page > 1 }
+func (p adminPagination) HasNext() bool { return p.Page < p.TotalPages }
+func (p adminPagination) PrevPage() int { return p.Page - 1 }
+func (p adminPagination) NextPage() int { return p.Page + 1 }
+
+// adminPageNumber reads the ?page= parameter, defaulting to the first page
+func adminPageNumber(c *gin.Context) int {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
 	}
-	return password
+	return page
 }
 
 // ServeAdminDashboard serves the main admin dashboard
@@ -62,14 +154,24 @@ func (h *AdminHandler) ServeAdminDashboard(c *gin.Context) {
 		Limit(5).
 		Find(&recentArticles)
 
-	html := h.generateAdminDashboardHTML(userCount, sourceCount, articleCount, recentArticles)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+	renderPage(c, "dashboard", http.StatusOK, struct {
+		adminPage
+		UserCount      int64
+		SourceCount    int64
+		ArticleCount   int64
+		RecentArticles []models.Article
+	}{
+		adminPage:      newAdminPage(c, "", "/admin"),
+		UserCount:      userCount,
+		SourceCount:    sourceCount,
+		ArticleCount:   articleCount,
+		RecentArticles: recentArticles,
+	})
 }
 
 // ServeUsersPage serves the users management page
 func (h *AdminHandler) ServeUsersPage(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page := adminPageNumber(c)
 	limit := 20
 	offset := (page - 1) * limit
 
@@ -82,14 +184,20 @@ func (h *AdminHandler) ServeUsersPage(c *gin.Context) {
 		Offset(offset).
 		Find(&users)
 
-	html := h.generateUsersPageHTML(users, page, limit, totalUsers)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+	renderPage(c, "users", http.StatusOK, struct {
+		adminPage
+		Users      []models.User
+		Pagination adminPagination
+	}{
+		adminPage:  newAdminPage(c, "Users", "/admin/users"),
+		Users:      users,
+		Pagination: newAdminPagination(page, limit, totalUsers, "/admin/users"),
+	})
 }
 
 // ServeSourcesPage serves the sources management page
 func (h *AdminHandler) ServeSourcesPage(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page := adminPageNumber(c)
 	limit := 20
 	offset := (page - 1) * limit
 
@@ -102,559 +210,434 @@ func (h *AdminHandler) ServeSourcesPage(c *gin.Context) {
 		Offset(offset).
 		Find(&sources)
 
-	html := h.generateSourcesPageHTML(sources, page, limit, totalSources)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+	renderPage(c, "sources", http.StatusOK, struct {
+		adminPage
+		Sources    []models.Source
+		Pagination adminPagination
+	}{
+		adminPage:  newAdminPage(c, "Sources", "/admin/sources"),
+		Sources:    sources,
+		Pagination: newAdminPagination(page, limit, totalSources, "/admin/sources"),
+	})
 }
 
-// ServeArticlesPage serves the articles management page
-func (h *AdminHandler) ServeArticlesPage(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit := 20
-	offset := (page - 1) * limit
+// addSourceRequest is the body of POST /admin/api/sources
+type addSourceRequest struct {
+	Actor    string `json:"actor" binding:"required"` // Handle or DID
+	Backfill bool   `json:"backfill"`                 // Import the source's recent link posts
+}
 
-	var articles []models.Article
-	var totalArticles int64
+// AddSource creates a source from a handle or DID without anyone following it, and
+// optionally queues a backfill of its recent link posts
+// POST /admin/api/sources
+func (h *AdminHandler) AddSource(c *gin.Context) {
+	var req addSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "actor (handle or DID) is required")
+		return
+	}
 
-	h.db.Model(&models.Article{}).Count(&totalArticles)
-	h.db.Preload("SourceArticles.Source").
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&articles)
+	source, created, err := h.profileService.AddSource(req.Actor)
+	if errors.Is(err, services.ErrProfileNotFound) {
+		respondError(c, http.StatusNotFound, "No Bluesky profile found for " + req.Actor)
+		return
+	} else if err != nil {
+		respondError(c, http.StatusBadGateway, err.Error())
+		return
+	}
 
-	html := h.generateArticlesPageHTML(articles, page, limit, totalArticles)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+	response := gin.H{
+		"success": true,
+		"created": created,
+		"source":  source,
+	}
+	if req.Backfill {
+		job, err := h.jobService.Enqueue(services.JobTypeBackfillSource, services.BackfillSourcePayload{SourceID: source.ID}, time.Now())
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Source saved but backfill could not be queued: " + err.Error())
+			return
+		}
+		response["backfill_job_id"] = job.ID
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, response)
 }
 
-// generateAdminDashboardHTML generates the main admin dashboard
-func (h *AdminHandler) generateAdminDashboardHTML(userCount, sourceCount, articleCount int64, recentArticles []models.Article) string {
-	return `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>open.news Admin</title>
-    <link rel="stylesheet" href="/static/feed.css">
-    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap" rel="stylesheet">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.0.0/css/all.min.css">
-    <style>
-        .admin-nav {
-            background: #1e293b;
-            padding: 1rem 0;
-            margin-bottom: 2rem;
-        }
-        .admin-nav .nav-container {
-            max-width: 1200px;
-            margin: 0 auto;
-            padding: 0 1rem;
-            display: flex;
-            align-items: center;
-            gap: 2rem;
-        }
-        .admin-nav .nav-brand {
-            color: #f1f5f9;
-            font-weight: 700;
-            font-size: 1.25rem;
-        }
-        .admin-nav .nav-links {
-            display: flex;
-            gap: 1rem;
-        }
-        .admin-nav .nav-link {
-            color: #cbd5e1;
-            text-decoration: none;
-            padding: 0.5rem 1rem;
-            border-radius: 6px;
-            transition: all 0.2s;
-        }
-        .admin-nav .nav-link:hover,
-        .admin-nav .nav-link.active {
-            background: #3b82f6;
-            color: white;
-        }
-        .stats-grid {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(250px, 1fr));
-            gap: 1.5rem;
-            margin-bottom: 2rem;
-        }
-        .stat-card {
-            background: white;
-            padding: 1.5rem;
-            border-radius: 12px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-            text-align: center;
-        }
-        .stat-number {
-            font-size: 2.5rem;
-            font-weight: 700;
-            color: #3b82f6;
-            margin-bottom: 0.5rem;
-        }
-        .stat-label {
-            color: #64748b;
-            font-weight: 500;
-        }
-        .recent-activity {
-            background: white;
-            padding: 1.5rem;
-            border-radius: 12px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-        }
-        .activity-item {
-            padding: 1rem 0;
-            border-bottom: 1px solid #e2e8f0;
-        }
-        .activity-item:last-child {
-            border-bottom: none;
-        }
-    </style>
-</head>
-<body>
-    <nav class="admin-nav">
-        <div class="nav-container">
-            <div class="nav-brand">
-                <i class="fas fa-shield-alt"></i> open.news Admin
-            </div>
-            <div class="nav-links">
-                <a href="/admin" class="nav-link active">Dashboard</a>
-                <a href="/admin/users" class="nav-link">Users</a>
-                <a href="/admin/sources" class="nav-link">Sources</a>
-                <a href="/admin/articles" class="nav-link">Articles</a>
-                <a href="/" class="nav-link">← Back to Site</a>
-            </div>
-        </div>
-    </nav>
-
-    <div class="main-content">
-        <h1>Admin Dashboard</h1>
-        
-        <div class="stats-grid">
-            <div class="stat-card">
-                <div class="stat-number">` + strconv.FormatInt(userCount, 10) + `</div>
-                <div class="stat-label">Users</div>
-            </div>
-            <div class="stat-card">
-                <div class="stat-number">` + strconv.FormatInt(sourceCount, 10) + `</div>
-                <div class="stat-label">Sources</div>
-            </div>
-            <div class="stat-card">
-                <div class="stat-number">` + strconv.FormatInt(articleCount, 10) + `</div>
-                <div class="stat-label">Articles</div>
-            </div>
-        </div>
-
-        <div class="recent-activity">
-            <h2>Recent Articles</h2>
-            ` + h.generateRecentArticlesHTML(recentArticles) + `
-        </div>
-    </div>
-</body>
-</html>`
+// ListPendingVerifications lists the sources verified automatically that are
+// waiting for review
+// GET /admin/api/sources/verification
+func (h *AdminHandler) ListPendingVerifications(c *gin.Context) {
+	sources, err := h.verification.PendingReview(100)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sources": sources})
 }
 
-// generateRecentArticlesHTML generates HTML for recent articles
-func (h *AdminHandler) generateRecentArticlesHTML(articles []models.Article) string {
-	if len(articles) == 0 {
-		return `<p>No articles found.</p>`
+// VerifySource checks a source against the news sites it shares now, rather than
+// waiting for the hourly batch
+// POST /admin/api/sources/:id/verify
+func (h *AdminHandler) VerifySource(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid source ID")
+		return
+	}
+	var source models.Source
+	if err := h.db.Where("id = ?", id).First(&source).Error; err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Source not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	html := ""
-	for _, article := range articles {
-		sourceName := "Unknown Source"
-		if len(article.SourceArticles) > 0 && article.SourceArticles[0].Source.ID != uuid.Nil {
-			sourceName = article.SourceArticles[0].Source.DisplayName
-		}
+	match, err := h.verification.Verify(&source)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"match":   match,
+		"source":  source,
+	})
+}
 
-		html += `
-        <div class="activity-item">
-            <div style="display: flex; justify-content: space-between; align-items: flex-start;">
-                <div>
-                    <h4 style="margin: 0 0 0.5rem 0;">` + article.Title + `</h4>
-                    <p style="margin: 0; color: #64748b; font-size: 0.875rem;">
-                        by ` + sourceName + ` • ` + article.CreatedAt.Format("Jan 2, 3:04 PM") + `
-                    </p>
-                </div>
-                <div style="background: #f1f5f9; padding: 0.25rem 0.5rem; border-radius: 4px; font-size: 0.75rem;">
-                    Score: ` + strconv.FormatFloat(article.QualityScore, 'f', 1, 64) + `
-                </div>
-            </div>
-        </div>`
-	}
-
-	return html
+// reviewVerificationRequest is the body of ReviewSourceVerification
+type reviewVerificationRequest struct {
+	Approve bool `json:"approve"`
 }
 
-// generateUsersPageHTML generates the users management page
-func (h *AdminHandler) generateUsersPageHTML(users []models.User, page, limit int, total int64) string {
-	html := h.generateAdminLayout("Users", `/admin/users`)
-	
-	html += `
-        <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 1.5rem;">
-            <h1>Users (` + strconv.FormatInt(total, 10) + `)</h1>
-        </div>
-
-        <div style="background: white; border-radius: 12px; overflow: hidden; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
-            <table style="width: 100%; border-collapse: collapse;">
-                <thead style="background: #f8fafc;">
-                    <tr>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Handle</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Display Name</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">DID</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Active</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Last Refresh</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Actions</th>
-                    </tr>
-                </thead>
-                <tbody>`
-
-	for _, user := range users {
-		activeStatus := "❌"
-		if user.IsActive {
-			activeStatus = "✅"
-		}
+// ReviewSourceVerification approves or rejects a source's verification
+// POST /admin/api/sources/:id/verification
+func (h *AdminHandler) ReviewSourceVerification(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid source ID")
+		return
+	}
+	var req reviewVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
 
-		lastRefresh := "Never"
-		if !user.FollowsLastRefreshed.IsZero() {
-			lastRefresh = user.FollowsLastRefreshed.Format("Jan 2, 15:04")
-		}
+	source, err := h.verification.Review(id, req.Approve)
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Source not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Printf("🔎 %s set verification of %s to %s", adminActor(c), source.Handle, source.VerificationStatus)
 
-		html += `
-                    <tr style="border-bottom: 1px solid #f1f5f9;">
-                        <td style="padding: 1rem;">@` + user.Handle + `</td>
-                        <td style="padding: 1rem;">` + user.DisplayName + `</td>
-                        <td style="padding: 1rem; font-family: monospace; font-size: 0.875rem;">` + user.BlueSkyDID[:20] + `...</td>
-                        <td style="padding: 1rem;">` + activeStatus + `</td>
-                        <td style="padding: 1rem;">` + lastRefresh + `</td>
-                        <td style="padding: 1rem;">
-                            <button onclick="refreshUserFollows('` + user.Handle + `')" 
-                                    style="background: #3b82f6; color: white; border: none; padding: 0.5rem 1rem; border-radius: 6px; cursor: pointer; font-size: 0.875rem;">
-                                🔄 Refresh
-                            </button>
-                        </td>
-                    </tr>`
-	}
-
-	html += `
-                </tbody>
-            </table>
-        </div>
-
-        ` + h.generatePagination(page, limit, total, "/admin/users") + `
-    </div>
-
-    <script>
-        function refreshUserFollows(userHandle) {
-            const button = event.target;
-            const originalText = button.innerHTML;
-            
-            // Show loading state
-            button.innerHTML = '⏳ Refreshing...';
-            button.disabled = true;
-            
-            fetch('/admin/refresh-follows/' + encodeURIComponent(userHandle), {
-                method: 'POST',
-                headers: {
-                    'Content-Type': 'application/json',
-                }
-            })
-            .then(response => response.json())
-            .then(data => {
-                if (data.success) {
-                    button.innerHTML = '✅ Done';
-                    button.style.background = '#10b981';
-                    setTimeout(() => {
-                        button.innerHTML = originalText;
-                        button.style.background = '#3b82f6';
-                        button.disabled = false;
-                        // Reload the page to show updated refresh time
-                        window.location.reload();
-                    }, 2000);
-                } else {
-                    button.innerHTML = '❌ Error';
-                    button.style.background = '#ef4444';
-                    setTimeout(() => {
-                        button.innerHTML = originalText;
-                        button.style.background = '#3b82f6';
-                        button.disabled = false;
-                    }, 3000);
-                    alert('Error: ' + (data.error || 'Unknown error'));
-                }
-            })
-            .catch(error => {
-                button.innerHTML = '❌ Error';
-                button.style.background = '#ef4444';
-                setTimeout(() => {
-                    button.innerHTML = originalText;
-                    button.style.background = '#3b82f6';
-                    button.disabled = false;
-                }, 3000);
-                alert('Network error: ' + error.message);
-            });
-        }
-    </script>
-</body>
-</html>`
-
-	return html
+	c.JSON(http.StatusOK, gin.H{
+		"success":             true,
+		"is_verified":         source.IsVerified,
+		"verification_status": source.VerificationStatus,
+	})
 }
 
-// generateSourcesPageHTML generates the sources management page
-func (h *AdminHandler) generateSourcesPageHTML(sources []models.Source, page, limit int, total int64) string {
-	html := h.generateAdminLayout("Sources", `/admin/sources`)
-	
-	html += `
-        <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 1.5rem;">
-            <h1>Sources (` + strconv.FormatInt(total, 10) + `)</h1>
-        </div>
-
-        <div style="background: white; border-radius: 12px; overflow: hidden; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
-            <table style="width: 100%; border-collapse: collapse;">
-                <thead style="background: #f8fafc;">
-                    <tr>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Handle</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Display Name</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Quality Score</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Verified</th>
-                        <th style="padding: 1rem; text-align: left; border-bottom: 1px solid #e2e8f0;">Created</th>
-                    </tr>
-                </thead>
-                <tbody>`
-
-	for _, source := range sources {
-		verifiedStatus := "❌"
-		if source.IsVerified {
-			verifiedStatus = "✅"
-		}
+// ListFlaggedSources lists the sources flagged as spam that are waiting for review
+// GET /admin/api/sources/spam
+func (h *AdminHandler) ListFlaggedSources(c *gin.Context) {
+	sources, err := h.spam.Flagged(100)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sources": sources})
+}
 
-		qualityClass := "background: #fef2f2; color: #991b1b;" // Low
-		if source.QualityScore >= 0.7 {
-			qualityClass = "background: #f0fdf4; color: #166534;" // High
-		} else if source.QualityScore >= 0.5 {
-			qualityClass = "background: #fefce8; color: #a16207;" // Medium
-		}
+// ListDuplicateClusters lists groups of recent articles whose embeddings are
+// nearly identical, such as copies of one wire story
+// GET /admin/api/articles/duplicates?hours=24&similarity=0.95
+func (h *AdminHandler) ListDuplicateClusters(c *gin.Context) {
+	if !h.embeddings.Enabled() {
+		respondError(c, http.StatusServiceUnavailable, "Embeddings are not enabled")
+		return
+	}
+	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if hours < 1 || hours > 168 {
+		hours = 24
+	}
+	similarity, err := strconv.ParseFloat(c.DefaultQuery("similarity", "0.95"), 64)
+	if err != nil || similarity <= 0 || similarity > 1 {
+		respondError(c, http.StatusBadRequest, "similarity must be between 0 and 1")
+		return
+	}
 
-		html += `
-                    <tr style="border-bottom: 1px solid #f1f5f9;">
-                        <td style="padding: 1rem;">@` + source.Handle + `</td>
-                        <td style="padding: 1rem;">` + source.DisplayName + `</td>
-                        <td style="padding: 1rem;">
-                            <span style="padding: 0.25rem 0.5rem; border-radius: 4px; font-size: 0.875rem; ` + qualityClass + `">
-                                ` + strconv.FormatFloat(source.QualityScore, 'f', 2, 64) + `
-                            </span>
-                        </td>
-                        <td style="padding: 1rem;">` + verifiedStatus + `</td>
-                        <td style="padding: 1rem;">` + source.CreatedAt.Format("Jan 2, 2006") + `</td>
-                    </tr>`
-	}
-
-	html += `
-                </tbody>
-            </table>
-        </div>
-
-        ` + h.generatePagination(page, limit, total, "/admin/sources") + `
-    </div>
-</body>
-</html>`
-
-	return html
+	clusters, err := h.embeddings.DuplicateClusters(time.Now().Add(-time.Duration(hours)*time.Hour), similarity)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if clusters == nil {
+		clusters = []services.ArticleCluster{}
+	}
+	c.JSON(http.StatusOK, gin.H{"clusters": clusters})
 }
 
-// generateArticlesPageHTML generates the articles management page
-func (h *AdminHandler) generateArticlesPageHTML(articles []models.Article, page, limit int, total int64) string {
-	html := h.generateAdminLayout("Articles", `/admin/articles`)
-	
-	html += `
-        <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 1.5rem;">
-            <h1>Articles (` + strconv.FormatInt(total, 10) + `)</h1>
-        </div>
-
-        <div style="background: white; border-radius: 12px; padding: 1.5rem; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">`
+// GetRetention shows the retention periods and what recent retention runs purged
+// GET /admin/api/retention
+func (h *AdminHandler) GetRetention(c *gin.Context) {
+	runs, err := h.retention.Runs(30)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if runs == nil {
+		runs = []models.RetentionRun{}
+	}
+	c.JSON(http.StatusOK, gin.H{"config": h.retention.Config(), "runs": runs})
+}
 
-	for _, article := range articles {
-		sourceName := "Unknown Source"
-		if len(article.SourceArticles) > 0 && article.SourceArticles[0].Source.ID != uuid.Nil {
-			sourceName = article.SourceArticles[0].Source.DisplayName
+// maxSnapshotUpload is the largest snapshot ImportSnapshot accepts
+const maxSnapshotUpload = 512 << 20
+
+// ExportSnapshot downloads the global feeds, their articles and sources as a
+// snapshot (?format=ndjson or json; ?all=true for every article, ?days=N to
+// limit those to the last N days; ?content=true to include cached pages)
+// GET /admin/api/snapshot
+func (h *AdminHandler) ExportSnapshot(c *gin.Context) {
+	options := services.ExportOptions{
+		Format:         c.DefaultQuery("format", services.SnapshotNDJSON),
+		AllArticles:    c.Query("all") == "true",
+		IncludeContent: c.Query("content") == "true",
+	}
+	if options.Format != services.SnapshotNDJSON && options.Format != services.SnapshotJSON {
+		respondError(c, http.StatusBadRequest, "format must be ndjson or json")
+		return
+	}
+	if days := c.Query("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			respondError(c, http.StatusBadRequest, "days must be a positive number")
+			return
 		}
+		options.Since = time.Now().AddDate(0, 0, -n)
+	}
 
-		qualityClass := "background: #fef2f2; color: #991b1b;" // Low
-		if article.QualityScore >= 0.7 {
-			qualityClass = "background: #f0fdf4; color: #166534;" // High
-		} else if article.QualityScore >= 0.5 {
-			qualityClass = "background: #fefce8; color: #a16207;" // Medium
-		}
+	contentType := "application/x-ndjson"
+	if options.Format == services.SnapshotJSON {
+		contentType = "application/json"
+	}
+	filename := fmt.Sprintf("opennews-snapshot-%s.%s", time.Now().UTC().Format("20060102-150405"), options.Format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	if err := h.snapshots.Export(c.Writer, options); err != nil {
+		// The response has started, so the error can only be logged
+		log.Printf("Failed to export snapshot: %v", err)
+	}
+}
 
-		html += `
-            <div style="border-bottom: 1px solid #e2e8f0; padding: 1.5rem 0;">
-                <div style="display: flex; justify-content: space-between; align-items: flex-start; gap: 1rem;">
-                    <div style="flex: 1;">
-                        <h3 style="margin: 0 0 0.5rem 0;">
-                            <a href="` + article.URL + `" target="_blank" style="color: #3b82f6; text-decoration: none;">
-                                ` + article.Title + `
-                            </a>
-                        </h3>
-                        <p style="margin: 0 0 0.5rem 0; color: #64748b; line-height: 1.5;">` + article.Description + `</p>
-                        <div style="display: flex; align-items: center; gap: 1rem; font-size: 0.875rem; color: #64748b;">
-                            <span>by ` + sourceName + `</span>
-                            <span>•</span>
-                            <span>` + article.CreatedAt.Format("Jan 2, 2006 3:04 PM") + `</span>
-                            <span style="padding: 0.25rem 0.5rem; border-radius: 4px; ` + qualityClass + `">
-                                Score: ` + strconv.FormatFloat(article.QualityScore, 'f', 1, 64) + `
-                            </span>`
-
-		// Add fetch status indicator
-		if !article.IsReachable {
-			html += `
-                            <span style="padding: 0.25rem 0.5rem; border-radius: 4px; background: #fef2f2; color: #991b1b; border: 1px solid #fecaca;">
-                                ❌ Unreachable
-                            </span>`
-		}
+// ImportSnapshot loads a snapshot, in either format, from the request body
+// POST /admin/api/snapshot
+func (h *AdminHandler) ImportSnapshot(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotUpload)
+	result, err := h.snapshots.Import(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	log.Printf("Imported snapshot: %d sources, %d articles, %d shares, %d feeds", result.Sources, result.Articles, result.Shares, result.Feeds)
+	c.JSON(http.StatusOK, result)
+}
 
-		html += `
-                            <span>•</span>
-                            <a href="/admin/articles/` + article.ID.String() + `" 
-                               style="color: #3b82f6; text-decoration: none; padding: 0.25rem 0.5rem; background: #eff6ff; border-radius: 4px; border: 1px solid #dbeafe;">
-                                🔍 Inspect
-                            </a>
-                        </div>
-                    </div>`
-
-		if article.ImageURL != "" {
-			html += `
-                    <img src="` + article.ImageURL + `" 
-                         alt="Article image" 
-                         style="width: 120px; height: 120px; object-fit: cover; border-radius: 8px; flex-shrink: 0;">`
-		}
+// reviewSpamRequest is the body of ReviewSourceSpam
+type reviewSpamRequest struct {
+	Spam bool `json:"spam"`
+}
 
-		html += `
-                </div>
-            </div>`
+// ReviewSourceSpam confirms a flagged source as spam, or clears it
+// POST /admin/api/sources/:id/spam
+func (h *AdminHandler) ReviewSourceSpam(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid source ID")
+		return
+	}
+	var req reviewSpamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
 	}
 
-	html += `
-        </div>
+	source, err := h.spam.Review(id, req.Spam)
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Source not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Printf("🚩 %s set spam status of %s to %s", adminActor(c), source.Handle, source.SpamStatus)
 
-        ` + h.generatePagination(page, limit, total, "/admin/articles") + `
-    </div>
-</body>
-</html>`
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"spam_status": source.SpamStatus,
+	})
+}
 
-	return html
+// ListDomains lists the news sites in the domains table
+// GET /admin/api/domains
+func (h *AdminHandler) ListDomains(c *gin.Context) {
+	list, err := h.domains.List()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"domains": list})
 }
 
-// generateAdminLayout generates the common admin layout
-func (h *AdminHandler) generateAdminLayout(title, activePath string) string {
-	return `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>` + title + ` - open.news Admin</title>
-    <link rel="stylesheet" href="/static/feed.css">
-    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap" rel="stylesheet">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.0.0/css/all.min.css">
-    <style>
-        .admin-nav {
-            background: #1e293b;
-            padding: 1rem 0;
-            margin-bottom: 2rem;
-        }
-        .admin-nav .nav-container {
-            max-width: 1200px;
-            margin: 0 auto;
-            padding: 0 1rem;
-            display: flex;
-            align-items: center;
-            gap: 2rem;
-        }
-        .admin-nav .nav-brand {
-            color: #f1f5f9;
-            font-weight: 700;
-            font-size: 1.25rem;
-        }
-        .admin-nav .nav-links {
-            display: flex;
-            gap: 1rem;
-        }
-        .admin-nav .nav-link {
-            color: #cbd5e1;
-            text-decoration: none;
-            padding: 0.5rem 1rem;
-            border-radius: 6px;
-            transition: all 0.2s;
-        }
-        .admin-nav .nav-link:hover,
-        .admin-nav .nav-link.active {
-            background: #3b82f6;
-            color: white;
-        }
-    </style>
-</head>
-<body>
-    <nav class="admin-nav">
-        <div class="nav-container">
-            <div class="nav-brand">
-                <i class="fas fa-shield-alt"></i> open.news Admin
-            </div>
-            <div class="nav-links">
-                <a href="/admin" class="nav-link` + h.getActiveClass("/admin", activePath) + `">Dashboard</a>
-                <a href="/admin/users" class="nav-link` + h.getActiveClass("/admin/users", activePath) + `">Users</a>
-                <a href="/admin/sources" class="nav-link` + h.getActiveClass("/admin/sources", activePath) + `">Sources</a>
-                <a href="/admin/articles" class="nav-link` + h.getActiveClass("/admin/articles", activePath) + `">Articles</a>
-                <a href="/" class="nav-link">← Back to Site</a>
-            </div>
-        </div>
-    </nav>
-
-    <div class="main-content">`
+// saveDomainRequest is the body of SaveDomain
+type saveDomainRequest struct {
+	Domain    string   `json:"domain" binding:"required"`
+	Name      string   `json:"name"`
+	Score     *float64 `json:"score" binding:"required"`
+	Category  string   `json:"category"`
+	Country   string   `json:"country"`
+	IsBlocked bool     `json:"is_blocked"`
+
+	SchemaRequirement string `json:"schema_requirement"` // "require", "prefer", "ignore" or "" for NEWSARTICLE_SCHEMA
+
+	IngestFeeds bool     `json:"ingest_feeds"` // Store articles from the site's feeds before they're shared
+	FeedURLs    []string `json:"feed_urls"`    // RSS or Atom feeds or sitemaps; empty uses those the home page advertises
 }
 
-// getActiveClass returns " active" if the path matches
-func (h *AdminHandler) getActiveClass(path, activePath string) string {
-	if path == activePath {
-		return " active"
+// SaveDomain adds a news site to the domains table or replaces its entry.
+// Article scores pick the change up at the next metrics update.
+// POST /admin/api/domains
+func (h *AdminHandler) SaveDomain(c *gin.Context) {
+	var req saveDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "domain and score are required")
+		return
 	}
-	return ""
+
+	domain, err := h.domains.Save(models.Domain{
+		Domain:    req.Domain,
+		Name:      req.Name,
+		Score:     *req.Score,
+		Category:  req.Category,
+		Country:   req.Country,
+		IsBlocked: req.IsBlocked,
+
+		SchemaRequirement: req.SchemaRequirement,
+
+		IngestFeeds: req.IngestFeeds,
+		FeedURLs:    req.FeedURLs,
+	})
+	if errors.Is(err, domains.ErrInvalidDomain) || errors.Is(err, domains.ErrInvalidScore) || errors.Is(err, domains.ErrInvalidSchemaRequirement) || errors.Is(err, domains.ErrInvalidFeedURL) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "domain": domain})
 }
 
-// generatePagination generates pagination controls
-func (h *AdminHandler) generatePagination(currentPage, limit int, total int64, basePath string) string {
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
-	
-	if totalPages <= 1 {
-		return ""
+// DeleteDomain removes a news site from the domains table, so it scores as unknown
+// DELETE /admin/api/domains/:domain
+func (h *AdminHandler) DeleteDomain(c *gin.Context) {
+	err := h.domains.Delete(c.Param("domain"))
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Domain not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
 
-	html := `
-    <div style="display: flex; justify-content: center; gap: 0.5rem; margin-top: 2rem; padding-top: 2rem; border-top: 1px solid #e2e8f0;">
-        ` + h.getPaginationButton(basePath, currentPage-1, "Previous", currentPage <= 1) + `
-        <span style="padding: 0.5rem 1rem; background: #3b82f6; color: white; border-radius: 6px;">
-            Page ` + strconv.Itoa(currentPage) + ` of ` + strconv.Itoa(totalPages) + `
-        </span>
-        ` + h.getPaginationButton(basePath, currentPage+1, "Next", currentPage >= totalPages) + `
-    </div>`
+// ServeArticlesPage serves the articles management page
+func (h *AdminHandler) ServeArticlesPage(c *gin.Context) {
+	page := adminPageNumber(c)
+	limit := 20
+	offset := (page - 1) * limit
+
+	var articles []models.Article
+	var totalArticles int64
+
+	h.db.Model(&models.Article{}).Count(&totalArticles)
+	h.db.Preload("SourceArticles.Source").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&articles)
+
+	renderPage(c, "articles", http.StatusOK, struct {
+		adminPage
+		Articles   []models.Article
+		Pagination adminPagination
+	}{
+		adminPage:  newAdminPage(c, "Articles", "/admin/articles"),
+		Articles:   articles,
+		Pagination: newAdminPagination(page, limit, totalArticles, "/admin/articles"),
+	})
+}
+
+// ServeJobsPage lists background jobs that ran out of attempts
+func (h *AdminHandler) ServeJobsPage(c *gin.Context) {
+	page := adminPageNumber(c)
+	limit := 20
+	offset := (page - 1) * limit
+
+	jobs, totalJobs, err := h.jobService.ListFailed(limit, offset)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to list jobs: %v", err)
+		return
+	}
 
-	return html
+	renderPage(c, "jobs", http.StatusOK, struct {
+		adminPage
+		Jobs       []models.Job
+		Pagination adminPagination
+	}{
+		adminPage:  newAdminPage(c, "Failed Jobs", "/admin/jobs"),
+		Jobs:       jobs,
+		Pagination: newAdminPagination(page, limit, totalJobs, "/admin/jobs"),
+	})
 }
 
-// getPaginationButton generates a pagination button
-func (h *AdminHandler) getPaginationButton(basePath string, page int, text string, disabled bool) string {
-	if disabled {
-		return `<span style="padding: 0.5rem 1rem; background: #f1f5f9; color: #94a3b8; border-radius: 6px;">` + text + `</span>`
+// RetryJob queues a failed job to run again
+// POST /admin/jobs/:id/retry
+func (h *AdminHandler) RetryJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	if err := h.jobService.Retry(id); err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Failed job not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
 	}
-	return `<a href="` + basePath + `?page=` + strconv.Itoa(page) + `" style="padding: 0.5rem 1rem; background: white; color: #3b82f6; border: 1px solid #e2e8f0; border-radius: 6px; text-decoration: none; transition: all 0.2s;" onmouseover="this.style.background='#f1f5f9'" onmouseout="this.style.background='white'">` + text + `</a>`
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Job queued for retry",
+	})
 }
 
 // RefreshUserFollows handles manual refresh of user follows
 func (h *AdminHandler) RefreshUserFollows(c *gin.Context) {
 	userIdentifier := c.Param("user")
 	if userIdentifier == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user identifier (handle or DID) is required"})
+		respondError(c, http.StatusBadRequest, "user identifier (handle or DID) is required")
 		return
 	}
 
@@ -681,13 +664,13 @@ func (h *AdminHandler) RefreshUserFollows(c *gin.Context) {
 	}
 	
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		respondError(c, http.StatusNotFound, "User not found")
 		return
 	}
 
 	// Import follows
 	if err := h.userFollowsService.ImportUserFollows(&user, config); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh follows: " + err.Error()})
+		respondError(c, http.StatusInternalServerError, "Failed to refresh follows: " + err.Error())
 		return
 	}
 
@@ -707,7 +690,7 @@ func (h *AdminHandler) RefreshAllUserFollows(c *gin.Context) {
 	}
 
 	if err := h.userFollowsService.RefreshBatch(config); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh follows: " + err.Error()})
+		respondError(c, http.StatusInternalServerError, "Failed to refresh follows: " + err.Error())
 		return
 	}
 
@@ -730,7 +713,9 @@ func (h *AdminHandler) ServeArticleInspection(c *gin.Context) {
 
 	// Get article with all related data
 	var article models.Article
-	result := h.db.Preload("SourceArticles.Source").
+	result := h.db.Preload("SourceArticles", func(db *gorm.DB) *gorm.DB {
+		return db.Order("posted_at")
+	}).Preload("SourceArticles.Source").
 		Preload("Facts").
 		First(&article, id)
 	
@@ -743,16 +728,262 @@ func (h *AdminHandler) ServeArticleInspection(c *gin.Context) {
 		return
 	}
 
-	html := h.generateArticleInspectionHTML(article)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+	view := newArticleInspectionView(c, article)
+	if view.AuditLog, err = h.articlesService.ArticleAuditLog(article.ID); err != nil {
+		log.Printf("Failed to load audit log for article %s: %v", article.ID, err)
+	}
+	history, err := h.scoreHistory.ForArticle(article.ID, time.Now().AddDate(0, 0, -defaultScoreHistoryDays))
+	if err != nil {
+		log.Printf("Failed to load score history for article %s: %v", article.ID, err)
+	}
+	view.setScoreHistory(history)
+	renderPage(c, "article", http.StatusOK, view)
+}
+
+// RefetchArticle fetches an article's page again immediately and returns its new fetch status
+// POST /admin/articles/:id/refetch
+func (h *AdminHandler) RefetchArticle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	article, err := h.articlesService.RefetchArticle(c.Request.Context(), id)
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       article.IsReachable,
+		"is_reachable":  article.IsReachable,
+		"fetch_error":   article.FetchError,
+		"fetch_retries": article.FetchRetries,
+		"last_fetch_at": article.LastFetchAt,
+		"title":         article.Title,
+		"quality_score": article.QualityScore,
+	})
+}
+
+// ExtractArticleFacts extracts an article's facts again and returns them
+// POST /admin/articles/:id/facts
+func (h *AdminHandler) ExtractArticleFacts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	found, err := h.facts.ExtractArticle(c.Request.Context(), id)
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"facts":   found,
+	})
+}
+
+// SummarizeArticle writes an article's summary again
+// POST /admin/articles/:id/summary
+func (h *AdminHandler) SummarizeArticle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	if !h.summaries.Enabled() {
+		respondError(c, http.StatusServiceUnavailable, "Summaries are turned off")
+		return
+	}
+
+	text, err := h.summaries.SummarizeArticle(c.Request.Context(), id)
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"summary": text,
+	})
+}
+
+// pinArticleRequest is the body of PinArticle
+type pinArticleRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+// PinArticle pins an article to the top of the global feed, or unpins it
+// POST /admin/articles/:id/pin
+func (h *AdminHandler) PinArticle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	var req pinArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	article, err := h.articlesService.SetPinned(id, req.Pinned, adminActor(c))
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"is_pinned": article.IsPinned,
+		"pinned_at": article.PinnedAt,
+	})
+}
+
+// boostArticleRequest is the body of BoostArticle
+type boostArticleRequest struct {
+	Boost *float64 `json:"boost" binding:"required"`
+}
+
+// BoostArticle sets the editorial boost or penalty added to an article's quality score
+// POST /admin/articles/:id/boost
+func (h *AdminHandler) BoostArticle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	var req boostArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "boost is required")
+		return
+	}
+
+	article, err := h.articlesService.SetEditorialBoost(id, *req.Boost, adminActor(c))
+	if errors.Is(err, services.ErrInvalidBoost) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":         true,
+		"editorial_boost": article.EditorialBoost,
+		"quality_score":   article.QualityScore,
+	})
+}
+
+// bulkArticlesRequest is the body of the bulk article actions
+type bulkArticlesRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// bindBulkArticleIDs parses the selected article IDs, writing an error response
+// and returning false if they're missing or invalid
+func bindBulkArticleIDs(c *gin.Context) ([]uuid.UUID, bool) {
+	var req bulkArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.IDs) == 0 {
+		respondError(c, http.StatusBadRequest, "ids must list at least one article ID")
+		return nil, false
+	}
+	if len(req.IDs) > services.MaxBulkArticles {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("At most %d articles can be selected at once", services.MaxBulkArticles))
+		return nil, false
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid article ID: " + raw)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// bulkArticlesResponse writes the outcome of a bulk action
+func bulkArticlesResponse(c *gin.Context, result services.BulkArticleResult) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   len(result.Failed) == 0,
+		"processed": result.Processed,
+		"failed":    result.Failed,
+	})
+}
+
+// BulkDeleteArticles deletes the selected articles
+// POST /admin/api/articles/bulk-delete
+func (h *AdminHandler) BulkDeleteArticles(c *gin.Context) {
+	ids, ok := bindBulkArticleIDs(c)
+	if !ok {
+		return
+	}
+	bulkArticlesResponse(c, h.articlesService.DeleteArticles(ids))
+}
+
+// BulkRefetchArticles queues a re-fetch of each selected article
+// POST /admin/api/articles/bulk-refetch
+func (h *AdminHandler) BulkRefetchArticles(c *gin.Context) {
+	ids, ok := bindBulkArticleIDs(c)
+	if !ok {
+		return
+	}
+	bulkArticlesResponse(c, h.articlesService.QueueRefetches(h.jobService, ids))
+}
+
+// BulkRescoreArticles recalculates the quality scores of the selected articles
+// POST /admin/api/articles/bulk-rescore
+func (h *AdminHandler) BulkRescoreArticles(c *gin.Context) {
+	ids, ok := bindBulkArticleIDs(c)
+	if !ok {
+		return
+	}
+	bulkArticlesResponse(c, h.articlesService.RescoreArticles(ids))
+}
+
+// BulkMarkNotNews marks the selected articles as not news, removing them from feeds
+// POST /admin/api/articles/bulk-not-news
+func (h *AdminHandler) BulkMarkNotNews(c *gin.Context) {
+	ids, ok := bindBulkArticleIDs(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.articlesService.MarkNotNews(ids)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	bulkArticlesResponse(c, services.BulkArticleResult{Processed: int(marked)})
 }
 
 // InspectURL provides URL inspection for debugging article validation
 func (h *AdminHandler) InspectURL(c *gin.Context) {
 	url := c.Query("url")
 	if url == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url parameter is required"})
+		respondError(c, http.StatusBadRequest, "url parameter is required")
 		return
 	}
 
@@ -774,295 +1005,96 @@ func (h *AdminHandler) InspectURL(c *gin.Context) {
 	})
 }
 
-// generateArticleInspectionHTML generates the detailed article inspection page
-func (h *AdminHandler) generateArticleInspectionHTML(article models.Article) string {
-	html := h.generateAdminLayout("Article Inspection", "/admin/articles")
-	
-	// Determine quality score styling
-	qualityClass := "background: #fef2f2; color: #991b1b; border: 1px solid #fecaca;" // Low
-	qualityIcon := "⚠️"
-	if article.QualityScore >= 0.7 {
-		qualityClass = "background: #f0fdf4; color: #166534; border: 1px solid #bbf7d0;" // High
-		qualityIcon = "✅"
-	} else if article.QualityScore >= 0.5 {
-		qualityClass = "background: #fefce8; color: #a16207; border: 1px solid #fde68a;" // Medium
-		qualityIcon = "⚡"
-	}
-
-	// Get source information
-	sourceName := "Unknown Source"
-	sourceHandle := "N/A"
-	sourceDID := "N/A"
-	postURI := "N/A"
+// articleInspectionView is the data passed to the article inspection page
+type articleInspectionView struct {
+	adminPage
+	Article      models.Article
+	SourceName   string
+	SourceHandle string
+	SourceDID    string
+	PostURI      string
+	RawJSON      string
+	AuditLog     []models.ArticleAuditLog
+
+	// The article's scores over the last week, charted from score_history
+	ScoreSnapshots    int
+	QualitySparkline  sparkline
+	TrendingSparkline sparkline
+}
+
+// setScoreHistory charts an article's score snapshots, oldest first
+func (v *articleInspectionView) setScoreHistory(history []models.ScoreHistory) {
+	quality := make([]float64, len(history))
+	trending := make([]float64, len(history))
+	for i, snapshot := range history {
+		quality[i] = snapshot.QualityScore
+		trending[i] = snapshot.TrendingScore
+	}
+	v.ScoreSnapshots = len(history)
+	v.QualitySparkline = newSparkline("Quality score", quality)
+	v.TrendingSparkline = newSparkline("Trending score", trending)
+}
+
+// newArticleInspectionView gathers source details and a debug JSON dump for an article
+func newArticleInspectionView(c *gin.Context, article models.Article) articleInspectionView {
+	view := articleInspectionView{
+		adminPage:    newAdminPage(c, "Article Inspection", "/admin/articles"),
+		Article:      article,
+		SourceName:   "Unknown Source",
+		SourceHandle: "N/A",
+		SourceDID:    "N/A",
+		PostURI:      "N/A",
+	}
+
 	if len(article.SourceArticles) > 0 {
 		sourceArticle := article.SourceArticles[0]
 		if sourceArticle.Source.ID != uuid.Nil {
-			sourceName = sourceArticle.Source.DisplayName
-			sourceHandle = sourceArticle.Source.Handle
-			sourceDID = sourceArticle.Source.BlueSkyDID
+			view.SourceName = sourceArticle.Source.DisplayName
+			view.SourceHandle = sourceArticle.Source.Handle
+			view.SourceDID = sourceArticle.Source.BlueSkyDID
 		}
-		postURI = sourceArticle.PostURI
-	}
-
-	html += `
-        <div style="margin-bottom: 1.5rem;">
-            <a href="/admin/articles" style="color: #3b82f6; text-decoration: none; font-size: 0.875rem;">
-                ← Back to Articles
-            </a>
-        </div>
-
-        <div style="background: white; border-radius: 12px; padding: 2rem; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
-            <div style="border-bottom: 1px solid #e2e8f0; padding-bottom: 1.5rem; margin-bottom: 1.5rem;">
-                <h1 style="margin: 0 0 1rem 0; color: #1e293b; font-size: 1.5rem;">Article Inspection</h1>
-                <div style="padding: 1rem; border-radius: 8px; ` + qualityClass + `">
-                    <strong>` + qualityIcon + ` Quality Score: ` + strconv.FormatFloat(article.QualityScore, 'f', 3, 64) + `</strong>
-                </div>
-            </div>
-
-            <!-- Article Content -->
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Content</h2>
-                <div style="display: grid; gap: 1rem;">
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Title:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.Title + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Description:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0; line-height: 1.5;">` + article.Description + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">URL:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">
-                            <a href="` + article.URL + `" target="_blank" style="color: #3b82f6; text-decoration: none;">` + article.URL + `</a>
-                        </div>
-                    </div>`
-	
-	if article.ImageURL != "" {
-		html += `
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Image:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">
-                            <a href="` + article.ImageURL + `" target="_blank" style="color: #3b82f6; text-decoration: none;">` + article.ImageURL + `</a><br>
-                            <img src="` + article.ImageURL + `" alt="Article image" style="max-width: 200px; max-height: 200px; object-fit: cover; border-radius: 6px; margin-top: 0.5rem;">
-                        </div>
-                    </div>`
-	}
-
-	html += `
-                </div>
-            </div>
-
-            <!-- Metadata -->
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Metadata</h2>
-                <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 1rem;">
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Word Count:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + strconv.Itoa(article.WordCount) + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Reading Time:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + strconv.Itoa(article.ReadingTime) + ` min</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Language:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.Language + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Site Name:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.SiteName + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Author:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.Author + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Created:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.CreatedAt.Format("Jan 2, 2006 3:04:05 PM") + `</div>
-                    </div>
-                </div>
-            </div>
-
-            <!-- Fetch Status -->
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Fetch Status</h2>
-                <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 1rem;">`
-
-	// Fetch status styling
-	reachableClass := "background: #f0fdf4; color: #166534; border: 1px solid #bbf7d0;" // Green for reachable
-	reachableIcon := "✅"
-	reachableText := "Reachable"
-	
-	if !article.IsReachable {
-		reachableClass = "background: #fef2f2; color: #991b1b; border: 1px solid #fecaca;" // Red for unreachable
-		reachableIcon = "❌"
-		reachableText = "Unreachable"
-	}
-
-	html += `
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Status:</label>
-                        <div style="padding: 0.75rem; border-radius: 6px; ` + reachableClass + `">` + reachableIcon + ` ` + reachableText + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Fetch Retries:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + strconv.Itoa(article.FetchRetries) + `</div>
-                    </div>`
-
-	if article.LastFetchAt != nil {
-		html += `
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Last Fetch Attempt:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.LastFetchAt.Format("Jan 2, 2006 3:04:05 PM") + `</div>
-                    </div>`
-	}
-
-	if article.LastFetchError != nil {
-		html += `
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Last Error Time:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + article.LastFetchError.Format("Jan 2, 2006 3:04:05 PM") + `</div>
-                    </div>`
-	}
-
-	if article.FetchError != "" {
-		html += `
-                    <div style="grid-column: 1 / -1;">
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Last Error Message:</label>
-                        <div style="padding: 0.75rem; background: #fef2f2; border-radius: 6px; border: 1px solid #fecaca; font-family: monospace; font-size: 0.875rem; color: #991b1b;">` + article.FetchError + `</div>
-                    </div>`
-	}
-
-	html += `
-                </div>
-            </div>
-
-            <!-- Source Information -->
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Source Information</h2>
-                <div style="display: grid; gap: 1rem;">
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Source Name:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + sourceName + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Bluesky Handle:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0;">` + sourceHandle + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Bluesky DID:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0; font-family: monospace; font-size: 0.875rem;">` + sourceDID + `</div>
-                    </div>
-                    <div>
-                        <label style="font-weight: 600; color: #374151; display: block; margin-bottom: 0.5rem;">Post URI:</label>
-                        <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0; font-family: monospace; font-size: 0.875rem; word-break: break-all;">` + postURI + `</div>
-                    </div>
-                </div>
-            </div>`
-
-	// Article Facts section
-	if len(article.Facts) > 0 {
-		html += `
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Article Facts</h2>
-                <div style="display: grid; gap: 0.5rem;">`
-
-		for _, fact := range article.Facts {
-			html += `
-                    <div style="padding: 0.75rem; background: #f8fafc; border-radius: 6px; border: 1px solid #e2e8f0; display: flex; justify-content: space-between; align-items: center;">
-                        <span style="font-weight: 500;">` + fact.FactType + `: ` + fact.FactText + `</span>
-                        <span style="font-family: monospace; background: #e2e8f0; padding: 0.25rem 0.5rem; border-radius: 4px;">Confidence: ` + strconv.FormatFloat(fact.Confidence, 'f', 3, 64) + `</span>
-                    </div>`
-		}
-
-		html += `
-                </div>
-            </div>`
-	}
-
-	// JSON-LD and Open Graph Data sections for article type analysis
-	html += `
-            <!-- Structured Data Analysis -->
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Structured Data Analysis</h2>
-                <p style="color: #6b7280; font-size: 0.875rem; margin-bottom: 1rem;">
-                    This data helps determine if the content is actually a news article. Look for "@type": "NewsArticle" in JSON-LD or article-related Open Graph tags.
-                </p>`
-
-	// JSON-LD Data
-	if article.JSONLDData != "" {
-		html += `
-                <details style="margin-bottom: 1rem;">
-                    <summary style="cursor: pointer; padding: 0.75rem; background: #f0f9ff; border: 1px solid #bae6fd; border-radius: 6px; font-weight: 600; color: #0c4a6e;">
-                        📊 JSON-LD Structured Data
-                    </summary>
-                    <div style="margin-top: 0.5rem; padding: 1rem; background: #1e293b; border-radius: 6px; border: 1px solid #334155;">
-                        <pre style="color: #e2e8f0; font-size: 0.875rem; line-height: 1.4; white-space: pre-wrap; word-wrap: break-word; margin: 0; overflow-x: auto;">` + article.JSONLDData + `</pre>
-                    </div>
-                </details>`
-	} else {
-		html += `
-                <div style="padding: 0.75rem; background: #fef2f2; border: 1px solid #fecaca; border-radius: 6px; margin-bottom: 1rem;">
-                    <span style="color: #991b1b;">⚠️ No JSON-LD structured data found</span>
-                </div>`
-	}
-
-	// Open Graph Data
-	if article.OGData != "" {
-		html += `
-                <details style="margin-bottom: 1rem;">
-                    <summary style="cursor: pointer; padding: 0.75rem; background: #f0fdf4; border: 1px solid #bbf7d0; border-radius: 6px; font-weight: 600; color: #166534;">
-                        🏷️ Open Graph Metadata
-                    </summary>
-                    <div style="margin-top: 0.5rem; padding: 1rem; background: #1e293b; border-radius: 6px; border: 1px solid #334155;">
-                        <pre style="color: #e2e8f0; font-size: 0.875rem; line-height: 1.4; white-space: pre-wrap; word-wrap: break-word; margin: 0; overflow-x: auto;">` + article.OGData + `</pre>
-                    </div>
-                </details>`
-	} else {
-		html += `
-                <div style="padding: 0.75rem; background: #fef2f2; border: 1px solid #fecaca; border-radius: 6px; margin-bottom: 1rem;">
-                    <span style="color: #991b1b;">⚠️ No Open Graph metadata found</span>
-                </div>`
+		view.PostURI = sourceArticle.PostURI
 	}
 
-	html += `
-            </div>`
-
-	// Raw JSON section for debugging
-	html += `
-            <div style="margin-bottom: 2rem;">
-                <h2 style="color: #1e293b; margin-bottom: 1rem; border-bottom: 2px solid #e2e8f0; padding-bottom: 0.5rem;">Raw Data (JSON)</h2>
-                <details style="margin-bottom: 1rem;">
-                    <summary style="cursor: pointer; padding: 0.5rem; background: #f8fafc; border-radius: 6px; font-weight: 500;">Article JSON</summary>
-                    <pre style="background: #1e293b; color: #e2e8f0; padding: 1rem; border-radius: 6px; overflow-x: auto; margin-top: 0.5rem; font-size: 0.875rem; line-height: 1.4;">`
-
 	// Create a simplified JSON representation for display
-	html += `{
-  "id": "` + article.ID.String() + `",
-  "url": "` + article.URL + `",
-  "title": "` + article.Title + `",
-  "description": "` + article.Description + `",
-  "image_url": "` + article.ImageURL + `",
-  "site_name": "` + article.SiteName + `",
-  "author": "` + article.Author + `",
-  "language": "` + article.Language + `",
-  "word_count": ` + strconv.Itoa(article.WordCount) + `,
-  "reading_time": ` + strconv.Itoa(article.ReadingTime) + `,
-  "quality_score": ` + strconv.FormatFloat(article.QualityScore, 'f', 6, 64) + `,
-  "created_at": "` + article.CreatedAt.Format(time.RFC3339) + `",
-  "updated_at": "` + article.UpdatedAt.Format(time.RFC3339) + `"
-}`
-
-	html += `</pre>
-                </details>
-            </div>
-        </div>
-    </div>
-</body>
-</html>`
-
-	return html
+	var raw bytes.Buffer
+	encoder := json.NewEncoder(&raw)
+	encoder.SetEscapeHTML(false) // The template escapes the output
+	encoder.SetIndent("", "  ")
+	encoder.Encode(struct {
+		ID           uuid.UUID `json:"id"`
+		URL          string    `json:"url"`
+		Title        string    `json:"title"`
+		Description  string    `json:"description"`
+		ImageURL     string    `json:"image_url"`
+		SiteName     string    `json:"site_name"`
+		Author       string    `json:"author"`
+		Authors      []string  `json:"authors"`
+		Language     string    `json:"language"`
+		WordCount    int       `json:"word_count"`
+		ReadingTime  int       `json:"reading_time"`
+		QualityScore float64   `json:"quality_score"`
+		CreatedAt    string    `json:"created_at"`
+		UpdatedAt    string    `json:"updated_at"`
+	}{
+		ID:           article.ID,
+		URL:          article.URL,
+		Title:        article.Title,
+		Description:  article.Description,
+		ImageURL:     article.ImageURL,
+		SiteName:     article.SiteName,
+		Author:       article.Author,
+		Authors:      article.Authors,
+		Language:     article.Language,
+		WordCount:    article.WordCount,
+		ReadingTime:  article.ReadingTime,
+		QualityScore: article.QualityScore,
+		CreatedAt:    article.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    article.UpdatedAt.Format(time.RFC3339),
+	})
+	view.RawJSON = strings.TrimSpace(raw.String())
+
+	return view
 }
 
 // ValidateArticles validates existing articles and optionally removes invalid ones
@@ -1070,9 +1102,7 @@ func (h *AdminHandler) ValidateArticles(c *gin.Context) {
 	dryRun := c.DefaultQuery("dry_run", "true") == "true"
 	
 	if err := h.articlesService.ValidateAndCleanupExistingArticles(dryRun); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Validation failed: %v", err),
-		})
+		respondError(c, http.StatusInternalServerError, fmt.Sprintf("Validation failed: %v", err))
 		return
 	}
 	
@@ -1086,3 +1116,140 @@ func (h *AdminHandler) ValidateArticles(c *gin.Context) {
 		"dry_run": dryRun,
 	})
 }
+
+// ListAPIKeys returns all widget API keys
+func (h *AdminHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to list API keys: " + err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+	})
+}
+
+// CreateAPIKey creates a widget API key. The plaintext key is only returned here.
+func (h *AdminHandler) CreateAPIKey(c *gin.Context) {
+	name := c.PostForm("name")
+	if name == "" {
+		respondError(c, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	key, apiKey, err := h.apiKeyService.CreateAPIKey(name, c.PostForm("allowed_origins"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create API key: " + err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key created. Store it now; it will not be shown again.",
+		"key":     key,
+		"api_key": apiKey,
+	})
+}
+
+// RevokeAPIKey deactivates a widget API key
+func (h *AdminHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(id); err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "API key not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked",
+	})
+}
+
+// GetClickAnalytics reports clicks per article, source and feed
+// GET /admin/analytics/clicks?days=<n>&limit=<n>
+func (h *AdminHandler) GetClickAnalytics(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days < 1 || days > 90 {
+		days = 7
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	report, err := h.analyticsService.ClickReport(time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SetSeenFilter opts a user in or out of hiding articles they've already been served
+// POST /admin/users/:id/seen-filter (show_seen=true|false)
+func (h *AdminHandler) SetSeenFilter(c *gin.Context) {
+	user, err := h.findUser(c.Param("id"))
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	showSeen, err := strconv.ParseBool(c.PostForm("show_seen"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "show_seen must be true or false")
+		return
+	}
+
+	if err := h.preferencesService.SetShowSeenArticles(user.ID, showSeen); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Seen-article preference updated",
+		"user_id":   user.ID,
+		"show_seen": showSeen,
+	})
+}
+
+// SetDigestSubscription subscribes a user to the top-stories digest direct
+// messages, or unsubscribes them
+// POST /admin/users/:id/digest (subscribed=true|false)
+func (h *AdminHandler) SetDigestSubscription(c *gin.Context) {
+	user, err := h.findUser(c.Param("id"))
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	subscribed, err := strconv.ParseBool(c.PostForm("subscribed"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "subscribed must be true or false")
+		return
+	}
+
+	if err := h.preferencesService.SetDigestSubscribed(user.ID, subscribed); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Digest subscription updated",
+		"user_id":    user.ID,
+		"subscribed": subscribed,
+	})
+}
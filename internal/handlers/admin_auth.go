@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// adminSessionCookie holds the signed-in admin's session token
+	adminSessionCookie = "open_news_admin_session"
+
+	// adminUserKey is the context key AdminAuth stores the signed-in account under
+	adminUserKey = "adminUser"
+)
+
+// AdminAuth requires a signed-in admin account. Page requests without a session
+// are sent to the login page; API requests get a 401.
+func (h *AdminHandler) AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(adminSessionCookie)
+		user, err := tenantAdminUsers(c, h.adminUsers).ValidateSession(token)
+		if err != nil {
+			if c.Request.Method == http.MethodGet && strings.Contains(c.GetHeader("Accept"), "text/html") {
+				c.Redirect(http.StatusSeeOther, "/admin/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+			} else {
+				respondError(c, http.StatusUnauthorized, "Sign in at /admin/login")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(adminUserKey, user)
+		c.Next()
+	}
+}
+
+// RequireRole rejects signed-in accounts whose role doesn't include role.
+// It must run after AdminAuth.
+func (h *AdminHandler) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := currentAdmin(c)
+		if user == nil || !user.HasRole(role) {
+			abortWithError(c, http.StatusForbidden, "This action needs the "+role+" role")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireDefaultTenant rejects requests for tenants other than the default one,
+// for settings shared by the whole deployment such as API keys and snapshots
+func (h *AdminHandler) RequireDefaultTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenant := currentTenant(c); tenant != nil && !tenant.IsDefault() {
+			abortWithError(c, http.StatusForbidden, "This action is only available to the deployment's operators")
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentAdmin returns the account signed in for this request, or nil
+func currentAdmin(c *gin.Context) *models.AdminUser {
+	if value, ok := c.Get(adminUserKey); ok {
+		if user, ok := value.(*models.AdminUser); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// adminActor names the admin account making a request, for audit logs
+func adminActor(c *gin.Context) string {
+	if user := currentAdmin(c); user != nil {
+		return user.Username
+	}
+	return ""
+}
+
+// loginView is the data passed to the login page
+type loginView struct {
+	adminPage
+	Next  string
+	Error string
+}
+
+// ServeLoginPage serves the admin sign-in form
+// GET /admin/login
+func (h *AdminHandler) ServeLoginPage(c *gin.Context) {
+	renderPage(c, "login", http.StatusOK, loginView{
+		adminPage: newAdminPage(c, "Sign In", ""),
+		Next:      safeAdminRedirect(c.Query("next")),
+	})
+}
+
+// Login checks the submitted credentials and starts a session
+// POST /admin/login
+func (h *AdminHandler) Login(c *gin.Context) {
+	next := safeAdminRedirect(c.PostForm("next"))
+
+	token, _, err := tenantAdminUsers(c, h.adminUsers).SignIn(c.PostForm("username"), c.PostForm("password"))
+	if err != nil {
+		message := "Sign in failed, please try again"
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			message, status = "Invalid username or password", http.StatusUnauthorized
+		}
+		renderPage(c, "login", status, loginView{
+			adminPage: newAdminPage(c, "Sign In", ""),
+			Next:      next,
+			Error:     message,
+		})
+		return
+	}
+
+	h.setSessionCookie(c, token, int(h.adminUsers.SessionTTL().Seconds()))
+	c.Redirect(http.StatusSeeOther, next)
+}
+
+// Logout ends the current session
+// POST /admin/logout
+func (h *AdminHandler) Logout(c *gin.Context) {
+	if token, err := c.Cookie(adminSessionCookie); err == nil {
+		h.adminUsers.SignOut(token)
+	}
+	h.setSessionCookie(c, "", -1)
+	c.Redirect(http.StatusSeeOther, "/admin/login")
+}
+
+// setSessionCookie writes the session cookie; a negative maxAge deletes it.
+// The cookie is marked Secure when the request came over HTTPS.
+func (h *AdminHandler) setSessionCookie(c *gin.Context, token string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(adminSessionCookie, token, maxAge, "/admin", "", secure, true)
+}
+
+// safeAdminRedirect returns next if it's a path within the admin, so the login
+// form can't be used to redirect elsewhere
+func safeAdminRedirect(next string) string {
+	if strings.HasPrefix(next, "/admin") && !strings.HasPrefix(next, "/admin/login") && !strings.Contains(next, "//") && !strings.Contains(next, `\`) {
+		return next
+	}
+	return "/admin/"
+}
+
+// ServeAccountsPage lists admin accounts
+// GET /admin/accounts
+func (h *AdminHandler) ServeAccountsPage(c *gin.Context) {
+	accounts, err := tenantAdminUsers(c, h.adminUsers).ListUsers()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to list accounts: %v", err)
+		return
+	}
+
+	renderPage(c, "accounts", http.StatusOK, struct {
+		adminPage
+		Accounts []models.AdminUser
+		Roles    []string
+	}{
+		adminPage: newAdminPage(c, "Admin Accounts", "/admin/accounts"),
+		Accounts:  accounts,
+		Roles:     []string{models.AdminRoleViewer, models.AdminRoleModerator, models.AdminRoleAdmin},
+	})
+}
+
+// createAccountRequest is the body of CreateAccount
+type createAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// CreateAccount creates an admin account
+// POST /admin/api/accounts
+func (h *AdminHandler) CreateAccount(c *gin.Context) {
+	var req createAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "username, password and role are required")
+		return
+	}
+
+	account, err := tenantAdminUsers(c, h.adminUsers).CreateUser(req.Username, req.Password, req.Role)
+	if errors.Is(err, services.ErrInvalidAdminRole) || errors.Is(err, services.ErrWeakPassword) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "account": account})
+}
+
+// updateAccountRequest is the body of UpdateAccount; omitted fields are left unchanged
+type updateAccountRequest struct {
+	Role     *string `json:"role"`
+	IsActive *bool   `json:"is_active"`
+	Password *string `json:"password"`
+}
+
+// UpdateAccount changes an admin account's role, active flag or password
+// POST /admin/api/accounts/:id
+func (h *AdminHandler) UpdateAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+	var req updateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminUsers := tenantAdminUsers(c, h.adminUsers)
+	if req.Role != nil {
+		err = adminUsers.SetRole(id, *req.Role)
+	}
+	if err == nil && req.IsActive != nil {
+		err = adminUsers.SetActive(id, *req.IsActive)
+	}
+	if err == nil && req.Password != nil {
+		err = adminUsers.SetPassword(id, *req.Password)
+	}
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	case err == gorm.ErrRecordNotFound:
+		respondError(c, http.StatusNotFound, "Account not found")
+	case errors.Is(err, services.ErrInvalidAdminRole), errors.Is(err, services.ErrWeakPassword), errors.Is(err, services.ErrLastAdmin):
+		respondError(c, http.StatusBadRequest, err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, err.Error())
+	}
+}
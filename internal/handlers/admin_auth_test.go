@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-news/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeAdminRedirect(t *testing.T) {
+	tests := []struct {
+		next     string
+		expected string
+	}{
+		{"/admin/articles?page=2", "/admin/articles?page=2"},
+		{"", "/admin/"},
+		{"https://evil.example/admin", "/admin/"},
+		{"//evil.example/admin", "/admin/"},
+		{"/admin//evil.example", "/admin/"},
+		{`/admin\evil`, "/admin/"},
+		{"/admin/login", "/admin/"},
+		{"/feed/global", "/admin/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.next, func(t *testing.T) {
+			assert.Equal(t, tt.expected, safeAdminRedirect(tt.next))
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &AdminHandler{}
+
+	tests := []struct {
+		name     string
+		user     *models.AdminUser
+		expected int
+	}{
+		{"no account", nil, http.StatusForbidden},
+		{"viewer", &models.AdminUser{Role: models.AdminRoleViewer, IsActive: true}, http.StatusForbidden},
+		{"moderator", &models.AdminUser{Role: models.AdminRoleModerator, IsActive: true}, http.StatusOK},
+		{"admin", &models.AdminUser{Role: models.AdminRoleAdmin, IsActive: true}, http.StatusOK},
+		{"disabled admin", &models.AdminUser{Role: models.AdminRoleAdmin}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/admin/action", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(adminUserKey, tt.user)
+				}
+			}, h.RequireRole(models.AdminRoleModerator), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/action", nil))
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"open-news/internal/feeds"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedHealthView is the data passed to the feed health page
+type feedHealthView struct {
+	adminPage
+	Feeds []feeds.FeedHealth
+}
+
+// ServeFeedHealthPage renders the health of every stored feed: when it was
+// last regenerated, its item count and score distribution, how many items have
+// images and publication dates, and the last regeneration error
+// GET /admin/feed-health
+func (h *AdminHandler) ServeFeedHealthPage(c *gin.Context) {
+	report, err := h.feedService.WithContext(c.Request.Context()).Health(time.Now())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load feed health: %v", err)
+		return
+	}
+	renderPage(c, "feed_health", http.StatusOK, feedHealthView{
+		adminPage: newAdminPage(c, "Feed Health", "/admin/feed-health"),
+		Feeds:     report,
+	})
+}
+
+// GetFeedHealth returns the feed health report as JSON
+// GET /admin/api/feed-health
+func (h *AdminHandler) GetFeedHealth(c *gin.Context) {
+	report, err := h.feedService.WithContext(c.Request.Context()).Health(time.Now())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"feeds": report})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// previewItem is one feed item on the admin feed preview page
+type previewItem struct {
+	feeds.FeedItemDetails
+	Breakdown models.ScoreBreakdown
+}
+
+// missedArticle is a recent article from the user's follows that isn't in the preview
+type missedArticle struct {
+	Article models.Article
+	Reason  string
+}
+
+// feedPreviewView is the data passed to the user feed preview page
+type feedPreviewView struct {
+	adminPage
+	User        models.User
+	Definitions []models.FeedDefinition
+	Feed        *models.FeedDefinition
+	Limit       int
+	Items       []previewItem
+	Missed      []missedArticle
+	BuildError  string
+}
+
+// ServeUserFeedPreview renders what a user's feed skeleton would return right now,
+// with a score breakdown for each item and recent follow shares that didn't make it
+// GET /admin/users/:id/feed?feed=<rkey>&limit=<n>
+func (h *AdminHandler) ServeUserFeedPreview(c *gin.Context) {
+	user, err := h.findUser(c.Param("id"))
+	if err == gorm.ErrRecordNotFound {
+		c.String(http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "Database error: "+err.Error())
+		return
+	}
+
+	registry := tenantRegistry(c, h.registry)
+	definitions, err := registry.List()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load feed definitions: "+err.Error())
+		return
+	}
+
+	def, err := registry.Lookup(c.DefaultQuery("feed", "open-news-personal"))
+	if err == feeds.ErrFeedNotFound {
+		c.String(http.StatusNotFound, "Feed not found")
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to look up feed: "+err.Error())
+		return
+	}
+
+	// Match the getFeedSkeleton defaults and bounds
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if limit > 100 {
+		limit = 100
+	}
+	if limit < 1 {
+		limit = 30
+	}
+
+	view := feedPreviewView{
+		adminPage:   newAdminPage(c, "Feed Preview", "/admin/users"),
+		User:        user,
+		Definitions: definitions,
+		Feed:        def,
+		Limit:       limit,
+	}
+
+	feedResponse, err := registry.BuildSkeleton(def, &user.ID, limit, 0)
+	if err != nil {
+		view.BuildError = err.Error()
+		renderPage(c, "user_feed", http.StatusOK, view)
+		return
+	}
+
+	view.Items, err = h.explainFeedItems(ranking.Get(def.Ranker), feedResponse.Items)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load article scores: "+err.Error())
+		return
+	}
+
+	view.Missed, err = h.missedFollowShares(user.ID, feedResponse.Items, limit)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load followed shares: "+err.Error())
+		return
+	}
+
+	renderPage(c, "user_feed", http.StatusOK, view)
+}
+
+// findUser looks a user up by ID, DID or handle
+func (h *AdminHandler) findUser(identifier string) (models.User, error) {
+	var user models.User
+	if id, err := uuid.Parse(identifier); err == nil {
+		err := h.db.First(&user, "id = ?", id).Error
+		return user, err
+	}
+	err := h.db.Where("blue_sky_d_id = ? OR handle = ?", identifier, identifier).First(&user).Error
+	return user, err
+}
+
+// explainFeedItems attaches a score breakdown from the feed's ranker to each feed item
+func (h *AdminHandler) explainFeedItems(ranker ranking.Ranker, items []feeds.FeedItemDetails) ([]previewItem, error) {
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.Article.ID
+	}
+
+	var articles []models.Article
+	if len(ids) > 0 {
+		if err := h.db.Preload("SourceArticles.Source").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	preview := make([]previewItem, len(items))
+	for i, item := range items {
+		preview[i] = previewItem{FeedItemDetails: item}
+		if article, ok := byID[item.Article.ID]; ok {
+			preview[i].Breakdown = ranker.Explain(article, ranking.Signals{FollowedSharers: item.SharedByCount, Domain: h.domains.Lookup(article)})
+		}
+	}
+	return preview, nil
+}
+
+// missedFollowShares lists recent articles shared by the user's follows that aren't
+// in the feed, with the most likely reason each one was left out
+func (h *AdminHandler) missedFollowShares(userID uuid.UUID, items []feeds.FeedItemDetails, limit int) ([]missedArticle, error) {
+	shown := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		shown[item.Article.ID] = true
+	}
+
+	var articles []models.Article
+	err := h.db.Where("articles.id IN (?)", h.db.Table("source_articles").
+		Select("source_articles.article_id").
+		Joins("JOIN user_sources ON user_sources.source_id = source_articles.source_id").
+		Where("user_sources.user_id = ?", userID)).
+		Where("articles.created_at > ?", time.Now().AddDate(0, 0, -7)).
+		Order("articles.created_at DESC").
+		Limit(limit + len(items)).
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions in the global Top Stories feed, which personalized fallbacks draw from
+	positions := make(map[uuid.UUID]int)
+	var globalItems []models.FeedItem
+	h.db.Table("feed_items").
+		Select("feed_items.article_id, feed_items.position").
+		Joins("JOIN feeds ON feeds.id = feed_items.feed_id").
+		Where("feeds.feed_type = ? AND feeds.name = ?", "global", "Top Stories").
+		Find(&globalItems)
+	for _, item := range globalItems {
+		positions[item.ArticleID] = item.Position
+	}
+
+	var missed []missedArticle
+	for _, article := range articles {
+		if shown[article.ID] {
+			continue
+		}
+		missed = append(missed, missedArticle{
+			Article: article,
+			Reason:  missedReason(article, positions, limit),
+		})
+	}
+	return missed, nil
+}
+
+// missedReason explains why an article shared by a follow isn't in a feed
+func missedReason(article models.Article, globalPositions map[uuid.UUID]int, limit int) string {
+	switch {
+	case !article.IsReachable:
+		return "Article is unreachable"
+	case article.QualityScore <= 0:
+		return "Not scored yet (quality score is 0)"
+	}
+	if position, ok := globalPositions[article.ID]; ok {
+		if position > limit {
+			return fmt.Sprintf("Ranked #%d in Top Stories, below the %d-item limit", position, limit)
+		}
+		return fmt.Sprintf("Ranked #%d in Top Stories but filtered out by this feed", position)
+	}
+	return fmt.Sprintf("Not in Top Stories (quality %.2f, trending %.2f)", article.QualityScore, article.TrendingScore)
+}
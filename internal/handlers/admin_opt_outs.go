@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"open-news/internal/domains"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListOptOuts lists publishers' opt-outs, pending and active
+// GET /admin/api/opt-outs
+func (h *AdminHandler) ListOptOuts(c *gin.Context) {
+	list, err := h.domains.OptOuts()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"opt_outs": list})
+}
+
+// addOptOutRequest is the body of AddOptOut
+type addOptOutRequest struct {
+	Domain  string `json:"domain" binding:"required"`
+	Contact string `json:"contact"`
+	Reason  string `json:"reason"`
+}
+
+// AddOptOut opts a domain out on its publisher's behalf, e.g. after a request
+// by email, or activates a pending request without verification
+// POST /admin/api/opt-outs
+func (h *AdminHandler) AddOptOut(c *gin.Context) {
+	var req addOptOutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "domain is required")
+		return
+	}
+
+	optOut, err := h.domains.AddOptOut(req.Domain, req.Contact, req.Reason)
+	if errors.Is(err, domains.ErrInvalidDomain) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "opt_out": optOut})
+}
+
+// DeleteOptOut removes a domain's opt-out, so links to it are stored again
+// DELETE /admin/api/opt-outs/:domain
+func (h *AdminHandler) DeleteOptOut(c *gin.Context) {
+	err := h.domains.DeleteOptOut(c.Param("domain"))
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Opt-out not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
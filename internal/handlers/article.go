@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"open-news/internal/embeddings"
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sitemapMaxURLs is the most URLs a single sitemap file may list
+const sitemapMaxURLs = 50000
+
+// landingRelatedArticles is how many related articles a landing page lists
+const landingRelatedArticles = 5
+
+// defaultScoreHistoryDays is how far back GET /api/articles/:id/history goes
+// without days, which can be at most 30
+const defaultScoreHistoryDays = 7
+
+// ArticleHandler handles article API requests, landing pages and the sitemap
+type ArticleHandler struct {
+	db                  *gorm.DB
+	qualityScoreService *services.QualityScoreService
+	relatedService      *services.RelatedService
+	embeddingService    *services.EmbeddingService
+	scoreHistory        *services.ScoreHistoryService
+	baseURL             string // PUBLIC_BASE_URL; empty uses the request's host
+}
+
+// NewArticleHandler creates a new article handler
+func NewArticleHandler(db *gorm.DB) *ArticleHandler {
+	embeddingService := services.NewEmbeddingService(db, embeddings.FromEnv())
+	return &ArticleHandler{
+		db:                  db,
+		qualityScoreService: services.NewQualityScoreService(db),
+		relatedService:      services.NewRelatedService(db, embeddingService),
+		embeddingService:    embeddingService,
+		scoreHistory:        services.NewScoreHistoryService(db),
+		baseURL:             strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/"),
+	}
+}
+
+// ScoreBreakdownResponse explains an article's current scores
+type ScoreBreakdownResponse struct {
+	ArticleID     uuid.UUID             `json:"article_id"`
+	QualityScore  float64               `json:"quality_score"`
+	TrendingScore float64               `json:"trending_score"`
+	Breakdown     models.ScoreBreakdown `json:"breakdown"`
+	Persisted     bool                  `json:"persisted"` // False when the breakdown was calculated for this request
+}
+
+// GetScoreBreakdown handles GET /api/articles/:id/score-breakdown
+func (h *ArticleHandler) GetScoreBreakdown(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var article models.Article
+	err = h.db.Preload("SourceArticles.Source").First(&article, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	response := ScoreBreakdownResponse{
+		ArticleID:     article.ID,
+		QualityScore:  article.QualityScore,
+		TrendingScore: article.TrendingScore,
+	}
+
+	breakdown, err := models.ParseScoreBreakdown(article.ScoreBreakdownData)
+	if err != nil {
+		log.Printf("Invalid score breakdown stored for article %s: %v", article.ID, err)
+	}
+	if breakdown != nil {
+		response.Breakdown = *breakdown
+		response.Persisted = true
+	} else {
+		// Not scored since breakdowns were recorded; explain the current inputs instead
+		response.Breakdown = h.qualityScoreService.ExplainScores(article)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ArticleSummary is an article as listed alongside another article or an entity
+type ArticleSummary struct {
+	ID           uuid.UUID  `json:"id"`
+	URL          string     `json:"url"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	ImageURL     string     `json:"image_url"`
+	SiteName     string     `json:"site_name"`
+	Authors      []string   `json:"authors"`
+	PublishedAt  *time.Time `json:"published_at"`
+	QualityScore float64    `json:"quality_score"`
+}
+
+// newArticleSummary converts a stored article to its summary
+func newArticleSummary(article models.Article) ArticleSummary {
+	return ArticleSummary{
+		ID:           article.ID,
+		URL:          article.URL,
+		Title:        article.Title,
+		Description:  article.Description,
+		ImageURL:     article.ImageURL,
+		SiteName:     article.SiteName,
+		Authors:      article.Authors,
+		PublishedAt:  article.PublishedAt,
+		QualityScore: article.QualityScore,
+	}
+}
+
+// RelatedArticleEntry is an article covering the same story, with its similarity
+type RelatedArticleEntry struct {
+	ArticleSummary
+	Score float64 `json:"score"`
+}
+
+// RelatedArticlesResponse lists the other coverage of an article, most similar first
+type RelatedArticlesResponse struct {
+	ArticleID uuid.UUID             `json:"article_id"`
+	Articles  []RelatedArticleEntry `json:"articles"`
+}
+
+// GetRelated handles GET /api/articles/:id/related
+func (h *ArticleHandler) GetRelated(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	var query struct {
+		Limit int `form:"limit,default=10" binding:"min=1,max=50"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	limit := query.Limit
+
+	var article models.Article
+	err = h.db.First(&article, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	related, err := h.relatedService.Related(article, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load related articles")
+		return
+	}
+
+	response := RelatedArticlesResponse{ArticleID: id, Articles: make([]RelatedArticleEntry, len(related))}
+	for i, candidate := range related {
+		response.Articles[i] = RelatedArticleEntry{ArticleSummary: newArticleSummary(candidate.Article), Score: candidate.Score}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchArticles handles GET /api/articles/search, finding articles by meaning
+// rather than exact words with the article embeddings
+func (h *ArticleHandler) SearchArticles(c *gin.Context) {
+	var params struct {
+		Q     string `form:"q" binding:"required"`
+		Limit int    `form:"limit,default=20" binding:"min=1,max=50"`
+	}
+	if !bindQuery(c, &params) {
+		return
+	}
+	query := strings.TrimSpace(params.Q)
+	if query == "" {
+		respondInvalid(c, "Invalid query parameters", map[string]string{"q": "is required"})
+		return
+	}
+	if !h.embeddingService.Enabled() {
+		respondError(c, http.StatusServiceUnavailable, "Semantic search is not enabled")
+		return
+	}
+
+	found, err := h.embeddingService.Search(c.Request.Context(), query, params.Limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to search articles")
+		return
+	}
+
+	results := make([]RelatedArticleEntry, len(found))
+	for i, candidate := range found {
+		results[i] = RelatedArticleEntry{ArticleSummary: newArticleSummary(candidate.Article), Score: candidate.Score}
+	}
+	c.JSON(http.StatusOK, gin.H{"query": query, "articles": results})
+}
+
+// ShareTimelineEntry is one post that shared an article
+type ShareTimelineEntry struct {
+	PostURI      string        `json:"post_uri"`
+	PostURL      string        `json:"post_url,omitempty"` // bsky.app page of the post
+	Text         string        `json:"text"`
+	ShareType    string        `json:"share_type"` // post, quote, repost or reply
+	IsRepost     bool          `json:"is_repost"`
+	LikesCount   int           `json:"likes_count"`
+	RepostsCount int           `json:"reposts_count"`
+	RepliesCount int           `json:"replies_count"`
+	PostedAt     time.Time     `json:"posted_at"`
+	Source       models.Source `json:"source"`
+}
+
+// ShareTimelineResponse lists the posts sharing an article, oldest first
+type ShareTimelineResponse struct {
+	ArticleID uuid.UUID            `json:"article_id"`
+	Shares    []ShareTimelineEntry `json:"shares"`
+	Total     int64                `json:"total"`
+	Page      int                  `json:"page"`
+	Limit     int                  `json:"limit"`
+}
+
+// GetShares handles GET /api/articles/:id/shares
+func (h *ArticleHandler) GetShares(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var params struct {
+		Limit int `form:"limit,default=50" binding:"min=1,max=200"`
+		Page  int `form:"page,default=1" binding:"min=1"`
+	}
+	if !bindQuery(c, &params) {
+		return
+	}
+	limit, page := params.Limit, params.Page
+
+	var article models.Article
+	if err := h.db.Select("id").First(&article, "id = ?", id).Error; err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	response := ShareTimelineResponse{ArticleID: id, Shares: []ShareTimelineEntry{}, Page: page, Limit: limit}
+	query := h.db.Model(&models.SourceArticle{}).Where("article_id = ?", id)
+	if err := query.Count(&response.Total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load shares")
+		return
+	}
+
+	var shares []models.SourceArticle
+	err = query.Preload("Source").
+		Order("posted_at, id").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&shares).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load shares")
+		return
+	}
+	for _, share := range shares {
+		response.Shares = append(response.Shares, newShareTimelineEntry(share))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ScoreHistoryResponse lists an article's score snapshots, oldest first
+type ScoreHistoryResponse struct {
+	ArticleID uuid.UUID             `json:"article_id"`
+	Days      int                   `json:"days"`
+	History   []models.ScoreHistory `json:"history"`
+}
+
+// GetScoreHistory handles GET /api/articles/:id/history
+func (h *ArticleHandler) GetScoreHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var query struct {
+		Days int `form:"days" binding:"omitempty,min=1,max=30"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	days := query.Days
+	if days == 0 {
+		days = defaultScoreHistoryDays
+	}
+
+	var article models.Article
+	if err := h.db.Select("id").First(&article, "id = ?", id).Error; err == gorm.ErrRecordNotFound {
+		respondError(c, http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	history, err := h.scoreHistory.ForArticle(id, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load score history")
+		return
+	}
+	if history == nil {
+		history = []models.ScoreHistory{}
+	}
+
+	c.JSON(http.StatusOK, ScoreHistoryResponse{ArticleID: id, Days: days, History: history})
+}
+
+// newShareTimelineEntry converts a stored share to its timeline entry
+func newShareTimelineEntry(share models.SourceArticle) ShareTimelineEntry {
+	return ShareTimelineEntry{
+		PostURI:      share.PostURI,
+		PostURL:      blueskyPostURL(share.PostURI),
+		Text:         share.PostText,
+		ShareType:    share.ShareType,
+		IsRepost:     share.IsRepost,
+		LikesCount:   share.LikesCount,
+		RepostsCount: share.RepostsCount,
+		RepliesCount: share.RepliesCount,
+		PostedAt:     share.PostedAt,
+		Source:       share.Source,
+	}
+}
+
+// articlePageView is the data passed to the article landing page
+type articlePageView struct {
+	Article models.Article
+	Shares  []articleShareView
+	Related []services.RelatedArticle // More on this story
+	ReadURL string
+	Theme   Theme
+	Brand   branding
+}
+
+// articleShareView is a source that shared the article, with a link to its post
+type articleShareView struct {
+	Source  models.Source
+	PostURL string
+}
+
+// ServeLandingPage renders a permalink page for an article from its cached
+// metadata. Its Open Graph tags describe and point to the original story, so
+// shared links unfurl like the article itself.
+// GET /article/:id
+func (h *ArticleHandler) ServeLandingPage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Article not found")
+		return
+	}
+
+	var article models.Article
+	err = h.db.Preload("SourceArticles", func(db *gorm.DB) *gorm.DB {
+		return db.Order("posted_at")
+	}).Preload("SourceArticles.Source").
+		First(&article, "id = ? AND is_not_news = ?", id, false).Error
+	if err == gorm.ErrRecordNotFound {
+		c.String(http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	view := articlePageView{
+		Article: article,
+		ReadURL: clickURL(article.ID, "", SurfaceLanding, 0, uuid.Nil, nil),
+		Theme:   themeFromRequest(c),
+		Brand:   brandingFor(c),
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, share := range article.SourceArticles {
+		if share.Source.ID == uuid.Nil || seen[share.Source.ID] {
+			continue
+		}
+		seen[share.Source.ID] = true
+		view.Shares = append(view.Shares, articleShareView{Source: share.Source, PostURL: blueskyPostURL(share.PostURI)})
+	}
+	if view.Related, err = h.relatedService.Related(article, landingRelatedArticles); err != nil {
+		log.Printf("Failed to load related articles for %s: %v", article.ID, err)
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	renderTemplate(c, feedTemplates, http.StatusOK, "article_page", view)
+}
+
+// blueskyPostURL converts an at://did/app.bsky.feed.post/rkey URI to its bsky.app
+// page, or returns "" for URIs that aren't posts
+func blueskyPostURL(postURI string) string {
+	parts := strings.Split(strings.TrimPrefix(postURI, "at://"), "/")
+	if !strings.HasPrefix(postURI, "at://") || len(parts) != 3 || parts[1] != "app.bsky.feed.post" {
+		return ""
+	}
+	return "https://bsky.app/profile/" + parts[0] + "/post/" + parts[2]
+}
+
+// sitemapURLSet is the root element of a sitemap
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is one page listed in a sitemap
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// ServeSitemap lists the feed pages and the landing pages of the most recent articles
+// GET /sitemap.xml
+func (h *ArticleHandler) ServeSitemap(c *gin.Context) {
+	var articles []models.Article
+	err := h.db.Select("id", "updated_at").
+		Where("is_not_news = ? AND quality_score > 0", false).
+		Order("created_at DESC").
+		Limit(sitemapMaxURLs - 2).
+		Find(&articles).Error
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		return
+	}
+
+	base := h.publicBaseURL(c)
+	urlSet := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  []sitemapURL{{Loc: base + "/"}, {Loc: base + "/feeds"}},
+	}
+	for _, article := range articles {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     base + "/article/" + article.ID.String(),
+			LastMod: article.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	output, err := xml.Marshal(urlSet)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), output...))
+}
+
+// publicBaseURL is the scheme and host absolute links are built from
+func (h *ArticleHandler) publicBaseURL(c *gin.Context) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
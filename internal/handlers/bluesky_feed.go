@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -24,217 +25,204 @@ import (
 type BlueSkyFeedHandler struct {
 	db                 *gorm.DB
 	feedService        *feeds.FeedService
+	registry           *feeds.Registry
 	blueskyClient      *bluesky.Client
 	userFollowsService *services.UserFollowsService
-	jwtVerifier        interface {
-		ValidateToken(authHeader string) (string, bool)
-		ExtractDIDFromToken(tokenString string) (string, error)
-	}
+	analyticsService   *services.AnalyticsService
+	jwtVerifier        tokenVerifier
 }
 
-// NewBlueSkyFeedHandler creates a new Bluesky feed handler
-func NewBlueSkyFeedHandler(db *gorm.DB, blueskyClient *bluesky.Client) *BlueSkyFeedHandler {
-	var jwtVerifier interface {
-		ValidateToken(authHeader string) (string, bool)
-		ExtractDIDFromToken(tokenString string) (string, error)
-	}
-	
-	// Use real JWT verification in production
+// tokenVerifier checks the AT Protocol JWTs Bluesky clients send with requests
+type tokenVerifier interface {
+	ValidateToken(authHeader string) (string, bool)
+	ExtractDIDFromToken(tokenString string) (string, error)
+}
+
+// newTokenVerifier verifies tokens for real in release mode and accepts any
+// token as a test user's in development
+func newTokenVerifier() tokenVerifier {
 	if os.Getenv("GIN_MODE") == "release" {
 		log.Println("Initializing production JWT verifier")
-		jwtVerifier = auth.NewJWTVerifier()
-	} else {
-		log.Println("Initializing mock JWT verifier for development")
-		jwtVerifier = auth.NewMockJWTVerifier()
+		return auth.NewJWTVerifier()
 	}
-	
+	log.Println("Initializing mock JWT verifier for development")
+	return auth.NewMockJWTVerifier()
+}
+
+// NewBlueSkyFeedHandler creates a new Bluesky feed handler
+func NewBlueSkyFeedHandler(db *gorm.DB, blueskyClient *bluesky.Client) *BlueSkyFeedHandler {
 	return &BlueSkyFeedHandler{
 		db:                 db,
 		feedService:        feeds.NewFeedService(db),
+		registry:           feeds.NewRegistry(db),
 		blueskyClient:      blueskyClient,
 		userFollowsService: services.NewUserFollowsService(db, blueskyClient),
-		jwtVerifier:        jwtVerifier,
+		analyticsService:   services.NewAnalyticsService(db),
+		jwtVerifier:        newTokenVerifier(),
 	}
 }
 
-// ATProtoFeedResponse represents the AT Protocol feed format
-type ATProtoFeedResponse struct {
-	Feed   []ATProtoFeedItem `json:"feed"`
-	Cursor *string           `json:"cursor,omitempty"`
-}
-
-// ATProtoFeedItem represents a single item in the AT Protocol feed
-type ATProtoFeedItem struct {
-	Post   ATProtoPost   `json:"post"`
-	Reason *ATProtoReason `json:"reason,omitempty"`
-}
-
-// ATProtoPost represents a post in the feed
-type ATProtoPost struct {
-	URI       string         `json:"uri"`
-	CID       string         `json:"cid"`
-	Author    ATProtoAuthor  `json:"author"`
-	Record    ATProtoRecord  `json:"record"`
-	IndexedAt time.Time      `json:"indexedAt"`
-}
-
-// ATProtoAuthor represents the author of a post
-type ATProtoAuthor struct {
-	DID         string  `json:"did"`
-	Handle      string  `json:"handle"`
-	DisplayName *string `json:"displayName,omitempty"`
-	Avatar      *string `json:"avatar,omitempty"`
-}
-
-// ATProtoRecord represents the post content
-type ATProtoRecord struct {
-	Type      string                 `json:"$type"`
-	Text      string                 `json:"text"`
-	CreatedAt time.Time              `json:"createdAt"`
-	Embed     *ATProtoEmbed          `json:"embed,omitempty"`
-	Facets    []map[string]interface{} `json:"facets,omitempty"`
-}
-
-// ATProtoEmbed represents embedded content
-type ATProtoEmbed struct {
-	Type     string                 `json:"$type"`
-	External *ATProtoExternalEmbed  `json:"external,omitempty"`
-}
-
-// ATProtoExternalEmbed represents external link embed
-type ATProtoExternalEmbed struct {
-	URI         string  `json:"uri"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Thumb       *string `json:"thumb,omitempty"`
-}
+// GetFeedSkeleton routes a feed request to the builder registered for its record key
+// GET /xrpc/app.bsky.feed.getFeedSkeleton?feed=at://did:plc:example/app.bsky.feed.generator/<rkey>
+func (h *BlueSkyFeedHandler) GetFeedSkeleton(c *gin.Context) {
+	def, err := tenantRegistry(c, h.registry).Lookup(c.Query("feed"))
+	if err != nil {
+		if err != feeds.ErrFeedNotFound {
+			log.Printf("Failed to look up feed %s: %v", c.Query("feed"), err)
+		}
+		xrpcError(c, http.StatusBadRequest, "UnknownFeed", "Feed not found")
+		return
+	}
 
-// ATProtoReason represents why this post is in the feed
-type ATProtoReason struct {
-	Type string `json:"$type"`
-	By   *ATProtoAuthor `json:"by,omitempty"`
+	if feeds.RequiresUser(def) {
+		h.GetPersonalizedFeed(c, def)
+	} else {
+		h.GetGlobalFeed(c, def)
+	}
 }
 
-// GetGlobalFeed handles custom Bluesky feed requests for global feed
-// GET /xrpc/app.bsky.feed.getFeedSkeleton?feed=at://did:plc:example/app.bsky.feed.generator/open-news-global
-func (h *BlueSkyFeedHandler) GetGlobalFeed(c *gin.Context) {
-	// Extract authorization header to get requesting user's DID
-	authHeader := c.GetHeader("Authorization")
-	userDID := h.extractDIDFromAuth(authHeader)
+// GetGlobalFeed handles custom Bluesky feed requests for feeds that don't need a user,
+// such as open-news-global
+func (h *BlueSkyFeedHandler) GetGlobalFeed(c *gin.Context, def *models.FeedDefinition) {
+	// Get the requesting user's DID from the authorization header
+	userDID := h.RequesterDID(c)
 	
 	// If we have a user DID, ensure they exist in our system
+	var user models.User
 	if userDID != "" {
-		if err := h.ensureUserExists(userDID); err != nil {
+		if err := h.ensureUserExists(c.Request.Context(), userDID); err != nil {
 			log.Printf("Failed to ensure user exists for DID %s: %v", userDID, err)
+		} else if err := h.db.Where("blue_sky_d_id = ?", userDID).First(&user).Error; err != nil {
+			log.Printf("Failed to load user for DID %s: %v", userDID, err)
 		}
 	}
 
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
-	// cursor := c.DefaultQuery("cursor", "") // TODO: Implement cursor-based pagination
-	
-	if limit > 100 {
-		limit = 100
-	}
-	if limit < 1 {
-		limit = 30
-	}
-
-	// Get our internal global feed
-	feedResponse, err := h.feedService.GetGlobalFeed(limit, 0)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": map[string]interface{}{
-				"message": "Failed to retrieve global feed",
-			},
-		})
+	limit, offset, ok := skeletonPage(c)
+	if !ok {
 		return
 	}
 
-	// Convert to AT Protocol format
-	atProtoFeed := h.convertToATProtoFeed(feedResponse.Items)
-	
-	response := ATProtoFeedResponse{
-		Feed: atProtoFeed,
+	// Build the feed from its definition, with share context for signed-in users
+	var userID *uuid.UUID
+	if user.ID != uuid.Nil {
+		userID = &user.ID
 	}
-	
-	// Add cursor for pagination (simplified - using timestamp)
-	if len(atProtoFeed) > 0 {
-		nextCursor := fmt.Sprintf("%d", time.Now().Unix())
-		response.Cursor = &nextCursor
+	feedResponse, err := h.registry.WithContext(c.Request.Context()).BuildSkeleton(def, userID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to build feed %s: %v", def.RKey, err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to retrieve global feed")
+		return
 	}
-
-	c.JSON(http.StatusOK, response)
+	h.respondSkeleton(c, userID, def, feedResponse)
 }
 
-// GetPersonalizedFeed handles custom Bluesky feed requests for personalized feed
-// GET /xrpc/app.bsky.feed.getFeedSkeleton?feed=at://did:plc:example/app.bsky.feed.generator/open-news-personal
-func (h *BlueSkyFeedHandler) GetPersonalizedFeed(c *gin.Context) {
-	// Extract authorization header to get requesting user's DID
-	authHeader := c.GetHeader("Authorization")
-	userDID := h.extractDIDFromAuth(authHeader)
+// GetPersonalizedFeed handles custom Bluesky feed requests for feeds built from the
+// requesting user's follows, such as open-news-personal
+func (h *BlueSkyFeedHandler) GetPersonalizedFeed(c *gin.Context, def *models.FeedDefinition) {
+	// Get the requesting user's DID from the authorization header
+	userDID := h.RequesterDID(c)
 	
 	if userDID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": map[string]interface{}{
-				"message": "Authentication required",
-			},
-		})
+		if fallback := feeds.AnonymousFallback(def); fallback != nil {
+			h.getAnonymousFeed(c, def, fallback)
+			return
+		}
+		xrpcError(c, http.StatusUnauthorized, "AuthenticationRequired", "Authentication required")
 		return
 	}
 
 	// Ensure user exists and is set up with their follows
-	user, err := h.ensureUserExistsWithFollows(userDID)
+	user, err := h.ensureUserExistsWithFollows(c.Request.Context(), userDID)
 	if err != nil {
 		log.Printf("Failed to ensure user exists with follows for DID %s: %v", userDID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": map[string]interface{}{
-				"message": "Failed to setup user account",
-			},
-		})
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to setup user account")
 		return
 	}
 
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
-	// cursor := c.DefaultQuery("cursor", "") // TODO: Implement cursor-based pagination
-	
-	if limit > 100 {
-		limit = 100
+	limit, offset, ok := skeletonPage(c)
+	if !ok {
+		return
+	}
+
+	// Get personalized feed for this user
+	feedResponse, err := h.registry.WithContext(c.Request.Context()).BuildSkeleton(def, &user.ID, limit, offset)
+	if err != nil {
+		log.Printf("Failed to build feed %s for %s: %v", def.RKey, userDID, err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to retrieve personalized feed")
+		return
+	}
+	h.respondSkeleton(c, &user.ID, def, feedResponse)
+}
+
+// getAnonymousFeed serves a personalized feed's fallback to a logged-out or
+// unverifiable request. Impressions are recorded under the requested feed.
+func (h *BlueSkyFeedHandler) getAnonymousFeed(c *gin.Context, def, fallback *models.FeedDefinition) {
+	limit, offset, ok := skeletonPage(c)
+	if !ok {
+		return
+	}
+	feedResponse, err := h.registry.WithContext(c.Request.Context()).BuildSkeleton(fallback, nil, limit, offset)
+	if err != nil {
+		log.Printf("Failed to build %s fallback for feed %s: %v", def.AnonymousFallback, def.RKey, err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to retrieve feed")
+		return
+	}
+	h.respondSkeleton(c, nil, def, feedResponse)
+}
+
+// skeletonPage reads the limit and cursor of a getFeedSkeleton request. Limits
+// outside 1-100 are clamped; the cursor is the offset of the next page.
+func skeletonPage(c *gin.Context) (limit, offset int, ok bool) {
+	limit, _ = strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if limit > bluesky.MaxSkeletonLimit {
+		limit = bluesky.MaxSkeletonLimit
 	}
 	if limit < 1 {
 		limit = 30
 	}
 
-	// Get personalized feed for this user
-	feedResponse, err := h.feedService.GetPersonalizedFeed(user.ID, limit, 0)
-	if err != nil {
-		// If no personalized feed exists, fall back to global feed filtered by user's sources
-		feedResponse, err = h.getFilteredGlobalFeed(user.ID, limit)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": map[string]interface{}{
-					"message": "Failed to retrieve personalized feed",
-				},
-			})
-			return
+	if cursor := c.Query("cursor"); cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil || offset < 0 {
+			xrpcError(c, http.StatusBadRequest, "InvalidRequest", "Invalid cursor")
+			return 0, 0, false
 		}
 	}
+	return limit, offset, true
+}
 
-	// Convert to AT Protocol format
-	atProtoFeed := h.convertToATProtoFeed(feedResponse.Items)
-	
-	response := ATProtoFeedResponse{
-		Feed: atProtoFeed,
+// respondSkeleton records the impressions of a page of a feed and serves it as
+// a feed skeleton, with a cursor when there are more items
+func (h *BlueSkyFeedHandler) respondSkeleton(c *gin.Context, userID *uuid.UUID, def *models.FeedDefinition, feedResponse *feeds.FeedResponse) {
+	h.analyticsService.RecordImpressions(servedFeed(userID, def.RKey, SurfaceSkeleton, feedResponse.Items))
+
+	shares, err := h.skeletonShares(feedResponse.Items)
+	if err != nil {
+		log.Printf("Failed to load posts for feed %s: %v", def.RKey, err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to retrieve feed")
+		return
 	}
-	
-	// Add cursor for pagination
-	if len(atProtoFeed) > 0 {
-		nextCursor := fmt.Sprintf("%d", time.Now().Unix())
-		response.Cursor = &nextCursor
+
+	skeleton := skeletonFeed(feedResponse.Items, shares)
+	if feedResponse.NextOffset > 0 {
+		skeleton.Cursor = strconv.Itoa(feedResponse.NextOffset)
 	}
+	c.JSON(http.StatusOK, skeleton)
+}
+
+// requesterDIDKey is the context key RequesterDID caches the verified DID under
+const requesterDIDKey = "requesterDID"
 
-	c.JSON(http.StatusOK, response)
+// RequesterDID returns the DID of the user making a feed request, or "" when the
+// request has no valid token. The result is cached on the context, so the rate
+// limiter and the handler verify the token only once.
+func (h *BlueSkyFeedHandler) RequesterDID(c *gin.Context) string {
+	if did, ok := c.Get(requesterDIDKey); ok {
+		return did.(string)
+	}
+	did := h.extractDIDFromAuth(c.GetHeader("Authorization"))
+	c.Set(requesterDIDKey, did)
+	return did
 }
 
 // extractDIDFromAuth extracts the DID from the Authorization header
@@ -256,14 +244,14 @@ func (h *BlueSkyFeedHandler) extractDIDFromAuth(authHeader string) string {
 }
 
 // ensureUserExists creates a user record if it doesn't exist
-func (h *BlueSkyFeedHandler) ensureUserExists(did string) error {
+func (h *BlueSkyFeedHandler) ensureUserExists(ctx context.Context, did string) error {
 	var user models.User
 	err := h.db.Where("blue_sky_d_id = ?", did).First(&user).Error
 	
 	if err == gorm.ErrRecordNotFound {
 		// User doesn't exist, create them
 		// Get profile from Bluesky
-		profile, err := h.blueskyClient.GetProfile(did)
+		profile, err := h.blueskyClient.WithContext(ctx).GetProfile(did)
 		if err != nil {
 			// If we can't get profile, create with minimal info
 			user = models.User{
@@ -282,11 +270,13 @@ func (h *BlueSkyFeedHandler) ensureUserExists(did string) error {
 			}
 		}
 		
-		if err := h.db.Create(&user).Error; err != nil {
+		created, err := models.InsertUser(h.db, &user)
+		if err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
-		
-		log.Printf("Created new user from DID: %s (%s)", did, user.Handle)
+		if created {
+			log.Printf("Created new user from DID: %s (%s)", did, user.Handle)
+		}
 	} else if err != nil {
 		return fmt.Errorf("failed to query user: %w", err)
 	}
@@ -295,7 +285,7 @@ func (h *BlueSkyFeedHandler) ensureUserExists(did string) error {
 }
 
 // ensureUserExistsWithFollows creates user and imports their follows as sources
-func (h *BlueSkyFeedHandler) ensureUserExistsWithFollows(did string) (*models.User, error) {
+func (h *BlueSkyFeedHandler) ensureUserExistsWithFollows(ctx context.Context, did string) (*models.User, error) {
 	var user models.User
 	err := h.db.Where("blue_sky_d_id = ?", did).First(&user).Error
 	
@@ -303,7 +293,7 @@ func (h *BlueSkyFeedHandler) ensureUserExistsWithFollows(did string) (*models.Us
 	if err == gorm.ErrRecordNotFound {
 		isNewUser = true
 		// Create user first
-		if err := h.ensureUserExists(did); err != nil {
+		if err := h.ensureUserExists(ctx, did); err != nil {
 			return nil, err
 		}
 		
@@ -332,180 +322,136 @@ func (h *BlueSkyFeedHandler) ensureUserExistsWithFollows(did string) (*models.Us
 	return &user, nil
 }
 
-// getFilteredGlobalFeed gets global feed filtered by user's sources
-func (h *BlueSkyFeedHandler) getFilteredGlobalFeed(userID uuid.UUID, limit int) (*feeds.FeedResponse, error) {
-	// Get global feed but filter by articles from user's sources
-	var feedItems []models.FeedItem
-	
-	query := h.db.Table("feed_items").
-		Select("feed_items.*").
-		Joins("JOIN feeds ON feeds.id = feed_items.feed_id").
-		Joins("JOIN articles ON articles.id = feed_items.article_id").
-		Joins("JOIN source_articles ON source_articles.article_id = articles.id").
-		Joins("JOIN sources ON sources.id = source_articles.source_id").
-		Joins("JOIN user_sources ON user_sources.source_id = sources.id").
-		Where("feeds.feed_type = ? AND feeds.name = ?", "global", "Top Stories").
-		Where("user_sources.user_id = ?", userID).
-		Preload("Article").
-		Preload("Article.SourceArticles.Source").
-		Order("feed_items.position ASC").
-		Limit(limit)
-	
-	if err := query.Find(&feedItems).Error; err != nil {
-		return nil, err
+// skeletonShare is the Bluesky post a feed item is served as
+type skeletonShare struct {
+	ArticleID   uuid.UUID
+	SourceID    uuid.UUID
+	PostURI     string
+	IsRepost    bool
+	OriginalURI string
+}
+
+// skeletonShares loads the posts sharing the articles of feed items, earliest first
+func (h *BlueSkyFeedHandler) skeletonShares(items []feeds.FeedItemDetails) ([]skeletonShare, error) {
+	if len(items) == 0 {
+		return nil, nil
 	}
-	
-	// Get the global feed for metadata
-	var globalFeed models.Feed
-	if err := h.db.Where("feed_type = ? AND name = ?", "global", "Top Stories").First(&globalFeed).Error; err != nil {
-		return nil, err
+	articleIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		articleIDs[i] = item.Article.ID
 	}
-	
-	// Transform to response format (similar to feeds service)
-	items := make([]feeds.FeedItemDetails, len(feedItems))
-	for i, item := range feedItems {
-		var source feeds.Source
-		if len(item.Article.SourceArticles) > 0 {
-			src := item.Article.SourceArticles[0].Source
-			source = feeds.Source{
-				ID:           src.ID,
-				Handle:       src.Handle,
-				DisplayName:  src.DisplayName,
-				Avatar:       src.Avatar,
-				QualityScore: src.QualityScore,
-			}
-		}
-		
-		items[i] = feeds.FeedItemDetails{
-			FeedItem: item,
-			Article: feeds.Article{
-				ID:           item.Article.ID,
-				URL:          item.Article.URL,
-				Title:        item.Article.Title,
-				Description:  item.Article.Description,
-				ImageURL:     item.Article.ImageURL,
-				PublishedAt:  item.Article.PublishedAt,
-				SiteName:     item.Article.SiteName,
-				QualityScore: item.Article.QualityScore,
-			},
-			Source: source,
-		}
+
+	var shares []skeletonShare
+	err := h.db.Model(&models.SourceArticle{}).
+		Select("article_id, source_id, post_uri, is_repost, original_uri").
+		Where("article_id IN ? AND post_uri <> ''", articleIDs).
+		Order("posted_at ASC").
+		Scan(&shares).Error
+	return shares, err
+}
+
+// skeletonPost returns how a share is served in a feed skeleton: a repost as the
+// original post with a repost reason, any other share as its own post. It
+// returns false when the share has no post a client can load.
+func (share skeletonShare) skeletonPost() (bluesky.SkeletonFeedPost, bool) {
+	if share.IsRepost && bluesky.IsPostURI(share.OriginalURI) && strings.Contains(share.PostURI, "/app.bsky.feed.repost/") {
+		return bluesky.SkeletonFeedPost{
+			Post:   share.OriginalURI,
+			Reason: &bluesky.SkeletonReason{Type: bluesky.SkeletonReasonRepost, Repost: share.PostURI},
+		}, true
 	}
-	
-	return &feeds.FeedResponse{
-		Feed:  globalFeed,
-		Items: items,
-		Meta: feeds.FeedMeta{
-			TotalItems:    len(items),
-			Page:          1,
-			PerPage:       limit,
-			LastUpdatedAt: globalFeed.UpdatedAt,
-		},
-	}, nil
+	if bluesky.IsPostURI(share.PostURI) {
+		return bluesky.SkeletonFeedPost{Post: share.PostURI}, true
+	}
+	return bluesky.SkeletonFeedPost{}, false
 }
 
-// convertToATProtoFeed converts internal feed items to AT Protocol format
-func (h *BlueSkyFeedHandler) convertToATProtoFeed(items []feeds.FeedItemDetails) []ATProtoFeedItem {
-	atProtoItems := make([]ATProtoFeedItem, 0, len(items))
-	
-	for _, item := range items {
-		// Create a synthetic post URI (in real implementation, you'd use actual post URIs)
-		postURI := fmt.Sprintf("at://%s/app.bsky.feed.post/%s", 
-			item.Source.Handle, item.Article.ID.String())
-		
-		// Create external embed for the article
-		var embed *ATProtoEmbed
-		if item.Article.URL != "" {
-			embed = &ATProtoEmbed{
-				Type: "app.bsky.embed.external",
-				External: &ATProtoExternalEmbed{
-					URI:         item.Article.URL,
-					Title:       item.Article.Title,
-					Description: item.Article.Description,
-				},
-			}
-			
-			// Add thumbnail if available
-			if item.Article.ImageURL != "" {
-				embed.External.Thumb = &item.Article.ImageURL
-			}
+// skeletonFeed serves each feed item as a post sharing its article: the item's
+// source's own post when there is one, otherwise the earliest post sharing it.
+// Items no loadable post shares are left out, as are repeats of a post.
+func skeletonFeed(items []feeds.FeedItemDetails, shares []skeletonShare) bluesky.FeedSkeleton {
+	bySource := make(map[[2]uuid.UUID]bluesky.SkeletonFeedPost, len(shares))
+	byArticle := make(map[uuid.UUID]bluesky.SkeletonFeedPost, len(shares))
+	for _, share := range shares {
+		post, ok := share.skeletonPost()
+		if !ok {
+			continue
 		}
-		
-		// Create post text
-		postText := item.Article.Title
-		if len(postText) > 280 { // Bluesky character limit
-			postText = postText[:277] + "..."
+		key := [2]uuid.UUID{share.ArticleID, share.SourceID}
+		if _, ok := bySource[key]; !ok {
+			bySource[key] = post
 		}
-		
-		// Use published date or fallback to when added to feed
-		createdAt := item.FeedItem.AddedAt.UTC()
-		if item.Article.PublishedAt != nil {
-			createdAt = item.Article.PublishedAt.UTC()
+		if _, ok := byArticle[share.ArticleID]; !ok {
+			byArticle[share.ArticleID] = post
 		}
-		
-		// Create the AT Protocol post
-		atProtoPost := ATProtoPost{
-			URI: postURI,
-			CID: fmt.Sprintf("bafyrei%s", item.Article.ID.String()[:20]), // Synthetic CID
-			Author: ATProtoAuthor{
-				DID:    fmt.Sprintf("did:plc:%s", item.Source.Handle),
-				Handle: item.Source.Handle,
-			},
-			Record: ATProtoRecord{
-				Type:      "app.bsky.feed.post",
-				Text:      postText,
-				CreatedAt: createdAt,
-				Embed:     embed,
-			},
-			IndexedAt: item.FeedItem.AddedAt.UTC(),
-		}
-		
-		// Add display name and avatar if available
-		if item.Source.DisplayName != "" {
-			atProtoPost.Author.DisplayName = &item.Source.DisplayName
+	}
+
+	skeleton := bluesky.FeedSkeleton{Feed: make([]bluesky.SkeletonFeedPost, 0, len(items))}
+	served := make(map[string]bool, len(items))
+	for _, item := range items {
+		post, ok := bySource[[2]uuid.UUID{item.Article.ID, item.Source.ID}]
+		if !ok {
+			if post, ok = byArticle[item.Article.ID]; !ok {
+				continue
+			}
 		}
-		if item.Source.Avatar != "" {
-			atProtoPost.Author.Avatar = &item.Source.Avatar
+		if served[post.Post] {
+			continue
 		}
-		
-		atProtoItems = append(atProtoItems, ATProtoFeedItem{
-			Post: atProtoPost,
-		})
+		served[post.Post] = true
+		post.FeedContext = item.Reason
+		skeleton.Feed = append(skeleton.Feed, post)
 	}
-	
-	return atProtoItems
+	return skeleton
 }
 
-// GetFeedInfo returns information about the custom feeds
+// GetFeedInfo returns information about the custom feeds.
+// With a feed parameter it describes that feed; without one it lists every active feed.
 func (h *BlueSkyFeedHandler) GetFeedInfo(c *gin.Context) {
 	feedURI := c.Query("feed")
+	generatorDID := feedGeneratorDID(c)
+	if generatorDID == "" {
+		generatorDID = "did:plc:your-feed-generator-did"
+	}
 	
-	var feedInfo map[string]interface{}
-	
-	if strings.Contains(feedURI, "open-news-global") {
-		feedInfo = map[string]interface{}{
-			"uri":         feedURI,
-			"displayName": "Open News - Global",
-			"description": "Top stories from across the Bluesky network, ranked by engagement and quality.",
-			"avatar":      "", // Add your feed avatar URL here
-			"createdBy":   "did:plc:your-feed-generator-did", // Your feed generator's DID
+	if feedURI == "" {
+		definitions, err := tenantRegistry(c, h.registry).List()
+		if err != nil {
+			log.Printf("Failed to list feeds: %v", err)
+			xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to list feeds")
+			return
 		}
-	} else if strings.Contains(feedURI, "open-news-personal") {
-		feedInfo = map[string]interface{}{
-			"uri":         feedURI,
-			"displayName": "Open News - Personal",
-			"description": "Personalized news feed based on accounts you follow on Bluesky.",
-			"avatar":      "", // Add your feed avatar URL here
-			"createdBy":   "did:plc:your-feed-generator-did", // Your feed generator's DID
+		
+		feedList := make([]map[string]interface{}, 0, len(definitions))
+		for _, def := range definitions {
+			feedList = append(feedList, map[string]interface{}{
+				"uri": fmt.Sprintf("at://%s/app.bsky.feed.generator/%s", generatorDID, def.RKey),
+			})
 		}
-	} else {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": map[string]interface{}{
-				"message": "Feed not found",
-			},
+		
+		c.JSON(http.StatusOK, gin.H{
+			"did":   generatorDID,
+			"feeds": feedList,
 		})
 		return
 	}
 	
+	def, err := tenantRegistry(c, h.registry).Lookup(feedURI)
+	if err != nil {
+		if err != feeds.ErrFeedNotFound {
+			log.Printf("Failed to look up feed %s: %v", feedURI, err)
+		}
+		xrpcError(c, http.StatusBadRequest, "UnknownFeed", "Feed not found")
+		return
+	}
+	
+	feedInfo := map[string]interface{}{
+		"uri":         feedURI,
+		"displayName": def.DisplayName,
+		"description": def.Description,
+		"avatar":      def.Avatar,
+		"createdBy":   generatorDID,
+	}
+	
 	c.JSON(http.StatusOK, feedInfo)
 }
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/database"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestDB returns a migrated in-memory SQLite database
+func setupTestDB(t *testing.T) *gorm.DB {
+	require.NoError(t, database.Connect(&database.Config{Driver: database.DriverSQLite, Path: ":memory:"}))
+	t.Cleanup(func() { database.Close() })
+	require.NoError(t, database.Migrate())
+	return database.DB
+}
+
+func TestSkeletonFeed(t *testing.T) {
+	items := make([]feeds.FeedItemDetails, 4)
+	for i := range items {
+		items[i].Article.ID = uuid.New()
+		items[i].Source.ID = uuid.New()
+	}
+	items[0].Reason = "Shared by @reuters"
+	other := uuid.New()
+	shares := []skeletonShare{
+		// The earliest share is another source's; the item's own source is preferred
+		{ArticleID: items[0].Article.ID, SourceID: other, PostURI: "at://did:plc:other/app.bsky.feed.post/1"},
+		{ArticleID: items[0].Article.ID, SourceID: items[0].Source.ID, PostURI: "at://did:plc:reuters/app.bsky.feed.post/2"},
+		// A repost is served as the original post
+		{ArticleID: items[1].Article.ID, SourceID: items[1].Source.ID, PostURI: "at://did:plc:ap/app.bsky.feed.repost/3", IsRepost: true, OriginalURI: "at://did:plc:writer/app.bsky.feed.post/4"},
+		// A share without a post clients can load is skipped
+		{ArticleID: items[2].Article.ID, SourceID: items[2].Source.ID, PostURI: "at://someone.example/app.bsky.feed.post/5"},
+		// The same post can't be served twice
+		{ArticleID: items[3].Article.ID, SourceID: other, PostURI: "at://did:plc:other/app.bsky.feed.post/1"},
+	}
+
+	skeleton := skeletonFeed(items, shares)
+
+	require.Len(t, skeleton.Feed, 3)
+	assert.Equal(t, "at://did:plc:reuters/app.bsky.feed.post/2", skeleton.Feed[0].Post)
+	assert.Equal(t, "Shared by @reuters", skeleton.Feed[0].FeedContext)
+	assert.Equal(t, "at://did:plc:writer/app.bsky.feed.post/4", skeleton.Feed[1].Post)
+	require.NotNil(t, skeleton.Feed[1].Reason)
+	assert.Equal(t, bluesky.SkeletonReasonRepost, skeleton.Feed[1].Reason.Type)
+	assert.Equal(t, "at://did:plc:other/app.bsky.feed.post/1", skeleton.Feed[2].Post)
+
+	skeleton.Cursor = "30"
+	body, err := json.Marshal(skeleton)
+	require.NoError(t, err)
+	assert.NoError(t, bluesky.ValidateSkeleton(body, 30), "the served skeleton conforms to the lexicon")
+
+	body, err = json.Marshal(skeletonFeed(nil, nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"feed":[]}`, string(body), "an empty feed is an empty array without a cursor")
+}
+
+func TestSkeletonPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query  string
+		limit  int
+		offset int
+		status int
+	}{
+		{"", 30, 0, http.StatusOK},
+		{"limit=10&cursor=20", 10, 20, http.StatusOK},
+		{"limit=500", 100, 0, http.StatusOK},
+		{"limit=0", 30, 0, http.StatusOK},
+		{"cursor=1700000000x", 0, 0, http.StatusBadRequest},
+		{"cursor=-5", 0, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/xrpc/app.bsky.feed.getFeedSkeleton?"+tt.query, nil)
+
+			limit, offset, ok := skeletonPage(c)
+			if tt.status != http.StatusOK {
+				assert.False(t, ok)
+				assert.Equal(t, tt.status, w.Code)
+				assert.True(t, strings.Contains(w.Body.String(), `"error":"InvalidRequest"`))
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, tt.limit, limit)
+			assert.Equal(t, tt.offset, offset)
+		})
+	}
+}
+
+func TestGetFeedSkeletonRouting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	require.NoError(t, feeds.NewRegistry(db).EnsureDefaults())
+
+	h := NewBlueSkyFeedHandler(db, nil)
+	r := gin.New()
+	r.GET("/xrpc/app.bsky.feed.getFeedSkeleton", h.GetFeedSkeleton)
+	request := func(rkey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		feed := "at://did:plc:example/app.bsky.feed.generator/" + rkey
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/xrpc/app.bsky.feed.getFeedSkeleton?feed="+feed, nil))
+		return w
+	}
+
+	w := request("open-news-unknown")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"UnknownFeed","message":"Feed not found"}`, w.Body.String())
+
+	// The global feed is served from the regenerated feed items
+	source := models.Source{BlueSkyDID: "did:plc:source", Handle: "source.test"}
+	require.NoError(t, db.Create(&source).Error)
+	article := models.Article{URL: "https://example.com/story", Title: "Story", QualityScore: 0.9}
+	require.NoError(t, db.Create(&article).Error)
+	require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: article.ID,
+		PostURI: "at://did:plc:source/app.bsky.feed.post/1", PostedAt: time.Now()}).Error)
+	require.NoError(t, feeds.NewFeedService(db).RegenerateGlobalFeed())
+
+	w = request("open-news-global")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, bluesky.ValidateSkeleton(w.Body.Bytes(), 50))
+	assert.Contains(t, w.Body.String(), `"post":"at://did:plc:source/app.bsky.feed.post/1"`)
+
+	// The personal feed is routed to the personalized builder, which needs a
+	// requesting user once it has no anonymous fallback
+	require.NoError(t, db.Model(&models.FeedDefinition{}).Where("rkey = ?", "open-news-personal").Update("anonymous_fallback", feeds.FallbackNone).Error)
+	w = request("open-news-personal")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"AuthenticationRequired"`)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxTrackedHeaderLength bounds the referrer and user agent stored with a click
+const maxTrackedHeaderLength = 1024
+
+// Surfaces feed items are served on and clicked from
+const (
+	SurfaceSkeleton = "skeleton"
+	SurfaceWeb      = "web"
+	SurfaceWidget   = "widget"
+	SurfaceAPI      = "api"
+	SurfaceLanding  = "landing"
+	SurfaceEmail    = "email"
+)
+
+// clickSigningKey returns the key reader tokens in click links are signed with,
+// from CLICK_SIGNING_KEY. Without it a random key is used, so links stop naming
+// their reader after a restart and aren't recognized by other instances.
+var clickSigningKey = sync.OnceValue(func() []byte {
+	if key := os.Getenv("CLICK_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("Failed to generate click signing key: %v", err)
+	}
+	return key
+})
+
+// ClickHandler records clicks on feed items before sending readers on to the article
+type ClickHandler struct {
+	db               *gorm.DB
+	analyticsService *services.AnalyticsService
+}
+
+// NewClickHandler creates a new click handler
+func NewClickHandler(db *gorm.DB) *ClickHandler {
+	return &ClickHandler{
+		db:               db,
+		analyticsService: services.NewAnalyticsService(db),
+	}
+}
+
+// Redirect records a click and redirects to the article's URL.
+// Only stored article URLs are redirected to, so the endpoint can't be used as an open redirect.
+// GET /r/:article_id?feed=<feed>&surface=<surface>&pos=<position>&src=<source id>&u=<reader token>
+func (h *ClickHandler) Redirect(c *gin.Context) {
+	articleID, err := uuid.Parse(c.Param("article_id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Article not found")
+		return
+	}
+
+	var article models.Article
+	err = h.db.Select("id", "url").First(&article, "id = ?", articleID).Error
+	if err == gorm.ErrRecordNotFound {
+		c.String(http.StatusNotFound, "Article not found")
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load article")
+		return
+	}
+
+	click := &models.Click{
+		ArticleID: article.ID,
+		Feed:      c.Query("feed"),
+		Surface:   c.DefaultQuery("surface", SurfaceWeb),
+		Referrer:  truncateHeader(c.Request.Referer()),
+		UserAgent: truncateHeader(c.Request.UserAgent()),
+	}
+	click.Position, _ = strconv.Atoi(c.Query("pos"))
+	if sourceID, err := uuid.Parse(c.Query("src")); err == nil {
+		click.SourceID = &sourceID
+	}
+	// Clicks from personal feeds teach the reader's topic preferences
+	if userID, ok := verifyClickUser(c.Query("u")); ok {
+		click.UserID = &userID
+	}
+
+	// A failed insert shouldn't stop the reader from getting to the article
+	if err := h.analyticsService.RecordClick(click); err != nil {
+		log.Printf("Failed to record click on article %s: %v", article.ID, err)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, article.URL)
+}
+
+// clickURL returns the tracking redirect for a feed item. Items of a personal
+// feed pass its reader's userID, which is signed into the link.
+func clickURL(articleID uuid.UUID, feed, surface string, position int, sourceID uuid.UUID, userID *uuid.UUID) string {
+	query := url.Values{}
+	if feed != "" {
+		query.Set("feed", feed)
+	}
+	query.Set("surface", surface)
+	if position > 0 {
+		query.Set("pos", strconv.Itoa(position))
+	}
+	if sourceID != uuid.Nil {
+		query.Set("src", sourceID.String())
+	}
+	if userID != nil {
+		query.Set("u", signClickUser(*userID))
+	}
+	return "/r/" + articleID.String() + "?" + query.Encode()
+}
+
+// signClickUser returns the token naming a reader in click links: the user ID
+// and its HMAC-SHA256 under clickSigningKey
+func signClickUser(userID uuid.UUID) string {
+	mac := hmac.New(sha256.New, clickSigningKey())
+	mac.Write([]byte(userID.String()))
+	return userID.String() + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyClickUser returns the reader a click link's token names, if its
+// signature is valid
+func verifyClickUser(token string) (uuid.UUID, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	_, expected, _ := strings.Cut(signClickUser(userID), ".")
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// servedFeed describes feed items served in a request, for impression logging
+func servedFeed(userID *uuid.UUID, feed, surface string, items []feeds.FeedItemDetails) services.ServedFeed {
+	served := services.ServedFeed{
+		UserID:  userID,
+		Feed:    feed,
+		Surface: surface,
+		Items:   make([]services.ServedItem, len(items)),
+	}
+	for i, item := range items {
+		served.Items[i] = services.ServedItem{
+			ArticleID: item.Article.ID,
+			SourceID:  item.Source.ID,
+			Position:  item.Position,
+		}
+	}
+	return served
+}
+
+// truncateHeader limits a request header value to maxTrackedHeaderLength bytes
+func truncateHeader(value string) string {
+	if len(value) > maxTrackedHeaderLength {
+		return value[:maxTrackedHeaderLength]
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickURL(t *testing.T) {
+	articleID := uuid.New()
+	sourceID := uuid.New()
+
+	link := clickURL(articleID, "open-news-tech", SurfaceWidget, 3, sourceID, nil)
+	require.True(t, strings.HasPrefix(link, "/r/"+articleID.String()+"?"))
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "open-news-tech", query.Get("feed"))
+	assert.Equal(t, SurfaceWidget, query.Get("surface"))
+	assert.Equal(t, "3", query.Get("pos"))
+	assert.Equal(t, sourceID.String(), query.Get("src"))
+	assert.Empty(t, query.Get("u"), "links outside personal feeds name no reader")
+
+	// Unknown context is left out rather than sent empty
+	assert.Equal(t, "/r/"+articleID.String()+"?surface=web", clickURL(articleID, "", SurfaceWeb, 0, uuid.Nil, nil))
+}
+
+func TestClickUserToken(t *testing.T) {
+	userID := uuid.New()
+	token := signClickUser(userID)
+
+	verified, ok := verifyClickUser(token)
+	require.True(t, ok)
+	assert.Equal(t, userID, verified)
+
+	// Another user's ID can't be swapped in without the key
+	_, signature, _ := strings.Cut(token, ".")
+	for _, forged := range []string{uuid.NewString() + "." + signature, userID.String(), userID.String() + ".", ""} {
+		_, ok := verifyClickUser(forged)
+		assert.False(t, ok, "token %q", forged)
+	}
+}
+
+func TestRedirectTeachesTopics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+
+	user := models.User{BlueSkyDID: "did:plc:clicker", Handle: "clicker.test"}
+	require.NoError(t, db.Create(&user).Error)
+	article := models.Article{URL: "https://example.com/comet", Title: "Comet sighted", Tags: pq.StringArray{"science"}}
+	require.NoError(t, db.Create(&article).Error)
+
+	r := gin.New()
+	r.GET("/r/:article_id", NewClickHandler(db).Redirect)
+	follow := func(link string) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+		require.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, article.URL, w.Header().Get("Location"))
+	}
+
+	// A click from the reader's personal feed, and one from the global feed
+	follow(clickURL(article.ID, "personal", SurfaceWeb, 1, uuid.Nil, &user.ID))
+	follow(clickURL(article.ID, "global", SurfaceWeb, 1, uuid.Nil, nil))
+
+	var clicks []models.Click
+	require.NoError(t, db.Order("feed DESC").Find(&clicks).Error)
+	require.Len(t, clicks, 2)
+	require.NotNil(t, clicks[0].UserID)
+	assert.Equal(t, user.ID, *clicks[0].UserID)
+	assert.Nil(t, clicks[1].UserID)
+
+	learned, err := services.NewPreferencesService(db).LearnTopics(user.ID)
+	require.NoError(t, err)
+	require.Len(t, learned, 1)
+	assert.Equal(t, "science", learned[0].Topic)
+	assert.Equal(t, 1, learned[0].Clicks)
+}
+
+func TestTruncateHeader(t *testing.T) {
+	assert.Equal(t, "https://example.com/", truncateHeader("https://example.com/"))
+	assert.Len(t, truncateHeader(strings.Repeat("a", 5000)), maxTrackedHeaderLength)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig lists the origins allowed to make cross-origin requests
+type CORSConfig struct {
+	AllowedOrigins []string // "*" allows any origin
+}
+
+// LoadCORSConfig reads a comma-separated origin list from the given environment
+// variable, falling back to the provided default when it is unset
+func LoadCORSConfig(envVar, defaultOrigins string) CORSConfig {
+	value := os.Getenv(envVar)
+	if value == "" {
+		value = defaultOrigins
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return CORSConfig{AllowedOrigins: origins}
+}
+
+// AllowsOrigin reports whether an Origin header value is permitted
+func (cfg CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowAllOrigins reports whether the config is a plain wildcard
+func (cfg CORSConfig) allowAllOrigins() bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware sets CORS headers for allowed origins and answers preflight requests
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		if cfg.allowAllOrigins() {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else if origin != "" && cfg.AllowsOrigin(origin) {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-API-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
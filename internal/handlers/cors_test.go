@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(cfg CORSConfig) *gin.Engine {
+		r := gin.New()
+		r.Use(CORSMiddleware(cfg))
+		r.GET("/api/feeds/global", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return r
+	}
+	request := func(r *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/feeds/global", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := newRouter(CORSConfig{AllowedOrigins: []string{"https://allowed.example"}})
+
+	w := request(r, http.MethodGet, "https://allowed.example")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://allowed.example", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = request(r, http.MethodGet, "https://evil.example")
+	assert.Equal(t, http.StatusOK, w.Code, "the browser enforces the policy")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "other origins aren't allowed")
+
+	w = request(r, http.MethodOptions, "https://allowed.example")
+	assert.Equal(t, http.StatusNoContent, w.Code, "preflight requests are answered without reaching the route")
+	assert.Equal(t, "https://allowed.example", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "GET")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-API-Key")
+
+	w = request(newRouter(LoadCORSConfig("TEST_CORS_ORIGINS_UNSET", "*")), http.MethodGet, "https://any.example")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Vary"))
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// customFeedView is a feed a user defined, with where to read it
+type customFeedView struct {
+	ID           uuid.UUID         `json:"id"`
+	RKey         string            `json:"rkey"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Topics       []string          `json:"topics"`
+	Domains      []string          `json:"domains"`
+	Language     string            `json:"language"`
+	MinShares    int               `json:"min_shares"`
+	GeneratorDID string            `json:"generator_did"`
+	WebURL       string            `json:"web_url"`            // Page the feed is served on
+	FeedURI      string            `json:"feed_uri,omitempty"` // URI of the feed record, once published
+	Record       *customFeedRecord `json:"record,omitempty"`   // Feed record to publish, when there's a generator DID
+}
+
+// customFeedRecord is the app.bsky.feed.generator record a user publishes in
+// their own repository, under the feed's record key, to list the feed on Bluesky
+type customFeedRecord struct {
+	Type        string `json:"$type"`
+	DID         string `json:"did"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description,omitempty"`
+}
+
+// newCustomFeedView describes a custom feed. ownerDID is the repository its
+// record is published in.
+func newCustomFeedView(def models.FeedDefinition, ownerDID string) customFeedView {
+	view := customFeedView{
+		ID:           def.ID,
+		RKey:         def.RKey,
+		Name:         def.DisplayName,
+		Description:  def.Description,
+		Topics:       def.Topics,
+		Domains:      def.Domains,
+		Language:     def.Language,
+		MinShares:    def.MinShares,
+		GeneratorDID: def.GeneratorDID,
+		WebURL:       "/feed/custom/" + def.RKey,
+	}
+	if view.Topics == nil {
+		view.Topics = []string{}
+	}
+	if view.Domains == nil {
+		view.Domains = []string{}
+	}
+	if def.GeneratorDID != "" {
+		view.Record = &customFeedRecord{
+			Type:        "app.bsky.feed.generator",
+			DID:         def.GeneratorDID,
+			DisplayName: def.DisplayName,
+			Description: def.Description,
+		}
+		if ownerDID != "" {
+			view.FeedURI = "at://" + ownerDID + "/app.bsky.feed.generator/" + def.RKey
+		}
+	}
+	return view
+}
+
+// ListCustomFeeds handles GET /api/me/feeds, returning the feeds the user defined
+func (h *MeHandler) ListCustomFeeds(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	definitions, err := h.customFeeds.List(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load feeds")
+		return
+	}
+
+	ownerDID, _ := h.customFeeds.OwnerDID(userID)
+	views := make([]customFeedView, len(definitions))
+	for i, def := range definitions {
+		views[i] = newCustomFeedView(def, ownerDID)
+	}
+	c.JSON(http.StatusOK, gin.H{"feeds": views})
+}
+
+// CreateCustomFeed handles POST /api/me/feeds, defining a feed filtered by
+// topics, sites, language and share count. The feed is served on its web page,
+// and through getFeedSkeleton once the user publishes its record.
+func (h *MeHandler) CreateCustomFeed(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	var req services.CustomFeed
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var tenantID *uuid.UUID
+	if tenant := currentTenant(c); tenant != nil {
+		tenantID = &tenant.ID
+	}
+	def, err := h.customFeeds.Create(userID, tenantID, req)
+	if err != nil {
+		h.respondCustomFeedError(c, err)
+		return
+	}
+	h.respondCustomFeed(c, http.StatusCreated, userID, def)
+}
+
+// UpdateCustomFeed handles PUT /api/me/feeds/:id, replacing a feed's settings.
+// Its record key, and so its web page and feed URI, stay the same.
+func (h *MeHandler) UpdateCustomFeed(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	var req services.CustomFeed
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	def, err := h.customFeeds.Update(userID, id, req)
+	if err != nil {
+		h.respondCustomFeedError(c, err)
+		return
+	}
+	h.respondCustomFeed(c, http.StatusOK, userID, def)
+}
+
+// DeleteCustomFeed handles DELETE /api/me/feeds/:id
+func (h *MeHandler) DeleteCustomFeed(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+	if err := h.customFeeds.Delete(userID, id); err != nil {
+		h.respondCustomFeedError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// respondCustomFeed responds with a feed the user just saved
+func (h *MeHandler) respondCustomFeed(c *gin.Context, status int, userID uuid.UUID, def *models.FeedDefinition) {
+	ownerDID, _ := h.customFeeds.OwnerDID(userID)
+	c.JSON(status, newCustomFeedView(*def, ownerDID))
+}
+
+// respondCustomFeedError maps a custom feed error to a response
+func (h *MeHandler) respondCustomFeedError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "Feed not found")
+	case errors.Is(err, services.ErrInvalidCustomFeed),
+		errors.Is(err, services.ErrUnknownTopic),
+		errors.Is(err, services.ErrInvalidLanguage):
+		respondError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCustomFeedLimit):
+		respondError(c, http.StatusConflict, err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, "Failed to save feed")
+	}
+}
+
+// ServeCustomFeedHTML serves a feed a user defined as HTML
+func (h *FeedPageHandler) ServeCustomFeedHTML(c *gin.Context) {
+	def, err := tenantRegistry(c, h.registry).Lookup(c.Param("rkey"))
+	if err != nil || def.OwnerID == nil {
+		renderTemplate(c, feedTemplates, http.StatusNotFound, "feed_error", errorView{
+			Icon:    "fa-search",
+			Title:   "Feed not found",
+			Message: "There's no feed at this address.",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if limit > 100 {
+		limit = 100
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	feedResponse, err := h.registry.WithContext(c.Request.Context()).Build(def, nil, limit, (page-1)*limit)
+	if err != nil {
+		renderTemplate(c, feedTemplates, http.StatusInternalServerError, "feed_error", errorView{
+			Icon:    "fa-exclamation-triangle",
+			Title:   "Failed to load feed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.renderFeedHTML(c, feedResponse, nil, def.RKey, def.DisplayName, "🧭", page, limit, "/feed/custom/"+def.RKey)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler handles email digest subscriptions
+type EmailHandler struct {
+	digestService *services.EmailDigestService
+}
+
+// NewEmailHandler creates a new email handler
+func NewEmailHandler(digestService *services.EmailDigestService) *EmailHandler {
+	return &EmailHandler{digestService: digestService}
+}
+
+// subscribeRequest is the body of Subscribe
+type subscribeRequest struct {
+	Email     string   `json:"email" binding:"required"`
+	Frequency string   `json:"frequency"` // daily (default) or weekly
+	Topics    []string `json:"topics"`    // Topic slugs; empty for top stories from every topic
+}
+
+// Subscribe starts an email digest subscription and sends the confirmation email.
+// The response doesn't reveal whether the address was already subscribed.
+// POST /api/email/subscriptions
+func (h *EmailHandler) Subscribe(c *gin.Context) {
+	var req subscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "email is required")
+		return
+	}
+
+	err := h.digestService.Subscribe(req.Email, req.Frequency, req.Topics)
+	switch {
+	case errors.Is(err, services.ErrInvalidEmail), errors.Is(err, services.ErrInvalidFrequency), errors.Is(err, services.ErrUnknownTopic):
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to subscribe to the email digest: %v", err)
+		respondError(c, http.StatusInternalServerError, "Failed to subscribe")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Check your inbox for a link to confirm the subscription"})
+}
+
+// emailPageView is the data passed to the email_page template
+type emailPageView struct {
+	Title       string
+	Message     string
+	FormAction  string // Shows a button posting here when set
+	ButtonLabel string
+	Theme       Theme
+}
+
+// Confirm activates a subscription from the link in its confirmation email
+// GET /email/confirm/:token
+func (h *EmailHandler) Confirm(c *gin.Context) {
+	subscription, err := h.digestService.Confirm(c.Param("token"))
+	if err != nil {
+		h.renderLinkError(c, err)
+		return
+	}
+
+	renderTemplate(c, feedTemplates, http.StatusOK, "email_page", emailPageView{
+		Title:   "Subscription confirmed",
+		Message: "You'll receive the " + subscription.Frequency + " open.news digest at " + subscription.Email + ".",
+		Theme:   themeFromRequest(c),
+	})
+}
+
+// ServeUnsubscribePage asks for confirmation before unsubscribing, so link
+// scanners that open every URL in an email don't unsubscribe readers
+// GET /email/unsubscribe/:token
+func (h *EmailHandler) ServeUnsubscribePage(c *gin.Context) {
+	renderTemplate(c, feedTemplates, http.StatusOK, "email_page", emailPageView{
+		Title:       "Unsubscribe",
+		Message:     "Stop receiving the open.news digest at this address?",
+		FormAction:  "/email/unsubscribe/" + c.Param("token"),
+		ButtonLabel: "Unsubscribe",
+		Theme:       themeFromRequest(c),
+	})
+}
+
+// Unsubscribe ends a subscription, from the unsubscribe page or a mail client's
+// one-click List-Unsubscribe-Post request
+// POST /email/unsubscribe/:token
+func (h *EmailHandler) Unsubscribe(c *gin.Context) {
+	if err := h.digestService.Unsubscribe(c.Param("token")); err != nil {
+		h.renderLinkError(c, err)
+		return
+	}
+
+	renderTemplate(c, feedTemplates, http.StatusOK, "email_page", emailPageView{
+		Title:   "Unsubscribed",
+		Message: "You won't receive the open.news digest anymore.",
+		Theme:   themeFromRequest(c),
+	})
+}
+
+// renderLinkError explains a confirm or unsubscribe link that didn't work
+func (h *EmailHandler) renderLinkError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrSubscriptionNotFound) {
+		renderTemplate(c, feedTemplates, http.StatusNotFound, "email_page", emailPageView{
+			Title:   "Link expired",
+			Message: "This link doesn't match a subscription. It may have been unsubscribed already.",
+			Theme:   themeFromRequest(c),
+		})
+		return
+	}
+
+	log.Printf("Failed to update email subscription: %v", err)
+	renderTemplate(c, feedTemplates, http.StatusInternalServerError, "email_page", emailPageView{
+		Title:   "Something went wrong",
+		Message: "We couldn't update your subscription. Please try again later.",
+		Theme:   themeFromRequest(c),
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EntityHandler serves the index of people, organizations and places named in articles
+type EntityHandler struct {
+	entities *services.EntityService
+}
+
+// NewEntityHandler creates a new entity handler
+func NewEntityHandler(db *gorm.DB) *EntityHandler {
+	return &EntityHandler{entities: services.NewEntityService(db)}
+}
+
+// EntityCoverageResponse lists the coverage of an entity over time
+type EntityCoverageResponse struct {
+	Slug     string                          `json:"slug"`
+	Entities []models.Entity                 `json:"entities"` // Every type the name matched, most covered first
+	Articles []ArticleSummary                `json:"articles"` // Newest first
+	Timeline []services.EntityTimelineBucket `json:"timeline"` // Articles per day over the last `days` days
+	Total    int64                           `json:"total"`
+	Page     int                             `json:"page"`
+	Limit    int                             `json:"limit"`
+}
+
+// ListEntities handles GET /api/entities, listing the most covered entities
+func (h *EntityHandler) ListEntities(c *gin.Context) {
+	var query struct {
+		Type  string `form:"type" binding:"omitempty,oneof=person organization place"`
+		Q     string `form:"q"`
+		Limit int    `form:"limit,default=50" binding:"min=1,max=200"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	entities, err := h.entities.Top(query.Type, query.Q, query.Limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load entities")
+		return
+	}
+	if entities == nil {
+		entities = []models.Entity{}
+	}
+	c.JSON(http.StatusOK, gin.H{"entities": entities})
+}
+
+// GetEntity handles GET /api/entities/:name, listing all coverage of an entity.
+// The name may be written as in articles ("Christine Lagarde") or as its slug.
+func (h *EntityHandler) GetEntity(c *gin.Context) {
+	var query struct {
+		Type  string `form:"type" binding:"omitempty,oneof=person organization place"`
+		Limit int    `form:"limit,default=20" binding:"min=1,max=100"`
+		Page  int    `form:"page,default=1" binding:"min=1"`
+		Days  int    `form:"days,default=90" binding:"min=1,max=365"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	limit, page, days := query.Limit, query.Page, query.Days
+
+	entities, err := h.entities.Lookup(c.Param("name"), query.Type)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load entity")
+		return
+	}
+	if len(entities) == 0 {
+		respondError(c, http.StatusNotFound, "Entity not found")
+		return
+	}
+
+	ids := make([]uuid.UUID, len(entities))
+	for i, entity := range entities {
+		ids[i] = entity.ID
+	}
+	articles, total, err := h.entities.Coverage(ids, limit, (page-1)*limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load coverage")
+		return
+	}
+	timeline, err := h.entities.Timeline(ids, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load coverage")
+		return
+	}
+
+	response := EntityCoverageResponse{
+		Slug:     entities[0].Slug,
+		Entities: entities,
+		Articles: make([]ArticleSummary, len(articles)),
+		Timeline: timeline,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	}
+	if response.Timeline == nil {
+		response.Timeline = []services.EntityTimelineBucket{}
+	}
+	for i, article := range articles {
+		response.Articles[i] = newArticleSummary(article)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
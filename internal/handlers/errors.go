@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"open-news/internal/feeds"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// Codes of API error responses, one per kind of failure
+const (
+	ErrCodeInvalidRequest = "invalid_request"
+	ErrCodeUnauthorized   = "unauthorized"
+	ErrCodeForbidden      = "forbidden"
+	ErrCodeNotFound       = "not_found"
+	ErrCodeConflict       = "conflict"
+	ErrCodeRateLimited    = "rate_limited"
+	ErrCodeUnavailable    = "unavailable"
+	ErrCodeInternal       = "internal_error"
+)
+
+// APIError is the body of every JSON error response outside /xrpc, whose
+// errors follow the AT Protocol instead. Error repeats Message so clients
+// written against the earlier {"error": "..."} bodies keep working.
+type APIError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"` // Invalid parameters and what's wrong with each
+	Error   string            `json:"error"`
+}
+
+// errorCode returns the code of error responses with an HTTP status
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrCodeUnavailable
+	}
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrCodeInvalidRequest
+}
+
+// xrpcErrorName returns the XRPC error name of responses with an HTTP status
+func xrpcErrorName(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "AuthenticationRequired"
+	case http.StatusTooManyRequests:
+		return "RateLimitExceeded"
+	case http.StatusNotImplemented:
+		return "MethodNotImplemented"
+	}
+	if status >= 500 {
+		return "InternalServerError"
+	}
+	return "InvalidRequest"
+}
+
+// isXRPC reports whether a request is for an XRPC method
+func isXRPC(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, "/xrpc/")
+}
+
+// respondError responds with an error in the shape the route's clients
+// expect: an XRPC error under /xrpc and an APIError everywhere else
+func respondError(c *gin.Context, status int, message string) {
+	if isXRPC(c) {
+		xrpcError(c, status, xrpcErrorName(status), message)
+		return
+	}
+	c.JSON(status, APIError{Code: errorCode(status), Message: message, Error: message})
+}
+
+// abortWithError responds like respondError and stops the remaining handlers
+func abortWithError(c *gin.Context, status int, message string) {
+	respondError(c, status, message)
+	c.Abort()
+}
+
+// NotFoundHandler answers requests no route matches: XRPC methods this server
+// doesn't implement, and API paths that don't exist, get an error body in their
+// shape. Other paths get gin's plain 404 page.
+func NotFoundHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch {
+		case isXRPC(c):
+			xrpcError(c, http.StatusNotImplemented, "MethodNotImplemented", "Method not implemented")
+		case strings.HasPrefix(c.Request.URL.Path, "/api/"):
+			respondError(c, http.StatusNotFound, "Not found")
+		}
+	}
+}
+
+// respondInvalid responds 400 with what's wrong with each invalid parameter
+func respondInvalid(c *gin.Context, message string, details map[string]string) {
+	if isXRPC(c) {
+		xrpcError(c, http.StatusBadRequest, "InvalidRequest", message)
+		return
+	}
+	c.JSON(http.StatusBadRequest, APIError{Code: ErrCodeInvalidRequest, Message: message, Details: details, Error: message})
+}
+
+// invalidRequestErrors are service errors caused by what the client sent
+var invalidRequestErrors = []error{
+	services.ErrUnknownTopic,
+	services.ErrInvalidLanguage,
+	services.ErrInvalidCustomFeed,
+	services.ErrCustomFeedLimit,
+}
+
+// errorStatus maps an error from a service to the status of the response
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, feeds.ErrAuthRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	}
+	for _, invalid := range invalidRequestErrors {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// respondServiceError responds to an error from a service with the status it
+// maps to. Errors caused by the request are explained; others are logged and
+// answered with message, so internal details don't reach clients.
+func respondServiceError(c *gin.Context, err error, message string) {
+	status := errorStatus(err)
+	switch {
+	case status == http.StatusBadRequest:
+		message = err.Error()
+	case status >= 500:
+		log.Printf("%s %s: %s: %v", c.Request.Method, c.Request.URL.Path, message, err)
+	}
+	respondError(c, status, message)
+}
+
+// bindQuery binds the query parameters to query, a pointer to a struct with
+// form and binding tags, or responds 400 with what's wrong with each of them
+func bindQuery(c *gin.Context, query interface{}) bool {
+	if err := c.ShouldBindQuery(query); err != nil {
+		respondInvalid(c, "Invalid query parameters", queryErrorDetails(c, query, err))
+		return false
+	}
+	return true
+}
+
+// queryErrorDetails describes why query parameters failed to bind, by name
+func queryErrorDetails(c *gin.Context, query interface{}, err error) map[string]string {
+	details := make(map[string]string)
+
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		queryType := reflect.TypeOf(query).Elem()
+		for _, fieldErr := range invalid {
+			name := fieldErr.Field()
+			if field, ok := queryType.FieldByName(fieldErr.StructField()); ok {
+				name, _, _ = strings.Cut(field.Tag.Get("form"), ",")
+			}
+			details[name] = describeValidation(fieldErr)
+		}
+		return details
+	}
+
+	// Values that don't parse are reported without their parameter's name,
+	// so find the parameters with that value
+	var parseErr *strconv.NumError
+	if errors.As(err, &parseErr) {
+		for name, values := range c.Request.URL.Query() {
+			for _, value := range values {
+				if value == parseErr.Num {
+					details[name] = describeParse(parseErr)
+				}
+			}
+		}
+	}
+	if len(details) == 0 {
+		details["query"] = err.Error()
+	}
+	return details
+}
+
+// describeValidation explains a failed binding rule
+func describeValidation(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	}
+	return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+}
+
+// describeParse explains a value that didn't parse
+func describeParse(parseErr *strconv.NumError) string {
+	switch parseErr.Func {
+	case "ParseBool":
+		return "must be true or false"
+	case "ParseFloat":
+		return "must be a number"
+	}
+	return "must be a whole number"
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/feed", func(c *gin.Context) {
+		var q feedQuery
+		if !bindQuery(c, &q) {
+			return
+		}
+		c.JSON(http.StatusOK, q)
+	})
+
+	tests := []struct {
+		query   string
+		status  int
+		details map[string]string
+	}{
+		{"", http.StatusOK, nil},
+		{"?limit=100&sentiment=positive", http.StatusOK, nil},
+		{"?limit=500", http.StatusBadRequest, map[string]string{"limit": "must be at most 100"}},
+		{"?limit=0&page=0", http.StatusBadRequest, map[string]string{"limit": "must be at least 1", "page": "must be at least 1"}},
+		{"?sentiment=angry", http.StatusBadRequest, map[string]string{"sentiment": "must be one of positive, neutral, negative"}},
+		{"?limit=ten", http.StatusBadRequest, map[string]string{"limit": "must be a whole number"}},
+		{"?long_reads=maybe", http.StatusBadRequest, map[string]string{"long_reads": "must be true or false"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/feed"+tt.query, nil))
+		assert.Equal(t, tt.status, w.Code, tt.query)
+		if tt.status != http.StatusOK {
+			var body APIError
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, ErrCodeInvalidRequest, body.Code, tt.query)
+			assert.Equal(t, tt.details, body.Details, tt.query)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/feed", nil))
+	var q feedQuery
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	assert.Equal(t, 20, q.Limit, "defaults apply")
+	assert.Equal(t, 1, q.Page)
+}
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.NoRoute(NotFoundHandler())
+	fail := func(c *gin.Context) { respondError(c, http.StatusTooManyRequests, "Slow down") }
+	r.GET("/api/fail", fail)
+	r.GET("/xrpc/fail", fail)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := request("/api/fail")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, `{"code":"rate_limited","message":"Slow down","error":"Slow down"}`, w.Body.String())
+
+	w = request("/xrpc/fail")
+	assert.JSONEq(t, `{"error":"RateLimitExceeded","message":"Slow down"}`, w.Body.String())
+
+	w = request("/api/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":"not_found","message":"Not found","error":"Not found"}`, w.Body.String())
+
+	w = request("/xrpc/app.bsky.feed.getTimeline")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.JSONEq(t, `{"error":"MethodNotImplemented","message":"Method not implemented"}`, w.Body.String())
+
+	w = request("/missing.html")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+}
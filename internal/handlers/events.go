@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// eventPollInterval is how often event streams check for new breaking stories.
+	// Polling the database lets every instance serve streams, whichever one
+	// detected the story.
+	eventPollInterval = 15 * time.Second
+
+	// eventBatchSize caps the events sent per poll
+	eventBatchSize = 50
+)
+
+// EventsHandler streams breaking news events to subscribers
+type EventsHandler struct {
+	breaking *services.BreakingService
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(db *gorm.DB) *EventsHandler {
+	return &EventsHandler{breaking: services.NewBreakingService(db, services.LoadBreakingConfig())}
+}
+
+// StreamBreaking handles GET /api/events/breaking, a server-sent event stream
+// with a "breaking" event for each story marked breaking. Event IDs are the
+// detection times, so reconnecting clients sending Last-Event-ID receive what
+// they missed.
+func (h *EventsHandler) StreamBreaking(c *gin.Context) {
+	cursor := time.Now()
+	if lastID := c.GetHeader("Last-Event-ID"); lastID != "" {
+		if parsed, err := time.Parse(time.RFC3339Nano, lastID); err == nil {
+			cursor = parsed
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	// Flush the headers, and any missed events, right away
+	first := make(chan struct{}, 1)
+	first <- struct{}{}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-first:
+		case <-ticker.C:
+		}
+
+		events, err := h.breaking.Since(cursor, eventBatchSize)
+		if err != nil {
+			log.Printf("Failed to load breaking events: %v", err)
+			return true
+		}
+		if len(events) == 0 {
+			// Comments keep proxies from closing an idle stream
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		}
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.DetectedAt.Format(time.RFC3339Nano), event.Type, data)
+			cursor = event.DetectedAt
+		}
+		return true
+	})
+}
@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
-	"strconv"
 
 	"open-news/internal/feeds"
 	"open-news/internal/worker"
@@ -28,32 +28,34 @@ func NewFeedHandler(db *gorm.DB, workerService *worker.WorkerService) *FeedHandl
 
 // GetGlobalFeed handles GET /api/feeds/global
 func (h *FeedHandler) GetGlobalFeed(c *gin.Context) {
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	
-	if limit > 100 {
-		limit = 100
-	}
-	if limit < 1 {
-		limit = 20
-	}
-	if page < 1 {
-		page = 1
+	var query feedQuery
+	if !bindQuery(c, &query) {
+		return
 	}
-	
-	offset := (page - 1) * limit
+	limit, offset := query.Limit, (query.Page-1)*query.Limit
+	content := query.contentFilter()
 
-	// Get the global feed
-	feedResponse, err := h.feedService.GetGlobalFeed(limit, offset)
+	// Get the global feed, ranked on the fly when content filters are set
+	feedService := h.feedService.WithContext(c.Request.Context())
+	var feedResponse *feeds.FeedResponse
+	var err error
+	if content.IsZero() {
+		feedResponse, err = feedService.GetGlobalFeed(limit, offset)
+	} else {
+		feedResponse, err = feedService.GetContentFilteredFeed(content, nil, limit, offset)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve global feed",
-			"details": err.Error(),
-		})
+		respondServiceError(c, err, "Failed to retrieve global feed")
 		return
 	}
 
+	// Add shared-by context when the request is authenticated
+	if userID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		if err := feedService.AttachShareContext(userID, feedResponse.Items); err != nil {
+			log.Printf("Failed to attach share context: %v", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, feedResponse)
 }
 
@@ -62,49 +64,59 @@ func (h *FeedHandler) GetPersonalizedFeed(c *gin.Context) {
 	// Get user ID from context (would be set by auth middleware)
 	userIDStr := c.GetString("user_id")
 	if userIDStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User authentication required",
-		})
+		respondError(c, http.StatusUnauthorized, "User authentication required")
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID format",
-		})
+		respondError(c, http.StatusBadRequest, "Invalid user ID format")
 		return
 	}
 
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	
-	if limit > 100 {
-		limit = 100
-	}
-	if limit < 1 {
-		limit = 20
-	}
-	if page < 1 {
-		page = 1
+	var query feedQuery
+	if !bindQuery(c, &query) {
+		return
 	}
-	
-	offset := (page - 1) * limit
+	limit, offset := query.Limit, (query.Page-1)*query.Limit
+	content := query.contentFilter()
 
-	// Get the personalized feed
-	feedResponse, err := h.feedService.GetPersonalizedFeed(userID, limit, offset)
+	// Get the personalized feed, ranked on the fly when content filters are set
+	feedService := h.feedService.WithContext(c.Request.Context())
+	var feedResponse *feeds.FeedResponse
+	if content.IsZero() {
+		feedResponse, err = feedService.GetPersonalizedFeed(userID, limit, offset)
+	} else {
+		feedResponse, err = feedService.GetContentFilteredFeed(content, &userID, limit, offset)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve personalized feed",
-			"details": err.Error(),
-		})
+		respondServiceError(c, err, "Failed to retrieve personalized feed")
 		return
 	}
 
 	c.JSON(http.StatusOK, feedResponse)
 }
 
+// feedQuery is the query of the /api/feeds endpoints
+type feedQuery struct {
+	Limit     int     `form:"limit,default=20" binding:"min=1,max=100"`
+	Page      int     `form:"page,default=1" binding:"min=1"`
+	MinWords  int     `form:"min_words" binding:"min=0"`
+	LongReads bool    `form:"long_reads"`
+	MaxGrade  float64 `form:"max_grade" binding:"min=0"`
+	Sentiment string  `form:"sentiment" binding:"omitempty,oneof=positive neutral negative"`
+}
+
+// contentFilter returns the length, reading level and tone the query asks for.
+// long_reads=true asks for at least feeds.LongReadWords words.
+func (q feedQuery) contentFilter() feeds.ContentFilter {
+	filter := feeds.ContentFilter{MinWordCount: q.MinWords, MaxReadingGrade: q.MaxGrade, Sentiment: q.Sentiment}
+	if q.LongReads && filter.MinWordCount < feeds.LongReadWords {
+		filter.MinWordCount = feeds.LongReadWords
+	}
+	return filter
+}
+
 // HealthCheck handles GET /health
 func (h *FeedHandler) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -1,34 +1,80 @@
 package handlers
 
 import (
-	"html/template"
+	"io/fs"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // FeedPageHandler handles web feed pages
 type FeedPageHandler struct {
-	feedService *feeds.FeedService
+	db               *gorm.DB
+	feedService      *feeds.FeedService
+	analyticsService *services.AnalyticsService
+	registry         *feeds.Registry
+	static           fs.FS // The static directory, with the main feed page
 }
 
 // NewFeedPageHandler creates a new feed page handler
-func NewFeedPageHandler(db *gorm.DB) *FeedPageHandler {
+func NewFeedPageHandler(db *gorm.DB, static fs.FS) *FeedPageHandler {
 	return &FeedPageHandler{
-		feedService: feeds.NewFeedService(db),
+		db:               db,
+		feedService:      feeds.NewFeedService(db),
+		analyticsService: services.NewAnalyticsService(db),
+		registry:         feeds.NewRegistry(db),
+		static:           static,
 	}
 }
 
 // ServeMainFeedPage serves the main feed page
 func (h *FeedPageHandler) ServeMainFeedPage(c *gin.Context) {
 	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.File("static/feed.html")
+	http.ServeFileFS(c.Writer, c.Request, h.static, "feed.html")
+}
+
+// feedView is the data passed to the feed_content template
+type feedView struct {
+	Title       string
+	Icon        string
+	Items       []feedItemView
+	UpdatedAt   time.Time
+	Page        int
+	IsWidget    bool
+	CurrentPath string
+	PrevURL     string
+	NextURL     string
+}
+
+// feedItemView is a feed item with the tracked link readers follow
+type feedItemView struct {
+	feeds.FeedItemDetails
+	ClickURL string
+}
+
+// widgetView is the data passed to the widget template
+type widgetView struct {
+	Feed              feedView
+	Theme             Theme
+	Compact           bool
+	AutoRefreshMillis int
+}
+
+// errorView is the data passed to the feed_error template
+type errorView struct {
+	Icon    string
+	Title   string
+	Message string
 }
 
 // ServeGlobalFeedHTML serves the global feed as HTML
@@ -50,35 +96,29 @@ func (h *FeedPageHandler) ServeGlobalFeedHTML(c *gin.Context) {
 	offset := (page - 1) * limit
 
 	// Get the global feed
-	feedResponse, err := h.feedService.GetGlobalFeed(limit, offset)
+	feedResponse, err := h.feedService.WithContext(c.Request.Context()).GetGlobalFeed(limit, offset)
 	if err != nil {
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.String(http.StatusInternalServerError, `
-			<div class="error-state">
-				<i class="fas fa-exclamation-triangle"></i>
-				<h3>Failed to load feed</h3>
-				<p>%s</p>
-			</div>
-		`, err.Error())
+		renderTemplate(c, feedTemplates, http.StatusInternalServerError, "feed_error", errorView{
+			Icon:    "fa-exclamation-triangle",
+			Title:   "Failed to load feed",
+			Message: err.Error(),
+		})
 		return
 	}
 
 	// Render HTML template
-	h.renderFeedHTML(c, feedResponse, "Global Feed", "🌍", page, limit, "/feed/global")
+	h.renderFeedHTML(c, feedResponse, nil, "global", "Global Feed", "🌍", page, limit, "/feed/global")
 }
 
 // ServePersonalFeedHTML serves a personalized feed as HTML
 func (h *FeedPageHandler) ServePersonalFeedHTML(c *gin.Context) {
 	userIdentifier := c.Query("user")
 	if userIdentifier == "" {
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.String(http.StatusBadRequest, `
-			<div class="error-state">
-				<i class="fas fa-user-slash"></i>
-				<h3>User Required</h3>
-				<p>Please provide a user handle or DID in the 'user' parameter.</p>
-			</div>
-		`)
+		renderTemplate(c, feedTemplates, http.StatusBadRequest, "feed_error", errorView{
+			Icon:    "fa-user-slash",
+			Title:   "User Required",
+			Message: "Please provide a user handle or DID in the 'user' parameter.",
+		})
 		return
 	}
 
@@ -100,27 +140,18 @@ func (h *FeedPageHandler) ServePersonalFeedHTML(c *gin.Context) {
 
 	// TODO: Implement personal feed service
 	// For now, return global feed with user context
-	feedResponse, err := h.feedService.GetGlobalFeed(limit, offset)
+	feedResponse, err := h.feedService.WithContext(c.Request.Context()).GetGlobalFeed(limit, offset)
 	if err != nil {
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.String(http.StatusInternalServerError, `
-			<div class="error-state">
-				<i class="fas fa-exclamation-triangle"></i>
-				<h3>Failed to load feed</h3>
-				<p>%s</p>
-			</div>
-		`, err.Error())
+		renderTemplate(c, feedTemplates, http.StatusInternalServerError, "feed_error", errorView{
+			Icon:    "fa-exclamation-triangle",
+			Title:   "Failed to load feed",
+			Message: err.Error(),
+		})
 		return
 	}
 
-	// Clean user identifier for display
-	displayUser := userIdentifier
-	if strings.HasPrefix(userIdentifier, "did:plc:") {
-		displayUser = userIdentifier[:12] + "..."
-	}
-
 	// Render HTML template
-	h.renderFeedHTML(c, feedResponse, "Personal Feed - "+displayUser, "👤", page, limit, "/feed/personal?user="+userIdentifier)
+	h.renderFeedHTML(c, feedResponse, h.personalUser(userIdentifier), "personal", "Personal Feed - "+displayUser(userIdentifier), "👤", page, limit, "/feed/personal?user="+url.QueryEscape(userIdentifier))
 }
 
 // ServeGlobalWidget serves the embeddable global feed widget
@@ -138,7 +169,6 @@ func (h *FeedPageHandler) ServePersonalWidget(c *gin.Context) {
 func (h *FeedPageHandler) serveWidget(c *gin.Context, feedType string, userIdentifier string) {
 	// Parse widget parameters
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	theme := c.DefaultQuery("theme", "light")
 	compact := c.DefaultQuery("compact", "false")
 	autoRefresh, _ := strconv.Atoi(c.DefaultQuery("autorefresh", "300"))
 	
@@ -153,237 +183,114 @@ func (h *FeedPageHandler) serveWidget(c *gin.Context, feedType string, userIdent
 	}
 
 	// Get feed data
-	feedResponse, err := h.feedService.GetGlobalFeed(limit, 0)
+	feedResponse, err := h.feedService.WithContext(c.Request.Context()).GetGlobalFeed(limit, 0)
 	if err != nil {
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.String(http.StatusInternalServerError, `
-			<div class="error-state">
-				<i class="fas fa-exclamation-triangle"></i>
-				<h3>Widget Error</h3>
-				<p>Failed to load feed data</p>
-			</div>
-		`)
+		renderTemplate(c, feedTemplates, http.StatusInternalServerError, "feed_error", errorView{
+			Icon:    "fa-exclamation-triangle",
+			Title:   "Widget Error",
+			Message: "Failed to load feed data",
+		})
 		return
 	}
 
+	var userID *uuid.UUID
+	if feedType == "personal" {
+		userID = h.personalUser(userIdentifier)
+	}
+	h.analyticsService.RecordImpressions(servedFeed(userID, feedType, SurfaceWidget, feedResponse.Items))
+
 	// Determine widget title
 	title := "Global News Feed"
 	icon := "🌍"
 	if feedType == "personal" && userIdentifier != "" {
-		displayUser := userIdentifier
-		if strings.HasPrefix(userIdentifier, "did:plc:") {
-			displayUser = userIdentifier[:12] + "..."
-		}
-		title = "Personal Feed - " + displayUser
+		title = "Personal Feed - " + displayUser(userIdentifier)
 		icon = "👤"
 	}
 
-	// Widget CSS classes
-	widgetClasses := "widget"
-	if compact == "true" {
-		widgetClasses += " compact"
-	}
-
-	// Render widget HTML
-	widgetHTML := `
-<!DOCTYPE html>
-<html lang="en" data-theme="` + theme + `">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>` + title + `</title>
-    <script src="https://unpkg.com/htmx.org@2.0.2"></script>
-    <link rel="stylesheet" href="` + c.Request.Header.Get("Origin") + `/static/feed.css">
-    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap" rel="stylesheet">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.0.0/css/all.min.css">
-    <style>
-        body { margin: 0; padding: 1rem; }
-        .feed-header { margin-bottom: 1rem; }
-        .feed-title { font-size: 1.25rem; }
-    </style>
-</head>
-<body>
-    <div class="` + widgetClasses + `">`
-
-	// Add feed content
-	widgetHTML += h.generateFeedHTML(feedResponse, title, icon, 1, limit, true, "")
-
-	// Add auto-refresh script if enabled
-	if autoRefresh > 0 {
-		widgetHTML += `
-    <script>
-        setInterval(function() {
-            location.reload();
-        }, ` + strconv.Itoa(autoRefresh*1000) + `);
-    </script>`
-	}
-
-	widgetHTML += `
-    </div>
-</body>
-</html>`
-
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, widgetHTML)
+	renderTemplate(c, feedTemplates, http.StatusOK, "widget", widgetView{
+		Feed:              h.newFeedView(feedResponse, userID, feedType, title, icon, 1, limit, true, ""),
+		Theme:             themeFromRequest(c),
+		Compact:           compact == "true",
+		AutoRefreshMillis: autoRefresh * 1000,
+	})
 }
 
-// renderFeedHTML renders the feed HTML for the main page
-func (h *FeedPageHandler) renderFeedHTML(c *gin.Context, feedResponse *feeds.FeedResponse, title, icon string, page, limit int, currentPath string) {
-	html := h.generateFeedHTML(feedResponse, title, icon, page, limit, false, currentPath)
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
+// renderFeedHTML renders the feed HTML for the main page. userID is the reader
+// of a personal feed, or nil.
+func (h *FeedPageHandler) renderFeedHTML(c *gin.Context, feedResponse *feeds.FeedResponse, userID *uuid.UUID, feed, title, icon string, page, limit int, currentPath string) {
+	h.analyticsService.RecordImpressions(servedFeed(userID, feed, SurfaceWeb, feedResponse.Items))
+	view := h.newFeedView(feedResponse, userID, feed, title, icon, page, limit, false, currentPath)
+	renderTemplate(c, feedTemplates, http.StatusOK, "feed_content", view)
 }
 
-// generateFeedHTML generates HTML for feed content
-func (h *FeedPageHandler) generateFeedHTML(feedResponse *feeds.FeedResponse, title, icon string, page, limit int, isWidget bool, currentPath string) string {
-	html := `<div class="feed-header">
-        <h1 class="feed-title">
-            <span>` + icon + `</span>
-            ` + title + `
-        </h1>
-        <div class="feed-meta">
-            <div class="feed-stats">
-                <i class="fas fa-newspaper"></i>
-                <span>` + strconv.Itoa(len(feedResponse.Items)) + ` articles</span>
-            </div>
-            <div class="feed-stats">
-                <i class="fas fa-clock"></i>
-                <span>Updated ` + feedResponse.Meta.LastUpdatedAt.Format("Jan 2, 3:04 PM") + `</span>
-            </div>`
-	
-	if !isWidget {
-		html += `
-            <button class="refresh-btn" 
-                    hx-get="` + currentPath + `" 
-                    hx-target="#feed-container"
-                    hx-indicator="#loading">
-                <i class="fas fa-sync-alt"></i> Refresh
-            </button>`
-	}
-	
-	html += `
-        </div>
-    </div>`
-
-	if len(feedResponse.Items) == 0 {
-		html += `
-    <div class="empty-state">
-        <i class="fas fa-newspaper"></i>
-        <h3>No articles found</h3>
-        <p>Check back later for new content or try seeding the database with sources.</p>
-    </div>`
-		return html
+// newFeedView builds the template data for a page of feed items.
+// feed names the feed in click analytics, e.g. "global", and clicks on a
+// personal feed are attributed to its reader, userID.
+func (h *FeedPageHandler) newFeedView(feedResponse *feeds.FeedResponse, userID *uuid.UUID, feed, title, icon string, page, limit int, isWidget bool, currentPath string) feedView {
+	view := feedView{
+		Title:       title,
+		Icon:        icon,
+		Items:       make([]feedItemView, len(feedResponse.Items)),
+		UpdatedAt:   feedResponse.Meta.LastUpdatedAt,
+		Page:        page,
+		IsWidget:    isWidget,
+		CurrentPath: currentPath,
 	}
 
-	html += `<div class="feed-items">`
-
-	for _, item := range feedResponse.Items {
-		qualityClass := "low"
-		if item.Article.QualityScore >= 0.7 {
-			qualityClass = "high"
-		} else if item.Article.QualityScore >= 0.5 {
-			qualityClass = "medium"
-		}
-
-		// Format published time
-		publishedTime := "Unknown"
-		if item.Article.PublishedAt != nil {
-			publishedTime = formatRelativeTime(*item.Article.PublishedAt)
-		}
-
-		// Safe title and description
-		title := template.HTMLEscapeString(item.Article.Title)
-		description := template.HTMLEscapeString(item.Article.Description)
-		if len(description) > 200 {
-			description = description[:200] + "..."
+	surface := SurfaceWeb
+	if isWidget {
+		surface = SurfaceWidget
+	}
+	for i, item := range feedResponse.Items {
+		view.Items[i] = feedItemView{
+			FeedItemDetails: item,
+			ClickURL:        clickURL(item.Article.ID, feed, surface, item.Position, item.Source.ID, userID),
 		}
+	}
 
-		html += `
-        <article class="feed-item">
-            <div class="article-header">
-                <div class="article-content">
-                    <h2 class="article-title">
-                        <a href="` + template.HTMLEscapeString(item.Article.URL) + `" target="_blank" rel="noopener">
-                            ` + title + `
-                        </a>
-                    </h2>
-                    <p class="article-description">` + description + `</p>
-                </div>`
-		
-		if item.Article.ImageURL != "" {
-			html += `
-                <img src="` + template.HTMLEscapeString(item.Article.ImageURL) + `" 
-                     alt="Article image" 
-                     class="article-image"
-                     loading="lazy">`
-		}
-		
-		html += `
-            </div>
-            <div class="article-footer">
-                <div class="source-info">`
-		
-		if item.Source.Avatar != "" {
-			html += `
-                    <img src="` + template.HTMLEscapeString(item.Source.Avatar) + `" 
-                         alt="` + template.HTMLEscapeString(item.Source.DisplayName) + `" 
-                         class="source-avatar">`
-		} else {
-			html += `<div class="source-avatar" style="background: var(--primary-color); display: flex; align-items: center; justify-content: center; color: white; font-weight: bold;">` + 
-				string([]rune(item.Source.DisplayName)[0]) + `</div>`
+	// Add pagination if not a widget
+	if !isWidget && len(feedResponse.Items) == limit {
+		view.NextURL = pageURL(currentPath, page+1, limit)
+		if page > 1 {
+			view.PrevURL = pageURL(currentPath, page-1, limit)
 		}
-		
-		html += `
-                    <div class="source-details">
-                        <div class="source-name">` + template.HTMLEscapeString(item.Source.DisplayName) + `</div>
-                        <div class="source-handle">@` + template.HTMLEscapeString(item.Source.Handle) + `</div>
-                    </div>
-                </div>
-                <div class="article-meta">
-                    <span>` + publishedTime + `</span>
-                    <div class="quality-score ` + qualityClass + `">
-                        <i class="fas fa-star"></i>
-                        ` + strconv.FormatFloat(item.Article.QualityScore, 'f', 1, 64) + `
-                    </div>
-                </div>
-            </div>
-        </article>`
 	}
 
-	html += `</div>`
+	return view
+}
 
-	// Add pagination if not a widget
-	if !isWidget && len(feedResponse.Items) == limit {
-		html += h.generatePaginationHTML(page, limit, currentPath)
+// pageURL appends pagination parameters to a feed path that may already have a query string
+func pageURL(path string, page, limit int) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
 	}
+	return path + separator + "page=" + strconv.Itoa(page) + "&limit=" + strconv.Itoa(limit)
+}
 
-	return html
+// displayUser shortens DIDs for display in feed titles
+func displayUser(userIdentifier string) string {
+	if strings.HasPrefix(userIdentifier, "did:plc:") && len(userIdentifier) > 12 {
+		return userIdentifier[:12] + "..."
+	}
+	return userIdentifier
 }
 
-// generatePaginationHTML generates pagination controls
-func (h *FeedPageHandler) generatePaginationHTML(currentPage, limit int, currentPath string) string {
-	html := `<div class="pagination">`
-	
-	if currentPage > 1 {
-		html += `
-        <button hx-get="` + currentPath + `?page=` + strconv.Itoa(currentPage-1) + `&limit=` + strconv.Itoa(limit) + `" 
-                hx-target="#feed-container"
-                hx-indicator="#loading">
-            <i class="fas fa-chevron-left"></i> Previous
-        </button>`
+// personalUser returns the ID of the stored user a personal feed page is for,
+// given their DID or handle, or nil when they aren't stored
+func (h *FeedPageHandler) personalUser(userIdentifier string) *uuid.UUID {
+	if userIdentifier == "" {
+		return nil
 	}
-	
-	html += `<span class="current-page">Page ` + strconv.Itoa(currentPage) + `</span>`
-	
-	html += `
-        <button hx-get="` + currentPath + `?page=` + strconv.Itoa(currentPage+1) + `&limit=` + strconv.Itoa(limit) + `" 
-                hx-target="#feed-container"
-                hx-indicator="#loading">
-            Next <i class="fas fa-chevron-right"></i>
-        </button>`
-	
-	html += `</div>`
-	return html
+	column := "handle"
+	if strings.HasPrefix(userIdentifier, "did:") {
+		column = "blue_sky_d_id"
+	}
+	var user models.User
+	if err := h.db.Select("id").Where(column+" = ?", strings.TrimPrefix(userIdentifier, "@")).First(&user).Error; err != nil {
+		return nil
+	}
+	return &user.ID
 }
 
 // Helper functions
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"open-news/internal/labeler"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// labelPollInterval is how often label subscriptions check for new labels.
+	// Polling the database lets every instance serve subscriptions, whichever
+	// one issued the labels.
+	labelPollInterval = 5 * time.Second
+
+	// labelBatchSize caps the labels sent per frame
+	labelBatchSize = 100
+
+	// labelWriteTimeout drops subscribers that stop reading
+	labelWriteTimeout = 10 * time.Second
+)
+
+var labelUpgrader = websocket.Upgrader{
+	// Subscriptions are public and read-only, like the labels themselves
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// LabelerHandler serves the labels the labeler issued over the AT Protocol
+// label endpoints
+type LabelerHandler struct {
+	labels *services.LabelService
+}
+
+// NewLabelerHandler creates a new labeler handler
+func NewLabelerHandler(db *gorm.DB) *LabelerHandler {
+	return &LabelerHandler{labels: services.NewLabelService(db, nil)}
+}
+
+// QueryLabels returns the labels on subjects matching uriPatterns, where a
+// trailing * matches a prefix, oldest first
+// GET /xrpc/com.atproto.label.queryLabels?uriPatterns=did:plc:example&uriPatterns=at://*
+func (h *LabelerHandler) QueryLabels(c *gin.Context) {
+	query := services.LabelQuery{
+		URIPatterns: c.QueryArray("uriPatterns"),
+		Sources:     c.QueryArray("sources"),
+		Limit:       services.DefaultLabelPageSize,
+	}
+	if len(query.URIPatterns) == 0 {
+		xrpcError(c, http.StatusBadRequest, "InvalidRequest", "uriPatterns is required")
+		return
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > services.MaxLabelPageSize {
+			xrpcError(c, http.StatusBadRequest, "InvalidRequest", "limit must be between 1 and 250")
+			return
+		}
+		query.Limit = limit
+	}
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || cursor < 0 {
+			xrpcError(c, http.StatusBadRequest, "InvalidRequest", "Malformed cursor")
+			return
+		}
+		query.Cursor = cursor
+	}
+
+	labels, next, err := h.labels.Query(query)
+	if err != nil {
+		log.Printf("Failed to query labels: %v", err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to query labels")
+		return
+	}
+	response := gin.H{"labels": labels}
+	if next > 0 {
+		response["cursor"] = strconv.FormatInt(next, 10)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SubscribeLabels streams labels over a WebSocket as CBOR #labels frames. With
+// ?cursor=N it first sends every label after seq N; without one, only labels
+// issued from now on.
+// GET /xrpc/com.atproto.label.subscribeLabels
+func (h *LabelerHandler) SubscribeLabels(c *gin.Context) {
+	latest, err := h.labels.LatestSeq()
+	if err != nil {
+		log.Printf("Failed to get the latest label: %v", err)
+		xrpcError(c, http.StatusInternalServerError, "InternalServerError", "Failed to read labels")
+		return
+	}
+	cursor := latest
+	futureCursor := false
+	if value := c.Query("cursor"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			xrpcError(c, http.StatusBadRequest, "InvalidRequest", "Malformed cursor")
+			return
+		}
+		cursor = parsed
+		futureCursor = parsed > latest
+	}
+
+	conn, err := labelUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if futureCursor {
+		writeLabelFrame(conn, map[string]interface{}{"op": -1},
+			map[string]interface{}{"error": "FutureCursor", "message": "Cursor is ahead of the stream"})
+		return
+	}
+
+	// Notice subscribers that hang up, since nothing is read from them
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(labelPollInterval)
+	defer ticker.Stop()
+	for {
+		rows, err := h.labels.Since(cursor, labelBatchSize)
+		if err != nil {
+			log.Printf("Failed to read labels for a subscriber: %v", err)
+			return
+		}
+		for _, row := range rows {
+			body := map[string]interface{}{
+				"seq":    row.Seq,
+				"labels": []interface{}{services.WireLabel(row).CBOR()},
+			}
+			if err := writeLabelFrame(conn, map[string]interface{}{"op": 1, "t": "#labels"}, body); err != nil {
+				return
+			}
+			cursor = row.Seq
+		}
+		if len(rows) == labelBatchSize {
+			continue // Catch up before waiting
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeLabelFrame writes an event stream frame: a CBOR header followed by a
+// CBOR body, in one binary message
+func writeLabelFrame(conn *websocket.Conn, header, body map[string]interface{}) error {
+	var frame bytes.Buffer
+	for _, part := range []map[string]interface{}{header, body} {
+		data, err := labeler.EncodeCBOR(part)
+		if err != nil {
+			return err
+		}
+		frame.Write(data)
+	}
+	conn.SetWriteDeadline(time.Now().Add(labelWriteTimeout))
+	return conn.WriteMessage(websocket.BinaryMessage, frame.Bytes())
+}
+
+// xrpcError writes an XRPC error response
+func xrpcError(c *gin.Context, status int, name, message string) {
+	c.JSON(status, gin.H{"error": name, "message": message})
+}
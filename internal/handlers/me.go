@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MeHandler serves the signed-in user's own account, settings and learned preferences
+type MeHandler struct {
+	db          *gorm.DB
+	verifier    tokenVerifier
+	accounts    *services.UserAccountService
+	jobs        *services.JobService
+	preferences *services.PreferencesService
+	customFeeds *services.CustomFeedService
+}
+
+// NewMeHandler creates a new handler for the signed-in user's endpoints
+func NewMeHandler(db *gorm.DB) *MeHandler {
+	return &MeHandler{
+		db:          db,
+		verifier:    newTokenVerifier(),
+		accounts:    services.NewUserAccountService(db),
+		jobs:        services.NewJobService(db),
+		preferences: services.NewPreferencesService(db),
+		customFeeds: services.NewCustomFeedService(db),
+	}
+}
+
+// PreferencesResponse lists what a user's personal feeds favor
+type PreferencesResponse struct {
+	FollowedTopics   []string                   `json:"followed_topics"`    // Topics the user chose to follow
+	Languages        []string                   `json:"languages"`          // Languages the user reads; empty for any
+	ShowSeenArticles bool                       `json:"show_seen_articles"` // Articles already served aren't pushed behind fresh ones
+	DigestSubscribed bool                       `json:"digest_subscribed"`  // The user receives the top-stories digest by direct message
+	Topics           []models.UserTopicAffinity `json:"topics"`             // Learned topic weights, strongest first; they sum to at most 1
+}
+
+// ExportResponse describes a user's data export
+type ExportResponse struct {
+	*models.UserExport
+	DownloadURL string `json:"download_url,omitempty"` // Where the archive can be downloaded, once ready
+}
+
+// UpdatePreferencesRequest is the body of PUT /api/me/preferences. Only the
+// fields present are changed.
+type UpdatePreferencesRequest struct {
+	FollowedTopics   *[]string `json:"followed_topics"`
+	Languages        *[]string `json:"languages"`
+	ShowSeenArticles *bool     `json:"show_seen_articles"`
+	DigestSubscribed *bool     `json:"digest_subscribed"`
+}
+
+// SetLanguagesRequest is the body of PUT /api/me/languages
+type SetLanguagesRequest struct {
+	Languages []string `json:"languages"`
+}
+
+// Auth verifies the AT Protocol JWT a Bluesky client sends as a bearer token
+// and sets user_id to the stored user with the token's DID. Users are stored
+// when they first request a personal feed, so DIDs without one get a 404.
+func (h *MeHandler) Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		did, valid := "", false
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			did, valid = h.verifier.ValidateToken(authHeader)
+		}
+		if !valid {
+			abortWithError(c, http.StatusUnauthorized, "A valid Bluesky token is required")
+			return
+		}
+
+		var user models.User
+		err := h.db.Select("id").Where("blue_sky_d_id = ?", did).First(&user).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				abortWithError(c, http.StatusNotFound, "No account is stored for this user")
+				return
+			}
+			log.Printf("Failed to look up user %s: %v", did, err)
+			abortWithError(c, http.StatusInternalServerError, "Failed to look up user")
+			return
+		}
+		c.Set("user_id", user.ID.String())
+		c.Next()
+	}
+}
+
+// requestUser returns the authenticated user, or responds with an error
+func requestUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.GetString("user_id")
+	if userIDStr == "" {
+		respondError(c, http.StatusUnauthorized, "User authentication required")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// GetProfile handles GET /api/me, returning the user's stored record and how
+// many follows and custom feeds are stored for them
+func (h *MeHandler) GetProfile(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	profile, err := h.accounts.Profile(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to load profile")
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteAccount handles DELETE /api/me, deleting the user and their personal
+// data. Their clicks and impressions are kept anonymized.
+func (h *MeHandler) DeleteAccount(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	if err := h.accounts.DeleteAccount(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Failed to delete user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to delete account")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RequestExport handles GET /api/me/export. It starts building an archive of
+// everything stored about the user, or reports on the one already requested:
+// 202 while it's being built, then 200 with a download_url until it expires.
+func (h *MeHandler) RequestExport(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	export, err := h.accounts.RequestExport(h.jobs, userID, time.Now())
+	if err != nil {
+		log.Printf("Failed to request export for user %s: %v", userID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to request export")
+		return
+	}
+
+	if export.Status != models.UserExportStatusReady {
+		c.JSON(http.StatusAccepted, ExportResponse{UserExport: export})
+		return
+	}
+	c.JSON(http.StatusOK, ExportResponse{
+		UserExport:  export,
+		DownloadURL: fmt.Sprintf("/api/me/export/%s/download", export.ID),
+	})
+}
+
+// DownloadExport handles GET /api/me/export/:id/download, serving a ready
+// archive as a JSON file
+func (h *MeHandler) DownloadExport(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+	export, err := h.accounts.Export(userID, exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Export not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to load export")
+		return
+	}
+	if export.Status != models.UserExportStatusReady || export.ExpiresAt == nil || !export.ExpiresAt.After(time.Now()) {
+		respondError(c, http.StatusNotFound, "Export isn't ready or has expired")
+		return
+	}
+
+	filename := fmt.Sprintf("open-news-export-%s.json", export.CompletedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(export.Data))
+}
+
+// GetPreferences handles GET /api/me/preferences, returning the topics and
+// languages the user chose and the topic weights learned from what their
+// follows share and what they click. Users whose topic weights haven't been
+// learned yet are learned on the spot.
+func (h *MeHandler) GetPreferences(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+
+	topics, err := h.preferences.TopicPreferences(userID)
+	if err == nil && len(topics) == 0 {
+		topics, err = h.preferences.LearnTopics(userID)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load preferences")
+		return
+	}
+	h.respondPreferences(c, userID, topics)
+}
+
+// UpdatePreferences handles PUT /api/me/preferences, changing the followed
+// topics, languages and feed options present in the body
+func (h *MeHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var err error
+	if req.FollowedTopics != nil {
+		err = h.preferences.SetTopics(userID, *req.FollowedTopics)
+	}
+	if err == nil && req.Languages != nil {
+		_, err = h.preferences.SetLanguages(userID, *req.Languages)
+	}
+	if err == nil && req.ShowSeenArticles != nil {
+		err = h.preferences.SetShowSeenArticles(userID, *req.ShowSeenArticles)
+	}
+	if err == nil && req.DigestSubscribed != nil {
+		err = h.preferences.SetDigestSubscribed(userID, *req.DigestSubscribed)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownTopic) || errors.Is(err, services.ErrInvalidLanguage) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to update preferences")
+		return
+	}
+	h.respondSettings(c, userID)
+}
+
+// FollowTopic handles PUT /api/me/topics/:topic
+func (h *MeHandler) FollowTopic(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	if err := h.preferences.FollowTopic(userID, c.Param("topic")); err != nil {
+		if errors.Is(err, services.ErrUnknownTopic) {
+			respondError(c, http.StatusNotFound, "Topic not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to follow topic")
+		return
+	}
+	h.respondSettings(c, userID)
+}
+
+// UnfollowTopic handles DELETE /api/me/topics/:topic
+func (h *MeHandler) UnfollowTopic(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	if err := h.preferences.UnfollowTopic(userID, c.Param("topic")); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unfollow topic")
+		return
+	}
+	h.respondSettings(c, userID)
+}
+
+// SetLanguages handles PUT /api/me/languages, replacing the languages the user
+// reads. An empty list allows every language.
+func (h *MeHandler) SetLanguages(c *gin.Context) {
+	userID, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	var req SetLanguagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if _, err := h.preferences.SetLanguages(userID, req.Languages); err != nil {
+		if errors.Is(err, services.ErrInvalidLanguage) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to update languages")
+		return
+	}
+	h.respondSettings(c, userID)
+}
+
+// respondSettings responds with the user's preferences after a settings change
+func (h *MeHandler) respondSettings(c *gin.Context, userID uuid.UUID) {
+	topics, err := h.preferences.TopicPreferences(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load preferences")
+		return
+	}
+	h.respondPreferences(c, userID, topics)
+}
+
+// respondPreferences responds with the user's settings and learned topic weights
+func (h *MeHandler) respondPreferences(c *gin.Context, userID uuid.UUID, topics []models.UserTopicAffinity) {
+	followed, languages, err := h.preferences.Settings(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load preferences")
+		return
+	}
+	showSeen, digestSubscribed, err := h.preferences.Options(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load preferences")
+		return
+	}
+
+	response := PreferencesResponse{
+		FollowedTopics:   followed,
+		Languages:        languages,
+		ShowSeenArticles: showSeen,
+		DigestSubscribed: digestSubscribed,
+		Topics:           topics,
+	}
+	if response.FollowedTopics == nil {
+		response.FollowedTopics = []string{}
+	}
+	if response.Languages == nil {
+		response.Languages = []string{}
+	}
+	if response.Topics == nil {
+		response.Topics = []models.UserTopicAffinity{}
+	}
+	c.JSON(http.StatusOK, response)
+}
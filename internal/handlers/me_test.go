@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"open-news/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeAuthRequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &MeHandler{verifier: auth.NewMockJWTVerifier()}
+	r := gin.New()
+	r.GET("/api/me", h.Auth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, header := range []string{"", "Basic dXNlcjpwYXNz"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+	}
+}
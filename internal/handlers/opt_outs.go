@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"open-news/internal/domains"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OptOutHandler lets publishers ask for their sites to be left out
+type OptOutHandler struct {
+	domains *domains.Registry
+}
+
+// NewOptOutHandler creates a new opt-out handler
+func NewOptOutHandler(db *gorm.DB) *OptOutHandler {
+	return &OptOutHandler{domains: domains.NewRegistry(db)}
+}
+
+// optOutRequest is the body of RequestOptOut
+type optOutRequest struct {
+	Domain  string `json:"domain" binding:"required"`
+	Contact string `json:"contact"` // How to reach the publisher, e.g. an email address
+	Reason  string `json:"reason"`
+}
+
+// RequestOptOut records a publisher's request to leave their domain out and
+// returns the token that proves they own it. The opt-out takes effect once the
+// token is published and VerifyOptOut finds it.
+// POST /api/publishers/opt-out
+func (h *OptOutHandler) RequestOptOut(c *gin.Context) {
+	var req optOutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "domain is required")
+		return
+	}
+
+	optOut, err := h.domains.RequestOptOut(req.Domain, req.Contact, req.Reason)
+	if errors.Is(err, domains.ErrInvalidDomain) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		log.Printf("Failed to record opt-out request: %v", err)
+		respondError(c, http.StatusInternalServerError, "Failed to record the request")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"domain": optOut.Domain,
+		"status": optOut.Status,
+		"token":  optOut.Token,
+		"meta":   `<meta name="` + domains.OptOutVerificationName + `" content="` + optOut.Token + `">`,
+		"dns":    domains.OptOutVerificationName + "=" + optOut.Token,
+		"message": "Add the meta tag to the home page of https://" + optOut.Domain + "/, or the TXT record to the domain, " +
+			"then POST the domain to /api/publishers/opt-out/verify",
+	})
+}
+
+// verifyOptOutRequest is the body of VerifyOptOut
+type verifyOptOutRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// VerifyOptOut checks a domain's home page and TXT records for its opt-out
+// token, and activates the opt-out when either carries it
+// POST /api/publishers/opt-out/verify
+func (h *OptOutHandler) VerifyOptOut(c *gin.Context) {
+	var req verifyOptOutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "domain is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+	optOut, err := h.domains.VerifyOptOut(ctx, req.Domain)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "No opt-out has been requested for this domain")
+		return
+	case errors.Is(err, domains.ErrOptOutNotVerified):
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to verify opt-out of %s: %v", req.Domain, err)
+		respondError(c, http.StatusInternalServerError, "Failed to verify the domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"domain": optOut.Domain, "status": optOut.Status, "verified_by": optOut.VerifiedBy, "verified_at": optOut.VerifiedAt})
+}
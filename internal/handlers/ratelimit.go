@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped from a limiter
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitConfig sets a token bucket's sustained rate and burst size
+type RateLimitConfig struct {
+	Rate  float64 // Requests per second allowed over time; 0 disables the limit
+	Burst int     // Requests allowed at once before the rate applies
+}
+
+// LoadRateLimitConfig reads <prefix>_RPS and <prefix>_BURST, falling back to the
+// given defaults when they are unset or invalid
+func LoadRateLimitConfig(prefix string, defaultRate float64, defaultBurst int) RateLimitConfig {
+	cfg := RateLimitConfig{Rate: defaultRate, Burst: defaultBurst}
+
+	if value := os.Getenv(prefix + "_RPS"); value != "" {
+		if rate, err := strconv.ParseFloat(value, 64); err == nil && rate >= 0 {
+			cfg.Rate = rate
+		} else {
+			log.Printf("Invalid %s_RPS %q, using %v", prefix, value, defaultRate)
+		}
+	}
+	if value := os.Getenv(prefix + "_BURST"); value != "" {
+		if burst, err := strconv.Atoi(value); err == nil && burst > 0 {
+			cfg.Burst = burst
+		} else {
+			log.Printf("Invalid %s_BURST %q, using %d", prefix, value, defaultBurst)
+		}
+	}
+	return cfg
+}
+
+// LoadTrustedProxies reads a comma-separated list of proxy IPs and CIDR ranges from
+// the given environment variable. Only requests arriving through one of them have
+// their client IP read from X-Forwarded-For; when it is unset no proxy is trusted.
+func LoadTrustedProxies(envVar string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv(envVar), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// tokenBucket holds the tokens left for one client
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter is a set of token buckets keyed by client, such as an IP address or DID.
+// A nil RateLimiter allows every request.
+type RateLimiter struct {
+	cfg       RateLimitConfig
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter, or returns nil when cfg.Rate is 0
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	if cfg.Rate <= 0 {
+		return nil
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	return &RateLimiter{
+		cfg:       cfg,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns false
+// and how long until a token is available.
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.cfg.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill for the time since the last request, up to the burst size
+	bucket.tokens = math.Min(float64(l.cfg.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*l.cfg.Rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / l.cfg.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket starts full anyway
+func (l *RateLimiter) sweep(now time.Time) {
+	fullAfter := time.Duration(float64(l.cfg.Burst) / l.cfg.Rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > fullAfter {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimitMiddleware limits requests per client IP, or per DID when didFunc finds
+// a verified requester. Feed requests from Bluesky come from a few shared AppView
+// IPs, so those with a DID aren't counted against their IP. Limited requests get
+// a 429 with a Retry-After header. Either limiter may be nil. didFunc runs before
+// any bucket is checked, so it must fail fast on made-up tokens; those requests
+// are then charged to their IP.
+func RateLimitMiddleware(ipLimiter, didLimiter *RateLimiter, didFunc func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+
+		did := ""
+		if didLimiter != nil && didFunc != nil {
+			did = didFunc(c)
+		}
+
+		var allowed bool
+		var wait time.Duration
+		if did != "" {
+			allowed, wait = didLimiter.Allow(did, now)
+		} else {
+			allowed, wait = ipLimiter.Allow(c.ClientIP(), now)
+		}
+		if allowed {
+			c.Next()
+			return
+		}
+
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		abortWithError(c, http.StatusTooManyRequests, fmt.Sprintf("Too many requests, retry in %d seconds", retryAfter))
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Rate: 2, Burst: 3})
+	now := time.Now()
+
+	// The burst is available at once
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("1.2.3.4", now)
+		assert.True(t, allowed, "request %d", i+1)
+	}
+	allowed, wait := limiter.Allow("1.2.3.4", now)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other clients have their own bucket
+	allowed, _ = limiter.Allow("5.6.7.8", now)
+	assert.True(t, allowed)
+
+	// Tokens refill at the sustained rate
+	allowed, _ = limiter.Allow("1.2.3.4", now.Add(500*time.Millisecond))
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("1.2.3.4", now.Add(500*time.Millisecond))
+	assert.False(t, allowed)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Rate: 0, Burst: 10})
+	assert.Nil(t, limiter)
+
+	allowed, _ := limiter.Allow("1.2.3.4", time.Now())
+	assert.True(t, allowed)
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 5})
+	now := time.Now()
+	limiter.Allow("idle", now)
+	limiter.Allow("active", now.Add(2*time.Minute))
+
+	assert.NotContains(t, limiter.buckets, "idle")
+	assert.Contains(t, limiter.buckets, "active")
+}
+
+func TestLoadRateLimitConfig(t *testing.T) {
+	t.Setenv("TEST_LIMIT_RPS", "0.5")
+	t.Setenv("TEST_LIMIT_BURST", "nope")
+
+	cfg := LoadRateLimitConfig("TEST_LIMIT", 10, 40)
+	assert.Equal(t, 0.5, cfg.Rate)
+	assert.Equal(t, 40, cfg.Burst)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ipLimiter := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 5})
+	didLimiter := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 1})
+	didFunc := func(c *gin.Context) string { return c.GetHeader("X-Test-DID") }
+
+	r := gin.New()
+	r.GET("/xrpc/test", RateLimitMiddleware(ipLimiter, didLimiter, didFunc), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(did string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/xrpc/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if did != "" {
+			req.Header.Set("X-Test-DID", did)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Requests with a DID are limited per DID, not counted against their IP
+	assert.Equal(t, http.StatusOK, request("did:plc:alice").Code)
+	w := request("did:plc:alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, request(fmt.Sprintf("did:plc:user%d", i)).Code, "other DIDs from the same IP")
+	}
+
+	// Requests without one share the IP bucket
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, request("").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request("").Code)
+}
+
+func TestRateLimitMiddleware_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(proxies []string) *gin.Engine {
+		r := gin.New()
+		assert.NoError(t, r.SetTrustedProxies(proxies))
+		ipLimiter := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 1})
+		r.GET("/api/test", RateLimitMiddleware(ipLimiter, nil, nil), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+	request := func(r *gin.Engine, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Without trusted proxies a client can't pick a fresh IP per request
+	r := newRouter(LoadTrustedProxies("TEST_TRUSTED_PROXIES_UNSET"))
+	assert.Equal(t, http.StatusOK, request(r, "1.1.1.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request(r, "2.2.2.2"))
+
+	// Behind a trusted proxy each forwarded client has its own bucket
+	t.Setenv("TEST_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, LoadTrustedProxies("TEST_TRUSTED_PROXIES"))
+	r = newRouter(LoadTrustedProxies("TEST_TRUSTED_PROXIES"))
+	assert.Equal(t, http.StatusOK, request(r, "1.1.1.1"))
+	assert.Equal(t, http.StatusOK, request(r, "2.2.2.2"))
+	assert.Equal(t, http.StatusTooManyRequests, request(r, "2.2.2.2"))
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"open-news/internal/errreport"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware turns a panicking handler into a 500 error response, like
+// gin.Recovery, and reports the panic with the request it happened on
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, err interface{}) {
+		tags := errreport.Tags{
+			"http.method": c.Request.Method,
+			"http.route":  c.FullPath(),
+			"url.path":    c.Request.URL.Path,
+		}
+		if traceID := c.GetString(traceIDKey); traceID != "" {
+			tags["trace_id"] = traceID
+		}
+		if tenant := currentTenant(c); tenant != nil {
+			tags["tenant"] = tenant.Slug
+		}
+		errreport.CapturePanic(err, debug.Stack(), tags)
+		abortWithError(c, http.StatusInternalServerError, "Internal server error")
+	})
+}
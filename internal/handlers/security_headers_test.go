@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(SecurityHeadersMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/feeds", ok)
+	r.GET("/widget/global", ok)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds", nil))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'")
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "https://fonts.googleapis.com")
+	assert.Equal(t, "strict-origin-when-cross-origin", w.Header().Get("Referrer-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"), "not over plain HTTP")
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/widget/global", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	r.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("X-Frame-Options"), "widgets can be embedded")
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "frame-ancestors *")
+	assert.Equal(t, hstsMaxAge, w.Header().Get("Strict-Transport-Security"))
+}
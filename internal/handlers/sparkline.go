@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Size of the sparklines drawn on admin pages, in SVG user units. The SVG is
+// stretched to its box, so only the aspect ratio matters.
+const (
+	sparklineWidth  = 240
+	sparklineHeight = 40
+)
+
+// sparkline is a series of values drawn as an inline SVG polyline
+type sparkline struct {
+	Label          string
+	Width, Height  float64
+	Points         string // The polyline's points; empty with fewer than two values
+	Min, Max, Last float64
+}
+
+// newSparkline scales values to the sparkline's box, the lowest value at the
+// bottom and the highest at the top. A flat series is drawn across the middle.
+func newSparkline(label string, values []float64) sparkline {
+	line := sparkline{Label: label, Width: sparklineWidth, Height: sparklineHeight}
+	if len(values) == 0 {
+		return line
+	}
+	line.Min, line.Max, line.Last = values[0], values[0], values[len(values)-1]
+	for _, value := range values {
+		line.Min = min(line.Min, value)
+		line.Max = max(line.Max, value)
+	}
+	if len(values) < 2 {
+		return line
+	}
+
+	points := make([]string, len(values))
+	for i, value := range values {
+		x := float64(i) * line.Width / float64(len(values)-1)
+		y := line.Height / 2
+		if line.Max > line.Min {
+			y = line.Height - (value-line.Min)/(line.Max-line.Min)*line.Height
+		}
+		points[i] = strconv.FormatFloat(x, 'f', 1, 64) + "," + strconv.FormatFloat(y, 'f', 1, 64)
+	}
+	line.Points = strings.Join(points, " ")
+	return line
+}
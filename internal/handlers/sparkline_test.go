@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSparkline(t *testing.T) {
+	line := newSparkline("Quality", []float64{0.2, 0.6, 0.4})
+	assert.Equal(t, "0.0,40.0 120.0,0.0 240.0,20.0", line.Points)
+	assert.Equal(t, 0.2, line.Min)
+	assert.Equal(t, 0.6, line.Max)
+	assert.Equal(t, 0.4, line.Last)
+
+	flat := newSparkline("Trending", []float64{0.5, 0.5})
+	assert.Equal(t, "0.0,20.0 240.0,20.0", flat.Points, "a flat series is drawn across the middle")
+
+	assert.Empty(t, newSparkline("Quality", []float64{0.5}).Points, "one value isn't a line")
+}
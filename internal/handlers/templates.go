@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"open-news/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templateFuncs are helpers available to every page template
+var templateFuncs = template.FuncMap{
+	"truncate":      truncateText,
+	"initial":       initial,
+	"qualityClass":  qualityClass,
+	"qualityIcon":   qualityIcon,
+	"publishedTime": publishedTime,
+	"sourceName":    sourceName,
+	"field":         newFieldView,
+	"join":          strings.Join,
+	"percent":       percent,
+}
+
+var (
+	// feedTemplates renders the public feed pages and widgets
+	feedTemplates = template.Must(template.New("feed").Funcs(templateFuncs).ParseFS(templateFS, "templates/feed/*.html"))
+
+	// docsTemplates renders Markdown documentation pages
+	docsTemplates = template.Must(template.New("docs").Funcs(templateFuncs).ParseFS(templateFS, "templates/docs/*.html"))
+
+	// adminPages holds one template set per admin page, keyed by file name
+	adminPages = mustParsePages("templates/admin/layout.html", "templates/admin/partials/*.html", "templates/admin/pages/*.html")
+)
+
+// mustParsePages parses a layout and its partials once, then clones that base
+// for every page so each page can define its own "content" and "scripts" blocks
+func mustParsePages(layout, partials, pages string) map[string]*template.Template {
+	base := template.Must(template.New(path.Base(layout)).Funcs(templateFuncs).ParseFS(templateFS, layout, partials))
+
+	files, err := fs.Glob(templateFS, pages)
+	if err != nil {
+		panic(err)
+	}
+
+	sets := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		page := template.Must(template.Must(base.Clone()).ParseFS(templateFS, file))
+		sets[strings.TrimSuffix(path.Base(file), ".html")] = page
+	}
+	return sets
+}
+
+// renderPage renders an admin page inside the shared layout
+func renderPage(c *gin.Context, page string, status int, data interface{}) {
+	tmpl, ok := adminPages[page]
+	if !ok {
+		log.Printf("Unknown admin page template: %s", page)
+		c.String(http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	renderTemplate(c, tmpl, status, "layout", data)
+}
+
+// renderTemplate executes a named template into the response. Rendering into a
+// buffer first means a template error never leaves a half-written page.
+func renderTemplate(c *gin.Context, tmpl *template.Template, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Printf("Failed to render template %s: %v", name, err)
+		c.String(http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// truncateText shortens s to at most n characters, adding an ellipsis when cut
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// percent returns part as a whole percentage of whole, 0 when whole is 0
+func percent(part, whole int) int {
+	if whole == 0 {
+		return 0
+	}
+	return part * 100 / whole
+}
+
+// initial returns the first character of a name for placeholder avatars
+func initial(name string) string {
+	r, _ := utf8.DecodeRuneInString(name)
+	if r == utf8.RuneError {
+		return "?"
+	}
+	return string(r)
+}
+
+// qualityClass buckets a quality score for styling
+func qualityClass(score float64) string {
+	if score >= 0.7 {
+		return "high"
+	} else if score >= 0.5 {
+		return "medium"
+	}
+	return "low"
+}
+
+// publishedTime formats an optional publish date relative to now
+func publishedTime(t *time.Time) string {
+	if t == nil {
+		return "Unknown"
+	}
+	return formatRelativeTime(*t)
+}
+
+// qualityIcon returns the status emoji for a quality score
+func qualityIcon(score float64) string {
+	switch qualityClass(score) {
+	case "high":
+		return "✅"
+	case "medium":
+		return "⚡"
+	default:
+		return "⚠️"
+	}
+}
+
+// sourceName returns the display name of the first source that shared an article
+func sourceName(article models.Article) string {
+	if len(article.SourceArticles) > 0 && article.SourceArticles[0].Source.ID != uuid.Nil {
+		return article.SourceArticles[0].Source.DisplayName
+	}
+	return "Unknown Source"
+}
+
+// fieldView is the data passed to the "field" partial
+type fieldView struct {
+	Label string
+	Value interface{}
+	Class string
+}
+
+// newFieldView builds a labelled value for the "field" partial, with optional extra CSS classes
+func newFieldView(label string, value interface{}, classes ...string) fieldView {
+	return fieldView{Label: label, Value: value, Class: strings.Join(classes, " ")}
+}
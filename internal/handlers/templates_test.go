@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Theme
+	}{
+		{"light", ThemeLight},
+		{"dark", ThemeDark},
+		{"auto", ThemeAuto},
+		{"", ThemeAuto},
+		{"solarized", ThemeAuto},
+		{`dark" onload="alert(1)`, ThemeAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseTheme(tt.value))
+		})
+	}
+}
+
+func TestWidgetTemplateEscapesContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	item := feeds.FeedItemDetails{}
+	item.Article.URL = "https://example.com/story"
+	item.Article.Title = `<script>alert("title")</script>`
+	item.Article.Description = `<img src=x onerror=alert(1)>`
+	item.Source.Handle = "reporter.bsky.social"
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	renderTemplate(c, feedTemplates, http.StatusOK, "widget", widgetView{
+		Feed: feedView{
+			Title:     `Personal Feed - <b>evil</b>`,
+			Icon:      "👤",
+			Items:     []feedItemView{{FeedItemDetails: item, ClickURL: "/r/example"}},
+			UpdatedAt: time.Now(),
+		},
+		Theme:             ThemeDark,
+		AutoRefreshMillis: 300000,
+	})
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, body, `data-theme="dark"`)
+	assert.Contains(t, body, "&lt;script&gt;")
+	assert.Contains(t, body, "&lt;b&gt;evil&lt;/b&gt;")
+	assert.NotContains(t, body, "<img src=x")
+	assert.Contains(t, body, `source-avatar-initial">?</div>`)
+	assert.Contains(t, body, `href="/r/example"`)
+}
+
+func TestArticlePageTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	article := models.Article{
+		URL:         "https://example.com/story",
+		Title:       `<script>alert("title")</script>`,
+		Description: "A story worth reading",
+		ImageURL:    "https://example.com/story.jpg",
+		PublishedAt: &published,
+	}
+	source := models.Source{Handle: "reporter.bsky.social", DisplayName: "Reporter"}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	renderTemplate(c, feedTemplates, http.StatusOK, "article_page", articlePageView{
+		Article: article,
+		Shares:  []articleShareView{{Source: source, PostURL: "https://bsky.app/profile/did:plc:abc/post/3k"}},
+		ReadURL: "/r/example?surface=landing",
+		Theme:   ThemeAuto,
+		Brand:   branding{SiteName: "City News"},
+	})
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, body, `<meta property="og:url" content="https://example.com/story">`)
+	assert.Contains(t, body, `<meta property="og:image" content="https://example.com/story.jpg">`)
+	assert.Contains(t, body, `<link rel="canonical" href="https://example.com/story">`)
+	assert.Contains(t, body, "&lt;script&gt;")
+	assert.NotContains(t, body, `<script>alert`)
+	assert.Contains(t, body, `href="/r/example?surface=landing"`)
+	assert.Contains(t, body, "reporter.bsky.social")
+	assert.Contains(t, body, "- City News</title>")
+}
+
+func TestEmailPageTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	renderTemplate(c, feedTemplates, http.StatusOK, "email_page", emailPageView{
+		Title:       "Unsubscribe",
+		Message:     "Stop receiving the digest at <b>this</b> address?",
+		FormAction:  "/email/unsubscribe/abc",
+		ButtonLabel: "Unsubscribe",
+		Theme:       ThemeLight,
+	})
+
+	body := w.Body.String()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, body, `<form method="POST" action="/email/unsubscribe/abc">`)
+	assert.Contains(t, body, "&lt;b&gt;this&lt;/b&gt;")
+}
+
+func TestBlueskyPostURL(t *testing.T) {
+	assert.Equal(t, "https://bsky.app/profile/did:plc:abc/post/3kxyz",
+		blueskyPostURL("at://did:plc:abc/app.bsky.feed.post/3kxyz"))
+	assert.Equal(t, "", blueskyPostURL("at://did:plc:abc/app.bsky.feed.repost/3kxyz"))
+	assert.Equal(t, "", blueskyPostURL(""))
+}
+
+func TestNewShareTimelineEntry(t *testing.T) {
+	postedAt := time.Now()
+	entry := newShareTimelineEntry(models.SourceArticle{
+		PostURI:    "at://did:plc:abc/app.bsky.feed.post/3kxyz",
+		PostText:   "Worth a read",
+		LikesCount: 4,
+		PostedAt:   postedAt,
+		Source:     models.Source{Handle: "reporter.bsky.social"},
+	})
+
+	assert.Equal(t, "https://bsky.app/profile/did:plc:abc/post/3kxyz", entry.PostURL)
+	assert.Equal(t, "Worth a read", entry.Text)
+	assert.Equal(t, 4, entry.LikesCount)
+	assert.Equal(t, postedAt, entry.PostedAt)
+	assert.Equal(t, "reporter.bsky.social", entry.Source.Handle)
+}
+
+func TestPageURL(t *testing.T) {
+	assert.Equal(t, "/feed/global?page=2&limit=20", pageURL("/feed/global", 2, 20))
+	assert.Equal(t, "/feed/personal?user=alice&page=3&limit=10", pageURL("/feed/personal?user=alice", 3, 10))
+}
+
+func TestAdminPagesRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Now()
+	source := models.Source{ID: uuid.New(), Handle: "reporter.bsky.social", DisplayName: `<i>Reporter</i>`, QualityScore: 0.8}
+	article := models.Article{
+		ID:           uuid.New(),
+		URL:          "https://example.com/story",
+		Title:        `Breaking <script>alert(1)</script>`,
+		QualityScore: 0.4,
+		LastFetchAt:  &now,
+		FetchError:   "HTTP 500: Internal Server Error",
+		JSONLDData:   `{"@type":"NewsArticle"}`,
+		SourceArticles: []models.SourceArticle{
+			{Source: source, PostURI: "at://did:plc:abc/app.bsky.feed.post/1", PostText: "Read <b>this</b>", PostedAt: now},
+			{Source: source, PostURI: "at://did:plc:abc/app.bsky.feed.repost/2", IsRepost: true, PostedAt: now},
+		},
+		Facts: []models.ArticleFact{{FactType: "quote", FactText: "said <b>this</b>", Confidence: 0.9}},
+	}
+	user := models.User{Handle: "alice.bsky.social", BlueSkyDID: "did:plc:short"}
+
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/admin?theme=dark", nil)
+		return c, w
+	}
+
+	tests := []struct {
+		page string
+		data func(c *gin.Context) interface{}
+	}{
+		{"dashboard", func(c *gin.Context) interface{} {
+			return struct {
+				adminPage
+				UserCount, SourceCount, ArticleCount int64
+				RecentArticles                       []models.Article
+			}{newAdminPage(c, "", "/admin"), 1, 1, 1, []models.Article{article}}
+		}},
+		{"users", func(c *gin.Context) interface{} {
+			return struct {
+				adminPage
+				Users      []models.User
+				Pagination adminPagination
+			}{newAdminPage(c, "Users", "/admin/users"), []models.User{user}, newAdminPagination(2, 20, 45, "/admin/users")}
+		}},
+		{"sources", func(c *gin.Context) interface{} {
+			return struct {
+				adminPage
+				Sources    []models.Source
+				Pagination adminPagination
+			}{newAdminPage(c, "Sources", "/admin/sources"), []models.Source{source}, newAdminPagination(1, 20, 1, "/admin/sources")}
+		}},
+		{"articles", func(c *gin.Context) interface{} {
+			return struct {
+				adminPage
+				Articles   []models.Article
+				Pagination adminPagination
+			}{newAdminPage(c, "Articles", "/admin/articles"), []models.Article{article}, newAdminPagination(1, 20, 1, "/admin/articles")}
+		}},
+		{"jobs", func(c *gin.Context) interface{} {
+			job := models.Job{ID: uuid.New(), Type: "refresh_follows", Payload: "{}", Attempts: 3, MaxAttempts: 3, LastError: "<script>alert(1)</script>", FinishedAt: &now}
+			return struct {
+				adminPage
+				Jobs       []models.Job
+				Pagination adminPagination
+			}{newAdminPage(c, "Failed Jobs", "/admin/jobs"), []models.Job{job}, newAdminPagination(1, 20, 1, "/admin/jobs")}
+		}},
+		{"feed_health", func(c *gin.Context) interface{} {
+			feed := models.Feed{Name: "Top Stories", FeedType: "global", RefreshRate: 300, RegeneratedAt: &now, LastError: "<script>alert(1)</script>", LastErrorAt: &now}
+			health := feeds.FeedHealth{Feed: feed, Items: 2, WithImage: 50, Scores: []feeds.ScoreBucket{{Min: 0, Max: 0.2, Count: 2}, {Min: 0.2, Max: 0.4}}}
+			return feedHealthView{adminPage: newAdminPage(c, "Feed Health", "/admin/feed-health"), Feeds: []feeds.FeedHealth{health}}
+		}},
+		{"login", func(c *gin.Context) interface{} {
+			return loginView{adminPage: newAdminPage(c, "Sign In", ""), Next: "/admin/articles", Error: "Invalid username or password"}
+		}},
+		{"accounts", func(c *gin.Context) interface{} {
+			c.Set(adminUserKey, &models.AdminUser{Username: "root", Role: models.AdminRoleAdmin, IsActive: true})
+			account := models.AdminUser{ID: uuid.New(), Username: "<b>editor</b>", Role: models.AdminRoleModerator, IsActive: true, LastLoginAt: &now}
+			return struct {
+				adminPage
+				Accounts []models.AdminUser
+				Roles    []string
+			}{newAdminPage(c, "Admin Accounts", "/admin/accounts"), []models.AdminUser{account}, []string{models.AdminRoleViewer, models.AdminRoleModerator, models.AdminRoleAdmin}}
+		}},
+		{"article", func(c *gin.Context) interface{} {
+			view := newArticleInspectionView(c, article)
+			view.AuditLog = []models.ArticleAuditLog{{ArticleID: article.ID, Actor: "admin", Action: models.AuditActionBoost, OldValue: "0", NewValue: "0.2", CreatedAt: now}}
+			view.setScoreHistory([]models.ScoreHistory{{QualityScore: 0.4, TrendingScore: 1.2}, {QualityScore: 0.5, TrendingScore: 0.9}})
+			return view
+		}},
+		{"user_feed", func(c *gin.Context) interface{} {
+			item := feeds.FeedItemDetails{Reason: "Shared by @reporter.bsky.social"}
+			item.Article.ID = article.ID
+			item.Article.Title = article.Title
+			def := models.FeedDefinition{RKey: "open-news-personal", DisplayName: "Open News - Personal"}
+			return feedPreviewView{
+				adminPage:   newAdminPage(c, "Feed Preview", "/admin/users"),
+				User:        user,
+				Definitions: []models.FeedDefinition{def},
+				Feed:        &def,
+				Limit:       30,
+				Items:       []previewItem{{FeedItemDetails: item, Breakdown: models.ScoreBreakdown{Base: 0.5, QualityScore: 0.5}}},
+				Missed:      []missedArticle{{Article: article, Reason: "Article is unreachable"}},
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			c, w := newContext()
+			renderPage(c, tt.page, http.StatusOK, tt.data(c))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			body := w.Body.String()
+			assert.Contains(t, body, `data-theme="dark"`)
+			assert.Contains(t, body, "open.news Admin")
+			assert.NotContains(t, body, "<script>alert(1)</script>")
+			assert.NotContains(t, body, "<i>Reporter</i>")
+		})
+	}
+}
+
+func TestAdminPagination(t *testing.T) {
+	p := newAdminPagination(2, 20, 45, "/admin/users")
+	assert.Equal(t, 3, p.TotalPages)
+	assert.True(t, p.HasPrev())
+	assert.True(t, p.HasNext())
+
+	p = newAdminPagination(3, 20, 45, "/admin/users")
+	assert.False(t, p.HasNext())
+}
+
+func TestMissedReason(t *testing.T) {
+	ranked := models.Article{ID: uuid.New(), IsReachable: true, QualityScore: 0.6}
+	unranked := models.Article{ID: uuid.New(), IsReachable: true, QualityScore: 0.4, TrendingScore: 0.1}
+	positions := map[uuid.UUID]int{ranked.ID: 42}
+
+	assert.Equal(t, "Article is unreachable", missedReason(models.Article{IsReachable: false, QualityScore: 0.9}, positions, 30))
+	assert.Equal(t, "Not scored yet (quality score is 0)", missedReason(models.Article{IsReachable: true}, positions, 30))
+	assert.Equal(t, "Ranked #42 in Top Stories, below the 30-item limit", missedReason(ranked, positions, 30))
+	assert.Equal(t, "Ranked #42 in Top Stories but filtered out by this feed", missedReason(ranked, positions, 50))
+	assert.Equal(t, "Not in Top Stories (quality 0.40, trending 0.10)", missedReason(unranked, positions, 30))
+}
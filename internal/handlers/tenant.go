@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tenantKey is the context key TenantMiddleware stores the request's tenant under
+const tenantKey = "tenant"
+
+// TenantMiddleware resolves the tenant a request is for from its host, so feed,
+// admin and page handlers serve that tenant's feeds, accounts and branding.
+// Hosts no tenant claims are served by the default tenant.
+func TenantMiddleware(tenants *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, err := tenants.Resolve(c.Request.Host)
+		if err != nil {
+			log.Printf("Failed to resolve tenant for %s: %v", c.Request.Host, err)
+			abortWithError(c, http.StatusServiceUnavailable, "Service unavailable")
+			return
+		}
+		c.Set(tenantKey, tenant)
+		c.Next()
+	}
+}
+
+// currentTenant returns the tenant of this request, or nil when TenantMiddleware
+// didn't run
+func currentTenant(c *gin.Context) *models.Tenant {
+	if value, ok := c.Get(tenantKey); ok {
+		if tenant, ok := value.(*models.Tenant); ok {
+			return tenant
+		}
+	}
+	return nil
+}
+
+// tenantRegistry returns registry scoped to the feeds of the request's tenant
+func tenantRegistry(c *gin.Context, registry *feeds.Registry) *feeds.Registry {
+	registry = registry.WithContext(c.Request.Context())
+	if tenant := currentTenant(c); tenant != nil {
+		return registry.ForTenant(tenant.ID)
+	}
+	return registry
+}
+
+// tenantAdminUsers returns adminUsers scoped to the accounts of the request's tenant
+func tenantAdminUsers(c *gin.Context, adminUsers *services.AdminUserService) *services.AdminUserService {
+	if tenant := currentTenant(c); tenant != nil {
+		return adminUsers.ForTenant(tenant.ID)
+	}
+	return adminUsers
+}
+
+// feedGeneratorDID returns the DID the request's tenant serves its feeds as,
+// falling back to FEED_GENERATOR_DID
+func feedGeneratorDID(c *gin.Context) string {
+	if tenant := currentTenant(c); tenant != nil && tenant.FeedGeneratorDID != "" {
+		return tenant.FeedGeneratorDID
+	}
+	return os.Getenv("FEED_GENERATOR_DID")
+}
+
+// branding is how server-rendered pages name and style the request's tenant
+type branding struct {
+	SiteName    string
+	LogoURL     string
+	AccentColor string
+}
+
+// brandingFor returns the branding of the request's tenant
+func brandingFor(c *gin.Context) branding {
+	brand := branding{SiteName: "open.news"}
+	if tenant := currentTenant(c); tenant != nil {
+		if tenant.Name != "" {
+			brand.SiteName = tenant.Name
+		}
+		brand.LogoURL, brand.AccentColor = tenant.LogoURL, tenant.AccentColor
+	}
+	return brand
+}
+
+// tenantView is a tenant's settings as its admins see them
+type tenantView struct {
+	models.Tenant
+	HasBlueskyPassword bool `json:"has_bluesky_password"`
+}
+
+// GetTenant returns the settings of the signed-in account's tenant
+// GET /admin/api/tenant
+func (h *AdminHandler) GetTenant(c *gin.Context) {
+	tenant := currentTenant(c)
+	if tenant == nil {
+		respondError(c, http.StatusNotFound, "Tenant not found")
+		return
+	}
+	c.JSON(http.StatusOK, tenantView{Tenant: *tenant, HasBlueskyPassword: tenant.BlueskyPassword != ""})
+}
+
+// updateTenantRequest is the body of UpdateTenant; omitted fields are left unchanged
+type updateTenantRequest struct {
+	Name              *string   `json:"name"`
+	LogoURL           *string   `json:"logo_url"`
+	AccentColor       *string   `json:"accent_color"`
+	Hostnames         *[]string `json:"hostnames"`
+	FeedGeneratorDID  *string   `json:"feed_generator_did"`
+	BlueskyIdentifier *string   `json:"bluesky_identifier"`
+	BlueskyPassword   *string   `json:"bluesky_password"`
+}
+
+// UpdateTenant changes the branding, hostnames and Bluesky account of the
+// signed-in account's tenant
+// PUT /admin/api/tenant
+func (h *AdminHandler) UpdateTenant(c *gin.Context) {
+	current := currentTenant(c)
+	if current == nil {
+		respondError(c, http.StatusNotFound, "Tenant not found")
+		return
+	}
+	var req updateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tenant := *current
+	tenant.BlueskyPassword = "" // Kept unless a new one is given
+	set := func(field *string, value *string) {
+		if value != nil {
+			*field = strings.TrimSpace(*value)
+		}
+	}
+	set(&tenant.Name, req.Name)
+	set(&tenant.LogoURL, req.LogoURL)
+	set(&tenant.AccentColor, req.AccentColor)
+	set(&tenant.FeedGeneratorDID, req.FeedGeneratorDID)
+	set(&tenant.BlueskyIdentifier, req.BlueskyIdentifier)
+	set(&tenant.BlueskyPassword, req.BlueskyPassword)
+	if req.Hostnames != nil {
+		tenant.Hostnames = *req.Hostnames
+	}
+
+	saved, err := h.tenants.Save(tenant)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"success": true, "tenant": tenantView{Tenant: *saved, HasBlueskyPassword: saved.BlueskyPassword != ""}})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "Tenant not found")
+	case errors.Is(err, services.ErrTenantNameRequired):
+		respondError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTenantHostTaken):
+		respondError(c, http.StatusConflict, err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Theme is a color scheme for server-rendered pages and widgets
+type Theme string
+
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+	ThemeAuto  Theme = "auto" // Follows the visitor's system preference
+)
+
+// themeCookie is the cookie written by static/theme.js
+const themeCookie = "theme"
+
+// ParseTheme converts a query or cookie value to a Theme, falling back to auto
+func ParseTheme(value string) Theme {
+	switch Theme(value) {
+	case ThemeLight, ThemeDark, ThemeAuto:
+		return Theme(value)
+	default:
+		return ThemeAuto
+	}
+}
+
+// themeFromRequest picks the theme from the ?theme= parameter, then the theme cookie
+func themeFromRequest(c *gin.Context) Theme {
+	if value := c.Query("theme"); value != "" {
+		return ParseTheme(value)
+	}
+	if value, err := c.Cookie(themeCookie); err == nil {
+		return ParseTheme(value)
+	}
+	return ThemeAuto
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+
+	"open-news/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceIDKey is the context key of the request's trace ID
+const traceIDKey = "trace_id"
+
+// RequestLogger logs each request like gin's default logger, with its trace ID
+// when it's traced so a slow request in the logs can be looked up in the
+// collector
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		line := fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			param.Path,
+		)
+		if traceID, ok := param.Keys[traceIDKey].(string); ok {
+			line += " trace=" + traceID
+		}
+		if param.ErrorMessage != "" {
+			line += "\n" + param.ErrorMessage
+		}
+		return line + "\n"
+	})
+}
+
+// TracingMiddleware records a server span for each request with otelgin,
+// continuing the caller's trace when it sends a traceparent header, then puts
+// the trace ID of recorded requests in the log and the X-Trace-Id header.
+// Handlers pass c.Request.Context() on so their queries and outbound calls join
+// the trace.
+func TracingMiddleware() gin.HandlersChain {
+	return gin.HandlersChain{otelgin.Middleware(tracing.ServiceName), traceID}
+}
+
+// traceID records the trace ID of a request whose span is being recorded
+func traceID(c *gin.Context) {
+	if span := trace.SpanFromContext(c.Request.Context()); span.IsRecording() {
+		id := span.SpanContext().TraceID().String()
+		c.Set(traceIDKey, id)
+		c.Header("X-Trace-Id", id)
+	}
+	c.Next()
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"io/fs"
+	"net/http"
+
+	"open-news/internal/didweb"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WellKnownHandler serves /.well-known: the did:web document generated from
+// the feed generator settings, or from the tenant's own did:web on a tenant's
+// host, and any other files from a file system
+type WellKnownHandler struct {
+	document *didweb.Document
+	files    http.FileSystem
+}
+
+// NewWellKnownHandler creates a handler serving document, which may be nil to
+// serve a hand-written did.json from files like the other files
+func NewWellKnownHandler(document *didweb.Document, files fs.FS) *WellKnownHandler {
+	return &WellKnownHandler{document: document, files: http.FS(files)}
+}
+
+// ServeWellKnown serves the DID document or a file
+// GET /.well-known/*path
+func (h *WellKnownHandler) ServeWellKnown(c *gin.Context) {
+	path := c.Param("path")
+	if path == "/did.json" {
+		if tenant := currentTenant(c); tenant != nil && tenant.FeedGeneratorDID != "" && (h.document == nil || tenant.FeedGeneratorDID != h.document.ID) {
+			document, err := didweb.Build(didweb.Config{DID: tenant.FeedGeneratorDID})
+			if err != nil || document == nil {
+				respondError(c, http.StatusNotFound, "No DID document for this host")
+				return
+			}
+			c.JSON(http.StatusOK, document)
+			return
+		}
+		if h.document != nil {
+			c.JSON(http.StatusOK, h.document)
+			return
+		}
+	}
+	c.FileFromFS(path, h.files)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WidgetHandler serves the lightweight JSON API used by embeddable widgets
+type WidgetHandler struct {
+	feedService      *feeds.FeedService
+	apiKeyService    *services.APIKeyService
+	analyticsService *services.AnalyticsService
+	cors             CORSConfig
+	requireAPIKey    bool
+}
+
+// NewWidgetHandler creates a new widget handler.
+// WIDGET_CORS_ORIGINS limits which sites may call the API without a key (default "*"),
+// and WIDGET_REQUIRE_API_KEY=true rejects requests that don't present a key.
+func NewWidgetHandler(db *gorm.DB) *WidgetHandler {
+	return &WidgetHandler{
+		feedService:      feeds.NewFeedService(db),
+		apiKeyService:    services.NewAPIKeyService(db),
+		analyticsService: services.NewAnalyticsService(db),
+		cors:             LoadCORSConfig("WIDGET_CORS_ORIGINS", "*"),
+		requireAPIKey:    os.Getenv("WIDGET_REQUIRE_API_KEY") == "true",
+	}
+}
+
+// WidgetResponse is the JSON returned by the widget API
+type WidgetResponse struct {
+	Feed        string       `json:"feed"`
+	Version     string       `json:"version"`             // Pass back as ?version= to poll for changes
+	Unchanged   bool         `json:"unchanged,omitempty"` // True when the client's version is current
+	GeneratedAt time.Time    `json:"generated_at"`
+	Items       []WidgetItem `json:"items"`
+}
+
+// WidgetItem is a single story in a widget response
+type WidgetItem struct {
+	ID          string       `json:"id"`
+	Position    int          `json:"position"`
+	URL         string       `json:"url"`
+	ClickURL    string       `json:"click_url"` // Tracked redirect to URL, relative to the API host
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	ImageURL    string       `json:"image_url,omitempty"`
+	SiteName    string       `json:"site_name,omitempty"`
+	Authors     []string     `json:"authors,omitempty"`
+	PublishedAt *time.Time   `json:"published_at,omitempty"`
+	Source      WidgetSource `json:"source"`
+}
+
+// WidgetSource is the account that shared a widget item
+type WidgetSource struct {
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+}
+
+// WidgetAuth checks the caller's origin and optional API key before serving widget data.
+// Keys may be sent as an X-API-Key header or a ?key= query parameter.
+func (h *WidgetHandler) WidgetAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			key = c.Query("key")
+		}
+
+		if key != "" {
+			apiKey, err := h.apiKeyService.ValidateAPIKey(key)
+			if err != nil {
+				if err != services.ErrInvalidAPIKey {
+					log.Printf("Failed to validate widget API key: %v", err)
+				}
+				abortWithError(c, http.StatusUnauthorized, "Invalid API key")
+				return
+			}
+			if !apiKey.AllowsOrigin(origin) {
+				abortWithError(c, http.StatusForbidden, "Origin not allowed for this API key")
+				return
+			}
+			h.setCORSHeaders(c, origin, apiKey)
+			c.Set("api_key_id", apiKey.ID.String())
+			c.Next()
+			return
+		}
+
+		if h.requireAPIKey {
+			abortWithError(c, http.StatusUnauthorized, "API key required")
+			return
+		}
+		if origin != "" && !h.cors.AllowsOrigin(origin) {
+			abortWithError(c, http.StatusForbidden, "Origin not allowed")
+			return
+		}
+
+		h.setCORSHeaders(c, origin, nil)
+		c.Next()
+	}
+}
+
+// setCORSHeaders overrides the site-wide CORS headers with the widget policy
+func (h *WidgetHandler) setCORSHeaders(c *gin.Context, origin string, apiKey *models.APIKey) {
+	if origin == "" {
+		return
+	}
+	if apiKey == nil && h.cors.allowAllOrigins() {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+	c.Header("Vary", "Origin")
+}
+
+// GetGlobalWidget handles GET /api/widget/global. With ?since= set to an RFC 3339
+// time, only items published after it are returned.
+func (h *WidgetHandler) GetGlobalWidget(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit > 50 {
+		limit = 50
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondInvalid(c, "Invalid query parameters", map[string]string{"since": "must be an RFC 3339 time"})
+			return
+		}
+		since = parsed
+	}
+
+	feedResponse, err := h.feedService.WithContext(c.Request.Context()).GetGlobalFeed(limit, 0)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to retrieve global feed")
+		return
+	}
+
+	version := fmt.Sprintf("%d-%d", feedResponse.Meta.LastUpdatedAt.Unix(), limit)
+	etag := `W/"` + version + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=60")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response := WidgetResponse{
+		Feed:        "global",
+		Version:     version,
+		GeneratedAt: time.Now().UTC(),
+		Items:       []WidgetItem{},
+	}
+
+	// Pollers that already have this version only need to know nothing changed
+	if c.Query("version") == version {
+		response.Unchanged = true
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	items := feedResponse.Items
+	if !since.IsZero() {
+		items = publishedAfter(items, since)
+	}
+
+	h.analyticsService.RecordImpressions(servedFeed(nil, "global", SurfaceAPI, items))
+
+	for _, item := range items {
+		response.Items = append(response.Items, WidgetItem{
+			ID:          item.Article.ID.String(),
+			Position:    item.Position,
+			URL:         item.Article.URL,
+			ClickURL:    clickURL(item.Article.ID, "global", SurfaceAPI, item.Position, item.Source.ID, nil),
+			Title:       item.Article.Title,
+			Description: item.Article.Description,
+			ImageURL:    item.Article.ImageURL,
+			SiteName:    item.Article.SiteName,
+			Authors:     item.Article.Authors,
+			PublishedAt: item.Article.PublishedAt,
+			Source: WidgetSource{
+				Handle:      item.Source.Handle,
+				DisplayName: item.Source.DisplayName,
+				Avatar:      item.Source.Avatar,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// publishedAfter keeps the items published after since, leaving out those
+// without a publication date
+func publishedAfter(items []feeds.FeedItemDetails, since time.Time) []feeds.FeedItemDetails {
+	var newer []feeds.FeedItemDetails
+	for _, item := range items {
+		if item.Article.PublishedAt != nil && item.Article.PublishedAt.After(since) {
+			newer = append(newer, item)
+		}
+	}
+	return newer
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWidgetAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	keys := services.NewAPIKeyService(db)
+	partnerKey, _, err := keys.CreateAPIKey("Partner", "https://partner.example")
+	require.NoError(t, err)
+	revokedKey, revoked, err := keys.CreateAPIKey("Revoked", "")
+	require.NoError(t, err)
+	require.NoError(t, keys.RevokeAPIKey(revoked.ID))
+
+	h := NewWidgetHandler(db)
+	h.cors = CORSConfig{AllowedOrigins: []string{"https://allowed.example"}}
+	r := gin.New()
+	r.GET("/api/widget/global", h.WidgetAuth(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func(origin, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/widget/global", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		key     string
+		status  int
+		message string
+	}{
+		{"allowed origin without a key", "https://allowed.example", "", http.StatusOK, ""},
+		{"same origin without a key", "", "", http.StatusOK, ""},
+		{"rejected origin without a key", "https://evil.example", "", http.StatusForbidden, "Origin not allowed"},
+		{"wrong key", "https://allowed.example", "onk_wrong", http.StatusUnauthorized, "Invalid API key"},
+		{"revoked key", "https://allowed.example", revokedKey, http.StatusUnauthorized, "Invalid API key"},
+		{"key from its origin", "https://partner.example", partnerKey, http.StatusOK, ""},
+		{"key from another origin", "https://allowed.example", partnerKey, http.StatusForbidden, "Origin not allowed for this API key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.origin, tt.key)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.message != "" {
+				var body APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, tt.message, body.Message)
+			}
+		})
+	}
+
+	w := request("https://partner.example", partnerKey)
+	assert.Equal(t, "https://partner.example", w.Header().Get("Access-Control-Allow-Origin"), "keyed requests echo their origin")
+
+	h.requireAPIKey = true
+	w = request("https://allowed.example", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "a key is required")
+	assert.Equal(t, http.StatusOK, request("https://partner.example", partnerKey).Code)
+}
+
+func TestGetGlobalWidget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+
+	source := models.Source{BlueSkyDID: "did:plc:source", Handle: "source.test"}
+	require.NoError(t, db.Create(&source).Error)
+	older := time.Now().Add(-6 * time.Hour).UTC().Truncate(time.Second)
+	newer := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	articles := []models.Article{
+		{URL: "https://example.com/older", Title: "Older", QualityScore: 0.9, PublishedAt: &older},
+		{URL: "https://example.com/newer", Title: "Newer", QualityScore: 0.8, PublishedAt: &newer},
+	}
+	for i := range articles {
+		require.NoError(t, db.Create(&articles[i]).Error)
+		require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: articles[i].ID,
+			PostURI: "at://did:plc:source/app.bsky.feed.post/" + articles[i].Title, PostedAt: time.Now()}).Error)
+	}
+	require.NoError(t, feeds.NewFeedService(db).RegenerateGlobalFeed())
+
+	h := NewWidgetHandler(db)
+	r := gin.New()
+	r.GET("/api/widget/global", h.GetGlobalWidget)
+	request := func(query string, header http.Header) (*httptest.ResponseRecorder, WidgetResponse) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/widget/global"+query, nil)
+		for name := range header {
+			req.Header.Set(name, header.Get(name))
+		}
+		r.ServeHTTP(w, req)
+		var response WidgetResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w, response
+	}
+	titles := func(response WidgetResponse) []string {
+		var list []string
+		for _, item := range response.Items {
+			list = append(list, item.Title)
+		}
+		return list
+	}
+
+	w, full := request("", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Older", "Newer"}, titles(full))
+	assert.Equal(t, "source.test", full.Items[0].Source.Handle)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `W/"`+full.Version+`"`, etag)
+
+	w, _ = request("", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w, polled := request("?version="+full.Version, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, polled.Unchanged)
+	assert.Empty(t, polled.Items, "a current version gets no items back")
+
+	w, since := request("?since="+url.QueryEscape(older.Format(time.RFC3339)), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Newer"}, titles(since), "only items published after since")
+
+	w, since = request("?since="+url.QueryEscape(newer.Format(time.RFC3339)), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, since.Items)
+
+	w, _ = request("?since=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]string{"since": "must be an RFC 3339 time"}, body.Details)
+}
@@ -0,0 +1,110 @@
+package labeler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// CBOR major types
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+)
+
+// EncodeCBOR encodes a value as DAG-CBOR, the canonical CBOR AT Protocol signs
+// and streams: map keys are sorted by length and then bytewise, and integers
+// use their shortest form. It supports nil, bools, ints, strings, byte slices,
+// slices of values and string-keyed maps, which is all labels need.
+func EncodeCBOR(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBOR(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCBOR(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case int:
+		writeCBORInt(buf, int64(v))
+	case int64:
+		writeCBORInt(buf, v)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, key := range keys {
+			writeCBORHead(buf, cborText, uint64(len(key)))
+			buf.WriteString(key)
+			if err := encodeCBOR(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("can't encode %T as CBOR", value)
+	}
+	return nil
+}
+
+func writeCBORInt(buf *bytes.Buffer, v int64) {
+	if v < 0 {
+		writeCBORHead(buf, cborNegative, uint64(-(v + 1)))
+		return
+	}
+	writeCBORHead(buf, cborUnsigned, uint64(v))
+}
+
+// writeCBORHead writes a major type and its argument in the fewest bytes
+func writeCBORHead(buf *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(major | byte(arg))
+	case arg <= 0xff:
+		buf.Write([]byte{major | 24, byte(arg)})
+	case arg <= 0xffff:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(arg))
+	case arg <= 0xffffffff:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(arg))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, arg)
+	}
+}
@@ -0,0 +1,277 @@
+// Package labeler signs AT Protocol labels, so Bluesky clients and other feeds
+// that subscribe to the open.news labeler can use its signals: which accounts
+// are verified news sources and which posts share breaking stories.
+package labeler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// Label values the labeler emits
+const (
+	ValueVerifiedSource = "opennews-verified-source" // On the account of a source verified as speaking for a news site
+	ValueBreaking       = "opennews-breaking"        // On posts sharing a story many sources shared at once; expires with the breaking boost
+)
+
+// ValueDefinition describes a label value for Bluesky apps, in the shape of the
+// app.bsky.labeler.service record's labelValueDefinitions
+type ValueDefinition struct {
+	Identifier     string   `json:"identifier"`
+	Severity       string   `json:"severity"`       // "inform" shows the label without a warning
+	Blurs          string   `json:"blurs"`          // "none": labeled content is shown as usual
+	DefaultSetting string   `json:"defaultSetting"` // "warn" shows the label unless a user hides it
+	Locales        []Locale `json:"locales"`
+}
+
+// Locale is the name and description of a label value in one language
+type Locale struct {
+	Lang        string `json:"lang"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Definitions returns the definitions of the label values the labeler emits
+func Definitions() []ValueDefinition {
+	define := func(identifier, name, description string) ValueDefinition {
+		return ValueDefinition{
+			Identifier:     identifier,
+			Severity:       "inform",
+			Blurs:          "none",
+			DefaultSetting: "warn",
+			Locales:        []Locale{{Lang: "en", Name: name, Description: description}},
+		}
+	}
+	return []ValueDefinition{
+		define(ValueVerifiedSource, "Verified news source", "open.news verified that this account speaks for the news site it shares."),
+		define(ValueBreaking, "Breaking news", "Many news sources open.news follows shared this story within minutes."),
+	}
+}
+
+// Label is a signed label in its AT Protocol shape
+type Label struct {
+	Src string // DID of the labeler
+	URI string // Labeled post's AT URI, or labeled account's DID
+	CID string // Version of the post labeled; empty for accounts
+	Val string
+	Neg bool   // Retracts an earlier label with the same src, uri and val
+	Cts string // When the label was created, in RFC 3339 with milliseconds
+	Exp string // When the label stops applying; empty if it doesn't
+	Sig []byte
+}
+
+// labelVersion is the version of the label format
+const labelVersion = 1
+
+// fields returns the label as the map that's encoded, without its signature
+func (l Label) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"ver": labelVersion,
+		"src": l.Src,
+		"uri": l.URI,
+		"val": l.Val,
+		"cts": l.Cts,
+	}
+	if l.CID != "" {
+		fields["cid"] = l.CID
+	}
+	if l.Neg {
+		fields["neg"] = true
+	}
+	if l.Exp != "" {
+		fields["exp"] = l.Exp
+	}
+	return fields
+}
+
+// CBOR returns the label with its signature as a map for EncodeCBOR
+func (l Label) CBOR() map[string]interface{} {
+	fields := l.fields()
+	fields["sig"] = l.Sig
+	return fields
+}
+
+// MarshalJSON writes the label as XRPC responses carry it, with the signature
+// as a $bytes object
+func (l Label) MarshalJSON() ([]byte, error) {
+	fields := l.fields()
+	fields["sig"] = map[string]string{"$bytes": base64.RawStdEncoding.EncodeToString(l.Sig)}
+	return json.Marshal(fields)
+}
+
+// FormatTime formats a label timestamp
+func FormatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// Config holds the labeler's identity, read from LABELER_* settings
+type Config struct {
+	DID        string // LABELER_DID: the labeler account, which labels are issued as
+	SigningKey string // LABELER_SIGNING_KEY: hex P-256 private key matching the DID document's #atproto_label key
+}
+
+// LoadConfig reads the labeler settings from the environment
+func LoadConfig() Config {
+	return Config{
+		DID:        strings.TrimSpace(os.Getenv("LABELER_DID")),
+		SigningKey: strings.TrimSpace(os.Getenv("LABELER_SIGNING_KEY")),
+	}
+}
+
+// Signer signs labels as the labeler
+type Signer struct {
+	did string
+	key *ecdsa.PrivateKey
+}
+
+// NewSigner creates a signer from a config, or returns nil when no labeler DID
+// is set
+func NewSigner(config Config) (*Signer, error) {
+	if config.DID == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(config.DID, "did:") {
+		return nil, fmt.Errorf("LABELER_DID %q is not a DID", config.DID)
+	}
+	if config.SigningKey == "" {
+		return nil, errors.New("LABELER_SIGNING_KEY is required to run the labeler")
+	}
+	key, err := ParseKey(config.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LABELER_SIGNING_KEY: %w", err)
+	}
+	return &Signer{did: config.DID, key: key}, nil
+}
+
+// FromEnv creates the signer LABELER_* configures, or nil when the labeler is
+// off or its settings are invalid
+func FromEnv() *Signer {
+	signer, err := NewSigner(LoadConfig())
+	if err != nil {
+		log.Printf("⚠️  %v, labeler disabled", err)
+		return nil
+	}
+	return signer
+}
+
+// DID returns the DID labels are issued as
+func (s *Signer) DID() string {
+	return s.did
+}
+
+// PublicKey returns the signing key as a did:key, the form the DID document's
+// #atproto_label verification method lists without its "did:key:" prefix
+func (s *Signer) PublicKey() string {
+	return DIDKey(&s.key.PublicKey)
+}
+
+// Sign sets the label's source to the labeler and signs it
+func (s *Signer) Sign(label *Label) error {
+	label.Src = s.did
+	label.Sig = nil
+	data, err := EncodeCBOR(label.fields())
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	r, sigS, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign label: %w", err)
+	}
+
+	// Signatures use the low-S form, as 64 bytes of r and s
+	n := s.key.Curve.Params().N
+	if sigS.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		sigS.Sub(n, sigS)
+	}
+	label.Sig = make([]byte, 64)
+	r.FillBytes(label.Sig[:32])
+	sigS.FillBytes(label.Sig[32:])
+	return nil
+}
+
+// Verify checks a label's signature against a public key
+func Verify(label Label, key *ecdsa.PublicKey) bool {
+	if len(label.Sig) != 64 {
+		return false
+	}
+	data, err := EncodeCBOR(label.fields())
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256(data)
+	r := new(big.Int).SetBytes(label.Sig[:32])
+	s := new(big.Int).SetBytes(label.Sig[32:])
+	return ecdsa.Verify(key, digest[:], r, s)
+}
+
+// GenerateKey returns a new P-256 signing key in the hex form LABELER_SIGNING_KEY takes
+func GenerateKey() (string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key.D.FillBytes(make([]byte, 32))), nil
+}
+
+// ParseKey reads a hex P-256 private key
+func ParseKey(value string) (*ecdsa.PrivateKey, error) {
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("expected 64 hex characters")
+	}
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(raw)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.New("key out of range")
+	}
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(raw)
+	return key, nil
+}
+
+// p256Multicodec is the varint multicodec prefix of a compressed P-256 public key
+var p256Multicodec = []byte{0x80, 0x24}
+
+// DIDKey returns a P-256 public key as a did:key
+func DIDKey(key *ecdsa.PublicKey) string {
+	compressed := elliptic.MarshalCompressed(key.Curve, key.X, key.Y)
+	return "did:key:z" + base58(append(append([]byte{}, p256Multicodec...), compressed...))
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58 encodes data with the Bitcoin alphabet multibase calls base58btc
+func base58(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
@@ -0,0 +1,82 @@
+package labeler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCBOR(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000, "1903e8"},
+		{-1, "20"},
+		{true, "f5"},
+		{"ver", "63766572"},
+		{[]byte{1, 2}, "420102"},
+		{[]interface{}{1, nil}, "8201f6"},
+		// Shorter keys sort first, then bytewise
+		{map[string]interface{}{"val": 1, "b": 2, "a": 3}, "a36161036162026376616c01"},
+	}
+	for _, tt := range tests {
+		encoded, err := EncodeCBOR(tt.value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(encoded), "%v", tt.value)
+	}
+
+	_, err := EncodeCBOR(1.5)
+	assert.Error(t, err, "floats aren't allowed in DAG-CBOR labels")
+}
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	signer, err := NewSigner(Config{DID: "did:plc:labeler", SigningKey: key})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signer.PublicKey(), "did:key:zDn"), "P-256 did:keys start with zDn: %s", signer.PublicKey())
+
+	label := Label{URI: "did:plc:source", Val: ValueVerifiedSource, Cts: "2024-05-01T12:00:00.000Z"}
+	require.NoError(t, signer.Sign(&label))
+	assert.Equal(t, "did:plc:labeler", label.Src)
+	assert.Len(t, label.Sig, 64)
+	assert.True(t, Verify(label, &signer.key.PublicKey))
+
+	tampered := label
+	tampered.Neg = true
+	assert.False(t, Verify(tampered, &signer.key.PublicKey), "the signature covers every field")
+
+	encoded, err := json.Marshal(label)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	assert.Equal(t, float64(1), fields["ver"])
+	assert.NotContains(t, fields, "neg")
+	assert.Contains(t, fields["sig"], "$bytes")
+}
+
+func TestNewSigner(t *testing.T) {
+	signer, err := NewSigner(Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, signer, "the labeler is off without a DID")
+
+	_, err = NewSigner(Config{DID: "did:plc:labeler"})
+	assert.ErrorContains(t, err, "LABELER_SIGNING_KEY")
+
+	_, err = NewSigner(Config{DID: "did:plc:labeler", SigningKey: "abc"})
+	assert.ErrorContains(t, err, "invalid LABELER_SIGNING_KEY")
+}
+
+func TestBase58(t *testing.T) {
+	assert.Equal(t, "", base58(nil))
+	assert.Equal(t, "11", base58([]byte{0, 0}))
+	assert.Equal(t, "2NEpo7TZRRrLZSi2U", base58([]byte("Hello World!")))
+}
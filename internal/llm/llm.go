@@ -0,0 +1,114 @@
+// Package llm calls OpenAI-compatible chat completions APIs, for the packages
+// that can hand article text to a model.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is OpenAI's chat completions endpoint
+const DefaultURL = "https://api.openai.com/v1/chat/completions"
+
+// MaxInput caps the characters of article text sent to the API
+const MaxInput = 12000
+
+// Client sends prompts to a chat completions endpoint
+type Client struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewClient creates a client that calls the chat completions endpoint at url
+func NewClient(url, apiKey, model string) *Client {
+	return &Client{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Model is the model prompts are sent to
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Prompt is a chat completions request: instructions, and the article they apply to
+type Prompt struct {
+	System string
+	User   string
+	JSON   bool // Ask for a JSON object in response
+}
+
+// chatMessage is a message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Complete sends a prompt and returns the content of the first choice
+func (c *Client) Complete(ctx context.Context, prompt Prompt) (string, error) {
+	request := map[string]interface{}{
+		"model": c.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: prompt.User},
+		},
+		"temperature": 0,
+	}
+	if prompt.JSON {
+		request["response_format"] = map[string]string{"type": "json_object"}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// ArticleMessage is the user message giving a model an article's title and
+// text, cut to MaxInput characters
+func ArticleMessage(title, text string) string {
+	if len(text) > MaxInput {
+		text = text[:MaxInput]
+	}
+	return "Title: " + title + "\n\n" + text
+}
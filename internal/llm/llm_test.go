@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model          string            `json:"model"`
+			Messages       []chatMessage     `json:"messages"`
+			ResponseFormat map[string]string `json:"response_format"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+		require.Len(t, req.Messages, 2)
+		assert.Equal(t, "system", req.Messages[0].Role)
+		assert.Equal(t, "Be brief.", req.Messages[0].Content)
+		assert.Equal(t, "json_object", req.ResponseFormat["type"])
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": `{"ok":true}`}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key", "test-model")
+	assert.Equal(t, "test-model", client.Model())
+	content, err := client.Complete(context.Background(), Prompt{System: "Be brief.", User: "Hello", JSON: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, content)
+}
+
+func TestComplete_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "key", "model").Complete(context.Background(), Prompt{User: "Hello"})
+	assert.ErrorContains(t, err, "429")
+	assert.ErrorContains(t, err, "rate limited")
+}
+
+func TestArticleMessage(t *testing.T) {
+	assert.Equal(t, "Title: Rates\n\nThe ECB raised rates.", ArticleMessage("Rates", "The ECB raised rates."))
+	assert.Len(t, ArticleMessage("", strings.Repeat("a", MaxInput+100)), len("Title: \n\n")+MaxInput)
+}
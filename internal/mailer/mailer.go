@@ -0,0 +1,193 @@
+// Package mailer sends email through a configurable provider. SMTP works with
+// most providers; the log mailer prints messages instead, for development.
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email with HTML and plain text versions of its body
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	Headers map[string]string // Extra headers, e.g. List-Unsubscribe
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config selects and configures a mailer, read from MAIL_* and SMTP_* settings
+type Config struct {
+	Driver   string // MAIL_DRIVER: "smtp" or "log" (default)
+	From     string // MAIL_FROM, e.g. "open.news <digest@open.news>"
+	Host     string // SMTP_HOST
+	Port     string // SMTP_PORT (default: 587)
+	Username string // SMTP_USERNAME; no authentication when empty
+	Password string // SMTP_PASSWORD
+}
+
+// LoadConfig reads the mailer settings from the environment
+func LoadConfig() Config {
+	config := Config{
+		Driver:   os.Getenv("MAIL_DRIVER"),
+		From:     os.Getenv("MAIL_FROM"),
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+	if config.Driver == "" {
+		config.Driver = "log"
+	}
+	if config.From == "" {
+		config.From = "open.news <noreply@localhost>"
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return config
+}
+
+// New creates the mailer a config selects
+func New(config Config) (Mailer, error) {
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM %q: %w", config.From, err)
+	}
+
+	switch config.Driver {
+	case "log":
+		return &LogMailer{from: config.From}, nil
+	case "smtp":
+		if config.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+		}
+		return &SMTPMailer{config: config}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q, expected smtp or log", config.Driver)
+	}
+}
+
+// SMTPMailer sends messages through an SMTP server, upgrading to TLS when the
+// server offers STARTTLS
+type SMTPMailer struct {
+	config Config
+}
+
+// Send delivers a message over SMTP
+func (m *SMTPMailer) Send(msg Message) error {
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return err
+	}
+	data, err := Encode(m.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	if err := smtp.SendMail(m.config.Host+":"+m.config.Port, auth, from.Address, []string{msg.To}, data); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// LogMailer logs messages instead of sending them
+type LogMailer struct {
+	from string
+}
+
+// Send logs a message's recipient, subject and text body
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("📧 Email from %s to %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// Encode renders a message as a MIME email with text and HTML alternatives
+func Encode(from string, msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	headers := map[string]string{
+		"From":         from,
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         date.Format(time.RFC1123Z),
+		"Message-ID":   messageID(from),
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/alternative; boundary=" + body.Boundary(),
+	}
+	for name, value := range msg.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(name)] = value
+	}
+
+	var head bytes.Buffer
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.NewReplacer("\r", "", "\n", "").Replace(headers[name])
+		fmt.Fprintf(&head, "%s: %s\r\n", name, value)
+	}
+	head.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return append(head.Bytes(), buf.Bytes()...), nil
+}
+
+// messageID makes a unique Message-ID at the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(address.Address, "@"); at >= 0 {
+			domain = address.Address[at+1:]
+		}
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Driver: "log", From: "open.news <digest@open.news>"})
+	assert.NoError(t, err)
+
+	_, err = New(Config{Driver: "smtp", From: "digest@open.news"})
+	assert.Error(t, err, "smtp needs a host")
+
+	_, err = New(Config{Driver: "carrier-pigeon", From: "digest@open.news"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Driver: "log", From: "not an address"})
+	assert.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+	data, err := Encode("open.news <digest@open.news>", Message{
+		To:      "reader@example.com",
+		Subject: "Today's top stories — Mar 4",
+		Text:    "1. Story\nhttps://example.com/story",
+		HTML:    `<p><a href="https://example.com/story">Story</a></p>`,
+		Headers: map[string]string{"list-unsubscribe": "<https://open.news/email/unsubscribe/abc>\r\nBcc: evil@example.com"},
+	}, time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "reader@example.com", msg.Header.Get("To"))
+	assert.Empty(t, msg.Header.Get("Bcc"), "header values can't inject headers")
+	assert.Contains(t, msg.Header.Get("List-Unsubscribe"), "https://open.news/email/unsubscribe/abc")
+	assert.Contains(t, msg.Header.Get("Message-Id"), "@open.news>")
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Today's top stories — Mar 4", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, part.Header.Get("Content-Type")+": "+string(content))
+	}
+	require.Len(t, parts, 2)
+	assert.Equal(t, "text/plain; charset=utf-8: 1. Story\r\nhttps://example.com/story", parts[0])
+	assert.Contains(t, parts[1], `<a href="https://example.com/story">`)
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ampCacheSuffix is the host suffix of Google's AMP cache
+const ampCacheSuffix = ".cdn.ampproject.org"
+
+// alternateHostPrefixes are subdomains publishers serve AMP and mobile pages on
+var alternateHostPrefixes = []string{"amp.", "m.", "mobile."}
+
+// IsAMPURL reports whether a URL looks like the AMP version of a page: on an
+// amp. subdomain or the AMP cache, with an amp path segment or .amp extension,
+// or with an amp query parameter
+func IsAMPURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.HasPrefix(host, "amp.") || strings.HasSuffix(host, ampCacheSuffix) {
+		return true
+	}
+	path := strings.ToLower(u.Path)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "amp" || strings.HasSuffix(segment, ".amp") || strings.HasSuffix(segment, ".amp.html") {
+			return true
+		}
+	}
+	query := u.Query()
+	return query.Has("amp") || query.Get("outputType") == "amp" || query.Get("_amp") == "true"
+}
+
+// IsMobileURL reports whether a URL is on a mobile subdomain, m. or mobile.
+func IsMobileURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return strings.HasPrefix(host, "m.") || strings.HasPrefix(host, "mobile.")
+}
+
+// IsAlternateURL reports whether a URL looks like an AMP or mobile version of a
+// page, whose canonical URL should be stored instead
+func IsAlternateURL(rawURL string) bool {
+	return IsAMPURL(rawURL) || IsMobileURL(rawURL)
+}
+
+// AMPCacheOrigin returns the publisher's URL of a page served from the AMP
+// cache, e.g. https://www.example.com/story for
+// https://www-example-com.cdn.ampproject.org/c/s/www.example.com/story. It
+// reports false for other URLs.
+func AMPCacheOrigin(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasSuffix(strings.ToLower(u.Hostname()), ampCacheSuffix) {
+		return "", false
+	}
+	// /c/ serves documents, /v/ the viewer and /i/ images; s/ marks https
+	path := strings.TrimPrefix(u.Path, "/")
+	kind, rest, ok := strings.Cut(path, "/")
+	if !ok || (kind != "c" && kind != "v" && kind != "i") {
+		return "", false
+	}
+	scheme := "http"
+	if strings.HasPrefix(rest, "s/") {
+		scheme, rest = "https", strings.TrimPrefix(rest, "s/")
+	}
+	origin, err := url.Parse(scheme + "://" + rest)
+	if err != nil || origin.Host == "" {
+		return "", false
+	}
+	origin.RawQuery = u.RawQuery
+	return origin.String(), true
+}
+
+// CanonicalLink returns the absolute URL of a page's <link rel="canonical">, or
+// "" when it has none
+func CanonicalLink(doc *html.Node, pageURL string) string {
+	return linkHref(doc, pageURL, "canonical")
+}
+
+// AMPLink returns the absolute URL of a page's <link rel="amphtml">, the AMP
+// version of the page, or "" when it has none
+func AMPLink(doc *html.Node, pageURL string) string {
+	return linkHref(doc, pageURL, "amphtml")
+}
+
+// DesktopURL returns the canonical URL an AMP or mobile page names, or pageURL
+// when it names none or one on another site
+func DesktopURL(doc *html.Node, pageURL string) string {
+	if canonical := CanonicalLink(doc, pageURL); canonical != "" && SameSite(canonical, pageURL) {
+		return canonical
+	}
+	return pageURL
+}
+
+// SameSite reports whether two URLs are on the same site, ignoring www., amp.
+// and mobile subdomains, and taking one site's subdomains (such as
+// edition.cnn.com for amp.cnn.com) as part of it
+func SameSite(a, b string) bool {
+	hostA, hostB := siteHost(a), siteHost(b)
+	if hostA == "" || hostB == "" {
+		return false
+	}
+	return hostA == hostB || strings.HasSuffix(hostA, "."+hostB) || strings.HasSuffix(hostB, "."+hostA)
+}
+
+// siteHost returns a URL's host without www., AMP and mobile subdomains, or ""
+func siteHost(rawURL string) string {
+	if origin, ok := AMPCacheOrigin(rawURL); ok {
+		rawURL = origin
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, prefix := range alternateHostPrefixes {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
+
+// linkHref returns the absolute http(s) URL of the first <link> with a rel, or ""
+func linkHref(doc *html.Node, pageURL, rel string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	var href string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if href != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "link" && hasToken(attribute(n, "rel"), rel) {
+			if target, err := base.Parse(strings.TrimSpace(attribute(n, "href"))); err == nil && (target.Scheme == "http" || target.Scheme == "https") {
+				target.Fragment = ""
+				href = target.String()
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return href
+}
+
+// hasToken reports whether a space-separated list such as a rel attribute has a
+// token, ignoring case
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
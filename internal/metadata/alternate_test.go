@@ -0,0 +1,79 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestAlternateURLs(t *testing.T) {
+	tests := []struct {
+		url    string
+		amp    bool
+		mobile bool
+	}{
+		{"https://amp.theguardian.com/world/story", true, false},
+		{"https://www.bbc.co.uk/news/amp/world-123", true, false},
+		{"https://www.example.com/2024/05/story/amp/", true, false},
+		{"https://www.example.com/story.amp.html", true, false},
+		{"https://www.example.com/story?amp", true, false},
+		{"https://www.example.com/story?outputType=amp", true, false},
+		{"https://www-example-com.cdn.ampproject.org/c/s/www.example.com/story", true, false},
+		{"https://m.example.com/story", false, true},
+		{"https://mobile.example.com/story", false, true},
+		{"https://www.example.com/ample-supplies", false, false},
+		{"https://www.example.com/story", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.amp, IsAMPURL(tt.url))
+			assert.Equal(t, tt.mobile, IsMobileURL(tt.url))
+			assert.Equal(t, tt.amp || tt.mobile, IsAlternateURL(tt.url))
+		})
+	}
+}
+
+func TestAMPCacheOrigin(t *testing.T) {
+	origin, ok := AMPCacheOrigin("https://www-example-com.cdn.ampproject.org/c/s/www.example.com/story/amp?id=7")
+	assert.True(t, ok)
+	assert.Equal(t, "https://www.example.com/story/amp?id=7", origin)
+
+	origin, ok = AMPCacheOrigin("https://news-example-org.cdn.ampproject.org/v/news.example.org/story")
+	assert.True(t, ok)
+	assert.Equal(t, "http://news.example.org/story", origin)
+
+	_, ok = AMPCacheOrigin("https://www.example.com/c/s/story")
+	assert.False(t, ok)
+}
+
+func TestDesktopURL(t *testing.T) {
+	parse := func(page string) *html.Node {
+		doc, err := html.Parse(strings.NewReader(page))
+		require.NoError(t, err)
+		return doc
+	}
+
+	amp := parse(`<html amp><head><link rel="canonical" href="/world/story#top"><link rel="amphtml" href="/world/story/amp"></head></html>`)
+	assert.Equal(t, "https://www.example.com/world/story", DesktopURL(amp, "https://www.example.com/world/story/amp"))
+	assert.Equal(t, "https://www.example.com/world/story/amp", AMPLink(amp, "https://www.example.com/world/story"))
+
+	mobile := parse(`<html><head><link rel="Canonical" href="https://edition.example.com/story"></head></html>`)
+	assert.Equal(t, "https://edition.example.com/story", DesktopURL(mobile, "https://m.example.com/story"))
+
+	elsewhere := parse(`<html><head><link rel="canonical" href="https://other.example/story"></head></html>`)
+	assert.Equal(t, "https://amp.example.com/story", DesktopURL(elsewhere, "https://amp.example.com/story"), "canonical pages on other sites are ignored")
+
+	assert.Equal(t, "", CanonicalLink(parse(`<html><head><link rel="canonical" href="javascript:alert(1)"></head></html>`), "https://www.example.com/"))
+}
+
+func TestSameSite(t *testing.T) {
+	assert.True(t, SameSite("https://www.example.com/a", "https://amp.example.com/b"))
+	assert.True(t, SameSite("https://edition.cnn.com/a", "https://amp.cnn.com/b"))
+	assert.True(t, SameSite("https://www.example.com/a", "https://www-example-com.cdn.ampproject.org/c/s/www.example.com/a"))
+	assert.False(t, SameSite("https://www.example.com/a", "https://www.example.org/a"))
+	assert.False(t, SameSite("https://badexample.com/a", "https://example.com/a"))
+}
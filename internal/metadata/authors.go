@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+)
+
+// bylineSeparators split a byline naming several authors, e.g.
+// "By Jane Roe, John Doe and Ann Lee"
+var bylineSeparators = regexp.MustCompile(`(?i)\s*(?:,|;|&|\|)\s*|\s+and\s+`)
+
+// bylinePrefix is the "By" bylines start with
+var bylinePrefix = regexp.MustCompile(`(?i)^by[\s:]+`)
+
+// bylineRoles are words marking a part of a byline as a job title or agency
+// credit rather than a name, e.g. the "Staff Writer" of "Jane Roe, Staff Writer"
+var bylineRoles = []string{"staff", "writer", "reporter", "correspondent", "editor", "contributor", "columnist"}
+
+// ParseByline splits a byline into the names of its authors, dropping a leading
+// "By", job titles, profile URLs and repeated names
+func ParseByline(byline string) []string {
+	byline = bylinePrefix.ReplaceAllString(strings.TrimSpace(byline), "")
+	var authors []string
+	for _, part := range bylineSeparators.Split(byline, -1) {
+		authors = appendAuthor(authors, part)
+	}
+	return authors
+}
+
+// JSONLDAuthors returns the author names of a JSON-LD author value: a name, a
+// Person or Organization object, or an array of either
+func JSONLDAuthors(value interface{}) []string {
+	var authors []string
+	var collect func(interface{})
+	collect = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			for _, name := range ParseByline(v) {
+				authors = appendAuthor(authors, name)
+			}
+		case map[string]interface{}:
+			if name, ok := v["name"].(string); ok {
+				collect(name)
+			}
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		}
+	}
+	collect(value)
+	return authors
+}
+
+// JoinAuthors writes authors as a byline: "Jane Roe", "Jane Roe and John Doe",
+// or "Jane Roe, John Doe and Ann Lee"
+func JoinAuthors(authors []string) string {
+	switch len(authors) {
+	case 0:
+		return ""
+	case 1:
+		return authors[0]
+	}
+	return strings.Join(authors[:len(authors)-1], ", ") + " and " + authors[len(authors)-1]
+}
+
+// appendAuthor adds a name to authors unless it's empty, a URL, a job title or
+// already listed
+func appendAuthor(authors []string, name string) []string {
+	name = strings.Join(strings.Fields(bylinePrefix.ReplaceAllString(strings.TrimSpace(name), "")), " ")
+	if name == "" || strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") || isBylineRole(name) {
+		return authors
+	}
+	for _, author := range authors {
+		if strings.EqualFold(author, name) {
+			return authors
+		}
+	}
+	return append(authors, name)
+}
+
+// isBylineRole reports whether part of a byline is a job title
+func isBylineRole(part string) bool {
+	for _, word := range strings.Fields(strings.ToLower(part)) {
+		word = strings.TrimSuffix(strings.Trim(word, ".()"), "s")
+		for _, role := range bylineRoles {
+			if word == role {
+				return true
+			}
+		}
+	}
+	return false
+}
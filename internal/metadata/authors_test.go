@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByline(t *testing.T) {
+	tests := []struct {
+		byline string
+		want   []string
+	}{
+		{"Jane Roe", []string{"Jane Roe"}},
+		{"By Jane Roe and John Doe", []string{"Jane Roe", "John Doe"}},
+		{"BY: Jane Roe, John Doe & Ann Lee", []string{"Jane Roe", "John Doe", "Ann Lee"}},
+		{"Jane Roe, Staff Writer", []string{"Jane Roe"}},
+		{"Jane Roe; jane roe", []string{"Jane Roe"}},
+		{"Sandra Andersen", []string{"Sandra Andersen"}},
+		{"https://example.com/staff/jane", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.byline, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseByline(tt.byline))
+		})
+	}
+}
+
+func TestJSONLDAuthors(t *testing.T) {
+	tests := []struct {
+		name   string
+		author string
+		want   []string
+	}{
+		{"name", `"Jane Roe"`, []string{"Jane Roe"}},
+		{"person", `{"@type": "Person", "name": "Jane Roe"}`, []string{"Jane Roe"}},
+		{"array", `[{"@type": "Person", "name": "Jane Roe"}, {"@type": "Person", "name": "By John Doe"}, "Ann Lee"]`,
+			[]string{"Jane Roe", "John Doe", "Ann Lee"}},
+		{"byline in name", `{"@type": "Person", "name": "Jane Roe and John Doe"}`, []string{"Jane Roe", "John Doe"}},
+		{"no name", `{"@type": "Person", "url": "https://example.com/jane"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var author interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.author), &author))
+			assert.Equal(t, tt.want, JSONLDAuthors(author))
+		})
+	}
+
+	assert.Nil(t, JSONLDAuthors(nil))
+}
+
+func TestJoinAuthors(t *testing.T) {
+	assert.Equal(t, "", JoinAuthors(nil))
+	assert.Equal(t, "Jane Roe", JoinAuthors([]string{"Jane Roe"}))
+	assert.Equal(t, "Jane Roe and John Doe", JoinAuthors([]string{"Jane Roe", "John Doe"}))
+	assert.Equal(t, "Jane Roe, John Doe and Ann Lee", JoinAuthors([]string{"Jane Roe", "John Doe", "Ann Lee"}))
+}
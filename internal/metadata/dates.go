@@ -0,0 +1,149 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// DateConfidence is how far an article's publication date can be trusted,
+// from where on the page it was found
+type DateConfidence string
+
+const (
+	DateConfidenceHigh   DateConfidence = "high"   // JSON-LD datePublished
+	DateConfidenceMedium DateConfidence = "medium" // Article or Dublin Core meta tags, or a <time> marked as the publication date
+	DateConfidenceLow    DateConfidence = "low"    // The page's first <time>, or its Last-Modified header
+)
+
+// earliestPublicationDate bounds publication dates from below; earlier ones,
+// such as the zero dates some CMSes write, are taken as missing
+var earliestPublicationDate = time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// futureDateTolerance is how far past the time of fetching a publication date
+// may be and still be taken, as now, for dates written without a time zone.
+// Dates further ahead are taken as missing.
+const futureDateTolerance = 24 * time.Hour
+
+// PublicationDate finds when a page was published, trying in order its JSON-LD
+// datePublished, article:published_time and Dublin Core meta tags, <time>
+// elements, and the HTTP Last-Modified header. Dates out of bounds are passed
+// over, and one later than the JSON-LD dateModified, a sign the page wrote its
+// update time as its publication time, gives way to the modification time. It
+// returns nil and "" when no source has a usable date.
+func PublicationDate(doc *html.Node, header http.Header, now time.Time) (*time.Time, DateConfidence) {
+	if article := jsonLDArticle(doc); article != nil {
+		published := ParseMetaDate(stringField(article, "datePublished"))
+		modified := ParseMetaDate(stringField(article, "dateModified"))
+		if published != nil && modified != nil && published.After(*modified) {
+			if date, ok := boundDate(modified, now); ok {
+				return date, DateConfidenceMedium
+			}
+		}
+		if date, ok := boundDate(published, now); ok {
+			return date, DateConfidenceHigh
+		}
+	}
+
+	tags := ReadMetaTags(doc)
+	for _, key := range publishedTags {
+		if date, ok := boundDate(ParseMetaDate(tags[key]), now); ok {
+			return date, DateConfidenceMedium
+		}
+	}
+
+	if date, marked := timeElementDate(doc, now); date != nil {
+		if marked {
+			return date, DateConfidenceMedium
+		}
+		return date, DateConfidenceLow
+	}
+
+	if header != nil {
+		// Pages generated for each request send the time they were generated
+		if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil && lastModified.Before(now.Add(-time.Minute)) {
+			if date, ok := boundDate(&lastModified, now); ok {
+				return date, DateConfidenceLow
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// boundDate checks a publication date against the bounds, moving dates a
+// little in the future back to now
+func boundDate(date *time.Time, now time.Time) (*time.Time, bool) {
+	if date == nil || date.Before(earliestPublicationDate) || date.After(now.Add(futureDateTolerance)) {
+		return nil, false
+	}
+	if date.After(now) {
+		return &now, true
+	}
+	return date, true
+}
+
+// timeElementDate returns the date of the first <time datetime> on a page
+// marked as the publication date, with itemprop="datePublished" or a pubdate
+// attribute, and reports true; failing that, the first in bounds, reporting
+// false
+func timeElementDate(doc *html.Node, now time.Time) (*time.Time, bool) {
+	var marked, first *time.Time
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if marked != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "time" {
+			if date, ok := boundDate(ParseMetaDate(attribute(n, "datetime")), now); ok {
+				if hasToken(attribute(n, "itemprop"), "datePublished") || hasAttribute(n, "pubdate") {
+					marked = date
+					return
+				}
+				if first == nil {
+					first = date
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if marked != nil {
+		return marked, true
+	}
+	return first, false
+}
+
+// jsonLDArticle returns the article object in a page's JSON-LD, or nil
+func jsonLDArticle(doc *html.Node) map[string]interface{} {
+	merged := MergeJSONLD(JSONLDScripts(doc))
+	if merged == "" {
+		return nil
+	}
+	var jsonLD interface{}
+	if err := json.Unmarshal([]byte(merged), &jsonLD); err != nil {
+		return nil
+	}
+	return ArticleObject(jsonLD)
+}
+
+// stringField returns a JSON object's string field, or ""
+func stringField(object map[string]interface{}, key string) string {
+	value, _ := object[key].(string)
+	return value
+}
+
+// hasAttribute reports whether an element has an attribute, whatever its value
+func hasAttribute(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return true
+		}
+	}
+	return false
+}
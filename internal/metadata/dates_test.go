@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestPublicationDate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		head         string
+		body         string
+		lastModified string
+		want         *time.Time
+		confidence   DateConfidence
+	}{
+		{
+			name:       "JSON-LD",
+			head:       `<script type="application/ld+json">{"@type": "NewsArticle", "datePublished": "2025-05-30T08:00:00Z"}</script><meta property="article:published_time" content="2025-05-29T08:00:00Z">`,
+			want:       timePtr(time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceHigh,
+		},
+		{
+			name:       "published after modified",
+			head:       `<script type="application/ld+json">{"@type": "NewsArticle", "datePublished": "2025-05-31T09:00:00Z", "dateModified": "2025-05-30T08:00:00Z"}</script>`,
+			want:       timePtr(time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceMedium,
+		},
+		{
+			name:       "future JSON-LD date falls through to meta tags",
+			head:       `<script type="application/ld+json">{"@type": "NewsArticle", "datePublished": "2026-01-01T00:00:00Z"}</script><meta property="article:published_time" content="2025-05-29T08:00:00+0000">`,
+			want:       timePtr(time.Date(2025, 5, 29, 8, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceMedium,
+		},
+		{
+			name:       "date a few hours ahead is taken as now",
+			head:       `<meta property="article:published_time" content="2025-06-01T20:00:00">`,
+			want:       timePtr(now),
+			confidence: DateConfidenceMedium,
+		},
+		{
+			name:       "zero date falls through to Dublin Core",
+			head:       `<meta property="article:published_time" content="0001-01-01T00:00:00Z"><meta name="DC.date.issued" content="2025-05-28">`,
+			want:       timePtr(time.Date(2025, 5, 28, 0, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceMedium,
+		},
+		{
+			name:       "marked time element",
+			body:       `<time datetime="2025-05-31">Yesterday</time><time itemprop="datePublished" datetime="2025-05-27T10:00:00Z">May 27</time>`,
+			want:       timePtr(time.Date(2025, 5, 27, 10, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceMedium,
+		},
+		{
+			name:       "first time element",
+			body:       `<time>Today</time><time datetime="2025-05-26T10:00:00Z">May 26</time>`,
+			want:       timePtr(time.Date(2025, 5, 26, 10, 0, 0, 0, time.UTC)),
+			confidence: DateConfidenceLow,
+		},
+		{
+			name:         "Last-Modified",
+			lastModified: "Sun, 25 May 2025 10:00:00 GMT",
+			want:         timePtr(time.Date(2025, 5, 25, 10, 0, 0, 0, time.UTC)),
+			confidence:   DateConfidenceLow,
+		},
+		{
+			name:         "Last-Modified of a generated page",
+			lastModified: "Sun, 01 Jun 2025 12:00:00 GMT",
+		},
+		{
+			name: "no date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<html><head>" + tt.head + "</head><body>" + tt.body + "</body></html>"))
+			require.NoError(t, err)
+			header := http.Header{}
+			if tt.lastModified != "" {
+				header.Set("Last-Modified", tt.lastModified)
+			}
+
+			date, confidence := PublicationDate(doc, header, now)
+			assert.Equal(t, tt.confidence, confidence)
+			if tt.want == nil {
+				assert.Nil(t, date)
+				return
+			}
+			require.NotNil(t, date)
+			assert.True(t, tt.want.Equal(*date), "got %v", date)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
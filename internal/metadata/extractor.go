@@ -3,111 +3,86 @@ package metadata
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
 	"golang.org/x/net/html"
+
+	"open-news/internal/fetcher"
 )
 
 // ArticleMetadata represents extracted metadata from an article
 type ArticleMetadata struct {
-	Title       string
-	Description string
-	Author      string
-	SiteName    string
-	ImageURL    string
-	PublishedAt *time.Time
-	JSONLDData  string
-	OGData      string
-	HTMLContent string
-	TextContent string
-	WordCount   int64
-	ReadingTime int64
-	Language    string
+	Title          string
+	Description    string
+	Author         string   // Byline naming every author, e.g. "Jane Roe and John Doe"
+	Authors        []string // Author names, in byline order
+	SiteName       string
+	ImageURL       string
+	PublishedAt    *time.Time
+	DateConfidence DateConfidence // Where PublishedAt was found, see PublicationDate
+	JSONLDData     string
+	SchemaType     string // Highest weighted article type in the JSON-LD, see SchemaTypeWeights
+	OGData         string
+	HTMLContent    string
+	TextContent    string
+	WordCount      int64
+	ReadingTime    int64
+	Language       string
+
+	ReadingGrade float64 // Flesch-Kincaid grade level of the text
+	Sentiment    float64 // Tone of the text, from -1 to 1
+
+	CanonicalURL string // The page's <link rel="canonical">
+	AMPURL       string // The page's <link rel="amphtml">, its AMP version
+
+	NoArchive bool // The page asked for its content not to be kept, see NoArchive; HTMLContent and TextContent are empty
 }
 
 // MetadataExtractor handles extracting metadata from web articles
 type MetadataExtractor struct {
-	httpClient *http.Client
+	fetcher *fetcher.Fetcher
 }
 
 // NewMetadataExtractor creates a new metadata extractor
 func NewMetadataExtractor() *MetadataExtractor {
 	return &MetadataExtractor{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("stopped after 10 redirects")
-				}
-				return nil
-			},
-		},
+		fetcher: fetcher.NewFetcher(fetcher.DefaultOptions()),
 	}
 }
 
-// ExtractMetadata fetches and extracts full metadata from an article URL
+// ExtractMetadata fetches and extracts full metadata from an article URL.
+// Oversized or non-HTML responses are rejected with fetcher.ErrBodyTooLarge
+// or fetcher.ErrUnsupportedContentType.
 func (me *MetadataExtractor) ExtractMetadata(ctx context.Context, articleURL string) (*ArticleMetadata, error) {
-	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", articleURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set appropriate headers
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; OpenNewsBot/1.0; +https://opennews.social)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	// Remove Accept-Encoding to let Go's HTTP client handle compression automatically
-	req.Header.Set("Connection", "keep-alive")
-
-	// Make HTTP request
-	resp, err := me.httpClient.Do(req)
-	if err != nil {
-		log.Printf("❌ Network error fetching %s: %v", articleURL, err)
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("❌ Failed to fetch metadata for %s: HTTP %d (%s)", articleURL, resp.StatusCode, resp.Status)
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	log.Printf("✅ Successfully fetched metadata for %s (HTTP %d)", articleURL, resp.StatusCode)
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	page, err := me.fetcher.FetchHTML(ctx, articleURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		log.Printf("❌ Failed to fetch metadata for %s: %v", articleURL, err)
+		return nil, err
 	}
 
-	htmlContent := string(body)
+	log.Printf("✅ Successfully fetched metadata for %s", articleURL)
 
-	// Parse HTML
-	doc, err := html.Parse(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
-	}
+	doc := page.Root
 
 	// Extract metadata
 	metadata := &ArticleMetadata{
-		HTMLContent: htmlContent,
+		HTMLContent:  page.Content,
+		CanonicalURL: CanonicalLink(doc, page.URL),
+		AMPURL:       AMPLink(doc, page.URL),
 	}
 
+	tags := ReadMetaTags(doc)
 	me.extractOGData(doc, metadata)
 	me.extractJSONLD(doc, metadata)
-	me.extractTitle(doc, metadata)
-	me.extractDescription(doc, metadata)
-	me.extractAuthor(doc, metadata)
-	me.extractSiteName(doc, metadata)
-	me.extractImageURL(doc, metadata)
-	me.extractPublishedDate(doc, metadata)
+	me.extractTitle(doc, tags, metadata)
+	me.extractDescription(tags, metadata)
+	me.extractAuthor(tags, metadata)
+	me.extractSiteName(tags, metadata)
+	me.extractImageURL(tags, metadata)
+	metadata.PublishedAt, metadata.DateConfidence = PublicationDate(doc, page.Header, time.Now())
 	me.extractTextContent(doc, metadata)
 	me.extractLanguage(doc, metadata)
 
@@ -117,6 +92,14 @@ func (me *MetadataExtractor) ExtractMetadata(ctx context.Context, articleURL str
 		if metadata.ReadingTime < 1 {
 			metadata.ReadingTime = 1
 		}
+		metadata.ReadingGrade = ReadingGrade(metadata.TextContent)
+		metadata.Sentiment = Sentiment(metadata.TextContent)
+	}
+
+	if NoArchive(tags, page.Header) {
+		metadata.NoArchive = true
+		metadata.HTMLContent = ""
+		metadata.TextContent = ""
 	}
 
 	return metadata, nil
@@ -175,30 +158,16 @@ func (me *MetadataExtractor) extractOGData(doc *html.Node, metadata *ArticleMeta
 	}
 }
 
+// extractJSONLD stores all of a page's ld+json scripts, merged, and reads the
+// article's metadata from the article object in any of them
 func (me *MetadataExtractor) extractJSONLD(doc *html.Node, metadata *ArticleMetadata) {
-	var findJSONLD func(*html.Node)
-	findJSONLD = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "script" {
-			for _, attr := range n.Attr {
-				if attr.Key == "type" && attr.Val == "application/ld+json" {
-					if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
-						jsonldText := strings.TrimSpace(n.FirstChild.Data)
-						if jsonldText != "" {
-							metadata.JSONLDData = jsonldText
-							me.extractFromJSONLD(jsonldText, metadata)
-						}
-					}
-					return
-				}
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findJSONLD(c)
-		}
+	jsonldText := MergeJSONLD(JSONLDScripts(doc))
+	if jsonldText == "" {
+		return
 	}
-	
-	findJSONLD(doc)
+	metadata.JSONLDData = jsonldText
+	metadata.SchemaType, _ = SchemaType(jsonldText)
+	me.extractFromJSONLD(jsonldText, metadata)
 }
 
 func (me *MetadataExtractor) extractFromJSONLD(jsonldText string, metadata *ArticleMetadata) {
@@ -206,65 +175,50 @@ func (me *MetadataExtractor) extractFromJSONLD(jsonldText string, metadata *Arti
 	if err := json.Unmarshal([]byte(jsonldText), &data); err != nil {
 		return
 	}
-	
-	var processItem func(interface{})
-	processItem = func(item interface{}) {
-		if obj, ok := item.(map[string]interface{}); ok {
-			if typeVal, exists := obj["@type"]; exists {
-				if typeStr, ok := typeVal.(string); ok && (typeStr == "NewsArticle" || typeStr == "Article") {
-					// Extract article data
-					if headline, ok := obj["headline"].(string); ok && metadata.Title == "" {
-						metadata.Title = headline
-					}
-					if description, ok := obj["description"].(string); ok && metadata.Description == "" {
-						metadata.Description = description
-					}
-					if author, ok := obj["author"]; ok {
-						if authorObj, ok := author.(map[string]interface{}); ok {
-							if name, ok := authorObj["name"].(string); ok && metadata.Author == "" {
-								metadata.Author = name
-							}
-						}
-					}
-					if publisher, ok := obj["publisher"]; ok {
-						if pubObj, ok := publisher.(map[string]interface{}); ok {
-							if name, ok := pubObj["name"].(string); ok && metadata.SiteName == "" {
-								metadata.SiteName = name
-							}
-						}
-					}
-					if image, ok := obj["image"]; ok {
-						if imageStr, ok := image.(string); ok && metadata.ImageURL == "" {
-							metadata.ImageURL = imageStr
-						} else if imageArr, ok := image.([]interface{}); ok && len(imageArr) > 0 {
-							if imageObj, ok := imageArr[0].(map[string]interface{}); ok {
-								if url, ok := imageObj["url"].(string); ok && metadata.ImageURL == "" {
-									metadata.ImageURL = url
-								}
-							}
-						}
-					}
-					if datePublished, ok := obj["datePublished"].(string); ok && metadata.PublishedAt == nil {
-						if parsedTime, err := time.Parse(time.RFC3339, datePublished); err == nil {
-							metadata.PublishedAt = &parsedTime
-						}
-					}
-				}
+
+	obj := ArticleObject(data)
+	if obj == nil {
+		return
+	}
+
+	// Extract article data
+	if headline, ok := obj["headline"].(string); ok && metadata.Title == "" {
+		metadata.Title = headline
+	}
+	if description, ok := obj["description"].(string); ok && metadata.Description == "" {
+		metadata.Description = description
+	}
+	if authors := JSONLDAuthors(obj["author"]); len(authors) > 0 && len(metadata.Authors) == 0 {
+		metadata.Authors = authors
+	}
+	if publisher, ok := obj["publisher"]; ok {
+		if pubObj, ok := publisher.(map[string]interface{}); ok {
+			if name, ok := pubObj["name"].(string); ok && metadata.SiteName == "" {
+				metadata.SiteName = name
 			}
-		} else if arr, ok := item.([]interface{}); ok {
-			for _, subItem := range arr {
-				processItem(subItem)
+		}
+	}
+	if image, ok := obj["image"]; ok {
+		if imageStr, ok := image.(string); ok && metadata.ImageURL == "" {
+			metadata.ImageURL = imageStr
+		} else if imageArr, ok := image.([]interface{}); ok && len(imageArr) > 0 {
+			if imageObj, ok := imageArr[0].(map[string]interface{}); ok {
+				if url, ok := imageObj["url"].(string); ok && metadata.ImageURL == "" {
+					metadata.ImageURL = url
+				}
 			}
 		}
 	}
-	
-	processItem(data)
 }
 
-func (me *MetadataExtractor) extractTitle(doc *html.Node, metadata *ArticleMetadata) {
+func (me *MetadataExtractor) extractTitle(doc *html.Node, tags MetaTags, metadata *ArticleMetadata) {
 	if metadata.Title != "" {
 		return
 	}
+	if title := tags.Title(); title != "" {
+		metadata.Title = title
+		return
+	}
 	
 	var findTitle func(*html.Node) string
 	findTitle = func(n *html.Node) string {
@@ -285,151 +239,32 @@ func (me *MetadataExtractor) extractTitle(doc *html.Node, metadata *ArticleMetad
 	metadata.Title = findTitle(doc)
 }
 
-func (me *MetadataExtractor) extractDescription(doc *html.Node, metadata *ArticleMetadata) {
-	if metadata.Description != "" {
-		return
-	}
-	
-	var findMeta func(*html.Node)
-	findMeta = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "meta" {
-			var name, content string
-			for _, attr := range n.Attr {
-				if attr.Key == "name" && (attr.Val == "description" || attr.Val == "twitter:description") {
-					name = attr.Val
-				} else if attr.Key == "content" {
-					content = attr.Val
-				}
-			}
-			if name != "" && content != "" && metadata.Description == "" {
-				metadata.Description = content
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findMeta(c)
-		}
-	}
-	
-	findMeta(doc)
-}
+// The fields below fall back to Twitter card and Dublin Core tags when Open
+// Graph and JSON-LD leave them empty
 
-func (me *MetadataExtractor) extractAuthor(doc *html.Node, metadata *ArticleMetadata) {
-	if metadata.Author != "" {
-		return
-	}
-	
-	var findMeta func(*html.Node)
-	findMeta = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "meta" {
-			var name, content string
-			for _, attr := range n.Attr {
-				if attr.Key == "name" && (attr.Val == "author" || attr.Val == "article:author") {
-					name = attr.Val
-				} else if attr.Key == "content" {
-					content = attr.Val
-				}
-			}
-			if name != "" && content != "" && metadata.Author == "" {
-				metadata.Author = content
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findMeta(c)
-		}
+func (me *MetadataExtractor) extractDescription(tags MetaTags, metadata *ArticleMetadata) {
+	if metadata.Description == "" {
+		metadata.Description = tags.Description()
 	}
-	
-	findMeta(doc)
 }
 
-func (me *MetadataExtractor) extractSiteName(doc *html.Node, metadata *ArticleMetadata) {
-	if metadata.SiteName != "" {
-		return
+func (me *MetadataExtractor) extractAuthor(tags MetaTags, metadata *ArticleMetadata) {
+	if len(metadata.Authors) == 0 {
+		metadata.Authors = ParseByline(tags.Author())
 	}
-	
-	var findMeta func(*html.Node)
-	findMeta = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "meta" {
-			var property, content string
-			for _, attr := range n.Attr {
-				if attr.Key == "property" && attr.Val == "og:site_name" {
-					property = attr.Val
-				} else if attr.Key == "content" {
-					content = attr.Val
-				}
-			}
-			if property != "" && content != "" && metadata.SiteName == "" {
-				metadata.SiteName = content
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findMeta(c)
-		}
-	}
-	
-	findMeta(doc)
+	metadata.Author = JoinAuthors(metadata.Authors)
 }
 
-func (me *MetadataExtractor) extractImageURL(doc *html.Node, metadata *ArticleMetadata) {
-	if metadata.ImageURL != "" {
-		return
+func (me *MetadataExtractor) extractSiteName(tags MetaTags, metadata *ArticleMetadata) {
+	if metadata.SiteName == "" {
+		metadata.SiteName = tags.SiteName()
 	}
-	
-	var findMeta func(*html.Node)
-	findMeta = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "meta" {
-			var property, content string
-			for _, attr := range n.Attr {
-				if attr.Key == "property" && (attr.Val == "og:image" || attr.Val == "twitter:image") {
-					property = attr.Val
-				} else if attr.Key == "content" {
-					content = attr.Val
-				}
-			}
-			if property != "" && content != "" && metadata.ImageURL == "" {
-				metadata.ImageURL = content
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findMeta(c)
-		}
-	}
-	
-	findMeta(doc)
 }
 
-func (me *MetadataExtractor) extractPublishedDate(doc *html.Node, metadata *ArticleMetadata) {
-	if metadata.PublishedAt != nil {
-		return
-	}
-	
-	var findMeta func(*html.Node)
-	findMeta = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "meta" {
-			var property, content string
-			for _, attr := range n.Attr {
-				if attr.Key == "property" && (attr.Val == "article:published_time" || attr.Val == "article:published") {
-					property = attr.Val
-				} else if attr.Key == "content" {
-					content = attr.Val
-				}
-			}
-			if property != "" && content != "" && metadata.PublishedAt == nil {
-				if parsedTime, err := time.Parse(time.RFC3339, content); err == nil {
-					metadata.PublishedAt = &parsedTime
-				}
-			}
-		}
-		
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			findMeta(c)
-		}
+func (me *MetadataExtractor) extractImageURL(tags MetaTags, metadata *ArticleMetadata) {
+	if metadata.ImageURL == "" {
+		metadata.ImageURL = tags.ImageURL()
 	}
-	
-	findMeta(doc)
 }
 
 func (me *MetadataExtractor) extractTextContent(doc *html.Node, metadata *ArticleMetadata) {
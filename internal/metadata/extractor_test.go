@@ -3,14 +3,24 @@ package metadata
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"open-news/internal/fetcher"
 )
 
+func TestMain(m *testing.M) {
+	// Test servers listen on 127.0.0.1, which the SSRF guard blocks by default
+	os.Setenv("FETCH_ALLOW_PRIVATE_NETWORKS", "true")
+	os.Exit(m.Run())
+}
+
 func TestExtractMetadata(t *testing.T) {
 	// Read test HTML file
 	htmlContent, err := os.ReadFile("testdata/sample_article.html")
@@ -87,6 +97,10 @@ func TestExtractMetadata(t *testing.T) {
 		t.Error("Expected JSONLDData to contain NewsArticle type")
 	}
 
+	if metadata.SchemaType != "NewsArticle" {
+		t.Errorf("Expected SchemaType = NewsArticle, got %q", metadata.SchemaType)
+	}
+
 	// Test Open Graph data
 	if metadata.OGData == "" {
 		t.Error("Expected OGData to be extracted")
@@ -139,6 +153,106 @@ func TestExtractMetadataMinimalHTML(t *testing.T) {
 	}
 }
 
+func TestExtractMetadataMultipleJSONLD(t *testing.T) {
+	pageHTML := `<!DOCTYPE html>
+<html>
+<head>
+	<title>Page Title</title>
+	<script type="application/ld+json">{"@context": "https://schema.org", "@type": "WebSite", "name": "Site Search", "description": "Search the site"}</script>
+	<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+		{"@type": "Organization", "name": "Example Publisher"},
+		{"@type": "NewsArticle", "headline": "Headline From Schema", "description": "The article's description",
+		 "author": {"@type": "Person", "name": "Jane Roe"}, "datePublished": "2025-07-28T12:00:00Z"}
+	]}</script>
+</head>
+<body><p>Article text.</p></body>
+</html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(pageHTML))
+	}))
+	defer server.Close()
+
+	extractor := NewMetadataExtractor()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	metadata, err := extractor.ExtractMetadata(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to extract metadata: %v", err)
+	}
+
+	if metadata.SchemaType != "NewsArticle" {
+		t.Errorf("Expected SchemaType = NewsArticle, got %q", metadata.SchemaType)
+	}
+	if metadata.Description != "The article's description" {
+		t.Errorf("Expected the article's description, got %q", metadata.Description)
+	}
+	if metadata.Author != "Jane Roe" {
+		t.Errorf("Expected Author = 'Jane Roe', got %q", metadata.Author)
+	}
+	if len(metadata.Authors) != 1 || metadata.Authors[0] != "Jane Roe" {
+		t.Errorf("Expected Authors = [Jane Roe], got %v", metadata.Authors)
+	}
+	if metadata.PublishedAt == nil {
+		t.Error("Expected PublishedAt to be set from the second script")
+	}
+	if !strings.Contains(metadata.JSONLDData, "Site Search") || !strings.Contains(metadata.JSONLDData, "Headline From Schema") {
+		t.Errorf("Expected JSONLDData to keep both scripts, got %q", metadata.JSONLDData)
+	}
+}
+
+func TestExtractMetadataTwitterAndDublinCore(t *testing.T) {
+	pageHTML := `<!DOCTYPE html>
+<html>
+<head>
+	<title>Page Title | Example Daily</title>
+	<meta name="twitter:title" content="Card Title">
+	<meta name="twitter:description" content="Card description">
+	<meta name="twitter:image" content="https://example.com/card.jpg">
+	<meta name="DC.creator" content="Jane Roe">
+	<meta name="DC.publisher" content="Example Daily">
+	<meta name="DC.date.issued" content="2025-03-04">
+</head>
+<body><p>Article text.</p></body>
+</html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(pageHTML))
+	}))
+	defer server.Close()
+
+	extractor := NewMetadataExtractor()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	metadata, err := extractor.ExtractMetadata(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to extract metadata: %v", err)
+	}
+
+	if metadata.Title != "Card Title" {
+		t.Errorf("Expected Title = 'Card Title', got %q", metadata.Title)
+	}
+	if metadata.Description != "Card description" {
+		t.Errorf("Expected Description = 'Card description', got %q", metadata.Description)
+	}
+	if metadata.ImageURL != "https://example.com/card.jpg" {
+		t.Errorf("Expected the card image, got %q", metadata.ImageURL)
+	}
+	if metadata.Author != "Jane Roe" {
+		t.Errorf("Expected Author = 'Jane Roe', got %q", metadata.Author)
+	}
+	if metadata.SiteName != "Example Daily" {
+		t.Errorf("Expected SiteName = 'Example Daily', got %q", metadata.SiteName)
+	}
+	if metadata.PublishedAt == nil || !metadata.PublishedAt.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected PublishedAt from DC.date.issued, got %v", metadata.PublishedAt)
+	}
+}
+
 func TestExtractMetadataHTTPError(t *testing.T) {
 	// Create a test server that returns 404
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -161,6 +275,90 @@ func TestExtractMetadataHTTPError(t *testing.T) {
 	}
 }
 
+func TestExtractMetadataRejectsNonHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer server.Close()
+
+	extractor := NewMetadataExtractor()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := extractor.ExtractMetadata(ctx, server.URL)
+	if !errors.Is(err, fetcher.ErrUnsupportedContentType) {
+		t.Errorf("Expected ErrUnsupportedContentType, got: %v", err)
+	}
+}
+
+func TestExtractMetadataRejectsLargeBody(t *testing.T) {
+	t.Setenv("FETCH_MAX_BODY_BYTES", "1024")
+
+	tests := []struct {
+		name          string
+		contentLength bool
+	}{
+		{"announced content length", true},
+		{"chunked body", false},
+	}
+
+	body := "<html><body><p>" + strings.Repeat("news ", 1000) + "</p></body></html>"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				if tt.contentLength {
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				}
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			extractor := NewMetadataExtractor()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			_, err := extractor.ExtractMetadata(ctx, server.URL)
+			if !errors.Is(err, fetcher.ErrBodyTooLarge) {
+				t.Errorf("Expected ErrBodyTooLarge, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractMetadataBlocksPrivateAddresses(t *testing.T) {
+	t.Setenv("FETCH_ALLOW_PRIVATE_NETWORKS", "false")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request should not reach a loopback server")
+	}))
+	defer server.Close()
+
+	extractor := NewMetadataExtractor()
+
+	urls := []string{
+		server.URL,
+		"http://localhost:1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://[::1]:1/",
+	}
+
+	for _, u := range urls {
+		t.Run(u, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := extractor.ExtractMetadata(ctx, u)
+			if !errors.Is(err, fetcher.ErrBlockedAddress) {
+				t.Errorf("Expected ErrBlockedAddress, got: %v", err)
+			}
+		})
+	}
+}
+
 func TestExtractMetadataInvalidURL(t *testing.T) {
 	extractor := NewMetadataExtractor()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// MetaTags are the values of a page's <meta> tags by lowercased name or
+// property, e.g. "twitter:title" or "dc.date.issued"
+type MetaTags map[string]string
+
+// Meta tags read, in order, when Open Graph and JSON-LD leave a field empty.
+// Many outlets fill in Twitter card or Dublin Core tags more fully than Open Graph.
+var (
+	titleTags       = []string{"twitter:title", "dc.title", "dcterms.title"}
+	descriptionTags = []string{"description", "twitter:description", "dc.description", "dcterms.description", "dcterms.abstract"}
+	imageTags       = []string{"twitter:image", "twitter:image:src"}
+	authorTags      = []string{"author", "article:author", "dc.creator", "dcterms.creator"}
+	siteNameTags    = []string{"og:site_name", "dc.publisher", "dcterms.publisher"}
+	publishedTags   = []string{"article:published_time", "article:published", "dc.date.issued", "dcterms.issued", "dc.date", "dcterms.date", "dcterms.created", "dc.date.created"}
+)
+
+// metaDateLayouts are the formats dates in meta tags are parsed with
+var metaDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ReadMetaTags collects a page's <meta> tags, keeping the first value of each
+func ReadMetaTags(doc *html.Node) MetaTags {
+	tags := MetaTags{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			content := strings.TrimSpace(attribute(n, "content"))
+			for _, key := range []string{attribute(n, "name"), attribute(n, "property")} {
+				key = strings.ToLower(strings.TrimSpace(key))
+				if key == "" || content == "" {
+					continue
+				}
+				if _, seen := tags[key]; !seen {
+					tags[key] = content
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return tags
+}
+
+// First returns the value of the first of keys the page has, or ""
+func (m MetaTags) First(keys ...string) string {
+	for _, key := range keys {
+		if value := m[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Title returns the page's Twitter card or Dublin Core title
+func (m MetaTags) Title() string {
+	return m.First(titleTags...)
+}
+
+// Description returns the page's meta, Twitter card or Dublin Core description
+func (m MetaTags) Description() string {
+	return m.First(descriptionTags...)
+}
+
+// ImageURL returns the page's Twitter card image
+func (m MetaTags) ImageURL() string {
+	return m.First(imageTags...)
+}
+
+// Author returns the page's meta or Dublin Core author. Profile URLs, which
+// article:author often holds, are skipped.
+func (m MetaTags) Author() string {
+	for _, key := range authorTags {
+		if value := m[key]; value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			return value
+		}
+	}
+	return ""
+}
+
+// SiteName returns the page's Open Graph or Dublin Core publisher name
+func (m MetaTags) SiteName() string {
+	return m.First(siteNameTags...)
+}
+
+// PublishedAt returns the first publication date in the page's article or
+// Dublin Core tags that parses, or nil
+func (m MetaTags) PublishedAt() *time.Time {
+	for _, key := range publishedTags {
+		if date := ParseMetaDate(m[key]); date != nil {
+			return date
+		}
+	}
+	return nil
+}
+
+// ParseMetaDate parses a date as meta tags write them: RFC 3339, or a date
+// and time without a zone (taken as UTC), or a bare date. It returns nil for
+// values that don't parse.
+func ParseMetaDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range metaDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
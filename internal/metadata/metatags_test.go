@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestReadMetaTags(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+		<meta name="Twitter:Title" content=" Card Title ">
+		<meta property="twitter:title" content="Second Card Title">
+		<meta name="DC.Date.Issued" content="2025-03-04">
+		<meta name="empty" content="">
+	</head></html>`))
+	require.NoError(t, err)
+
+	tags := ReadMetaTags(doc)
+	assert.Equal(t, "Card Title", tags["twitter:title"])
+	assert.Equal(t, "2025-03-04", tags["dc.date.issued"])
+	assert.NotContains(t, tags, "empty")
+}
+
+func TestMetaTagsFallbacks(t *testing.T) {
+	tags := MetaTags{
+		"dc.title":            "Dublin Core Title",
+		"twitter:title":       "Card Title",
+		"twitter:description": "Card description",
+		"twitter:image:src":   "https://example.com/card.jpg",
+		"article:author":      "https://example.com/staff/jane",
+		"dc.creator":          "Jane Roe",
+		"dc.publisher":        "Example Daily",
+		"dc.date":             "not a date",
+		"dcterms.created":     "2025-03-04T10:00:00",
+	}
+
+	assert.Equal(t, "Card Title", tags.Title())
+	assert.Equal(t, "Card description", tags.Description())
+	assert.Equal(t, "https://example.com/card.jpg", tags.ImageURL())
+	assert.Equal(t, "Jane Roe", tags.Author())
+	assert.Equal(t, "Example Daily", tags.SiteName())
+	require.NotNil(t, tags.PublishedAt())
+	assert.Equal(t, time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC), *tags.PublishedAt())
+
+	assert.Empty(t, MetaTags{}.Title())
+	assert.Nil(t, MetaTags{}.PublishedAt())
+}
+
+func TestParseMetaDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2025-03-04T10:00:00Z", time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"2025-03-04T10:00:00-05:00", time.Date(2025, 3, 4, 15, 0, 0, 0, time.UTC)},
+		{"2025-03-04T10:00:00", time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"2025-03-04 10:00:00", time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{" 2025-03-04 ", time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			date := ParseMetaDate(tt.value)
+			require.NotNil(t, date)
+			assert.True(t, tt.want.Equal(*date), "got %v", date)
+		})
+	}
+
+	assert.Nil(t, ParseMetaDate(""))
+	assert.Nil(t, ParseMetaDate("March 4"))
+}
@@ -0,0 +1,145 @@
+package metadata
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SchemaRequirement says how a NewsArticle JSON-LD schema counts when deciding
+// whether a page is a news article. It's set for all sites with
+// NEWSARTICLE_SCHEMA and for one site in its domains table entry.
+type SchemaRequirement string
+
+const (
+	// SchemaRequire accepts only pages with the schema
+	SchemaRequire SchemaRequirement = "require"
+
+	// SchemaPrefer accepts pages with the schema, and pages without it that
+	// have og:type article or look like articles
+	SchemaPrefer SchemaRequirement = "prefer"
+
+	// SchemaIgnore doesn't look at the schema: pages are accepted when they
+	// have og:type article or look like articles
+	SchemaIgnore SchemaRequirement = "ignore"
+)
+
+// ParseSchemaRequirement parses a schema requirement, reporting whether it's valid
+func ParseSchemaRequirement(value string) (SchemaRequirement, bool) {
+	switch requirement := SchemaRequirement(strings.ToLower(strings.TrimSpace(value))); requirement {
+	case SchemaRequire, SchemaPrefer, SchemaIgnore:
+		return requirement, true
+	}
+	return "", false
+}
+
+// LoadSchemaRequirement reads the requirement of sites without their own from
+// NEWSARTICLE_SCHEMA (default require)
+func LoadSchemaRequirement() SchemaRequirement {
+	value := os.Getenv("NEWSARTICLE_SCHEMA")
+	if value == "" {
+		return SchemaRequire
+	}
+	requirement, ok := ParseSchemaRequirement(value)
+	if !ok {
+		log.Printf("Invalid NEWSARTICLE_SCHEMA %q, using %s", value, SchemaRequire)
+		return SchemaRequire
+	}
+	return requirement
+}
+
+// minArticleWords is the least paragraph text a page needs to look like an article
+const minArticleWords = 150
+
+// PageSignals are what a page tells about whether it's a news article
+type PageSignals struct {
+	NewsSchema       bool // Its JSON-LD has a news article type, see SchemaTypeWeights
+	OGArticle        bool // Its og:type is article
+	LooksLikeArticle bool // It has a publication date or <article> element and article-length paragraphs
+}
+
+// DetectSignals reads the signals of a parsed page. Whether its JSON-LD has a
+// news article type is checked by the caller.
+func DetectSignals(doc *html.Node, newsSchema bool) PageSignals {
+	signals := PageSignals{NewsSchema: newsSchema}
+	dated, words := false, 0
+
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inBoilerplate bool) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "meta":
+				property := strings.ToLower(attribute(n, "property"))
+				if property == "og:type" && strings.EqualFold(strings.TrimSpace(attribute(n, "content")), "article") {
+					signals.OGArticle = true
+				}
+				if property == "article:published_time" && attribute(n, "content") != "" {
+					dated = true
+				}
+			case "article":
+				dated = true
+			case "time":
+				if attribute(n, "datetime") != "" {
+					dated = true
+				}
+			case "nav", "header", "footer", "aside", "script", "style":
+				inBoilerplate = true
+			case "p":
+				if !inBoilerplate {
+					words += len(strings.Fields(textOf(n)))
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBoilerplate)
+		}
+	}
+	walk(doc, false)
+
+	signals.LooksLikeArticle = dated && words >= minArticleWords
+	return signals
+}
+
+// Accepts decides whether a page with the given signals is a news article, with
+// the reason for the decision. An empty requirement is SchemaRequire.
+func (r SchemaRequirement) Accepts(signals PageSignals) (bool, string) {
+	if signals.NewsSchema && r != SchemaIgnore {
+		return true, "NewsArticle schema"
+	}
+	if r != SchemaPrefer && r != SchemaIgnore {
+		return false, "no NewsArticle schema"
+	}
+	switch {
+	case signals.OGArticle:
+		return true, "og:type article"
+	case signals.LooksLikeArticle:
+		return true, "looks like an article"
+	}
+	return false, "no NewsArticle schema, og:type article or article content"
+}
+
+// attribute returns the value of an element's attribute, or ""
+func attribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// textOf returns the text inside a node
+func textOf(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var text strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text.WriteString(textOf(c))
+		text.WriteByte(' ')
+	}
+	return text.String()
+}
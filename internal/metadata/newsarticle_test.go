@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+// articlePage returns a page with the given head and paragraphs of words words each
+func articlePage(head, wrapper string, paragraphs, words int) string {
+	var body strings.Builder
+	for i := 0; i < paragraphs; i++ {
+		body.WriteString("<p>" + strings.Repeat("word ", words) + "</p>")
+	}
+	return "<html><head>" + head + "</head><body>" + strings.Replace(wrapper, "%s", body.String(), 1) + "</body></html>"
+}
+
+func TestDetectSignals(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		expected PageSignals
+	}{
+		{
+			name:     "og:type article",
+			page:     articlePage(`<meta property="og:type" content="Article">`, "%s", 1, 10),
+			expected: PageSignals{OGArticle: true},
+		},
+		{
+			name:     "dated article with enough text",
+			page:     articlePage(`<meta property="article:published_time" content="2024-05-01T10:00:00Z">`, "%s", 4, 50),
+			expected: PageSignals{LooksLikeArticle: true},
+		},
+		{
+			name:     "article element",
+			page:     articlePage("", "<article>%s</article>", 4, 50),
+			expected: PageSignals{LooksLikeArticle: true},
+		},
+		{
+			name:     "too little text",
+			page:     articlePage("", "<article>%s</article>", 2, 50),
+			expected: PageSignals{},
+		},
+		{
+			name:     "text only in boilerplate",
+			page:     articlePage("", "<article></article><footer>%s</footer>", 4, 50),
+			expected: PageSignals{},
+		},
+		{
+			name:     "undated page",
+			page:     articlePage(`<meta property="og:type" content="website">`, "<div>%s</div>", 4, 50),
+			expected: PageSignals{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.page))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, DetectSignals(doc, false))
+		})
+	}
+}
+
+func TestSchemaRequirementAccepts(t *testing.T) {
+	schema := PageSignals{NewsSchema: true}
+	og := PageSignals{OGArticle: true}
+	heuristic := PageSignals{LooksLikeArticle: true}
+	none := PageSignals{}
+
+	tests := []struct {
+		requirement SchemaRequirement
+		signals     PageSignals
+		expected    bool
+	}{
+		{SchemaRequire, schema, true},
+		{SchemaRequire, og, false},
+		{SchemaRequire, heuristic, false},
+		{"", schema, true},
+		{"", og, false},
+		{SchemaPrefer, schema, true},
+		{SchemaPrefer, og, true},
+		{SchemaPrefer, heuristic, true},
+		{SchemaPrefer, none, false},
+		{SchemaIgnore, schema, false},
+		{SchemaIgnore, PageSignals{NewsSchema: true, OGArticle: true}, true},
+		{SchemaIgnore, heuristic, true},
+	}
+
+	for _, tt := range tests {
+		accepted, reason := tt.requirement.Accepts(tt.signals)
+		assert.Equal(t, tt.expected, accepted, "%q with %+v", tt.requirement, tt.signals)
+		assert.NotEmpty(t, reason)
+	}
+}
+
+func TestParseSchemaRequirement(t *testing.T) {
+	requirement, ok := ParseSchemaRequirement(" Ignore ")
+	assert.True(t, ok)
+	assert.Equal(t, SchemaIgnore, requirement)
+
+	_, ok = ParseSchemaRequirement("sometimes")
+	assert.False(t, ok)
+
+	t.Setenv("NEWSARTICLE_SCHEMA", "prefer")
+	assert.Equal(t, SchemaPrefer, LoadSchemaRequirement())
+	t.Setenv("NEWSARTICLE_SCHEMA", "bogus")
+	assert.Equal(t, SchemaRequire, LoadSchemaRequirement())
+}
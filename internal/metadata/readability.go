@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ReadingGrade returns the Flesch-Kincaid grade level of text: roughly the
+// years of US schooling needed to follow it. Text without words grades 0.
+func ReadingGrade(text string) float64 {
+	words := 0
+	syllables := 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		words++
+		syllables += countSyllables(word)
+	}
+	if words == 0 {
+		return 0
+	}
+
+	sentences := 0
+	inBoundary := false
+	for _, r := range text {
+		isBoundary := r == '.' || r == '!' || r == '?'
+		if isBoundary && !inBoundary {
+			sentences++
+		}
+		inBoundary = isBoundary
+	}
+	if sentences == 0 {
+		sentences = 1
+	}
+
+	grade := 0.39*float64(words)/float64(sentences) + 11.8*float64(syllables)/float64(words) - 15.59
+	if grade < 0 {
+		grade = 0
+	}
+	return math.Round(grade*10) / 10
+}
+
+// countSyllables estimates the syllables of an English word from its groups of
+// vowels, not counting a silent final e
+func countSyllables(word string) int {
+	word = strings.ToLower(strings.Trim(word, "'"))
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// Sentiment returns a coarse tone of text from -1 (negative) to 1 (positive),
+// from the balance of positive and negative words it uses. News that uses
+// neither scores 0.
+func Sentiment(text string) float64 {
+	positive, negative := 0, 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if positiveWords[word] {
+			positive++
+		} else if negativeWords[word] {
+			negative++
+		}
+	}
+	// The constant keeps a single word from pushing an article to either end
+	score := float64(positive-negative) / float64(positive+negative+4)
+	return math.Round(score*100) / 100
+}
+
+// positiveWords and negativeWords are common words that carry the tone of news
+var positiveWords = wordSet(
+	"good", "great", "best", "better", "win", "wins", "won", "success", "successful",
+	"gain", "gains", "growth", "improve", "improved", "improves", "recovery", "recover",
+	"hope", "hopeful", "celebrate", "celebrates", "celebrated", "breakthrough", "record",
+	"praise", "praised", "support", "boost", "boosts", "rise", "rises", "peace", "safe",
+	"rescue", "rescued", "benefit", "benefits", "happy", "strong", "thrive", "innovative",
+	"award", "awarded", "cure", "progress", "agreement", "approve", "approved", "welcome",
+)
+
+var negativeWords = wordSet(
+	"bad", "worse", "worst", "lose", "loses", "lost", "loss", "losses", "fail", "failed",
+	"failure", "crisis", "death", "deaths", "dead", "die", "died", "kill", "killed", "killing",
+	"war", "attack", "attacks", "crash", "fall", "falls", "fell", "decline", "declines",
+	"fear", "fears", "threat", "threats", "violence", "disaster", "collapse", "scandal",
+	"fraud", "crime", "injured", "victims", "warning", "warns", "conflict", "recession",
+	"layoffs", "protest", "protests", "accused", "shooting", "flood", "fire", "outbreak",
+)
+
+// wordSet builds a set of words
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
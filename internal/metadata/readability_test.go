@@ -0,0 +1,53 @@
+package metadata
+
+import "testing"
+
+func TestReadingGrade(t *testing.T) {
+	simple := "The cat sat on the mat. The dog ran to the park. We had fun."
+	complex := "Macroeconomic considerations notwithstanding, the administration's comprehensive " +
+		"infrastructure legislation necessitates unprecedented intergovernmental coordination."
+
+	if grade := ReadingGrade(simple); grade > 3 {
+		t.Errorf("ReadingGrade(simple) = %v, want at most 3", grade)
+	}
+	if grade := ReadingGrade(complex); grade < 16 {
+		t.Errorf("ReadingGrade(complex) = %v, want at least 16", grade)
+	}
+	if grade := ReadingGrade(""); grade != 0 {
+		t.Errorf("ReadingGrade(\"\") = %v, want 0", grade)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"cat":       1,
+		"table":     2,
+		"make":      1,
+		"reading":   2,
+		"elephant":  3,
+		"the":       1,
+		"rhythm":    1,
+		"education": 4,
+	}
+	for word, want := range tests {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestSentiment(t *testing.T) {
+	positive := Sentiment("Rescuers celebrate a breakthrough as the recovery brings hope and progress to the town.")
+	negative := Sentiment("The attack killed dozens and injured hundreds as the crisis deepened, officials warned of more violence.")
+	neutral := Sentiment("The council met on Tuesday to discuss the budget for next year.")
+
+	if positive < 0.2 {
+		t.Errorf("positive sentiment = %v, want at least 0.2", positive)
+	}
+	if negative > -0.2 {
+		t.Errorf("negative sentiment = %v, want at most -0.2", negative)
+	}
+	if neutral != 0 {
+		t.Errorf("neutral sentiment = %v, want 0", neutral)
+	}
+}
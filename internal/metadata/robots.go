@@ -0,0 +1,36 @@
+package metadata
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// robotsTags are the meta tags robots directives are read from: those for
+// every crawler, and those addressed to ours
+var robotsTags = []string{"robots", "opennewsbot"}
+
+// NoArchive reports whether a page asks for its content not to be kept, with a
+// noarchive or noai directive in its robots meta tags or X-Robots-Tag header.
+// Directives addressed to other crawlers by name are honored too.
+func NoArchive(tags MetaTags, header http.Header) bool {
+	var values []string
+	for _, key := range robotsTags {
+		values = append(values, tags[key])
+	}
+	if header != nil {
+		values = append(values, header.Values("X-Robots-Tag")...)
+	}
+
+	for _, value := range values {
+		directives := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+			return r == ',' || r == ':' || unicode.IsSpace(r)
+		})
+		for _, directive := range directives {
+			if directive == "noarchive" || directive == "noai" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoArchive(t *testing.T) {
+	tests := []struct {
+		name   string
+		tags   MetaTags
+		header http.Header
+		want   bool
+	}{
+		{"no directives", MetaTags{}, nil, false},
+		{"indexing only", MetaTags{"robots": "noindex, nofollow"}, nil, false},
+		{"noarchive", MetaTags{"robots": "index, NOARCHIVE"}, nil, true},
+		{"noai", MetaTags{"robots": "noai, noimageai"}, nil, true},
+		{"addressed to us", MetaTags{"opennewsbot": "noarchive"}, nil, true},
+		{"header", MetaTags{}, http.Header{"X-Robots-Tag": {"googlebot: noarchive"}}, true},
+		{"header without opt-out", MetaTags{}, http.Header{"X-Robots-Tag": {"max-snippet:50"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NoArchive(tt.tags, tt.header))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SchemaTypeWeights are the schema.org types that make a page a news article,
+// weighted by how surely they mark news: reporting scores highest, while blog
+// posts and plain articles are often not news at all. Weights add to articles'
+// content quality.
+var SchemaTypeWeights = map[string]float64{
+	"NewsArticle":          1.0,
+	"ReportageNewsArticle": 1.0,
+	"AnalysisNewsArticle":  0.9,
+	"LiveBlogPosting":      0.8,
+	"BlogPosting":          0.5,
+	"Article":              0.4,
+}
+
+// SchemaTypeWeight returns the weight of a schema.org type, or 0 for types that
+// aren't articles. Types may be given as URLs, e.g. https://schema.org/NewsArticle.
+func SchemaTypeWeight(typeName string) float64 {
+	typeName = strings.TrimSpace(typeName)
+	if slash := strings.LastIndexAny(typeName, "/:"); slash >= 0 {
+		typeName = typeName[slash+1:]
+	}
+	return SchemaTypeWeights[typeName]
+}
+
+// ArticleSchemaType finds the highest weighted article type in parsed JSON-LD,
+// looking through arrays of objects and @graph lists. It returns "" and 0 when
+// there's none.
+func ArticleSchemaType(jsonLD interface{}) (string, float64) {
+	_, schemaType, weight := findArticle(jsonLD)
+	return schemaType, weight
+}
+
+// ArticleObject finds the object with the highest weighted article type in
+// parsed JSON-LD, the one to read an article's headline, author and dates from.
+// It returns nil when there's none.
+func ArticleObject(jsonLD interface{}) map[string]interface{} {
+	object, _, _ := findArticle(jsonLD)
+	return object
+}
+
+// findArticle returns the object in parsed JSON-LD with the highest weighted
+// article type, the type and its weight. The first of equally weighted objects
+// wins.
+func findArticle(jsonLD interface{}) (map[string]interface{}, string, float64) {
+	var bestObject map[string]interface{}
+	bestType, bestWeight := "", 0.0
+	consider := func(object map[string]interface{}, typeName string, weight float64) {
+		if weight > bestWeight {
+			bestObject, bestType, bestWeight = object, typeName, weight
+		}
+	}
+
+	switch value := jsonLD.(type) {
+	case []interface{}:
+		for _, item := range value {
+			consider(findArticle(item))
+		}
+	case map[string]interface{}:
+		// @type can be a string or array of strings
+		switch t := value["@type"].(type) {
+		case string:
+			consider(value, t, SchemaTypeWeight(t))
+		case []interface{}:
+			for _, typeName := range t {
+				if typeStr, ok := typeName.(string); ok {
+					consider(value, typeStr, SchemaTypeWeight(typeStr))
+				}
+			}
+		}
+		if graph, ok := value["@graph"].([]interface{}); ok {
+			consider(findArticle(graph))
+		}
+	}
+	return bestObject, bestType, bestWeight
+}
+
+// SchemaType parses JSON-LD data and returns its highest weighted article type
+// and the type's weight, or "" and 0 when it has none or doesn't parse
+func SchemaType(jsonldData string) (string, float64) {
+	if jsonldData == "" {
+		return "", 0
+	}
+	var jsonLD interface{}
+	if err := json.Unmarshal([]byte(jsonldData), &jsonLD); err != nil {
+		return "", 0
+	}
+	return ArticleSchemaType(jsonLD)
+}
+
+// JSONLDScripts returns the contents of every application/ld+json script on a
+// page, in page order. Sites often split their structured data across several,
+// e.g. one for the site's Organization and another for the article.
+func JSONLDScripts(doc *html.Node) []string {
+	var scripts []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "script" {
+			if strings.EqualFold(strings.TrimSpace(attribute(n, "type")), "application/ld+json") {
+				if text := strings.TrimSpace(textOf(n)); text != "" {
+					scripts = append(scripts, text)
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return scripts
+}
+
+// PageSchemaType returns the highest weighted article type in any of a page's
+// JSON-LD scripts and its weight, or "" and 0 when there's none
+func PageSchemaType(doc *html.Node) (string, float64) {
+	bestType, bestWeight := "", 0.0
+	for _, script := range JSONLDScripts(doc) {
+		if schemaType, weight := SchemaType(script); weight > bestWeight {
+			bestType, bestWeight = schemaType, weight
+		}
+	}
+	return bestType, bestWeight
+}
+
+// MergeJSONLD combines a page's JSON-LD scripts into one JSON-LD document to
+// store: the script itself when there's one, otherwise an array of their
+// contents. Scripts that don't parse are left out.
+func MergeJSONLD(scripts []string) string {
+	var valid []string
+	for _, script := range scripts {
+		if json.Valid([]byte(script)) {
+			valid = append(valid, script)
+		}
+	}
+	switch len(valid) {
+	case 0:
+		return ""
+	case 1:
+		return valid[0]
+	}
+	return "[" + strings.Join(valid, ",") + "]"
+}
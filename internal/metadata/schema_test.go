@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestSchemaType(t *testing.T) {
+	tests := []struct {
+		name         string
+		jsonld       string
+		expectedType string
+	}{
+		{"news article", `{"@type": "NewsArticle"}`, "NewsArticle"},
+		{"reportage", `{"@type": "ReportageNewsArticle"}`, "ReportageNewsArticle"},
+		{"live blog", `{"@type": "LiveBlogPosting"}`, "LiveBlogPosting"},
+		{"blog post", `{"@type": "BlogPosting"}`, "BlogPosting"},
+		{"type list", `{"@type": ["WebPage", "AnalysisNewsArticle"]}`, "AnalysisNewsArticle"},
+		{"type URL", `{"@type": "https://schema.org/NewsArticle"}`, "https://schema.org/NewsArticle"},
+		{"highest weight wins", `[{"@type": "Article"}, {"@type": "BlogPosting"}]`, "BlogPosting"},
+		{"graph", `{"@context": "https://schema.org", "@graph": [{"@type": "WebSite"}, {"@type": "NewsArticle"}]}`, "NewsArticle"},
+		{"not an article", `{"@type": "Recipe"}`, ""},
+		{"invalid JSON", `{"@type": "NewsArticle"`, ""},
+		{"empty", ``, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaType, weight := SchemaType(tt.jsonld)
+			assert.Equal(t, tt.expectedType, schemaType)
+			assert.Equal(t, tt.expectedType != "", weight > 0)
+		})
+	}
+}
+
+func TestSchemaTypeWeight(t *testing.T) {
+	assert.Greater(t, SchemaTypeWeight("NewsArticle"), SchemaTypeWeight("LiveBlogPosting"))
+	assert.Greater(t, SchemaTypeWeight("LiveBlogPosting"), SchemaTypeWeight("BlogPosting"))
+	assert.Greater(t, SchemaTypeWeight("BlogPosting"), SchemaTypeWeight("Article"))
+	assert.Equal(t, SchemaTypeWeight("NewsArticle"), SchemaTypeWeight("schema:NewsArticle"))
+	assert.Zero(t, SchemaTypeWeight("WebPage"))
+}
+
+func TestPageSchemaType(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+		<script type="application/ld+json">{"@type": "Organization"}</script>
+		<script type="text/javascript">var schema = {"@type": "NewsArticle"};</script>
+		<script type="application/ld+json"> </script>
+		</head><body>
+		<script type="Application/LD+JSON">{"@graph": [{"@type": "BlogPosting"}]}</script>
+		</body></html>`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`{"@type": "Organization"}`, `{"@graph": [{"@type": "BlogPosting"}]}`}, JSONLDScripts(doc))
+	schemaType, weight := PageSchemaType(doc)
+	assert.Equal(t, "BlogPosting", schemaType)
+	assert.Equal(t, SchemaTypeWeights["BlogPosting"], weight)
+}
+
+func TestArticleObject(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"@type": "WebSite", "name": "Site"},
+		{"@graph": [{"@type": "Article", "headline": "Teaser"}, {"@type": "NewsArticle", "headline": "Story"}]}
+	]`), &data))
+	assert.Equal(t, "Story", ArticleObject(data)["headline"])
+
+	require.NoError(t, json.Unmarshal([]byte(`{"@type": "WebSite"}`), &data))
+	assert.Nil(t, ArticleObject(data))
+}
+
+func TestMergeJSONLD(t *testing.T) {
+	assert.Equal(t, "", MergeJSONLD(nil))
+	assert.Equal(t, `{"@type": "NewsArticle"}`, MergeJSONLD([]string{`{"@type": "NewsArticle"}`, `{broken`}))
+	merged := MergeJSONLD([]string{`{"@type": "WebSite"}`, `{"@type": "NewsArticle"}`})
+	assert.Equal(t, `[{"@type": "WebSite"},{"@type": "NewsArticle"}]`, merged)
+	schemaType, _ := SchemaType(merged)
+	assert.Equal(t, "NewsArticle", schemaType)
+}
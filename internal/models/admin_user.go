@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Admin roles, from least to most privileged
+const (
+	AdminRoleViewer    = "viewer"    // Read-only access to the admin pages
+	AdminRoleModerator = "moderator" // Can also change articles, sources, follows and jobs
+	AdminRoleAdmin     = "admin"     // Can also manage admin accounts and API keys
+)
+
+// adminRoleRanks orders the roles so a role includes the permissions of those below it
+var adminRoleRanks = map[string]int{
+	AdminRoleViewer:    1,
+	AdminRoleModerator: 2,
+	AdminRoleAdmin:     3,
+}
+
+// IsValidAdminRole reports whether role is one of the admin roles
+func IsValidAdminRole(role string) bool {
+	_, ok := adminRoleRanks[role]
+	return ok
+}
+
+// AdminUser is an operator account for the admin interface
+type AdminUser struct {
+	ID           uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TenantID     *uuid.UUID `json:"tenant_id" db:"tenant_id" gorm:"type:uuid;uniqueIndex:idx_admin_users_tenant_username"` // Tenant the account administers
+	Username     string     `json:"username" db:"username" gorm:"uniqueIndex:idx_admin_users_tenant_username;not null"`    // Unique per tenant
+	PasswordHash string     `json:"-" db:"password_hash" gorm:"not null"`                                                  // bcrypt hash
+	Role         string     `json:"role" db:"role" gorm:"not null;default:'viewer'"`
+	IsActive     bool       `json:"is_active" db:"is_active" gorm:"default:true"`
+	LastLoginAt  *time.Time `json:"last_login_at" db:"last_login_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the AdminUser model
+func (AdminUser) TableName() string {
+	return "admin_users"
+}
+
+// HasRole reports whether the account's role includes the permissions of role
+func (u *AdminUser) HasRole(role string) bool {
+	return u.IsActive && adminRoleRanks[u.Role] >= adminRoleRanks[role] && adminRoleRanks[role] > 0
+}
+
+// AdminSession is a signed-in admin browser session. Only a hash of the session
+// token is stored; the token itself lives in the browser's cookie.
+type AdminSession struct {
+	ID          uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	AdminUserID uuid.UUID `json:"admin_user_id" db:"admin_user_id" gorm:"type:uuid;not null;index"`
+	TokenHash   string    `json:"-" db:"token_hash" gorm:"uniqueIndex;not null"` // SHA-256 of the session token
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	AdminUser AdminUser `json:"admin_user,omitempty" gorm:"foreignKey:AdminUserID;references:ID"`
+}
+
+// TableName sets the table name for the AdminSession model
+func (AdminSession) TableName() string {
+	return "admin_sessions"
+}
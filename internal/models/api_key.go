@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey identifies a third-party embedder of the widget API.
+// Only a hash of the key is stored; the plaintext is shown once at creation.
+type APIKey struct {
+	ID             uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name           string     `json:"name" db:"name" gorm:"not null"`
+	KeyHash        string     `json:"-" db:"key_hash" gorm:"uniqueIndex;not null"` // SHA-256 of the key
+	Prefix         string     `json:"prefix" db:"prefix" gorm:"not null"`          // First characters of the key, for display
+	AllowedOrigins string     `json:"allowed_origins" db:"allowed_origins"`        // Comma-separated origins; empty allows any
+	IsActive       bool       `json:"is_active" db:"is_active" gorm:"default:true"`
+	RequestCount   int64      `json:"request_count" db:"request_count" gorm:"default:0"`
+	LastUsedAt     *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// AllowsOrigin reports whether the key may be used from the given Origin header
+func (k *APIKey) AllowsOrigin(origin string) bool {
+	if strings.TrimSpace(k.AllowedOrigins) == "" || origin == "" {
+		return true
+	}
+	for _, allowed := range strings.Split(k.AllowedOrigins, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), origin) {
+			return true
+		}
+	}
+	return false
+}
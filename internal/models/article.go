@@ -13,23 +13,29 @@ type Article struct {
 	URL         string         `json:"url" db:"url" gorm:"uniqueIndex;not null"` // Canonical URL
 	Title       string         `json:"title" db:"title"`
 	Description string         `json:"description" db:"description"`
-	Author      string         `json:"author" db:"author"`
+	Author      string         `json:"author" db:"author"` // Byline naming every author
+	Authors     pq.StringArray `json:"authors" db:"authors" gorm:"type:text[]"` // Author names, in byline order
 	SiteName    string         `json:"site_name" db:"site_name"`
 	ImageURL    string         `json:"image_url" db:"image_url"`
 	PublishedAt *time.Time     `json:"published_at" db:"published_at"`
+	DateConfidence string      `json:"date_confidence,omitempty" db:"date_confidence"` // Where the publication date was found: "high" (JSON-LD), "medium" (meta tags) or "low" (<time> elements, Last-Modified)
 	
 	// JSON-LD and Open Graph metadata
 	JSONLDData  string `json:"jsonld_data" db:"jsonld_data" gorm:"type:text"`  // Raw JSON-LD data
 	OGData      string `json:"og_data" db:"og_data" gorm:"type:text"`       // Open Graph metadata as JSON
+	SchemaType  string `json:"schema_type,omitempty" db:"schema_type"`      // Highest weighted schema.org article type in the JSON-LD, e.g. "NewsArticle"
 	
 	// Cached HTML content
 	HTMLContent string `json:"html_content" db:"html_content" gorm:"type:text"` // Full HTML cache
 	TextContent string `json:"text_content" db:"text_content" gorm:"type:text"` // Extracted text content
+	Summary     string `json:"summary" db:"summary" gorm:"type:text"`           // Two or three sentences shown in feeds in place of the description
 	
 	// Article metadata
 	WordCount    int            `json:"word_count" db:"word_count" gorm:"default:0"`
 	ReadingTime  int            `json:"reading_time" db:"reading_time" gorm:"default:0"` // in minutes
 	Language     string         `json:"language" db:"language"`
+	ReadingGrade float64        `json:"reading_grade" db:"reading_grade" gorm:"default:0"` // Flesch-Kincaid grade level of the text
+	Sentiment    float64        `json:"sentiment" db:"sentiment" gorm:"default:0"`         // Tone of the text, from -1 (negative) to 1 (positive)
 	Tags         pq.StringArray `json:"tags" db:"tags" gorm:"type:text[]"`
 	
 	// Engagement metrics
@@ -38,21 +44,37 @@ type Article struct {
 	RepostsCount int `json:"reposts_count" db:"reposts_count" gorm:"default:0"`
 	
 	// Quality and ranking metrics
-	QualityScore float64 `json:"quality_score" db:"quality_score" gorm:"default:0.0"`
+	QualityScore float64 `json:"quality_score" db:"quality_score" gorm:"default:0.0;index:idx_articles_created_quality,priority:2"`
 	TrendingScore float64 `json:"trending_score" db:"trending_score" gorm:"default:0.0"`
+	ScoreBreakdownData string `json:"-" db:"score_breakdown" gorm:"column:score_breakdown;type:text"` // ScoreBreakdown as JSON, written at scoring time
+	NeedsRescore bool `json:"-" db:"needs_rescore" gorm:"default:false"` // Shares or engagement changed since the article was last scored
 	
 	// Cache status
 	IsCached     bool      `json:"is_cached" db:"is_cached" gorm:"default:false"`
+	NoArchive    bool      `json:"no_archive" db:"no_archive" gorm:"default:false"` // The page asked with noarchive or noai for its content not to be kept
 	CachedAt     *time.Time `json:"cached_at" db:"cached_at"`
 	LastFetchAt  *time.Time `json:"last_fetch_at" db:"last_fetch_at"`
 	
 	// Fetch status tracking
 	IsReachable    bool   `json:"is_reachable" db:"is_reachable" gorm:"default:false"`
+	IsUnshared     bool   `json:"is_unshared" db:"is_unshared" gorm:"default:false"` // Ingested from its site's feeds and not shared on Bluesky yet; kept out of feeds until it is
 	FetchError     string `json:"fetch_error" db:"fetch_error"`              // Last error message
 	FetchRetries   int    `json:"fetch_retries" db:"fetch_retries" gorm:"default:0"` // Number of failed attempts
 	LastFetchError *time.Time `json:"last_fetch_error" db:"last_fetch_error"` // When the last error occurred
+	FactsExtractedAt *time.Time `json:"facts_extracted_at" db:"facts_extracted_at" gorm:"index"` // When facts were last extracted; cleared when the text changes
+	SummarizedAt     *time.Time `json:"summarized_at" db:"summarized_at" gorm:"index"`           // When the summary was last written; cleared when the text changes
+
+	// Moderation
+	IsNotNews bool `json:"is_not_news" db:"is_not_news" gorm:"default:false"` // Marked by an admin; kept out of feeds and not re-ingested
+	IsPinned       bool       `json:"is_pinned" db:"is_pinned" gorm:"default:false"`            // Leads the global feed until unpinned
+	PinnedAt       *time.Time `json:"pinned_at" db:"pinned_at"`
+	EditorialBoost float64    `json:"editorial_boost" db:"editorial_boost" gorm:"default:0.0"` // Added to the quality score; negative for a penalty
+
+	// Burst detection
+	BreakingAt      *time.Time `json:"breaking_at,omitempty" db:"breaking_at" gorm:"index"` // When many sources started sharing it at once; boosted for a few hours after
+	BreakingSources int        `json:"breaking_sources,omitempty" db:"breaking_sources" gorm:"default:0"` // Distinct sources that shared it within the detection window
 	
-	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime;index:idx_articles_created_quality,priority:1"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
 
 	// Relationships
@@ -60,6 +82,14 @@ type Article struct {
 	Facts          []ArticleFact   `json:"facts,omitempty" gorm:"foreignKey:ArticleID"`
 }
 
+// Blurb returns the article's summary, or its description when it hasn't been summarized
+func (a Article) Blurb() string {
+	if a.Summary != "" {
+		return a.Summary
+	}
+	return a.Description
+}
+
 // TableName sets the table name for the Article model
 func (Article) TableName() string {
 	return "articles"
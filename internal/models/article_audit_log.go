@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Editorial actions recorded in the article audit log
+const (
+	AuditActionPin   = "pin"   // Pinned to the top of the global feed
+	AuditActionUnpin = "unpin" // Pin removed
+	AuditActionBoost = "boost" // Editorial quality boost or penalty changed
+)
+
+// ArticleAuditLog records an editorial change an admin made to an article
+type ArticleAuditLog struct {
+	ID        uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ArticleID uuid.UUID `json:"article_id" db:"article_id" gorm:"type:uuid;not null;index"`
+	Actor     string    `json:"actor" db:"actor" gorm:"not null"`   // Admin account that made the change
+	Action    string    `json:"action" db:"action" gorm:"not null"` // One of the AuditAction constants
+	OldValue  string    `json:"old_value" db:"old_value"`
+	NewValue  string    `json:"new_value" db:"new_value"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName sets the table name for the ArticleAuditLog model
+func (ArticleAuditLog) TableName() string {
+	return "article_audit_logs"
+}
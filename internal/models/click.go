@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Click records a reader following a feed item through the /r/:article_id redirect
+type Click struct {
+	ID        uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ArticleID uuid.UUID  `json:"article_id" db:"article_id" gorm:"type:uuid;not null;index"`
+	SourceID  *uuid.UUID `json:"source_id" db:"source_id" gorm:"type:uuid;index"` // Source the item was attributed to, if known
+	UserID    *uuid.UUID `json:"user_id" db:"user_id" gorm:"type:uuid;index"`     // Reader of the personal feed clicked, if any; clicks teach their topic preferences
+	Feed      string     `json:"feed" db:"feed" gorm:"index"`                     // Feed the item was shown in, e.g. "global" or a feed rkey
+	Surface   string     `json:"surface" db:"surface"`                            // Where the feed was rendered: "web", "widget" or "api"
+	Position  int        `json:"position" db:"position"`                          // Position of the item in the feed, 0 if unknown
+	Referrer  string     `json:"referrer" db:"referrer"`                          // Referer header of the click
+	UserAgent string     `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName sets the table name for the Click model
+func (Click) TableName() string {
+	return "clicks"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Email digest frequencies
+const (
+	EmailFrequencyDaily  = "daily"
+	EmailFrequencyWeekly = "weekly"
+)
+
+// EmailSubscription is an address that receives the top-stories digest by email.
+// Digests are only sent once the address is confirmed from the confirmation email.
+type EmailSubscription struct {
+	ID          uuid.UUID      `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Email       string         `json:"email" db:"email" gorm:"uniqueIndex;not null"` // Lowercased address
+	Frequency   string         `json:"frequency" db:"frequency" gorm:"not null;default:'daily'"`
+	Topics      pq.StringArray `json:"topics" db:"topics" gorm:"type:text[]"`    // Topic slugs; empty means top stories from every topic
+	Token       string         `json:"-" db:"token" gorm:"uniqueIndex;not null"` // Secret for the confirm and unsubscribe links
+	ConfirmedAt *time.Time     `json:"confirmed_at" db:"confirmed_at"`
+	LastSentAt  *time.Time     `json:"last_sent_at" db:"last_sent_at"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the EmailSubscription model
+func (EmailSubscription) TableName() string {
+	return "email_subscriptions"
+}
+
+// IsValidEmailFrequency reports whether frequency is one of the digest frequencies
+func IsValidEmailFrequency(frequency string) bool {
+	return frequency == EmailFrequencyDaily || frequency == EmailFrequencyWeekly
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Vector is a pgvector vector, written and read in its text form "[1,2,3]"
+type Vector []float32
+
+// Value implements driver.Valuer
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (v *Vector) Scan(src interface{}) error {
+	var text string
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		text = s
+	case []byte:
+		text = string(s)
+	default:
+		return fmt.Errorf("cannot scan %T into Vector", src)
+	}
+
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return fmt.Errorf("invalid vector %q", text)
+	}
+	text = strings.TrimSpace(text[1 : len(text)-1])
+	if text == "" {
+		*v = Vector{}
+		return nil
+	}
+	parts := strings.Split(text, ",")
+	vector := make(Vector, len(parts))
+	for i, part := range parts {
+		x, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return fmt.Errorf("invalid vector element %q: %w", part, err)
+		}
+		vector[i] = float32(x)
+	}
+	*v = vector
+	return nil
+}
+
+// ArticleEmbedding is the vector of an article's title and description. It's
+// only migrated when the database has the pgvector extension.
+type ArticleEmbedding struct {
+	ArticleID   uuid.UUID `json:"article_id" db:"article_id" gorm:"primaryKey;type:uuid"`
+	Model       string    `json:"model" db:"model" gorm:"not null;index"`         // Provider that computed it, e.g. "hash-256"
+	ContentHash string    `json:"content_hash" db:"content_hash" gorm:"not null"` // md5 of the embedded text, to notice edits
+	Embedding   Vector    `json:"-" db:"embedding" gorm:"type:vector;not null"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Article Article `json:"-" gorm:"foreignKey:ArticleID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName sets the table name for the ArticleEmbedding model
+func (ArticleEmbedding) TableName() string {
+	return "article_embeddings"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// engagementRollupSQL recalculates articles' engagement from their shares: the
+// distinct sources that shared them and the likes and reposts those posts got
+const engagementRollupSQL = `UPDATE articles SET
+	shares_count = (SELECT COUNT(DISTINCT source_articles.source_id) FROM source_articles WHERE source_articles.article_id = articles.id),
+	likes_count = (SELECT COALESCE(SUM(source_articles.likes_count), 0) FROM source_articles WHERE source_articles.article_id = articles.id),
+	reposts_count = (SELECT COALESCE(SUM(source_articles.reposts_count), 0) FROM source_articles WHERE source_articles.article_id = articles.id)`
+
+// AggregateEngagement rolls the engagement of articles' shares up into
+// SharesCount, LikesCount and RepostsCount, after shares were added or removed
+func AggregateEngagement(db *gorm.DB, articleIDs ...uuid.UUID) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	return db.Exec(engagementRollupSQL+` WHERE articles.id IN ?`, articleIDs).Error
+}
+
+// AggregateAllEngagement rolls up the engagement of every article, catching
+// changes made without AggregateEngagement
+func AggregateAllEngagement(db *gorm.DB) error {
+	return db.Exec(engagementRollupSQL).Error
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity is a person, organization or place named in article facts
+type Entity struct {
+	ID           uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name         string    `json:"name" db:"name" gorm:"not null"`                                    // As first written, e.g. "Christine Lagarde"
+	Slug         string    `json:"slug" db:"slug" gorm:"not null;uniqueIndex:idx_entities_slug_type"` // Lowercased name with hyphens, e.g. "christine-lagarde"
+	Type         string    `json:"type" db:"type" gorm:"not null;uniqueIndex:idx_entities_slug_type"` // person, organization or place
+	ArticleCount int       `json:"article_count" db:"article_count" gorm:"default:0;index"`           // Articles naming the entity
+	FirstSeenAt  time.Time `json:"first_seen_at" db:"first_seen_at"`                                  // Earliest article naming it
+	LastSeenAt   time.Time `json:"last_seen_at" db:"last_seen_at"`                                    // Latest article naming it
+	CreatedAt    time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the Entity model
+func (Entity) TableName() string {
+	return "entities"
+}
+
+// ArticleEntity links an article to an entity its facts name
+type ArticleEntity struct {
+	ArticleID  uuid.UUID `json:"article_id" db:"article_id" gorm:"primaryKey;type:uuid"`
+	EntityID   uuid.UUID `json:"entity_id" db:"entity_id" gorm:"primaryKey;type:uuid;index"`
+	Confidence float64   `json:"confidence" db:"confidence" gorm:"default:0.0"` // Highest confidence of the facts naming it
+	CreatedAt  time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Article Article `json:"article,omitempty" gorm:"foreignKey:ArticleID;references:ID"`
+	Entity  Entity  `json:"entity,omitempty" gorm:"foreignKey:EntityID;references:ID"`
+}
+
+// TableName sets the table name for the ArticleEntity model
+func (ArticleEntity) TableName() string {
+	return "article_entities"
+}
@@ -18,7 +18,21 @@ type Feed struct {
 	MaxItems      int     `json:"max_items" db:"max_items" gorm:"default:50"`
 	RefreshRate   int     `json:"refresh_rate" db:"refresh_rate" gorm:"default:300"` // seconds
 	QualityThreshold float64 `json:"quality_threshold" db:"quality_threshold" gorm:"default:0.0"`
-	
+
+	// Diversity limits per page of items; 0 is unlimited
+	MaxPerSource  int `json:"max_per_source" db:"max_per_source" gorm:"default:0"`   // Items attributed to one source
+	MaxPerDomain  int `json:"max_per_domain" db:"max_per_domain" gorm:"default:0"`   // Items from one site
+	MaxPerCluster int `json:"max_per_cluster" db:"max_per_cluster" gorm:"default:0"` // Items covering one story
+
+	// Freshness of the articles a precomputed feed is regenerated with
+	MaxAgeHours     int  `json:"max_age_hours" db:"max_age_hours" gorm:"default:0"`           // Articles created longer ago are left out; 0 is 7 days
+	PruneSuperseded bool `json:"prune_superseded" db:"prune_superseded" gorm:"default:true"` // Leave out articles a newer article on the same story replaced
+
+	// Regeneration status, for the feed health report
+	RegeneratedAt *time.Time `json:"regenerated_at" db:"regenerated_at"`         // Last successful regeneration
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`       // Why the last failed regeneration failed
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty" db:"last_error_at"` // When it failed
+
 	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
 
@@ -29,12 +43,12 @@ type Feed struct {
 // FeedItem represents an article in a feed with its ranking
 type FeedItem struct {
 	ID           uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	FeedID       uuid.UUID `json:"feed_id" db:"feed_id" gorm:"not null;index"`
+	FeedID       uuid.UUID `json:"feed_id" db:"feed_id" gorm:"not null;index;index:idx_feed_items_feed_position,priority:1"`
 	ArticleID    uuid.UUID `json:"article_id" db:"article_id" gorm:"not null;index"`
 	UserID       *uuid.UUID `json:"user_id" db:"user_id" gorm:"index"` // NULL for global feed
 	
 	// Ranking and scoring
-	Position     int     `json:"position" db:"position" gorm:"not null"`
+	Position     int     `json:"position" db:"position" gorm:"not null;index:idx_feed_items_feed_position,priority:2"`
 	Score        float64 `json:"score" db:"score" gorm:"default:0.0"`
 	Relevance    float64 `json:"relevance" db:"relevance" gorm:"default:0.0"` // For personalized feeds
 	
@@ -62,9 +76,16 @@ type UserFeedPreference struct {
 	EngagementWeight float64 `json:"engagement_weight" db:"engagement_weight" gorm:"default:0.3"`
 	
 	// Content preferences
-	PreferredTopics   []string `json:"preferred_topics" db:"preferred_topics" gorm:"type:text[]"`
+	PreferredTopics   []string `json:"preferred_topics" db:"preferred_topics" gorm:"type:text[]"`       // Topics the user follows
+	PreferredLanguages []string `json:"preferred_languages" db:"preferred_languages" gorm:"type:text[]"` // Language codes the user reads, e.g. "en"; empty for any
 	BlockedSources    []uuid.UUID `json:"blocked_sources" db:"blocked_sources" gorm:"type:uuid[]"`
 	PreferredSources  []uuid.UUID `json:"preferred_sources" db:"preferred_sources" gorm:"type:uuid[]"`
+
+	// Opt out of pushing articles already served in personal feeds behind fresh ones
+	ShowSeenArticles bool `json:"show_seen_articles" db:"show_seen_articles" gorm:"default:false"`
+
+	// Receive the top-stories digest as a direct message from the bot account
+	DigestSubscribed bool `json:"digest_subscribed" db:"digest_subscribed" gorm:"default:false"`
 	
 	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
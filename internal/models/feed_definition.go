@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// FeedDefinition maps a Bluesky feed generator record key to the builder that serves it
+type FeedDefinition struct {
+	ID          uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TenantID    *uuid.UUID `json:"tenant_id" db:"tenant_id" gorm:"type:uuid;uniqueIndex:idx_feed_definitions_tenant_rkey"`  // Tenant serving the feed
+	RKey        string     `json:"rkey" db:"rkey" gorm:"column:rkey;uniqueIndex:idx_feed_definitions_tenant_rkey;not null"` // Record key, e.g. "open-news-global"; unique per tenant
+	DisplayName string     `json:"display_name" db:"display_name" gorm:"not null"`
+	Description string     `json:"description" db:"description"`
+	Avatar      string     `json:"avatar" db:"avatar"`
+	Builder     string     `json:"builder" db:"builder" gorm:"not null"` // "global" or "personalized"
+
+	// Feeds users define for themselves; operator feeds have no owner
+	OwnerID      *uuid.UUID `json:"owner_id,omitempty" db:"owner_id" gorm:"type:uuid;index"`                // User who defined the feed
+	GeneratorDID string     `json:"generator_did,omitempty" db:"generator_did" gorm:"column:generator_did"` // DID the owner publishes the feed record with; empty for a web-only feed
+
+	// Builder parameters
+	Topic           string         `json:"topic" db:"topic"`                                          // Only include articles tagged with this topic
+	Language        string         `json:"language" db:"language"`                                    // Only include articles in this language (e.g. "en")
+	DomainCategory  string         `json:"domain_category" db:"domain_category"`                      // Only include articles from domains in this category (e.g. "wire")
+	Country         string         `json:"country" db:"country"`                                      // Only include articles from domains in this country (e.g. "GB")
+	Topics          pq.StringArray `json:"topics" db:"topics" gorm:"type:text[]"`                     // Only include articles tagged with any of these topics
+	Domains         pq.StringArray `json:"domains" db:"domains" gorm:"type:text[]"`                   // Only include articles from these sites or their subdomains
+	MinShares       int            `json:"min_shares" db:"min_shares" gorm:"default:0"`               // Only include articles shared at least this many times
+	TimeWindowHours int            `json:"time_window_hours" db:"time_window_hours" gorm:"default:0"` // 0 uses the builder default
+	MinQualityScore float64        `json:"min_quality_score" db:"min_quality_score" gorm:"default:0.0"`
+	Ranker          string         `json:"ranker" db:"ranker"`       // Ranking strategy, e.g. "editorial"; empty uses the stored scores
+	SafeMode        bool           `json:"safe_mode" db:"safe_mode"` // Leave out articles shared in posts with a sensitive label
+
+	// What a personalized feed serves requests without a valid token: "global",
+	// "trending", or empty to refuse them
+	AnonymousFallback string `json:"anonymous_fallback,omitempty" db:"anonymous_fallback"`
+
+	// Diversity limits per page of items; 0 is unlimited
+	MaxPerSource  int `json:"max_per_source" db:"max_per_source" gorm:"default:0"`   // Items attributed to one source
+	MaxPerDomain  int `json:"max_per_domain" db:"max_per_domain" gorm:"default:0"`   // Items from one site
+	MaxPerCluster int `json:"max_per_cluster" db:"max_per_cluster" gorm:"default:0"` // Items covering one story
+
+	IsActive  bool      `json:"is_active" db:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the FeedDefinition model
+func (FeedDefinition) TableName() string {
+	return "feed_definitions"
+}
+
+// HasDiversity reports whether the definition limits items per source, site or story
+func (fd *FeedDefinition) HasDiversity() bool {
+	return fd.MaxPerSource > 0 || fd.MaxPerDomain > 0 || fd.MaxPerCluster > 0
+}
+
+// HasFilters reports whether the definition narrows its builder's default article set
+func (fd *FeedDefinition) HasFilters() bool {
+	return fd.Topic != "" || fd.Language != "" || fd.DomainCategory != "" || fd.Country != "" || fd.TimeWindowHours > 0 || fd.MinQualityScore > 0 || fd.SafeMode ||
+		len(fd.Topics) > 0 || len(fd.Domains) > 0 || fd.MinShares > 0
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impression records a feed item that was served to a reader.
+// Anonymous requests are sampled; SampleRate is the probability the serve was recorded,
+// so 1/SampleRate estimates how many serves the row stands for.
+type Impression struct {
+	ID         uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ArticleID  uuid.UUID  `json:"article_id" db:"article_id" gorm:"type:uuid;not null;index"`
+	SourceID   *uuid.UUID `json:"source_id" db:"source_id" gorm:"type:uuid;index"` // Source the item was attributed to, if known
+	UserID     *uuid.UUID `json:"user_id" db:"user_id" gorm:"type:uuid;index"`     // Requesting user, nil for anonymous requests
+	Feed       string     `json:"feed" db:"feed" gorm:"index"`                     // Feed the item was served in, e.g. "global" or a feed rkey
+	Surface    string     `json:"surface" db:"surface"`                            // "skeleton", "web", "widget" or "api"
+	Position   int        `json:"position" db:"position"`                          // Position of the item in the feed
+	SampleRate float64    `json:"sample_rate" db:"sample_rate" gorm:"default:1.0"` // Probability this serve was recorded
+	CreatedAt  time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName sets the table name for the Impression model
+func (Impression) TableName() string {
+	return "impressions"
+}
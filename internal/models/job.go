@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"   // Waiting for NextRunAt, including retries after a failure
+	JobStatusRunning   = "running"   // Claimed by a worker
+	JobStatusSucceeded = "succeeded" // Finished without error
+	JobStatusFailed    = "failed"    // Out of attempts; stays until retried from the admin
+)
+
+// Job records a run of a background task, so failures are visible and can be retried
+type Job struct {
+	ID          uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Type        string     `json:"type" db:"type" gorm:"not null;index"`                         // Handler name, e.g. "refresh_follows"
+	Payload     string     `json:"payload" db:"payload" gorm:"type:jsonb;not null;default:'{}'"` // JSON arguments passed to the handler
+	Status      string     `json:"status" db:"status" gorm:"not null;default:'pending';index:idx_jobs_status_next_run_at"`
+	Attempts    int        `json:"attempts" db:"attempts" gorm:"default:0"`
+	MaxAttempts int        `json:"max_attempts" db:"max_attempts" gorm:"default:3"`
+	NextRunAt   time.Time  `json:"next_run_at" db:"next_run_at" gorm:"not null;index:idx_jobs_status_next_run_at"`
+	LastError   string     `json:"last_error" db:"last_error"`
+	StartedAt   *time.Time `json:"started_at" db:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at" db:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the Job model
+func (Job) TableName() string {
+	return "jobs"
+}
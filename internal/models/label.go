@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Label is a label the labeler issued, signed as it's served. Seq orders the
+// labels for subscribeLabels cursors.
+type Label struct {
+	Seq       int64     `json:"seq" db:"seq" gorm:"primaryKey;autoIncrement"`
+	URI       string    `json:"uri" db:"uri" gorm:"not null;index:idx_labels_uri_val"` // Post AT URI or account DID
+	CID       string    `json:"cid,omitempty" db:"cid"`
+	Val       string    `json:"val" db:"val" gorm:"not null;index:idx_labels_uri_val"`
+	Neg       bool      `json:"neg" db:"neg" gorm:"default:false"` // Retracts the earlier label
+	Cts       string    `json:"cts" db:"cts" gorm:"not null"`      // Creation time exactly as signed
+	Exp       string    `json:"exp,omitempty" db:"exp"`            // Expiry exactly as signed; empty if none
+	Src       string    `json:"src" db:"src" gorm:"not null"`
+	Sig       []byte    `json:"sig" db:"sig" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName sets the table name for the Label model
+func (Label) TableName() string {
+	return "labels"
+}
@@ -2,6 +2,8 @@
 package models
 
 import (
+	"log"
+
 	"gorm.io/gorm"
 )
 
@@ -17,10 +19,81 @@ func AllModels() []interface{} {
 		&Feed{},
 		&FeedItem{},
 		&UserFeedPreference{},
+		&FeedDefinition{},
+		&APIKey{},
+		&Click{},
+		&Impression{},
+		&Job{},
+		&ArticleAuditLog{},
+		&AdminUser{},
+		&AdminSession{},
+		&EmailSubscription{},
+		&Domain{},
+		&Entity{},
+		&ArticleEntity{},
+		&UserTopicAffinity{},
+		&ArchivedFeedItem{},
+		&RetentionRun{},
+		&Label{},
+		&Tenant{},
+		&PublisherOptOut{},
+		&ScoreHistory{},
+		&UserExport{},
 	}
 }
 
-// AutoMigrate runs automatic migrations for all models
+// AutoMigrate runs automatic migrations for all models. Article embeddings are
+// migrated too when the database is Postgres and the pgvector extension can be
+// enabled.
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(AllModels()...)
+	if err := removeDuplicateFollows(db); err != nil {
+		return err
+	}
+	addingFallback := addingAnonymousFallback(db)
+	if err := db.AutoMigrate(AllModels()...); err != nil {
+		return err
+	}
+	if addingFallback {
+		// Serve the global feed to logged-out readers of the default personal feed,
+		// as new installations do
+		err := db.Model(&FeedDefinition{}).
+			Where("rkey = ? AND builder = ? AND owner_id IS NULL", "open-news-personal", "personalized").
+			Update("anonymous_fallback", "global").Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := assignDefaultTenant(db); err != nil {
+		return err
+	}
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		log.Printf("⚠️  pgvector is not available, skipping article embeddings: %v", err)
+		return nil
+	}
+	return db.AutoMigrate(&ArticleEmbedding{})
+}
+
+// addingAnonymousFallback reports whether feed definitions exist from before
+// they had an anonymous fallback
+func addingAnonymousFallback(db *gorm.DB) bool {
+	migrator := db.Migrator()
+	return migrator.HasTable(&FeedDefinition{}) && !migrator.HasColumn(&FeedDefinition{}, "anonymous_fallback")
+}
+
+// removeDuplicateFollows deletes repeated user_sources rows, keeping the
+// oldest, so the unique index on (user_id, source_id) can be created on
+// databases from before it existed
+func removeDuplicateFollows(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&UserSource{}) || migrator.HasIndex(&UserSource{}, "idx_user_sources_user_source") {
+		return nil
+	}
+	return db.Exec(`DELETE FROM user_sources WHERE EXISTS (
+		SELECT 1 FROM user_sources AS kept
+		WHERE kept.user_id = user_sources.user_id AND kept.source_id = user_sources.source_id
+		AND (kept.created_at < user_sources.created_at
+			OR (kept.created_at = user_sources.created_at AND kept.id < user_sources.id)))`).Error
 }
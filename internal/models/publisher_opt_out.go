@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Publisher opt-out statuses. A publisher's request is pending until they prove
+// they own the domain; admins add active opt-outs directly.
+const (
+	OptOutPending = "pending"
+	OptOutActive  = "active"
+)
+
+// PublisherOptOut is a publisher's request to keep their site out of open.news.
+// While active, links to the domain or its subdomains aren't fetched or stored,
+// and articles already stored are kept out of feeds without their cached content.
+type PublisherOptOut struct {
+	ID         uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Domain     string     `json:"domain" db:"domain" gorm:"uniqueIndex;not null"` // Without "www.", e.g. "example.com"
+	Status     string     `json:"status" db:"status" gorm:"not null;index"`       // See the OptOut* constants
+	Contact    string     `json:"contact,omitempty" db:"contact"`                 // Who asked, e.g. an email address
+	Reason     string     `json:"reason,omitempty" db:"reason"`
+	Token      string     `json:"-" db:"token"`                           // Proves ownership once published on the site, see domains.VerifyOptOut
+	VerifiedBy string     `json:"verified_by,omitempty" db:"verified_by"` // "meta", "dns" or "admin"
+	VerifiedAt *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the PublisherOptOut model
+func (PublisherOptOut) TableName() string {
+	return "publisher_opt_outs"
+}
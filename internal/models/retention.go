@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedFeedItem is a feed item moved out of feed_items by the retention job.
+// Archived items still count as an article having been ranked.
+type ArchivedFeedItem struct {
+	ID          uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid"`
+	FeedID      uuid.UUID  `json:"feed_id" db:"feed_id" gorm:"type:uuid;not null;index"`
+	ArticleID   uuid.UUID  `json:"article_id" db:"article_id" gorm:"type:uuid;not null;index"`
+	UserID      *uuid.UUID `json:"user_id" db:"user_id" gorm:"type:uuid"`
+	Position    int        `json:"position" db:"position"`
+	Score       float64    `json:"score" db:"score"`
+	Relevance   float64    `json:"relevance" db:"relevance"`
+	AddedAt     time.Time  `json:"added_at" db:"added_at"`
+	LastShownAt *time.Time `json:"last_shown_at" db:"last_shown_at"`
+	ArchivedAt  time.Time  `json:"archived_at" db:"archived_at" gorm:"not null;index"`
+}
+
+// TableName sets the table name for the ArchivedFeedItem model
+func (ArchivedFeedItem) TableName() string {
+	return "feed_items_archive"
+}
+
+// RetentionRun records what one run of the retention job purged
+type RetentionRun struct {
+	ID                 uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	StartedAt          time.Time `json:"started_at" db:"started_at" gorm:"not null;index"`
+	FinishedAt         time.Time `json:"finished_at" db:"finished_at"`
+	HTMLPurged         int64     `json:"html_purged" db:"html_purged"`                   // Articles whose cached HTML was dropped
+	ArticlesDeleted    int64     `json:"articles_deleted" db:"articles_deleted"`         // Unreachable articles that were never ranked
+	FeedItemsArchived  int64     `json:"feed_items_archived" db:"feed_items_archived"`   // Feed items moved to feed_items_archive
+	ScoreHistoryPurged int64     `json:"score_history_purged" db:"score_history_purged"` // Score snapshots past their retention period
+	Error              string    `json:"error,omitempty" db:"error"`                     // Why the run stopped early, if it did
+}
+
+// TableName sets the table name for the RetentionRun model
+func (RetentionRun) TableName() string {
+	return "retention_runs"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScoreBreakdown records the components that make up an article's quality and
+// trending scores, so operators can see why an article ranks where it does
+type ScoreBreakdown struct {
+	Ranker string `json:"ranker,omitempty"` // Ranker that produced the scores
+
+	// Quality score components, already weighted
+	Base             float64 `json:"base"`
+	SourceQuality    float64 `json:"source_quality"`            // Average quality of sharing sources × 0.4
+	ReplyPenalty     float64 `json:"reply_penalty,omitempty"`   // Source quality lost to downranked replies from untrusted sources, already taken off SourceQuality
+	Engagement       float64 `json:"engagement"`                // Likes, reposts and shares, capped at 0.3
+	ContentQuality   float64 `json:"content_quality"`           // Length, title, description and image × 0.2
+	DomainReputation float64 `json:"domain_reputation"`         // Known publisher reputation × 0.1
+	EditorialBoost   float64 `json:"editorial_boost,omitempty"` // Admin boost or penalty, added after the cap
+	Breaking         float64 `json:"breaking,omitempty"`        // Temporary boost for breaking stories, fading over a few hours; added after the cap
+	QualityScore     float64 `json:"quality_score"`             // Sum of the above, capped at 1.0, plus the editorial and breaking boosts
+
+	// Trending score components
+	Velocity      float64 `json:"velocity"`       // Engagement per hour since the article was created
+	Decay         float64 `json:"decay"`          // Age decay factor, 1.0 when new
+	TrendingScore float64 `json:"trending_score"` // Velocity × decay / 10, capped at 1.0
+
+	// Boost for articles shared by several of the requesting user's follows (follow-graph ranker only)
+	FollowGraph float64 `json:"follow_graph,omitempty"`
+
+	// Boost for articles on topics the requesting user favors (personalized feeds only)
+	TopicAffinity float64 `json:"topic_affinity,omitempty"`
+
+	CalculatedAt time.Time `json:"calculated_at"`
+}
+
+// ParseScoreBreakdown decodes a breakdown stored in Article.ScoreBreakdownData.
+// It returns nil when the article hasn't been scored since breakdowns were recorded.
+func ParseScoreBreakdown(data string) (*ScoreBreakdown, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var breakdown ScoreBreakdown
+	if err := json.Unmarshal([]byte(data), &breakdown); err != nil {
+		return nil, err
+	}
+	return &breakdown, nil
+}
+
+// Encode returns the breakdown as JSON for Article.ScoreBreakdownData
+func (b ScoreBreakdown) Encode() string {
+	data, _ := json.Marshal(b)
+	return string(data)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScoreHistory is a snapshot of an article's scores, recorded after each
+// quality score update while the article is recent, so how its ranking
+// changed can be traced
+type ScoreHistory struct {
+	ID            uuid.UUID `json:"-" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ArticleID     uuid.UUID `json:"-" db:"article_id" gorm:"type:uuid;not null;index:idx_score_history_article_recorded,priority:1"`
+	QualityScore  float64   `json:"quality_score" db:"quality_score"`
+	TrendingScore float64   `json:"trending_score" db:"trending_score"`
+	RecordedAt    time.Time `json:"recorded_at" db:"recorded_at" gorm:"not null;index:idx_score_history_article_recorded,priority:2;index"`
+}
+
+// TableName sets the table name for the ScoreHistory model
+func (ScoreHistory) TableName() string {
+	return "score_history"
+}
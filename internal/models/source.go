@@ -4,6 +4,23 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Source verification statuses. Automatic checks set IsVerified and leave the source
+// pending until an admin approves or rejects it; reviewed sources aren't checked again.
+const (
+	VerificationPending  = "pending"
+	VerificationApproved = "approved"
+	VerificationRejected = "rejected"
+)
+
+// Source spam statuses. Spam checks flag a source for admin review; flagged and
+// confirmed sources are downranked, cleared ones are checked again on new posts.
+const (
+	SpamFlagged   = "flagged"
+	SpamConfirmed = "confirmed"
+	SpamCleared   = "cleared"
 )
 
 // Source represents users that share links (content creators)
@@ -15,8 +32,23 @@ type Source struct {
 	Avatar      string    `json:"avatar" db:"avatar"`
 	Bio         string    `json:"bio" db:"bio"`
 	FollowersCount int    `json:"followers_count" db:"followers_count" gorm:"default:0"`
+	FollowsCount   int    `json:"follows_count" db:"follows_count" gorm:"default:0"`
+	PostsCount     int    `json:"posts_count" db:"posts_count" gorm:"default:0"`
+	Labels         pq.StringArray `json:"labels" db:"labels" gorm:"type:text[]"` // Moderation label values from the profile
+	ProfileRefreshedAt *time.Time `json:"profile_refreshed_at" db:"profile_refreshed_at"` // Last profile enrichment
+	IsActive       bool   `json:"is_active" db:"is_active" gorm:"default:true"` // False while the Bluesky account is deactivated, suspended or deleted
+	AccountStatus  string `json:"account_status,omitempty" db:"account_status"` // Jetstream status of an inactive account, e.g. "deleted"
 	IsVerified     bool   `json:"is_verified" db:"is_verified" gorm:"default:false"`
+	VerificationStatus    string     `json:"verification_status,omitempty" db:"verification_status" gorm:"index"` // See the Verification* constants; empty until verified automatically
+	VerificationMethod    string     `json:"verification_method,omitempty" db:"verification_method"`             // How the account was matched to VerifiedDomain
+	VerifiedDomain        string     `json:"verified_domain,omitempty" db:"verified_domain"`                     // News site the account speaks for, e.g. "reuters.com"
+	VerificationCheckedAt *time.Time `json:"verification_checked_at,omitempty" db:"verification_checked_at"`   // Last automatic check
+	SpamStatus            string         `json:"spam_status,omitempty" db:"spam_status" gorm:"index"` // See the Spam* constants; empty when never flagged
+	SpamReasons           pq.StringArray `json:"spam_reasons,omitempty" db:"spam_reasons" gorm:"type:text[]"` // Why the source was flagged
+	SpamFlaggedAt         *time.Time     `json:"spam_flagged_at,omitempty" db:"spam_flagged_at"`
+	SpamReviewedAt        *time.Time     `json:"spam_reviewed_at,omitempty" db:"spam_reviewed_at"` // Only posts after a review are checked again
 	QualityScore   float64 `json:"quality_score" db:"quality_score" gorm:"default:0.0"` // Algorithm score for source quality
+	NeedsRescore   bool    `json:"-" db:"needs_rescore" gorm:"default:false"`      // Shares or engagement changed since the source was last scored
 	CreatedAt      time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
 
@@ -29,3 +61,8 @@ type Source struct {
 func (Source) TableName() string {
 	return "sources"
 }
+
+// IsSpam reports whether the source is flagged or confirmed as spam
+func (s *Source) IsSpam() bool {
+	return s.SpamStatus == SpamFlagged || s.SpamStatus == SpamConfirmed
+}
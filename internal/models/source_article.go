@@ -4,13 +4,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Share types, read from the structure of the post record
+const (
+	ShareTypePost   = "post"   // An original post linking to the article
+	ShareTypeQuote  = "quote"  // A post quoting another that links to the article
+	ShareTypeRepost = "repost" // A repost of a post linking to the article
+	ShareTypeReply  = "reply"  // A reply to another post, linking to the article
 )
 
 // SourceArticle represents a source's post or repost that contains an article
 type SourceArticle struct {
 	ID         uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	SourceID   uuid.UUID `json:"source_id" db:"source_id" gorm:"not null;index"`
-	ArticleID  uuid.UUID `json:"article_id" db:"article_id" gorm:"not null;index;uniqueIndex:idx_source_articles_unique,priority:2"`
+	SourceID   uuid.UUID `json:"source_id" db:"source_id" gorm:"not null;index;index:idx_source_articles_article_source,priority:2"`
+	ArticleID  uuid.UUID `json:"article_id" db:"article_id" gorm:"not null;index;uniqueIndex:idx_source_articles_unique,priority:2;index:idx_source_articles_article_source,priority:1"`
 	
 	// Bluesky post information
 	PostURI    string `json:"post_uri" db:"post_uri" gorm:"uniqueIndex:idx_source_articles_unique,priority:1;not null"` // Bluesky post AT URI
@@ -18,9 +27,14 @@ type SourceArticle struct {
 	PostText   string `json:"post_text" db:"post_text" gorm:"type:text"`          // Post content
 	
 	// Post metadata
-	IsRepost     bool      `json:"is_repost" db:"is_repost" gorm:"default:false"`
-	OriginalURI  string    `json:"original_uri" db:"original_uri"`      // If repost, original post URI
-	PostedAt     time.Time `json:"posted_at" db:"posted_at"`            // When posted on Bluesky
+	ShareType    string    `json:"share_type" db:"share_type" gorm:"default:'post'"` // See the ShareType* constants
+	IsRepost     bool      `json:"is_repost" db:"is_repost" gorm:"default:false"` // ShareType is ShareTypeRepost
+	OriginalURI  string    `json:"original_uri" db:"original_uri" gorm:"index"` // If repost or quote, original post URI
+	PostedAt     time.Time `json:"posted_at" db:"posted_at" gorm:"index;uniqueIndex:idx_source_articles_unique,priority:3"` // When posted on Bluesky; the partition key of source_articles
+	
+	// Moderation labels on the post
+	Labels      pq.StringArray `json:"labels" db:"labels" gorm:"type:text[]"`                    // Self-labels and labeler labels
+	IsSensitive bool           `json:"is_sensitive" db:"is_sensitive" gorm:"default:false;index"` // Labeled porn, graphic media or similar
 	
 	// Engagement metrics from Bluesky
 	LikesCount   int `json:"likes_count" db:"likes_count" gorm:"default:0"`
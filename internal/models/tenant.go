@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// DefaultTenantSlug is the slug of the tenant every deployment has. It serves
+// requests for hosts no other tenant claims, and owns the feeds and admin
+// accounts created before tenants existed.
+const DefaultTenantSlug = "default"
+
+// Tenant is an operator hosting its own feeds on a shared deployment. Each
+// tenant has its own feed definitions, admin accounts, branding and Bluesky
+// account; articles and sources are shared by all tenants.
+type Tenant struct {
+	ID        uuid.UUID      `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Slug      string         `json:"slug" db:"slug" gorm:"uniqueIndex;not null"`  // e.g. "default" or "citynews"
+	Hostnames pq.StringArray `json:"hostnames" db:"hostnames" gorm:"type:text[]"` // Hosts the tenant is served on, e.g. "feeds.citynews.example"
+	IsActive  bool           `json:"is_active" db:"is_active" gorm:"default:true"`
+
+	// Branding of the admin and article pages
+	Name        string `json:"name" db:"name" gorm:"not null"` // Shown in page titles, e.g. "City News"
+	LogoURL     string `json:"logo_url" db:"logo_url"`
+	AccentColor string `json:"accent_color" db:"accent_color"` // CSS color, e.g. "#c0392b"
+
+	// Feed generator identity; empty fields fall back to the deployment's settings
+	FeedGeneratorDID  string `json:"feed_generator_did" db:"feed_generator_did" gorm:"column:feed_generator_did"` // Instead of FEED_GENERATOR_DID
+	BlueskyIdentifier string `json:"bluesky_identifier" db:"bluesky_identifier"`                                  // Account publishing the feeds, instead of BLUESKY_IDENTIFIER
+	BlueskyPassword   string `json:"-" db:"bluesky_password"`                                                     // App password of that account, instead of BLUESKY_PASSWORD
+
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the Tenant model
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// IsDefault reports whether this is the deployment's default tenant
+func (t *Tenant) IsDefault() bool {
+	return t.Slug == DefaultTenantSlug
+}
+
+// DefaultTenant returns the default tenant, creating it if it doesn't exist yet
+func DefaultTenant(db *gorm.DB) (*Tenant, error) {
+	tenant := Tenant{Slug: DefaultTenantSlug, Name: "open.news", IsActive: true}
+	if err := db.Where("slug = ?", DefaultTenantSlug).FirstOrCreate(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to ensure default tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// DefaultTenantID returns the ID of the default tenant, or nil on databases
+// without a tenants table, such as test databases migrating only some models
+func DefaultTenantID(db *gorm.DB) *uuid.UUID {
+	if !db.Migrator().HasTable(&Tenant{}) {
+		return nil
+	}
+	tenant, err := DefaultTenant(db)
+	if err != nil {
+		return nil
+	}
+	return &tenant.ID
+}
+
+// assignDefaultTenant gives the feed definitions and admin accounts created
+// before tenants existed to the default tenant, and drops the unique indexes
+// on record keys and usernames that are now unique per tenant
+func assignDefaultTenant(db *gorm.DB) error {
+	tenant, err := DefaultTenant(db)
+	if err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&FeedDefinition{}, &AdminUser{}} {
+		if err := db.Model(model).Where("tenant_id IS NULL").Update("tenant_id", tenant.ID).Error; err != nil {
+			return fmt.Errorf("failed to assign default tenant: %w", err)
+		}
+	}
+
+	migrator := db.Migrator()
+	for _, index := range []struct {
+		model interface{}
+		name  string
+	}{
+		{&FeedDefinition{}, "idx_feed_definitions_rkey"},
+		{&AdminUser{}, "idx_admin_users_username"},
+	} {
+		if !migrator.HasIndex(index.model, index.name) {
+			continue
+		}
+		if err := migrator.DropIndex(index.model, index.name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTopicAffinity is how strongly a user is drawn to a topic, learned from the
+// articles their follows share and the articles they click
+type UserTopicAffinity struct {
+	UserID    uuid.UUID `json:"-" db:"user_id" gorm:"primaryKey;type:uuid"`
+	Topic     string    `json:"topic" db:"topic" gorm:"primaryKey"`
+	Shares    int       `json:"shares" db:"shares" gorm:"default:0"`   // Articles on the topic the user's follows shared recently
+	Clicks    int       `json:"clicks" db:"clicks" gorm:"default:0"`   // Articles on the topic the user clicked recently
+	Weight    float64   `json:"weight" db:"weight" gorm:"default:0.0"` // 0-1; the topic's part of the user's recent activity
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName sets the table name for the UserTopicAffinity model
+func (UserTopicAffinity) TableName() string {
+	return "user_topic_affinities"
+}
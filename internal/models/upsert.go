@@ -0,0 +1,96 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// insertUnique inserts row unless a row with the same value in a uniquely
+// indexed column exists, in which case row is replaced with the stored one. It
+// reports whether row was inserted. Unlike looking the row up before creating
+// it, this can't fail or create a duplicate when two workers store the same
+// row at once.
+func insertUnique[T any](db *gorm.DB, row *T, column string, value interface{}) (bool, error) {
+	result := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: column}}, DoNothing: true}).Create(row)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	var stored T
+	if err := db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value}).First(&stored).Error; err != nil {
+		return false, err
+	}
+	*row = stored
+	return false, nil
+}
+
+// InsertArticle stores a new article, or loads the article already stored
+// with its URL into article. It reports whether the article was inserted.
+func InsertArticle(db *gorm.DB, article *Article) (bool, error) {
+	return insertUnique(db, article, "url", article.URL)
+}
+
+// InsertSource stores a new source, or loads the source already stored with
+// its DID into source. It reports whether the source was inserted.
+func InsertSource(db *gorm.DB, source *Source) (bool, error) {
+	return insertUnique(db, source, "blue_sky_d_id", source.BlueSkyDID)
+}
+
+// InsertUser stores a new user, or loads the user already stored with its DID
+// into user. It reports whether the user was inserted.
+func InsertUser(db *gorm.DB, user *User) (bool, error) {
+	return insertUnique(db, user, "blue_sky_d_id", user.BlueSkyDID)
+}
+
+// InsertShare stores a share unless the post was already recorded for the
+// article, and reports whether it was inserted. No conflict target is named so
+// the statement works with both the partitioned and the older unique index.
+func InsertShare(db *gorm.DB, share *SourceArticle) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(share)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if err := MarkShared(db, share.ArticleID); err != nil {
+		return true, err
+	}
+	if err := AggregateEngagement(db, share.ArticleID); err != nil {
+		return true, err
+	}
+	return true, MarkForRescore(db, []uuid.UUID{share.ArticleID}, []uuid.UUID{share.SourceID})
+}
+
+// MarkForRescore flags articles and sources whose shares or engagement changed,
+// so the next metrics update rescores them without sweeping every row
+func MarkForRescore(db *gorm.DB, articleIDs, sourceIDs []uuid.UUID) error {
+	if len(articleIDs) > 0 {
+		if err := db.Model(&Article{}).Where("id IN ? AND needs_rescore = ?", articleIDs, false).Update("needs_rescore", true).Error; err != nil {
+			return err
+		}
+	}
+	if len(sourceIDs) > 0 {
+		return db.Model(&Source{}).Where("id IN ? AND needs_rescore = ?", sourceIDs, false).Update("needs_rescore", true).Error
+	}
+	return nil
+}
+
+// MarkShared clears IsUnshared on articles that have just been shared
+func MarkShared(db *gorm.DB, articleIDs ...uuid.UUID) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	return db.Model(&Article{}).Where("id IN ? AND is_unshared = ?", articleIDs, true).Update("is_unshared", false).Error
+}
+
+// InsertUserSource stores a follow unless the user already follows the
+// source, and reports whether it was inserted
+func InsertUserSource(db *gorm.DB, follow *UserSource) (bool, error) {
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "source_id"}},
+		DoNothing: true,
+	}).Create(follow)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User export statuses
+const (
+	UserExportStatusPending = "pending" // Waiting for a worker to build the archive
+	UserExportStatusReady   = "ready"   // The archive can be downloaded until ExpiresAt
+)
+
+// UserExport is an archive of everything stored about a feed user, built by a
+// worker on request so they can take their data elsewhere
+type UserExport struct {
+	ID          uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `json:"-" db:"user_id" gorm:"type:uuid;not null;index"`
+	Status      string     `json:"status" db:"status" gorm:"not null;default:'pending'"`
+	Data        string     `json:"-" db:"data" gorm:"type:text"` // The JSON archive, once ready
+	CreatedAt   time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at" gorm:"index"` // When the archive is deleted
+}
+
+// TableName sets the table name for the UserExport model
+func (UserExport) TableName() string {
+	return "user_exports"
+}
@@ -8,11 +8,12 @@ import (
 
 // UserSource represents the relationship between users and the sources they follow
 type UserSource struct {
-	ID        uuid.UUID `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id" gorm:"not null;index"`
-	SourceID  uuid.UUID `json:"source_id" db:"source_id" gorm:"not null;index"`
-	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+	ID         uuid.UUID  `json:"id" db:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id" gorm:"not null;index;uniqueIndex:idx_user_sources_user_source,priority:1"`
+	SourceID   uuid.UUID  `json:"source_id" db:"source_id" gorm:"not null;index;uniqueIndex:idx_user_sources_user_source,priority:2"`
+	LastSeenAt *time.Time `json:"last_seen_at" db:"last_seen_at"` // Last follow refresh that listed this follow
+	CreatedAt  time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
 
 	// Relationships
 	User   User   `json:"user,omitempty" gorm:"foreignKey:UserID;references:ID"`
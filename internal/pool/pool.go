@@ -0,0 +1,129 @@
+// Package pool runs background work such as scoring and fetching concurrently,
+// with bounded parallelism overall and per domain, so batches finish sooner
+// without opening unlimited database connections or hammering one site.
+package pool
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultConcurrency and defaultPerDomain apply without WORKER_CONCURRENCY and
+// FETCH_CONCURRENCY_PER_DOMAIN
+const (
+	defaultConcurrency = 8
+	defaultPerDomain   = 2
+)
+
+// Config holds how much background work runs at once
+type Config struct {
+	Concurrency int // Tasks a pool runs at once (default: 8)
+	PerDomain   int // Fetches from one domain at once (default: 2)
+}
+
+// LoadConfig reads the concurrency settings from the environment
+func LoadConfig() Config {
+	config := Config{Concurrency: defaultConcurrency, PerDomain: defaultPerDomain}
+	if value := os.Getenv("WORKER_CONCURRENCY"); value != "" {
+		if concurrency, err := strconv.Atoi(value); err == nil && concurrency > 0 {
+			config.Concurrency = concurrency
+		} else {
+			log.Printf("Invalid WORKER_CONCURRENCY %q, using %d", value, defaultConcurrency)
+		}
+	}
+	if value := os.Getenv("FETCH_CONCURRENCY_PER_DOMAIN"); value != "" {
+		if perDomain, err := strconv.Atoi(value); err == nil && perDomain > 0 {
+			config.PerDomain = perDomain
+		} else {
+			log.Printf("Invalid FETCH_CONCURRENCY_PER_DOMAIN %q, using %d", value, defaultPerDomain)
+		}
+	}
+	return config
+}
+
+var (
+	sharedOnce   sync.Once
+	sharedConfig Config
+)
+
+// Shared returns the process-wide concurrency settings, read from the
+// environment on first use so values loaded from .env at startup are picked up
+func Shared() Config {
+	sharedOnce.Do(func() {
+		sharedConfig = LoadConfig()
+	})
+	return sharedConfig
+}
+
+// Group runs tasks on at most a fixed number of goroutines. Unlike a plain
+// errgroup, a failing task doesn't stop the others: every error is collected
+// and Wait returns them joined, as the sequential loops it replaces did.
+type Group struct {
+	group  errgroup.Group
+	mu     sync.Mutex
+	errors []error
+}
+
+// New creates a group running at most limit tasks at once, or one at a time
+// when limit isn't positive
+func New(limit int) *Group {
+	g := &Group{}
+	g.group.SetLimit(max(limit, 1))
+	return g
+}
+
+// Go runs task once fewer than the group's limit are running
+func (g *Group) Go(task func() error) {
+	g.group.Go(func() error {
+		if err := task(); err != nil {
+			g.mu.Lock()
+			g.errors = append(g.errors, err)
+			g.mu.Unlock()
+		}
+		return nil
+	})
+}
+
+// Wait waits for every task and returns their errors joined, or nil
+func (g *Group) Wait() error {
+	g.group.Wait()
+	return errors.Join(g.errors...)
+}
+
+// DomainLimiter bounds how many tasks run against one domain at once
+type DomainLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// NewDomainLimiter creates a limiter allowing limit tasks per domain at once
+func NewDomainLimiter(limit int) *DomainLimiter {
+	return &DomainLimiter{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+// Do runs task once fewer than the limit are running for domain, or returns
+// the context's error if it's done first
+func (l *DomainLimiter) Do(ctx context.Context, domain string, task func() error) error {
+	l.mu.Lock()
+	slots, ok := l.slots[domain]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[domain] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slots }()
+	return task()
+}
@@ -0,0 +1,83 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("WORKER_CONCURRENCY", "4")
+	t.Setenv("FETCH_CONCURRENCY_PER_DOMAIN", "0")
+
+	config := LoadConfig()
+	assert.Equal(t, 4, config.Concurrency)
+	assert.Equal(t, defaultPerDomain, config.PerDomain, "zero is invalid")
+}
+
+// track counts running tasks and records the most seen at once
+type track struct {
+	running, peak atomic.Int32
+}
+
+func (tr *track) run() {
+	n := tr.running.Add(1)
+	for {
+		peak := tr.peak.Load()
+		if n <= peak || tr.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	tr.running.Add(-1)
+}
+
+func TestGroup(t *testing.T) {
+	var tr track
+	group := New(3)
+	for i := 0; i < 12; i++ {
+		group.Go(func() error {
+			tr.run()
+			if i%5 == 0 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	assert.Error(t, err)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3, "every failure is returned")
+	assert.Equal(t, int32(3), tr.peak.Load())
+}
+
+func TestDomainLimiter(t *testing.T) {
+	var example, other track
+	limiter := NewDomainLimiter(2)
+	group := New(10)
+	for i := 0; i < 5; i++ {
+		group.Go(func() error {
+			return limiter.Do(context.Background(), "example.com", func() error { example.run(); return nil })
+		})
+		group.Go(func() error {
+			return limiter.Do(context.Background(), "other.com", func() error { other.run(); return nil })
+		})
+	}
+
+	assert.NoError(t, group.Wait())
+	assert.Equal(t, int32(2), example.peak.Load())
+	assert.Equal(t, int32(2), other.peak.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hold := make(chan struct{})
+	go limiter.Do(context.Background(), "busy.com", func() error { <-hold; return nil })
+	go limiter.Do(context.Background(), "busy.com", func() error { <-hold; return nil })
+	time.Sleep(10 * time.Millisecond)
+	assert.ErrorIs(t, limiter.Do(ctx, "busy.com", func() error { return nil }), context.Canceled)
+	close(hold)
+}
@@ -0,0 +1,262 @@
+// Package ranking turns article signals (source quality, engagement, content,
+// domain reputation and age) into the quality and trending scores feeds are
+// ordered by. Feed definitions select a Ranker by name so ranking experiments
+// don't require changes to the scoring service.
+package ranking
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"open-news/internal/models"
+)
+
+// Ranker names feed definitions can select
+const (
+	Default     = "default"
+	Engagement  = "engagement"
+	Editorial   = "editorial"
+	FollowGraph = "follow-graph"
+)
+
+// Ranker scores articles for a feed
+type Ranker interface {
+	// Name is the key feed definitions use to select the ranker
+	Name() string
+
+	// Explain scores an article and returns the components that produced the
+	// scores. The article's SourceArticles.Source should be preloaded.
+	Explain(article models.Article, signals Signals) models.ScoreBreakdown
+
+	// Rank combines a breakdown into the single value feed items are ordered by
+	Rank(breakdown models.ScoreBreakdown) float64
+}
+
+// Signals is context about a ranking request beyond the article itself
+type Signals struct {
+	Now             time.Time      // Time scores are calculated at; zero uses time.Now()
+	FollowedSharers int            // Sources the requesting user follows that shared the article
+	Domain          *models.Domain // The article's site in the domains table; nil for unknown sites
+
+	// How shares in replies count; nil uses ReplyLinks()
+	ReplyLinks *ReplyLinkPolicy
+
+	// Learned topic weights (0-1) of the requesting user, for personalized rankers
+	TopicAffinity map[string]float64
+}
+
+func (s Signals) now() time.Time {
+	if s.Now.IsZero() {
+		return time.Now()
+	}
+	return s.Now
+}
+
+func (s Signals) replyLinks() ReplyLinkPolicy {
+	if s.ReplyLinks == nil {
+		return ReplyLinks()
+	}
+	return *s.ReplyLinks
+}
+
+// Weights controls how a weightedRanker combines article signals
+type Weights struct {
+	Base             float64 // Score every article starts with
+	SourceQuality    float64 // Multiplier for the average quality of sharing sources
+	EngagementScale  float64 // Engagement count worth a full point, before the cap
+	EngagementCap    float64 // Maximum engagement contribution
+	ContentQuality   float64 // Multiplier for content quality (length, title, description, image)
+	DomainReputation float64 // Multiplier for the site's reputation in the domains table
+	TrendingWeight   float64 // How much trending score counts towards rank
+}
+
+// DefaultWeights are the weights open.news has always ranked with
+var DefaultWeights = Weights{
+	Base:             0.5,
+	SourceQuality:    0.4,
+	EngagementScale:  500,
+	EngagementCap:    0.3,
+	ContentQuality:   0.2,
+	DomainReputation: 0.1,
+	TrendingWeight:   0.3,
+}
+
+// weightedRanker is a linear combination of article signals
+type weightedRanker struct {
+	name    string
+	weights Weights
+}
+
+// NewWeightedRanker creates a ranker that combines article signals with the given weights
+func NewWeightedRanker(name string, weights Weights) Ranker {
+	return &weightedRanker{name: name, weights: weights}
+}
+
+func (r *weightedRanker) Name() string {
+	return r.name
+}
+
+func (r *weightedRanker) Explain(article models.Article, signals Signals) models.ScoreBreakdown {
+	w := r.weights
+	breakdown := models.ScoreBreakdown{Ranker: r.name, Base: w.Base}
+
+	// 1. Source quality contribution, and what downranked replies took off it
+	replies := signals.replyLinks()
+	breakdown.SourceQuality = AverageSourceQuality(article, replies) * w.SourceQuality
+	breakdown.ReplyPenalty = AverageSourceQuality(article, ReplyLinkPolicy{})*w.SourceQuality - breakdown.SourceQuality
+
+	// 2. Engagement metrics
+	totalEngagement := article.LikesCount + article.RepostsCount + article.SharesCount
+	if w.EngagementScale > 0 {
+		breakdown.Engagement = math.Min(float64(totalEngagement)/w.EngagementScale, w.EngagementCap)
+	}
+
+	// 3. Content quality indicators
+	breakdown.ContentQuality = ContentQuality(article) * w.ContentQuality
+
+	// 4. Domain reputation
+	breakdown.DomainReputation = DomainReputation(signals.Domain) * w.DomainReputation
+
+	score := breakdown.Base + breakdown.SourceQuality + breakdown.Engagement + breakdown.ContentQuality + breakdown.DomainReputation
+	breakdown.QualityScore = math.Min(score, 1.0) // Cap at 1.0
+
+	now := signals.now()
+
+	// 5. Editorial and breaking boosts, applied after the cap so they can lift a top article further
+	breakdown.EditorialBoost = article.EditorialBoost
+	breakdown.Breaking = Breaking(article, now)
+	breakdown.QualityScore = math.Max(breakdown.QualityScore+breakdown.EditorialBoost+breakdown.Breaking, 0)
+
+	breakdown.Velocity, breakdown.Decay, breakdown.TrendingScore = Trending(article, now)
+	breakdown.CalculatedAt = now
+
+	return breakdown
+}
+
+func (r *weightedRanker) Rank(breakdown models.ScoreBreakdown) float64 {
+	return breakdown.QualityScore + breakdown.TrendingScore*r.weights.TrendingWeight
+}
+
+// followGraphRanker boosts articles shared by several of the requesting user's follows
+type followGraphRanker struct {
+	base Ranker
+}
+
+// followBoostPerSharer and followBoostCap bound the follow-graph boost
+const (
+	followBoostPerSharer = 0.1
+	followBoostCap       = 0.3
+)
+
+func (r *followGraphRanker) Name() string {
+	return FollowGraph
+}
+
+func (r *followGraphRanker) Explain(article models.Article, signals Signals) models.ScoreBreakdown {
+	breakdown := r.base.Explain(article, signals)
+	breakdown.Ranker = FollowGraph
+	breakdown.FollowGraph = math.Min(float64(signals.FollowedSharers)*followBoostPerSharer, followBoostCap)
+	return breakdown
+}
+
+func (r *followGraphRanker) Rank(breakdown models.ScoreBreakdown) float64 {
+	return r.base.Rank(breakdown) + breakdown.FollowGraph
+}
+
+// personalizedRanker boosts articles on the topics the requesting user favors
+type personalizedRanker struct {
+	base Ranker
+}
+
+// topicBoostScale and topicBoostCap bound the topic affinity boost
+const (
+	topicBoostScale = 0.5
+	topicBoostCap   = 0.3
+)
+
+// Personalized wraps a ranker with a boost for articles on the requesting user's
+// favored topics, from Signals.TopicAffinity. A nil base uses the default ranker.
+func Personalized(base Ranker) Ranker {
+	if base == nil {
+		base = Get(Default)
+	}
+	return &personalizedRanker{base: base}
+}
+
+func (r *personalizedRanker) Name() string {
+	return r.base.Name()
+}
+
+func (r *personalizedRanker) Explain(article models.Article, signals Signals) models.ScoreBreakdown {
+	breakdown := r.base.Explain(article, signals)
+	breakdown.TopicAffinity = TopicBoost(article.Tags, signals.TopicAffinity)
+	return breakdown
+}
+
+func (r *personalizedRanker) Rank(breakdown models.ScoreBreakdown) float64 {
+	return r.base.Rank(breakdown) + breakdown.TopicAffinity
+}
+
+// TopicBoost is the boost for an article with the given topic tags, from the
+// weight of its most favored topic
+func TopicBoost(tags []string, affinity map[string]float64) float64 {
+	best := 0.0
+	for _, tag := range tags {
+		best = math.Max(best, affinity[tag])
+	}
+	return math.Min(best*topicBoostScale, topicBoostCap)
+}
+
+var rankers = map[string]Ranker{
+	Default: NewWeightedRanker(Default, DefaultWeights),
+
+	// Favors fast-moving stories: engagement and trending count most
+	Engagement: NewWeightedRanker(Engagement, Weights{
+		Base:             0.3,
+		SourceQuality:    0.2,
+		EngagementScale:  250,
+		EngagementCap:    0.5,
+		ContentQuality:   0.1,
+		DomainReputation: 0.05,
+		TrendingWeight:   0.6,
+	}),
+
+	// Favors reputable publishers and substantial articles over raw popularity
+	Editorial: NewWeightedRanker(Editorial, Weights{
+		Base:             0.3,
+		SourceQuality:    0.2,
+		EngagementScale:  1000,
+		EngagementCap:    0.1,
+		ContentQuality:   0.3,
+		DomainReputation: 0.3,
+		TrendingWeight:   0.1,
+	}),
+
+	// Default scoring plus a boost for stories several follows shared
+	FollowGraph: &followGraphRanker{base: NewWeightedRanker(Default, DefaultWeights)},
+}
+
+// Get returns the ranker registered under name. Empty and unknown names use the default ranker.
+func Get(name string) Ranker {
+	if ranker, ok := rankers[name]; ok {
+		return ranker
+	}
+	return rankers[Default]
+}
+
+// Exists reports whether a ranker is registered under name
+func Exists(name string) bool {
+	_, ok := rankers[name]
+	return ok
+}
+
+// Names returns the registered ranker names in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(rankers))
+	for name := range rankers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
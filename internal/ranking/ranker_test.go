@@ -0,0 +1,216 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", Default},
+		{Default, Default},
+		{Engagement, Engagement},
+		{Editorial, Editorial},
+		{FollowGraph, FollowGraph},
+		{"unknown", Default},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Get(tt.name).Name())
+		})
+	}
+
+	assert.Equal(t, []string{Default, Editorial, Engagement, FollowGraph}, Names())
+}
+
+func TestDefaultRanker_Explain(t *testing.T) {
+	now := time.Now()
+	article := models.Article{
+		Title:        "A reasonably long headline",
+		SiteName:     "Unknown Gazette",
+		WordCount:    200,
+		LikesCount:   40,
+		RepostsCount: 10,
+		CreatedAt:    now.Add(-5 * time.Hour),
+		SourceArticles: []models.SourceArticle{
+			{Source: models.Source{QualityScore: 0.5}},
+		},
+	}
+
+	breakdown := Get(Default).Explain(article, Signals{Now: now})
+
+	assert.Equal(t, Default, breakdown.Ranker)
+	assert.Equal(t, 0.5, breakdown.Base)
+	assert.InDelta(t, 0.2, breakdown.SourceQuality, 1e-9)
+	assert.InDelta(t, 0.1, breakdown.Engagement, 1e-9)
+	assert.InDelta(t, 0.14, breakdown.ContentQuality, 1e-9)
+	assert.InDelta(t, 0.05, breakdown.DomainReputation, 1e-9)
+	assert.InDelta(t, 0.99, breakdown.QualityScore, 1e-9)
+	assert.InDelta(t, 10.0, breakdown.Velocity, 1e-9)
+	assert.Equal(t, now, breakdown.CalculatedAt)
+	assert.InDelta(t, breakdown.QualityScore+breakdown.TrendingScore*0.3, Get(Default).Rank(breakdown), 1e-9)
+}
+
+func TestContentQuality_SchemaType(t *testing.T) {
+	article := models.Article{Title: "A reasonably long headline", WordCount: 200}
+
+	assert.InDelta(t, 0.7, ContentQuality(article), 1e-9)
+	article.SchemaType = "NewsArticle"
+	assert.InDelta(t, 0.8, ContentQuality(article), 1e-9)
+	article.SchemaType = "BlogPosting"
+	assert.InDelta(t, 0.75, ContentQuality(article), 1e-9)
+}
+
+func TestDefaultRanker_EditorialBoost(t *testing.T) {
+	now := time.Now()
+	article := models.Article{Title: "Headline", CreatedAt: now}
+
+	unboosted := Get(Default).Explain(article, Signals{Now: now})
+
+	article.EditorialBoost = 0.25
+	boosted := Get(Default).Explain(article, Signals{Now: now})
+	assert.Equal(t, 0.25, boosted.EditorialBoost)
+	assert.InDelta(t, unboosted.QualityScore+0.25, boosted.QualityScore, 1e-9)
+
+	article.EditorialBoost = -2
+	penalized := Get(Default).Explain(article, Signals{Now: now})
+	assert.Equal(t, 0.0, penalized.QualityScore, "penalties never push the score below zero")
+}
+
+func TestRankers_Ordering(t *testing.T) {
+	now := time.Now()
+
+	// A viral story from an unknown site versus a long read from a reputable publisher
+	viral := models.Article{
+		Title:      "Everyone is talking about this",
+		SiteName:   "Viral Daily",
+		LikesCount: 400,
+		CreatedAt:  now.Add(-2 * time.Hour),
+	}
+	longRead := models.Article{
+		Title:       "An in-depth investigation",
+		Description: "A description that is comfortably longer than fifty characters in total.",
+		ImageURL:    "https://example.com/image.jpg",
+		SiteName:    "Reuters",
+		WordCount:   2500,
+		LikesCount:  20,
+		CreatedAt:   now.Add(-2 * time.Hour),
+	}
+
+	rank := func(name string, article models.Article, signals Signals) float64 {
+		ranker := Get(name)
+		signals.Now = now
+		return ranker.Rank(ranker.Explain(article, signals))
+	}
+
+	assert.Greater(t, rank(Engagement, viral, Signals{}), rank(Engagement, longRead, Signals{}))
+	assert.Greater(t, rank(Editorial, longRead, Signals{}), rank(Editorial, viral, Signals{}))
+
+	// Follow-graph matches the default ranker until follows shared the story
+	assert.InDelta(t, rank(Default, longRead, Signals{}), rank(FollowGraph, longRead, Signals{}), 1e-9)
+	assert.InDelta(t, rank(Default, longRead, Signals{})+0.2, rank(FollowGraph, longRead, Signals{FollowedSharers: 2}), 1e-9)
+	assert.InDelta(t, rank(Default, longRead, Signals{})+0.3, rank(FollowGraph, longRead, Signals{FollowedSharers: 10}), 1e-9)
+}
+
+func TestAverageSourceQuality_SpamPenalty(t *testing.T) {
+	article := models.Article{SourceArticles: []models.SourceArticle{
+		{Source: models.Source{QualityScore: 0.8}},
+		{Source: models.Source{QualityScore: 0.8, SpamStatus: models.SpamFlagged}},
+	}}
+	assert.InDelta(t, (0.8+0.8*SpamPenalty)/2, AverageSourceQuality(article, ReplyLinkPolicy{}), 1e-9)
+
+	article.SourceArticles[1].Source.SpamStatus = models.SpamCleared
+	assert.InDelta(t, 0.8, AverageSourceQuality(article, ReplyLinkPolicy{}), 1e-9, "cleared sources aren't penalized")
+}
+
+func TestAverageSourceQuality_ShareType(t *testing.T) {
+	article := models.Article{SourceArticles: []models.SourceArticle{
+		{ShareType: models.ShareTypePost, Source: models.Source{QualityScore: 0.8}},
+		{ShareType: models.ShareTypeRepost, Source: models.Source{QualityScore: 0.8}},
+	}}
+	assert.InDelta(t, (0.8+0.8*ShareTypeWeights[models.ShareTypeRepost])/2, AverageSourceQuality(article, ReplyLinkPolicy{}), 1e-9)
+
+	article.SourceArticles[1].ShareType = ""
+	assert.InDelta(t, 0.8, AverageSourceQuality(article, ReplyLinkPolicy{}), 1e-9, "shares without a type count as posts")
+}
+
+func TestDefaultRanker_ReplyLinks(t *testing.T) {
+	now := time.Now()
+	article := models.Article{
+		CreatedAt: now,
+		SourceArticles: []models.SourceArticle{
+			{ShareType: models.ShareTypePost, Source: models.Source{QualityScore: 0.5}},
+			{ShareType: models.ShareTypeReply, Source: models.Source{QualityScore: 0.5}},
+			{ShareType: models.ShareTypeReply, Source: models.Source{QualityScore: 0.9}},
+		},
+	}
+
+	keep := Get(Default).Explain(article, Signals{Now: now, ReplyLinks: &ReplyLinkPolicy{Mode: ReplyLinksKeep}})
+	assert.InDelta(t, (0.5+0.5+0.9)/3*0.4, keep.SourceQuality, 1e-9)
+	assert.Zero(t, keep.ReplyPenalty)
+
+	policy := ReplyLinkPolicy{Mode: ReplyLinksDownrank, Weight: 0.5, TrustedQuality: 0.8}
+	downranked := Get(Default).Explain(article, Signals{Now: now, ReplyLinks: &policy})
+	assert.InDelta(t, (0.5+0.25+0.9)/3*0.4, downranked.SourceQuality, 1e-9, "only the untrusted source's reply is downranked")
+	assert.InDelta(t, keep.SourceQuality-downranked.SourceQuality, downranked.ReplyPenalty, 1e-9)
+}
+
+func TestLoadReplyLinkPolicy(t *testing.T) {
+	t.Setenv("REPLY_LINKS", "skip")
+	t.Setenv("REPLY_LINKS_WEIGHT", "2")
+	t.Setenv("REPLY_LINKS_TRUSTED_QUALITY", "0.7")
+	policy := LoadReplyLinkPolicy()
+	assert.Equal(t, ReplyLinksSkip, policy.Mode)
+	assert.Equal(t, defaultReplyWeight, policy.Weight, "weights above 1 are rejected")
+	assert.Equal(t, 0.7, policy.TrustedQuality)
+
+	assert.True(t, policy.Skips(models.Source{QualityScore: 0.5}))
+	assert.False(t, policy.Skips(models.Source{QualityScore: 0.7}))
+	assert.True(t, policy.Skips(models.Source{QualityScore: 0.9, SpamStatus: models.SpamFlagged}), "spam sources are never trusted")
+
+	t.Setenv("REPLY_LINKS", "drop")
+	assert.Equal(t, ReplyLinksKeep, LoadReplyLinkPolicy().Mode)
+}
+
+func TestPersonalized(t *testing.T) {
+	now := time.Now()
+	article := models.Article{Title: "Rates rise again", Tags: []string{"business", "politics"}, CreatedAt: now.Add(-time.Hour)}
+	base := Get(Engagement)
+	ranker := Personalized(base)
+	assert.Equal(t, Engagement, ranker.Name())
+
+	plain := base.Rank(base.Explain(article, Signals{Now: now}))
+	assert.InDelta(t, plain, ranker.Rank(ranker.Explain(article, Signals{Now: now})), 1e-9, "no preferences, no boost")
+
+	breakdown := ranker.Explain(article, Signals{Now: now, TopicAffinity: map[string]float64{"business": 0.4, "sports": 0.9}})
+	assert.InDelta(t, 0.2, breakdown.TopicAffinity, 1e-9, "the most favored of the article's topics counts")
+	assert.InDelta(t, plain+0.2, ranker.Rank(breakdown), 1e-9)
+
+	assert.Equal(t, Default, Personalized(nil).Name())
+	assert.Equal(t, topicBoostCap, TopicBoost([]string{"sports"}, map[string]float64{"sports": 1}))
+}
+
+func TestBreaking(t *testing.T) {
+	now := time.Now()
+	article := models.Article{Title: "Earthquake strikes", CreatedAt: now.Add(-time.Hour)}
+	assert.Zero(t, Breaking(article, now))
+
+	detected := now.Add(-BreakingBoostDuration / 2)
+	article.BreakingAt = &detected
+	assert.InDelta(t, BreakingBoost/2, Breaking(article, now), 1e-9, "the boost fades linearly")
+
+	breakdown := Get(Default).Explain(article, Signals{Now: now})
+	assert.InDelta(t, BreakingBoost/2, breakdown.Breaking, 1e-9)
+
+	expired := now.Add(-BreakingBoostDuration)
+	article.BreakingAt = &expired
+	assert.Zero(t, Breaking(article, now))
+}
@@ -0,0 +1,99 @@
+package ranking
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"open-news/internal/models"
+)
+
+// How links in replies are treated, set with REPLY_LINKS
+const (
+	ReplyLinksKeep     = "keep"     // Replies count like any other share
+	ReplyLinksDownrank = "downrank" // Replies from untrusted sources count at ReplyLinkPolicy.Weight
+	ReplyLinksSkip     = "skip"     // Replies from untrusted sources aren't stored
+)
+
+// defaultReplyWeight and defaultReplyTrustedQuality apply without
+// REPLY_LINKS_WEIGHT and REPLY_LINKS_TRUSTED_QUALITY
+const (
+	defaultReplyWeight         = 0.5
+	defaultReplyTrustedQuality = 0.8
+)
+
+// ReplyLinkPolicy is how links dropped in reply threads count. Sources with a
+// quality score of at least TrustedQuality are exempt, so a newsroom replying
+// with its own story still counts in full.
+type ReplyLinkPolicy struct {
+	Mode           string  // keep, downrank or skip (default: keep)
+	Weight         float64 // Share weight of downranked replies (default: 0.5)
+	TrustedQuality float64 // Quality score from which a source's replies count in full (default: 0.8)
+}
+
+// LoadReplyLinkPolicy reads the reply link settings from the environment
+func LoadReplyLinkPolicy() ReplyLinkPolicy {
+	policy := ReplyLinkPolicy{
+		Mode:           ReplyLinksKeep,
+		Weight:         defaultReplyWeight,
+		TrustedQuality: defaultReplyTrustedQuality,
+	}
+	switch value := os.Getenv("REPLY_LINKS"); value {
+	case "", ReplyLinksKeep:
+	case ReplyLinksDownrank, ReplyLinksSkip:
+		policy.Mode = value
+	default:
+		log.Printf("Invalid REPLY_LINKS %q, using %s", value, ReplyLinksKeep)
+	}
+	if value := os.Getenv("REPLY_LINKS_WEIGHT"); value != "" {
+		if weight, err := strconv.ParseFloat(value, 64); err == nil && weight >= 0 && weight <= 1 {
+			policy.Weight = weight
+		} else {
+			log.Printf("Invalid REPLY_LINKS_WEIGHT %q, using %v", value, defaultReplyWeight)
+		}
+	}
+	if value := os.Getenv("REPLY_LINKS_TRUSTED_QUALITY"); value != "" {
+		if quality, err := strconv.ParseFloat(value, 64); err == nil && quality >= 0 && quality <= 1 {
+			policy.TrustedQuality = quality
+		} else {
+			log.Printf("Invalid REPLY_LINKS_TRUSTED_QUALITY %q, using %v", value, defaultReplyTrustedQuality)
+		}
+	}
+	return policy
+}
+
+var (
+	replyLinksOnce sync.Once
+	replyLinks     ReplyLinkPolicy
+)
+
+// ReplyLinks returns the process-wide reply link policy, read from the
+// environment on first use so values loaded from .env at startup are picked up
+func ReplyLinks() ReplyLinkPolicy {
+	replyLinksOnce.Do(func() {
+		replyLinks = LoadReplyLinkPolicy()
+	})
+	return replyLinks
+}
+
+// Trusted reports whether a source's replies count in full
+func (p ReplyLinkPolicy) Trusted(source models.Source) bool {
+	return source.QualityScore >= p.TrustedQuality && !source.IsSpam()
+}
+
+// Skips reports whether the links in a reply by source are dropped at ingest
+func (p ReplyLinkPolicy) Skips(source models.Source) bool {
+	return p.Mode == ReplyLinksSkip && !p.Trusted(source)
+}
+
+// weight returns how much a share counts towards source quality. Replies by
+// untrusted sources stored before REPLY_LINKS=skip was set are downranked too.
+func (p ReplyLinkPolicy) weight(share models.SourceArticle) float64 {
+	weight := ShareWeight(share.ShareType)
+	downranks := p.Mode == ReplyLinksDownrank || p.Mode == ReplyLinksSkip
+	if share.ShareType == models.ShareTypeReply && downranks && !p.Trusted(share.Source) {
+		weight *= p.Weight
+	}
+	return weight
+}
@@ -0,0 +1,126 @@
+package ranking
+
+import (
+	"math"
+	"time"
+
+	"open-news/internal/domains"
+	"open-news/internal/metadata"
+	"open-news/internal/models"
+)
+
+// SpamPenalty scales the quality of sources flagged as spam, so what they share
+// ranks below what reputable sources share
+const SpamPenalty = 0.25
+
+// ShareTypeWeights scales a source's quality by how it shared an article: a
+// repost passes a link along without the source writing anything about it
+var ShareTypeWeights = map[string]float64{
+	models.ShareTypePost:   1.0,
+	models.ShareTypeQuote:  1.0,
+	models.ShareTypeRepost: 0.8,
+	models.ShareTypeReply:  1.0,
+}
+
+// ShareWeight returns the weight of a share type, 1 for shares stored before
+// share types were recorded
+func ShareWeight(shareType string) float64 {
+	if weight, ok := ShareTypeWeights[shareType]; ok {
+		return weight
+	}
+	return 1.0
+}
+
+// AverageSourceQuality is the mean quality score of the sources that shared an
+// article, weighted by share type and, for replies, as the reply link policy
+// says, with spam sources counted at SpamPenalty of their score
+func AverageSourceQuality(article models.Article, replies ReplyLinkPolicy) float64 {
+	if len(article.SourceArticles) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, sa := range article.SourceArticles {
+		quality := sa.Source.QualityScore * replies.weight(sa)
+		if sa.Source.IsSpam() {
+			quality *= SpamPenalty
+		}
+		total += quality
+	}
+	return total / float64(len(article.SourceArticles))
+}
+
+// ContentQuality evaluates content quality on a 0-1 scale
+func ContentQuality(article models.Article) float64 {
+	var score float64 = 0.5
+
+	// Word count bonus (articles with good length get bonus)
+	if article.WordCount >= 300 && article.WordCount <= 3000 {
+		score += 0.2
+	} else if article.WordCount >= 150 {
+		score += 0.1
+	}
+
+	// Title and description quality
+	if len(article.Title) > 10 && len(article.Title) < 200 {
+		score += 0.1
+	}
+	if len(article.Description) > 50 {
+		score += 0.1
+	}
+
+	// Has media (image)
+	if article.ImageURL != "" {
+		score += 0.1
+	}
+
+	// Marked up as news, weighted by how surely its schema.org type means news
+	score += 0.1 * metadata.SchemaTypeWeights[article.SchemaType]
+
+	return math.Min(score, 1.0)
+}
+
+// DomainReputation is the reputation score of an article's site from the domains
+// table, or domains.DefaultScore for sites that aren't listed
+func DomainReputation(domain *models.Domain) float64 {
+	if domain == nil {
+		return domains.DefaultScore
+	}
+	return domain.Score
+}
+
+// BreakingBoost is the boost a story gets when it is marked breaking, fading
+// linearly to nothing over BreakingBoostDuration
+const (
+	BreakingBoost         = 0.3
+	BreakingBoostDuration = 3 * time.Hour
+)
+
+// Breaking returns the boost an article marked breaking has left at now
+func Breaking(article models.Article, now time.Time) float64 {
+	if article.BreakingAt == nil {
+		return 0
+	}
+	elapsed := now.Sub(*article.BreakingAt)
+	if elapsed < 0 || elapsed >= BreakingBoostDuration {
+		return 0
+	}
+	return BreakingBoost * (1 - float64(elapsed)/float64(BreakingBoostDuration))
+}
+
+// Trending returns an article's engagement velocity, age decay factor and resulting trending score
+func Trending(article models.Article, now time.Time) (velocity, decayFactor, trendingScore float64) {
+	hoursSinceCreated := now.Sub(article.CreatedAt).Hours()
+
+	// Decay factor: articles lose trending value over time
+	decayFactor = math.Exp(-hoursSinceCreated / 24.0) // Half-life of 24 hours
+
+	// Engagement velocity (engagement per hour)
+	totalEngagement := float64(article.LikesCount + article.RepostsCount + article.SharesCount)
+	velocity = totalEngagement / math.Max(hoursSinceCreated, 1.0)
+
+	// Trending score based on velocity and decay
+	trendingScore = velocity * decayFactor / 10.0 // Scale down
+
+	return velocity, decayFactor, math.Min(trendingScore, 1.0)
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultAdminSessionTTL is how long an admin stays signed in
+	defaultAdminSessionTTL = 24 * time.Hour
+
+	// minAdminPasswordLength is the shortest password an admin account accepts
+	minAdminPasswordLength = 8
+)
+
+var (
+	// ErrInvalidCredentials is returned for an unknown username, wrong password or disabled account
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrInvalidSession is returned when a session token is unknown or expired
+	ErrInvalidSession = errors.New("invalid or expired session")
+
+	// ErrInvalidAdminRole is returned for a role that isn't viewer, moderator or admin
+	ErrInvalidAdminRole = errors.New("role must be viewer, moderator or admin")
+
+	// ErrWeakPassword is returned for a password shorter than minAdminPasswordLength
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minAdminPasswordLength)
+
+	// ErrLastAdmin is returned when a change would leave no active admin account
+	ErrLastAdmin = errors.New("at least one active admin account is required")
+)
+
+// AdminUserService manages admin accounts and their sign-in sessions. Its
+// accounts are those of one tenant: the default tenant unless ForTenant
+// selected another.
+type AdminUserService struct {
+	db         *gorm.DB
+	sessionTTL time.Duration
+	tenantID   *uuid.UUID // nil for the default tenant
+}
+
+// NewAdminUserService creates a new admin user service. Sessions last
+// ADMIN_SESSION_TTL_HOURS (default 24).
+func NewAdminUserService(db *gorm.DB) *AdminUserService {
+	sessionTTL := defaultAdminSessionTTL
+	if value := os.Getenv("ADMIN_SESSION_TTL_HOURS"); value != "" {
+		if hours, err := strconv.Atoi(value); err == nil && hours > 0 {
+			sessionTTL = time.Duration(hours) * time.Hour
+		} else {
+			log.Printf("Invalid ADMIN_SESSION_TTL_HOURS %q, using %v", value, defaultAdminSessionTTL)
+		}
+	}
+
+	return &AdminUserService{db: db, sessionTTL: sessionTTL}
+}
+
+// ForTenant returns a service managing the admin accounts of a tenant
+func (s *AdminUserService) ForTenant(tenantID uuid.UUID) *AdminUserService {
+	scoped := *s
+	scoped.tenantID = &tenantID
+	return &scoped
+}
+
+// tenant returns the ID of the service's tenant, or nil on databases without
+// tenants, such as test databases migrating only some models
+func (s *AdminUserService) tenant() *uuid.UUID {
+	if s.tenantID != nil {
+		return s.tenantID
+	}
+	return models.DefaultTenantID(s.db)
+}
+
+// SessionTTL is how long a new session lasts
+func (s *AdminUserService) SessionTTL() time.Duration {
+	return s.sessionTTL
+}
+
+// EnsureBootstrapAdmin creates the first admin account when the tenant has
+// none, so a new install can sign in. It uses ADMIN_USERNAME (default "admin") and
+// ADMIN_PASSWORD; without a password, a random one is generated and logged once.
+func (s *AdminUserService) EnsureBootstrapAdmin() error {
+	var count int64
+	if err := scopedToTenant(s.db.Model(&models.AdminUser{}), s.tenant()).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count admin accounts: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	username := os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		username = "admin"
+	}
+	password := os.Getenv("ADMIN_PASSWORD")
+	generated := password == ""
+	if generated {
+		secret := make([]byte, 12)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate admin password: %w", err)
+		}
+		password = hex.EncodeToString(secret)
+	}
+
+	if _, err := s.CreateUser(username, password, models.AdminRoleAdmin); err != nil {
+		return err
+	}
+	if generated {
+		log.Printf("🔑 Created admin account %q with generated password %s - change it or set ADMIN_PASSWORD", username, password)
+	} else {
+		log.Printf("🔑 Created admin account %q from ADMIN_PASSWORD", username)
+	}
+	return nil
+}
+
+// CreateUser creates an admin account with a bcrypt-hashed password
+func (s *AdminUserService) CreateUser(username, password, role string) (*models.AdminUser, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+	if !models.IsValidAdminRole(role) {
+		return nil, ErrInvalidAdminRole
+	}
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.AdminUser{
+		TenantID:     s.tenant(),
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		IsActive:     true,
+	}
+	if err := s.db.Create(user).Error; err != nil {
+		return nil, fmt.Errorf("failed to create admin account: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns the tenant's admin accounts ordered by username
+func (s *AdminUserService) ListUsers() ([]models.AdminUser, error) {
+	var users []models.AdminUser
+	err := scopedToTenant(s.db, s.tenant()).Order("username").Find(&users).Error
+	return users, err
+}
+
+// SetRole changes an account's role
+func (s *AdminUserService) SetRole(id uuid.UUID, role string) error {
+	if !models.IsValidAdminRole(role) {
+		return ErrInvalidAdminRole
+	}
+	tenantID := s.tenant()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := scopedToTenant(tx, tenantID).Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+		if user.Role == models.AdminRoleAdmin && role != models.AdminRoleAdmin {
+			if err := ensureAnotherAdmin(tx, user); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&user).Update("role", role).Error
+	})
+}
+
+// SetActive enables or disables an account. Disabling signs it out everywhere.
+func (s *AdminUserService) SetActive(id uuid.UUID, active bool) error {
+	tenantID := s.tenant()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.AdminUser
+		if err := scopedToTenant(tx, tenantID).Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+		if !active && user.Role == models.AdminRoleAdmin {
+			if err := ensureAnotherAdmin(tx, user); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user).Update("is_active", active).Error; err != nil {
+			return err
+		}
+		if !active {
+			return tx.Where("admin_user_id = ?", id).Delete(&models.AdminSession{}).Error
+		}
+		return nil
+	})
+}
+
+// SetPassword replaces an account's password and signs it out everywhere
+func (s *AdminUserService) SetPassword(id uuid.UUID, password string) error {
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return err
+	}
+	tenantID := s.tenant()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := scopedToTenant(tx.Model(&models.AdminUser{}), tenantID).Where("id = ?", id).Update("password_hash", hash)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("admin_user_id = ?", id).Delete(&models.AdminSession{}).Error
+	})
+}
+
+// SignIn checks a username and password and starts a session, returning its token
+func (s *AdminUserService) SignIn(username, password string) (string, *models.AdminUser, error) {
+	var user models.AdminUser
+	err := scopedToTenant(s.db, s.tenant()).Where("username = ? AND is_active = ?", strings.TrimSpace(username), true).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil, ErrInvalidCredentials
+	} else if err != nil {
+		return "", nil, fmt.Errorf("failed to look up admin account: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+
+	now := time.Now()
+	session := models.AdminSession{
+		AdminUserID: user.ID,
+		TokenHash:   hashSessionToken(token),
+		ExpiresAt:   now.Add(s.sessionTTL),
+	}
+	if err := s.db.Create(&session).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	s.db.Model(&user).Update("last_login_at", now)
+
+	// Clear out expired sessions while we're here
+	s.db.Where("expires_at < ?", now).Delete(&models.AdminSession{})
+
+	return token, &user, nil
+}
+
+// ValidateSession returns the active account of the tenant a session token belongs to
+func (s *AdminUserService) ValidateSession(token string) (*models.AdminUser, error) {
+	if token == "" {
+		return nil, ErrInvalidSession
+	}
+
+	var session models.AdminSession
+	err := s.db.Preload("AdminUser").
+		Where("token_hash = ? AND expires_at > ?", hashSessionToken(token), time.Now()).
+		First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrInvalidSession
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up session: %w", err)
+	}
+	if !session.AdminUser.IsActive {
+		return nil, ErrInvalidSession
+	}
+	// Sessions only sign in to the tenant whose account started them
+	if tenantID := s.tenant(); tenantID != nil && (session.AdminUser.TenantID == nil || *session.AdminUser.TenantID != *tenantID) {
+		return nil, ErrInvalidSession
+	}
+	return &session.AdminUser, nil
+}
+
+// SignOut ends the session a token belongs to
+func (s *AdminUserService) SignOut(token string) error {
+	return s.db.Where("token_hash = ?", hashSessionToken(token)).Delete(&models.AdminSession{}).Error
+}
+
+// ensureAnotherAdmin returns ErrLastAdmin unless the tenant of user has an
+// active admin other than user
+func ensureAnotherAdmin(tx *gorm.DB, user models.AdminUser) error {
+	var others int64
+	if err := scopedToTenant(tx.Model(&models.AdminUser{}), user.TenantID).
+		Where("id <> ? AND role = ? AND is_active = ?", user.ID, models.AdminRoleAdmin, true).
+		Count(&others).Error; err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// scopedToTenant returns a query limited to the rows of a tenant
+func scopedToTenant(db *gorm.DB, tenantID *uuid.UUID) *gorm.DB {
+	if tenantID == nil {
+		return db
+	}
+	return db.Where("tenant_id = ?", *tenantID)
+}
+
+// hashSessionToken returns the hex SHA-256 of a session token
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashAdminPassword checks a password's length and returns its bcrypt hash
+func hashAdminPassword(password string) (string, error) {
+	if len(password) < minAdminPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
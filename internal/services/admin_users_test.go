@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminUser_HasRole(t *testing.T) {
+	moderator := models.AdminUser{Role: models.AdminRoleModerator, IsActive: true}
+	assert.True(t, moderator.HasRole(models.AdminRoleViewer))
+	assert.True(t, moderator.HasRole(models.AdminRoleModerator))
+	assert.False(t, moderator.HasRole(models.AdminRoleAdmin))
+	assert.False(t, moderator.HasRole("superuser"))
+
+	disabled := models.AdminUser{Role: models.AdminRoleAdmin}
+	assert.False(t, disabled.HasRole(models.AdminRoleViewer))
+}
+
+func TestAdminUserService_Sessions(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AdminUser{}, &models.AdminSession{}))
+	db.Exec("DELETE FROM admin_sessions")
+	db.Exec("DELETE FROM admin_users")
+
+	s := NewAdminUserService(db)
+
+	_, err := s.CreateUser("editor", "short", models.AdminRoleModerator)
+	assert.ErrorIs(t, err, ErrWeakPassword)
+	_, err = s.CreateUser("editor", "long enough", "owner")
+	assert.ErrorIs(t, err, ErrInvalidAdminRole)
+
+	user, err := s.CreateUser("editor", "long enough", models.AdminRoleModerator)
+	require.NoError(t, err)
+	assert.NotEqual(t, "long enough", user.PasswordHash)
+
+	_, _, err = s.SignIn("editor", "wrong password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	token, signedIn, err := s.SignIn("editor", "long enough")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, signedIn.ID)
+
+	current, err := s.ValidateSession(token)
+	require.NoError(t, err)
+	assert.Equal(t, "editor", current.Username)
+
+	// Expired sessions are rejected
+	db.Model(&models.AdminSession{}).Where("admin_user_id = ?", user.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	_, err = s.ValidateSession(token)
+	assert.ErrorIs(t, err, ErrInvalidSession)
+
+	// Disabling an account ends its sessions
+	token, _, err = s.SignIn("editor", "long enough")
+	require.NoError(t, err)
+	require.NoError(t, s.SetActive(user.ID, false))
+	_, err = s.ValidateSession(token)
+	assert.ErrorIs(t, err, ErrInvalidSession)
+	_, _, err = s.SignIn("editor", "long enough")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	db.Exec("DELETE FROM admin_sessions")
+	db.Exec("DELETE FROM admin_users")
+}
+
+func TestAdminUserService_KeepsOneAdmin(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AdminUser{}, &models.AdminSession{}))
+	db.Exec("DELETE FROM admin_sessions")
+	db.Exec("DELETE FROM admin_users")
+
+	t.Setenv("ADMIN_USERNAME", "owner")
+	t.Setenv("ADMIN_PASSWORD", "bootstrap-password")
+	s := NewAdminUserService(db)
+	require.NoError(t, s.EnsureBootstrapAdmin())
+	require.NoError(t, s.EnsureBootstrapAdmin()) // No-op once an account exists
+
+	users, err := s.ListUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	owner := users[0]
+	assert.Equal(t, models.AdminRoleAdmin, owner.Role)
+
+	assert.ErrorIs(t, s.SetRole(owner.ID, models.AdminRoleViewer), ErrLastAdmin)
+	assert.ErrorIs(t, s.SetActive(owner.ID, false), ErrLastAdmin)
+
+	_, err = s.CreateUser("second", "another-password", models.AdminRoleAdmin)
+	require.NoError(t, err)
+	assert.NoError(t, s.SetRole(owner.ID, models.AdminRoleViewer))
+
+	db.Exec("DELETE FROM admin_sessions")
+	db.Exec("DELETE FROM admin_users")
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultImpressionSampleRate is the share of anonymous feed requests whose impressions are recorded
+const defaultImpressionSampleRate = 0.1
+
+// AnalyticsService records reader engagement with feeds and aggregates it for reporting
+type AnalyticsService struct {
+	db                   *gorm.DB
+	impressionSampleRate float64
+}
+
+// NewAnalyticsService creates a new analytics service.
+// IMPRESSION_SAMPLE_RATE (0-1, default 0.1) sets the share of anonymous requests whose
+// impressions are recorded; requests from known users are always recorded.
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	sampleRate := defaultImpressionSampleRate
+	if value := os.Getenv("IMPRESSION_SAMPLE_RATE"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 && parsed <= 1 {
+			sampleRate = parsed
+		} else {
+			log.Printf("Invalid IMPRESSION_SAMPLE_RATE %q, using %.2f", value, sampleRate)
+		}
+	}
+	return &AnalyticsService{db: db, impressionSampleRate: sampleRate}
+}
+
+// ClickStats is the click count for one article, source or feed, with the
+// click-through rate against estimated impressions
+type ClickStats struct {
+	Key         string  `json:"key"`   // Article ID, source ID or feed name
+	Label       string  `json:"label"` // Article title, source handle or feed name
+	Clicks      int64   `json:"clicks"`
+	Impressions float64 `json:"impressions"` // Estimated from sampled impressions
+	CTR         float64 `json:"ctr"`         // Clicks / impressions, 0 when there are no impressions
+}
+
+// ServedItem is a feed item that was served to a reader
+type ServedItem struct {
+	ArticleID uuid.UUID
+	SourceID  uuid.UUID // uuid.Nil when the item has no source
+	Position  int
+}
+
+// ServedFeed is a page of feed items served in one request
+type ServedFeed struct {
+	UserID  *uuid.UUID // nil for anonymous requests
+	Feed    string     // e.g. "global" or a feed rkey
+	Surface string     // "skeleton", "web", "widget" or "api"
+	Items   []ServedItem
+}
+
+// ClickReport aggregates clicks over a time window
+type ClickReport struct {
+	Since    time.Time    `json:"since"`
+	Total    int64        `json:"total"`
+	Articles []ClickStats `json:"articles"`
+	Sources  []ClickStats `json:"sources"`
+	Feeds    []ClickStats `json:"feeds"`
+}
+
+// RecordClick stores a click on a feed item
+func (s *AnalyticsService) RecordClick(click *models.Click) error {
+	if err := s.db.Create(click).Error; err != nil {
+		return fmt.Errorf("failed to record click: %w", err)
+	}
+	return nil
+}
+
+// RecordImpressions stores the items of a served feed in the background so feed
+// responses aren't slowed down. Anonymous requests are sampled.
+func (s *AnalyticsService) RecordImpressions(served ServedFeed) {
+	impressions := s.impressionsFor(served, rand.Float64())
+	if len(impressions) == 0 {
+		return
+	}
+
+	go func() {
+		if err := s.db.CreateInBatches(impressions, 100).Error; err != nil {
+			log.Printf("Failed to record %d impressions for feed %s: %v", len(impressions), served.Feed, err)
+		}
+	}()
+}
+
+// impressionsFor converts a served feed to impression rows, or returns nil when the
+// request isn't sampled. roll is a uniform random number in [0, 1).
+func (s *AnalyticsService) impressionsFor(served ServedFeed, roll float64) []models.Impression {
+	sampleRate := 1.0
+	if served.UserID == nil {
+		sampleRate = s.impressionSampleRate
+	}
+	if len(served.Items) == 0 || roll >= sampleRate {
+		return nil
+	}
+
+	impressions := make([]models.Impression, len(served.Items))
+	for i, item := range served.Items {
+		impressions[i] = models.Impression{
+			ArticleID:  item.ArticleID,
+			UserID:     served.UserID,
+			Feed:       served.Feed,
+			Surface:    served.Surface,
+			Position:   item.Position,
+			SampleRate: sampleRate,
+		}
+		if item.SourceID != uuid.Nil {
+			sourceID := item.SourceID
+			impressions[i].SourceID = &sourceID
+		}
+	}
+	return impressions
+}
+
+// ClickReport returns click totals and click-through rates per article, source and feed since the given time.
+// limit caps the number of articles and sources returned.
+func (s *AnalyticsService) ClickReport(since time.Time, limit int) (*ClickReport, error) {
+	report := &ClickReport{
+		Since:    since,
+		Articles: []ClickStats{},
+		Sources:  []ClickStats{},
+		Feeds:    []ClickStats{},
+	}
+
+	if err := s.db.Model(&models.Click{}).Where("created_at > ?", since).Count(&report.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	err := s.db.Table("clicks").
+		Select("clicks.article_id AS key, articles.title AS label, COUNT(*) AS clicks").
+		Joins("JOIN articles ON articles.id = clicks.article_id").
+		Where("clicks.created_at > ?", since).
+		Group("clicks.article_id, articles.title").
+		Order("clicks DESC").
+		Limit(limit).
+		Scan(&report.Articles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate clicks by article: %w", err)
+	}
+
+	err = s.db.Table("clicks").
+		Select("clicks.source_id AS key, sources.handle AS label, COUNT(*) AS clicks").
+		Joins("JOIN sources ON sources.id = clicks.source_id").
+		Where("clicks.created_at > ?", since).
+		Group("clicks.source_id, sources.handle").
+		Order("clicks DESC").
+		Limit(limit).
+		Scan(&report.Sources).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate clicks by source: %w", err)
+	}
+
+	err = s.db.Table("clicks").
+		Select("clicks.feed AS key, clicks.feed AS label, COUNT(*) AS clicks").
+		Where("clicks.created_at > ?", since).
+		Group("clicks.feed").
+		Order("clicks DESC").
+		Scan(&report.Feeds).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate clicks by feed: %w", err)
+	}
+
+	for _, group := range []struct {
+		column string
+		stats  []ClickStats
+	}{
+		{"article_id", report.Articles},
+		{"source_id", report.Sources},
+		{"feed", report.Feeds},
+	} {
+		impressions, err := s.impressionCounts(group.column, since)
+		if err != nil {
+			return nil, err
+		}
+		for i := range group.stats {
+			group.stats[i].Impressions = impressions[group.stats[i].Key]
+			group.stats[i].CTR = clickThroughRate(group.stats[i].Clicks, group.stats[i].Impressions)
+		}
+	}
+
+	return report, nil
+}
+
+// impressionCounts estimates impressions since the given time, grouped by an impressions column
+func (s *AnalyticsService) impressionCounts(column string, since time.Time) (map[string]float64, error) {
+	var rows []struct {
+		Key         string
+		Impressions float64
+	}
+	err := s.db.Table("impressions").
+		Select(column+" AS key, SUM(1.0 / NULLIF(sample_rate, 0)) AS impressions").
+		Where("created_at > ? AND "+column+" IS NOT NULL", since).
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate impressions by %s: %w", column, err)
+	}
+
+	counts := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Impressions
+	}
+	return counts, nil
+}
+
+// clickThroughRate returns clicks per impression, capped at 1
+func clickThroughRate(clicks int64, impressions float64) float64 {
+	if impressions <= 0 {
+		return 0
+	}
+	if ctr := float64(clicks) / impressions; ctr < 1 {
+		return ctr
+	}
+	return 1
+}
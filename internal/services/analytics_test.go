@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsService_ImpressionsFor(t *testing.T) {
+	service := &AnalyticsService{impressionSampleRate: 0.25}
+	userID := uuid.New()
+	sourceID := uuid.New()
+
+	served := ServedFeed{
+		Feed:    "open-news-global",
+		Surface: "skeleton",
+		Items: []ServedItem{
+			{ArticleID: uuid.New(), SourceID: sourceID, Position: 1},
+			{ArticleID: uuid.New(), Position: 2},
+		},
+	}
+
+	t.Run("anonymous requests are sampled", func(t *testing.T) {
+		assert.Nil(t, service.impressionsFor(served, 0.5))
+
+		impressions := service.impressionsFor(served, 0.1)
+		require.Len(t, impressions, 2)
+		assert.Equal(t, 0.25, impressions[0].SampleRate)
+		assert.Nil(t, impressions[0].UserID)
+		assert.Equal(t, sourceID, *impressions[0].SourceID)
+		assert.Nil(t, impressions[1].SourceID)
+		assert.Equal(t, 2, impressions[1].Position)
+		assert.Equal(t, "open-news-global", impressions[1].Feed)
+	})
+
+	t.Run("signed-in requests are always recorded", func(t *testing.T) {
+		served := served
+		served.UserID = &userID
+
+		impressions := service.impressionsFor(served, 0.99)
+		require.Len(t, impressions, 2)
+		assert.Equal(t, 1.0, impressions[0].SampleRate)
+		assert.Equal(t, userID, *impressions[0].UserID)
+	})
+
+	t.Run("empty feeds record nothing", func(t *testing.T) {
+		assert.Nil(t, service.impressionsFor(ServedFeed{UserID: &userID}, 0))
+	})
+}
+
+func TestClickThroughRate(t *testing.T) {
+	assert.Equal(t, 0.0, clickThroughRate(5, 0))
+	assert.Equal(t, 0.05, clickThroughRate(5, 100))
+	assert.Equal(t, 1.0, clickThroughRate(12, 10), "sampling noise can't push CTR above 1")
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks widget API keys so they are recognisable in logs and config
+const apiKeyPrefix = "onk_"
+
+// ErrInvalidAPIKey is returned when a key is unknown or revoked
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService manages API keys for third-party widget embedders
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// CreateAPIKey generates a new key and returns its plaintext value, which is not stored
+func (s *APIKeyService) CreateAPIKey(name, allowedOrigins string) (string, *models.APIKey, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &models.APIKey{
+		Name:           name,
+		KeyHash:        hashAPIKey(plaintext),
+		Prefix:         plaintext[:len(apiKeyPrefix)+8],
+		AllowedOrigins: allowedOrigins,
+		IsActive:       true,
+	}
+	if err := s.db.Create(apiKey).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return plaintext, apiKey, nil
+}
+
+// ValidateAPIKey looks up an active key and records its usage
+func (s *APIKeyService) ValidateAPIKey(plaintext string) (*models.APIKey, error) {
+	if plaintext == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var apiKey models.APIKey
+	err := s.db.Where("key_hash = ? AND is_active = ?", hashAPIKey(plaintext), true).First(&apiKey).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	now := time.Now()
+	s.db.Model(&apiKey).UpdateColumns(map[string]interface{}{
+		"request_count": gorm.Expr("request_count + 1"),
+		"last_used_at":  now,
+	})
+
+	return &apiKey, nil
+}
+
+// ListAPIKeys returns all keys, newest first
+func (s *APIKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.db.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey deactivates a key
+func (s *APIKeyService) RevokeAPIKey(id uuid.UUID) error {
+	result := s.db.Model(&models.APIKey{}).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
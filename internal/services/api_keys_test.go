@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAPIKey(t *testing.T) {
+	service := NewAPIKeyService(setupSQLiteTestDB(t))
+
+	plaintext, created, err := service.CreateAPIKey("Example", "https://example.com")
+	require.NoError(t, err)
+	assert.Contains(t, plaintext, apiKeyPrefix)
+	assert.NotEqual(t, plaintext, created.KeyHash, "only the hash is stored")
+
+	apiKey, err := service.ValidateAPIKey(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, apiKey.ID)
+
+	for _, key := range []string{"", "onk_wrong", plaintext + "x"} {
+		_, err = service.ValidateAPIKey(key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, key)
+	}
+
+	require.NoError(t, service.RevokeAPIKey(created.ID))
+	_, err = service.ValidateAPIKey(plaintext)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "revoked keys are refused")
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	articlemeta "open-news/internal/metadata"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// desktopURL returns the canonical page an AMP or mobile page names, or pageURL
+// for other pages and ones naming a page on another site
+func desktopURL(pageURL string, metadata *ArticleMetadata) string {
+	if articlemeta.IsAlternateURL(pageURL) && metadata.CanonicalURL != "" && articlemeta.SameSite(metadata.CanonicalURL, pageURL) {
+		return metadata.CanonicalURL
+	}
+	return pageURL
+}
+
+// mergeVersions folds AMP and mobile versions of an article into its canonical
+// article, given the metadata just fetched from its page. When the article is
+// itself a version of a stored canonical article, it's merged into that one,
+// which is returned; when the canonical page isn't stored, the article moves to
+// its URL. Otherwise a stored article for the page's rel=amphtml version is
+// merged into it, and nil is returned.
+func (as *ArticlesService) mergeVersions(article *models.Article, metadata *ArticleMetadata) (*models.Article, error) {
+	if desktop := desktopURL(article.URL, metadata); desktop != article.URL {
+		var canonical models.Article
+		err := as.db.Where("url = ?", desktop).First(&canonical).Error
+		if err == gorm.ErrRecordNotFound {
+			log.Printf("🔀 Moving %s to its canonical URL %s", article.URL, desktop)
+			article.URL = desktop
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up canonical article: %w", err)
+		}
+		if err := as.mergeArticle(article.ID, canonical.ID); err != nil {
+			return nil, err
+		}
+		log.Printf("🔀 Merged %s into its canonical article %s", article.URL, canonical.URL)
+		// Reload the engagement the merge added
+		if err := as.db.Where("id = ?", canonical.ID).First(&canonical).Error; err != nil {
+			return nil, err
+		}
+		return &canonical, nil
+	}
+
+	if metadata.AMPURL == "" || metadata.AMPURL == article.URL {
+		return nil, nil
+	}
+	var amp models.Article
+	err := as.db.Where("url = ?", metadata.AMPURL).First(&amp).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up AMP article: %w", err)
+	}
+	if err := as.mergeArticle(amp.ID, article.ID); err != nil {
+		return nil, err
+	}
+	log.Printf("🔀 Merged AMP version %s into %s", amp.URL, article.URL)
+	return nil, nil
+}
+
+// mergeArticle moves the shares, clicks and impressions of one article to
+// another, then deletes it. Shares by posts that linked both keep only the one
+// of the article merged into, so their engagement is counted once.
+func (as *ArticlesService) mergeArticle(from, into uuid.UUID) error {
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE source_articles SET article_id = ?
+			WHERE article_id = ? AND post_uri NOT IN (SELECT post_uri FROM source_articles WHERE article_id = ?)`,
+			into, from, into).Error; err != nil {
+			return fmt.Errorf("failed to move shares: %w", err)
+		}
+		if err := models.AggregateEngagement(tx, into); err != nil {
+			return fmt.Errorf("failed to add engagement: %w", err)
+		}
+		if err := tx.Model(&models.Click{}).Where("article_id = ?", from).Update("article_id", into).Error; err != nil {
+			return fmt.Errorf("failed to move clicks: %w", err)
+		}
+		if err := tx.Model(&models.Impression{}).Where("article_id = ?", from).Update("article_id", into).Error; err != nil {
+			return fmt.Errorf("failed to move impressions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return as.deleteArticleAndReferences(from)
+}
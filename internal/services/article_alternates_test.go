@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesktopURL(t *testing.T) {
+	metadata := &ArticleMetadata{CanonicalURL: "https://www.example.com/world/story"}
+
+	assert.Equal(t, "https://www.example.com/world/story", desktopURL("https://www.example.com/world/story/amp", metadata))
+	assert.Equal(t, "https://www.example.com/world/story", desktopURL("https://m.example.com/world/story", metadata))
+	assert.Equal(t, "https://www.example.com/other", desktopURL("https://www.example.com/other", metadata), "canonical pages stay where they are")
+	assert.Equal(t, "https://amp.news.example/story", desktopURL("https://amp.news.example/story", metadata), "canonical pages on other sites are ignored")
+	assert.Equal(t, "https://m.example.com/story", desktopURL("https://m.example.com/story", &ArticleMetadata{}))
+}
+
+func TestCanonicalizeURL_AMPCache(t *testing.T) {
+	assert.Equal(t, "https://www.example.com/story/amp?id=7",
+		canonicalizeURL("https://www-example-com.cdn.ampproject.org/c/s/www.example.com/story/amp?id=7&utm_source=twitter"))
+}
+
+func TestArticlesService_MergeVersions(t *testing.T) {
+	db := setupTestDB(t)
+	as := &ArticlesService{db: db}
+
+	source := models.Source{BlueSkyDID: "did:plc:testmergeversions", Handle: "merge.test"}
+	require.NoError(t, db.Create(&source).Error)
+
+	// The article's likes are those of its last share
+	newArticle := func(url string, likes int, postURIs ...string) models.Article {
+		article := models.Article{URL: url, Title: "Story", LikesCount: likes}
+		require.NoError(t, db.Create(&article).Error)
+		for i, uri := range postURIs {
+			share := models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: uri, PostedAt: time.Now()}
+			if i == len(postURIs)-1 {
+				share.LikesCount = likes
+			}
+			require.NoError(t, db.Create(&share).Error)
+		}
+		return article
+	}
+	shares := func(articleID uuid.UUID) int64 {
+		var count int64
+		db.Model(&models.SourceArticle{}).Where("article_id = ?", articleID).Count(&count)
+		return count
+	}
+
+	t.Run("AMP version merges into the stored canonical article", func(t *testing.T) {
+		canonical := newArticle("https://www.example.com/merge", 3, "at://did:plc:testmergeversions/post/1")
+		amp := newArticle("https://www.example.com/merge/amp", 2, "at://did:plc:testmergeversions/post/1", "at://did:plc:testmergeversions/post/2")
+
+		merged, err := as.mergeVersions(&amp, &ArticleMetadata{CanonicalURL: canonical.URL})
+		require.NoError(t, err)
+		require.NotNil(t, merged)
+		assert.Equal(t, canonical.ID, merged.ID)
+		assert.Equal(t, 5, merged.LikesCount, "the likes of both versions' shares are rolled up")
+		assert.Equal(t, 1, merged.SharesCount, "one source shared both versions")
+		assert.Equal(t, int64(2), shares(canonical.ID), "the post linking both versions is counted once")
+
+		var remaining int64
+		db.Model(&models.Article{}).Where("id = ?", amp.ID).Count(&remaining)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("AMP version without a stored canonical article moves", func(t *testing.T) {
+		amp := newArticle("https://amp.example.com/moved", 0)
+
+		merged, err := as.mergeVersions(&amp, &ArticleMetadata{CanonicalURL: "https://www.example.com/moved"})
+		require.NoError(t, err)
+		assert.Nil(t, merged)
+		assert.Equal(t, "https://www.example.com/moved", amp.URL)
+	})
+
+	t.Run("stored amphtml version merges into the canonical article", func(t *testing.T) {
+		canonical := newArticle("https://www.example.com/reverse", 0)
+		amp := newArticle("https://www.example.com/reverse.amp.html", 0, "at://did:plc:testmergeversions/post/3")
+
+		merged, err := as.mergeVersions(&canonical, &ArticleMetadata{AMPURL: amp.URL})
+		require.NoError(t, err)
+		assert.Nil(t, merged)
+		assert.Equal(t, int64(1), shares(canonical.ID))
+	})
+
+	db.Where("source_id = ?", source.ID).Delete(&models.SourceArticle{})
+	db.Delete(&source)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBulkArticles caps how many articles one bulk action can select
+const MaxBulkArticles = 200
+
+// BulkArticleResult reports the outcome of a bulk action on articles
+type BulkArticleResult struct {
+	Processed int               `json:"processed"`
+	Failed    map[string]string `json:"failed,omitempty"` // Article ID to error message
+}
+
+// fail records an article the action couldn't be applied to
+func (r *BulkArticleResult) fail(articleID uuid.UUID, err error) {
+	if r.Failed == nil {
+		r.Failed = make(map[string]string)
+	}
+	r.Failed[articleID.String()] = err.Error()
+}
+
+// DeleteArticles deletes articles along with their shares, facts and feed items
+func (as *ArticlesService) DeleteArticles(articleIDs []uuid.UUID) BulkArticleResult {
+	var result BulkArticleResult
+	for _, id := range articleIDs {
+		if err := as.deleteArticleAndReferences(id); err != nil {
+			result.fail(id, err)
+			continue
+		}
+		result.Processed++
+	}
+	log.Printf("🗑️ Bulk deleted %d articles (%d failed)", result.Processed, len(result.Failed))
+	return result
+}
+
+// RescoreArticles recalculates the quality scores of articles
+func (as *ArticlesService) RescoreArticles(articleIDs []uuid.UUID) BulkArticleResult {
+	var result BulkArticleResult
+	scorer := NewQualityScoreService(as.db)
+	for _, id := range articleIDs {
+		if err := scorer.UpdateSingleArticleScore(id.String()); err != nil {
+			result.fail(id, err)
+			continue
+		}
+		result.Processed++
+	}
+	return result
+}
+
+// QueueRefetches queues a refetch_article job per article. Fetching takes up to
+// refetchTimeout per page, too long to do many of while an admin waits.
+func (as *ArticlesService) QueueRefetches(jobs *JobService, articleIDs []uuid.UUID) BulkArticleResult {
+	var result BulkArticleResult
+	now := time.Now()
+	for _, id := range articleIDs {
+		if _, err := jobs.Enqueue(JobTypeRefetchArticle, RefetchArticlePayload{ArticleID: id}, now); err != nil {
+			result.fail(id, err)
+			continue
+		}
+		result.Processed++
+	}
+	return result
+}
+
+// RunRefetchJob is the handler for refetch_article jobs
+func (as *ArticlesService) RunRefetchJob(ctx context.Context, payload RefetchArticlePayload) error {
+	_, err := as.RefetchArticle(ctx, payload.ArticleID)
+	if err == gorm.ErrRecordNotFound {
+		return nil // Deleted since the job was queued
+	}
+	return err
+}
+
+// MarkNotNews flags articles as not news and removes them from stored feeds.
+// Flagged articles are kept so the firehose recognizes their URLs and doesn't
+// ingest them again.
+func (as *ArticlesService) MarkNotNews(articleIDs []uuid.UUID) (int64, error) {
+	var marked int64
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Article{}).Where("id IN ?", articleIDs).Update("is_not_news", true)
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark articles: %w", result.Error)
+		}
+		marked = result.RowsAffected
+
+		if err := tx.Where("article_id IN ?", articleIDs).Delete(&models.FeedItem{}).Error; err != nil {
+			return fmt.Errorf("failed to delete feed items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("🚫 Marked %d articles as not news", marked)
+	return marked, nil
+}
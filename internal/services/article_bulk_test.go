@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticlesService_BulkActions(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.FeedItem{}, &models.Job{}))
+	db.Exec("DELETE FROM feed_items")
+
+	as := &ArticlesService{db: db}
+	feed := models.Feed{Name: "Bulk Test Feed", FeedType: "global"}
+	require.NoError(t, db.Create(&feed).Error)
+	defer db.Delete(&feed)
+
+	newArticle := func(url string) models.Article {
+		article := models.Article{URL: url, Title: "Story", QualityScore: 0.5}
+		require.NoError(t, db.Create(&article).Error)
+		require.NoError(t, db.Create(&models.FeedItem{FeedID: feed.ID, ArticleID: article.ID, Position: 1}).Error)
+		return article
+	}
+
+	t.Run("mark not news", func(t *testing.T) {
+		article := newArticle("https://example.com/bulk-not-news")
+
+		marked, err := as.MarkNotNews([]uuid.UUID{article.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), marked)
+
+		var updated models.Article
+		require.NoError(t, db.First(&updated, "id = ?", article.ID).Error)
+		assert.True(t, updated.IsNotNews)
+
+		var items int64
+		db.Model(&models.FeedItem{}).Where("article_id = ?", article.ID).Count(&items)
+		assert.Zero(t, items)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		article := newArticle("https://example.com/bulk-delete")
+
+		result := as.DeleteArticles([]uuid.UUID{article.ID})
+		assert.Equal(t, 1, result.Processed)
+		assert.Empty(t, result.Failed)
+
+		var remaining int64
+		db.Model(&models.Article{}).Where("id = ?", article.ID).Count(&remaining)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("rescore reports missing articles", func(t *testing.T) {
+		article := newArticle("https://example.com/bulk-rescore")
+		missing := uuid.New()
+
+		result := as.RescoreArticles([]uuid.UUID{article.ID, missing})
+		assert.Equal(t, 1, result.Processed)
+		assert.Contains(t, result.Failed, missing.String())
+	})
+
+	t.Run("refetch queues jobs", func(t *testing.T) {
+		article := newArticle("https://example.com/bulk-refetch")
+		jobs := NewJobService(db)
+
+		result := as.QueueRefetches(jobs, []uuid.UUID{article.ID})
+		assert.Equal(t, 1, result.Processed)
+
+		var queued int64
+		db.Model(&models.Job{}).Where("type = ? AND payload->>'article_id' = ?", JobTypeRefetchArticle, article.ID.String()).Count(&queued)
+		assert.Equal(t, int64(1), queued)
+		db.Where("type = ?", JobTypeRefetchArticle).Delete(&models.Job{})
+	})
+
+	db.Exec("DELETE FROM feed_items")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxEditorialBoost bounds the editorial boost in either direction. A boost of 1
+// outweighs every other quality signal combined.
+const MaxEditorialBoost = 1.0
+
+// ErrInvalidBoost is returned for an editorial boost outside ±MaxEditorialBoost
+var ErrInvalidBoost = fmt.Errorf("editorial boost must be between -%.1f and %.1f", MaxEditorialBoost, MaxEditorialBoost)
+
+// SetPinned pins an article to the top of the global feed or unpins it, recording
+// the change in the audit log. The feed picks the change up when it's next regenerated.
+func (as *ArticlesService) SetPinned(articleID uuid.UUID, pinned bool, actor string) (*models.Article, error) {
+	var article models.Article
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", articleID).First(&article).Error; err != nil {
+			return err
+		}
+		if article.IsPinned == pinned {
+			return nil
+		}
+
+		action := models.AuditActionUnpin
+		var pinnedAt *time.Time
+		if pinned {
+			action = models.AuditActionPin
+			now := time.Now()
+			pinnedAt = &now
+		}
+
+		if err := tx.Model(&article).Updates(map[string]interface{}{
+			"is_pinned": pinned,
+			"pinned_at": pinnedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update pin: %w", err)
+		}
+		article.IsPinned, article.PinnedAt = pinned, pinnedAt
+
+		return recordArticleAudit(tx, article.ID, actor, action, strconv.FormatBool(!pinned), strconv.FormatBool(pinned))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// SetEditorialBoost sets the boost (or, when negative, penalty) added to an
+// article's quality score, rescores the article and records the change in the audit log
+func (as *ArticlesService) SetEditorialBoost(articleID uuid.UUID, boost float64, actor string) (*models.Article, error) {
+	if boost < -MaxEditorialBoost || boost > MaxEditorialBoost {
+		return nil, ErrInvalidBoost
+	}
+
+	var article models.Article
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", articleID).First(&article).Error; err != nil {
+			return err
+		}
+		if article.EditorialBoost == boost {
+			return nil
+		}
+
+		previous := article.EditorialBoost
+		if err := tx.Model(&article).Update("editorial_boost", boost).Error; err != nil {
+			return fmt.Errorf("failed to update editorial boost: %w", err)
+		}
+		return recordArticleAudit(tx, article.ID, actor, models.AuditActionBoost, formatBoost(previous), formatBoost(boost))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := NewQualityScoreService(as.db).UpdateSingleArticleScore(article.ID.String()); err != nil {
+		log.Printf("⚠️ Failed to rescore article %s: %v", article.ID, err)
+	}
+	if err := as.db.Where("id = ?", article.ID).First(&article).Error; err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// ArticleAuditLog returns the editorial changes made to an article, most recent first
+func (as *ArticlesService) ArticleAuditLog(articleID uuid.UUID) ([]models.ArticleAuditLog, error) {
+	var entries []models.ArticleAuditLog
+	err := as.db.Where("article_id = ?", articleID).Order("created_at DESC").Find(&entries).Error
+	return entries, err
+}
+
+// recordArticleAudit stores an audit log entry for an editorial change
+func recordArticleAudit(tx *gorm.DB, articleID uuid.UUID, actor, action, oldValue, newValue string) error {
+	if actor == "" {
+		return errors.New("audit log entries need an actor")
+	}
+	entry := models.ArticleAuditLog{
+		ArticleID: articleID,
+		Actor:     actor,
+		Action:    action,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record audit log entry: %w", err)
+	}
+	log.Printf("📝 %s: %s article %s (%s → %s)", actor, action, articleID, oldValue, newValue)
+	return nil
+}
+
+// formatBoost formats an editorial boost for the audit log
+func formatBoost(boost float64) string {
+	return strconv.FormatFloat(boost, 'f', -1, 64)
+}
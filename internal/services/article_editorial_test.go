@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticlesService_EditorialControls(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ArticleAuditLog{}))
+	db.Exec("DELETE FROM article_audit_logs")
+
+	as := &ArticlesService{db: db}
+	article := models.Article{URL: "https://example.com/editorial", Title: "Story"}
+	require.NoError(t, db.Create(&article).Error)
+
+	t.Run("pin and unpin", func(t *testing.T) {
+		pinned, err := as.SetPinned(article.ID, true, "admin")
+		require.NoError(t, err)
+		assert.True(t, pinned.IsPinned)
+		assert.NotNil(t, pinned.PinnedAt)
+
+		// Pinning again changes nothing and isn't logged
+		_, err = as.SetPinned(article.ID, true, "admin")
+		require.NoError(t, err)
+
+		unpinned, err := as.SetPinned(article.ID, false, "editor")
+		require.NoError(t, err)
+		assert.False(t, unpinned.IsPinned)
+		assert.Nil(t, unpinned.PinnedAt)
+	})
+
+	t.Run("boost rescores", func(t *testing.T) {
+		before := NewQualityScoreService(db).ExplainScores(article).QualityScore
+
+		boosted, err := as.SetEditorialBoost(article.ID, 0.2, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, 0.2, boosted.EditorialBoost)
+		assert.InDelta(t, before+0.2, boosted.QualityScore, 1e-9)
+
+		_, err = as.SetEditorialBoost(article.ID, 1.5, "admin")
+		assert.ErrorIs(t, err, ErrInvalidBoost)
+	})
+
+	entries, err := as.ArticleAuditLog(article.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.AuditActionBoost, entries[0].Action)
+	assert.Equal(t, "0", entries[0].OldValue)
+	assert.Equal(t, "0.2", entries[0].NewValue)
+	assert.Equal(t, models.AuditActionUnpin, entries[1].Action)
+	assert.Equal(t, "editor", entries[1].Actor)
+	assert.Equal(t, models.AuditActionPin, entries[2].Action)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/errreport"
+	"open-news/internal/models"
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+)
+
+// refetchTimeout bounds a manual re-fetch, which an admin is waiting on
+const refetchTimeout = 30 * time.Second
+
+// RefetchArticle fetches an article's page again right away and stores the fresh
+// metadata, clearing earlier fetch errors, then reclassifies and rescores it.
+// A failed fetch is recorded on the returned article rather than returned as an
+// error; the error is for lookups and writes that failed.
+func (as *ArticlesService) RefetchArticle(ctx context.Context, articleID uuid.UUID) (*models.Article, error) {
+	var article models.Article
+	if err := as.db.Where("id = ?", articleID).First(&article).Error; err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, refetchTimeout)
+	defer cancel()
+
+	now := time.Now()
+	metadata, fetchErr := as.ExtractArticleMetadata(ctx, article.URL)
+	applyRefetch(&article, metadata, fetchErr, now)
+	if fetchErr == nil {
+		// AMP and mobile versions are folded into the canonical article
+		canonical, err := as.mergeVersions(&article, metadata)
+		if err != nil {
+			return nil, err
+		}
+		if canonical != nil {
+			article = *canonical
+		}
+	}
+
+	if err := as.db.Save(&article).Error; err != nil {
+		return nil, fmt.Errorf("failed to save article: %w", err)
+	}
+	if fetchErr != nil && errreport.RepeatedFetchFailure(article.FetchRetries) {
+		errreport.Capture(fmt.Errorf("failed to fetch article %d times in a row: %w", article.FetchRetries, fetchErr), errreport.Tags{
+			"article.id":  article.ID.String(),
+			"article.url": article.URL,
+		})
+	}
+
+	if err := NewQualityScoreService(as.db).UpdateSingleArticleScore(article.ID.String()); err != nil {
+		log.Printf("⚠️ Failed to rescore article %s: %v", article.ID, err)
+	}
+	// Reload so the caller sees the new scores
+	if err := as.db.Where("id = ?", article.ID).First(&article).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔄 Re-fetched article %s (reachable: %v)", article.URL, article.IsReachable)
+	return &article, nil
+}
+
+// applyRefetch copies the result of a fetch onto an article. A successful fetch
+// replaces the metadata and clears the error history; a failed one is counted.
+func applyRefetch(article *models.Article, metadata *ArticleMetadata, fetchErr error, now time.Time) {
+	article.LastFetchAt = &now
+
+	if fetchErr != nil {
+		article.IsReachable = false
+		article.FetchError = fetchErr.Error()
+		article.FetchRetries++
+		article.LastFetchError = &now
+		return
+	}
+
+	article.Title = coalesceString(metadata.Title, article.Title)
+	article.Description = coalesceString(metadata.Description, article.Description)
+	article.Author = coalesceString(metadata.Author, article.Author)
+	if len(metadata.Authors) > 0 {
+		article.Authors = metadata.Authors
+	}
+	article.SiteName = coalesceString(metadata.SiteName, article.SiteName)
+	article.ImageURL = coalesceString(metadata.ImageURL, article.ImageURL)
+	if metadata.PublishedAt != nil {
+		article.PublishedAt = metadata.PublishedAt
+		article.DateConfidence = string(metadata.DateConfidence)
+	}
+	article.JSONLDData = metadata.JSONLDData
+	article.SchemaType = metadata.SchemaType
+	article.OGData = metadata.OGData
+	article.HTMLContent = metadata.HTMLContent
+	if metadata.TextContent != article.TextContent {
+		article.FactsExtractedAt = nil // Extract facts from the new text
+		article.SummarizedAt = nil     // and summarize it again
+	}
+	article.TextContent = metadata.TextContent
+	article.WordCount = int(metadata.WordCount)
+	article.ReadingTime = int(metadata.ReadingTime)
+	article.ReadingGrade = metadata.ReadingGrade
+	article.Sentiment = metadata.Sentiment
+	article.Language = metadata.Language
+
+	article.NoArchive = metadata.NoArchive
+	article.IsCached = !metadata.NoArchive
+	article.CachedAt = &now
+	article.IsReachable = true
+	article.FetchError = ""
+	article.FetchRetries = 0
+	article.LastFetchError = nil
+
+	article.Tags = topics.MergeTags(article.Tags, topics.Classify(topics.Input{
+		Title:       article.Title,
+		Description: article.Description,
+		Text:        article.TextContent,
+		JSONLD:      article.JSONLDData,
+	}))
+}
+
+// coalesceString returns value unless it's empty, in which case fallback
+func coalesceString(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
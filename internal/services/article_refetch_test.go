@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRefetch(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	t.Run("success clears the error history", func(t *testing.T) {
+		article := &models.Article{
+			Title:          "Old title",
+			Author:         "Reporter",
+			FetchError:     "HTTP 503",
+			FetchRetries:   4,
+			LastFetchError: &earlier,
+		}
+		applyRefetch(article, &ArticleMetadata{
+			Title:       "Senate election: voters head to the polls",
+			TextContent: "Voters in the election chose a new senate majority.",
+			JSONLDData:  `{"@type":"NewsArticle"}`,
+			WordCount:   8,
+		}, nil, now)
+
+		assert.Equal(t, "Senate election: voters head to the polls", article.Title)
+		assert.Equal(t, "Reporter", article.Author, "empty fields keep what was stored")
+		assert.True(t, article.IsReachable)
+		assert.True(t, article.IsCached)
+		assert.Empty(t, article.FetchError)
+		assert.Zero(t, article.FetchRetries)
+		assert.Nil(t, article.LastFetchError)
+		assert.Equal(t, &now, article.LastFetchAt)
+		assert.Contains(t, []string(article.Tags), "politics")
+	})
+
+	t.Run("failure is recorded", func(t *testing.T) {
+		article := &models.Article{Title: "Story", IsReachable: true, FetchRetries: 1}
+		applyRefetch(article, nil, errors.New("HTTP 404: Not Found"), now)
+
+		assert.Equal(t, "Story", article.Title)
+		assert.False(t, article.IsReachable)
+		assert.Equal(t, "HTTP 404: Not Found", article.FetchError)
+		assert.Equal(t, 2, article.FetchRetries)
+		assert.Equal(t, &now, article.LastFetchError)
+	})
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"open-news/internal/domains"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultBreakingWindow and defaultBreakingSources are the burst that marks
+	// a story breaking without BREAKING_WINDOW_MINUTES and BREAKING_MIN_SOURCES
+	defaultBreakingWindow  = 30 * time.Minute
+	defaultBreakingSources = 5
+
+	// breakingMaxAge leaves out articles first seen too long ago to be breaking news
+	breakingMaxAge = 24 * time.Hour
+)
+
+// BreakingConfig holds the burst detection threshold and where events are sent
+type BreakingConfig struct {
+	Window        time.Duration // How recent the shares must be (default: 30 minutes)
+	MinSources    int           // Distinct sources sharing within the window that mark a story breaking (default: 5)
+	WebhookURLs   []string      // Endpoints each breaking event is posted to (BREAKING_WEBHOOK_URLS, comma separated)
+	WebhookSecret string        // Signs webhook bodies with HMAC-SHA256 when set (BREAKING_WEBHOOK_SECRET)
+}
+
+// LoadBreakingConfig reads the breaking news settings from the environment
+func LoadBreakingConfig() BreakingConfig {
+	config := BreakingConfig{
+		Window:        defaultBreakingWindow,
+		MinSources:    defaultBreakingSources,
+		WebhookSecret: os.Getenv("BREAKING_WEBHOOK_SECRET"),
+	}
+	if value := os.Getenv("BREAKING_WINDOW_MINUTES"); value != "" {
+		if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+			config.Window = time.Duration(minutes) * time.Minute
+		} else {
+			log.Printf("Invalid BREAKING_WINDOW_MINUTES %q, using %v", value, defaultBreakingWindow)
+		}
+	}
+	if value := os.Getenv("BREAKING_MIN_SOURCES"); value != "" {
+		if sources, err := strconv.Atoi(value); err == nil && sources > 1 {
+			config.MinSources = sources
+		} else {
+			log.Printf("Invalid BREAKING_MIN_SOURCES %q, using %d", value, defaultBreakingSources)
+		}
+	}
+	for _, url := range strings.Split(os.Getenv("BREAKING_WEBHOOK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			config.WebhookURLs = append(config.WebhookURLs, url)
+		}
+	}
+	return config
+}
+
+// BreakingEvent announces a story that many sources started sharing at once
+type BreakingEvent struct {
+	Type       string    `json:"type"` // Always "breaking"
+	ArticleID  uuid.UUID `json:"article_id"`
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	SiteName   string    `json:"site_name"`
+	Sources    int       `json:"sources"` // Distinct sources that shared it within the detection window
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// newBreakingEvent describes an article marked breaking
+func newBreakingEvent(article models.Article) BreakingEvent {
+	event := BreakingEvent{
+		Type:      "breaking",
+		ArticleID: article.ID,
+		URL:       article.URL,
+		Title:     article.Title,
+		SiteName:  article.SiteName,
+		Sources:   article.BreakingSources,
+	}
+	if article.BreakingAt != nil {
+		event.DetectedAt = *article.BreakingAt
+	}
+	return event
+}
+
+// BreakingService marks stories breaking when their shares burst across many
+// sources, which boosts them in rankings for a few hours, and announces them to
+// webhooks and event stream subscribers
+type BreakingService struct {
+	db            *gorm.DB
+	config        BreakingConfig
+	qualityScores *QualityScoreService
+	client        *http.Client
+}
+
+// NewBreakingService creates a new breaking news service
+func NewBreakingService(db *gorm.DB, config BreakingConfig) *BreakingService {
+	return &BreakingService{
+		db:            db,
+		config:        config,
+		qualityScores: NewQualityScoreService(db),
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithContext returns a breaking news service running its queries, and the rescoring of what it marks, with ctx
+func (s *BreakingService) WithContext(ctx context.Context) *BreakingService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.qualityScores = s.qualityScores.WithContext(ctx)
+	return &scoped
+}
+
+// Detect marks the articles that at least MinSources distinct sources shared
+// within the window as breaking, rescores them so the boost applies right away
+// and sends their events to the webhooks. Articles are only marked once.
+func (s *BreakingService) Detect() ([]BreakingEvent, error) {
+	now := time.Now()
+	var bursts []struct {
+		ArticleID uuid.UUID
+		Sources   int
+	}
+	err := s.db.Table("source_articles").
+		Select("source_articles.article_id, COUNT(DISTINCT source_articles.source_id) AS sources").
+		Joins("JOIN articles ON articles.id = source_articles.article_id").
+		Joins("JOIN sources ON sources.id = source_articles.source_id").
+		Where("source_articles.posted_at > ?", now.Add(-s.config.Window)).
+		Where("articles.breaking_at IS NULL AND articles.is_not_news = ? AND articles.created_at > ?", false, now.Add(-breakingMaxAge)).
+		Where("COALESCE(sources.spam_status, '') NOT IN ?", []string{models.SpamFlagged, models.SpamConfirmed}).
+		Scopes(domains.NotBlocked).
+		Group("source_articles.article_id").
+		Having("COUNT(DISTINCT source_articles.source_id) >= ?", s.config.MinSources).
+		Scan(&bursts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find share bursts: %w", err)
+	}
+
+	var events []BreakingEvent
+	for _, burst := range bursts {
+		// Another instance may have marked it since the query
+		result := s.db.Model(&models.Article{}).
+			Where("id = ? AND breaking_at IS NULL", burst.ArticleID).
+			Updates(map[string]interface{}{"breaking_at": now, "breaking_sources": burst.Sources})
+		if result.Error != nil {
+			log.Printf("❌ Failed to mark article %s breaking: %v", burst.ArticleID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.qualityScores.UpdateSingleArticleScore(burst.ArticleID.String()); err != nil {
+			log.Printf("Failed to rescore breaking article %s: %v", burst.ArticleID, err)
+		}
+
+		var article models.Article
+		if err := s.db.First(&article, "id = ?", burst.ArticleID).Error; err != nil {
+			log.Printf("Failed to load breaking article %s: %v", burst.ArticleID, err)
+			continue
+		}
+		event := newBreakingEvent(article)
+		log.Printf("🚨 Breaking: %s (%d sources in %v)", article.URL, burst.Sources, s.config.Window)
+		s.notify(event)
+		events = append(events, event)
+	}
+
+	if len(events) > 0 {
+		feeds.InvalidateGlobalFeed()
+	}
+	return events, nil
+}
+
+// Since returns the events of articles marked breaking after a time, oldest first
+func (s *BreakingService) Since(since time.Time, limit int) ([]BreakingEvent, error) {
+	var articles []models.Article
+	err := s.db.Where("breaking_at > ?", since).
+		Order("breaking_at ASC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breaking articles: %w", err)
+	}
+
+	events := make([]BreakingEvent, len(articles))
+	for i, article := range articles {
+		events[i] = newBreakingEvent(article)
+	}
+	return events, nil
+}
+
+// notify posts an event to each webhook. Failures are logged; webhooks aren't retried.
+func (s *BreakingService) notify(event BreakingEvent) {
+	if len(s.config.WebhookURLs) == 0 {
+		return
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode breaking event: %v", err)
+		return
+	}
+
+	for _, url := range s.config.WebhookURLs {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("Invalid breaking webhook %s: %v", url, err)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.config.WebhookSecret != "" {
+			req.Header.Set("X-OpenNews-Signature", "sha256="+SignWebhook(s.config.WebhookSecret, body))
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			log.Printf("Breaking webhook %s failed: %v", url, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("Breaking webhook %s returned %s", url, resp.Status)
+		}
+	}
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of a webhook body, sent in the
+// X-OpenNews-Signature header as "sha256=<signature>"
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBreakingConfig(t *testing.T) {
+	t.Setenv("BREAKING_WINDOW_MINUTES", "10")
+	t.Setenv("BREAKING_MIN_SOURCES", "1")
+	t.Setenv("BREAKING_WEBHOOK_URLS", "https://a.example/hook, ,https://b.example/hook")
+
+	config := LoadBreakingConfig()
+	assert.Equal(t, 10*time.Minute, config.Window)
+	assert.Equal(t, defaultBreakingSources, config.MinSources, "one source isn't a burst")
+	assert.Equal(t, []string{"https://a.example/hook", "https://b.example/hook"}, config.WebhookURLs)
+}
+
+func TestDetectBreaking(t *testing.T) {
+	db := setupTestDB(t)
+
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+
+	service := NewBreakingService(db, BreakingConfig{Window: 30 * time.Minute, MinSources: 3, WebhookURLs: []string{server.URL}, WebhookSecret: "secret"})
+
+	burst := models.Article{URL: "https://example.com/quake", Title: "Earthquake strikes"}
+	quiet := models.Article{URL: "https://example.com/garden", Title: "Garden show opens"}
+	for _, a := range []*models.Article{&burst, &quiet} {
+		require.NoError(t, db.Create(a).Error)
+	}
+	for i := 0; i < 3; i++ {
+		source := models.Source{BlueSkyDID: fmt.Sprintf("did:plc:testbreaking%d", i), Handle: fmt.Sprintf("breaking%d.test", i), IsActive: true}
+		require.NoError(t, db.Create(&source).Error)
+		require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: burst.ID, PostURI: fmt.Sprintf("at://%s/app.bsky.feed.post/1", source.BlueSkyDID), PostedAt: time.Now()}).Error)
+		if i == 0 {
+			require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: quiet.ID, PostURI: fmt.Sprintf("at://%s/app.bsky.feed.post/2", source.BlueSkyDID), PostedAt: time.Now()}).Error)
+		}
+	}
+
+	events, err := service.Detect()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, burst.ID, events[0].ArticleID)
+	assert.Equal(t, 3, events[0].Sources)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, "sha256="+SignWebhook("secret", bodies[0]), received[0].Header.Get("X-OpenNews-Signature"))
+	var event BreakingEvent
+	require.NoError(t, json.Unmarshal(bodies[0], &event))
+	assert.Equal(t, "breaking", event.Type)
+
+	var stored models.Article
+	require.NoError(t, db.First(&stored, "id = ?", burst.ID).Error)
+	require.NotNil(t, stored.BreakingAt)
+	assert.Greater(t, stored.QualityScore, 0.0, "rescored with the breaking boost")
+
+	events, err = service.Detect()
+	require.NoError(t, err)
+	assert.Empty(t, events, "stories are only marked once")
+
+	since, err := service.Since(stored.BreakingAt.Add(-time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, since, 1)
+	assert.Equal(t, burst.ID, since[0].ArticleID)
+}
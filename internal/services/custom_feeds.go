@@ -0,0 +1,228 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"open-news/internal/domains"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxCustomFeedsPerUser caps the feeds one user can define
+	MaxCustomFeedsPerUser = 10
+
+	// maxCustomFeedSites caps the sites one custom feed can be limited to
+	maxCustomFeedSites = 50
+
+	// customFeedRKeyPrefix starts the record keys of custom feeds, keeping them
+	// apart from the operator's feeds
+	customFeedRKeyPrefix = "u-"
+)
+
+var (
+	// ErrCustomFeedLimit is returned when a user already has MaxCustomFeedsPerUser feeds
+	ErrCustomFeedLimit = fmt.Errorf("at most %d custom feeds per user", MaxCustomFeedsPerUser)
+
+	// ErrInvalidCustomFeed is returned for a custom feed with invalid settings
+	ErrInvalidCustomFeed = errors.New("invalid custom feed")
+)
+
+// CustomFeed is what a user sets on a feed they define for themselves
+type CustomFeed struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Topics       []string `json:"topics"`        // Topic slugs; articles need at least one
+	Domains      []string `json:"domains"`       // Sites articles come from; empty for any
+	Language     string   `json:"language"`      // Language code, e.g. "en"; empty for any
+	MinShares    int      `json:"min_shares"`    // Minimum number of shares
+	GeneratorDID string   `json:"generator_did"` // DID to publish the feed record with; empty for a web-only feed
+}
+
+// CustomFeedService manages the feeds users define for themselves. They're
+// stored as global feed definitions owned by the user, so they're served like
+// the operator's feeds, as web pages and through getFeedSkeleton.
+type CustomFeedService struct {
+	db *gorm.DB
+}
+
+// NewCustomFeedService creates a new CustomFeedService
+func NewCustomFeedService(db *gorm.DB) *CustomFeedService {
+	return &CustomFeedService{db: db}
+}
+
+// List returns the feeds a user defined, oldest first
+func (s *CustomFeedService) List(ownerID uuid.UUID) ([]models.FeedDefinition, error) {
+	var definitions []models.FeedDefinition
+	err := s.db.Where("owner_id = ?", ownerID).Order("created_at ASC").Find(&definitions).Error
+	return definitions, err
+}
+
+// OwnerDID returns the Bluesky DID of a user, whose repository their feed
+// records are published in
+func (s *CustomFeedService) OwnerDID(ownerID uuid.UUID) (string, error) {
+	var user models.User
+	if err := s.db.Select("blue_sky_d_id").Where("id = ?", ownerID).First(&user).Error; err != nil {
+		return "", err
+	}
+	return user.BlueSkyDID, nil
+}
+
+// Get returns one of a user's feeds. It returns gorm.ErrRecordNotFound when the
+// user has no such feed.
+func (s *CustomFeedService) Get(ownerID, id uuid.UUID) (*models.FeedDefinition, error) {
+	var def models.FeedDefinition
+	if err := s.db.Where("id = ? AND owner_id = ?", id, ownerID).First(&def).Error; err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Create defines a feed for a user, served by a tenant (nil for the default
+// tenant). It gets a random record key.
+func (s *CustomFeedService) Create(ownerID uuid.UUID, tenantID *uuid.UUID, feed CustomFeed) (*models.FeedDefinition, error) {
+	def := models.FeedDefinition{
+		OwnerID:  &ownerID,
+		TenantID: tenantID,
+		Builder:  feeds.BuilderGlobal,
+		IsActive: true,
+	}
+	if def.TenantID == nil {
+		def.TenantID = models.DefaultTenantID(s.db)
+	}
+	if err := applyCustomFeed(&def, feed); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.FeedDefinition{}).Where("owner_id = ?", ownerID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count custom feeds: %w", err)
+	}
+	if count >= MaxCustomFeedsPerUser {
+		return nil, ErrCustomFeedLimit
+	}
+
+	rkey, err := newCustomFeedRKey()
+	if err != nil {
+		return nil, err
+	}
+	def.RKey = rkey
+	if err := s.db.Create(&def).Error; err != nil {
+		return nil, fmt.Errorf("failed to create custom feed: %w", err)
+	}
+	return &def, nil
+}
+
+// Update replaces the settings of one of a user's feeds, keeping its record key
+func (s *CustomFeedService) Update(ownerID, id uuid.UUID, feed CustomFeed) (*models.FeedDefinition, error) {
+	def, err := s.Get(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCustomFeed(def, feed); err != nil {
+		return nil, err
+	}
+	err = s.db.Model(def).
+		Select("display_name", "description", "topics", "domains", "language", "min_shares", "generator_did", "updated_at").
+		Updates(def).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update custom feed: %w", err)
+	}
+	return def, nil
+}
+
+// Delete removes one of a user's feeds. It returns gorm.ErrRecordNotFound when
+// the user has no such feed.
+func (s *CustomFeedService) Delete(ownerID, id uuid.UUID) error {
+	result := s.db.Where("id = ? AND owner_id = ?", id, ownerID).Delete(&models.FeedDefinition{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete custom feed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// applyCustomFeed validates a user's settings and copies them to a definition
+func applyCustomFeed(def *models.FeedDefinition, feed CustomFeed) error {
+	name := strings.TrimSpace(feed.Name)
+	if name == "" || utf8.RuneCountInString(name) > 24 {
+		return fmt.Errorf("%w: name must be 1 to 24 characters", ErrInvalidCustomFeed)
+	}
+	if utf8.RuneCountInString(feed.Description) > 300 {
+		return fmt.Errorf("%w: description must be at most 300 characters", ErrInvalidCustomFeed)
+	}
+	if feed.MinShares < 0 {
+		return fmt.Errorf("%w: min_shares can't be negative", ErrInvalidCustomFeed)
+	}
+
+	slugs := pq.StringArray{}
+	for _, slug := range feed.Topics {
+		slug = strings.ToLower(strings.TrimSpace(slug))
+		if !topics.IsTopic(slug) {
+			return fmt.Errorf("%w: %s", ErrUnknownTopic, slug)
+		}
+		slugs = append(slugs, slug)
+	}
+
+	sites := pq.StringArray{}
+	for _, site := range feed.Domains {
+		normalized := domains.Normalize(site)
+		if normalized == "" || !strings.Contains(normalized, ".") {
+			return fmt.Errorf("%w: %q isn't a site", ErrInvalidCustomFeed, site)
+		}
+		sites = append(sites, normalized)
+	}
+	if len(sites) > maxCustomFeedSites {
+		return fmt.Errorf("%w: at most %d sites", ErrInvalidCustomFeed, maxCustomFeedSites)
+	}
+
+	language := ""
+	if strings.TrimSpace(feed.Language) != "" {
+		languages, err := normalizeLanguages([]string{feed.Language})
+		if err != nil {
+			return err
+		}
+		language = languages[0]
+	}
+
+	generatorDID := strings.TrimSpace(feed.GeneratorDID)
+	if generatorDID != "" && !strings.HasPrefix(generatorDID, "did:") {
+		return fmt.Errorf("%w: generator_did must be a DID", ErrInvalidCustomFeed)
+	}
+
+	if len(slugs) == 0 && len(sites) == 0 && language == "" && feed.MinShares == 0 {
+		return fmt.Errorf("%w: choose topics, sites, a language or a minimum share count", ErrInvalidCustomFeed)
+	}
+
+	def.DisplayName = name
+	def.Description = strings.TrimSpace(feed.Description)
+	def.Topics = slugs
+	def.Domains = sites
+	def.Language = language
+	def.MinShares = feed.MinShares
+	def.GeneratorDID = generatorDID
+	return nil
+}
+
+// newCustomFeedRKey returns a random record key for a custom feed, short
+// enough for Bluesky's 15 character limit
+func newCustomFeedRKey() (string, error) {
+	secret := make([]byte, 8)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate record key: %w", err)
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret))
+	return customFeedRKeyPrefix + encoded[:12], nil
+}
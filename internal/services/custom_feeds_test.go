@@ -0,0 +1,81 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"open-news/internal/feeds"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCustomFeedService(t *testing.T) {
+	db := setupTenantTestDB(t)
+	s := NewCustomFeedService(db)
+	owner, other := uuid.New(), uuid.New()
+
+	_, err := s.Create(owner, nil, CustomFeed{Name: "Everything"})
+	assert.ErrorIs(t, err, ErrInvalidCustomFeed, "a custom feed needs at least one filter")
+	_, err = s.Create(owner, nil, CustomFeed{Name: "Gardening", Topics: []string{"gardening"}})
+	assert.ErrorIs(t, err, ErrUnknownTopic)
+	_, err = s.Create(owner, nil, CustomFeed{Name: "Tech", Topics: []string{"tech"}, GeneratorDID: "feeds.example"})
+	assert.ErrorIs(t, err, ErrInvalidCustomFeed)
+
+	def, err := s.Create(owner, nil, CustomFeed{
+		Name:         " Local Tech ",
+		Topics:       []string{"Tech"},
+		Domains:      []string{"https://www.Example.com/news"},
+		Language:     "en-US",
+		MinShares:    3,
+		GeneratorDID: "did:web:feeds.example",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Local Tech", def.DisplayName)
+	assert.True(t, strings.HasPrefix(def.RKey, "u-"))
+	assert.LessOrEqual(t, len(def.RKey), 15, "record keys fit Bluesky's limit")
+	assert.Equal(t, []string{"tech"}, []string(def.Topics))
+	assert.Equal(t, []string{"example.com"}, []string(def.Domains))
+	assert.Equal(t, "en", def.Language)
+	assert.Equal(t, feeds.BuilderGlobal, def.Builder)
+
+	// Custom feeds are served by record key but aren't listed as the operator's feeds
+	registry := feeds.NewRegistry(db)
+	found, err := registry.Lookup("at://did:plc:someone/app.bsky.feed.generator/" + def.RKey)
+	require.NoError(t, err)
+	assert.Equal(t, def.ID, found.ID)
+	listed, err := registry.List()
+	require.NoError(t, err)
+	for _, listedDef := range listed {
+		assert.NotEqual(t, def.ID, listedDef.ID)
+	}
+
+	updated, err := s.Update(owner, def.ID, CustomFeed{Name: "Busy Tech", Topics: []string{"tech"}, MinShares: 10})
+	require.NoError(t, err)
+	assert.Equal(t, def.RKey, updated.RKey, "updates keep the record key")
+	reloaded, err := s.Get(owner, def.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, reloaded.MinShares)
+	assert.Empty(t, reloaded.Domains)
+	assert.Empty(t, reloaded.GeneratorDID)
+
+	_, err = s.Update(other, def.ID, CustomFeed{Name: "Stolen", MinShares: 1})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "users only change their own feeds")
+	assert.ErrorIs(t, s.Delete(other, def.ID), gorm.ErrRecordNotFound)
+
+	for i := 1; i < MaxCustomFeedsPerUser; i++ {
+		_, err = s.Create(owner, nil, CustomFeed{Name: "Popular", MinShares: i})
+		require.NoError(t, err)
+	}
+	_, err = s.Create(owner, nil, CustomFeed{Name: "One too many", MinShares: 1})
+	assert.ErrorIs(t, err, ErrCustomFeedLimit)
+
+	require.NoError(t, s.Delete(owner, def.ID))
+	list, err := s.List(owner)
+	require.NoError(t, err)
+	assert.Len(t, list, MaxCustomFeedsPerUser-1)
+	_, err = registry.Lookup(def.RKey)
+	assert.ErrorIs(t, err, feeds.ErrFeedNotFound)
+}
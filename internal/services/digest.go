@@ -0,0 +1,336 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultDigestHeaderTemplate opens the digest thread and messages
+	defaultDigestHeaderTemplate = `📰 Today's top stories on open.news ({{.Date.Format "Jan 2"}}) 🧵`
+
+	// defaultDigestItemTemplate is one story, a reply in the thread or a paragraph of a message
+	defaultDigestItemTemplate = `{{.Rank}}. {{.Title}}{{if .SiteName}} ({{.SiteName}}){{end}}
+{{.URL}}`
+)
+
+// DigestConfig configures the top-stories digest, read from DIGEST_* settings
+type DigestConfig struct {
+	Enabled        bool           // DIGEST_ENABLED
+	Schedule       string         // DIGEST_SCHEDULE, a cron expression (default: daily at 08:00)
+	Location       *time.Location // DIGEST_TIMEZONE the schedule runs in (default: UTC)
+	PostThread     bool           // DIGEST_DELIVERY includes "post": a thread from the bot account
+	SendMessages   bool           // DIGEST_DELIVERY includes "dm": messages to subscribed users
+	Size           int            // DIGEST_SIZE stories (default: 5)
+	HeaderTemplate string         // DIGEST_HEADER_TEMPLATE, a text/template given DigestHeader
+	ItemTemplate   string         // DIGEST_ITEM_TEMPLATE, a text/template given DigestItem
+}
+
+// LoadDigestConfig reads the digest settings from the environment
+func LoadDigestConfig() DigestConfig {
+	config := DigestConfig{
+		Enabled:        os.Getenv("DIGEST_ENABLED") == "true",
+		Schedule:       "0 8 * * *",
+		Location:       time.UTC,
+		PostThread:     true,
+		Size:           5,
+		HeaderTemplate: defaultDigestHeaderTemplate,
+		ItemTemplate:   defaultDigestItemTemplate,
+	}
+
+	if value := os.Getenv("DIGEST_SCHEDULE"); value != "" {
+		config.Schedule = value
+	}
+	if value := os.Getenv("DIGEST_TIMEZONE"); value != "" {
+		if location, err := time.LoadLocation(value); err == nil {
+			config.Location = location
+		} else {
+			log.Printf("Invalid DIGEST_TIMEZONE %q, using UTC", value)
+		}
+	}
+	if value := os.Getenv("DIGEST_DELIVERY"); value != "" {
+		config.PostThread = false
+		for _, method := range strings.Split(value, ",") {
+			switch strings.TrimSpace(method) {
+			case "post":
+				config.PostThread = true
+			case "dm":
+				config.SendMessages = true
+			default:
+				log.Printf("Invalid DIGEST_DELIVERY method %q, expected post or dm", method)
+			}
+		}
+	}
+	if value := os.Getenv("DIGEST_SIZE"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed <= 25 {
+			config.Size = parsed
+		} else {
+			log.Printf("Invalid DIGEST_SIZE %q, using %d", value, config.Size)
+		}
+	}
+	if value := os.Getenv("DIGEST_HEADER_TEMPLATE"); value != "" {
+		config.HeaderTemplate = value
+	}
+	if value := os.Getenv("DIGEST_ITEM_TEMPLATE"); value != "" {
+		config.ItemTemplate = value
+	}
+	return config
+}
+
+// DigestHeader is the data given to the header template
+type DigestHeader struct {
+	Date  time.Time
+	Count int
+}
+
+// DigestItem is the data given to the item template
+type DigestItem struct {
+	Rank        int
+	Title       string
+	URL         string
+	SiteName    string
+	Description string
+}
+
+// Digest is a rendered set of top stories
+type Digest struct {
+	Header string
+	Items  []DigestItem
+	Posts  []string // Header first, then one post per story, each within the post limit
+}
+
+// DigestPublisher delivers digests; *bluesky.Client implements it
+type DigestPublisher interface {
+	CreatePost(post bluesky.NewPost) (*bluesky.RecordRef, error)
+	SendDirectMessage(recipientDID, text string) error
+}
+
+// DigestService builds the top-stories digest from the global feed and posts it
+// as a thread or sends it to subscribed users
+type DigestService struct {
+	db        *gorm.DB
+	publisher DigestPublisher
+	config    DigestConfig
+	header    *template.Template
+	item      *template.Template
+}
+
+// NewDigestService creates a digest service, failing if a template doesn't parse
+func NewDigestService(db *gorm.DB, publisher DigestPublisher, config DigestConfig) (*DigestService, error) {
+	header, err := template.New("header").Parse(config.HeaderTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest header template: %w", err)
+	}
+	item, err := template.New("item").Parse(config.ItemTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest item template: %w", err)
+	}
+	return &DigestService{db: db, publisher: publisher, config: config, header: header, item: item}, nil
+}
+
+// WithContext returns a digest service running its queries, and its posts when they go through a Bluesky client, with ctx
+func (s *DigestService) WithContext(ctx context.Context) *DigestService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	if client, ok := s.publisher.(*bluesky.Client); ok {
+		scoped.publisher = client.WithContext(ctx)
+	}
+	return &scoped
+}
+
+// Build renders a digest of the current top stories
+func (s *DigestService) Build(now time.Time) (*Digest, error) {
+	feed, err := feeds.NewFeedService(s.db).GetGlobalFeed(s.config.Size, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load top stories: %w", err)
+	}
+
+	items := make([]DigestItem, 0, len(feed.Items))
+	for i, entry := range feed.Items {
+		items = append(items, DigestItem{
+			Rank:        i + 1,
+			Title:       entry.Article.Title,
+			URL:         entry.Article.URL,
+			SiteName:    entry.Article.SiteName,
+			Description: entry.Article.Description,
+		})
+	}
+	return s.render(items, now.In(s.config.Location))
+}
+
+// render executes the templates for a set of stories
+func (s *DigestService) render(items []DigestItem, date time.Time) (*Digest, error) {
+	header, err := executeDigestTemplate(s.header, DigestHeader{Date: date, Count: len(items)})
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{Header: truncateRunes(header, bluesky.MaxPostGraphemes), Items: items}
+	digest.Posts = append(digest.Posts, digest.Header)
+	for _, item := range items {
+		text, err := s.renderItem(item, bluesky.MaxPostGraphemes)
+		if err != nil {
+			return nil, err
+		}
+		digest.Posts = append(digest.Posts, text)
+	}
+	return digest, nil
+}
+
+// renderItem renders a story, shortening its title so the text fits in limit
+// characters without cutting the URL
+func (s *DigestService) renderItem(item DigestItem, limit int) (string, error) {
+	text, err := executeDigestTemplate(s.item, item)
+	if err != nil {
+		return "", err
+	}
+	if over := utf8.RuneCountInString(text) - limit; over > 0 {
+		item.Title = truncateRunes(item.Title, utf8.RuneCountInString(item.Title)-over)
+		if text, err = executeDigestTemplate(s.item, item); err != nil {
+			return "", err
+		}
+	}
+	return truncateRunes(text, limit), nil
+}
+
+// Message joins a digest into one direct message, leaving out the last stories
+// if it would be too long
+func (d *Digest) Message() string {
+	message := d.Header
+	for _, post := range d.Posts[1:] {
+		next := message + "\n\n" + post
+		if utf8.RuneCountInString(next) > bluesky.MaxMessageGraphemes {
+			break
+		}
+		message = next
+	}
+	return message
+}
+
+// Send builds the digest and delivers it the configured ways. Delivery failures
+// to single subscribers are logged; an error is returned only when nothing was sent.
+func (s *DigestService) Send(now time.Time) error {
+	digest, err := s.Build(now)
+	if err != nil {
+		return err
+	}
+	if len(digest.Items) == 0 {
+		log.Printf("📭 No top stories for the digest, skipping")
+		return nil
+	}
+
+	delivered := false
+	var lastErr error
+	if s.config.PostThread {
+		if err := s.postThread(digest); err != nil {
+			lastErr = err
+		} else {
+			delivered = true
+		}
+	}
+	if s.config.SendMessages {
+		sent, err := s.sendMessages(digest)
+		if err != nil {
+			lastErr = err
+		}
+		delivered = delivered || sent > 0
+	}
+
+	if !delivered && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// postThread posts the header and replies with one story each. A story that
+// fails to post ends the thread early rather than leaving gaps.
+func (s *DigestService) postThread(digest *Digest) error {
+	root, err := s.publisher.CreatePost(bluesky.NewPost{Text: digest.Posts[0]})
+	if err != nil {
+		return fmt.Errorf("failed to post digest: %w", err)
+	}
+
+	parent := root
+	for i, text := range digest.Posts[1:] {
+		item := digest.Items[i]
+		reply, err := s.publisher.CreatePost(bluesky.NewPost{
+			Text:  text,
+			Reply: &bluesky.ReplyRefs{Root: *root, Parent: *parent},
+			External: &bluesky.ExternalEmbed{
+				URI:         item.URL,
+				Title:       item.Title,
+				Description: truncateRunes(item.Description, 300),
+			},
+		})
+		if err != nil {
+			log.Printf("⚠️  Digest thread stopped at story %d: %v", item.Rank, err)
+			break
+		}
+		parent = reply
+	}
+
+	log.Printf("📰 Posted digest thread %s", root.URI)
+	return nil
+}
+
+// sendMessages sends the digest to every active user subscribed to it
+func (s *DigestService) sendMessages(digest *Digest) (int, error) {
+	var dids []string
+	err := s.db.Model(&models.User{}).
+		Joins("JOIN user_feed_preferences ON user_feed_preferences.user_id = users.id").
+		Where("user_feed_preferences.digest_subscribed = ? AND users.is_active = ?", true, true).
+		Pluck("users.blue_sky_d_id", &dids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load digest subscribers: %w", err)
+	}
+
+	message := digest.Message()
+	sent := 0
+	var lastErr error
+	for _, did := range dids {
+		if err := s.publisher.SendDirectMessage(did, message); err != nil {
+			log.Printf("⚠️  Failed to send digest to %s: %v", did, err)
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("📬 Sent digest to %d of %d subscribers", sent, len(dids))
+	return sent, lastErr
+}
+
+// executeDigestTemplate renders a digest template to trimmed text
+func executeDigestTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render digest %s template: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// truncateRunes shortens s to at most n characters, ending with an ellipsis when cut
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
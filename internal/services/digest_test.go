@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"open-news/internal/bluesky"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records the posts and messages a digest sends
+type fakePublisher struct {
+	posts    []bluesky.NewPost
+	messages map[string]string
+	failAt   int // Fail the nth post (1-based); 0 never fails
+}
+
+func (p *fakePublisher) CreatePost(post bluesky.NewPost) (*bluesky.RecordRef, error) {
+	if p.failAt == len(p.posts)+1 {
+		return nil, fmt.Errorf("rate limited")
+	}
+	p.posts = append(p.posts, post)
+	return &bluesky.RecordRef{URI: fmt.Sprintf("at://did:plc:bot/app.bsky.feed.post/%d", len(p.posts))}, nil
+}
+
+func (p *fakePublisher) SendDirectMessage(recipientDID, text string) error {
+	if p.messages == nil {
+		p.messages = make(map[string]string)
+	}
+	p.messages[recipientDID] = text
+	return nil
+}
+
+func testDigestConfig() DigestConfig {
+	return DigestConfig{
+		Location:       time.UTC,
+		PostThread:     true,
+		Size:           5,
+		HeaderTemplate: defaultDigestHeaderTemplate,
+		ItemTemplate:   defaultDigestItemTemplate,
+	}
+}
+
+func TestNewDigestService_InvalidTemplate(t *testing.T) {
+	config := testDigestConfig()
+	config.ItemTemplate = "{{.Title"
+	_, err := NewDigestService(nil, &fakePublisher{}, config)
+	assert.Error(t, err)
+}
+
+func TestDigestService_Render(t *testing.T) {
+	service, err := NewDigestService(nil, &fakePublisher{}, testDigestConfig())
+	require.NoError(t, err)
+
+	longTitle := strings.Repeat("Very long headline ", 30)
+	digest, err := service.render([]DigestItem{
+		{Rank: 1, Title: "Council approves budget", URL: "https://example.com/budget", SiteName: "Example News"},
+		{Rank: 2, Title: longTitle, URL: "https://example.com/long"},
+	}, time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, digest.Posts, 3)
+	assert.Equal(t, "📰 Today's top stories on open.news (Mar 4) 🧵", digest.Header)
+	assert.Equal(t, "1. Council approves budget (Example News)\nhttps://example.com/budget", digest.Posts[1])
+
+	// Long titles are shortened so the URL survives
+	assert.LessOrEqual(t, utf8.RuneCountInString(digest.Posts[2]), bluesky.MaxPostGraphemes)
+	assert.True(t, strings.HasSuffix(digest.Posts[2], "…\nhttps://example.com/long"))
+
+	message := digest.Message()
+	assert.True(t, strings.HasPrefix(message, digest.Header+"\n\n1. Council"))
+	assert.LessOrEqual(t, utf8.RuneCountInString(message), bluesky.MaxMessageGraphemes)
+}
+
+func TestDigestService_PostThread(t *testing.T) {
+	publisher := &fakePublisher{}
+	service, err := NewDigestService(nil, publisher, testDigestConfig())
+	require.NoError(t, err)
+
+	digest, err := service.render([]DigestItem{
+		{Rank: 1, Title: "First", URL: "https://example.com/1"},
+		{Rank: 2, Title: "Second", URL: "https://example.com/2"},
+	}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, service.postThread(digest))
+
+	require.Len(t, publisher.posts, 3)
+	assert.Nil(t, publisher.posts[0].Reply)
+	root := "at://did:plc:bot/app.bsky.feed.post/1"
+	assert.Equal(t, root, publisher.posts[1].Reply.Root.URI)
+	assert.Equal(t, root, publisher.posts[1].Reply.Parent.URI)
+	assert.Equal(t, root, publisher.posts[2].Reply.Root.URI)
+	assert.Equal(t, "at://did:plc:bot/app.bsky.feed.post/2", publisher.posts[2].Reply.Parent.URI)
+	assert.Equal(t, "https://example.com/2", publisher.posts[2].External.URI)
+
+	// A failed header post fails the thread
+	failing := &fakePublisher{failAt: 1}
+	service.publisher = failing
+	assert.Error(t, service.postThread(digest))
+	assert.Empty(t, failing.posts)
+}
+
+func TestTruncateRunes(t *testing.T) {
+	assert.Equal(t, "short", truncateRunes("short", 10))
+	assert.Equal(t, "héll…", truncateRunes("héllo wörld", 5))
+	assert.Equal(t, "", truncateRunes("anything", 0))
+}
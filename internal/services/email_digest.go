@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/mail"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"open-news/internal/feeds"
+	"open-news/internal/mailer"
+	"open-news/internal/models"
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+//go:embed templates/email.html templates/email.txt
+var emailTemplateFS embed.FS
+
+var (
+	emailTemplateFuncs = template.FuncMap{"join": strings.Join}
+
+	// emailHTMLTemplates and emailTextTemplates render the two versions of each email
+	emailHTMLTemplates = htmltemplate.Must(htmltemplate.New("email").Funcs(htmltemplate.FuncMap(emailTemplateFuncs)).ParseFS(emailTemplateFS, "templates/email.html"))
+	emailTextTemplates = template.Must(template.New("email").Funcs(emailTemplateFuncs).ParseFS(emailTemplateFS, "templates/email.txt"))
+)
+
+// defaultEmailDigestSize is how many stories an email digest lists
+const defaultEmailDigestSize = 10
+
+// emailDigestIntervals is how long after a digest the next one is due. They're a
+// few hours short of a day or week so a run that starts a little early still sends.
+var emailDigestIntervals = map[string]time.Duration{
+	models.EmailFrequencyDaily:  20 * time.Hour,
+	models.EmailFrequencyWeekly: 6*24*time.Hour + 12*time.Hour,
+}
+
+var (
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrInvalidFrequency     = errors.New("frequency must be daily or weekly")
+	ErrUnknownTopic         = errors.New("unknown topic")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// EmailDigestService manages email digest subscriptions and sends the digests
+type EmailDigestService struct {
+	db      *gorm.DB
+	mailer  mailer.Mailer
+	baseURL string // For confirm, unsubscribe and story links
+	size    int
+}
+
+// NewEmailDigestService creates an email digest service. Links in emails use
+// PUBLIC_BASE_URL; EMAIL_DIGEST_SIZE sets the number of stories.
+func NewEmailDigestService(db *gorm.DB, m mailer.Mailer) *EmailDigestService {
+	baseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	size := defaultEmailDigestSize
+	if value := os.Getenv("EMAIL_DIGEST_SIZE"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed <= 50 {
+			size = parsed
+		} else {
+			log.Printf("Invalid EMAIL_DIGEST_SIZE %q, using %d", value, size)
+		}
+	}
+	return &EmailDigestService{db: db, mailer: m, baseURL: baseURL, size: size}
+}
+
+// WithContext returns an email digest service running its queries with ctx
+func (s *EmailDigestService) WithContext(ctx context.Context) *EmailDigestService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Subscribe records a subscription and emails a confirmation link. Subscribing an
+// address again updates an unconfirmed subscription and resends the link; a
+// confirmed one is left alone, so nobody can change another reader's settings.
+func (s *EmailDigestService) Subscribe(email, frequency string, topicSlugs []string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" {
+		return ErrInvalidEmail
+	}
+	email = strings.ToLower(address.Address)
+	if frequency == "" {
+		frequency = models.EmailFrequencyDaily
+	}
+	if !models.IsValidEmailFrequency(frequency) {
+		return ErrInvalidFrequency
+	}
+	for _, slug := range topicSlugs {
+		if !topics.IsTopic(slug) {
+			return fmt.Errorf("%w: %s", ErrUnknownTopic, slug)
+		}
+	}
+
+	var subscription models.EmailSubscription
+	err = s.db.Where("email = ?", email).First(&subscription).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		token, err := newSubscriptionToken()
+		if err != nil {
+			return err
+		}
+		subscription = models.EmailSubscription{Email: email, Frequency: frequency, Topics: topicSlugs, Token: token}
+		if err := s.db.Create(&subscription).Error; err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to load subscription: %w", err)
+	case subscription.ConfirmedAt != nil:
+		return nil
+	default:
+		subscription.Frequency = frequency
+		subscription.Topics = topicSlugs
+		if err := s.db.Save(&subscription).Error; err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+	}
+
+	return s.sendEmail(subscription.Email, "Confirm your open.news digest subscription", "confirm", map[string]string{
+		"Frequency":  subscription.Frequency,
+		"ConfirmURL": s.baseURL + "/email/confirm/" + subscription.Token,
+	}, nil)
+}
+
+// Confirm activates the subscription with the given token
+func (s *EmailDigestService) Confirm(token string) (*models.EmailSubscription, error) {
+	subscription, err := s.findByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.ConfirmedAt == nil {
+		now := time.Now()
+		subscription.ConfirmedAt = &now
+		if err := s.db.Model(subscription).Update("confirmed_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to confirm subscription: %w", err)
+		}
+		log.Printf("📧 Confirmed %s email digest for %s", subscription.Frequency, subscription.Email)
+	}
+	return subscription, nil
+}
+
+// Unsubscribe deletes the subscription with the given token
+func (s *EmailDigestService) Unsubscribe(token string) error {
+	subscription, err := s.findByToken(token)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(subscription).Error; err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	log.Printf("📧 Unsubscribed %s from the email digest", subscription.Email)
+	return nil
+}
+
+// findByToken loads the subscription a confirm or unsubscribe link belongs to
+func (s *EmailDigestService) findByToken(token string) (*models.EmailSubscription, error) {
+	if token == "" {
+		return nil, ErrSubscriptionNotFound
+	}
+	var subscription models.EmailSubscription
+	err := s.db.Where("token = ?", token).First(&subscription).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrSubscriptionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// SendDue emails the digest to every confirmed subscription whose last one is
+// older than its frequency, returning the number sent. Failed sends are logged
+// and retried on the next run.
+func (s *EmailDigestService) SendDue(now time.Time) (int, error) {
+	var due []models.EmailSubscription
+	for frequency, interval := range emailDigestIntervals {
+		var subscriptions []models.EmailSubscription
+		err := s.db.Where("confirmed_at IS NOT NULL AND frequency = ?", frequency).
+			Where("last_sent_at IS NULL OR last_sent_at < ?", now.Add(-interval)).
+			Find(&subscriptions).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to load due subscriptions: %w", err)
+		}
+		due = append(due, subscriptions...)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	// Subscriptions with the same frequency and topics get the same stories
+	stories := make(map[string][]DigestItem)
+	sent := 0
+	for _, subscription := range due {
+		key := subscription.Frequency + "|" + strings.Join(subscription.Topics, ",")
+		items, ok := stories[key]
+		if !ok {
+			var err error
+			if items, err = s.topStories(subscription, now); err != nil {
+				return sent, err
+			}
+			stories[key] = items
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		if err := s.sendDigest(subscription, items, now); err != nil {
+			log.Printf("⚠️  Failed to send email digest to %s: %v", subscription.Email, err)
+			continue
+		}
+		if err := s.db.Model(&subscription).Update("last_sent_at", now).Error; err != nil {
+			log.Printf("⚠️  Failed to record email digest for %s: %v", subscription.Email, err)
+		}
+		sent++
+	}
+
+	log.Printf("📧 Sent %d of %d due email digests", sent, len(due))
+	return sent, nil
+}
+
+// topStories picks the stories for a subscription: the global top stories, or
+// the best stories of its topics since its previous digest was due
+func (s *EmailDigestService) topStories(subscription models.EmailSubscription, now time.Time) ([]DigestItem, error) {
+	feedService := feeds.NewFeedService(s.db)
+	var entries []feeds.FeedItemDetails
+
+	if len(subscription.Topics) == 0 {
+		feed, err := feedService.GetGlobalFeed(s.size, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load top stories: %w", err)
+		}
+		entries = feed.Items
+	} else {
+		since := now.Add(-emailDigestIntervals[subscription.Frequency] - 4*time.Hour)
+		seen := make(map[uuid.UUID]bool)
+		for _, slug := range subscription.Topics {
+			feed, err := feedService.GetFilteredFeed(feeds.FeedFilter{
+				Name:     "Topic: " + slug,
+				FeedType: "topic",
+				Topic:    slug,
+				Since:    since,
+			}, s.size, 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s stories: %w", slug, err)
+			}
+			for _, entry := range feed.Items {
+				if !seen[entry.Article.ID] {
+					seen[entry.Article.ID] = true
+					entries = append(entries, entry)
+				}
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+		if len(entries) > s.size {
+			entries = entries[:s.size]
+		}
+	}
+
+	items := make([]DigestItem, len(entries))
+	for i, entry := range entries {
+		items[i] = DigestItem{
+			Rank:        i + 1,
+			Title:       entry.Article.Title,
+			URL:         s.baseURL + "/r/" + entry.Article.ID.String() + "?surface=email",
+			SiteName:    entry.Article.SiteName,
+			Description: truncateRunes(entry.Article.Description, 240),
+		}
+	}
+	return items, nil
+}
+
+// emailDigestView is the data given to the digest email templates
+type emailDigestView struct {
+	Subject        string
+	Heading        string
+	Date           time.Time
+	Frequency      string
+	Topics         []string // Topic names
+	Items          []DigestItem
+	UnsubscribeURL string
+}
+
+// sendDigest emails one subscription its digest
+func (s *EmailDigestService) sendDigest(subscription models.EmailSubscription, items []DigestItem, now time.Time) error {
+	view := emailDigestView{
+		Heading:        "Today's top stories",
+		Date:           now,
+		Frequency:      subscription.Frequency,
+		Items:          items,
+		UnsubscribeURL: s.baseURL + "/email/unsubscribe/" + subscription.Token,
+	}
+	if subscription.Frequency == models.EmailFrequencyWeekly {
+		view.Heading = "This week's top stories"
+	}
+	for _, slug := range subscription.Topics {
+		if topic, ok := topics.Get(slug); ok {
+			view.Topics = append(view.Topics, topic.Name)
+		}
+	}
+	view.Subject = view.Heading + " on open.news"
+
+	return s.sendEmail(subscription.Email, view.Subject, "digest", view, map[string]string{
+		"List-Unsubscribe":      "<" + view.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	})
+}
+
+// sendEmail renders the HTML and text versions of an email template and sends it
+func (s *EmailDigestService) sendEmail(to, subject, name string, data interface{}, headers map[string]string) error {
+	var html, text bytes.Buffer
+	if err := emailHTMLTemplates.ExecuteTemplate(&html, name, data); err != nil {
+		return fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+	if err := emailTextTemplates.ExecuteTemplate(&text, name, data); err != nil {
+		return fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      to,
+		Subject: subject,
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		Headers: headers,
+	})
+}
+
+// newSubscriptionToken returns a random token for confirm and unsubscribe links
+func newSubscriptionToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"open-news/internal/mailer"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer keeps the messages it's asked to send
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestEmailDigestService_SendDigest(t *testing.T) {
+	m := &recordingMailer{}
+	s := &EmailDigestService{mailer: m, baseURL: "https://open.news", size: 10}
+
+	subscription := models.EmailSubscription{Email: "reader@example.com", Frequency: models.EmailFrequencyWeekly, Topics: []string{"tech"}, Token: "abc"}
+	items := []DigestItem{{Rank: 1, Title: `Chips <b>shortage</b> ends`, URL: "https://open.news/r/1?surface=email", SiteName: "Example News"}}
+	require.NoError(t, s.sendDigest(subscription, items, time.Date(2026, 3, 4, 7, 0, 0, 0, time.UTC)))
+
+	require.Len(t, m.sent, 1)
+	msg := m.sent[0]
+	assert.Equal(t, "reader@example.com", msg.To)
+	assert.Equal(t, "This week's top stories on open.news", msg.Subject)
+	assert.Equal(t, "<https://open.news/email/unsubscribe/abc>", msg.Headers["List-Unsubscribe"])
+	assert.Contains(t, msg.HTML, "Chips &lt;b&gt;shortage&lt;/b&gt; ends")
+	assert.Contains(t, msg.HTML, "Technology")
+	assert.Contains(t, msg.Text, "1. Chips <b>shortage</b> ends (Example News)\nhttps://open.news/r/1?surface=email")
+	assert.Contains(t, msg.Text, "Unsubscribe: https://open.news/email/unsubscribe/abc")
+}
+
+func TestEmailDigestService_Subscriptions(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.EmailSubscription{}))
+	db.Exec("DELETE FROM email_subscriptions")
+
+	m := &recordingMailer{}
+	s := NewEmailDigestService(db, m)
+
+	assert.ErrorIs(t, s.Subscribe("not an address", "", nil), ErrInvalidEmail)
+	assert.ErrorIs(t, s.Subscribe("reader@example.com", "hourly", nil), ErrInvalidFrequency)
+	assert.ErrorIs(t, s.Subscribe("reader@example.com", "", []string{"gossip"}), ErrUnknownTopic)
+
+	require.NoError(t, s.Subscribe("Reader@Example.com", "", []string{"tech"}))
+	var subscription models.EmailSubscription
+	require.NoError(t, db.Where("email = ?", "reader@example.com").First(&subscription).Error)
+	assert.Equal(t, models.EmailFrequencyDaily, subscription.Frequency)
+	assert.Nil(t, subscription.ConfirmedAt)
+	require.Len(t, m.sent, 1)
+	assert.Contains(t, m.sent[0].Text, "/email/confirm/"+subscription.Token)
+
+	// Unconfirmed subscriptions aren't sent digests
+	sent, err := s.SendDue(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	confirmed, err := s.Confirm(subscription.Token)
+	require.NoError(t, err)
+	assert.NotNil(t, confirmed.ConfirmedAt)
+	_, err = s.Confirm("wrong")
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+
+	// Subscribing a confirmed address again changes nothing and sends nothing
+	require.NoError(t, s.Subscribe("reader@example.com", models.EmailFrequencyWeekly, nil))
+	require.NoError(t, db.First(&subscription, "id = ?", subscription.ID).Error)
+	assert.Equal(t, models.EmailFrequencyDaily, subscription.Frequency)
+	assert.Len(t, m.sent, 1)
+
+	require.NoError(t, s.Unsubscribe(subscription.Token))
+	assert.ErrorIs(t, s.Unsubscribe(subscription.Token), ErrSubscriptionNotFound)
+}
+
+func TestEmailDigestService_SendDue(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.EmailSubscription{}))
+	db.Exec("DELETE FROM email_subscriptions")
+
+	now := time.Now()
+	article := models.Article{URL: "https://example.com/email-story", Title: "Email story", QualityScore: 0.9, Tags: []string{"tech"}}
+	require.NoError(t, db.Create(&article).Error)
+
+	confirmed := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+	for _, subscription := range []models.EmailSubscription{
+		{Email: "due@example.com", Frequency: models.EmailFrequencyDaily, Topics: []string{"tech"}, Token: "due", ConfirmedAt: &confirmed},
+		{Email: "sent@example.com", Frequency: models.EmailFrequencyDaily, Topics: []string{"tech"}, Token: "sent", ConfirmedAt: &confirmed, LastSentAt: &recent},
+	} {
+		require.NoError(t, db.Create(&subscription).Error)
+	}
+
+	m := &recordingMailer{}
+	sent, err := NewEmailDigestService(db, m).SendDue(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "due@example.com", m.sent[0].To)
+	assert.True(t, strings.Contains(m.sent[0].Text, "Email story"))
+
+	var due models.EmailSubscription
+	require.NoError(t, db.Where("email = ?", "due@example.com").First(&due).Error)
+	require.NotNil(t, due.LastSentAt)
+}
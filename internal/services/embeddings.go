@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/domains"
+	"open-news/internal/embeddings"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// embeddingsBatchSize is how many articles an embedding job processes, in one request
+const embeddingsBatchSize = 100
+
+// articleEmbeddingTextSQL is the text an article's embedding is computed from,
+// matching embeddingText, so its md5 can be compared with content_hash
+const articleEmbeddingTextSQL = `coalesce(articles.title, '') || ' ' || coalesce(articles.description, '')`
+
+// ArticleCluster is a group of articles whose embeddings are nearly identical,
+// such as copies of a wire story or the same article under different URLs
+type ArticleCluster struct {
+	Articles []models.Article `json:"articles"` // Oldest first
+}
+
+// EmbeddingService stores vectors of article titles and descriptions in the
+// article_embeddings table and searches them with pgvector
+type EmbeddingService struct {
+	db       *gorm.DB
+	provider embeddings.Provider
+}
+
+// NewEmbeddingService creates an embedding service using provider, which may be
+// nil when embeddings are disabled
+func NewEmbeddingService(db *gorm.DB, provider embeddings.Provider) *EmbeddingService {
+	return &EmbeddingService{db: db, provider: provider}
+}
+
+// WithContext returns an embedding service running its queries with ctx
+func (s *EmbeddingService) WithContext(ctx context.Context) *EmbeddingService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Enabled reports whether a provider is configured. Embeddings are stored with
+// pgvector, so they're never enabled on SQLite.
+func (s *EmbeddingService) Enabled() bool {
+	return s != nil && s.provider != nil && !database.IsSQLite(s.db)
+}
+
+// embeddingText is the text of an article that is embedded
+func embeddingText(article models.Article) string {
+	return article.Title + " " + article.Description
+}
+
+// contentHash returns the hex md5 of text, as Postgres' md5() does
+func contentHash(text string) string {
+	sum := md5.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// EmbedArticles computes and stores the embeddings of articles
+func (s *EmbeddingService) EmbedArticles(ctx context.Context, articles []models.Article) error {
+	if !s.Enabled() || len(articles) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(articles))
+	for i, article := range articles {
+		texts[i] = embeddingText(article)
+	}
+	vectors, err := s.provider.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed articles: %w", err)
+	}
+
+	rows := make([]models.ArticleEmbedding, len(articles))
+	for i, article := range articles {
+		rows[i] = models.ArticleEmbedding{
+			ArticleID:   article.ID,
+			Model:       s.provider.Name(),
+			ContentHash: contentHash(texts[i]),
+			Embedding:   vectors[i],
+		}
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model", "content_hash", "embedding", "updated_at"}),
+	}).Create(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to store embeddings: %w", err)
+	}
+	return nil
+}
+
+// EmbedPending embeds up to limit of the newest articles without an embedding
+// from the current provider, or whose title or description changed since, and
+// returns how many were embedded
+func (s *EmbeddingService) EmbedPending(ctx context.Context, limit int) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	var articles []models.Article
+	err := s.db.Where("articles.is_not_news = ?", false).
+		Where("NOT EXISTS (SELECT 1 FROM article_embeddings WHERE article_embeddings.article_id = articles.id AND article_embeddings.model = ? AND article_embeddings.content_hash = md5("+articleEmbeddingTextSQL+"))", s.provider.Name()).
+		Order("articles.created_at DESC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get articles without embeddings: %w", err)
+	}
+
+	if err := s.EmbedArticles(ctx, articles); err != nil {
+		return 0, err
+	}
+	if len(articles) > 0 {
+		log.Printf("🧭 Embedded %d articles with %s", len(articles), s.provider.Name())
+	}
+	return len(articles), nil
+}
+
+// RunJob handles an embedding job
+func (s *EmbeddingService) RunJob(ctx context.Context) error {
+	_, err := s.EmbedPending(ctx, embeddingsBatchSize)
+	return err
+}
+
+// Nearest returns up to limit news articles embedded by the current provider,
+// most similar to vector first, with their cosine similarity as the score.
+// Scopes narrow the candidates.
+func (s *EmbeddingService) Nearest(vector []float32, limit int, scopes ...func(*gorm.DB) *gorm.DB) ([]RelatedArticle, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	query := models.Vector(vector)
+	var nearest []RelatedArticle
+	err := s.db.Model(&models.Article{}).
+		Select("articles.*, 1 - (article_embeddings.embedding <=> ?::vector) AS score", query).
+		Joins("JOIN article_embeddings ON article_embeddings.article_id = articles.id").
+		Where("article_embeddings.model = ? AND articles.is_not_news = ?", s.provider.Name(), false).
+		Scopes(append(scopes, domains.NotBlocked)...).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "article_embeddings.embedding <=> ?::vector", Vars: []interface{}{query}}}).
+		Limit(limit).
+		Scan(&nearest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	return nearest, nil
+}
+
+// Similar returns up to limit articles from within a month of an article whose
+// embeddings are nearest its own. It returns nothing when the article hasn't
+// been embedded by the current provider yet.
+func (s *EmbeddingService) Similar(article models.Article, limit int) ([]RelatedArticle, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	var embedding models.ArticleEmbedding
+	err := s.db.Where("article_id = ? AND model = ?", article.ID, s.provider.Name()).First(&embedding).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load article embedding: %w", err)
+	}
+
+	return s.Nearest(embedding.Embedding, limit, func(db *gorm.DB) *gorm.DB {
+		return db.Where("articles.id <> ?", article.ID).
+			Where("articles.created_at BETWEEN ? AND ?", article.CreatedAt.Add(-relatedWindow), article.CreatedAt.Add(relatedWindow))
+	})
+}
+
+// Search embeds a query and returns up to limit articles nearest to it
+func (s *EmbeddingService) Search(ctx context.Context, query string, limit int) ([]RelatedArticle, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return s.Nearest(vectors[0], limit)
+}
+
+// DuplicateClusters groups the articles created since a time whose embeddings
+// have at least minSimilarity, largest groups first
+func (s *EmbeddingService) DuplicateClusters(since time.Time, minSimilarity float64) ([]ArticleCluster, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	var pairs []struct {
+		First  uuid.UUID
+		Second uuid.UUID
+	}
+	err := s.db.Raw(`SELECT ours.article_id AS first, theirs.article_id AS second
+		FROM article_embeddings AS ours
+		JOIN article_embeddings AS theirs ON theirs.model = ours.model AND theirs.article_id > ours.article_id
+		JOIN articles AS our_articles ON our_articles.id = ours.article_id
+		JOIN articles AS their_articles ON their_articles.id = theirs.article_id
+		WHERE ours.model = ? AND our_articles.created_at >= ? AND their_articles.created_at >= ?
+			AND (ours.embedding <=> theirs.embedding) <= ?`,
+		s.provider.Name(), since, since, 1-minSimilarity).
+		Scan(&pairs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate articles: %w", err)
+	}
+
+	// Union the pairs into clusters
+	parent := make(map[uuid.UUID]uuid.UUID)
+	var find func(uuid.UUID) uuid.UUID
+	find = func(id uuid.UUID) uuid.UUID {
+		if p, ok := parent[id]; ok && p != id {
+			root := find(p)
+			parent[id] = root
+			return root
+		}
+		parent[id] = id
+		return id
+	}
+	for _, pair := range pairs {
+		parent[find(pair.First)] = find(pair.Second)
+	}
+	if len(parent) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(parent))
+	for id := range parent {
+		ids = append(ids, id)
+	}
+	var articles []models.Article
+	if err := s.db.Where("id IN ?", ids).Order("created_at").Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load duplicate articles: %w", err)
+	}
+
+	groups := make(map[uuid.UUID]int)
+	var clusters []ArticleCluster
+	for _, article := range articles {
+		root := find(article.ID)
+		i, ok := groups[root]
+		if !ok {
+			i = len(clusters)
+			groups[root] = i
+			clusters = append(clusters, ArticleCluster{})
+		}
+		clusters[i].Articles = append(clusters[i].Articles, article)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Articles) > len(clusters[j].Articles)
+	})
+	return clusters, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"open-news/internal/embeddings"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentHash(t *testing.T) {
+	assert.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", contentHash(""))
+	assert.Equal(t, contentHash("Title Description"), contentHash(embeddingText(models.Article{Title: "Title", Description: "Description"})))
+}
+
+func TestEmbeddingService_Disabled(t *testing.T) {
+	service := NewEmbeddingService(nil, nil)
+	assert.False(t, service.Enabled())
+
+	processed, err := service.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+}
+
+func TestEmbeddingService(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		t.Skipf("Skipping test - pgvector not available: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&models.ArticleEmbedding{}))
+	db.Exec("DELETE FROM article_embeddings")
+
+	service := NewEmbeddingService(db, embeddings.NewHashProvider(256))
+	article := models.Article{URL: "https://example.com/ecb", Title: "European Central Bank raises interest rates", Description: "Lagarde signals more hikes"}
+	story := models.Article{URL: "https://other.example/ecb", Title: "Central bank raises rates as Lagarde signals hikes"}
+	copied := models.Article{URL: "https://copy.example/ecb", Title: "European Central Bank raises interest rates", Description: "Lagarde signals more hikes"}
+	unrelated := models.Article{URL: "https://example.com/football", Title: "Local team wins cup final"}
+	for _, a := range []*models.Article{&article, &story, &copied, &unrelated} {
+		require.NoError(t, db.Create(a).Error)
+	}
+
+	processed, err := service.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, processed)
+	processed, err = service.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed, "unchanged articles aren't embedded again")
+
+	similar, err := service.Similar(article, 10)
+	require.NoError(t, err)
+	require.Len(t, similar, 3)
+	assert.Equal(t, copied.ID, similar[0].ID)
+	assert.InDelta(t, 1.0, similar[0].Score, 1e-4)
+	assert.Equal(t, story.ID, similar[1].ID)
+
+	// Related articles skip the copy and the unrelated article
+	related, err := NewRelatedService(db, service).Related(article, 10)
+	require.NoError(t, err)
+	require.Len(t, related, 1)
+	assert.Equal(t, story.ID, related[0].ID)
+
+	found, err := service.Search(context.Background(), "football cup", 1)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, unrelated.ID, found[0].ID)
+
+	clusters, err := service.DuplicateClusters(time.Now().Add(-time.Hour), 0.95)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.ElementsMatch(t, []string{article.URL, copied.URL}, []string{clusters[0].Articles[0].URL, clusters[0].Articles[1].URL})
+
+	// Editing the title embeds the article again
+	require.NoError(t, db.Model(&unrelated).Update("title", "Local team loses cup final").Error)
+	processed, err = service.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"open-news/internal/facts"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EntitySlug normalizes an entity name or slug for lookups: lowercased, with
+// runs of spaces and hyphens replaced by a single hyphen
+func EntitySlug(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '\t' || r == '\n' || r == '_'
+	}), "-")
+}
+
+// EntityTimelineBucket counts the articles naming an entity on one day
+type EntityTimelineBucket struct {
+	Date     time.Time `json:"date"`
+	Articles int       `json:"articles"`
+}
+
+// EntityService maintains the index of people, organizations and places named
+// in article facts and lists the coverage of each
+type EntityService struct {
+	db *gorm.DB
+}
+
+// NewEntityService creates a new entity service
+func NewEntityService(db *gorm.DB) *EntityService {
+	return &EntityService{db: db}
+}
+
+// indexEntities replaces the entities linked to an article with those its facts
+// name, and updates the article counts of every entity it gained or lost
+func indexEntities(tx *gorm.DB, article *models.Article, found []models.ArticleFact) error {
+	var previous []uuid.UUID
+	if err := tx.Model(&models.ArticleEntity{}).Where("article_id = ?", article.ID).Pluck("entity_id", &previous).Error; err != nil {
+		return fmt.Errorf("failed to load article entities: %w", err)
+	}
+	if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleEntity{}).Error; err != nil {
+		return fmt.Errorf("failed to clear article entities: %w", err)
+	}
+
+	seenAt := article.CreatedAt
+	if article.PublishedAt != nil {
+		seenAt = *article.PublishedAt
+	}
+
+	links := make(map[uuid.UUID]float64)
+	for _, fact := range found {
+		slug := EntitySlug(fact.FactText)
+		if !facts.IsEntity(fact.FactType) || slug == "" {
+			continue
+		}
+
+		entity := models.Entity{Name: strings.TrimSpace(fact.FactText), Slug: slug, Type: fact.FactType, FirstSeenAt: seenAt, LastSeenAt: seenAt}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "slug"}, {Name: "type"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "first_seen_at"}, Value: gorm.Expr("CASE WHEN EXCLUDED.first_seen_at < entities.first_seen_at THEN EXCLUDED.first_seen_at ELSE entities.first_seen_at END")},
+				{Column: clause.Column{Name: "last_seen_at"}, Value: gorm.Expr("CASE WHEN EXCLUDED.last_seen_at > entities.last_seen_at THEN EXCLUDED.last_seen_at ELSE entities.last_seen_at END")},
+			},
+		}).Create(&entity).Error
+		if err != nil {
+			return fmt.Errorf("failed to save entity %s: %w", entity.Name, err)
+		}
+		// The upsert doesn't return the existing row's ID
+		if err := tx.Where("slug = ? AND type = ?", slug, fact.FactType).First(&entity).Error; err != nil {
+			return err
+		}
+		if current, ok := links[entity.ID]; !ok || fact.Confidence > current {
+			links[entity.ID] = fact.Confidence
+		}
+	}
+
+	affected := previous
+	for entityID, confidence := range links {
+		link := models.ArticleEntity{ArticleID: article.ID, EntityID: entityID, Confidence: confidence}
+		if err := tx.Create(&link).Error; err != nil {
+			return fmt.Errorf("failed to link entity: %w", err)
+		}
+		affected = append(affected, entityID)
+	}
+	return updateEntityCounts(tx, affected)
+}
+
+// updateEntityCounts recounts the articles naming each entity
+func updateEntityCounts(tx *gorm.DB, entityIDs []uuid.UUID) error {
+	if len(entityIDs) == 0 {
+		return nil
+	}
+	err := tx.Model(&models.Entity{}).Where("id IN ?", entityIDs).
+		UpdateColumn("article_count", gorm.Expr("(SELECT COUNT(*) FROM article_entities WHERE article_entities.entity_id = entities.id)")).Error
+	if err != nil {
+		return fmt.Errorf("failed to update entity article counts: %w", err)
+	}
+	return nil
+}
+
+// unlinkArticle removes an article from the index before it's deleted
+func unlinkArticle(tx *gorm.DB, articleID uuid.UUID) error {
+	var entityIDs []uuid.UUID
+	if err := tx.Model(&models.ArticleEntity{}).Where("article_id = ?", articleID).Pluck("entity_id", &entityIDs).Error; err != nil {
+		return fmt.Errorf("failed to load article entities: %w", err)
+	}
+	if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleEntity{}).Error; err != nil {
+		return fmt.Errorf("failed to delete article entities: %w", err)
+	}
+	return updateEntityCounts(tx, entityIDs)
+}
+
+// Lookup returns the entities with a name or slug, of entityType when it isn't
+// empty. A name such as "Georgia" can match a place and a person.
+func (s *EntityService) Lookup(name, entityType string) ([]models.Entity, error) {
+	query := s.db.Where("slug = ?", EntitySlug(name))
+	if entityType != "" {
+		query = query.Where("type = ?", entityType)
+	}
+	var entities []models.Entity
+	err := query.Order("article_count DESC").Find(&entities).Error
+	return entities, err
+}
+
+// Top returns the entities named in the most articles, optionally of one type
+// or with names containing search
+func (s *EntityService) Top(entityType, search string, limit int) ([]models.Entity, error) {
+	query := s.db.Model(&models.Entity{}).Where("article_count > 0")
+	if entityType != "" {
+		query = query.Where("type = ?", entityType)
+	}
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("slug LIKE ?", "%"+EntitySlug(search)+"%")
+	}
+	var entities []models.Entity
+	err := query.Order("article_count DESC, last_seen_at DESC").Limit(limit).Find(&entities).Error
+	return entities, err
+}
+
+// coverageQuery selects the articles naming any of the entities
+func (s *EntityService) coverageQuery(entityIDs []uuid.UUID) *gorm.DB {
+	return s.db.Model(&models.Article{}).
+		Where("articles.is_not_news = ?", false).
+		Where("EXISTS (SELECT 1 FROM article_entities WHERE article_entities.article_id = articles.id AND article_entities.entity_id IN ?)", entityIDs)
+}
+
+// Coverage returns a page of the articles naming any of the entities, newest
+// first, with the total number of articles
+func (s *EntityService) Coverage(entityIDs []uuid.UUID, limit, offset int) ([]models.Article, int64, error) {
+	var total int64
+	if err := s.coverageQuery(entityIDs).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count coverage: %w", err)
+	}
+
+	var articles []models.Article
+	err := s.coverageQuery(entityIDs).
+		Order("COALESCE(articles.published_at, articles.created_at) DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load coverage: %w", err)
+	}
+	return articles, total, nil
+}
+
+// Timeline counts the articles naming any of the entities per UTC day since a
+// time. Days are counted here rather than with date_trunc so the query runs on
+// SQLite too.
+func (s *EntityService) Timeline(entityIDs []uuid.UUID, since time.Time) ([]EntityTimelineBucket, error) {
+	var dates []struct {
+		PublishedAt *time.Time
+		CreatedAt   time.Time
+	}
+	err := s.coverageQuery(entityIDs).
+		Select("articles.published_at, articles.created_at").
+		Where("COALESCE(articles.published_at, articles.created_at) >= ?", since).
+		Order("COALESCE(articles.published_at, articles.created_at)").
+		Scan(&dates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load coverage timeline: %w", err)
+	}
+
+	var buckets []EntityTimelineBucket
+	for _, date := range dates {
+		t := date.CreatedAt
+		if date.PublishedAt != nil {
+			t = *date.PublishedAt
+		}
+		day := t.UTC().Truncate(24 * time.Hour)
+		if n := len(buckets); n > 0 && buckets[n-1].Date.Equal(day) {
+			buckets[n-1].Articles++
+			continue
+		}
+		buckets = append(buckets, EntityTimelineBucket{Date: day, Articles: 1})
+	}
+	return buckets, nil
+}
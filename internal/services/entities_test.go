@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"open-news/internal/facts"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubExtractor returns the same facts for every article
+type stubExtractor []facts.Fact
+
+func (e stubExtractor) Name() string { return "stub" }
+
+func (e stubExtractor) Extract(ctx context.Context, input facts.Input) ([]facts.Fact, error) {
+	return e, nil
+}
+
+func TestEntitySlug(t *testing.T) {
+	assert.Equal(t, "christine-lagarde", EntitySlug("Christine  Lagarde"))
+	assert.Equal(t, "christine-lagarde", EntitySlug("christine-lagarde"))
+	assert.Equal(t, "at&t", EntitySlug(" AT&T "))
+	assert.Empty(t, EntitySlug("  "))
+}
+
+func TestEntityIndex(t *testing.T) {
+	db := setupTestDB(t)
+	entities := NewEntityService(db)
+	lagarde := stubExtractor{
+		{Type: facts.TypePerson, Text: "Christine Lagarde", Confidence: 0.8},
+		{Type: facts.TypeOrganization, Text: "European Central Bank", Confidence: 0.8},
+		{Type: facts.TypeClaim, Text: "Rates rose.", Confidence: 0.6},
+	}
+
+	older := time.Now().Add(-48 * time.Hour)
+	first := models.Article{URL: "https://example.com/ecb-1", Title: "ECB raises rates", PublishedAt: &older}
+	second := models.Article{URL: "https://example.com/ecb-2", Title: "Lagarde speaks"}
+	require.NoError(t, db.Create(&first).Error)
+	require.NoError(t, db.Create(&second).Error)
+
+	service := NewFactsService(db, lagarde)
+	_, err := service.Extract(context.Background(), &first)
+	require.NoError(t, err)
+	_, err = service.Extract(context.Background(), &second)
+	require.NoError(t, err)
+
+	found, err := entities.Lookup("christine-lagarde", "")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "Christine Lagarde", found[0].Name)
+	assert.Equal(t, 2, found[0].ArticleCount)
+	assert.WithinDuration(t, older, found[0].FirstSeenAt, time.Second)
+
+	articles, total, err := entities.Coverage([]uuid.UUID{found[0].ID}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, second.ID, articles[0].ID, "newest first")
+
+	timeline, err := entities.Timeline([]uuid.UUID{found[0].ID}, time.Now().AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Len(t, timeline, 2)
+
+	// Extracting again without the person unlinks it
+	_, err = NewFactsService(db, stubExtractor{lagarde[1]}).Extract(context.Background(), &second)
+	require.NoError(t, err)
+	found, err = entities.Lookup("Christine Lagarde", facts.TypePerson)
+	require.NoError(t, err)
+	assert.Equal(t, 1, found[0].ArticleCount)
+
+	top, err := entities.Top(facts.TypeOrganization, "central", 10)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, 2, top[0].ArticleCount)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/facts"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// factsBatchSize is how many articles a fact extraction job processes
+const factsBatchSize = 50
+
+// ExtractFactsPayload is the payload of a fact extraction job. Without an article,
+// the job extracts facts from a batch of articles that don't have them yet.
+type ExtractFactsPayload struct {
+	ArticleID *uuid.UUID `json:"article_id,omitempty"`
+}
+
+// FactsService stores the claims, quotes, statistics and entities an extractor
+// finds in article text as article facts, and indexes the entities
+type FactsService struct {
+	db        *gorm.DB
+	extractor facts.Extractor
+}
+
+// NewFactsService creates a facts service using extractor
+func NewFactsService(db *gorm.DB, extractor facts.Extractor) *FactsService {
+	return &FactsService{db: db, extractor: extractor}
+}
+
+// WithContext returns a facts service running its queries with ctx
+func (s *FactsService) WithContext(ctx context.Context) *FactsService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Extract replaces an article's facts with those the extractor finds now
+func (s *FactsService) Extract(ctx context.Context, article *models.Article) ([]models.ArticleFact, error) {
+	found, err := s.extractor.Extract(ctx, facts.Input{
+		Title:       article.Title,
+		Description: article.Description,
+		Text:        article.TextContent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract facts from %s: %w", article.URL, err)
+	}
+
+	now := time.Now()
+	stored := make([]models.ArticleFact, len(found))
+	for i, fact := range found {
+		stored[i] = models.ArticleFact{
+			ArticleID:     article.ID,
+			FactText:      fact.Text,
+			FactType:      fact.Type,
+			Context:       fact.Context,
+			Confidence:    fact.Confidence,
+			StartPosition: fact.Start,
+			EndPosition:   fact.End,
+			ExtractedBy:   s.extractor.Name(),
+			ExtractedAt:   now,
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleFact{}).Error; err != nil {
+			return err
+		}
+		if len(stored) > 0 {
+			if err := tx.Create(&stored).Error; err != nil {
+				return err
+			}
+		}
+		if err := indexEntities(tx, article, stored); err != nil {
+			return err
+		}
+		return tx.Model(article).UpdateColumn("facts_extracted_at", now).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store facts for %s: %w", article.URL, err)
+	}
+	article.FactsExtractedAt = &now
+	return stored, nil
+}
+
+// ExtractArticle extracts the facts of a single article
+func (s *FactsService) ExtractArticle(ctx context.Context, articleID uuid.UUID) ([]models.ArticleFact, error) {
+	var article models.Article
+	if err := s.db.First(&article, "id = ?", articleID).Error; err != nil {
+		return nil, err
+	}
+	return s.Extract(ctx, &article)
+}
+
+// ExtractPending extracts facts from up to limit of the newest fetched articles
+// that don't have them, and returns how many were processed. Articles that fail
+// are logged and retried on the next run.
+func (s *FactsService) ExtractPending(ctx context.Context, limit int) (int, error) {
+	var articles []models.Article
+	err := s.db.Where("facts_extracted_at IS NULL AND is_not_news = ?", false).
+		Where("text_content <> '' OR description <> ''").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get articles without facts: %w", err)
+	}
+
+	processed := 0
+	for i := range articles {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+		found, err := s.Extract(ctx, &articles[i])
+		if err != nil {
+			log.Printf("❌ %v", err)
+			continue
+		}
+		processed++
+		log.Printf("🔎 Extracted %d facts from %s", len(found), articles[i].URL)
+	}
+	return processed, nil
+}
+
+// RunJob handles a fact extraction job
+func (s *FactsService) RunJob(ctx context.Context, payload ExtractFactsPayload) error {
+	if payload.ArticleID != nil {
+		_, err := s.ExtractArticle(ctx, *payload.ArticleID)
+		return err
+	}
+	_, err := s.ExtractPending(ctx, factsBatchSize)
+	return err
+}
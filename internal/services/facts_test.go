@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"open-news/internal/facts"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactsService_ExtractPending(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewFactsService(db, facts.NewRuleExtractor())
+
+	article := models.Article{
+		URL:         "https://example.com/facts",
+		Title:       "Rates rise",
+		TextContent: `"Inflation is still too high," President Christine Lagarde said in Frankfurt.`,
+	}
+	require.NoError(t, db.Create(&article).Error)
+	empty := models.Article{URL: "https://example.com/no-text", Title: "No text"}
+	require.NoError(t, db.Create(&empty).Error)
+
+	processed, err := service.ExtractPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed, "articles without text are left alone")
+
+	var stored []models.ArticleFact
+	require.NoError(t, db.Where("article_id = ?", article.ID).Find(&stored).Error)
+	assert.NotEmpty(t, stored)
+	assert.Equal(t, "rules", stored[0].ExtractedBy)
+
+	// Extracted articles aren't picked up again, and extracting again replaces the facts
+	processed, err = service.ExtractPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	again, err := service.ExtractArticle(context.Background(), article.ID)
+	require.NoError(t, err)
+	var count int64
+	db.Model(&models.ArticleFact{}).Where("article_id = ?", article.ID).Count(&count)
+	assert.Equal(t, int64(len(again)), count)
+}
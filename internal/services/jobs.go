@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"open-news/internal/database"
+	"open-news/internal/errreport"
+	"open-news/internal/models"
+	"open-news/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// Job types run by the background workers
+const (
+	JobTypeRefreshFollows     = "refresh_follows"     // Import follows for users due a refresh
+	JobTypeEnrichProfiles     = "enrich_profiles"     // Refresh a batch of stale source profiles
+	JobTypeUpdateMetrics      = "update_metrics"      // Recalculate quality scores and classify topics
+	JobTypeBackfillSource     = "backfill_source"     // Import recent link posts by one source
+	JobTypeRefetchArticle     = "refetch_article"     // Fetch one article's page again
+	JobTypeSendDigest         = "send_digest"         // Post or message the top-stories digest
+	JobTypeSendEmailDigests   = "send_email_digests"  // Email the digest to subscribers who are due one
+	JobTypeVerifySources      = "verify_sources"      // Match a batch of sources to the news sites they share
+	JobTypeCheckSpam          = "check_spam"          // Flag recently active sources that look like spam
+	JobTypeExtractFacts       = "extract_facts"       // Extract facts from one article, or a batch without them
+	JobTypeEmbedArticles      = "embed_articles"      // Embed a batch of new or edited articles
+	JobTypeSummarize          = "summarize_articles"  // Summarize one article, or a batch without summaries
+	JobTypeLearnTopics        = "learn_topics"        // Relearn users' topic preferences from follows' shares and clicks
+	JobTypeDetectBreaking     = "detect_breaking"     // Mark stories many sources just shared as breaking
+	JobTypeApplyRetention     = "apply_retention"     // Purge old cached HTML, dead articles and feed items
+	JobTypeMaintainPartitions = "maintain_partitions" // Create the coming months' partitions of partitioned tables
+	JobTypeSyncLabels         = "sync_labels"         // Issue and retract labeler labels from scoring signals
+	JobTypePollSiteFeeds      = "poll_site_feeds"     // Store new articles from the feeds of sites that opted in
+	JobTypeExportUserData     = "export_user_data"    // Build the archive of one user's data they asked for
+)
+
+// BackfillSourcePayload is the payload of a backfill_source job
+type BackfillSourcePayload struct {
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+// RefetchArticlePayload is the payload of a refetch_article job
+type RefetchArticlePayload struct {
+	ArticleID uuid.UUID `json:"article_id"`
+}
+
+const (
+	// defaultJobMaxAttempts is how many times a job runs before it's marked failed
+	defaultJobMaxAttempts = 3
+
+	// jobRetryBaseDelay is the wait before the first retry; it doubles with each attempt
+	jobRetryBaseDelay = time.Minute
+
+	// jobRetryMaxDelay caps the wait between retries
+	jobRetryMaxDelay = time.Hour
+
+	// jobStaleAfter is how long a job can stay running before it's assumed lost
+	// with a crashed worker and queued again
+	jobStaleAfter = time.Hour
+)
+
+// JobHandler runs a job with its JSON payload. ctx carries the run's span and
+// is cancelled when the workers stop; handlers run their queries and calls with
+// it so they're traced as part of the run.
+type JobHandler func(ctx context.Context, payload []byte) error
+
+// JobService stores background task runs in the jobs table and retries failed ones
+type JobService struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+// NewJobService creates a new job service with no handlers registered
+func NewJobService(db *gorm.DB) *JobService {
+	return &JobService{
+		db:       db,
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+// Register sets the handler that runs jobs of the given type
+func (s *JobService) Register(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// handler returns the handler registered for a job type
+func (s *JobService) handler(jobType string) (JobHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.handlers[jobType]
+	return handler, ok
+}
+
+// Enqueue stores a job to be run by RunDue at runAt
+func (s *JobService) Enqueue(jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	encoded, err := encodeJobPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     encoded,
+		Status:      models.JobStatusPending,
+		MaxAttempts: defaultJobMaxAttempts,
+		NextRunAt:   runAt,
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return job, nil
+}
+
+// Run records a job and runs it immediately, for tasks the workers start on a
+// schedule. A failed run is left pending and retried by RunDue.
+func (s *JobService) Run(ctx context.Context, jobType string, payload interface{}) error {
+	encoded, err := encodeJobPayload(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     encoded,
+		Status:      models.JobStatusRunning,
+		Attempts:    1,
+		MaxAttempts: defaultJobMaxAttempts,
+		NextRunAt:   now,
+		StartedAt:   &now,
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		// Still run the task; losing its history is better than skipping it
+		log.Printf("⚠️  Failed to record %s job: %v", jobType, err)
+		return s.call(ctx, job)
+	}
+	return s.execute(ctx, job)
+}
+
+// RunDue claims up to limit pending jobs whose time has come and runs them.
+// Claiming skips rows locked by other workers, so several instances can share the table.
+// It returns the number of jobs run.
+func (s *JobService) RunDue(ctx context.Context, limit int) (int, error) {
+	if err := s.requeueStale(); err != nil {
+		log.Printf("⚠️  Failed to requeue stale jobs: %v", err)
+	}
+
+	// SQLite has no row locks; its writes are serialized, so a claim can't
+	// race another anyway
+	lock := "FOR UPDATE SKIP LOCKED"
+	if database.IsSQLite(s.db) {
+		lock = ""
+	}
+
+	now := time.Now()
+	var jobs []models.Job
+	err := s.db.Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE status = ? AND next_run_at <= ?
+			ORDER BY next_run_at
+			LIMIT ?
+			`+lock+`
+		)
+		RETURNING *`,
+		models.JobStatusRunning, now, now, models.JobStatusPending, now, limit).
+		Scan(&jobs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	for i := range jobs {
+		if err := s.execute(ctx, &jobs[i]); err != nil {
+			log.Printf("❌ %s job %s failed (attempt %d of %d): %v", jobs[i].Type, jobs[i].ID, jobs[i].Attempts, jobs[i].MaxAttempts, err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// execute runs a claimed job and stores the outcome
+func (s *JobService) execute(ctx context.Context, job *models.Job) error {
+	runErr := s.call(ctx, job)
+	if err := s.finish(job, runErr, time.Now()); err != nil {
+		log.Printf("⚠️  Failed to record outcome of %s job %s: %v", job.Type, job.ID, err)
+	}
+	return runErr
+}
+
+// call runs a job's handler in a span of its own, turning a panic into an error
+// so one bad job doesn't take the worker down. Failures are reported with the job.
+func (s *JobService) call(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := s.handler(job.Type)
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	ctx, span := tracing.Start(ctx, "job "+job.Type,
+		attribute.String("job.type", job.Type),
+		attribute.String("job.id", job.ID.String()),
+		attribute.Int("job.attempt", job.Attempts),
+	)
+	defer func() {
+		tags := errreport.Tags{"job.type": job.Type, "job.id": job.ID.String(), "job.attempt": strconv.Itoa(job.Attempts)}
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+			errreport.CapturePanic(r, debug.Stack(), tags)
+		} else {
+			errreport.Capture(err, tags)
+		}
+		tracing.End(span, err)
+	}()
+	return handler(ctx, []byte(job.Payload))
+}
+
+// finish marks a job succeeded, schedules its retry, or marks it failed once
+// it's out of attempts
+func (s *JobService) finish(job *models.Job, runErr error, now time.Time) error {
+	applyJobOutcome(job, runErr, now)
+	return s.db.Model(job).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"next_run_at": job.NextRunAt,
+		"last_error":  job.LastError,
+		"finished_at": job.FinishedAt,
+	}).Error
+}
+
+// applyJobOutcome updates a job's status after a run
+func applyJobOutcome(job *models.Job, runErr error, now time.Time) {
+	job.FinishedAt = &now
+	if runErr == nil {
+		job.Status = models.JobStatusSucceeded
+		return
+	}
+
+	job.LastError = runErr.Error()
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = models.JobStatusFailed
+		return
+	}
+	job.Status = models.JobStatusPending
+	job.NextRunAt = now.Add(jobRetryDelay(job.Attempts))
+}
+
+// jobRetryDelay is the wait before retrying a job that failed its nth attempt
+func jobRetryDelay(attempts int) time.Duration {
+	delay := jobRetryBaseDelay
+	for i := 1; i < attempts && delay < jobRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > jobRetryMaxDelay {
+		delay = jobRetryMaxDelay
+	}
+	return delay
+}
+
+// requeueStale returns jobs stuck running, usually because their worker exited
+// mid-run, to the queue
+func (s *JobService) requeueStale() error {
+	return s.db.Model(&models.Job{}).
+		Where("status = ? AND started_at < ?", models.JobStatusRunning, time.Now().Add(-jobStaleAfter)).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusPending,
+			"next_run_at": time.Now(),
+			"last_error":  "worker stopped before the job finished",
+		}).Error
+}
+
+// Retry queues a failed job to run again with a fresh set of attempts
+func (s *JobService) Retry(id uuid.UUID) error {
+	result := s.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusPending,
+			"attempts":    0,
+			"next_run_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to retry job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListFailed returns failed jobs, most recent first, with the total count
+func (s *JobService) ListFailed(limit, offset int) ([]models.Job, int64, error) {
+	var jobs []models.Job
+	var total int64
+
+	query := s.db.Model(&models.Job{}).Where("status = ?", models.JobStatusFailed)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("finished_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// PruneSucceeded deletes succeeded jobs finished before the cutoff, keeping the
+// table small. Failed jobs are kept until they're retried.
+func (s *JobService) PruneSucceeded(before time.Time) (int64, error) {
+	result := s.db.Where("status = ? AND finished_at < ?", models.JobStatusSucceeded, before).Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
+
+// encodeJobPayload encodes a job payload as JSON; nil becomes an empty object
+func encodeJobPayload(payload interface{}) (string, error) {
+	if payload == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, jobRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, jobRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, jobRetryDelay(3))
+	assert.Equal(t, time.Hour, jobRetryDelay(20))
+}
+
+func TestApplyJobOutcome(t *testing.T) {
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		job := &models.Job{Attempts: 1, MaxAttempts: 3}
+		applyJobOutcome(job, nil, now)
+		assert.Equal(t, models.JobStatusSucceeded, job.Status)
+		assert.Equal(t, now, *job.FinishedAt)
+	})
+
+	t.Run("failure with attempts left is retried", func(t *testing.T) {
+		job := &models.Job{Attempts: 2, MaxAttempts: 3}
+		applyJobOutcome(job, errors.New("timeout"), now)
+		assert.Equal(t, models.JobStatusPending, job.Status)
+		assert.Equal(t, "timeout", job.LastError)
+		assert.Equal(t, now.Add(2*time.Minute), job.NextRunAt)
+	})
+
+	t.Run("last attempt fails the job", func(t *testing.T) {
+		job := &models.Job{Attempts: 3, MaxAttempts: 3}
+		applyJobOutcome(job, errors.New("timeout"), now)
+		assert.Equal(t, models.JobStatusFailed, job.Status)
+	})
+}
+
+func TestJobService_CallRecoversPanics(t *testing.T) {
+	service := NewJobService(nil)
+	service.Register("explode", func(context.Context, []byte) error { panic("boom") })
+
+	err := service.call(context.Background(), &models.Job{Type: "explode"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+
+	err = service.call(context.Background(), &models.Job{Type: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestJobService_RetryFlow(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Job{}))
+	db.Exec("DELETE FROM jobs")
+
+	service := NewJobService(db)
+	calls := 0
+	service.Register("flaky", func(_ context.Context, payload []byte) error {
+		calls++
+		assert.JSONEq(t, `{"user":"alice"}`, string(payload))
+		return errors.New("upstream unavailable")
+	})
+
+	// The first run fails and is left pending for a retry
+	err := service.Run(context.Background(), "flaky", map[string]string{"user": "alice"})
+	require.Error(t, err)
+
+	var job models.Job
+	require.NoError(t, db.Where("type = ?", "flaky").First(&job).Error)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "upstream unavailable", job.LastError)
+
+	// Retries run once due, until the job is out of attempts
+	for i := 0; i < 2; i++ {
+		db.Model(&job).Update("next_run_at", time.Now().Add(-time.Second))
+		count, err := service.RunDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	}
+	assert.Equal(t, 3, calls)
+
+	failed, total, err := service.ListFailed(20, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, job.ID, failed[0].ID)
+
+	// A retry from the admin starts a fresh set of attempts
+	require.NoError(t, service.Retry(job.ID))
+	db.First(&job, "id = ?", job.ID)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+
+	service.Register("flaky", func(context.Context, []byte) error { return nil })
+	count, err := service.RunDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	db.First(&job, "id = ?", job.ID)
+	assert.Equal(t, models.JobStatusSucceeded, job.Status)
+
+	// Only failed jobs can be retried
+	assert.Error(t, service.Retry(job.ID))
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"open-news/internal/labeler"
+	"open-news/internal/models"
+	"open-news/internal/ranking"
+
+	"gorm.io/gorm"
+)
+
+// Page sizes of label queries
+const (
+	DefaultLabelPageSize = 50
+	MaxLabelPageSize     = 250
+)
+
+// LabelSyncResult counts the labels one sync issued
+type LabelSyncResult struct {
+	Applied int `json:"applied"` // New labels
+	Negated int `json:"negated"` // Labels retracted because they no longer apply
+}
+
+// LabelService issues labels from scoring signals and serves them to label
+// queries and subscriptions
+type LabelService struct {
+	db     *gorm.DB
+	signer *labeler.Signer
+}
+
+// NewLabelService creates a new LabelService. signer may be nil to serve labels
+// already issued without issuing more.
+func NewLabelService(db *gorm.DB, signer *labeler.Signer) *LabelService {
+	return &LabelService{db: db, signer: signer}
+}
+
+// WithContext returns a label service running its queries with ctx
+func (s *LabelService) WithContext(ctx context.Context) *LabelService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Sync labels the accounts of verified sources and the posts sharing breaking
+// stories, and retracts verified source labels from sources no longer verified.
+// Breaking labels expire on their own when the story's boost ends.
+func (s *LabelService) Sync() (*LabelSyncResult, error) {
+	if s.signer == nil {
+		return nil, fmt.Errorf("labeler is not configured")
+	}
+	result := &LabelSyncResult{}
+	if err := s.syncVerifiedSources(result); err != nil {
+		return result, err
+	}
+	if err := s.syncBreaking(result); err != nil {
+		return result, err
+	}
+	if result.Applied > 0 || result.Negated > 0 {
+		log.Printf("🏷️  Issued %d labels and retracted %d", result.Applied, result.Negated)
+	}
+	return result, nil
+}
+
+// syncVerifiedSources makes the verified source labels match the sources that
+// are verified now: approved, or verified by an admin without automatic review
+func (s *LabelService) syncVerifiedSources(result *LabelSyncResult) error {
+	var dids []string
+	err := s.db.Model(&models.Source{}).
+		Where("is_verified = ? AND is_active = ?", true, true).
+		Where("verification_status IS NULL OR verification_status IN ?", []string{"", models.VerificationApproved}).
+		Where("spam_status IS NULL OR spam_status NOT IN ?", []string{models.SpamFlagged, models.SpamConfirmed}).
+		Pluck("blue_sky_d_id", &dids).Error
+	if err != nil {
+		return fmt.Errorf("failed to get verified sources: %w", err)
+	}
+	verified := make(map[string]bool, len(dids))
+	for _, did := range dids {
+		verified[did] = true
+	}
+
+	active, err := s.activeLabels(labeler.ValueVerifiedSource)
+	if err != nil {
+		return err
+	}
+	for _, did := range dids {
+		if active[did] {
+			continue
+		}
+		if err := s.issue(labeler.Label{URI: did, Val: labeler.ValueVerifiedSource}); err != nil {
+			return err
+		}
+		result.Applied++
+	}
+	for did := range active {
+		if verified[did] {
+			continue
+		}
+		if err := s.issue(labeler.Label{URI: did, Val: labeler.ValueVerifiedSource, Neg: true}); err != nil {
+			return err
+		}
+		result.Negated++
+	}
+	return nil
+}
+
+// syncBreaking labels the posts that shared stories breaking now, expiring the
+// labels when the stories stop being boosted
+func (s *LabelService) syncBreaking(result *LabelSyncResult) error {
+	var shares []struct {
+		PostURI    string
+		PostCID    string
+		BreakingAt time.Time
+	}
+	err := s.db.Table("source_articles").
+		Select("source_articles.post_uri, source_articles.post_c_id, articles.breaking_at").
+		Joins("JOIN articles ON articles.id = source_articles.article_id").
+		Where("articles.breaking_at > ?", time.Now().Add(-ranking.BreakingBoostDuration)).
+		Where("source_articles.post_uri <> ''").
+		Scan(&shares).Error
+	if err != nil {
+		return fmt.Errorf("failed to get shares of breaking stories: %w", err)
+	}
+	if len(shares) == 0 {
+		return nil
+	}
+
+	uris := make([]string, len(shares))
+	for i, share := range shares {
+		uris[i] = share.PostURI
+	}
+	var labeled []string
+	if err := s.db.Model(&models.Label{}).Where("val = ? AND uri IN ?", labeler.ValueBreaking, uris).Pluck("uri", &labeled).Error; err != nil {
+		return fmt.Errorf("failed to get breaking labels: %w", err)
+	}
+	done := make(map[string]bool, len(labeled))
+	for _, uri := range labeled {
+		done[uri] = true
+	}
+
+	for _, share := range shares {
+		if done[share.PostURI] {
+			continue
+		}
+		done[share.PostURI] = true
+		label := labeler.Label{
+			URI: share.PostURI,
+			CID: share.PostCID,
+			Val: labeler.ValueBreaking,
+			Exp: labeler.FormatTime(share.BreakingAt.Add(ranking.BreakingBoostDuration)),
+		}
+		if err := s.issue(label); err != nil {
+			return err
+		}
+		result.Applied++
+	}
+	return nil
+}
+
+// activeLabels returns the subjects whose latest label with a value applies it
+func (s *LabelService) activeLabels(val string) (map[string]bool, error) {
+	var labels []models.Label
+	if err := s.db.Select("uri", "neg").Where("val = ?", val).Order("seq").Find(&labels).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s labels: %w", val, err)
+	}
+	active := make(map[string]bool)
+	for _, label := range labels {
+		if label.Neg {
+			delete(active, label.URI)
+		} else {
+			active[label.URI] = true
+		}
+	}
+	return active, nil
+}
+
+// issue signs and stores a label
+func (s *LabelService) issue(label labeler.Label) error {
+	label.Cts = labeler.FormatTime(time.Now())
+	if err := s.signer.Sign(&label); err != nil {
+		return err
+	}
+	row := models.Label{
+		URI: label.URI,
+		CID: label.CID,
+		Val: label.Val,
+		Neg: label.Neg,
+		Cts: label.Cts,
+		Exp: label.Exp,
+		Src: label.Src,
+		Sig: label.Sig,
+	}
+	if err := s.db.Create(&row).Error; err != nil {
+		return fmt.Errorf("failed to store %s label on %s: %w", label.Val, label.URI, err)
+	}
+	return nil
+}
+
+// LabelQuery selects labels for com.atproto.label.queryLabels
+type LabelQuery struct {
+	URIPatterns []string // Subjects to match; a trailing * matches a prefix
+	Sources     []string // Labeler DIDs; empty for any
+	Cursor      int64    // Seq of the last label of the previous page
+	Limit       int
+}
+
+// Query returns a page of labels matching a query, oldest first, and the cursor
+// of the next page, or 0 at the end
+func (s *LabelService) Query(query LabelQuery) ([]labeler.Label, int64, error) {
+	if query.Limit <= 0 || query.Limit > MaxLabelPageSize {
+		query.Limit = DefaultLabelPageSize
+	}
+
+	db := s.db.Where("seq > ?", query.Cursor)
+	if len(query.Sources) > 0 {
+		db = db.Where("src IN ?", query.Sources)
+	}
+	var conditions []string
+	var args []interface{}
+	for _, pattern := range query.URIPatterns {
+		if pattern == "*" {
+			conditions, args = nil, nil
+			break
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			conditions = append(conditions, `uri LIKE ? ESCAPE '\'`)
+			args = append(args, escapeLike(prefix)+"%")
+		} else {
+			conditions = append(conditions, "uri = ?")
+			args = append(args, pattern)
+		}
+	}
+	if len(conditions) > 0 {
+		db = db.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	var rows []models.Label
+	if err := db.Order("seq").Limit(query.Limit).Find(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to query labels: %w", err)
+	}
+	var cursor int64
+	if len(rows) == query.Limit {
+		cursor = rows[len(rows)-1].Seq
+	}
+	return wireLabels(rows), cursor, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// Since returns up to limit labels issued after seq, oldest first
+func (s *LabelService) Since(seq int64, limit int) ([]models.Label, error) {
+	var rows []models.Label
+	err := s.db.Where("seq > ?", seq).Order("seq").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// LatestSeq returns the seq of the newest label, or 0 when none were issued
+func (s *LabelService) LatestSeq() (int64, error) {
+	var seq int64
+	err := s.db.Model(&models.Label{}).Select("COALESCE(MAX(seq), 0)").Scan(&seq).Error
+	return seq, err
+}
+
+// WireLabel returns a stored label in its AT Protocol shape
+func WireLabel(row models.Label) labeler.Label {
+	return labeler.Label{
+		Src: row.Src,
+		URI: row.URI,
+		CID: row.CID,
+		Val: row.Val,
+		Neg: row.Neg,
+		Cts: row.Cts,
+		Exp: row.Exp,
+		Sig: row.Sig,
+	}
+}
+
+func wireLabels(rows []models.Label) []labeler.Label {
+	labels := make([]labeler.Label, len(rows))
+	for i, row := range rows {
+		labels[i] = WireLabel(row)
+	}
+	return labels
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"open-news/internal/labeler"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelServiceSync(t *testing.T) {
+	db := setupTestDB(t)
+	key, err := labeler.GenerateKey()
+	require.NoError(t, err)
+	signer, err := labeler.NewSigner(labeler.Config{DID: "did:plc:testlabeler", SigningKey: key})
+	require.NoError(t, err)
+
+	verified := models.Source{BlueSkyDID: "did:plc:testverified", Handle: "verified.test", IsActive: true, IsVerified: true, VerificationStatus: models.VerificationApproved}
+	pending := models.Source{BlueSkyDID: "did:plc:testpending", Handle: "pending.test", IsActive: true, IsVerified: true, VerificationStatus: models.VerificationPending}
+	for _, source := range []*models.Source{&verified, &pending} {
+		require.NoError(t, db.Create(source).Error)
+	}
+	breakingAt := time.Now().Add(-10 * time.Minute)
+	breaking := models.Article{URL: "https://example.com/breaking", Title: "Breaking", BreakingAt: &breakingAt}
+	calm := models.Article{URL: "https://example.com/calm", Title: "Calm"}
+	for _, article := range []*models.Article{&breaking, &calm} {
+		require.NoError(t, db.Create(article).Error)
+	}
+	for i, article := range []models.Article{breaking, calm} {
+		share := models.SourceArticle{SourceID: verified.ID, ArticleID: article.ID, PostURI: fmt.Sprintf("at://did:plc:testverified/app.bsky.feed.post/%d", i), PostCID: "bafy", PostedAt: time.Now()}
+		require.NoError(t, db.Create(&share).Error)
+	}
+
+	service := NewLabelService(db, signer)
+	result, err := service.Sync()
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Applied, "the approved source and the breaking post")
+
+	labels, cursor, err := service.Query(LabelQuery{URIPatterns: []string{"did:plc:testverified", "at://did:plc:testverified/*"}})
+	require.NoError(t, err)
+	assert.Zero(t, cursor)
+	require.Len(t, labels, 2)
+	assert.Equal(t, labeler.ValueVerifiedSource, labels[0].Val)
+	assert.Equal(t, labeler.ValueBreaking, labels[1].Val)
+	assert.Equal(t, "at://did:plc:testverified/app.bsky.feed.post/0", labels[1].URI)
+	assert.NotEmpty(t, labels[1].Exp, "breaking labels expire with the boost")
+
+	// Nothing changed, so nothing is issued again
+	result, err = service.Sync()
+	require.NoError(t, err)
+	assert.Zero(t, result.Applied)
+
+	// A source that loses its verification has its label retracted
+	require.NoError(t, db.Model(&verified).Update("is_verified", false).Error)
+	result, err = service.Sync()
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Negated)
+
+	labels, _, err = service.Query(LabelQuery{URIPatterns: []string{"did:plc:testverified"}})
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	assert.True(t, labels[1].Neg)
+
+	// Pages continue from the cursor
+	page, cursor, err := service.Query(LabelQuery{URIPatterns: []string{"*"}, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+	require.NotZero(t, cursor)
+	page, _, err = service.Query(LabelQuery{URIPatterns: []string{"*"}, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/database"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// partitionMonthsAhead is how many months after the current one get their
+// partitions created in advance, so inserts never land in the default partition
+const partitionMonthsAhead = 2
+
+// PartitionedTable is a table partitioned by month on a timestamp column
+type PartitionedTable struct {
+	Name   string
+	Column string
+}
+
+// PartitionedTables are the tables migration 044 partitions by month
+var PartitionedTables = []PartitionedTable{
+	{Name: "source_articles", Column: "posted_at"},
+	{Name: "impressions", Column: "created_at"},
+}
+
+// PartitionService creates the monthly partitions of the partitioned tables
+// ahead of time
+type PartitionService struct {
+	db     *gorm.DB
+	tables []PartitionedTable
+}
+
+// NewPartitionService creates a new partition service
+func NewPartitionService(db *gorm.DB) *PartitionService {
+	return &PartitionService{db: db, tables: PartitionedTables}
+}
+
+// WithContext returns a partition service running its statements with ctx
+func (s *PartitionService) WithContext(ctx context.Context) *PartitionService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// PartitionName is the name of a table's partition for the month of t, such as
+// source_articles_y2025m03
+func PartitionName(table string, t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%s_y%04dm%02d", table, t.Year(), int(t.Month()))
+}
+
+// monthStart returns the first instant of the month of t, in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Maintain creates the partitions for the current month and the next
+// partitionMonthsAhead months, and returns how many it created. Tables that
+// aren't partitioned, because migration 044 wasn't run, are skipped, as is
+// everything on SQLite, which has no partitioning.
+func (s *PartitionService) Maintain(now time.Time) (int, error) {
+	created := 0
+	if database.IsSQLite(s.db) {
+		return created, nil
+	}
+	for _, table := range s.tables {
+		partitioned, err := s.isPartitioned(table.Name)
+		if err != nil {
+			return created, err
+		}
+		if !partitioned {
+			continue
+		}
+		for i := 0; i <= partitionMonthsAhead; i++ {
+			added, err := s.ensureMonth(table, monthStart(now).AddDate(0, i, 0))
+			if err != nil {
+				return created, err
+			}
+			if added {
+				created++
+			}
+		}
+	}
+	if created > 0 {
+		log.Printf("🗂️  Created %d table partitions", created)
+	}
+	return created, nil
+}
+
+// isPartitioned reports whether a table is partitioned
+func (s *PartitionService) isPartitioned(table string) (bool, error) {
+	var partitioned bool
+	err := s.db.Raw(`SELECT EXISTS (
+		SELECT 1 FROM pg_partitioned_table
+		JOIN pg_class ON pg_class.oid = pg_partitioned_table.partrelid
+		WHERE pg_class.relname = ? AND pg_table_is_visible(pg_class.oid))`, table).Scan(&partitioned).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s is partitioned: %w", table, err)
+	}
+	return partitioned, nil
+}
+
+// ensureMonth creates a table's partition for a month unless it exists. Rows
+// for the month already in the default partition are moved into the new one,
+// since Postgres won't create a partition the default partition has rows for.
+func (s *PartitionService) ensureMonth(table PartitionedTable, month time.Time) (bool, error) {
+	name := PartitionName(table.Name, month)
+	var exists bool
+	if err := s.db.Raw("SELECT to_regclass(?) IS NOT NULL", name).Scan(&exists).Error; err != nil {
+		return false, fmt.Errorf("failed to look up partition %s: %w", name, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	from, to := month, month.AddDate(0, 1, 0)
+	parent := pq.QuoteIdentifier(table.Name)
+	fallback := pq.QuoteIdentifier(table.Name + "_default")
+	column := pq.QuoteIdentifier(table.Column)
+	inMonth := fmt.Sprintf("%s >= ? AND %s < ?", column, column)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var stray bool
+		err := tx.Raw(fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s)", fallback, inMonth), from, to).Scan(&stray).Error
+		if err != nil {
+			return err
+		}
+		if stray {
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", parent, fallback)).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Exec(fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+			pq.QuoteIdentifier(name), parent, pq.QuoteLiteral(from.Format(time.RFC3339)), pq.QuoteLiteral(to.Format(time.RFC3339)))).Error
+		if err != nil {
+			return err
+		}
+
+		if !stray {
+			return nil
+		}
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE %s", parent, fallback, inMonth), from, to).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", fallback, inMonth), from, to).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s DEFAULT", parent, fallback)).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create partition %s: %w", name, err)
+	}
+	return true, nil
+}
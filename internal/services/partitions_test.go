@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionName(t *testing.T) {
+	month := time.Date(2025, time.March, 31, 23, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	assert.Equal(t, "source_articles_y2025m04", PartitionName("source_articles", month), "months are UTC")
+	assert.Equal(t, time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), monthStart(month))
+}
+
+func TestMaintainPartitions(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := func() {
+		db.Exec("DROP TABLE IF EXISTS partition_test_events")
+		db.Exec("DROP TABLE IF EXISTS partition_test_plain")
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	require.NoError(t, db.Exec("CREATE TABLE partition_test_events (id INTEGER, created_at TIMESTAMPTZ) PARTITION BY RANGE (created_at)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE partition_test_events_default PARTITION OF partition_test_events DEFAULT").Error)
+	require.NoError(t, db.Exec("CREATE TABLE partition_test_plain (id INTEGER, created_at TIMESTAMPTZ)").Error)
+
+	now := time.Date(2025, time.November, 15, 12, 0, 0, 0, time.UTC)
+	// A row for next month arrived before its partition existed
+	require.NoError(t, db.Exec("INSERT INTO partition_test_events VALUES (1, ?), (2, ?)", now.AddDate(0, 1, 0), now.AddDate(0, 6, 0)).Error)
+
+	service := &PartitionService{db: db, tables: []PartitionedTable{
+		{Name: "partition_test_events", Column: "created_at"},
+		{Name: "partition_test_plain", Column: "created_at"},
+	}}
+	created, err := service.Maintain(now)
+	require.NoError(t, err)
+	assert.Equal(t, partitionMonthsAhead+1, created, "tables that aren't partitioned are skipped")
+
+	var count int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM partition_test_events_y2025m12").Scan(&count).Error)
+	assert.Equal(t, int64(1), count, "the row moves out of the default partition")
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM partition_test_events_default").Scan(&count).Error)
+	assert.Equal(t, int64(1), count, "rows past the created months stay in the default partition")
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM partition_test_events").Scan(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	created, err = service.Maintain(now)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"open-news/internal/topics"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// PreferencesService manages users' personalized feed preferences
+type PreferencesService struct {
+	db *gorm.DB
+}
+
+// NewPreferencesService creates a new preferences service
+func NewPreferencesService(db *gorm.DB) *PreferencesService {
+	return &PreferencesService{db: db}
+}
+
+// WithContext returns a preferences service running its queries with ctx
+func (s *PreferencesService) WithContext(ctx context.Context) *PreferencesService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// SetShowSeenArticles sets whether a user's personal feeds keep showing articles they
+// were already served instead of pushing them behind fresh ones
+func (s *PreferencesService) SetShowSeenArticles(userID uuid.UUID, show bool) error {
+	// Upsert just this column; the preference arrays need driver-specific encoding
+	err := upsertPreference(s.db, userID, "show_seen_articles", show)
+	if err != nil {
+		return fmt.Errorf("failed to update seen-article preference: %w", err)
+	}
+	return nil
+}
+
+// SetDigestSubscribed sets whether a user receives the top-stories digest as a
+// direct message
+func (s *PreferencesService) SetDigestSubscribed(userID uuid.UUID, subscribed bool) error {
+	err := upsertPreference(s.db, userID, "digest_subscribed", subscribed)
+	if err != nil {
+		return fmt.Errorf("failed to update digest subscription: %w", err)
+	}
+	return nil
+}
+
+// upsertPreference sets one column of a user's preferences, creating their
+// preferences row if they don't have one yet. column must be a trusted column name.
+func upsertPreference(db *gorm.DB, userID uuid.UUID, column string, value interface{}) error {
+	now := time.Now()
+	return db.Exec(fmt.Sprintf(`INSERT INTO user_feed_preferences (user_id, %[1]s, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET %[1]s = EXCLUDED.%[1]s, updated_at = EXCLUDED.updated_at`, column),
+		userID, value, now, now).Error
+}
+
+// updateTopics replaces the topics a user follows with the result of change.
+// The list is read and written in one transaction rather than with Postgres
+// array functions, so it works on SQLite too.
+func (s *PreferencesService) updateTopics(userID uuid.UUID, change func(followed []string) []string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var followed pq.StringArray
+		err := tx.Table("user_feed_preferences").
+			Select("preferred_topics").
+			Where("user_id = ?", userID).
+			Row().
+			Scan(&followed)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return upsertPreference(tx, userID, "preferred_topics", pq.StringArray(change(followed)))
+	})
+}
+
+// maxPreferredLanguages caps the languages a user can choose
+const maxPreferredLanguages = 10
+
+// ErrInvalidLanguage is returned for language codes that aren't two or three letters
+var ErrInvalidLanguage = errors.New("languages must be two or three letter codes such as en or fil")
+
+// Settings returns the topics a user follows and the languages they read
+func (s *PreferencesService) Settings(userID uuid.UUID) (topicSlugs, languages []string, err error) {
+	var followed, read pq.StringArray
+	err = s.db.Table("user_feed_preferences").
+		Select("preferred_topics, preferred_languages").
+		Where("user_id = ?", userID).
+		Row().
+		Scan(&followed, &read)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("failed to load feed settings: %w", err)
+	}
+	return followed, read, nil
+}
+
+// Options returns whether a user's personal feeds keep showing articles they
+// were already served and whether they receive the digest
+func (s *PreferencesService) Options(userID uuid.UUID) (showSeen, digestSubscribed bool, err error) {
+	err = s.db.Table("user_feed_preferences").
+		Select("show_seen_articles, digest_subscribed").
+		Where("user_id = ?", userID).
+		Row().
+		Scan(&showSeen, &digestSubscribed)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, false, fmt.Errorf("failed to load feed options: %w", err)
+	}
+	return showSeen, digestSubscribed, nil
+}
+
+// SetTopics replaces the topics a user follows
+func (s *PreferencesService) SetTopics(userID uuid.UUID, slugs []string) error {
+	followed := []string{}
+	seen := make(map[string]bool)
+	for _, slug := range slugs {
+		slug = strings.ToLower(strings.TrimSpace(slug))
+		if !topics.IsTopic(slug) {
+			return fmt.Errorf("%w: %s", ErrUnknownTopic, slug)
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			followed = append(followed, slug)
+		}
+	}
+	err := upsertPreference(s.db, userID, "preferred_topics", pq.StringArray(followed))
+	if err != nil {
+		return fmt.Errorf("failed to update topics: %w", err)
+	}
+	return nil
+}
+
+// FollowTopic adds a topic to those a user follows. Articles on followed topics
+// appear in the user's personal feeds even when none of their follows shared them.
+func (s *PreferencesService) FollowTopic(userID uuid.UUID, slug string) error {
+	if !topics.IsTopic(slug) {
+		return fmt.Errorf("%w: %s", ErrUnknownTopic, slug)
+	}
+	err := s.updateTopics(userID, func(followed []string) []string {
+		for _, topic := range followed {
+			if topic == slug {
+				return followed
+			}
+		}
+		return append(followed, slug)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to follow topic: %w", err)
+	}
+	return nil
+}
+
+// UnfollowTopic removes a topic from those a user follows
+func (s *PreferencesService) UnfollowTopic(userID uuid.UUID, slug string) error {
+	err := s.updateTopics(userID, func(followed []string) []string {
+		kept := []string{}
+		for _, topic := range followed {
+			if topic != slug {
+				kept = append(kept, topic)
+			}
+		}
+		return kept
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unfollow topic: %w", err)
+	}
+	return nil
+}
+
+// SetLanguages replaces the languages a user reads. Personal feeds leave out
+// articles in other languages; an empty list allows every language.
+func (s *PreferencesService) SetLanguages(userID uuid.UUID, languages []string) ([]string, error) {
+	normalized, err := normalizeLanguages(languages)
+	if err != nil {
+		return nil, err
+	}
+	err = upsertPreference(s.db, userID, "preferred_languages", pq.StringArray(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update languages: %w", err)
+	}
+	return normalized, nil
+}
+
+// normalizeLanguages lowercases language codes and drops repeats. Region
+// subtags are dropped too, so "en-GB" reads as "en".
+func normalizeLanguages(languages []string) ([]string, error) {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, language := range languages {
+		code := strings.ToLower(strings.TrimSpace(language))
+		if i := strings.IndexAny(code, "-_"); i >= 0 {
+			code = code[:i]
+		}
+		if len(code) < 2 || len(code) > 3 || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLanguage, language)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	if len(normalized) > maxPreferredLanguages {
+		return nil, fmt.Errorf("%w: at most %d languages", ErrInvalidLanguage, maxPreferredLanguages)
+	}
+	return normalized, nil
+}
@@ -1,28 +1,81 @@
 package services
 
 import (
+	"context"
 	"log"
 	"math"
+	"open-news/internal/domains"
 	"open-news/internal/models"
+	"open-news/internal/pool"
+	"open-news/internal/ranking"
+	"os"
+	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	// defaultScoreSweepInterval applies without QUALITY_SCORE_SWEEP_HOURS
+	defaultScoreSweepInterval = 24 * time.Hour
+
+	// rescoreBatchSize is how many flagged sources or articles are rescored at a time
+	rescoreBatchSize = 500
+
+	// rescoreArticleWindow is how far back the articles of a source whose score
+	// changed are flagged for rescoring; older ones wait for the full sweep
+	rescoreArticleWindow = 7 * 24 * time.Hour
+)
+
+// LoadScoreSweepInterval reads from QUALITY_SCORE_SWEEP_HOURS how often every
+// source and article is rescored, as a backstop for changes that weren't flagged
+func LoadScoreSweepInterval() time.Duration {
+	value := os.Getenv("QUALITY_SCORE_SWEEP_HOURS")
+	if value == "" {
+		return defaultScoreSweepInterval
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours <= 0 {
+		log.Printf("Invalid QUALITY_SCORE_SWEEP_HOURS %q, using %v", value, defaultScoreSweepInterval)
+		return defaultScoreSweepInterval
+	}
+	return time.Duration(hours) * time.Hour
+}
+
 // QualityScoreService handles dynamic quality score calculation
 type QualityScoreService struct {
-	db *gorm.DB
+	db          *gorm.DB
+	domains     *domains.Registry // Site reputations
+	concurrency int               // Sources or articles rescored at once
 }
 
 // NewQualityScoreService creates a new quality score service
 func NewQualityScoreService(db *gorm.DB) *QualityScoreService {
-	return &QualityScoreService{db: db}
+	return &QualityScoreService{db: db, domains: domains.NewRegistry(db), concurrency: pool.Shared().Concurrency}
+}
+
+// WithContext returns a quality score service running its queries with ctx, so rescoring is traced as part of the job ctx belongs to
+func (qs *QualityScoreService) WithContext(ctx context.Context) *QualityScoreService {
+	scoped := *qs
+	scoped.db = qs.db.WithContext(ctx)
+	return &scoped
 }
 
 // UpdateAllQualityScores recalculates quality scores for all articles
 func (qs *QualityScoreService) UpdateAllQualityScores() error {
 	log.Println("🔄 Starting quality score updates...")
 
+	// Everything is about to be rescored, so earlier flags are covered
+	if err := qs.clearRescoreFlags(); err != nil {
+		return err
+	}
+
+	// Roll up engagement first, in case shares changed without it
+	if err := models.AggregateAllEngagement(qs.db); err != nil {
+		return err
+	}
+
 	// First update source quality scores
 	if err := qs.updateSourceQualityScores(); err != nil {
 		return err
@@ -43,6 +96,7 @@ func (qs *QualityScoreService) UpdateAllQualityScores() error {
 }
 
 // updateSourceQualityScores calculates quality scores for sources based on their articles
+// and, once profiles have been enriched, their follower counts
 func (qs *QualityScoreService) updateSourceQualityScores() error {
 	log.Println("📊 Updating source quality scores...")
 
@@ -51,16 +105,28 @@ func (qs *QualityScoreService) updateSourceQualityScores() error {
 		return err
 	}
 
+	group := pool.New(qs.concurrency)
 	for _, source := range sources {
-		score := qs.calculateSourceQualityScore(source.ID.String())
-		
-		if err := qs.db.Model(&source).Update("quality_score", score).Error; err != nil {
-			log.Printf("Failed to update source %s quality score: %v", source.Handle, err)
-			continue
-		}
+		group.Go(func() error {
+			if _, err := qs.updateSourceScore(source); err != nil {
+				log.Printf("Failed to update source %s quality score: %v", source.Handle, err)
+			}
+			return nil
+		})
 	}
 
-	return nil
+	return group.Wait()
+}
+
+// updateSourceScore recalculates and stores a source's quality score, and
+// reports whether it changed
+func (qs *QualityScoreService) updateSourceScore(source models.Source) (bool, error) {
+	score := qs.calculateSourceQualityScore(source.ID.String())
+	score = math.Min(score+followerBonus(source.FollowersCount), 1.0)
+	if score == source.QualityScore {
+		return false, nil
+	}
+	return true, qs.db.Model(&source).Update("quality_score", score).Error
 }
 
 // calculateSourceQualityScore calculates quality score for a source
@@ -110,7 +176,7 @@ func (qs *QualityScoreService) calculateSourceQualityScore(sourceID string) floa
 func (qs *QualityScoreService) calculateRecentActivityBonus(sourceID string) float64 {
 	var count int64
 	qs.db.Model(&models.SourceArticle{}).
-		Where("source_id = ? AND created_at > ?", sourceID, time.Now().AddDate(0, 0, -7)).
+		Where("source_id = ? AND posted_at > ?", sourceID, time.Now().AddDate(0, 0, -7)).
 		Count(&count)
 
 	// Bonus up to 0.1 for recent activity
@@ -127,109 +193,164 @@ func (qs *QualityScoreService) updateArticleQualityScores() error {
 		return err
 	}
 
+	group := pool.New(qs.concurrency)
 	for _, article := range articles {
-		score := qs.calculateArticleQualityScore(article)
-		
-		if err := qs.db.Model(&article).Update("quality_score", score).Error; err != nil {
-			log.Printf("Failed to update article %s quality score: %v", article.URL, err)
-			continue
-		}
+		group.Go(func() error {
+			if err := qs.updateArticleScore(article); err != nil {
+				log.Printf("Failed to update article %s quality score: %v", article.URL, err)
+			}
+			return nil
+		})
 	}
 
-	return nil
+	return group.Wait()
 }
 
-// calculateArticleQualityScore calculates quality score for an article
-func (qs *QualityScoreService) calculateArticleQualityScore(article models.Article) float64 {
-	var score float64 = 0.5 // Base score
-
-	// 1. Source quality contribution (40% weight)
-	if len(article.SourceArticles) > 0 {
-		var avgSourceQuality float64
-		for _, sa := range article.SourceArticles {
-			avgSourceQuality += sa.Source.QualityScore
-		}
-		avgSourceQuality /= float64(len(article.SourceArticles))
-		score += avgSourceQuality * 0.4
+// updateArticleScore recalculates and stores an article's quality score. The
+// article's SourceArticles.Source should be preloaded.
+func (qs *QualityScoreService) updateArticleScore(article models.Article) error {
+	breakdown := qs.ExplainScores(article)
+
+	// Keep the trending components from the last trending update; only recent
+	// articles have their trending score recalculated
+	if previous, err := models.ParseScoreBreakdown(article.ScoreBreakdownData); err == nil && previous != nil {
+		breakdown.Velocity, breakdown.Decay, breakdown.TrendingScore = previous.Velocity, previous.Decay, previous.TrendingScore
+	} else {
+		breakdown.TrendingScore = article.TrendingScore
 	}
 
-	// 2. Engagement metrics (30% weight)
-	totalEngagement := article.LikesCount + article.RepostsCount + article.SharesCount
-	engagementScore := math.Min(float64(totalEngagement)/500.0, 0.3) // Cap at 0.3
-	score += engagementScore
+	return qs.db.Model(&article).Updates(map[string]interface{}{
+		"quality_score":   breakdown.QualityScore,
+		"score_breakdown": breakdown.Encode(),
+	}).Error
+}
 
-	// 3. Content quality indicators (20% weight)
-	contentScore := qs.calculateContentQualityScore(article)
-	score += contentScore * 0.2
+// UpdateChangedQualityScores rescores only the sources and articles flagged by
+// models.MarkForRescore since they were last scored, and the trending scores of
+// recent articles, which change with time alone. Sources whose score changed
+// flag their articles from the last week.
+func (qs *QualityScoreService) UpdateChangedQualityScores() error {
+	log.Println("🔄 Updating changed quality scores...")
 
-	// 4. Domain reputation (10% weight)
-	domainScore := qs.calculateDomainScore(article.SiteName)
-	score += domainScore * 0.1
+	sources, err := qs.updateChangedSourceScores()
+	if err != nil {
+		return err
+	}
+	articles, err := qs.updateChangedArticleScores()
+	if err != nil {
+		return err
+	}
+	if err := qs.updateTrendingScores(); err != nil {
+		return err
+	}
 
-	return math.Min(score, 1.0) // Cap at 1.0
+	log.Printf("✅ Rescored %d changed sources and %d changed articles", sources, articles)
+	return nil
 }
 
-// calculateContentQualityScore evaluates content quality
-func (qs *QualityScoreService) calculateContentQualityScore(article models.Article) float64 {
-	var score float64 = 0.5
+// updateChangedSourceScores rescores flagged sources and returns how many there were
+func (qs *QualityScoreService) updateChangedSourceScores() (int, error) {
+	rescored := 0
+	for {
+		ids, err := qs.takeFlagged(&models.Source{})
+		if err != nil || len(ids) == 0 {
+			return rescored, err
+		}
+		var sources []models.Source
+		if err := qs.db.Where("id IN ?", ids).Find(&sources).Error; err != nil {
+			return rescored, err
+		}
 
-	// Word count bonus (articles with good length get bonus)
-	if article.WordCount >= 300 && article.WordCount <= 3000 {
-		score += 0.2
-	} else if article.WordCount >= 150 {
-		score += 0.1
+		group := pool.New(qs.concurrency)
+		for _, source := range sources {
+			group.Go(func() error {
+				changed, err := qs.updateSourceScore(source)
+				if err != nil {
+					log.Printf("Failed to update source %s quality score: %v", source.Handle, err)
+					return nil
+				}
+				if changed {
+					// The scores of the articles it shared average in the source's
+					err := qs.db.Model(&models.Article{}).
+						Where("id IN (?) AND created_at > ?", qs.db.Model(&models.SourceArticle{}).Select("article_id").Where("source_id = ?", source.ID), time.Now().Add(-rescoreArticleWindow)).
+						Update("needs_rescore", true).Error
+					if err != nil {
+						log.Printf("Failed to flag the articles of source %s for rescoring: %v", source.Handle, err)
+					}
+				}
+				return nil
+			})
+		}
+		group.Wait()
+		rescored += len(sources)
+		if len(ids) < rescoreBatchSize {
+			return rescored, nil
+		}
 	}
+}
 
-	// Title and description quality
-	if len(article.Title) > 10 && len(article.Title) < 200 {
-		score += 0.1
-	}
-	if len(article.Description) > 50 {
-		score += 0.1
-	}
+// updateChangedArticleScores rescores flagged articles and returns how many there were
+func (qs *QualityScoreService) updateChangedArticleScores() (int, error) {
+	rescored := 0
+	for {
+		ids, err := qs.takeFlagged(&models.Article{})
+		if err != nil || len(ids) == 0 {
+			return rescored, err
+		}
+		var articles []models.Article
+		if err := qs.db.Preload("SourceArticles.Source").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return rescored, err
+		}
 
-	// Has media (image)
-	if article.ImageURL != "" {
-		score += 0.1
+		group := pool.New(qs.concurrency)
+		for _, article := range articles {
+			group.Go(func() error {
+				if err := qs.updateArticleScore(article); err != nil {
+					log.Printf("Failed to update article %s quality score: %v", article.URL, err)
+				}
+				return nil
+			})
+		}
+		group.Wait()
+		rescored += len(articles)
+		if len(ids) < rescoreBatchSize {
+			return rescored, nil
+		}
 	}
-
-	return math.Min(score, 1.0)
 }
 
-// calculateDomainScore gives reputation scores to known domains
-func (qs *QualityScoreService) calculateDomainScore(siteName string) float64 {
-	// High-quality news sources
-	highQualitySources := map[string]float64{
-		"Reuters":     1.0,
-		"BBC News":    0.95,
-		"The Guardian": 0.9,
-		"Nature":      0.98,
-		"arXiv":       0.9,
-		"The New York Times": 0.92,
-		"The Washington Post": 0.9,
-		"Associated Press": 0.95,
+// takeFlagged returns a batch of the IDs of sources or articles flagged for
+// rescoring and clears their flags. Flags are cleared before rescoring, so a
+// change made meanwhile flags the row again.
+func (qs *QualityScoreService) takeFlagged(model interface{}) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := qs.db.Model(model).Where("needs_rescore = ?", true).Limit(rescoreBatchSize).Pluck("id", &ids).Error; err != nil {
+		return nil, err
 	}
-
-	// Medium-quality sources
-	mediumQualitySources := map[string]float64{
-		"TechCrunch":     0.8,
-		"WIRED":          0.85,
-		"The Economist":  0.88,
-		"CNN":            0.75,
-		"Forbes":         0.7,
-		"Bloomberg":      0.85,
+	if len(ids) == 0 {
+		return nil, nil
 	}
+	return ids, qs.db.Model(model).Where("id IN ?", ids).Update("needs_rescore", false).Error
+}
 
-	if score, exists := highQualitySources[siteName]; exists {
-		return score
+// clearRescoreFlags clears every rescoring flag before a full sweep
+func (qs *QualityScoreService) clearRescoreFlags() error {
+	if err := qs.db.Model(&models.Source{}).Where("needs_rescore = ?", true).Update("needs_rescore", false).Error; err != nil {
+		return err
 	}
+	return qs.db.Model(&models.Article{}).Where("needs_rescore = ?", true).Update("needs_rescore", false).Error
+}
 
-	if score, exists := mediumQualitySources[siteName]; exists {
-		return score
-	}
+// calculateArticleQualityScore calculates quality score for an article
+func (qs *QualityScoreService) calculateArticleQualityScore(article models.Article) float64 {
+	return qs.ExplainScores(article).QualityScore
+}
 
-	// Default score for unknown domains
-	return 0.5
+// ExplainScores breaks an article's quality and trending scores into their components
+// using the default ranker, which produces the stored article scores.
+// The article's SourceArticles.Source should be preloaded.
+func (qs *QualityScoreService) ExplainScores(article models.Article) models.ScoreBreakdown {
+	return ranking.Get(ranking.Default).Explain(article, ranking.Signals{Now: time.Now(), Domain: qs.domains.Lookup(article)})
 }
 
 // updateTrendingScores calculates trending scores based on recent engagement
@@ -243,34 +364,37 @@ func (qs *QualityScoreService) updateTrendingScores() error {
 		return err
 	}
 
+	group := pool.New(qs.concurrency)
 	for _, article := range articles {
-		trendingScore := qs.calculateTrendingScore(article)
-		
-		if err := qs.db.Model(&article).Update("trending_score", trendingScore).Error; err != nil {
-			log.Printf("Failed to update article %s trending score: %v", article.URL, err)
-			continue
-		}
+		group.Go(func() error {
+			velocity, decay, trendingScore := qs.trendingComponents(article)
+
+			updates := map[string]interface{}{"trending_score": trendingScore}
+			if breakdown, err := models.ParseScoreBreakdown(article.ScoreBreakdownData); err == nil && breakdown != nil {
+				breakdown.Velocity, breakdown.Decay, breakdown.TrendingScore = velocity, decay, trendingScore
+				breakdown.CalculatedAt = time.Now()
+				updates["score_breakdown"] = breakdown.Encode()
+			}
+
+			if err := qs.db.Model(&article).Updates(updates).Error; err != nil {
+				log.Printf("Failed to update article %s trending score: %v", article.URL, err)
+			}
+			return nil
+		})
 	}
 
-	return nil
+	return group.Wait()
 }
 
 // calculateTrendingScore calculates how trending an article is
 func (qs *QualityScoreService) calculateTrendingScore(article models.Article) float64 {
-	now := time.Now()
-	hoursSinceCreated := now.Sub(article.CreatedAt).Hours()
-
-	// Decay factor: articles lose trending value over time
-	decayFactor := math.Exp(-hoursSinceCreated / 24.0) // Half-life of 24 hours
-
-	// Engagement velocity (engagement per hour)
-	totalEngagement := float64(article.LikesCount + article.RepostsCount + article.SharesCount)
-	velocity := totalEngagement / math.Max(hoursSinceCreated, 1.0)
-
-	// Trending score based on velocity and decay
-	trendingScore := velocity * decayFactor / 10.0 // Scale down
+	_, _, trendingScore := qs.trendingComponents(article)
+	return trendingScore
+}
 
-	return math.Min(trendingScore, 1.0)
+// trendingComponents returns the engagement velocity, decay factor and resulting trending score
+func (qs *QualityScoreService) trendingComponents(article models.Article) (velocity, decayFactor, trendingScore float64) {
+	return ranking.Trending(article, time.Now())
 }
 
 // UpdateSingleArticleScore updates quality score for a specific article
@@ -280,11 +404,11 @@ func (qs *QualityScoreService) UpdateSingleArticleScore(articleID string) error
 		return err
 	}
 
-	qualityScore := qs.calculateArticleQualityScore(article)
-	trendingScore := qs.calculateTrendingScore(article)
+	breakdown := qs.ExplainScores(article)
 
 	return qs.db.Model(&article).Updates(map[string]interface{}{
-		"quality_score":  qualityScore,
-		"trending_score": trendingScore,
+		"quality_score":   breakdown.QualityScore,
+		"trending_score":  breakdown.TrendingScore,
+		"score_breakdown": breakdown.Encode(),
 	}).Error
 }
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/domains"
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityScoreService_ExplainScores(t *testing.T) {
+	service := &QualityScoreService{domains: domains.NewStaticRegistry([]models.Domain{{Domain: "reuters.com", Name: "Reuters", Score: 1.0}})}
+
+	article := models.Article{
+		URL:          "https://www.reuters.com/world/story",
+		Title:        "A reasonably long headline",
+		Description:  "A description that is comfortably longer than fifty characters in total.",
+		ImageURL:     "https://example.com/image.jpg",
+		SiteName:     "Reuters",
+		WordCount:    800,
+		LikesCount:   100,
+		RepostsCount: 20,
+		CreatedAt:    time.Now().Add(-2 * time.Hour),
+		SourceArticles: []models.SourceArticle{
+			{Source: models.Source{QualityScore: 0.5}},
+			{Source: models.Source{QualityScore: 0.7}},
+		},
+	}
+
+	breakdown := service.ExplainScores(article)
+
+	assert.Equal(t, 0.5, breakdown.Base)
+	assert.InDelta(t, 0.24, breakdown.SourceQuality, 1e-9)
+	assert.InDelta(t, 0.24, breakdown.Engagement, 1e-9)
+	assert.InDelta(t, 0.2, breakdown.ContentQuality, 1e-9)
+	assert.InDelta(t, 0.1, breakdown.DomainReputation, 1e-9)
+	assert.Equal(t, 1.0, breakdown.QualityScore, "quality score is capped at 1.0")
+	assert.Equal(t, service.calculateArticleQualityScore(article), breakdown.QualityScore)
+
+	assert.InDelta(t, 60.0, breakdown.Velocity, 0.1)
+	assert.Less(t, breakdown.Decay, 1.0)
+	assert.InDelta(t, service.calculateTrendingScore(article), breakdown.TrendingScore, 1e-6)
+}
+
+func TestLoadScoreSweepInterval(t *testing.T) {
+	t.Setenv("QUALITY_SCORE_SWEEP_HOURS", "")
+	assert.Equal(t, defaultScoreSweepInterval, LoadScoreSweepInterval())
+
+	t.Setenv("QUALITY_SCORE_SWEEP_HOURS", "6")
+	assert.Equal(t, 6*time.Hour, LoadScoreSweepInterval())
+
+	t.Setenv("QUALITY_SCORE_SWEEP_HOURS", "0")
+	assert.Equal(t, defaultScoreSweepInterval, LoadScoreSweepInterval(), "zero is invalid")
+}
+
+func TestUpdateChangedQualityScores(t *testing.T) {
+	db := setupTestDB(t)
+
+	flagged := models.Article{URL: "https://example.com/rescore-flagged", Title: "Flagged", LikesCount: 50}
+	untouched := models.Article{URL: "https://example.com/rescore-untouched", Title: "Untouched", LikesCount: 50}
+	for _, a := range []*models.Article{&flagged, &untouched} {
+		require.NoError(t, db.Create(a).Error)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM articles WHERE id IN ?", []interface{}{flagged.ID, untouched.ID})
+	})
+	require.NoError(t, models.MarkForRescore(db, []uuid.UUID{flagged.ID}, nil))
+
+	service := NewQualityScoreService(db)
+	require.NoError(t, service.UpdateChangedQualityScores())
+
+	require.NoError(t, db.First(&flagged, "id = ?", flagged.ID).Error)
+	require.NoError(t, db.First(&untouched, "id = ?", untouched.ID).Error)
+	assert.False(t, flagged.NeedsRescore, "rescoring clears the flag")
+	assert.Greater(t, flagged.QualityScore, 0.0)
+	assert.Zero(t, untouched.QualityScore, "articles that weren't flagged aren't rescored")
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"open-news/internal/database"
+	"open-news/internal/domains"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// articleDocumentSQL is the text search document of an article: its title and
+// description. Migration 035 indexes the same expression.
+const articleDocumentSQL = `to_tsvector('english', coalesce(articles.title, '') || ' ' || coalesce(articles.description, ''))`
+
+// articleTextSQL is the lowercased title and description SQLite looks for words in
+const articleTextSQL = `lower(coalesce(articles.title, '') || ' ' || coalesce(articles.description, ''))`
+
+// sharedEntitiesSQL counts the entities an article shares with another
+const sharedEntitiesSQL = `(SELECT COUNT(*) FROM article_entities AS theirs
+	JOIN article_entities AS ours ON ours.entity_id = theirs.entity_id
+	WHERE theirs.article_id = articles.id AND ours.article_id = ?)`
+
+const (
+	// relatedWindow is how far before or after an article related coverage is looked for
+	relatedWindow = 30 * 24 * time.Hour
+
+	// relatedEntityWeight is added to the text similarity for each shared entity
+	relatedEntityWeight = 0.05
+
+	// minRelatedScore leaves out articles that only share a common word
+	minRelatedScore = 0.02
+
+	// minRelatedSimilarity leaves out articles whose embeddings are only
+	// loosely similar
+	minRelatedSimilarity = 0.3
+
+	// maxRelatedTerms caps the words of an article searched for
+	maxRelatedTerms = 30
+)
+
+// RelatedArticle is an article covering the same story or topic as another
+type RelatedArticle struct {
+	models.Article `gorm:"embedded"`
+	Score          float64 `json:"score"` // Embedding similarity, or text similarity plus shared entities
+}
+
+// RelatedService finds other coverage of an article's story
+type RelatedService struct {
+	db         *gorm.DB
+	embeddings *EmbeddingService
+}
+
+// NewRelatedService creates a new related articles service. With embeddings
+// enabled, articles are compared by their embeddings when they have one.
+func NewRelatedService(db *gorm.DB, embeddings *EmbeddingService) *RelatedService {
+	return &RelatedService{db: db, embeddings: embeddings}
+}
+
+// Related returns up to limit articles from within a month of an article, most
+// similar first. Articles are compared by their embeddings when the article
+// has one, and otherwise by the words of their titles and descriptions and the
+// entities they name.
+func (s *RelatedService) Related(article models.Article, limit int) ([]RelatedArticle, error) {
+	if s.embeddings.Enabled() {
+		similar, err := s.embeddings.Similar(article, limit*2)
+		if err != nil {
+			return nil, err
+		}
+		if len(similar) > 0 {
+			return distinctRelated(article, similar, minRelatedSimilarity, limit), nil
+		}
+	}
+	return s.textRelated(article, limit)
+}
+
+// textRelated finds related articles with full text search and shared entities.
+// SQLite has no full text search, so there the text similarity is the share of
+// the article's words another article's title or description contains.
+func (s *RelatedService) textRelated(article models.Article, limit int) ([]RelatedArticle, error) {
+	query := s.db.Model(&models.Article{}).
+		Where("articles.id <> ? AND articles.is_not_news = ?", article.ID, false).
+		Where("articles.created_at BETWEEN ? AND ?", article.CreatedAt.Add(-relatedWindow), article.CreatedAt.Add(relatedWindow)).
+		Scopes(domains.NotBlocked)
+
+	text := article.Title + " " + article.Description
+	if database.IsSQLite(s.db) {
+		words := relatedWords(text, true)
+		if len(words) == 0 {
+			return nil, nil
+		}
+		matches := make([]string, len(words))
+		args := make([]interface{}, len(words))
+		for i, word := range words {
+			matches[i], args[i] = "(instr("+articleTextSQL+", ?) > 0)", word
+		}
+		matched := "(" + strings.Join(matches, " + ") + ")"
+		query = query.
+			Select("articles.*, "+matched+" * 1.0 / ? + ? * "+sharedEntitiesSQL+" AS score",
+				append(args, len(words), relatedEntityWeight, article.ID)...).
+			Where(matched+" > 0", args...)
+	} else {
+		terms := relatedTerms(text)
+		if terms == "" {
+			return nil, nil
+		}
+		query = query.
+			Select("articles.*, ts_rank("+articleDocumentSQL+", to_tsquery('english', ?)) + ? * "+sharedEntitiesSQL+" AS score",
+				terms, relatedEntityWeight, article.ID).
+			Where(articleDocumentSQL+" @@ to_tsquery('english', ?)", terms)
+	}
+
+	var related []RelatedArticle
+	err := query.
+		Order("score DESC, articles.created_at DESC").
+		Limit(limit * 2).
+		Scan(&related).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find related articles: %w", err)
+	}
+	return distinctRelated(article, related, minRelatedScore, limit), nil
+}
+
+// distinctRelated keeps up to limit candidates scoring at least minScore,
+// skipping other copies of the same headline
+func distinctRelated(article models.Article, related []RelatedArticle, minScore float64, limit int) []RelatedArticle {
+	kept := related[:0]
+	seen := map[string]bool{strings.ToLower(article.Title): true}
+	for _, candidate := range related {
+		title := strings.ToLower(candidate.Title)
+		if candidate.Score < minScore || seen[title] {
+			continue
+		}
+		seen[title] = true
+		kept = append(kept, candidate)
+		if len(kept) == limit {
+			break
+		}
+	}
+	return kept
+}
+
+// relatedTerms turns text into a tsquery matching any of its words. Words are
+// reduced to letters and digits, so the query can't contain tsquery operators;
+// the english configuration drops stop words.
+func relatedTerms(text string) string {
+	return strings.Join(relatedWords(text, false), " | ")
+}
+
+// relatedStopWords are common words that say nothing about a story, left out
+// where no text search configuration drops them
+var relatedStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "are": true, "was": true, "were": true, "has": true, "have": true,
+	"had": true, "not": true, "but": true, "its": true, "his": true, "her": true,
+	"they": true, "their": true, "will": true, "would": true, "can": true, "could": true,
+	"after": true, "over": true, "into": true, "about": true, "more": true, "than": true,
+	"says": true, "said": true, "who": true, "what": true, "when": true, "how": true,
+	"why": true, "out": true, "all": true, "been": true, "new": true, "you": true,
+}
+
+// relatedWords returns the distinct words of text of three or more letters and
+// digits, up to maxRelatedTerms of them, optionally leaving out stop words
+func relatedWords(text string, dropStopWords bool) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var kept []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if len([]rune(word)) < 3 || seen[word] || (dropStopWords && relatedStopWords[word]) {
+			continue
+		}
+		seen[word] = true
+		kept = append(kept, word)
+		if len(kept) == maxRelatedTerms {
+			break
+		}
+	}
+	return kept
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRelatedTerms(t *testing.T) {
+	assert.Equal(t, "ecb | raises | rates | lagarde", relatedTerms("ECB raises rates (0.25%) as Lagarde... raises RATES"),
+		"short words and repeats are dropped")
+	assert.Equal(t, "don | think", relatedTerms("don't & think | !"), "punctuation can't reach the query")
+	assert.Empty(t, relatedTerms("a b c"))
+}
+
+func TestRelated(t *testing.T) {
+	testRelated(t, setupTestDB(t))
+}
+
+func TestRelatedSQLite(t *testing.T) {
+	testRelated(t, setupSQLiteTestDB(t))
+}
+
+func testRelated(t *testing.T, db *gorm.DB) {
+	service := NewRelatedService(db, nil)
+
+	article := models.Article{URL: "https://example.com/ecb", Title: "European Central Bank raises interest rates", Description: "Lagarde signals more hikes"}
+	story := models.Article{URL: "https://other.example/ecb", Title: "ECB lifts interest rates again", Description: "The central bank raised rates"}
+	copied := models.Article{URL: "https://copy.example/ecb", Title: "European Central Bank raises interest rates"}
+	unrelated := models.Article{URL: "https://example.com/football", Title: "Local team wins cup final"}
+	for _, a := range []*models.Article{&article, &story, &copied, &unrelated} {
+		require.NoError(t, db.Create(a).Error)
+	}
+
+	related, err := service.Related(article, 10)
+	require.NoError(t, err)
+	require.Len(t, related, 1, "the copied headline and unrelated article are left out")
+	assert.Equal(t, story.ID, related[0].ID)
+	assert.Greater(t, related[0].Score, 0.0)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// Defaults for RETENTION_HTML_DAYS, RETENTION_UNREACHABLE_DAYS,
+	// RETENTION_FEED_ITEMS_DAYS and RETENTION_SCORE_HISTORY_DAYS
+	defaultHTMLRetentionDays         = 30
+	defaultUnreachableRetentionDays  = 7
+	defaultFeedItemRetentionDays     = 90
+	defaultScoreHistoryRetentionDays = 30
+
+	// retentionBatchSize is how many rows each purge statement touches, so a
+	// large backlog doesn't hold locks on the whole table
+	retentionBatchSize = 1000
+
+	// retentionDeleteBatchSize is how many articles are deleted per batch;
+	// each one also takes its facts, entity links and shares with it
+	retentionDeleteBatchSize = 200
+)
+
+// neverRankedSQL matches articles that were never placed in a feed or shown to anyone
+const neverRankedSQL = `NOT EXISTS (SELECT 1 FROM feed_items WHERE feed_items.article_id = articles.id)
+	AND NOT EXISTS (SELECT 1 FROM feed_items_archive WHERE feed_items_archive.article_id = articles.id)
+	AND NOT EXISTS (SELECT 1 FROM impressions WHERE impressions.article_id = articles.id)`
+
+// RetentionConfig holds how long each kind of data is kept. Zero keeps it forever.
+type RetentionConfig struct {
+	HTMLContentDays        int `json:"html_content_days"`        // Cached article HTML is dropped after this many days (default: 30)
+	UnreachableArticleDays int `json:"unreachable_article_days"` // Unreachable articles that were never ranked are deleted after this many days (default: 7)
+	FeedItemDays           int `json:"feed_item_days"`           // Feed items are moved to feed_items_archive after this many days (default: 90)
+	ScoreHistoryDays       int `json:"score_history_days"`       // Score snapshots are deleted after this many days (default: 30)
+}
+
+// LoadRetentionConfig reads the retention periods from the environment
+func LoadRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		HTMLContentDays:        retentionDays("RETENTION_HTML_DAYS", defaultHTMLRetentionDays),
+		UnreachableArticleDays: retentionDays("RETENTION_UNREACHABLE_DAYS", defaultUnreachableRetentionDays),
+		FeedItemDays:           retentionDays("RETENTION_FEED_ITEMS_DAYS", defaultFeedItemRetentionDays),
+		ScoreHistoryDays:       retentionDays("RETENTION_SCORE_HISTORY_DAYS", defaultScoreHistoryRetentionDays),
+	}
+}
+
+// retentionDays reads a number of days from the environment; 0 disables the policy
+func retentionDays(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Printf("Invalid %s %q, using %d", name, value, fallback)
+		return fallback
+	}
+	return days
+}
+
+// RetentionService applies the retention policy: it drops old cached HTML,
+// deletes articles that never became reachable or ranked, and archives old
+// feed items. Each run is recorded with the number of rows it purged.
+type RetentionService struct {
+	db       *gorm.DB
+	config   RetentionConfig
+	articles *ArticlesService
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(db *gorm.DB, config RetentionConfig) *RetentionService {
+	return &RetentionService{db: db, config: config, articles: NewArticlesService(db, nil)}
+}
+
+// WithContext returns a retention service running its deletes with ctx
+func (s *RetentionService) WithContext(ctx context.Context) *RetentionService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.articles = s.articles.WithContext(ctx)
+	return &scoped
+}
+
+// Config returns the retention periods the service applies
+func (s *RetentionService) Config() RetentionConfig {
+	return s.config
+}
+
+// Run applies each enabled retention policy and records the run. A failing
+// policy stops the run; what was purged before it is still recorded.
+func (s *RetentionService) Run() (*models.RetentionRun, error) {
+	run := &models.RetentionRun{StartedAt: time.Now()}
+	err := s.apply(run)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	log.Printf("🧹 Retention: dropped HTML of %d articles, deleted %d unreachable articles, archived %d feed items, deleted %d score snapshots",
+		run.HTMLPurged, run.ArticlesDeleted, run.FeedItemsArchived, run.ScoreHistoryPurged)
+	if saveErr := s.db.Create(run).Error; saveErr != nil && err == nil {
+		err = fmt.Errorf("failed to record retention run: %w", saveErr)
+	}
+	return run, err
+}
+
+// apply runs each enabled policy, counting the rows purged into run
+func (s *RetentionService) apply(run *models.RetentionRun) error {
+	var err error
+	if days := s.config.HTMLContentDays; days > 0 {
+		if run.HTMLPurged, err = s.PurgeHTML(run.StartedAt.AddDate(0, 0, -days)); err != nil {
+			return err
+		}
+	}
+	if days := s.config.UnreachableArticleDays; days > 0 {
+		if run.ArticlesDeleted, err = s.DeleteUnreachable(run.StartedAt.AddDate(0, 0, -days)); err != nil {
+			return err
+		}
+	}
+	if days := s.config.FeedItemDays; days > 0 {
+		if run.FeedItemsArchived, err = s.ArchiveFeedItems(run.StartedAt.AddDate(0, 0, -days)); err != nil {
+			return err
+		}
+	}
+	if days := s.config.ScoreHistoryDays; days > 0 {
+		if run.ScoreHistoryPurged, err = s.PurgeScoreHistory(run.StartedAt.AddDate(0, 0, -days)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeHTML drops the cached HTML of articles cached before cutoff and returns
+// how many were purged. The extracted text and metadata are kept.
+func (s *RetentionService) PurgeHTML(cutoff time.Time) (int64, error) {
+	var purged int64
+	for {
+		result := s.db.Exec(`UPDATE articles SET html_content = '' WHERE id IN (
+			SELECT id FROM articles WHERE html_content <> '' AND COALESCE(cached_at, created_at) < ? LIMIT ?)`,
+			cutoff, retentionBatchSize)
+		if result.Error != nil {
+			return purged, fmt.Errorf("failed to purge cached HTML: %w", result.Error)
+		}
+		purged += result.RowsAffected
+		if result.RowsAffected < retentionBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// DeleteUnreachable deletes articles created before cutoff that are still
+// unreachable and were never ranked, and returns how many were deleted.
+// Pinned articles are kept.
+func (s *RetentionService) DeleteUnreachable(cutoff time.Time) (int64, error) {
+	var deleted int64
+	for {
+		var ids []uuid.UUID
+		err := s.db.Model(&models.Article{}).
+			Where("articles.is_reachable = ? AND articles.is_pinned = ? AND articles.created_at < ?", false, false, cutoff).
+			Where(neverRankedSQL).
+			Limit(retentionDeleteBatchSize).
+			Pluck("articles.id", &ids).Error
+		if err != nil {
+			return deleted, fmt.Errorf("failed to find unreachable articles: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := s.articles.deleteArticleAndReferences(id); err != nil {
+				return deleted, fmt.Errorf("failed to delete unreachable article %s: %w", id, err)
+			}
+			deleted++
+		}
+		if len(ids) < retentionDeleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// ArchiveFeedItems moves feed items added before cutoff to feed_items_archive
+// and returns how many were moved. Each batch is moved in one transaction, so
+// an item is never lost or in both tables.
+func (s *RetentionService) ArchiveFeedItems(cutoff time.Time) (int64, error) {
+	var archived int64
+	for {
+		var ids []uuid.UUID
+		err := s.db.Model(&models.FeedItem{}).
+			Where("added_at < ?", cutoff).
+			Limit(retentionBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return archived, fmt.Errorf("failed to find feed items to archive: %w", err)
+		}
+		if len(ids) == 0 {
+			return archived, nil
+		}
+
+		var moved int64
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Exec(`INSERT INTO feed_items_archive (id, feed_id, article_id, user_id, position, score, relevance, added_at, last_shown_at, archived_at)
+				SELECT id, feed_id, article_id, user_id, position, score, relevance, added_at, last_shown_at, ? FROM feed_items WHERE id IN ?`,
+				time.Now(), ids).Error
+			if err != nil {
+				return err
+			}
+			result := tx.Where("id IN ?", ids).Delete(&models.FeedItem{})
+			moved = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive feed items: %w", err)
+		}
+		archived += moved
+		if len(ids) < retentionBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// PurgeScoreHistory deletes score snapshots recorded before cutoff and returns
+// how many were deleted
+func (s *RetentionService) PurgeScoreHistory(cutoff time.Time) (int64, error) {
+	var purged int64
+	for {
+		result := s.db.Exec(`DELETE FROM score_history WHERE id IN (
+			SELECT id FROM score_history WHERE recorded_at < ? LIMIT ?)`,
+			cutoff, retentionBatchSize)
+		if result.Error != nil {
+			return purged, fmt.Errorf("failed to purge score history: %w", result.Error)
+		}
+		purged += result.RowsAffected
+		if result.RowsAffected < retentionBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// Runs returns the most recent retention runs, newest first
+func (s *RetentionService) Runs(limit int) ([]models.RetentionRun, error) {
+	var runs []models.RetentionRun
+	err := s.db.Order("started_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRetentionConfig(t *testing.T) {
+	t.Setenv("RETENTION_HTML_DAYS", "14")
+	t.Setenv("RETENTION_UNREACHABLE_DAYS", "-1")
+	t.Setenv("RETENTION_FEED_ITEMS_DAYS", "0")
+
+	config := LoadRetentionConfig()
+	assert.Equal(t, 14, config.HTMLContentDays)
+	assert.Equal(t, defaultUnreachableRetentionDays, config.UnreachableArticleDays, "negative periods are invalid")
+	assert.Equal(t, 0, config.FeedItemDays, "zero keeps feed items forever")
+	assert.Equal(t, defaultScoreHistoryRetentionDays, config.ScoreHistoryDays)
+}
+
+func TestRetentionRun(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+	old := now.AddDate(0, 0, -100)
+
+	feed := models.Feed{Name: "Retention test", FeedType: "global"}
+	require.NoError(t, db.Create(&feed).Error)
+	t.Cleanup(func() { db.Exec("DELETE FROM feeds WHERE id = ?", feed.ID) })
+
+	stale := models.Article{URL: "https://example.com/stale", Title: "Stale", HTMLContent: "<p>old</p>", IsReachable: true, CachedAt: &old}
+	fresh := models.Article{URL: "https://example.com/fresh", Title: "Fresh", HTMLContent: "<p>new</p>", IsReachable: true, CachedAt: &now}
+	dead := models.Article{URL: "https://example.com/dead", Title: "Dead"}
+	deadRanked := models.Article{URL: "https://example.com/dead-ranked", Title: "Dead but ranked"}
+	deadShown := models.Article{URL: "https://example.com/dead-shown", Title: "Dead but shown"}
+	deadPinned := models.Article{URL: "https://example.com/dead-pinned", Title: "Dead but pinned", IsPinned: true}
+	deadNew := models.Article{URL: "https://example.com/dead-new", Title: "Dead but new"}
+	for _, a := range []*models.Article{&stale, &fresh, &dead, &deadRanked, &deadShown, &deadPinned, &deadNew} {
+		require.NoError(t, db.Create(a).Error)
+	}
+	require.NoError(t, db.Model(&models.Article{}).
+		Where("id IN ?", []interface{}{stale.ID, dead.ID, deadRanked.ID, deadShown.ID, deadPinned.ID}).
+		UpdateColumn("created_at", old).Error)
+
+	oldItem := models.FeedItem{FeedID: feed.ID, ArticleID: deadRanked.ID, Position: 1}
+	newItem := models.FeedItem{FeedID: feed.ID, ArticleID: fresh.ID, Position: 2}
+	for _, item := range []*models.FeedItem{&oldItem, &newItem} {
+		require.NoError(t, db.Create(item).Error)
+	}
+	require.NoError(t, db.Model(&oldItem).UpdateColumn("added_at", old).Error)
+	require.NoError(t, db.Create(&models.Impression{ArticleID: deadShown.ID, Feed: "global", Surface: "api"}).Error)
+
+	service := NewRetentionService(db, RetentionConfig{HTMLContentDays: 30, UnreachableArticleDays: 7, FeedItemDays: 90})
+	run, err := service.Run()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), run.HTMLPurged)
+	assert.Equal(t, int64(1), run.ArticlesDeleted)
+	assert.Equal(t, int64(1), run.FeedItemsArchived)
+
+	require.NoError(t, db.First(&stale, "id = ?", stale.ID).Error)
+	assert.Empty(t, stale.HTMLContent)
+	require.NoError(t, db.First(&fresh, "id = ?", fresh.ID).Error)
+	assert.Equal(t, "<p>new</p>", fresh.HTMLContent)
+
+	var remaining []string
+	require.NoError(t, db.Model(&models.Article{}).Order("url").Pluck("url", &remaining).Error)
+	assert.NotContains(t, remaining, dead.URL)
+	assert.Contains(t, remaining, deadRanked.URL, "articles that were ranked are kept")
+	assert.Contains(t, remaining, deadShown.URL, "articles that were served are kept")
+	assert.Contains(t, remaining, deadPinned.URL)
+	assert.Contains(t, remaining, deadNew.URL)
+
+	var archived []models.ArchivedFeedItem
+	require.NoError(t, db.Find(&archived).Error)
+	require.Len(t, archived, 1)
+	assert.Equal(t, oldItem.ID, archived[0].ID)
+	var items int64
+	require.NoError(t, db.Model(&models.FeedItem{}).Count(&items).Error)
+	assert.Equal(t, int64(1), items)
+
+	runs, err := service.Runs(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, int64(1), runs[0].ArticlesDeleted)
+
+	// A second run finds nothing left to purge: the ranked article's feed item
+	// is archived, which still counts as ranked
+	run, err = service.Run()
+	require.NoError(t, err)
+	assert.Zero(t, run.HTMLPurged+run.ArticlesDeleted+run.FeedItemsArchived)
+}
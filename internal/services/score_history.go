@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// scoreHistoryWindow is how long after it's first seen an article's scores
+	// are recorded; past it the trending score no longer changes
+	scoreHistoryWindow = 48 * time.Hour
+
+	// scoreHistoryBatchSize is how many snapshots are inserted per statement
+	scoreHistoryBatchSize = 500
+)
+
+// ScoreHistoryService records snapshots of recent articles' quality and
+// trending scores after each quality score update, so how an article rose
+// and fell in the rankings can be charted
+type ScoreHistoryService struct {
+	db *gorm.DB
+}
+
+// NewScoreHistoryService creates a new score history service
+func NewScoreHistoryService(db *gorm.DB) *ScoreHistoryService {
+	return &ScoreHistoryService{db: db}
+}
+
+// Record snapshots the current scores of every article first seen within the
+// last two days, and returns how many were recorded
+func (s *ScoreHistoryService) Record(now time.Time) (int, error) {
+	var articles []models.Article
+	if err := s.db.Select("id", "quality_score", "trending_score").
+		Where("created_at > ?", now.Add(-scoreHistoryWindow)).
+		Find(&articles).Error; err != nil {
+		return 0, fmt.Errorf("failed to load recent articles: %w", err)
+	}
+	if len(articles) == 0 {
+		return 0, nil
+	}
+
+	history := make([]models.ScoreHistory, len(articles))
+	for i, article := range articles {
+		history[i] = models.ScoreHistory{
+			ArticleID:     article.ID,
+			QualityScore:  article.QualityScore,
+			TrendingScore: article.TrendingScore,
+			RecordedAt:    now,
+		}
+	}
+	if err := s.db.CreateInBatches(history, scoreHistoryBatchSize).Error; err != nil {
+		return 0, fmt.Errorf("failed to record score history: %w", err)
+	}
+	log.Printf("📈 Recorded the scores of %d recent articles", len(history))
+	return len(history), nil
+}
+
+// ForArticle returns an article's score snapshots recorded since a time, oldest first
+func (s *ScoreHistoryService) ForArticle(articleID uuid.UUID, since time.Time) ([]models.ScoreHistory, error) {
+	var history []models.ScoreHistory
+	err := s.db.Where("article_id = ? AND recorded_at >= ?", articleID, since).
+		Order("recorded_at ASC").
+		Find(&history).Error
+	return history, err
+}
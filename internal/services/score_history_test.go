@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreHistoryService(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+
+	recent := models.Article{URL: "https://example.com/history-recent", Title: "Recent", QualityScore: 0.4, TrendingScore: 1.5}
+	old := models.Article{URL: "https://example.com/history-old", Title: "Old", QualityScore: 0.6}
+	for _, a := range []*models.Article{&recent, &old} {
+		require.NoError(t, db.Create(a).Error)
+	}
+	require.NoError(t, db.Model(&old).UpdateColumn("created_at", now.AddDate(0, 0, -5)).Error)
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM score_history WHERE article_id IN ?", []interface{}{recent.ID, old.ID})
+		db.Exec("DELETE FROM articles WHERE id IN ?", []interface{}{recent.ID, old.ID})
+	})
+
+	service := NewScoreHistoryService(db)
+	_, err := service.Record(now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&recent).Update("trending_score", 1.1).Error)
+	_, err = service.Record(now)
+	require.NoError(t, err)
+
+	history, err := service.ForArticle(recent.ID, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 1.5, history[0].TrendingScore, "oldest first")
+	assert.Equal(t, 1.1, history[1].TrendingScore)
+
+	history, err = service.ForArticle(old.ID, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Empty(t, history, "only articles from the last two days are recorded")
+}
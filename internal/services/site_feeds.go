@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"open-news/internal/domains"
+	"open-news/internal/models"
+	"open-news/internal/pool"
+	"open-news/internal/sitefeeds"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSiteFeedInterval, defaultSiteFeedArticles and defaultSiteFeedMaxAge
+	// apply without SITE_FEEDS_INTERVAL_MINUTES, SITE_FEEDS_MAX_ARTICLES and
+	// SITE_FEEDS_MAX_AGE_HOURS
+	defaultSiteFeedInterval = time.Hour
+	defaultSiteFeedArticles = 20
+	defaultSiteFeedMaxAge   = 48 * time.Hour
+
+	// maxNestedSitemaps is how many of the sitemaps a sitemap index lists are
+	// read. News sites list their newest sitemaps first.
+	maxNestedSitemaps = 3
+)
+
+// SiteFeedConfig holds how often and how deeply sites' feeds are polled
+type SiteFeedConfig struct {
+	Interval    time.Duration // How long after a poll a site's feeds are polled again (default: 1 hour)
+	MaxArticles int           // New articles stored per site and poll (default: 20)
+	MaxAge      time.Duration // Entries dated further back are skipped (default: 48 hours)
+	Concurrency int           // Sites polled at once; 0 polls one at a time (default: WORKER_CONCURRENCY)
+}
+
+// LoadSiteFeedConfig reads the site feed settings from the environment
+func LoadSiteFeedConfig() SiteFeedConfig {
+	config := SiteFeedConfig{
+		Interval:    defaultSiteFeedInterval,
+		MaxArticles: defaultSiteFeedArticles,
+		MaxAge:      defaultSiteFeedMaxAge,
+		Concurrency: pool.Shared().Concurrency,
+	}
+	if value := os.Getenv("SITE_FEEDS_INTERVAL_MINUTES"); value != "" {
+		if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+			config.Interval = time.Duration(minutes) * time.Minute
+		} else {
+			log.Printf("Invalid SITE_FEEDS_INTERVAL_MINUTES %q, using %v", value, defaultSiteFeedInterval)
+		}
+	}
+	if value := os.Getenv("SITE_FEEDS_MAX_ARTICLES"); value != "" {
+		if articles, err := strconv.Atoi(value); err == nil && articles > 0 {
+			config.MaxArticles = articles
+		} else {
+			log.Printf("Invalid SITE_FEEDS_MAX_ARTICLES %q, using %d", value, defaultSiteFeedArticles)
+		}
+	}
+	if value := os.Getenv("SITE_FEEDS_MAX_AGE_HOURS"); value != "" {
+		if hours, err := strconv.Atoi(value); err == nil && hours > 0 {
+			config.MaxAge = time.Duration(hours) * time.Hour
+		} else {
+			log.Printf("Invalid SITE_FEEDS_MAX_AGE_HOURS %q, using %v", value, defaultSiteFeedMaxAge)
+		}
+	}
+	return config
+}
+
+// SiteFeedService stores the articles news sites list in their RSS and Atom
+// feeds and sitemaps before anyone shares them, for sites with ingest_feeds set
+// in the domains table that a verified source speaks for. Articles stored this
+// way are marked unshared, and kept out of feeds, until a share arrives.
+type SiteFeedService struct {
+	db       *gorm.DB
+	config   SiteFeedConfig
+	articles *ArticlesService
+}
+
+// NewSiteFeedService creates a new site feed service
+func NewSiteFeedService(db *gorm.DB, config SiteFeedConfig) *SiteFeedService {
+	return &SiteFeedService{db: db, config: config, articles: NewArticlesService(db, nil)}
+}
+
+// WithContext returns a site feed service running its queries, and those of the articles it stores, with ctx
+func (s *SiteFeedService) WithContext(ctx context.Context) *SiteFeedService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.articles = s.articles.WithContext(ctx)
+	return &scoped
+}
+
+// verifiedSourceForDomain matches domains a verified source that hasn't been
+// rejected speaks for, on the domain itself or a subdomain
+const verifiedSourceForDomain = `EXISTS (SELECT 1 FROM sources
+	WHERE sources.is_verified = ? AND sources.verification_status <> ?
+	AND (sources.verified_domain = domains.domain OR sources.verified_domain LIKE '%.' || domains.domain))`
+
+// PollDue polls the feeds of every site due a poll, several sites at once, and
+// returns how many articles were stored. A site that fails is logged and polled
+// again next time.
+func (s *SiteFeedService) PollDue(ctx context.Context, now time.Time) (int, error) {
+	var due []models.Domain
+	err := s.db.Where("ingest_feeds = ? AND (feeds_polled_at IS NULL OR feeds_polled_at < ?)", true, now.Add(-s.config.Interval)).
+		Where(verifiedSourceForDomain, true, models.VerificationRejected).
+		Order("feeds_polled_at ASC").
+		Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find sites due a feed poll: %w", err)
+	}
+
+	var mu sync.Mutex
+	stored := 0
+	group := pool.New(s.config.Concurrency)
+	for i := range due {
+		group.Go(func() error {
+			count, err := s.PollDomain(ctx, &due[i], now)
+			mu.Lock()
+			stored += count
+			mu.Unlock()
+			if err != nil {
+				log.Printf("⚠️  Failed to poll the feeds of %s: %v", due[i].Domain, err)
+				return fmt.Errorf("%s: %w", due[i].Domain, err)
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if stored > 0 {
+		log.Printf("📰 Stored %d unshared articles from %d sites' feeds", stored, len(due))
+	}
+	return stored, err
+}
+
+// PollDomain reads a site's feeds and stores the news articles they list that
+// aren't stored yet, newest first, up to MaxArticles. It returns how many were
+// stored.
+func (s *SiteFeedService) PollDomain(ctx context.Context, domain *models.Domain, now time.Time) (int, error) {
+	feedURLs := []string(domain.FeedURLs)
+	if len(feedURLs) == 0 {
+		var err error
+		if feedURLs, err = s.discover(ctx, domain.Domain); err != nil {
+			return 0, err
+		}
+	}
+
+	entries := s.entries(ctx, feedURLs)
+	if err := s.db.Model(domain).Update("feeds_polled_at", now).Error; err != nil {
+		return 0, fmt.Errorf("failed to record the poll: %w", err)
+	}
+
+	// Newest first, with undated entries last
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].PublishedAt, entries[j].PublishedAt
+		return a != nil && (b == nil || a.After(*b))
+	})
+
+	stored := 0
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if stored >= s.config.MaxArticles {
+			break
+		}
+		if entry.PublishedAt != nil && entry.PublishedAt.Before(now.Add(-s.config.MaxAge)) {
+			continue
+		}
+		pageURL := canonicalizeURL(entry.URL)
+		if seen[pageURL] || !domains.SameDomain(pageURL, domain.Domain) || s.articles.domains.OptedOut(pageURL) {
+			continue
+		}
+		seen[pageURL] = true
+
+		created, err := s.ingest(ctx, pageURL)
+		if err != nil {
+			log.Printf("⚠️  Skipping %s from the feeds of %s: %v", pageURL, domain.Domain, err)
+			continue
+		}
+		if created {
+			stored++
+		}
+	}
+	return stored, nil
+}
+
+// discover returns the feeds a site's home page advertises
+func (s *SiteFeedService) discover(ctx context.Context, domain string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	page, err := s.articles.fetcher.FetchHTML(ctx, "https://"+domain+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the home page: %w", err)
+	}
+	feedURLs := sitefeeds.Discover(page.Root, page.URL)
+	if len(feedURLs) == 0 {
+		return nil, errors.New("no feed_urls set and the home page advertises no feeds")
+	}
+	return feedURLs, nil
+}
+
+// entries reads the entries of feeds and sitemaps, following sitemap indexes
+// one level down. Feeds that fail are logged and skipped.
+func (s *SiteFeedService) entries(ctx context.Context, feedURLs []string) []sitefeeds.Entry {
+	var entries []sitefeeds.Entry
+	for _, feedURL := range feedURLs {
+		feed, err := s.read(ctx, feedURL)
+		if err != nil {
+			log.Printf("⚠️  Failed to read feed %s: %v", feedURL, err)
+			continue
+		}
+		entries = append(entries, feed.Entries...)
+
+		for i, sitemapURL := range feed.Sitemaps {
+			if i == maxNestedSitemaps {
+				break
+			}
+			sitemap, err := s.read(ctx, sitemapURL)
+			if err != nil {
+				log.Printf("⚠️  Failed to read sitemap %s: %v", sitemapURL, err)
+				continue
+			}
+			entries = append(entries, sitemap.Entries...)
+		}
+	}
+	return entries
+}
+
+// read fetches and parses a feed or sitemap
+func (s *SiteFeedService) read(ctx context.Context, feedURL string) (*sitefeeds.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	body, err := s.articles.fetcher.FetchFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	return sitefeeds.Parse(body, feedURL)
+}
+
+// ingest stores the article at a URL, marked unshared, unless it's stored
+// already or isn't a news article. It reports whether it was stored.
+func (s *SiteFeedService) ingest(ctx context.Context, pageURL string) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Article{}).Where("url = ?", pageURL).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	isNews, err := s.articles.CheckIfNewsArticle(checkCtx, pageURL)
+	cancel()
+	if err != nil {
+		return false, err
+	}
+	if !isNews {
+		return false, nil
+	}
+
+	extractCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	metadata, err := s.articles.ExtractArticleMetadata(extractCtx, pageURL)
+	cancel()
+	if err != nil {
+		return false, fmt.Errorf("failed to extract metadata: %w", err)
+	}
+
+	article := articleFromMetadata(pageURL, metadata)
+	article.IsUnshared = true
+	return models.InsertArticle(s.db, &article)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"open-news/internal/fetcher"
+	"open-news/internal/models"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSiteFeedConfig(t *testing.T) {
+	t.Setenv("SITE_FEEDS_INTERVAL_MINUTES", "30")
+	t.Setenv("SITE_FEEDS_MAX_ARTICLES", "none")
+	t.Setenv("SITE_FEEDS_MAX_AGE_HOURS", "")
+	config := LoadSiteFeedConfig()
+	assert.Equal(t, 30*time.Minute, config.Interval)
+	assert.Equal(t, defaultSiteFeedArticles, config.MaxArticles)
+	assert.Equal(t, defaultSiteFeedMaxAge, config.MaxAge)
+}
+
+func TestSiteFeedPolling(t *testing.T) {
+	db := setupTestDB(t)
+	db.Exec("DELETE FROM domains")
+
+	now := time.Now()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<rss version="2.0"><channel>
+				<item><link>%[1]s/fresh</link><pubDate>%[2]s</pubDate></item>
+				<item><link>%[1]s/old</link><pubDate>%[3]s</pubDate></item>
+				<item><link>%[1]s/about</link></item>
+				<item><link>https://elsewhere.example/story</link></item>
+			</channel></rss>`, server.URL, now.Add(-time.Hour).Format(time.RFC1123Z), now.Add(-30*24*time.Hour).Format(time.RFC1123Z))
+		case "/fresh", "/old":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>Fresh story</title>
+				<script type="application/ld+json">{"@type":"NewsArticle","headline":"Fresh story"}</script>
+				</head><body><p>Story</p></body></html>`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>About us</title></head><body></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	domain := models.Domain{Domain: "127.0.0.1", Score: 0.5, IngestFeeds: true, FeedURLs: pq.StringArray{server.URL + "/rss"}}
+	require.NoError(t, db.Create(&domain).Error)
+
+	service := NewSiteFeedService(db, SiteFeedConfig{Interval: time.Hour, MaxArticles: 10, MaxAge: 48 * time.Hour})
+	service.articles.fetcher = fetcher.NewFetcher(fetcher.Options{AllowPrivateNetworks: true})
+
+	stored, err := service.PollDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, stored, "sites no verified source speaks for aren't polled")
+
+	source := models.Source{BlueSkyDID: "did:plc:testsitefeeds", Handle: "site-feeds.test", IsVerified: true, VerifiedDomain: "127.0.0.1"}
+	require.NoError(t, db.Create(&source).Error)
+
+	stored, err = service.PollDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored, "only the recent news article on the site is stored")
+
+	var article models.Article
+	require.NoError(t, db.Where("url = ?", server.URL+"/fresh").First(&article).Error)
+	assert.True(t, article.IsUnshared)
+	assert.Equal(t, "Fresh story", article.Title)
+
+	stored, err = service.PollDue(context.Background(), now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, stored, "the site isn't due another poll yet")
+
+	require.NoError(t, models.MarkShared(db, article.ID))
+	require.NoError(t, db.First(&article, "id = ?", article.ID).Error)
+	assert.False(t, article.IsUnshared)
+}
@@ -0,0 +1,663 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Export
+const SnapshotVersion = 1
+
+// Snapshot formats
+const (
+	SnapshotNDJSON = "ndjson" // One record per line, each with a "type", written as it's read
+	SnapshotJSON   = "json"   // A single Snapshot document
+)
+
+// snapshotBatchSize is how many rows are read at a time while exporting
+const snapshotBatchSize = 500
+
+// Snapshot is a portable copy of the global feeds and the articles and sources
+// behind them. Rows refer to each other by article URL and source DID rather
+// than by ID, so a snapshot can be imported into another instance.
+type Snapshot struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Sources    []SnapshotSource  `json:"sources"`
+	Articles   []SnapshotArticle `json:"articles"`
+	Shares     []SnapshotShare   `json:"shares"`
+	Feeds      []SnapshotFeed    `json:"feeds"`
+}
+
+// SnapshotSource is a source in a snapshot
+type SnapshotSource struct {
+	DID                string    `json:"did"`
+	Handle             string    `json:"handle"`
+	DisplayName        string    `json:"display_name,omitempty"`
+	Avatar             string    `json:"avatar,omitempty"`
+	Bio                string    `json:"bio,omitempty"`
+	FollowersCount     int       `json:"followers_count"`
+	QualityScore       float64   `json:"quality_score"`
+	IsVerified         bool      `json:"is_verified"`
+	VerificationStatus string    `json:"verification_status,omitempty"`
+	VerifiedDomain     string    `json:"verified_domain,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// SnapshotArticle is an article in a snapshot. The cached page is only
+// included when exported with IncludeContent.
+type SnapshotArticle struct {
+	URL            string     `json:"url"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description,omitempty"`
+	Summary        string     `json:"summary,omitempty"`
+	Author         string     `json:"author,omitempty"`
+	Authors        []string   `json:"authors,omitempty"`
+	SiteName       string     `json:"site_name,omitempty"`
+	ImageURL       string     `json:"image_url,omitempty"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+	Language       string     `json:"language,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+	WordCount      int        `json:"word_count"`
+	ReadingTime    int        `json:"reading_time"`
+	QualityScore   float64    `json:"quality_score"`
+	TrendingScore  float64    `json:"trending_score"`
+	IsReachable    bool       `json:"is_reachable"`
+	IsNotNews      bool       `json:"is_not_news,omitempty"`
+	IsPinned       bool       `json:"is_pinned,omitempty"`
+	EditorialBoost float64    `json:"editorial_boost,omitempty"`
+	JSONLDData     string     `json:"jsonld_data,omitempty"`
+	HTMLContent    string     `json:"html_content,omitempty"`
+	TextContent    string     `json:"text_content,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// SnapshotShare is a source's post of an article
+type SnapshotShare struct {
+	SourceDID    string    `json:"source_did"`
+	ArticleURL   string    `json:"article_url"`
+	PostURI      string    `json:"post_uri"`
+	PostCID      string    `json:"post_cid,omitempty"`
+	PostText     string    `json:"post_text,omitempty"`
+	ShareType    string    `json:"share_type,omitempty"`
+	IsRepost     bool      `json:"is_repost,omitempty"`
+	OriginalURI  string    `json:"original_uri,omitempty"`
+	PostedAt     time.Time `json:"posted_at"`
+	Labels       []string  `json:"labels,omitempty"`
+	IsSensitive  bool      `json:"is_sensitive,omitempty"`
+	LikesCount   int       `json:"likes_count"`
+	RepostsCount int       `json:"reposts_count"`
+	RepliesCount int       `json:"replies_count"`
+	ShareScore   float64   `json:"share_score"`
+}
+
+// SnapshotFeed is a global feed and its current items, best first
+type SnapshotFeed struct {
+	Name             string             `json:"name"`
+	Description      string             `json:"description,omitempty"`
+	MaxItems         int                `json:"max_items"`
+	QualityThreshold float64            `json:"quality_threshold"`
+	MaxPerSource     int                `json:"max_per_source,omitempty"`
+	MaxPerDomain     int                `json:"max_per_domain,omitempty"`
+	MaxPerCluster    int                `json:"max_per_cluster,omitempty"`
+	Items            []SnapshotFeedItem `json:"items"`
+}
+
+// SnapshotFeedItem is an article's place in a feed
+type SnapshotFeedItem struct {
+	ArticleURL string  `json:"article_url"`
+	Position   int     `json:"position"`
+	Score      float64 `json:"score"`
+}
+
+// snapshotRecord is a line of an ndjson snapshot: a header, then one row per line
+type snapshotRecord struct {
+	Type       string           `json:"type"` // header, source, article, share or feed
+	Version    int              `json:"version,omitempty"`
+	ExportedAt *time.Time       `json:"exported_at,omitempty"`
+	Source     *SnapshotSource  `json:"source,omitempty"`
+	Article    *SnapshotArticle `json:"article,omitempty"`
+	Share      *SnapshotShare   `json:"share,omitempty"`
+	Feed       *SnapshotFeed    `json:"feed,omitempty"`
+}
+
+// ExportOptions selects what a snapshot contains
+type ExportOptions struct {
+	Format         string    // SnapshotNDJSON (default) or SnapshotJSON
+	AllArticles    bool      // Every article instead of only those in the global feeds
+	Since          time.Time // With AllArticles, only articles created since; zero for all
+	IncludeContent bool      // Include cached pages, which makes snapshots much larger
+}
+
+// ImportResult counts the rows a snapshot import added and left alone
+type ImportResult struct {
+	Sources   int `json:"sources"`  // Sources added
+	Articles  int `json:"articles"` // Articles added
+	Shares    int `json:"shares"`   // Shares added
+	Feeds     int `json:"feeds"`    // Feeds whose items were replaced
+	FeedItems int `json:"feed_items"`
+	Existing  int `json:"existing"` // Sources, articles and shares already stored
+	Skipped   int `json:"skipped"`  // Shares and feed items whose article or source wasn't found
+}
+
+// SnapshotService exports and imports snapshots
+type SnapshotService struct {
+	db *gorm.DB
+}
+
+// NewSnapshotService creates a new SnapshotService
+func NewSnapshotService(db *gorm.DB) *SnapshotService {
+	return &SnapshotService{db: db}
+}
+
+// Export writes a snapshot of the global feeds, their articles and the sources
+// that shared them to w. An ndjson snapshot is written as it's read, so it can
+// be as large as the database.
+func (s *SnapshotService) Export(w io.Writer, options ExportOptions) error {
+	switch options.Format {
+	case "", SnapshotNDJSON:
+		out := bufio.NewWriter(w)
+		encoder := json.NewEncoder(out)
+		if err := s.export(options, encoder.Encode); err != nil {
+			return err
+		}
+		return out.Flush()
+	case SnapshotJSON:
+		snapshot := Snapshot{
+			Sources:  []SnapshotSource{},
+			Articles: []SnapshotArticle{},
+			Shares:   []SnapshotShare{},
+			Feeds:    []SnapshotFeed{},
+		}
+		err := s.export(options, func(value interface{}) error {
+			record := value.(snapshotRecord)
+			switch record.Type {
+			case "header":
+				snapshot.Version, snapshot.ExportedAt = record.Version, *record.ExportedAt
+			case "source":
+				snapshot.Sources = append(snapshot.Sources, *record.Source)
+			case "article":
+				snapshot.Articles = append(snapshot.Articles, *record.Article)
+			case "share":
+				snapshot.Shares = append(snapshot.Shares, *record.Share)
+			case "feed":
+				snapshot.Feeds = append(snapshot.Feeds, *record.Feed)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(snapshot)
+	default:
+		return fmt.Errorf("unknown snapshot format %q", options.Format)
+	}
+}
+
+// export passes the snapshot's records to emit in import order: the header,
+// sources, articles, shares, then feeds
+func (s *SnapshotService) export(options ExportOptions, emit func(interface{}) error) error {
+	var feeds []models.Feed
+	if err := s.db.Where("feed_type = ?", "global").Order("name").Find(&feeds).Error; err != nil {
+		return fmt.Errorf("failed to load feeds: %w", err)
+	}
+	feedIDs := make([]uuid.UUID, len(feeds))
+	for i, feed := range feeds {
+		feedIDs[i] = feed.ID
+	}
+
+	// The articles in the snapshot, and the sources that shared them
+	articles := s.db.Model(&models.Article{})
+	if options.AllArticles {
+		if !options.Since.IsZero() {
+			articles = articles.Where("created_at >= ?", options.Since)
+		}
+	} else {
+		articles = articles.Where("id IN (?)", s.db.Model(&models.FeedItem{}).
+			Select("article_id").Where("feed_id IN ? AND user_id IS NULL", feedIDs))
+	}
+	articleIDs := articles.Session(&gorm.Session{}).Select("id")
+	shares := s.db.Model(&models.SourceArticle{}).Where("article_id IN (?)", articleIDs)
+	sources := s.db.Model(&models.Source{}).Where("id IN (?)", shares.Session(&gorm.Session{}).Select("source_id"))
+
+	now := time.Now().UTC()
+	if err := emit(snapshotRecord{Type: "header", Version: SnapshotVersion, ExportedAt: &now}); err != nil {
+		return err
+	}
+
+	var sourceBatch []models.Source
+	err := sources.Order("id").FindInBatches(&sourceBatch, snapshotBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, source := range sourceBatch {
+			if err := emit(snapshotRecord{Type: "source", Source: snapshotSource(source)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to export sources: %w", err)
+	}
+
+	columns := []string{"id", "url", "title", "description", "summary", "author", "authors", "site_name", "image_url", "published_at",
+		"language", "tags", "word_count", "reading_time", "quality_score", "trending_score", "is_reachable",
+		"is_not_news", "is_pinned", "editorial_boost", "created_at"}
+	if options.IncludeContent {
+		columns = append(columns, "json_ld_data", "html_content", "text_content")
+	}
+	var articleBatch []models.Article
+	err = articles.Select(columns).Order("id").FindInBatches(&articleBatch, snapshotBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, article := range articleBatch {
+			if err := emit(snapshotRecord{Type: "article", Article: snapshotArticle(article)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to export articles: %w", err)
+	}
+
+	var shareBatch []models.SourceArticle
+	err = shares.Preload("Source").Preload("Article", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "url")
+	}).Order("id").FindInBatches(&shareBatch, snapshotBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, share := range shareBatch {
+			if err := emit(snapshotRecord{Type: "share", Share: snapshotShare(share)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to export shares: %w", err)
+	}
+
+	for _, feed := range feeds {
+		var items []models.FeedItem
+		err := s.db.Preload("Article", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "url")
+		}).Where("feed_id = ? AND user_id IS NULL", feed.ID).Order("position").Find(&items).Error
+		if err != nil {
+			return fmt.Errorf("failed to export feed %s: %w", feed.Name, err)
+		}
+		if err := emit(snapshotRecord{Type: "feed", Feed: snapshotFeed(feed, items)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import adds the sources, articles and shares in a snapshot, written in either
+// format, that aren't stored yet, leaving stored rows as they are. The items of
+// each feed in the snapshot replace those of the global feed with its name,
+// which is created if missing; feed updates rank them again as usual.
+func (s *SnapshotService) Import(r io.Reader) (*ImportResult, error) {
+	importer := &snapshotImporter{
+		db:       s.db,
+		sources:  make(map[string]uuid.UUID),
+		articles: make(map[string]uuid.UUID),
+		result:   &ImportResult{},
+	}
+
+	decoder := json.NewDecoder(r)
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var header snapshotRecord
+	if err := json.Unmarshal(first, &header); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if header.Type == "" {
+		var snapshot Snapshot
+		if err := json.Unmarshal(first, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if err := checkSnapshotVersion(snapshot.Version); err != nil {
+			return nil, err
+		}
+		return importer.result, importer.snapshot(&snapshot)
+	}
+
+	if header.Type != "header" {
+		return nil, fmt.Errorf("snapshot starts with a %s record instead of its header", header.Type)
+	}
+	if err := checkSnapshotVersion(header.Version); err != nil {
+		return nil, err
+	}
+	for line := 2; ; line++ {
+		var record snapshotRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return importer.result, nil
+		} else if err != nil {
+			return importer.result, fmt.Errorf("failed to read record %d: %w", line, err)
+		}
+		if err := importer.record(&record); err != nil {
+			return importer.result, fmt.Errorf("record %d: %w", line, err)
+		}
+	}
+}
+
+// checkSnapshotVersion rejects snapshots written by a newer format
+func checkSnapshotVersion(version int) error {
+	if version < 1 || version > SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	return nil
+}
+
+// snapshotImporter stores the rows of one snapshot, remembering the IDs of the
+// sources and articles it has seen
+type snapshotImporter struct {
+	db       *gorm.DB
+	sources  map[string]uuid.UUID // By DID
+	articles map[string]uuid.UUID // By URL
+	result   *ImportResult
+}
+
+// snapshot imports every row of a JSON snapshot
+func (i *snapshotImporter) snapshot(snapshot *Snapshot) error {
+	for k := range snapshot.Sources {
+		if err := i.source(&snapshot.Sources[k]); err != nil {
+			return err
+		}
+	}
+	for k := range snapshot.Articles {
+		if err := i.article(&snapshot.Articles[k]); err != nil {
+			return err
+		}
+	}
+	for k := range snapshot.Shares {
+		if err := i.share(&snapshot.Shares[k]); err != nil {
+			return err
+		}
+	}
+	for k := range snapshot.Feeds {
+		if err := i.feed(&snapshot.Feeds[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record imports one row of an ndjson snapshot
+func (i *snapshotImporter) record(record *snapshotRecord) error {
+	switch {
+	case record.Type == "source" && record.Source != nil:
+		return i.source(record.Source)
+	case record.Type == "article" && record.Article != nil:
+		return i.article(record.Article)
+	case record.Type == "share" && record.Share != nil:
+		return i.share(record.Share)
+	case record.Type == "feed" && record.Feed != nil:
+		return i.feed(record.Feed)
+	}
+	return fmt.Errorf("unknown or empty %q record", record.Type)
+}
+
+func (i *snapshotImporter) source(data *SnapshotSource) error {
+	if data.DID == "" || data.Handle == "" {
+		return errors.New("source without a DID or handle")
+	}
+	source := models.Source{
+		BlueSkyDID:         data.DID,
+		Handle:             data.Handle,
+		DisplayName:        data.DisplayName,
+		Avatar:             data.Avatar,
+		Bio:                data.Bio,
+		FollowersCount:     data.FollowersCount,
+		QualityScore:       data.QualityScore,
+		IsVerified:         data.IsVerified,
+		VerificationStatus: data.VerificationStatus,
+		VerifiedDomain:     data.VerifiedDomain,
+		IsActive:           true,
+	}
+	created, err := models.InsertSource(i.db, &source)
+	if err != nil {
+		return fmt.Errorf("failed to import source %s: %w", data.Handle, err)
+	}
+	i.count(created, &i.result.Sources)
+	i.sources[data.DID] = source.ID
+	return nil
+}
+
+func (i *snapshotImporter) article(data *SnapshotArticle) error {
+	if data.URL == "" {
+		return errors.New("article without a URL")
+	}
+	article := models.Article{
+		URL:            data.URL,
+		Title:          data.Title,
+		Description:    data.Description,
+		Summary:        data.Summary,
+		Author:         data.Author,
+		Authors:        pq.StringArray(data.Authors),
+		SiteName:       data.SiteName,
+		ImageURL:       data.ImageURL,
+		PublishedAt:    data.PublishedAt,
+		Language:       data.Language,
+		Tags:           pq.StringArray(data.Tags),
+		WordCount:      data.WordCount,
+		ReadingTime:    data.ReadingTime,
+		QualityScore:   data.QualityScore,
+		TrendingScore:  data.TrendingScore,
+		IsReachable:    data.IsReachable,
+		IsNotNews:      data.IsNotNews,
+		IsPinned:       data.IsPinned,
+		EditorialBoost: data.EditorialBoost,
+		JSONLDData:     data.JSONLDData,
+		HTMLContent:    data.HTMLContent,
+		TextContent:    data.TextContent,
+		IsCached:       data.HTMLContent != "",
+	}
+	created, err := models.InsertArticle(i.db, &article)
+	if err != nil {
+		return fmt.Errorf("failed to import article %s: %w", data.URL, err)
+	}
+	i.count(created, &i.result.Articles)
+	i.articles[data.URL] = article.ID
+	return nil
+}
+
+func (i *snapshotImporter) share(data *SnapshotShare) error {
+	sourceID, ok := i.lookup(i.sources, &models.Source{}, "blue_sky_d_id", data.SourceDID)
+	if !ok {
+		i.result.Skipped++
+		return nil
+	}
+	articleID, ok := i.lookup(i.articles, &models.Article{}, "url", data.ArticleURL)
+	if !ok {
+		i.result.Skipped++
+		return nil
+	}
+	share := models.SourceArticle{
+		SourceID:     sourceID,
+		ArticleID:    articleID,
+		PostURI:      data.PostURI,
+		PostCID:      data.PostCID,
+		PostText:     data.PostText,
+		ShareType:    data.ShareType,
+		IsRepost:     data.IsRepost,
+		OriginalURI:  data.OriginalURI,
+		PostedAt:     data.PostedAt,
+		Labels:       pq.StringArray(data.Labels),
+		IsSensitive:  data.IsSensitive,
+		LikesCount:   data.LikesCount,
+		RepostsCount: data.RepostsCount,
+		RepliesCount: data.RepliesCount,
+		ShareScore:   data.ShareScore,
+	}
+	created, err := models.InsertShare(i.db, &share)
+	if err != nil {
+		return fmt.Errorf("failed to import share %s: %w", data.PostURI, err)
+	}
+	i.count(created, &i.result.Shares)
+	return nil
+}
+
+func (i *snapshotImporter) feed(data *SnapshotFeed) error {
+	if data.Name == "" {
+		return errors.New("feed without a name")
+	}
+	var items []models.FeedItem
+	for _, item := range data.Items {
+		articleID, ok := i.lookup(i.articles, &models.Article{}, "url", item.ArticleURL)
+		if !ok {
+			i.result.Skipped++
+			continue
+		}
+		items = append(items, models.FeedItem{ArticleID: articleID, Position: item.Position, Score: item.Score})
+	}
+
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		feed := models.Feed{
+			Name:             data.Name,
+			Description:      data.Description,
+			FeedType:         "global",
+			IsActive:         true,
+			MaxItems:         data.MaxItems,
+			QualityThreshold: data.QualityThreshold,
+			MaxPerSource:     data.MaxPerSource,
+			MaxPerDomain:     data.MaxPerDomain,
+			MaxPerCluster:    data.MaxPerCluster,
+		}
+		if err := tx.Where("feed_type = ? AND name = ?", "global", data.Name).FirstOrCreate(&feed).Error; err != nil {
+			return fmt.Errorf("failed to import feed %s: %w", data.Name, err)
+		}
+		if err := tx.Where("feed_id = ? AND user_id IS NULL", feed.ID).Delete(&models.FeedItem{}).Error; err != nil {
+			return fmt.Errorf("failed to replace items of feed %s: %w", data.Name, err)
+		}
+		for k := range items {
+			items[k].FeedID = feed.ID
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(&items, snapshotBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to import items of feed %s: %w", data.Name, err)
+			}
+		}
+		i.result.Feeds++
+		i.result.FeedItems += len(items)
+		return nil
+	})
+}
+
+// lookup returns the ID of the row whose column is value, from the rows this
+// import has seen or else the database
+func (i *snapshotImporter) lookup(seen map[string]uuid.UUID, model interface{}, column, value string) (uuid.UUID, bool) {
+	if id, ok := seen[value]; ok {
+		return id, true
+	}
+	var ids []uuid.UUID
+	if err := i.db.Model(model).Where(column+" = ?", value).Limit(1).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return uuid.Nil, false
+	}
+	seen[value] = ids[0]
+	return ids[0], true
+}
+
+// count adds a row to added when it was created, or to the existing rows
+func (i *snapshotImporter) count(created bool, added *int) {
+	if created {
+		*added++
+	} else {
+		i.result.Existing++
+	}
+}
+
+func snapshotSource(source models.Source) *SnapshotSource {
+	return &SnapshotSource{
+		DID:                source.BlueSkyDID,
+		Handle:             source.Handle,
+		DisplayName:        source.DisplayName,
+		Avatar:             source.Avatar,
+		Bio:                source.Bio,
+		FollowersCount:     source.FollowersCount,
+		QualityScore:       source.QualityScore,
+		IsVerified:         source.IsVerified,
+		VerificationStatus: source.VerificationStatus,
+		VerifiedDomain:     source.VerifiedDomain,
+		CreatedAt:          source.CreatedAt,
+	}
+}
+
+func snapshotArticle(article models.Article) *SnapshotArticle {
+	return &SnapshotArticle{
+		URL:            article.URL,
+		Title:          article.Title,
+		Description:    article.Description,
+		Summary:        article.Summary,
+		Author:         article.Author,
+		Authors:        article.Authors,
+		SiteName:       article.SiteName,
+		ImageURL:       article.ImageURL,
+		PublishedAt:    article.PublishedAt,
+		Language:       article.Language,
+		Tags:           article.Tags,
+		WordCount:      article.WordCount,
+		ReadingTime:    article.ReadingTime,
+		QualityScore:   article.QualityScore,
+		TrendingScore:  article.TrendingScore,
+		IsReachable:    article.IsReachable,
+		IsNotNews:      article.IsNotNews,
+		IsPinned:       article.IsPinned,
+		EditorialBoost: article.EditorialBoost,
+		JSONLDData:     article.JSONLDData,
+		HTMLContent:    article.HTMLContent,
+		TextContent:    article.TextContent,
+		CreatedAt:      article.CreatedAt,
+	}
+}
+
+func snapshotShare(share models.SourceArticle) *SnapshotShare {
+	return &SnapshotShare{
+		SourceDID:    share.Source.BlueSkyDID,
+		ArticleURL:   share.Article.URL,
+		PostURI:      share.PostURI,
+		PostCID:      share.PostCID,
+		PostText:     share.PostText,
+		ShareType:    share.ShareType,
+		IsRepost:     share.IsRepost,
+		OriginalURI:  share.OriginalURI,
+		PostedAt:     share.PostedAt,
+		Labels:       share.Labels,
+		IsSensitive:  share.IsSensitive,
+		LikesCount:   share.LikesCount,
+		RepostsCount: share.RepostsCount,
+		RepliesCount: share.RepliesCount,
+		ShareScore:   share.ShareScore,
+	}
+}
+
+func snapshotFeed(feed models.Feed, items []models.FeedItem) *SnapshotFeed {
+	snapshot := &SnapshotFeed{
+		Name:             feed.Name,
+		Description:      feed.Description,
+		MaxItems:         feed.MaxItems,
+		QualityThreshold: feed.QualityThreshold,
+		MaxPerSource:     feed.MaxPerSource,
+		MaxPerDomain:     feed.MaxPerDomain,
+		MaxPerCluster:    feed.MaxPerCluster,
+		Items:            make([]SnapshotFeedItem, 0, len(items)),
+	}
+	for _, item := range items {
+		snapshot.Items = append(snapshot.Items, SnapshotFeedItem{
+			ArticleURL: item.Article.URL,
+			Position:   item.Position,
+			Score:      item.Score,
+		})
+	}
+	return snapshot
+}
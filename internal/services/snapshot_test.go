@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+
+	feed := models.Feed{Name: "Snapshot test", FeedType: "global", IsActive: true, MaxItems: 50, QualityThreshold: 0.4}
+	require.NoError(t, db.Create(&feed).Error)
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM feed_items")
+		db.Exec("DELETE FROM feeds WHERE name = ?", feed.Name)
+	})
+
+	source := models.Source{BlueSkyDID: "did:plc:testsnapshot", Handle: "snapshot.test", DisplayName: "Snapshot News", QualityScore: 0.8, IsActive: true}
+	require.NoError(t, db.Create(&source).Error)
+	ranked := models.Article{URL: "https://example.com/ranked", Title: "Ranked", QualityScore: 0.9, Tags: []string{"tech"}, HTMLContent: "<p>page</p>"}
+	unranked := models.Article{URL: "https://example.com/unranked", Title: "Unranked"}
+	for _, article := range []*models.Article{&ranked, &unranked} {
+		require.NoError(t, db.Create(article).Error)
+	}
+	share := models.SourceArticle{SourceID: source.ID, ArticleID: ranked.ID, PostURI: "at://did:plc:testsnapshot/app.bsky.feed.post/1", PostedAt: time.Now(), LikesCount: 7}
+	require.NoError(t, db.Create(&share).Error)
+	require.NoError(t, db.Create(&models.FeedItem{FeedID: feed.ID, ArticleID: ranked.ID, Position: 1, Score: 0.9}).Error)
+
+	service := NewSnapshotService(db)
+	var ndjson bytes.Buffer
+	require.NoError(t, service.Export(&ndjson, ExportOptions{}))
+
+	// Records come in import order, and only the feed's article is included
+	var types []string
+	scanner := bufio.NewScanner(&ndjson)
+	var exported []string
+	for scanner.Scan() {
+		exported = append(exported, scanner.Text())
+		var record snapshotRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		types = append(types, record.Type)
+		if record.Article != nil {
+			assert.Equal(t, ranked.URL, record.Article.URL)
+			assert.Empty(t, record.Article.HTMLContent, "pages are only exported on request")
+		}
+	}
+	assert.Equal(t, []string{"header", "source", "article", "share", "feed"}, types)
+
+	var document bytes.Buffer
+	require.NoError(t, service.Export(&document, ExportOptions{Format: SnapshotJSON, AllArticles: true, IncludeContent: true}))
+	var snapshot Snapshot
+	require.NoError(t, json.Unmarshal(document.Bytes(), &snapshot))
+	assert.Equal(t, SnapshotVersion, snapshot.Version)
+	assert.Len(t, snapshot.Articles, 2)
+	assert.Len(t, snapshot.Shares, 1)
+
+	// Import into an empty database
+	db.Exec("DELETE FROM feed_items")
+	db.Exec("DELETE FROM source_articles")
+	db.Exec("DELETE FROM articles")
+	db.Exec("DELETE FROM sources WHERE blue_sky_d_id = ?", source.BlueSkyDID)
+	db.Exec("DELETE FROM feeds WHERE name = ?", feed.Name)
+
+	result, err := service.Import(strings.NewReader(strings.Join(exported, "\n")))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Sources)
+	assert.Equal(t, 1, result.Articles)
+	assert.Equal(t, 1, result.Shares)
+	assert.Equal(t, 1, result.FeedItems)
+
+	var imported models.Feed
+	require.NoError(t, db.Where("feed_type = ? AND name = ?", "global", feed.Name).First(&imported).Error)
+	assert.Equal(t, 50, imported.MaxItems)
+	var item models.FeedItem
+	require.NoError(t, db.Preload("Article").Where("feed_id = ?", imported.ID).First(&item).Error)
+	assert.Equal(t, ranked.URL, item.Article.URL)
+	assert.Equal(t, 1, item.Position)
+	var importedShare models.SourceArticle
+	require.NoError(t, db.Preload("Source").Where("post_uri = ?", share.PostURI).First(&importedShare).Error)
+	assert.Equal(t, source.Handle, importedShare.Source.Handle)
+	assert.Equal(t, 7, importedShare.LikesCount)
+
+	// Importing the JSON document keeps stored rows and adds the rest
+	result, err = service.Import(&document)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Articles, "only the unranked article is new")
+	assert.Equal(t, 0, result.Shares)
+	assert.Equal(t, 3, result.Existing)
+	var feedCount int64
+	db.Model(&models.Feed{}).Where("name = ?", feed.Name).Count(&feedCount)
+	assert.Equal(t, int64(1), feedCount, "feeds are matched by name")
+}
+
+func TestSnapshotImportRejectsUnknownVersions(t *testing.T) {
+	service := NewSnapshotService(nil)
+
+	_, err := service.Import(strings.NewReader(`{"type":"header","version":99}`))
+	assert.ErrorContains(t, err, "unsupported snapshot version")
+
+	_, err = service.Import(strings.NewReader(`{"type":"article","article":{"url":"https://example.com"}}`))
+	assert.ErrorContains(t, err, "instead of its header")
+}
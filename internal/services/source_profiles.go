@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProfileClientInterface defines the Bluesky profile lookups used for source enrichment
+type ProfileClientInterface interface {
+	GetProfiles(actors []string) ([]bluesky.Author, error)
+}
+
+// SourceProfileService keeps source profile details such as follower counts up to date
+type SourceProfileService struct {
+	db            *gorm.DB
+	blueskyClient ProfileClientInterface
+}
+
+// NewSourceProfileService creates a new SourceProfileService
+func NewSourceProfileService(db *gorm.DB, blueskyClient ProfileClientInterface) *SourceProfileService {
+	return &SourceProfileService{
+		db:            db,
+		blueskyClient: blueskyClient,
+	}
+}
+
+// WithContext returns a profile service running its queries, and its Bluesky API calls when they go through a bluesky.Client, with ctx
+func (s *SourceProfileService) WithContext(ctx context.Context) *SourceProfileService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	if client, ok := s.blueskyClient.(*bluesky.Client); ok {
+		scoped.blueskyClient = client.WithContext(ctx)
+	}
+	return &scoped
+}
+
+// ErrProfileNotFound is returned when Bluesky has no profile for an actor
+var ErrProfileNotFound = errors.New("bluesky profile not found")
+
+// AddSource creates a source for a handle or DID outside of user follows, filling
+// in its profile. An existing source is refreshed and returned with created false.
+func (s *SourceProfileService) AddSource(actor string) (*models.Source, bool, error) {
+	actor = strings.TrimPrefix(strings.TrimSpace(actor), "@")
+	if actor == "" {
+		return nil, false, fmt.Errorf("handle or DID is required")
+	}
+
+	profiles, err := s.blueskyClient.GetProfiles([]string{actor})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if len(profiles) == 0 || profiles[0].DID == "" {
+		return nil, false, ErrProfileNotFound
+	}
+	profile := &profiles[0]
+
+	var source models.Source
+	err = s.db.Where("blue_sky_d_id = ?", profile.DID).First(&source).Error
+	if err == nil {
+		if err := s.saveProfile(&source, profile); err != nil {
+			return nil, false, fmt.Errorf("failed to update source: %w", err)
+		}
+		return &source, false, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, false, fmt.Errorf("failed to look up source: %w", err)
+	}
+
+	source = models.Source{
+		BlueSkyDID:   profile.DID,
+		QualityScore: 0.5, // Default quality score, as for followed sources
+	}
+	applyProfile(&source, profile, time.Now())
+	created, err := models.InsertSource(s.db, &source)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create source: %w", err)
+	}
+	if !created {
+		return &source, false, nil // Added by a follow import in the meantime
+	}
+
+	log.Printf("✅ Added source: %s (%s)", source.Handle, source.BlueSkyDID)
+	return &source, true, nil
+}
+
+// EnrichmentConfig holds configuration for source profile enrichment
+type EnrichmentConfig struct {
+	RefreshInterval time.Duration // How long a fetched profile stays fresh (default: 24 hours)
+	BatchSize       int           // How many sources to enrich per run (default: 100)
+	RateLimit       time.Duration // Delay between getProfiles calls of 25 sources (default: 200ms)
+}
+
+// DefaultEnrichmentConfig returns default configuration for source profile enrichment
+func DefaultEnrichmentConfig() EnrichmentConfig {
+	return EnrichmentConfig{
+		RefreshInterval: 24 * time.Hour,
+		BatchSize:       100,
+		RateLimit:       200 * time.Millisecond,
+	}
+}
+
+// GetSourcesNeedingEnrichment returns active sources that were never enriched or whose
+// profile is older than the refresh interval, oldest first
+func (s *SourceProfileService) GetSourcesNeedingEnrichment(config EnrichmentConfig, limit int) ([]models.Source, error) {
+	var sources []models.Source
+	cutoffTime := time.Now().Add(-config.RefreshInterval)
+
+	err := s.db.Where("profile_refreshed_at IS NULL OR profile_refreshed_at < ?", cutoffTime).
+		Where("is_active = ?", true).
+		Order("profile_refreshed_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&sources).Error
+
+	return sources, err
+}
+
+// EnrichBatch fetches profiles for a batch of stale sources and stores their details.
+// It returns the number of sources updated.
+func (s *SourceProfileService) EnrichBatch(config EnrichmentConfig) (int, error) {
+	sources, err := s.GetSourcesNeedingEnrichment(config, config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sources needing enrichment: %w", err)
+	}
+
+	if len(sources) == 0 {
+		return 0, nil
+	}
+
+	log.Printf("🔄 Enriching profiles for %d sources", len(sources))
+
+	enriched := 0
+	for start := 0; start < len(sources); start += bluesky.MaxProfilesPerRequest {
+		end := start + bluesky.MaxProfilesPerRequest
+		if end > len(sources) {
+			end = len(sources)
+		}
+
+		count, err := s.EnrichSources(sources[start:end])
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch profiles for %d sources: %v", end-start, err)
+		}
+		enriched += count
+
+		time.Sleep(config.RateLimit)
+	}
+
+	log.Printf("✅ Enriched %d of %d source profiles", enriched, len(sources))
+	return enriched, nil
+}
+
+// EnrichSources fetches the profiles of the given sources in as few requests as
+// possible and stores their details. It returns the number of sources updated.
+func (s *SourceProfileService) EnrichSources(sources []models.Source) (int, error) {
+	if len(sources) == 0 {
+		return 0, nil
+	}
+
+	dids := make([]string, len(sources))
+	for i, source := range sources {
+		dids[i] = source.BlueSkyDID
+	}
+
+	profiles, err := s.blueskyClient.GetProfiles(dids)
+	if err != nil {
+		return 0, err
+	}
+
+	byDID := make(map[string]*bluesky.Author, len(profiles))
+	for i := range profiles {
+		byDID[profiles[i].DID] = &profiles[i]
+	}
+
+	enriched := 0
+	for i := range sources {
+		source := &sources[i]
+		profile, ok := byDID[source.BlueSkyDID]
+		if !ok {
+			continue
+		}
+		if err := s.saveProfile(source, profile); err != nil {
+			log.Printf("❌ Failed to update source %s: %v", source.Handle, err)
+			continue
+		}
+		enriched++
+	}
+	return enriched, nil
+}
+
+// saveProfile applies a fetched profile to a source and stores it
+func (s *SourceProfileService) saveProfile(source *models.Source, profile *bluesky.Author) error {
+	applyProfile(source, profile, time.Now())
+
+	return s.db.Model(source).Updates(map[string]interface{}{
+		"handle":               source.Handle,
+		"display_name":         source.DisplayName,
+		"avatar":               source.Avatar,
+		"bio":                  source.Bio,
+		"followers_count":      source.FollowersCount,
+		"follows_count":        source.FollowsCount,
+		"posts_count":          source.PostsCount,
+		"labels":               source.Labels,
+		"profile_refreshed_at": source.ProfileRefreshedAt,
+	}).Error
+}
+
+// applyProfile copies profile details onto a source. Empty names and avatars don't
+// overwrite what follow import stored, and negated labels are left out.
+func applyProfile(source *models.Source, profile *bluesky.Author, refreshedAt time.Time) {
+	if profile.Handle != "" {
+		source.Handle = profile.Handle
+	}
+	if profile.DisplayName != "" {
+		source.DisplayName = profile.DisplayName
+	}
+	if profile.Avatar != "" {
+		source.Avatar = profile.Avatar
+	}
+	source.Bio = profile.Description
+	source.FollowersCount = profile.FollowersCount
+	source.FollowsCount = profile.FollowsCount
+	source.PostsCount = profile.PostsCount
+
+	labels := make([]string, 0, len(profile.Labels))
+	for _, label := range profile.Labels {
+		if !label.Neg && label.Val != "" {
+			labels = append(labels, label.Val)
+		}
+	}
+	source.Labels = labels
+	source.ProfileRefreshedAt = &refreshedAt
+}
+
+// followerBonus rewards sources with a large audience, up to 0.1 at 100k followers.
+// The log scale keeps the largest accounts from dominating.
+func followerBonus(followers int) float64 {
+	if followers <= 0 {
+		return 0
+	}
+	return math.Min(math.Log10(float64(followers)+1)/50.0, 0.1)
+}
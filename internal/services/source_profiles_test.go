@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/bluesky"
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProfileClient is a mock implementation of the Bluesky profile lookups
+type MockProfileClient struct {
+	mock.Mock
+}
+
+func (m *MockProfileClient) GetProfiles(actors []string) ([]bluesky.Author, error) {
+	args := m.Called(actors)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bluesky.Author), args.Error(1)
+}
+
+func TestApplyProfile(t *testing.T) {
+	now := time.Now()
+	source := &models.Source{
+		Handle:      "reporter.bsky.social",
+		DisplayName: "Reporter",
+		Avatar:      "https://example.com/avatar.jpg",
+	}
+
+	applyProfile(source, &bluesky.Author{
+		Handle:         "reporter.news",
+		Description:    "Covering city hall",
+		FollowersCount: 12000,
+		FollowsCount:   300,
+		PostsCount:     4500,
+		Labels: []bluesky.Label{
+			{Val: "politics"},
+			{Val: "spam", Neg: true},
+		},
+	}, now)
+
+	assert.Equal(t, "reporter.news", source.Handle)
+	assert.Equal(t, "Reporter", source.DisplayName, "empty display names don't overwrite")
+	assert.Equal(t, "https://example.com/avatar.jpg", source.Avatar)
+	assert.Equal(t, "Covering city hall", source.Bio)
+	assert.Equal(t, 12000, source.FollowersCount)
+	assert.Equal(t, 300, source.FollowsCount)
+	assert.Equal(t, 4500, source.PostsCount)
+	assert.Equal(t, []string{"politics"}, []string(source.Labels))
+	assert.Equal(t, &now, source.ProfileRefreshedAt)
+}
+
+func TestFollowerBonus(t *testing.T) {
+	assert.Equal(t, 0.0, followerBonus(0))
+	assert.InDelta(t, 0.06, followerBonus(999), 1e-9)
+	assert.InDelta(t, 0.1, followerBonus(99999), 1e-9)
+	assert.Equal(t, 0.1, followerBonus(5000000))
+	assert.Less(t, followerBonus(100), followerBonus(10000))
+}
+
+func TestSourceProfileService_EnrichBatch(t *testing.T) {
+	db := setupTestDB(t)
+	mockClient := &MockProfileClient{}
+	service := NewSourceProfileService(db, mockClient)
+
+	stale := time.Now().Add(-48 * time.Hour)
+	fresh := time.Now()
+	sources := []models.Source{
+		{BlueSkyDID: "did:plc:testnever", Handle: "never.bsky.social"},
+		{BlueSkyDID: "did:plc:teststale", Handle: "stale.bsky.social", ProfileRefreshedAt: &stale},
+		{BlueSkyDID: "did:plc:testfresh", Handle: "fresh.bsky.social", ProfileRefreshedAt: &fresh},
+	}
+	for i := range sources {
+		db.Create(&sources[i])
+	}
+
+	// Both stale sources are looked up in a single request
+	mockClient.On("GetProfiles", []string{"did:plc:testnever", "did:plc:teststale"}).Return([]bluesky.Author{
+		{DID: "did:plc:testnever", Handle: "never.bsky.social", FollowersCount: 10},
+		{DID: "did:plc:teststale", Handle: "stale.bsky.social", FollowersCount: 20},
+	}, nil)
+
+	config := DefaultEnrichmentConfig()
+	config.RateLimit = 0
+	enriched, err := service.EnrichBatch(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, enriched)
+
+	var updated models.Source
+	db.Where("blue_sky_d_id = ?", "did:plc:teststale").First(&updated)
+	assert.Equal(t, 20, updated.FollowersCount)
+	assert.NotNil(t, updated.ProfileRefreshedAt)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestSourceProfileService_AddSource(t *testing.T) {
+	db := setupTestDB(t)
+	mockClient := &MockProfileClient{}
+	service := NewSourceProfileService(db, mockClient)
+
+	profile := bluesky.Author{DID: "did:plc:testadded", Handle: "added.bsky.social", DisplayName: "Added", FollowersCount: 42}
+	mockClient.On("GetProfiles", []string{"added.bsky.social"}).Return([]bluesky.Author{profile}, nil)
+	mockClient.On("GetProfiles", []string{"did:plc:testadded"}).Return([]bluesky.Author{profile}, nil)
+	mockClient.On("GetProfiles", []string{"missing.bsky.social"}).Return([]bluesky.Author{}, nil)
+
+	source, created, err := service.AddSource("@added.bsky.social")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "did:plc:testadded", source.BlueSkyDID)
+	assert.Equal(t, 42, source.FollowersCount)
+	assert.Equal(t, 0.5, source.QualityScore)
+	assert.NotNil(t, source.ProfileRefreshedAt)
+
+	again, created, err := service.AddSource("did:plc:testadded")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, source.ID, again.ID)
+
+	_, _, err = service.AddSource("missing.bsky.social")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+
+	_, _, err = service.AddSource("  ")
+	assert.Error(t, err)
+}
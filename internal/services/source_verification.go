@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ways a source can be matched to a news site
+const (
+	VerificationMethodDomainHandle    = "domain_handle"     // The handle is the domain of a site the source shares, e.g. reuters.com
+	VerificationMethodPublisherSameAs = "publisher_same_as" // A shared site's JSON-LD publisher lists the account in sameAs
+)
+
+// verificationSampleSize is how many of a source's most recent shares are checked
+const verificationSampleSize = 200
+
+// VerificationMatch is the evidence that a source speaks for a news site
+type VerificationMatch struct {
+	Method string `json:"method"`
+	Domain string `json:"domain"`
+}
+
+// VerificationConfig holds configuration for automatic source verification
+type VerificationConfig struct {
+	RefreshInterval time.Duration // How long before an unverified source is checked again (default: 7 days)
+	BatchSize       int           // How many sources to check per run (default: 100)
+}
+
+// DefaultVerificationConfig returns default configuration for source verification
+func DefaultVerificationConfig() VerificationConfig {
+	return VerificationConfig{
+		RefreshInterval: 7 * 24 * time.Hour,
+		BatchSize:       100,
+	}
+}
+
+// SourceVerificationService verifies sources that speak for the news sites they
+// share, for an admin to review
+type SourceVerificationService struct {
+	db *gorm.DB
+}
+
+// NewSourceVerificationService creates a new SourceVerificationService
+func NewSourceVerificationService(db *gorm.DB) *SourceVerificationService {
+	return &SourceVerificationService{db: db}
+}
+
+// WithContext returns a verification service running its queries with ctx
+func (s *SourceVerificationService) WithContext(ctx context.Context) *SourceVerificationService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Check looks for evidence in the source's recent shares that it's the account of
+// a news site: a domain handle matching the site, or the site's JSON-LD publisher
+// listing the account in sameAs. It returns nil without a match.
+func (s *SourceVerificationService) Check(source *models.Source) (*VerificationMatch, error) {
+	var shared []struct {
+		URL        string
+		JSONLDData string
+	}
+	err := s.db.Table("source_articles").
+		Select("articles.url, articles.jsonld_data").
+		Joins("JOIN articles ON articles.id = source_articles.article_id").
+		Where("source_articles.source_id = ?", source.ID).
+		Order("source_articles.posted_at DESC").
+		Limit(verificationSampleSize).
+		Scan(&shared).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared articles: %w", err)
+	}
+
+	handle := strings.ToLower(source.Handle)
+	var sameAsMatch *VerificationMatch
+	for _, article := range shared {
+		host := articleHost(article.URL)
+		if host == "" {
+			continue
+		}
+		if isDomainHandle(handle) && (host == handle || strings.HasSuffix(host, "."+handle)) {
+			return &VerificationMatch{Method: VerificationMethodDomainHandle, Domain: handle}, nil
+		}
+		if sameAsMatch != nil {
+			continue
+		}
+		for _, link := range publisherSameAs(article.JSONLDData) {
+			if actor := profileActor(link); actor != "" && (actor == handle || actor == source.BlueSkyDID) {
+				sameAsMatch = &VerificationMatch{Method: VerificationMethodPublisherSameAs, Domain: host}
+				break
+			}
+		}
+	}
+	return sameAsMatch, nil
+}
+
+// Verify checks a source and records the result. A match verifies the source and
+// leaves it pending admin review; a pending source that no longer matches, e.g.
+// after a handle change, is unverified again. Sources an admin has reviewed, or
+// verified by hand, keep their verification.
+func (s *SourceVerificationService) Verify(source *models.Source) (*VerificationMatch, error) {
+	if source.VerificationStatus == models.VerificationApproved || source.VerificationStatus == models.VerificationRejected {
+		return nil, nil
+	}
+
+	match, err := s.Check(source)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source.VerificationCheckedAt = &now
+	switch {
+	case match != nil:
+		if !source.IsVerified || source.VerifiedDomain != match.Domain {
+			log.Printf("✅ Verified source %s as %s (%s), pending review", source.Handle, match.Domain, match.Method)
+		}
+		source.IsVerified = true
+		source.VerificationStatus = models.VerificationPending
+		source.VerificationMethod = match.Method
+		source.VerifiedDomain = match.Domain
+	case source.VerificationStatus == models.VerificationPending:
+		log.Printf("⚠️  Source %s no longer matches %s, unverified", source.Handle, source.VerifiedDomain)
+		source.IsVerified = false
+		source.VerificationStatus = ""
+		source.VerificationMethod = ""
+		source.VerifiedDomain = ""
+	}
+
+	err = s.db.Model(source).
+		Select("is_verified", "verification_status", "verification_method", "verified_domain", "verification_checked_at").
+		Updates(source).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update source verification: %w", err)
+	}
+	return match, nil
+}
+
+// VerifyBatch checks active sources that haven't been reviewed and weren't checked
+// within the refresh interval. It returns the number of sources verified.
+func (s *SourceVerificationService) VerifyBatch(config VerificationConfig) (int, error) {
+	var sources []models.Source
+	err := s.db.Where("verification_checked_at IS NULL OR verification_checked_at < ?", time.Now().Add(-config.RefreshInterval)).
+		Where("is_active = ?", true).
+		Where("verification_status IS NULL OR verification_status NOT IN ?", []string{models.VerificationApproved, models.VerificationRejected}).
+		Order("verification_checked_at ASC NULLS FIRST").
+		Limit(config.BatchSize).
+		Find(&sources).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sources to verify: %w", err)
+	}
+
+	verified := 0
+	for i := range sources {
+		match, err := s.Verify(&sources[i])
+		if err != nil {
+			return verified, err
+		}
+		if match != nil {
+			verified++
+		}
+	}
+	if len(sources) > 0 {
+		log.Printf("🔎 Checked %d sources for verification, %d verified", len(sources), verified)
+	}
+	return verified, nil
+}
+
+// PendingReview returns the automatically verified sources waiting for an admin,
+// oldest check first
+func (s *SourceVerificationService) PendingReview(limit int) ([]models.Source, error) {
+	var sources []models.Source
+	err := s.db.Where("verification_status = ?", models.VerificationPending).
+		Order("verification_checked_at ASC").
+		Limit(limit).
+		Find(&sources).Error
+	return sources, err
+}
+
+// Review records an admin's decision on a source's verification. Approving
+// verifies the source for good; rejecting unverifies it and stops automatic checks.
+func (s *SourceVerificationService) Review(sourceID uuid.UUID, approve bool) (*models.Source, error) {
+	var source models.Source
+	if err := s.db.Where("id = ?", sourceID).First(&source).Error; err != nil {
+		return nil, err
+	}
+
+	status := models.VerificationRejected
+	if approve {
+		status = models.VerificationApproved
+	}
+	if err := s.db.Model(&source).Updates(map[string]interface{}{
+		"is_verified":         approve,
+		"verification_status": status,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to review source verification: %w", err)
+	}
+	source.IsVerified, source.VerificationStatus = approve, status
+	return &source, nil
+}
+
+// isDomainHandle reports whether a handle is a custom domain rather than one
+// issued by a hosting provider such as bsky.social
+func isDomainHandle(handle string) bool {
+	return strings.Contains(handle, ".") && !strings.HasSuffix(handle, ".bsky.social")
+}
+
+// articleHost returns the lowercased host of an article URL without "www."
+func articleHost(articleURL string) string {
+	parsed, err := url.Parse(articleURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// profileActor returns the handle or DID a sameAs link points at, for
+// https://bsky.app/profile/<actor> and at://<actor> links
+func profileActor(link string) string {
+	if rest, ok := strings.CutPrefix(link, "at://"); ok {
+		actor, _, _ := strings.Cut(rest, "/")
+		return strings.ToLower(actor)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || strings.TrimPrefix(parsed.Hostname(), "www.") != "bsky.app" {
+		return ""
+	}
+	rest, ok := strings.CutPrefix(parsed.Path, "/profile/")
+	if !ok {
+		return ""
+	}
+	actor, _, _ := strings.Cut(rest, "/")
+	return strings.ToLower(actor)
+}
+
+// publisherSameAs returns the sameAs links of the publishers and organizations
+// in a JSON-LD document
+func publisherSameAs(jsonld string) []string {
+	if jsonld == "" {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonld), &data); err != nil {
+		return nil
+	}
+
+	var links []string
+	var walk func(item interface{}, organization bool)
+	walk = func(item interface{}, organization bool) {
+		switch v := item.(type) {
+		case []interface{}:
+			for _, sub := range v {
+				walk(sub, organization)
+			}
+		case map[string]interface{}:
+			if organization || isOrganizationType(v["@type"]) {
+				links = append(links, stringValues(v["sameAs"])...)
+			}
+			if publisher, ok := v["publisher"]; ok {
+				walk(publisher, true)
+			}
+			if graph, ok := v["@graph"]; ok {
+				walk(graph, false)
+			}
+		}
+	}
+	walk(data, false)
+	return links
+}
+
+// isOrganizationType reports whether a JSON-LD @type names an organization
+func isOrganizationType(value interface{}) bool {
+	for _, t := range stringValues(value) {
+		if t == "Organization" || t == "NewsMediaOrganization" {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValues returns a JSON-LD value that may be a string or a list of strings
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisherSameAs(t *testing.T) {
+	article := `{"@type":"NewsArticle","publisher":{"@type":"Organization","name":"Example","sameAs":["https://bsky.app/profile/example.com","https://twitter.com/example"]}}`
+	assert.Equal(t, []string{"https://bsky.app/profile/example.com", "https://twitter.com/example"}, publisherSameAs(article))
+
+	graph := `{"@graph":[{"@type":"NewsArticle","publisher":{"@id":"#org"}},{"@id":"#org","@type":["NewsMediaOrganization"],"sameAs":"at://did:plc:example"}]}`
+	assert.Equal(t, []string{"at://did:plc:example"}, publisherSameAs(graph))
+
+	assert.Empty(t, publisherSameAs(`{"@type":"Person","sameAs":"https://bsky.app/profile/someone.com"}`), "only publishers and organizations count")
+	assert.Empty(t, publisherSameAs("not json"))
+}
+
+func TestProfileActor(t *testing.T) {
+	assert.Equal(t, "example.com", profileActor("https://bsky.app/profile/Example.com"))
+	assert.Equal(t, "did:plc:example", profileActor("https://bsky.app/profile/did:plc:example/post/abc"))
+	assert.Equal(t, "did:plc:example", profileActor("at://did:plc:example"))
+	assert.Empty(t, profileActor("https://twitter.com/example"))
+	assert.Empty(t, profileActor("https://bsky.app/search"))
+}
+
+func TestSourceVerification(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSourceVerificationService(db)
+
+	share := func(source models.Source, url, jsonld string) {
+		article := models.Article{URL: url, Title: "Story", JSONLDData: jsonld}
+		require.NoError(t, db.Create(&article).Error)
+		require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: "at://" + source.BlueSkyDID + "/app.bsky.feed.post/" + article.ID.String(), PostedAt: time.Now()}).Error)
+	}
+
+	domain := models.Source{BlueSkyDID: "did:plc:testverifydomain", Handle: "verify-example.com", IsActive: true}
+	require.NoError(t, db.Create(&domain).Error)
+	share(domain, "https://www.verify-example.com/story", "")
+
+	sameAs := models.Source{BlueSkyDID: "did:plc:testverifysameas", Handle: "newsroom.bsky.social", IsActive: true}
+	require.NoError(t, db.Create(&sameAs).Error)
+	share(sameAs, "https://daily.example.org/story", `{"@type":"NewsArticle","publisher":{"sameAs":"https://bsky.app/profile/did:plc:testverifysameas"}}`)
+
+	other := models.Source{BlueSkyDID: "did:plc:testverifyother", Handle: "reader.bsky.social", IsActive: true}
+	require.NoError(t, db.Create(&other).Error)
+	share(other, "https://www.verify-example.com/other", "")
+
+	match, err := service.Verify(&domain)
+	require.NoError(t, err)
+	assert.Equal(t, &VerificationMatch{Method: VerificationMethodDomainHandle, Domain: "verify-example.com"}, match)
+
+	match, err = service.Verify(&sameAs)
+	require.NoError(t, err)
+	assert.Equal(t, &VerificationMatch{Method: VerificationMethodPublisherSameAs, Domain: "daily.example.org"}, match)
+
+	match, err = service.Verify(&other)
+	require.NoError(t, err)
+	assert.Nil(t, match)
+
+	var stored models.Source
+	require.NoError(t, db.First(&stored, "id = ?", domain.ID).Error)
+	assert.True(t, stored.IsVerified)
+	assert.Equal(t, models.VerificationPending, stored.VerificationStatus)
+	assert.NotNil(t, stored.VerificationCheckedAt)
+
+	pending, err := service.PendingReview(10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	// A rejected source stays unverified when it's checked again
+	rejected, err := service.Review(domain.ID, false)
+	require.NoError(t, err)
+	assert.False(t, rejected.IsVerified)
+	match, err = service.Verify(rejected)
+	require.NoError(t, err)
+	assert.Nil(t, match)
+	require.NoError(t, db.First(&stored, "id = ?", domain.ID).Error)
+	assert.False(t, stored.IsVerified)
+	assert.Equal(t, models.VerificationRejected, stored.VerificationStatus)
+
+	// A pending source that no longer matches is unverified
+	require.NoError(t, db.Model(&sameAs).Update("blue_sky_d_id", "did:plc:testverifymoved").Error)
+	sameAs.BlueSkyDID = "did:plc:testverifymoved"
+	match, err = service.Verify(&sameAs)
+	require.NoError(t, err)
+	assert.Nil(t, match)
+	assert.False(t, sameAs.IsVerified)
+	assert.Empty(t, sameAs.VerificationStatus)
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// SpamPost is a link post or repost by a source
+type SpamPost struct {
+	Text     string
+	URL      string
+	IsRepost bool
+	PostedAt time.Time
+}
+
+// SpamActivity is what a source shared during the checked window, oldest first
+type SpamActivity struct {
+	Source models.Source
+	Posts  []SpamPost
+}
+
+// SpamClassifier flags sources from their recent activity. The built-in heuristics
+// are classifiers; others, such as a call to an external model, are plugged in
+// with SpamService.AddClassifier.
+type SpamClassifier interface {
+	// Classify returns why the activity looks like spam, or nothing when it doesn't
+	Classify(activity SpamActivity) []string
+}
+
+// SpamClassifierFunc adapts a function to the SpamClassifier interface
+type SpamClassifierFunc func(activity SpamActivity) []string
+
+// Classify calls f(activity)
+func (f SpamClassifierFunc) Classify(activity SpamActivity) []string {
+	return f(activity)
+}
+
+// SpamConfig holds the thresholds of the built-in spam heuristics
+type SpamConfig struct {
+	Window          time.Duration // How far back activity is checked (default: 24 hours)
+	MaxLinksPerHour int           // Links in any hour that flag a source (default: 30, SPAM_MAX_LINKS_PER_HOUR)
+	MinDomainPosts  int           // Posts needed before domain concentration is checked (default: 10)
+	MaxDomainShare  float64       // Share of posts linking one domain that flags a source (default: 0.8)
+	MinBaitPosts    int           // Engagement-bait posts that flag a source (default: 3)
+}
+
+// defaultMaxLinksPerHour is the link rate that flags a source without SPAM_MAX_LINKS_PER_HOUR
+const defaultMaxLinksPerHour = 30
+
+// DefaultSpamConfig returns the default spam thresholds, reading SPAM_MAX_LINKS_PER_HOUR
+func DefaultSpamConfig() SpamConfig {
+	config := SpamConfig{
+		Window:          24 * time.Hour,
+		MaxLinksPerHour: defaultMaxLinksPerHour,
+		MinDomainPosts:  10,
+		MaxDomainShare:  0.8,
+		MinBaitPosts:    3,
+	}
+	if value := os.Getenv("SPAM_MAX_LINKS_PER_HOUR"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			config.MaxLinksPerHour = limit
+		} else {
+			log.Printf("Invalid SPAM_MAX_LINKS_PER_HOUR %q, using %d", value, defaultMaxLinksPerHour)
+		}
+	}
+	return config
+}
+
+// SpamService flags sources that post links at spam rates, push a single domain
+// or use engagement bait, and queues them for admin review
+type SpamService struct {
+	db          *gorm.DB
+	config      SpamConfig
+	classifiers []SpamClassifier
+}
+
+// NewSpamService creates a spam service with the built-in heuristics
+func NewSpamService(db *gorm.DB) *SpamService {
+	config := DefaultSpamConfig()
+	return &SpamService{
+		db:     db,
+		config: config,
+		classifiers: []SpamClassifier{
+			linkRateClassifier(config),
+			domainClassifier(config),
+			baitClassifier(config),
+		},
+	}
+}
+
+// WithContext returns a spam service running its queries with ctx
+func (s *SpamService) WithContext(ctx context.Context) *SpamService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// AddClassifier adds a classifier that runs after the built-in heuristics
+func (s *SpamService) AddClassifier(classifier SpamClassifier) {
+	s.classifiers = append(s.classifiers, classifier)
+}
+
+// Classify runs every classifier and returns the reasons they gave
+func (s *SpamService) Classify(activity SpamActivity) []string {
+	var reasons []string
+	for _, classifier := range s.classifiers {
+		reasons = append(reasons, classifier.Classify(activity)...)
+	}
+	return reasons
+}
+
+// Activity loads what a source shared within the window. Posts from before an
+// admin last reviewed the source are left out.
+func (s *SpamService) Activity(source models.Source) (SpamActivity, error) {
+	since := time.Now().Add(-s.config.Window)
+	if source.SpamReviewedAt != nil && source.SpamReviewedAt.After(since) {
+		since = *source.SpamReviewedAt
+	}
+
+	activity := SpamActivity{Source: source}
+	err := s.db.Table("source_articles").
+		Select("source_articles.post_text AS text, articles.url AS url, source_articles.is_repost, source_articles.posted_at").
+		Joins("JOIN articles ON articles.id = source_articles.article_id").
+		Where("source_articles.source_id = ? AND source_articles.posted_at > ?", source.ID, since).
+		Order("source_articles.posted_at ASC").
+		Scan(&activity.Posts).Error
+	if err != nil {
+		return activity, fmt.Errorf("failed to load activity for %s: %w", source.Handle, err)
+	}
+	return activity, nil
+}
+
+// Check classifies a source's recent activity and flags it when a classifier
+// gives a reason. Flagged and confirmed sources aren't checked again; cleared
+// sources are, on what they posted since.
+func (s *SpamService) Check(source *models.Source) ([]string, error) {
+	if source.SpamStatus == models.SpamFlagged || source.SpamStatus == models.SpamConfirmed {
+		return nil, nil
+	}
+
+	activity, err := s.Activity(*source)
+	if err != nil {
+		return nil, err
+	}
+	reasons := s.Classify(activity)
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	err = s.db.Model(source).Updates(map[string]interface{}{
+		"spam_status":     models.SpamFlagged,
+		"spam_reasons":    pq.StringArray(reasons),
+		"spam_flagged_at": now,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag source %s: %w", source.Handle, err)
+	}
+	source.SpamStatus, source.SpamReasons, source.SpamFlaggedAt = models.SpamFlagged, reasons, &now
+
+	log.Printf("🚩 Flagged source %s for review: %s", source.Handle, strings.Join(reasons, "; "))
+	return reasons, nil
+}
+
+// CheckRecent checks the sources that shared links within the window and returns
+// how many were flagged
+func (s *SpamService) CheckRecent() (int, error) {
+	var sources []models.Source
+	err := s.db.Where("id IN (?)", s.db.Table("source_articles").
+		Select("source_id").
+		Where("posted_at > ?", time.Now().Add(-s.config.Window))).
+		Where("spam_status IS NULL OR spam_status NOT IN ?", []string{models.SpamFlagged, models.SpamConfirmed}).
+		Find(&sources).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recently active sources: %w", err)
+	}
+
+	flagged := 0
+	for i := range sources {
+		reasons, err := s.Check(&sources[i])
+		if err != nil {
+			return flagged, err
+		}
+		if len(reasons) > 0 {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// Flagged returns the sources waiting for spam review, most recently flagged first
+func (s *SpamService) Flagged(limit int) ([]models.Source, error) {
+	var sources []models.Source
+	err := s.db.Where("spam_status = ?", models.SpamFlagged).
+		Order("spam_flagged_at DESC").
+		Limit(limit).
+		Find(&sources).Error
+	return sources, err
+}
+
+// Review records an admin's decision on a flagged source. Confirmed spam stays
+// downranked; a cleared source loses its flag and is only checked on new posts.
+func (s *SpamService) Review(sourceID uuid.UUID, spam bool) (*models.Source, error) {
+	var source models.Source
+	if err := s.db.Where("id = ?", sourceID).First(&source).Error; err != nil {
+		return nil, err
+	}
+
+	status := models.SpamCleared
+	if spam {
+		status = models.SpamConfirmed
+	}
+	now := time.Now()
+	if err := s.db.Model(&source).Updates(map[string]interface{}{
+		"spam_status":      status,
+		"spam_reviewed_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to review source: %w", err)
+	}
+	source.SpamStatus, source.SpamReviewedAt = status, &now
+	return &source, nil
+}
+
+// linkRateClassifier flags sources that share more than MaxLinksPerHour links in any hour
+func linkRateClassifier(config SpamConfig) SpamClassifier {
+	return SpamClassifierFunc(func(activity SpamActivity) []string {
+		peak, start := 0, 0
+		for end, post := range activity.Posts {
+			for post.PostedAt.Sub(activity.Posts[start].PostedAt) >= time.Hour {
+				start++
+			}
+			if count := end - start + 1; count > peak {
+				peak = count
+			}
+		}
+		if peak > config.MaxLinksPerHour {
+			return []string{fmt.Sprintf("posted %d links in an hour", peak)}
+		}
+		return nil
+	})
+}
+
+// domainClassifier flags sources whose posts mostly link to a single domain, other
+// than the site the source was verified for
+func domainClassifier(config SpamConfig) SpamClassifier {
+	return SpamClassifierFunc(func(activity SpamActivity) []string {
+		if len(activity.Posts) < config.MinDomainPosts {
+			return nil
+		}
+		counts := make(map[string]int)
+		for _, post := range activity.Posts {
+			if host := articleHost(post.URL); host != "" {
+				counts[host]++
+			}
+		}
+		domains := make([]string, 0, len(counts))
+		for domain := range counts {
+			domains = append(domains, domain)
+		}
+		sort.Slice(domains, func(i, j int) bool { return counts[domains[i]] > counts[domains[j]] })
+
+		if len(domains) == 0 || domains[0] == activity.Source.VerifiedDomain {
+			return nil
+		}
+		top := domains[0]
+		if share := float64(counts[top]) / float64(len(activity.Posts)); share >= config.MaxDomainShare {
+			return []string{fmt.Sprintf("%d of %d posts link to %s", counts[top], len(activity.Posts), top)}
+		}
+		return nil
+	})
+}
+
+// baitPattern matches phrases that ask for engagement rather than offer news
+var baitPattern = regexp.MustCompile(`(?i)\b(like (and|&|\+) (repost|share|follow)|repost (this )?if|like (this )?if|rt if|follow (me )?for more|share (this )?if|smash (that|the) like|tag (a|someone|your) friend|you won'?t believe|what happens next|comment below|drop a like)\b`)
+
+// baitClassifier flags sources whose own posts repeatedly use engagement bait
+func baitClassifier(config SpamConfig) SpamClassifier {
+	return SpamClassifierFunc(func(activity SpamActivity) []string {
+		bait := 0
+		for _, post := range activity.Posts {
+			if !post.IsRepost && baitPattern.MatchString(post.Text) {
+				bait++
+			}
+		}
+		if bait >= config.MinBaitPosts {
+			return []string{fmt.Sprintf("%d posts use engagement bait", bait)}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpamClassifiers(t *testing.T) {
+	config := DefaultSpamConfig()
+	start := time.Now().Add(-3 * time.Hour)
+	posts := func(n int, every time.Duration, url func(i int) string, text string) []SpamPost {
+		var list []SpamPost
+		for i := 0; i < n; i++ {
+			list = append(list, SpamPost{Text: text, URL: url(i), PostedAt: start.Add(time.Duration(i) * every)})
+		}
+		return list
+	}
+	spread := func(i int) string { return fmt.Sprintf("https://site%d.example/story", i) }
+	same := func(i int) string { return fmt.Sprintf("https://www.pushed.example/story/%d", i) }
+
+	// Link rate
+	assert.NotEmpty(t, linkRateClassifier(config).Classify(SpamActivity{Posts: posts(40, time.Minute, spread, "")}))
+	assert.Empty(t, linkRateClassifier(config).Classify(SpamActivity{Posts: posts(40, 5*time.Minute, spread, "")}), "40 links over more than three hours is fine")
+
+	// Domain concentration, unless it's the source's own verified site
+	assert.Equal(t, []string{"12 of 12 posts link to pushed.example"}, domainClassifier(config).Classify(SpamActivity{Posts: posts(12, time.Minute, same, "")}))
+	assert.Empty(t, domainClassifier(config).Classify(SpamActivity{Posts: posts(12, time.Minute, spread, "")}))
+	assert.Empty(t, domainClassifier(config).Classify(SpamActivity{Posts: posts(5, time.Minute, same, "")}), "too few posts to judge")
+	verified := SpamActivity{Source: models.Source{VerifiedDomain: "pushed.example"}, Posts: posts(12, time.Minute, same, "")}
+	assert.Empty(t, domainClassifier(config).Classify(verified))
+
+	// Engagement bait in the source's own posts
+	assert.NotEmpty(t, baitClassifier(config).Classify(SpamActivity{Posts: posts(3, time.Minute, spread, "Like & repost if you agree!")}))
+	assert.Empty(t, baitClassifier(config).Classify(SpamActivity{Posts: posts(3, time.Minute, spread, "Parliament passes the budget")}))
+	reposts := posts(3, time.Minute, spread, "RT if you agree")
+	for i := range reposts {
+		reposts[i].IsRepost = true
+	}
+	assert.Empty(t, baitClassifier(config).Classify(SpamActivity{Posts: reposts}), "reposted text isn't the source's")
+}
+
+func TestSpamService_AddClassifier(t *testing.T) {
+	service := NewSpamService(nil)
+	assert.Empty(t, service.Classify(SpamActivity{}))
+
+	service.AddClassifier(SpamClassifierFunc(func(activity SpamActivity) []string {
+		return []string{"external model says spam"}
+	}))
+	assert.Equal(t, []string{"external model says spam"}, service.Classify(SpamActivity{}))
+}
+
+func TestSpamService_CheckAndReview(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSpamService(db)
+
+	source := models.Source{BlueSkyDID: "did:plc:testspambait", Handle: "bait.bsky.social", IsActive: true}
+	require.NoError(t, db.Create(&source).Error)
+	for i := 0; i < 3; i++ {
+		article := models.Article{URL: fmt.Sprintf("https://site%d.example/story", i), Title: "Story"}
+		require.NoError(t, db.Create(&article).Error)
+		require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: "at://did:plc:testspambait/app.bsky.feed.post/" + article.ID.String(), PostText: "You won't believe what happens next", PostedAt: time.Now().Add(-time.Minute)}).Error)
+	}
+
+	flagged, err := service.CheckRecent()
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged)
+
+	queue, err := service.Flagged(10)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, source.ID, queue[0].ID)
+	assert.True(t, queue[0].IsSpam())
+	assert.NotEmpty(t, queue[0].SpamReasons)
+
+	// A cleared source is only judged on what it posts afterwards
+	cleared, err := service.Review(source.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.SpamCleared, cleared.SpamStatus)
+	reasons, err := service.Check(cleared)
+	require.NoError(t, err)
+	assert.Empty(t, reasons)
+
+	confirmed, err := service.Review(source.ID, true)
+	require.NoError(t, err)
+	assert.True(t, confirmed.IsSpam())
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+	"open-news/internal/summary"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// summaryBatchSize is how many articles a summary job processes
+const summaryBatchSize = 50
+
+// SummarizeArticlesPayload is the payload of a summary job. Without an article,
+// the job summarizes a batch of articles that haven't been summarized yet.
+type SummarizeArticlesPayload struct {
+	ArticleID *uuid.UUID `json:"article_id,omitempty"`
+}
+
+// SummaryService stores the summaries a summarizer writes of article text
+type SummaryService struct {
+	db         *gorm.DB
+	summarizer summary.Summarizer
+}
+
+// NewSummaryService creates a summary service using summarizer, which may be nil
+// when summaries are turned off
+func NewSummaryService(db *gorm.DB, summarizer summary.Summarizer) *SummaryService {
+	return &SummaryService{db: db, summarizer: summarizer}
+}
+
+// WithContext returns a summary service running its queries with ctx
+func (s *SummaryService) WithContext(ctx context.Context) *SummaryService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Enabled reports whether a summarizer is configured
+func (s *SummaryService) Enabled() bool {
+	return s.summarizer != nil
+}
+
+// Summarize replaces an article's summary with one the summarizer writes now.
+// Articles with too little text are marked summarized with an empty summary,
+// so feeds keep showing their description.
+func (s *SummaryService) Summarize(ctx context.Context, article *models.Article) (string, error) {
+	if !s.Enabled() {
+		return "", fmt.Errorf("summaries are turned off")
+	}
+	text, err := s.summarizer.Summarize(ctx, summary.Input{
+		Title:       article.Title,
+		Description: article.Description,
+		Text:        article.TextContent,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize %s: %w", article.URL, err)
+	}
+
+	now := time.Now()
+	err = s.db.Model(article).UpdateColumns(map[string]interface{}{
+		"summary":       text,
+		"summarized_at": now,
+	}).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to store summary for %s: %w", article.URL, err)
+	}
+	article.Summary = text
+	article.SummarizedAt = &now
+	return text, nil
+}
+
+// SummarizeArticle summarizes a single article
+func (s *SummaryService) SummarizeArticle(ctx context.Context, articleID uuid.UUID) (string, error) {
+	var article models.Article
+	if err := s.db.First(&article, "id = ?", articleID).Error; err != nil {
+		return "", err
+	}
+	return s.Summarize(ctx, &article)
+}
+
+// SummarizePending summarizes up to limit of the newest fetched articles that
+// haven't been summarized, and returns how many were processed. Articles that
+// fail are logged and retried on the next run.
+func (s *SummaryService) SummarizePending(ctx context.Context, limit int) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	var articles []models.Article
+	err := s.db.Where("summarized_at IS NULL AND is_not_news = ? AND text_content <> ''", false).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get articles without summaries: %w", err)
+	}
+
+	processed := 0
+	for i := range articles {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+		if _, err := s.Summarize(ctx, &articles[i]); err != nil {
+			log.Printf("❌ %v", err)
+			continue
+		}
+		processed++
+	}
+	if processed > 0 {
+		log.Printf("📝 Summarized %d articles with %s", processed, s.summarizer.Name())
+	}
+	return processed, nil
+}
+
+// RunJob handles a summary job
+func (s *SummaryService) RunJob(ctx context.Context, payload SummarizeArticlesPayload) error {
+	if payload.ArticleID != nil {
+		_, err := s.SummarizeArticle(ctx, *payload.ArticleID)
+		return err
+	}
+	_, err := s.SummarizePending(ctx, summaryBatchSize)
+	return err
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"open-news/internal/models"
+	"open-news/internal/summary"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryService(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSummaryService(db, summary.NewExtractiveSummarizer())
+
+	article := models.Article{
+		URL:         "https://example.com/ecb-summary",
+		Title:       "ECB raises rates",
+		Description: "The meta description",
+		TextContent: "The European Central Bank raised interest rates by a quarter point on Thursday. " +
+			"President Christine Lagarde said rates would stay high until inflation returns to target.",
+	}
+	short := models.Article{URL: "https://example.com/short", Title: "Short", Description: "Short description", TextContent: "Too short."}
+	require.NoError(t, db.Create(&article).Error)
+	require.NoError(t, db.Create(&short).Error)
+
+	processed, err := service.SummarizePending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+
+	require.NoError(t, db.First(&article, "id = ?", article.ID).Error)
+	assert.Contains(t, article.Summary, "Christine Lagarde")
+	assert.NotNil(t, article.SummarizedAt)
+	assert.Equal(t, article.Summary, article.Blurb())
+
+	require.NoError(t, db.First(&short, "id = ?", short.ID).Error)
+	assert.Empty(t, short.Summary)
+	assert.NotNil(t, short.SummarizedAt, "short articles aren't tried again")
+	assert.Equal(t, "Short description", short.Blurb())
+
+	processed, err = service.SummarizePending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+}
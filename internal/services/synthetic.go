@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DomainWeight is a site synthetic articles come from, and how often relative
+// to the other sites
+type DomainWeight struct {
+	Domain   string
+	SiteName string
+	Weight   float64
+}
+
+// defaultSyntheticDomains are the sites synthetic articles come from unless
+// configured otherwise: a few large publishers and a long tail
+var defaultSyntheticDomains = []DomainWeight{
+	{Domain: "reuters.com", SiteName: "Reuters", Weight: 5},
+	{Domain: "apnews.com", SiteName: "AP News", Weight: 5},
+	{Domain: "bbc.com", SiteName: "BBC News", Weight: 4},
+	{Domain: "theguardian.com", SiteName: "The Guardian", Weight: 3},
+	{Domain: "nytimes.com", SiteName: "The New York Times", Weight: 3},
+	{Domain: "techcrunch.com", SiteName: "TechCrunch", Weight: 2},
+	{Domain: "nature.com", SiteName: "Nature", Weight: 1},
+	{Domain: "economist.com", SiteName: "The Economist", Weight: 1},
+	{Domain: "localnews.example", SiteName: "Local News", Weight: 1},
+	{Domain: "blog.example", SiteName: "Example Blog", Weight: 0.5},
+}
+
+// SyntheticConfig controls the volume and shape of generated articles
+type SyntheticConfig struct {
+	Articles      int            // How many articles to generate
+	Seed          int64          // Generates the same articles for the same seed; 0 for a random seed
+	Domains       []DomainWeight // Sites to draw articles from (default: a mix of publishers)
+	QualityMean   float64        // Average quality score (default: 0.7)
+	QualitySpread float64        // Standard deviation of quality scores (default: 0.15)
+	TimeSpread    time.Duration  // Articles are published within this long before now, most of them recently (default: 7 days)
+	MaxShares     int            // Most sources sharing one article; most articles get one or two (default: 5)
+}
+
+// DefaultSyntheticConfig returns the settings used for the mock articles seeded
+// when no real ones can be imported
+func DefaultSyntheticConfig(articles int) SyntheticConfig {
+	return SyntheticConfig{Articles: articles}.withDefaults()
+}
+
+// withDefaults fills in the settings left at zero
+func (config SyntheticConfig) withDefaults() SyntheticConfig {
+	if len(config.Domains) == 0 {
+		config.Domains = defaultSyntheticDomains
+	}
+	if config.QualityMean == 0 {
+		config.QualityMean = 0.7
+	}
+	if config.QualitySpread == 0 {
+		config.QualitySpread = 0.15
+	}
+	if config.TimeSpread == 0 {
+		config.TimeSpread = 7 * 24 * time.Hour
+	}
+	if config.MaxShares == 0 {
+		config.MaxShares = 5
+	}
+	return config
+}
+
+// ParseDomainWeights parses a comma separated list of domain:weight pairs, such
+// as "reuters.com:3,example.com:1". A domain without a weight counts once.
+func ParseDomainWeights(value string) ([]DomainWeight, error) {
+	var weights []DomainWeight
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		domain, weightText, hasWeight := strings.Cut(part, ":")
+		weight := 1.0
+		if hasWeight {
+			parsed, err := strconv.ParseFloat(weightText, 64)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid weight %q for %s", weightText, domain)
+			}
+			weight = parsed
+		}
+		weights = append(weights, DomainWeight{Domain: domain, SiteName: siteNameFor(domain), Weight: weight})
+	}
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("no domains in %q", value)
+	}
+	return weights, nil
+}
+
+// siteNameFor returns the name of a default domain, or the domain itself
+func siteNameFor(domain string) string {
+	for _, known := range defaultSyntheticDomains {
+		if known.Domain == domain {
+			return known.SiteName
+		}
+	}
+	return domain
+}
+
+// syntheticTopic is the vocabulary of a topic's generated headlines
+type syntheticTopic struct {
+	slug     string
+	subjects []string
+	actions  []string
+	objects  []string
+}
+
+var syntheticTopics = []syntheticTopic{
+	{"politics", []string{"Senate", "Parliament", "City council", "Governor", "Prime minister", "Election officials"},
+		[]string{"approves", "rejects", "debates", "delays", "unveils"}, []string{"budget deal", "voting reform", "housing bill", "tax overhaul", "border policy"}},
+	{"business", []string{"Central bank", "Retailers", "Automakers", "Airline", "Regulators", "Shareholders"},
+		[]string{"cuts", "raises", "reports", "warns of", "bets on"}, []string{"interest rates", "quarterly profits", "job cuts", "merger talks", "supply chain costs"}},
+	{"tech", []string{"Chipmaker", "Startup", "Social network", "Browser maker", "AI lab", "Phone maker"},
+		[]string{"launches", "recalls", "open sources", "patches", "delays"}, []string{"new language model", "privacy controls", "security flaw", "smart glasses", "satellite service"}},
+	{"science", []string{"Astronomers", "Physicists", "Geneticists", "Researchers", "Space agency"},
+		[]string{"discover", "measure", "map", "confirm", "observe"}, []string{"distant exoplanet", "ancient genome", "new particle decay", "deep sea species", "lunar ice"}},
+	{"climate", []string{"Heat wave", "Wildfires", "Floods", "Drought", "Glacier melt"},
+		[]string{"threatens", "reshapes", "displaces", "strains", "tests"}, []string{"coastal towns", "power grids", "farm output", "water supplies", "insurance markets"}},
+	{"health", []string{"Hospitals", "Vaccine maker", "Health officials", "Doctors", "Drug trial"},
+		[]string{"report", "expand", "question", "approve", "track"}, []string{"flu season surge", "new cancer therapy", "nurse shortages", "measles outbreak", "drug prices"}},
+	{"sports", []string{"Champions", "Rookie", "Coach", "League", "Underdogs"},
+		[]string{"clinch", "stun", "extend", "lose", "secure"}, []string{"title race", "playoff spot", "record contract", "final match", "winning streak"}},
+}
+
+var syntheticAuthors = []string{"Alex Rivera", "Priya Natarajan", "Sam Okafor", "Maria Gonzalez", "Chen Wei", "Jordan Blake", "Fatima Haddad", "Tomás Novak", "Grace Kim", "Desmond Clarke"}
+
+var syntheticPostTexts = []string{"Worth a read:", "Big if true.", "Important reporting here", "Thread below 👇", "Developing story", "This matters.", "Must read", ""}
+
+// GenerateSyntheticArticles returns config.Articles generated articles. Sites are
+// drawn by their weights, quality scores follow a normal distribution clamped to
+// [0, 1], publish times fall off exponentially into the past, and engagement
+// grows with quality so feeds have something to rank.
+func GenerateSyntheticArticles(config SyntheticConfig) []MockArticleData {
+	config = config.withDefaults()
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	var totalWeight float64
+	for _, domain := range config.Domains {
+		totalWeight += domain.Weight
+	}
+
+	now := time.Now()
+	articles := make([]MockArticleData, 0, config.Articles)
+	for i := 0; i < config.Articles; i++ {
+		domain := pickDomain(rng, config.Domains, totalWeight)
+		topic := syntheticTopics[rng.Intn(len(syntheticTopics))]
+		title := fmt.Sprintf("%s %s %s",
+			topic.subjects[rng.Intn(len(topic.subjects))],
+			topic.actions[rng.Intn(len(topic.actions))],
+			topic.objects[rng.Intn(len(topic.objects))])
+
+		// Most articles are recent; the mean age is a quarter of the spread
+		age := time.Duration(rng.ExpFloat64() * float64(config.TimeSpread) / 4)
+		if age > config.TimeSpread {
+			age = time.Duration(rng.Int63n(int64(config.TimeSpread)))
+		}
+		publishedAt := now.Add(-age).Truncate(time.Minute)
+
+		quality := clamp01(config.QualityMean + rng.NormFloat64()*config.QualitySpread)
+		engagement := math.Exp(rng.NormFloat64()+quality*3) * 10
+		shares := 1 + int(math.Min(rng.ExpFloat64()*quality*2, float64(config.MaxShares-1)))
+		wordCount := 300 + rng.Intn(2200)
+
+		articles = append(articles, MockArticleData{
+			URL:           fmt.Sprintf("https://%s/%s/%s/%s-%x", domain.Domain, topic.slug, publishedAt.Format("2006/01/02"), slugify(title), rng.Uint32()),
+			Title:         title,
+			Description:   fmt.Sprintf("%s, according to reporting by %s.", title, domain.SiteName),
+			Author:        syntheticAuthors[rng.Intn(len(syntheticAuthors))],
+			SiteName:      domain.SiteName,
+			ImageURL:      fmt.Sprintf("https://picsum.photos/seed/%x/400/300", rng.Uint32()),
+			PublishedAt:   publishedAt,
+			WordCount:     wordCount,
+			QualityScore:  quality,
+			TrendingScore: clamp01(quality * math.Exp(-age.Hours()/24)),
+			PostText:      syntheticPostTexts[rng.Intn(len(syntheticPostTexts))],
+			IsRepost:      rng.Float64() < 0.15,
+			LikesCount:    int(engagement),
+			RepostsCount:  int(engagement * (0.2 + rng.Float64()*0.3)),
+			RepliesCount:  int(engagement * rng.Float64() * 0.2),
+			ShareScore:    clamp01(quality * (0.8 + rng.Float64()*0.2)),
+			Tags:          []string{topic.slug},
+			Shares:        shares,
+		})
+	}
+	return articles
+}
+
+// pickDomain draws a domain with probability proportional to its weight
+func pickDomain(rng *rand.Rand, domains []DomainWeight, totalWeight float64) DomainWeight {
+	target := rng.Float64() * totalWeight
+	for _, domain := range domains {
+		target -= domain.Weight
+		if target < 0 {
+			return domain
+		}
+	}
+	return domains[len(domains)-1]
+}
+
+// slugify lowercases a headline and joins its words with hyphens
+func slugify(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), "-")
+}
+
+// clamp01 limits value to [0, 1]
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
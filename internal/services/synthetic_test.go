@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSyntheticArticles(t *testing.T) {
+	config := SyntheticConfig{
+		Articles:      2000,
+		Seed:          42,
+		Domains:       []DomainWeight{{Domain: "big.example", Weight: 3}, {Domain: "small.example", Weight: 1}},
+		QualityMean:   0.6,
+		QualitySpread: 0.1,
+		TimeSpread:    48 * time.Hour,
+	}
+	articles := GenerateSyntheticArticles(config)
+	require.Len(t, articles, 2000)
+
+	again := GenerateSyntheticArticles(config)
+	assert.Equal(t, articles[10].URL, again[10].URL, "the same seed generates the same articles")
+
+	urls := make(map[string]bool)
+	big, quality := 0, 0.0
+	oldest := time.Now().Add(-48*time.Hour - time.Minute)
+	for _, article := range articles {
+		urls[article.URL] = true
+		if strings.HasPrefix(article.URL, "https://big.example/") {
+			big++
+		}
+		quality += article.QualityScore
+		assert.True(t, article.QualityScore >= 0 && article.QualityScore <= 1)
+		assert.True(t, article.PublishedAt.After(oldest), "published within the time spread")
+		assert.GreaterOrEqual(t, article.Shares, 1)
+		assert.LessOrEqual(t, article.Shares, 5)
+		assert.Len(t, article.Tags, 1)
+	}
+	assert.Len(t, urls, 2000, "every article has its own URL")
+	assert.InDelta(t, 0.75, float64(big)/2000, 0.05, "sites are drawn by weight")
+	assert.InDelta(t, 0.6, quality/2000, 0.02)
+}
+
+func TestParseDomainWeights(t *testing.T) {
+	weights, err := ParseDomainWeights("reuters.com:3, example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []DomainWeight{
+		{Domain: "reuters.com", SiteName: "Reuters", Weight: 3},
+		{Domain: "example.com", SiteName: "example.com", Weight: 1},
+	}, weights)
+
+	_, err = ParseDomainWeights("example.com:-1")
+	assert.Error(t, err)
+	_, err = ParseDomainWeights(" , ")
+	assert.Error(t, err)
+}
+
+func TestCreateMockArticles(t *testing.T) {
+	db := setupTestDB(t)
+	for i := 0; i < 3; i++ {
+		source := models.Source{ID: uuid.New(), BlueSkyDID: fmt.Sprintf("did:plc:testsynthetic%d", i), Handle: fmt.Sprintf("synthetic%d.test", i)}
+		require.NoError(t, db.Create(&source).Error)
+	}
+
+	service := NewArticlesService(db, nil)
+	config := ArticleSeedConfig{Synthetic: SyntheticConfig{Articles: 20, Seed: 7, MaxShares: 3}}
+	require.NoError(t, service.CreateMockArticles(config))
+
+	var articles, shares int64
+	db.Model(&models.Article{}).Count(&articles)
+	db.Model(&models.SourceArticle{}).Count(&shares)
+	assert.Equal(t, int64(20), articles)
+	assert.GreaterOrEqual(t, shares, int64(20))
+
+	// Generating the same articles again only adds shares
+	require.NoError(t, service.CreateMockArticles(config))
+	db.Model(&models.Article{}).Count(&articles)
+	assert.Equal(t, int64(20), articles)
+}
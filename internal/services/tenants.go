@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// tenantReloadInterval is how long resolved hostnames are served from memory
+const tenantReloadInterval = time.Minute
+
+var (
+	// ErrInvalidTenantSlug is returned for a slug that isn't lowercase letters, digits and dashes
+	ErrInvalidTenantSlug = errors.New("slug must be lowercase letters, digits and dashes")
+
+	// ErrTenantNameRequired is returned when a tenant is saved without a name
+	ErrTenantNameRequired = errors.New("tenant name is required")
+
+	// ErrTenantHostTaken is returned when another tenant already serves a hostname
+	ErrTenantHostTaken = errors.New("hostname is already served by another tenant")
+
+	// tenantSlugPattern matches valid tenant slugs
+	tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+)
+
+// TenantService manages the tenants of a deployment and resolves the tenant a
+// request is for from its hostname. Tenants are cached in memory and reloaded
+// every minute.
+type TenantService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	tenants  []models.Tenant
+	loadedAt time.Time
+}
+
+// NewTenantService creates a new TenantService. Tenants are read on first use.
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{db: db}
+}
+
+// NormalizeHost lowercases a request host and strips its port
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// Resolve returns the active tenant serving a host, or the default tenant when
+// no tenant claims it
+func (s *TenantService) Resolve(host string) (*models.Tenant, error) {
+	host = NormalizeHost(host)
+	tenants := s.current()
+	var fallback *models.Tenant
+	for i := range tenants {
+		tenant := &tenants[i]
+		if tenant.IsDefault() {
+			fallback = tenant
+		}
+		if !tenant.IsActive {
+			continue
+		}
+		for _, hostname := range tenant.Hostnames {
+			if hostname == host {
+				return tenant, nil
+			}
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	return s.Default()
+}
+
+// current returns the cached tenants, reloading them when they're older than
+// tenantReloadInterval. A failed reload keeps serving the previous tenants.
+func (s *TenantService) current() []models.Tenant {
+	s.mu.RLock()
+	tenants, fresh := s.tenants, time.Since(s.loadedAt) < tenantReloadInterval
+	s.mu.RUnlock()
+	if fresh {
+		return tenants
+	}
+
+	list, err := s.List()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Now()
+	if err != nil {
+		log.Printf("Failed to load tenants, keeping previous hostnames: %v", err)
+		return s.tenants
+	}
+	s.tenants = list
+	return s.tenants
+}
+
+// invalidate makes the next resolve read the tenants again
+func (s *TenantService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// Default returns the default tenant, creating it if it doesn't exist yet
+func (s *TenantService) Default() (*models.Tenant, error) {
+	return models.DefaultTenant(s.db)
+}
+
+// List returns every tenant ordered by slug
+func (s *TenantService) List() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	err := s.db.Order("slug ASC").Find(&tenants).Error
+	return tenants, err
+}
+
+// Get returns a tenant by ID. It returns gorm.ErrRecordNotFound when there's
+// no such tenant.
+func (s *TenantService) Get(id uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.db.Where("id = ?", id).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// BySlug returns a tenant by slug. It returns gorm.ErrRecordNotFound when
+// there's no such tenant.
+func (s *TenantService) BySlug(slug string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.db.Where("slug = ?", strings.TrimSpace(slug)).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// Create adds a tenant. Its name defaults to its slug.
+func (s *TenantService) Create(tenant models.Tenant) (*models.Tenant, error) {
+	tenant.Slug = strings.ToLower(strings.TrimSpace(tenant.Slug))
+	if !tenantSlugPattern.MatchString(tenant.Slug) {
+		return nil, ErrInvalidTenantSlug
+	}
+	if strings.TrimSpace(tenant.Name) == "" {
+		tenant.Name = tenant.Slug
+	}
+	tenant.IsActive = true
+	if err := s.checkHostnames(&tenant); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tenant %s: %w", tenant.Slug, err)
+	}
+	s.invalidate()
+	return &tenant, nil
+}
+
+// Save updates a tenant's hostnames, branding and Bluesky account. An empty
+// Bluesky password keeps the stored one.
+func (s *TenantService) Save(tenant models.Tenant) (*models.Tenant, error) {
+	if strings.TrimSpace(tenant.Name) == "" {
+		return nil, ErrTenantNameRequired
+	}
+	if err := s.checkHostnames(&tenant); err != nil {
+		return nil, err
+	}
+	columns := []string{"name", "logo_url", "accent_color", "hostnames", "feed_generator_did", "bluesky_identifier", "is_active", "updated_at"}
+	if tenant.BlueskyPassword != "" {
+		columns = append(columns, "bluesky_password")
+	}
+	result := s.db.Model(&models.Tenant{ID: tenant.ID}).Select(columns).Updates(&tenant)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save tenant %s: %w", tenant.Slug, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	s.invalidate()
+	return s.Get(tenant.ID)
+}
+
+// checkHostnames normalizes a tenant's hostnames and rejects those another
+// tenant serves
+func (s *TenantService) checkHostnames(tenant *models.Tenant) error {
+	hostnames := make(pq.StringArray, 0, len(tenant.Hostnames))
+	for _, hostname := range tenant.Hostnames {
+		if hostname = NormalizeHost(hostname); hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	tenant.Hostnames = hostnames
+
+	others, err := s.List()
+	if err != nil {
+		return fmt.Errorf("failed to load tenants: %w", err)
+	}
+	for _, other := range others {
+		if other.ID == tenant.ID {
+			continue
+		}
+		for _, taken := range other.Hostnames {
+			for _, hostname := range tenant.Hostnames {
+				if hostname == taken {
+					return fmt.Errorf("%w: %s (%s)", ErrTenantHostTaken, hostname, other.Slug)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"open-news/internal/feeds"
+	"open-news/internal/models"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupTenantTestDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Tenant{}, &models.AdminUser{}, &models.AdminSession{}, &models.FeedDefinition{}))
+	cleanup := func() {
+		db.Exec("DELETE FROM admin_sessions")
+		db.Exec("DELETE FROM admin_users")
+		db.Exec("DELETE FROM feed_definitions")
+		db.Exec("DELETE FROM tenants")
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	return db
+}
+
+func TestNormalizeHost(t *testing.T) {
+	assert.Equal(t, "feeds.example.com", NormalizeHost("Feeds.Example.com:8443"))
+	assert.Equal(t, "feeds.example.com", NormalizeHost("feeds.example.com."))
+	assert.Equal(t, "localhost", NormalizeHost("localhost"))
+}
+
+func TestTenantService_Resolve(t *testing.T) {
+	db := setupTenantTestDB(t)
+	s := NewTenantService(db)
+
+	_, err := s.Create(models.Tenant{Slug: "City News"})
+	assert.ErrorIs(t, err, ErrInvalidTenantSlug)
+
+	city, err := s.Create(models.Tenant{Slug: "citynews", Hostnames: pq.StringArray{"Feeds.CityNews.example"}})
+	require.NoError(t, err)
+	assert.Equal(t, "citynews", city.Name, "the name defaults to the slug")
+	assert.Equal(t, pq.StringArray{"feeds.citynews.example"}, city.Hostnames)
+
+	_, err = s.Create(models.Tenant{Slug: "copycat", Hostnames: pq.StringArray{"feeds.citynews.example"}})
+	assert.ErrorIs(t, err, ErrTenantHostTaken)
+
+	resolved, err := s.Resolve("feeds.citynews.example:443")
+	require.NoError(t, err)
+	assert.Equal(t, city.ID, resolved.ID)
+
+	resolved, err = s.Resolve("unknown.example")
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultTenantSlug, resolved.Slug, "unclaimed hosts are served by the default tenant")
+
+	city.IsActive = false
+	city.Name = "City News"
+	_, err = s.Save(*city)
+	require.NoError(t, err)
+	resolved, err = s.Resolve("feeds.citynews.example")
+	require.NoError(t, err)
+	assert.True(t, resolved.IsDefault(), "inactive tenants aren't served")
+}
+
+func TestTenantScoping(t *testing.T) {
+	db := setupTenantTestDB(t)
+	tenants := NewTenantService(db)
+	city, err := tenants.Create(models.Tenant{Slug: "citynews"})
+	require.NoError(t, err)
+
+	// Both tenants can use the same usernames and record keys
+	users := NewAdminUserService(db)
+	owner, err := users.CreateUser("admin", "default-password", models.AdminRoleAdmin)
+	require.NoError(t, err)
+	cityUsers := users.ForTenant(city.ID)
+	_, err = cityUsers.CreateUser("admin", "city-password", models.AdminRoleAdmin)
+	require.NoError(t, err)
+
+	_, _, err = cityUsers.SignIn("admin", "default-password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "accounts only sign in to their own tenant")
+	token, _, err := users.SignIn("admin", "default-password")
+	require.NoError(t, err)
+	_, err = cityUsers.ValidateSession(token)
+	assert.ErrorIs(t, err, ErrInvalidSession, "sessions don't carry over to other tenants")
+	current, err := users.ValidateSession(token)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, current.ID)
+
+	listed, err := cityUsers.ListUsers()
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, city.ID, *listed[0].TenantID)
+	assert.ErrorIs(t, cityUsers.SetRole(owner.ID, models.AdminRoleViewer), gorm.ErrRecordNotFound)
+
+	registry := feeds.NewRegistry(db)
+	require.NoError(t, registry.EnsureDefaults())
+	cityRegistry := registry.ForTenant(city.ID)
+	require.NoError(t, cityRegistry.EnsureDefaults())
+	db.Model(&models.FeedDefinition{}).Where("tenant_id = ? AND rkey = ?", city.ID, "open-news-global").Update("display_name", "City News - Top Stories")
+
+	def, err := cityRegistry.Lookup("at://did:plc:citynews/app.bsky.feed.generator/open-news-global")
+	require.NoError(t, err)
+	assert.Equal(t, "City News - Top Stories", def.DisplayName)
+	def, err = registry.Lookup("open-news-global")
+	require.NoError(t, err)
+	assert.Equal(t, "Open News - Global", def.DisplayName)
+}
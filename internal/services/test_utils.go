@@ -38,15 +38,40 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.SourceArticle{},
 		&models.Feed{},
 		&models.ArticleFact{},
+		&models.Entity{},
+		&models.ArticleEntity{},
 		&models.UserSource{},
+		&models.Click{},
+		&models.UserTopicAffinity{},
+		&models.UserFeedPreference{},
+		&models.Domain{},
+		&models.FeedItem{},
+		&models.ArchivedFeedItem{},
+		&models.Impression{},
+		&models.RetentionRun{},
+		&models.Label{},
+		&models.PublisherOptOut{},
+		&models.ScoreHistory{},
+		&models.UserExport{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
 	}
 
 	// Clean up any existing test data
+	db.Exec("DELETE FROM retention_runs")
+	db.Exec("DELETE FROM labels")
+	db.Exec("DELETE FROM publisher_opt_outs")
+	db.Exec("DELETE FROM impressions")
+	db.Exec("DELETE FROM feed_items_archive")
+	db.Exec("DELETE FROM feed_items")
+	db.Exec("DELETE FROM user_topic_affinities")
+	db.Exec("DELETE FROM user_feed_preferences")
+	db.Exec("DELETE FROM clicks")
 	db.Exec("DELETE FROM user_sources")
 	db.Exec("DELETE FROM source_articles")
+	db.Exec("DELETE FROM article_entities")
+	db.Exec("DELETE FROM entities")
 	db.Exec("DELETE FROM article_facts")
 	db.Exec("DELETE FROM articles")
 	db.Exec("DELETE FROM sources WHERE blue_sky_d_id LIKE 'did:plc:test%'")
@@ -54,3 +79,16 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 	return db
 }
+
+// setupSQLiteTestDB returns a migrated in-memory SQLite database, for tests
+// that don't need Postgres
+func setupSQLiteTestDB(t *testing.T) *gorm.DB {
+	if err := database.Connect(&database.Config{Driver: database.DriverSQLite, Path: ":memory:"}); err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return database.DB
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	// topicLearningWindow is how far back shares and clicks teach topic preferences
+	topicLearningWindow = 30 * 24 * time.Hour
+
+	// topicClickWeight is how much more a click says about a user's interests
+	// than a share by one of their follows
+	topicClickWeight = 2.0
+
+	// minTopicWeight leaves out topics the user only brushed against
+	minTopicWeight = 0.02
+
+	// maxLearnedTopics caps the topics stored per user
+	maxLearnedTopics = 20
+)
+
+// TopicPreferences returns a user's learned topic preferences, strongest first
+func (s *PreferencesService) TopicPreferences(userID uuid.UUID) ([]models.UserTopicAffinity, error) {
+	var affinities []models.UserTopicAffinity
+	err := s.db.Where("user_id = ?", userID).Order("weight DESC, topic").Find(&affinities).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topic preferences: %w", err)
+	}
+	return affinities, nil
+}
+
+// LearnTopics replaces a user's topic preferences with weights derived from the
+// topics of the articles their follows shared and the articles they clicked in
+// the last 30 days
+func (s *PreferencesService) LearnTopics(userID uuid.UUID) ([]models.UserTopicAffinity, error) {
+	since := time.Now().Add(-topicLearningWindow)
+
+	// Topics are counted from each article's tags here rather than with unnest,
+	// which SQLite doesn't have
+	var shared []pq.StringArray
+	err := s.db.Model(&models.Article{}).
+		Where(`EXISTS (
+			SELECT 1 FROM source_articles
+			JOIN user_sources ON user_sources.source_id = source_articles.source_id
+			WHERE source_articles.article_id = articles.id AND user_sources.user_id = ?
+			AND source_articles.posted_at > ?)`, userID, since).
+		Where("articles.created_at > ? AND articles.is_not_news = ?", since, false).
+		Pluck("articles.tags", &shared).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shared topics: %w", err)
+	}
+
+	var clicked []pq.StringArray
+	err = s.db.Model(&models.Article{}).
+		Where("articles.id IN (SELECT article_id FROM clicks WHERE user_id = ? AND created_at > ?)", userID, since).
+		Pluck("articles.tags", &clicked).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicked topics: %w", err)
+	}
+
+	shares, clicks := countTopics(shared), countTopics(clicked)
+	affinities := make([]models.UserTopicAffinity, 0, maxLearnedTopics)
+	for topic, weight := range topicWeights(shares, clicks) {
+		if weight < minTopicWeight {
+			continue
+		}
+		affinities = append(affinities, models.UserTopicAffinity{
+			UserID: userID,
+			Topic:  topic,
+			Shares: shares[topic],
+			Clicks: clicks[topic],
+			Weight: weight,
+		})
+	}
+	sort.Slice(affinities, func(i, j int) bool {
+		if affinities[i].Weight != affinities[j].Weight {
+			return affinities[i].Weight > affinities[j].Weight
+		}
+		return affinities[i].Topic < affinities[j].Topic
+	})
+	if len(affinities) > maxLearnedTopics {
+		affinities = affinities[:maxLearnedTopics]
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTopicAffinity{}).Error; err != nil {
+			return err
+		}
+		if len(affinities) == 0 {
+			return nil
+		}
+		return tx.Create(&affinities).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store topic preferences: %w", err)
+	}
+	return affinities, nil
+}
+
+// LearnAllTopics relearns the topic preferences of every user who follows
+// sources or recently clicked an article, and returns how many were updated.
+// Users that fail are logged and retried on the next run.
+func (s *PreferencesService) LearnAllTopics() (int, error) {
+	var userIDs []uuid.UUID
+	err := s.db.Raw(`SELECT user_id FROM user_sources
+		UNION
+		SELECT user_id FROM clicks WHERE user_id IS NOT NULL AND created_at > ?`,
+		time.Now().Add(-topicLearningWindow)).Scan(&userIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users to learn topics for: %w", err)
+	}
+
+	learned := 0
+	for _, userID := range userIDs {
+		if _, err := s.LearnTopics(userID); err != nil {
+			log.Printf("❌ Failed to learn topics for user %s: %v", userID, err)
+			continue
+		}
+		learned++
+	}
+	log.Printf("🎯 Learned topic preferences for %d users", learned)
+	return learned, nil
+}
+
+// countTopics counts the articles on each topic from their tags
+func countTopics(articleTags []pq.StringArray) map[string]int {
+	byTopic := make(map[string]int)
+	for _, tags := range articleTags {
+		seen := make(map[string]bool, len(tags))
+		for _, topic := range tags {
+			if !seen[topic] {
+				seen[topic] = true
+				byTopic[topic]++
+			}
+		}
+	}
+	return byTopic
+}
+
+// topicWeights turns the articles per topic the user's follows shared and the
+// user clicked into weights that sum to 1: each topic's part of the shares and
+// of the clicks, with clicks counting topicClickWeight times as much
+func topicWeights(shares, clicks map[string]int) map[string]float64 {
+	totalShares, totalClicks := 0, 0
+	for _, n := range shares {
+		totalShares += n
+	}
+	for _, n := range clicks {
+		totalClicks += n
+	}
+
+	signals := 0.0
+	if totalShares > 0 {
+		signals++
+	}
+	if totalClicks > 0 {
+		signals += topicClickWeight
+	}
+	if signals == 0 {
+		return nil
+	}
+
+	weights := make(map[string]float64, len(shares)+len(clicks))
+	for topic, n := range shares {
+		weights[topic] += float64(n) / float64(totalShares) / signals
+	}
+	for topic, n := range clicks {
+		weights[topic] += topicClickWeight * float64(n) / float64(totalClicks) / signals
+	}
+	return weights
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicWeights(t *testing.T) {
+	weights := topicWeights(map[string]int{"politics": 3, "sports": 1}, nil)
+	assert.InDelta(t, 0.75, weights["politics"], 1e-9)
+	assert.InDelta(t, 0.25, weights["sports"], 1e-9)
+
+	// A click counts twice as much as the user's follows' shares
+	weights = topicWeights(map[string]int{"politics": 1}, map[string]int{"science": 1})
+	assert.InDelta(t, 1.0/3, weights["politics"], 1e-9)
+	assert.InDelta(t, 2.0/3, weights["science"], 1e-9)
+
+	assert.Empty(t, topicWeights(nil, nil))
+}
+
+func TestLearnTopics(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewPreferencesService(db)
+
+	user := models.User{BlueSkyDID: "did:plc:testtopics", Handle: "topics.test"}
+	require.NoError(t, db.Create(&user).Error)
+	source := models.Source{BlueSkyDID: "did:plc:testtopicsource", Handle: "topicsource.test", IsActive: true}
+	require.NoError(t, db.Create(&source).Error)
+	require.NoError(t, db.Create(&models.UserSource{UserID: user.ID, SourceID: source.ID}).Error)
+
+	politics := models.Article{URL: "https://example.com/vote", Title: "Vote count", Tags: pq.StringArray{"politics"}}
+	science := models.Article{URL: "https://example.com/comet", Title: "Comet sighted", Tags: pq.StringArray{"science"}}
+	for _, a := range []*models.Article{&politics, &science} {
+		require.NoError(t, db.Create(a).Error)
+	}
+	require.NoError(t, db.Create(&models.SourceArticle{SourceID: source.ID, ArticleID: politics.ID, PostURI: "at://did:plc:testtopicsource/app.bsky.feed.post/1", PostedAt: time.Now()}).Error)
+	require.NoError(t, db.Create(&models.Click{ArticleID: science.ID, UserID: &user.ID, Surface: "web"}).Error)
+
+	learned, err := service.LearnTopics(user.ID)
+	require.NoError(t, err)
+	require.Len(t, learned, 2)
+	assert.Equal(t, "science", learned[0].Topic, "clicks weigh more than follows' shares")
+	assert.Equal(t, 1, learned[0].Clicks)
+	assert.Equal(t, "politics", learned[1].Topic)
+	assert.Equal(t, 1, learned[1].Shares)
+
+	stored, err := service.TopicPreferences(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"science", "politics"}, []string{stored[0].Topic, stored[1].Topic})
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	languages, err := normalizeLanguages([]string{"EN", "en-GB", " fil ", "pt_BR"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"en", "fil", "pt"}, languages)
+
+	languages, err = normalizeLanguages(nil)
+	require.NoError(t, err)
+	assert.Empty(t, languages)
+
+	for _, invalid := range []string{"e", "english", "e1"} {
+		_, err := normalizeLanguages([]string{invalid})
+		assert.ErrorIs(t, err, ErrInvalidLanguage, invalid)
+	}
+}
+
+func TestTopicSettings(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewPreferencesService(db)
+
+	user := models.User{BlueSkyDID: "did:plc:testsettings", Handle: "settings.test"}
+	require.NoError(t, db.Create(&user).Error)
+
+	require.NoError(t, service.FollowTopic(user.ID, "science"))
+	require.NoError(t, service.FollowTopic(user.ID, "science"))
+	require.NoError(t, service.FollowTopic(user.ID, "sports"))
+	assert.ErrorIs(t, service.FollowTopic(user.ID, "astrology"), ErrUnknownTopic)
+	require.NoError(t, service.UnfollowTopic(user.ID, "sports"))
+	_, err := service.SetLanguages(user.ID, []string{"en-US", "es"})
+	require.NoError(t, err)
+
+	followed, languages, err := service.Settings(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"science"}, followed, "following twice keeps one entry")
+	assert.Equal(t, []string{"en", "es"}, languages)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"log"
+
+	"open-news/internal/models"
+	"open-news/internal/topics"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// TopicsService tags stored articles with topic slugs
+type TopicsService struct {
+	db *gorm.DB
+}
+
+// NewTopicsService creates a new topics service
+func NewTopicsService(db *gorm.DB) *TopicsService {
+	return &TopicsService{db: db}
+}
+
+// ClassifyUnclassifiedArticles tags articles that have never been classified.
+// Classified articles always get a non-NULL tags array, even when no topic matched,
+// so each article is only processed once.
+func (ts *TopicsService) ClassifyUnclassifiedArticles(batchSize int) (int, error) {
+	var articles []models.Article
+	err := ts.db.Select("id", "title", "description", "text_content", "jsonld_data", "tags").
+		Where("tags IS NULL AND is_reachable = ?", true).
+		Order("created_at DESC").
+		Limit(batchSize).
+		Find(&articles).Error
+	if err != nil {
+		return 0, err
+	}
+
+	classified := 0
+	for _, article := range articles {
+		tags := topics.MergeTags(article.Tags, topics.Classify(topics.Input{
+			Title:       article.Title,
+			Description: article.Description,
+			Text:        article.TextContent,
+			JSONLD:      article.JSONLDData,
+		}))
+
+		if err := ts.db.Model(&models.Article{}).Where("id = ?", article.ID).
+			UpdateColumn("tags", pq.StringArray(tags)).Error; err != nil {
+			log.Printf("Failed to update topics for article %s: %v", article.ID, err)
+			continue
+		}
+		classified++
+	}
+
+	if classified > 0 {
+		log.Printf("🏷️  Classified topics for %d articles", classified)
+	}
+	return classified, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertArticleConcurrently(t *testing.T) {
+	db := setupTestDB(t)
+
+	const workers = 8
+	articles := make([]models.Article, workers)
+	created := make([]bool, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			articles[i] = models.Article{URL: "https://example.com/race", Title: "Race"}
+			created[i], errs[i] = models.InsertArticle(db, &articles[i])
+		}(i)
+	}
+	wg.Wait()
+
+	inserted := 0
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		if created[i] {
+			inserted++
+		}
+		assert.Equal(t, articles[0].ID, articles[i].ID, "every worker ends up with the stored article")
+	}
+	assert.Equal(t, 1, inserted)
+}
+
+func TestInsertShareAndFollow(t *testing.T) {
+	db := setupTestDB(t)
+	user := models.User{BlueSkyDID: "did:plc:testupsertuser", Handle: "upsert.test"}
+	created, err := models.InsertUser(db, &user)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	source := models.Source{BlueSkyDID: "did:plc:testupsertsource", Handle: "upsert-source.test"}
+	_, err = models.InsertSource(db, &source)
+	require.NoError(t, err)
+	again := models.Source{BlueSkyDID: "did:plc:testupsertsource", Handle: "renamed.test"}
+	created, err = models.InsertSource(db, &again)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, source.ID, again.ID)
+	assert.Equal(t, "upsert-source.test", again.Handle, "the stored source is loaded")
+
+	article := models.Article{URL: "https://example.com/upsert", Title: "Upsert"}
+	_, err = models.InsertArticle(db, &article)
+	require.NoError(t, err)
+
+	postedAt := time.Now().Truncate(time.Second)
+	for i, want := range []bool{true, false} {
+		share := models.SourceArticle{SourceID: source.ID, ArticleID: article.ID, PostURI: "at://did:plc:testupsertsource/app.bsky.feed.post/1", PostedAt: postedAt}
+		created, err := models.InsertShare(db, &share)
+		require.NoError(t, err)
+		assert.Equal(t, want, created, "insert %d", i)
+
+		follow := models.UserSource{UserID: user.ID, SourceID: source.ID}
+		created, err = models.InsertUserSource(db, &follow)
+		require.NoError(t, err)
+		assert.Equal(t, want, created, "insert %d", i)
+	}
+}
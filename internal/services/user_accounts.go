@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserAccountService lets feed users see and delete what's stored about them
+type UserAccountService struct {
+	db *gorm.DB
+}
+
+// NewUserAccountService creates a new user account service
+func NewUserAccountService(db *gorm.DB) *UserAccountService {
+	return &UserAccountService{db: db}
+}
+
+// WithContext returns an account service running its queries with ctx
+func (s *UserAccountService) WithContext(ctx context.Context) *UserAccountService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// UserProfile is a user's stored record with counts of their personal data
+type UserProfile struct {
+	User        models.User `json:"user"`
+	Follows     int64       `json:"follows"`      // Accounts in the user's follows snapshot
+	CustomFeeds int64       `json:"custom_feeds"` // Feeds the user defined
+}
+
+// Profile returns a user's stored record, or gorm.ErrRecordNotFound
+func (s *UserAccountService) Profile(userID uuid.UUID) (*UserProfile, error) {
+	var profile UserProfile
+	if err := s.db.First(&profile.User, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&models.UserSource{}).Where("user_id = ?", userID).Count(&profile.Follows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count follows: %w", err)
+	}
+	if err := s.db.Model(&models.FeedDefinition{}).Where("owner_id = ?", userID).Count(&profile.CustomFeeds).Error; err != nil {
+		return nil, fmt.Errorf("failed to count custom feeds: %w", err)
+	}
+	return &profile, nil
+}
+
+// DeleteAccount deletes a user with their follows snapshot, preferences,
+// learned topic weights, personal feed items, custom feeds and exports, or returns
+// gorm.ErrRecordNotFound. Their clicks and impressions are kept for analytics
+// but no longer name them. A user who requests their feeds again is stored
+// afresh, starting from scratch.
+func (s *UserAccountService) DeleteAccount(userID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		deletes := []struct {
+			model  interface{}
+			column string
+		}{
+			{&models.UserSource{}, "user_id"},
+			{&models.UserFeedPreference{}, "user_id"},
+			{&models.UserTopicAffinity{}, "user_id"},
+			{&models.FeedItem{}, "user_id"},
+			{&models.ArchivedFeedItem{}, "user_id"},
+			{&models.FeedDefinition{}, "owner_id"},
+			{&models.UserExport{}, "user_id"},
+		}
+		for _, d := range deletes {
+			if err := tx.Where(d.column+" = ?", userID).Delete(d.model).Error; err != nil {
+				return fmt.Errorf("failed to delete personal data: %w", err)
+			}
+		}
+		for _, model := range []interface{}{&models.Click{}, &models.Impression{}} {
+			if err := tx.Model(model).Where("user_id = ?", userID).Update("user_id", nil).Error; err != nil {
+				return fmt.Errorf("failed to anonymize activity: %w", err)
+			}
+		}
+
+		result := tx.Delete(&models.User{}, "id = ?", userID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
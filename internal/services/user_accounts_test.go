@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserAccountService(db)
+
+	user := models.User{BlueSkyDID: "did:plc:testdeleteaccount", Handle: "deleteaccount.test"}
+	other := models.User{BlueSkyDID: "did:plc:testkeepaccount", Handle: "keepaccount.test"}
+	require.NoError(t, db.Create(&user).Error)
+	require.NoError(t, db.Create(&other).Error)
+	source := models.Source{BlueSkyDID: "did:plc:testdeletesource", Handle: "deletesource.test", IsActive: true}
+	require.NoError(t, db.Create(&source).Error)
+	article := models.Article{URL: "https://example.com/deleted-reader", Title: "Read by a leaving user"}
+	require.NoError(t, db.Create(&article).Error)
+
+	for _, u := range []models.User{user, other} {
+		require.NoError(t, db.Create(&models.UserSource{UserID: u.ID, SourceID: source.ID}).Error)
+		require.NoError(t, NewPreferencesService(db).SetDigestSubscribed(u.ID, true))
+	}
+	_, err := NewCustomFeedService(db).Create(user.ID, nil, CustomFeed{Name: "Mine", MinShares: 2})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.Click{ArticleID: article.ID, UserID: &user.ID, Surface: "web"}).Error)
+
+	profile, err := service.Profile(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Handle, profile.User.Handle)
+	assert.Equal(t, int64(1), profile.Follows)
+	assert.Equal(t, int64(1), profile.CustomFeeds)
+
+	require.NoError(t, service.DeleteAccount(user.ID))
+	_, err = service.Profile(user.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, service.DeleteAccount(user.ID), gorm.ErrRecordNotFound)
+
+	var count int64
+	db.Model(&models.UserSource{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.UserFeedPreference{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.FeedDefinition{}).Where("owner_id = ?", user.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.Click{}).Where("article_id = ? AND user_id IS NULL", article.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "clicks are kept without the user")
+
+	profile, err = service.Profile(other.ID)
+	require.NoError(t, err, "other users are untouched")
+	assert.Equal(t, int64(1), profile.Follows)
+}
+
+func TestUserExport(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserAccountService(db)
+	jobs := NewJobService(db)
+
+	user := models.User{BlueSkyDID: "did:plc:testexport", Handle: "export.test"}
+	require.NoError(t, db.Create(&user).Error)
+	source := models.Source{BlueSkyDID: "did:plc:testexportsource", Handle: "exportsource.test", IsActive: true}
+	require.NoError(t, db.Create(&source).Error)
+	require.NoError(t, db.Create(&models.UserSource{UserID: user.ID, SourceID: source.ID}).Error)
+	require.NoError(t, NewPreferencesService(db).SetTopics(user.ID, []string{"science"}))
+
+	now := time.Now()
+	export, err := service.RequestExport(jobs, user.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserExportStatusPending, export.Status)
+	again, err := service.RequestExport(jobs, user.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, export.ID, again.ID, "an export in progress isn't requested twice")
+
+	require.NoError(t, service.RunExportJob(ExportUserDataPayload{ExportID: export.ID}))
+	export, err = service.Export(user.ID, export.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserExportStatusReady, export.Status)
+	require.NotNil(t, export.ExpiresAt)
+
+	var archive UserArchive
+	require.NoError(t, json.Unmarshal([]byte(export.Data), &archive))
+	assert.Equal(t, user.Handle, archive.User.Handle)
+	require.Len(t, archive.Follows, 1)
+	assert.Equal(t, source.BlueSkyDID, archive.Follows[0].DID)
+	assert.Equal(t, []string{"science"}, archive.Preferences.FollowedTopics)
+
+	_, err = service.Export(uuid.New(), export.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "users only download their own exports")
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"open-news/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// userExportTTL is how long a built archive can be downloaded before it's deleted
+const userExportTTL = 7 * 24 * time.Hour
+
+// ExportUserDataPayload is the payload of an export_user_data job
+type ExportUserDataPayload struct {
+	ExportID uuid.UUID `json:"export_id"`
+}
+
+// UserArchive is everything stored about a feed user
+type UserArchive struct {
+	ExportedAt  time.Time                  `json:"exported_at"`
+	User        models.User                `json:"user"`
+	Preferences ArchivedPreferences        `json:"preferences"`
+	Follows     []ArchivedAccount          `json:"follows"` // The snapshot of who they follow, as of the last follow refresh
+	Mutes       []ArchivedAccount          `json:"mutes"`   // Sources they blocked from their personal feeds
+	Topics      []models.UserTopicAffinity `json:"topics"`  // Learned topic weights
+	CustomFeeds []models.FeedDefinition    `json:"custom_feeds"`
+	Clicks      []models.Click             `json:"clicks"`
+	Impressions []models.Impression        `json:"impressions"`
+}
+
+// ArchivedPreferences are a user's feed settings
+type ArchivedPreferences struct {
+	FollowedTopics   []string `json:"followed_topics"`
+	Languages        []string `json:"languages"`
+	ShowSeenArticles bool     `json:"show_seen_articles"`
+	DigestSubscribed bool     `json:"digest_subscribed"`
+}
+
+// ArchivedAccount is a Bluesky account a user followed or blocked
+type ArchivedAccount struct {
+	DID    string     `json:"did" gorm:"column:did"`
+	Handle string     `json:"handle"`
+	Since  *time.Time `json:"since,omitempty"` // When a follow was first imported
+}
+
+// RequestExport returns the user's export in progress or ready to download, or
+// queues an export_user_data job to build a new one. Exports pending for longer
+// than a job can run are assumed lost and requested again.
+func (s *UserAccountService) RequestExport(jobs *JobService, userID uuid.UUID, now time.Time) (*models.UserExport, error) {
+	var export models.UserExport
+	err := s.db.Where("user_id = ?", userID).
+		Where("(status = ? AND created_at > ?) OR (status = ? AND expires_at > ?)",
+			models.UserExportStatusPending, now.Add(-jobStaleAfter), models.UserExportStatusReady, now).
+		Order("created_at DESC").
+		First(&export).Error
+	if err == nil {
+		return &export, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up exports: %w", err)
+	}
+
+	export = models.UserExport{UserID: userID, Status: models.UserExportStatusPending}
+	if err := s.db.Create(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+	if _, err := jobs.Enqueue(JobTypeExportUserData, ExportUserDataPayload{ExportID: export.ID}, now); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Export returns one of a user's exports, or gorm.ErrRecordNotFound
+func (s *UserAccountService) Export(userID, exportID uuid.UUID) (*models.UserExport, error) {
+	var export models.UserExport
+	if err := s.db.Where("id = ? AND user_id = ?", exportID, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// RunExportJob is the handler for export_user_data jobs. It builds the archive
+// and deletes archives that have expired.
+func (s *UserAccountService) RunExportJob(payload ExportUserDataPayload) error {
+	now := time.Now()
+	if err := s.db.Where("expires_at <= ?", now).Delete(&models.UserExport{}).Error; err != nil {
+		log.Printf("⚠️  Failed to delete expired exports: %v", err)
+	}
+
+	var export models.UserExport
+	if err := s.db.First(&export, "id = ?", payload.ExportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // The user deleted their account since
+		}
+		return fmt.Errorf("failed to load export: %w", err)
+	}
+
+	archive, err := s.BuildArchive(export.UserID, now)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	expiresAt := now.Add(userExportTTL)
+	return s.db.Model(&export).Updates(map[string]interface{}{
+		"status":       models.UserExportStatusReady,
+		"data":         string(data),
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+// BuildArchive collects everything stored about a user
+func (s *UserAccountService) BuildArchive(userID uuid.UUID, now time.Time) (*UserArchive, error) {
+	archive := &UserArchive{ExportedAt: now}
+	if err := s.db.First(&archive.User, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	preferences := NewPreferencesService(s.db)
+	var err error
+	prefs := &archive.Preferences
+	if prefs.FollowedTopics, prefs.Languages, err = preferences.Settings(userID); err != nil {
+		return nil, err
+	}
+	if prefs.ShowSeenArticles, prefs.DigestSubscribed, err = preferences.Options(userID); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Table("user_sources").
+		Select("sources.blue_sky_d_id AS did, sources.handle, user_sources.created_at AS since").
+		Joins("JOIN sources ON sources.id = user_sources.source_id").
+		Where("user_sources.user_id = ?", userID).
+		Order("sources.handle").
+		Scan(&archive.Follows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load follows: %w", err)
+	}
+	if archive.Mutes, err = s.blockedSources(userID); err != nil {
+		return nil, err
+	}
+
+	loads := []struct {
+		what   string
+		dest   interface{}
+		column string
+	}{
+		{"topic weights", &archive.Topics, "user_id"},
+		{"custom feeds", &archive.CustomFeeds, "owner_id"},
+		{"clicks", &archive.Clicks, "user_id"},
+		{"impressions", &archive.Impressions, "user_id"},
+	}
+	for _, load := range loads {
+		if err := s.db.Where(load.column+" = ?", userID).Find(load.dest).Error; err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", load.what, err)
+		}
+	}
+	return archive, nil
+}
+
+// blockedSources returns the accounts a user blocked from their personal feeds
+func (s *UserAccountService) blockedSources(userID uuid.UUID) ([]ArchivedAccount, error) {
+	var blocked pq.StringArray
+	err := s.db.Table("user_feed_preferences").
+		Select("blocked_sources").
+		Where("user_id = ?", userID).
+		Row().
+		Scan(&blocked)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load blocked sources: %w", err)
+	}
+	accounts := []ArchivedAccount{}
+	if len(blocked) == 0 {
+		return accounts, nil
+	}
+	err = s.db.Model(&models.Source{}).
+		Select("blue_sky_d_id AS did, handle").
+		Where("id IN ?", []string(blocked)).
+		Order("handle").
+		Scan(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocked sources: %w", err)
+	}
+	return accounts, nil
+}
@@ -1,32 +1,67 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"open-news/internal/bluesky"
 	"open-news/internal/models"
+	"open-news/internal/pool"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // BlueskyClientInterface defines the interface for Bluesky API operations
 type BlueskyClientInterface interface {
 	GetFollows(actor string, limit int, cursor string) (*bluesky.FollowsResponse, error)
+	ProfileClientInterface
 }
 
+// defaultUnfollowGracePeriod is how long an account stays followed after it stops
+// appearing in a user's follows, so a flaky listing doesn't empty personal feeds
+const defaultUnfollowGracePeriod = 48 * time.Hour
+
 // UserFollowsService handles importing and updating user follows from Bluesky
 type UserFollowsService struct {
-	db            *gorm.DB
-	blueskyClient BlueskyClientInterface
+	db                  *gorm.DB
+	blueskyClient       BlueskyClientInterface
+	unfollowGracePeriod time.Duration
 }
 
-// NewUserFollowsService creates a new UserFollowsService
+// NewUserFollowsService creates a new UserFollowsService.
+// UNFOLLOW_GRACE_HOURS (default 48, 0 removes immediately) sets how long unfollowed
+// accounts keep appearing in personal feeds.
 func NewUserFollowsService(db *gorm.DB, blueskyClient BlueskyClientInterface) *UserFollowsService {
+	gracePeriod := defaultUnfollowGracePeriod
+	if value := os.Getenv("UNFOLLOW_GRACE_HOURS"); value != "" {
+		if hours, err := strconv.Atoi(value); err == nil && hours >= 0 {
+			gracePeriod = time.Duration(hours) * time.Hour
+		} else {
+			log.Printf("Invalid UNFOLLOW_GRACE_HOURS %q, using %v", value, gracePeriod)
+		}
+	}
+
 	return &UserFollowsService{
-		db:            db,
-		blueskyClient: blueskyClient,
+		db:                  db,
+		blueskyClient:       blueskyClient,
+		unfollowGracePeriod: gracePeriod,
+	}
+}
+
+// WithContext returns a follows service running its queries, and its Bluesky API calls when they go through a bluesky.Client, with ctx
+func (s *UserFollowsService) WithContext(ctx context.Context) *UserFollowsService {
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	if client, ok := s.blueskyClient.(*bluesky.Client); ok {
+		scoped.blueskyClient = client.WithContext(ctx)
 	}
+	return &scoped
 }
 
 // RefreshConfig holds configuration for follow refresh behavior
@@ -34,6 +69,7 @@ type RefreshConfig struct {
 	RefreshInterval time.Duration // How often to refresh follows (default: 24 hours)
 	BatchSize       int           // How many users to process at once (default: 10)
 	RateLimit       time.Duration // Delay between API calls (default: 100ms)
+	Concurrency     int           // Users in a batch refreshed at once; 0 refreshes one at a time (default: FETCH_CONCURRENCY_PER_DOMAIN)
 }
 
 // DefaultRefreshConfig returns default configuration for follow refresh
@@ -42,6 +78,7 @@ func DefaultRefreshConfig() RefreshConfig {
 		RefreshInterval: 24 * time.Hour,
 		BatchSize:       10,
 		RateLimit:       100 * time.Millisecond,
+		Concurrency:     pool.Shared().PerDomain,
 	}
 }
 
@@ -57,6 +94,7 @@ func (s *UserFollowsService) ShouldRefreshFollows(user *models.User, config Refr
 func (s *UserFollowsService) ImportUserFollows(user *models.User, config RefreshConfig) error {
 	log.Printf("🔄 Importing follows for user %s (%s)", user.Handle, user.BlueSkyDID)
 	
+	importStarted := time.Now()
 	limit := 100
 	cursor := ""
 	followsCount := 0
@@ -73,6 +111,11 @@ func (s *UserFollowsService) ImportUserFollows(user *models.User, config Refresh
 
 		log.Printf("📦 Received %d follows in this batch", len(follows.Follows))
 
+		// Sources created from this page, enriched with full profiles in one batched lookup
+		var newSources []models.Source
+		// Existing relationships listed on this page, marked as still followed
+		var seenSourceIDs []uuid.UUID
+
 		// Process each follow
 		for _, follow := range follows.Follows {
 			followsCount++
@@ -91,13 +134,18 @@ func (s *UserFollowsService) ImportUserFollows(user *models.User, config Refresh
 					QualityScore: 0.5, // Default quality score
 				}
 
-				if err := s.db.Create(&source).Error; err != nil {
+				// Another import may store the same source first
+				created, err := models.InsertSource(s.db, &source)
+				if err != nil {
 					log.Printf("❌ Failed to create source for %s: %v", follow.Handle, err)
 					continue
 				}
 
-				sourcesCreated++
-				log.Printf("✅ Created source: %s (%s)", follow.Handle, follow.DID)
+				if created {
+					sourcesCreated++
+					newSources = append(newSources, source)
+					log.Printf("✅ Created source: %s (%s)", follow.Handle, follow.DID)
+				}
 			} else if err != nil {
 				log.Printf("❌ Failed to query source %s: %v", follow.Handle, err)
 				continue
@@ -126,26 +174,37 @@ func (s *UserFollowsService) ImportUserFollows(user *models.User, config Refresh
 				}
 			}
 
-			// Create user-source relationship if it doesn't exist
-			var userSource models.UserSource
-			err = s.db.Where("user_id = ? AND source_id = ?", user.ID, source.ID).First(&userSource).Error
-
-			if err == gorm.ErrRecordNotFound {
-				userSource = models.UserSource{
-					UserID:   user.ID,
-					SourceID: source.ID,
-				}
+			// Create the user-source relationship, or mark the existing one as seen
+			userSource := models.UserSource{
+				UserID:     user.ID,
+				SourceID:   source.ID,
+				LastSeenAt: &importStarted,
+			}
+			created, err := models.InsertUserSource(s.db, &userSource)
+			if err != nil {
+				log.Printf("❌ Failed to create user-source relationship for %s: %v", follow.Handle, err)
+			} else if created {
+				relationshipsCreated++
+			} else {
+				seenSourceIDs = append(seenSourceIDs, source.ID)
+			}
+		}
 
-				if err := s.db.Create(&userSource).Error; err != nil {
-					log.Printf("❌ Failed to create user-source relationship for %s: %v", follow.Handle, err)
-				} else {
-					relationshipsCreated++
-				}
-			} else if err != nil {
-				log.Printf("❌ Failed to query user-source relationship for %s: %v", follow.Handle, err)
+		if len(seenSourceIDs) > 0 {
+			err := s.db.Model(&models.UserSource{}).
+				Where("user_id = ? AND source_id IN ?", user.ID, seenSourceIDs).
+				Update("last_seen_at", importStarted).Error
+			if err != nil {
+				log.Printf("❌ Failed to mark follows as seen for user %s: %v", user.Handle, err)
 			}
 		}
 
+		// getFollows doesn't return follower counts, so fetch profiles for new sources
+		// (25 per request) rather than leaving them for the enrichment worker
+		if _, err := NewSourceProfileService(s.db, s.blueskyClient).EnrichSources(newSources); err != nil {
+			log.Printf("⚠️  Failed to fetch profiles for new sources: %v", err)
+		}
+
 		// Check if there are more follows to fetch
 		log.Printf("🔍 Pagination check: cursor='%s', batch_size=%d, limit=%d", follows.Cursor, len(follows.Follows), limit)
 		if follows.Cursor == "" || len(follows.Follows) < limit {
@@ -159,6 +218,12 @@ func (s *UserFollowsService) ImportUserFollows(user *models.User, config Refresh
 		time.Sleep(config.RateLimit)
 	}
 
+	// Every page was listed, so follows that weren't are gone. They're kept for the grace period.
+	relationshipsRemoved, err := s.removeUnfollowed(user.ID, importStarted.Add(-s.unfollowGracePeriod))
+	if err != nil {
+		log.Printf("❌ Failed to remove unfollowed sources for user %s: %v", user.Handle, err)
+	}
+
 	// Update user's follows_last_refreshed timestamp
 	now := time.Now()
 	user.FollowsLastRefreshed = &now
@@ -167,12 +232,19 @@ func (s *UserFollowsService) ImportUserFollows(user *models.User, config Refresh
 	}
 
 	log.Printf("✅ Successfully imported %d follows for user %s", followsCount, user.Handle)
-	log.Printf("   📊 Stats: %d new sources, %d updated sources, %d new relationships", 
-		sourcesCreated, sourcesUpdated, relationshipsCreated)
+	log.Printf("   📊 Stats: %d new sources, %d updated sources, %d new relationships, %d removed relationships", 
+		sourcesCreated, sourcesUpdated, relationshipsCreated, relationshipsRemoved)
 
 	return nil
 }
 
+// removeUnfollowed deletes a user's follow relationships that no refresh has listed since cutoff
+func (s *UserFollowsService) removeUnfollowed(userID uuid.UUID, cutoff time.Time) (int64, error) {
+	result := s.db.Where("user_id = ? AND COALESCE(last_seen_at, created_at) < ?", userID, cutoff).
+		Delete(&models.UserSource{})
+	return result.RowsAffected, result.Error
+}
+
 // GetUsersNeedingRefresh gets users whose follows need refreshing
 func (s *UserFollowsService) GetUsersNeedingRefresh(config RefreshConfig, limit int) ([]models.User, error) {
 	var users []models.User
@@ -201,16 +273,28 @@ func (s *UserFollowsService) RefreshBatch(config RefreshConfig) error {
 
 	log.Printf("🔄 Processing follow refresh for %d users", len(users))
 
+	// Every refresh calls the Bluesky API, so only a few run at once
+	var failures atomic.Int32
+	group := pool.New(config.Concurrency)
 	for _, user := range users {
-		if err := s.ImportUserFollows(&user, config); err != nil {
-			log.Printf("⚠️  Failed to refresh follows for user %s: %v", user.Handle, err)
-			// Continue with other users even if one fails
-		}
-		
-		// Small delay between users
-		time.Sleep(config.RateLimit)
+		group.Go(func() error {
+			// Small delay after each user
+			defer time.Sleep(config.RateLimit)
+
+			if err := s.ImportUserFollows(&user, config); err != nil {
+				log.Printf("⚠️  Failed to refresh follows for user %s: %v", user.Handle, err)
+				failures.Add(1)
+				// Continue with other users even if one fails
+				return fmt.Errorf("%s: %w", user.Handle, err)
+			}
+			return nil
+		})
 	}
 
+	// Failed users keep their old refresh time, so a retried batch picks them up again
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("failed to refresh follows for %d of %d users: %w", failures.Load(), len(users), err)
+	}
 	return nil
 }
 
@@ -257,5 +341,7 @@ func (s *UserFollowsService) createBasicUser(did string) error {
 		IsActive:    true,
 	}
 
-	return s.db.Create(&user).Error
+	// Concurrent feed requests from a new user may both create it
+	_, err := models.InsertUser(s.db, &user)
+	return err
 }
@@ -5,11 +5,13 @@ import (
 	"time"
 
 	"open-news/internal/bluesky"
+	"open-news/internal/bluesky/fake"
 	"open-news/internal/models"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockBlueskyClient is a mock implementation of the Bluesky client
@@ -25,6 +27,14 @@ func (m *MockBlueskyClient) GetFollows(actor string, limit int, cursor string) (
 	return args.Get(0).(*bluesky.FollowsResponse), args.Error(1)
 }
 
+func (m *MockBlueskyClient) GetProfiles(actors []string) ([]bluesky.Author, error) {
+	args := m.Called(actors)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bluesky.Author), args.Error(1)
+}
+
 func TestUserFollowsService_ShouldRefreshFollows(t *testing.T) {
 	service := &UserFollowsService{}
 	config := DefaultRefreshConfig()
@@ -152,6 +162,10 @@ func TestUserFollowsService_ImportUserFollows(t *testing.T) {
 	}
 
 	mockClient.On("GetFollows", "did:plc:testuser", 100, "").Return(follows, nil)
+	mockClient.On("GetProfiles", []string{"did:plc:follow1", "did:plc:follow2"}).Return([]bluesky.Author{
+		{DID: "did:plc:follow1", Handle: "follow1.bsky.social", FollowersCount: 1500},
+		{DID: "did:plc:follow2", Handle: "follow2.bsky.social", FollowersCount: 20},
+	}, nil)
 
 	config := DefaultRefreshConfig()
 
@@ -164,6 +178,12 @@ func TestUserFollowsService_ImportUserFollows(t *testing.T) {
 	db.Find(&sources)
 	assert.Len(t, sources, 2)
 
+	// New sources were enriched with their full profiles in one batched lookup
+	var enriched models.Source
+	db.Where("blue_sky_d_id = ?", "did:plc:follow1").First(&enriched)
+	assert.Equal(t, 1500, enriched.FollowersCount)
+	assert.NotNil(t, enriched.ProfileRefreshedAt)
+
 	// Verify user-source relationships were created
 	var userSources []models.UserSource
 	db.Where("user_id = ?", user.ID).Find(&userSources)
@@ -237,6 +257,82 @@ func TestUserFollowsService_ImportUserFollows_UpdateExistingSource(t *testing.T)
 	mockClient.AssertExpectations(t)
 }
 
+func TestUserFollowsService_ImportUserFollows_RemovesUnfollowed(t *testing.T) {
+	db := setupTestDB(t)
+	mockClient := &MockBlueskyClient{}
+
+	service := &UserFollowsService{
+		db:                  db,
+		blueskyClient:       mockClient,
+		unfollowGracePeriod: 48 * time.Hour,
+	}
+
+	user := &models.User{
+		ID:         uuid.New(),
+		BlueSkyDID: "did:plc:testuser3",
+		Handle:     "testuser3.bsky.social",
+		IsActive:   true,
+	}
+	db.Create(user)
+
+	// Still followed, unfollowed within the grace period, and unfollowed long ago
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-72 * time.Hour)
+	sources := []models.Source{
+		{ID: uuid.New(), BlueSkyDID: "did:plc:testkept", Handle: "kept.bsky.social"},
+		{ID: uuid.New(), BlueSkyDID: "did:plc:testgrace", Handle: "grace.bsky.social"},
+		{ID: uuid.New(), BlueSkyDID: "did:plc:testgone", Handle: "gone.bsky.social"},
+	}
+	lastSeen := []*time.Time{&longAgo, &recently, &longAgo}
+	for i := range sources {
+		db.Create(&sources[i])
+		db.Create(&models.UserSource{UserID: user.ID, SourceID: sources[i].ID, LastSeenAt: lastSeen[i]})
+	}
+
+	follows := &bluesky.FollowsResponse{
+		Follows: []bluesky.Author{{DID: "did:plc:testkept", Handle: "kept.bsky.social"}},
+	}
+	mockClient.On("GetFollows", "did:plc:testuser3", 100, "").Return(follows, nil)
+
+	err := service.ImportUserFollows(user, DefaultRefreshConfig())
+	assert.NoError(t, err)
+
+	var remaining []models.UserSource
+	db.Where("user_id = ?", user.ID).Find(&remaining)
+	remainingSources := make([]uuid.UUID, len(remaining))
+	for i, userSource := range remaining {
+		remainingSources[i] = userSource.SourceID
+	}
+	assert.ElementsMatch(t, []uuid.UUID{sources[0].ID, sources[1].ID}, remainingSources)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestUserFollowsService_ImportUserFollows_FakeServer(t *testing.T) {
+	db := setupTestDB(t)
+	server := fake.NewServer(fake.DefaultFixtures())
+	defer server.Close()
+
+	client := server.Client()
+	require.NoError(t, client.CreateSession("reader.test", "password"))
+	service := NewUserFollowsService(db, client)
+
+	user := &models.User{ID: uuid.New(), BlueSkyDID: "did:plc:fakereader", Handle: "reader.test", IsActive: true}
+	require.NoError(t, db.Create(user).Error)
+
+	require.NoError(t, service.ImportUserFollows(user, RefreshConfig{BatchSize: 1}))
+
+	var sources []models.Source
+	db.Joins("JOIN user_sources ON user_sources.source_id = sources.id").
+		Where("user_sources.user_id = ?", user.ID).Order("handle").Find(&sources)
+	require.Len(t, sources, 3)
+	assert.Equal(t, "friend.test", sources[0].Handle)
+	assert.Equal(t, "news.test", sources[1].Handle)
+	assert.Equal(t, 125000, sources[1].FollowersCount, "new sources are enriched with their profiles")
+	assert.Equal(t, 1, server.Requests("app.bsky.graph.getFollows"))
+	assert.Equal(t, 1, server.Requests("app.bsky.actor.getProfiles"))
+}
+
 func TestDefaultRefreshConfig(t *testing.T) {
 	config := DefaultRefreshConfig()
 	
@@ -0,0 +1,204 @@
+// Package sitefeeds reads the RSS and Atom feeds and XML sitemaps news sites
+// publish, so their articles can be stored before anyone shares them. Sitemap
+// indexes list further sitemaps rather than articles; news sitemaps' publication
+// dates are read too.
+package sitefeeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"open-news/internal/metadata"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// ErrNotFeed is returned for XML that isn't an RSS or Atom feed or a sitemap
+var ErrNotFeed = errors.New("not an RSS or Atom feed or a sitemap")
+
+// Entry is an article listed in a feed or sitemap
+type Entry struct {
+	URL         string
+	PublishedAt *time.Time // Nil when the feed doesn't date it
+}
+
+// Feed is a parsed feed or sitemap
+type Feed struct {
+	Entries  []Entry
+	Sitemaps []string // Sitemaps listed by a sitemap index
+}
+
+// document is every format's root element. Go matches elements by local name
+// in any namespace, so RSS 1.0, RSS 2.0, Atom and sitemap documents all fit.
+type document struct {
+	XMLName  xml.Name
+	Channel  rssChannel   `xml:"channel"`
+	Items    []rssItem    `xml:"item"` // RSS 1.0 items are outside the channel
+	Entries  []atomEntry  `xml:"entry"`
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+type rssChannel struct {
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Link    string `xml:"link"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"date"` // Dublin Core dc:date
+}
+
+type atomEntry struct {
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+	News    struct {
+		PublicationDate string `xml:"publication_date"`
+	} `xml:"news"`
+}
+
+// Parse reads an RSS or Atom feed or a sitemap. Relative links are resolved
+// against feedURL.
+func Parse(body []byte, feedURL string) (*Feed, error) {
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL: %w", err)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	var doc document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	feed := &Feed{}
+	add := func(link string, published *time.Time) {
+		if resolved := resolve(base, link); resolved != "" {
+			feed.Entries = append(feed.Entries, Entry{URL: resolved, PublishedAt: published})
+		}
+	}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			add(item.Link, firstDate(parseRSSDate(item.PubDate), metadata.ParseMetaDate(item.Date)))
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			add(entry.link(), firstDate(metadata.ParseMetaDate(entry.Published), metadata.ParseMetaDate(entry.Updated)))
+		}
+	case "urlset":
+		for _, u := range doc.URLs {
+			add(u.Loc, firstDate(metadata.ParseMetaDate(u.News.PublicationDate), metadata.ParseMetaDate(u.LastMod)))
+		}
+	case "sitemapindex":
+		for _, sitemap := range doc.Sitemaps {
+			if resolved := resolve(base, sitemap.Loc); resolved != "" {
+				feed.Sitemaps = append(feed.Sitemaps, resolved)
+			}
+		}
+	default:
+		return nil, ErrNotFeed
+	}
+	return feed, nil
+}
+
+// link returns the entry's alternate link, the article it announces
+func (e atomEntry) link() string {
+	for _, link := range e.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// Discover returns the RSS and Atom feeds a page advertises with
+// <link rel="alternate">, as absolute URLs
+func Discover(doc *html.Node, pageURL string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var feeds []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "link" {
+			var rel, feedType, href string
+			for _, attr := range n.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "rel":
+					rel = strings.ToLower(attr.Val)
+				case "type":
+					feedType = strings.ToLower(strings.TrimSpace(attr.Val))
+				case "href":
+					href = attr.Val
+				}
+			}
+			if strings.Contains(rel, "alternate") && (feedType == "application/rss+xml" || feedType == "application/atom+xml") {
+				if resolved := resolve(base, href); resolved != "" {
+					feeds = append(feeds, resolved)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return feeds
+}
+
+// resolve returns the absolute http(s) URL of a link, or ""
+func resolve(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	resolved, err := base.Parse(link)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	resolved.Fragment = ""
+	return resolved.String()
+}
+
+// parseRSSDate parses an RSS pubDate, an RFC 822 date, or nil
+func parseRSSDate(value string) *time.Time {
+	date, err := mail.ParseDate(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &date
+}
+
+// firstDate returns the first date that isn't nil
+func firstDate(dates ...*time.Time) *time.Time {
+	for _, date := range dates {
+		if date != nil {
+			return date
+		}
+	}
+	return nil
+}
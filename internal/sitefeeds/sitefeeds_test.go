@@ -0,0 +1,87 @@
+package sitefeeds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><channel>
+	<item><link>https://news.example/world/story#comments</link><pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate></item>
+	<item><link>/local/story</link><dc:date>2006-01-03T10:00:00Z</dc:date></item>
+	<item><link>mailto:desk@news.example</link></item>
+</channel></rss>`), "https://news.example/rss")
+	require.NoError(t, err)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "https://news.example/world/story", feed.Entries[0].URL)
+	require.NotNil(t, feed.Entries[0].PublishedAt)
+	assert.True(t, feed.Entries[0].PublishedAt.Equal(time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)))
+	assert.Equal(t, "https://news.example/local/story", feed.Entries[1].URL)
+	require.NotNil(t, feed.Entries[1].PublishedAt)
+	assert.Equal(t, time.Date(2006, 1, 3, 10, 0, 0, 0, time.UTC), *feed.Entries[1].PublishedAt)
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<link rel="self" href="https://news.example/feed/entry/1"/>
+		<link href="https://news.example/story"/>
+		<updated>2024-05-02T10:00:00Z</updated>
+		<published>2024-05-01T10:00:00Z</published>
+	</entry>
+	<entry><link rel="alternate" href="https://news.example/undated"/></entry>
+</feed>`), "https://news.example/atom")
+	require.NoError(t, err)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "https://news.example/story", feed.Entries[0].URL)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), *feed.Entries[0].PublishedAt)
+	assert.Nil(t, feed.Entries[1].PublishedAt)
+}
+
+func TestParseSitemaps(t *testing.T) {
+	feed, err := Parse([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+	<url>
+		<loc>https://news.example/story</loc>
+		<lastmod>2024-05-03</lastmod>
+		<news:news><news:publication_date>2024-05-01T08:00:00+02:00</news:publication_date></news:news>
+	</url>
+	<url><loc>https://news.example/page</loc><lastmod>2024-05-03</lastmod></url>
+</urlset>`), "https://news.example/sitemap.xml")
+	require.NoError(t, err)
+	require.Len(t, feed.Entries, 2)
+	assert.True(t, feed.Entries[0].PublishedAt.Equal(time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)), "the news publication date wins over lastmod")
+	assert.Equal(t, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), *feed.Entries[1].PublishedAt)
+
+	index, err := Parse([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>/sitemap-news.xml</loc></sitemap>
+	<sitemap><loc>https://news.example/sitemap-2024-05.xml</loc></sitemap>
+</sitemapindex>`), "https://news.example/sitemap.xml")
+	require.NoError(t, err)
+	assert.Empty(t, index.Entries)
+	assert.Equal(t, []string{"https://news.example/sitemap-news.xml", "https://news.example/sitemap-2024-05.xml"}, index.Sitemaps)
+}
+
+func TestParseRejectsOtherXML(t *testing.T) {
+	_, err := Parse([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "https://news.example/logo.svg")
+	assert.ErrorIs(t, err, ErrNotFeed)
+
+	_, err = Parse([]byte(`not xml`), "https://news.example/rss")
+	assert.Error(t, err)
+}
+
+func TestDiscover(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+		<link rel="alternate" type="application/rss+xml" href="/rss">
+		<link rel="alternate" type="application/atom+xml" href="https://feeds.news.example/atom">
+		<link rel="alternate" hreflang="fr" href="/fr/">
+		<link rel="stylesheet" href="/style.css">
+	</head></html>`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://news.example/rss", "https://feeds.news.example/atom"}, Discover(doc, "https://news.example/"))
+}
@@ -0,0 +1,131 @@
+package summary
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"open-news/internal/facts"
+)
+
+const (
+	// minSentenceLength and maxSentenceLength skip captions, bylines and run-on
+	// paragraphs that lost their punctuation
+	minSentenceLength = 40
+	maxSentenceLength = 400
+
+	// maxSummaryLength caps the characters of an extractive summary
+	maxSummaryLength = 500
+
+	// leadSentences are the opening sentences, which usually state the story
+	leadSentences = 5
+)
+
+// ExtractiveSummarizer picks the sentences of an article that best cover its
+// most frequent words and its title, favoring the opening paragraphs
+type ExtractiveSummarizer struct{}
+
+// NewExtractiveSummarizer creates an extractive summarizer
+func NewExtractiveSummarizer() *ExtractiveSummarizer {
+	return &ExtractiveSummarizer{}
+}
+
+// Name identifies the summarizer
+func (s *ExtractiveSummarizer) Name() string {
+	return "extractive"
+}
+
+// Summarize returns up to three of the article's sentences, in article order
+func (s *ExtractiveSummarizer) Summarize(ctx context.Context, input Input) (string, error) {
+	var candidates []string
+	for _, sentence := range facts.Sentences(input.Text) {
+		if len(sentence) >= minSentenceLength && len(sentence) <= maxSentenceLength {
+			candidates = append(candidates, sentence)
+		}
+	}
+	if len(candidates) < 2 {
+		return "", nil
+	}
+
+	frequency := make(map[string]float64)
+	for _, sentence := range candidates {
+		for _, word := range contentWords(sentence) {
+			frequency[word]++
+		}
+	}
+	titleWords := make(map[string]bool)
+	for _, word := range contentWords(input.Title + " " + input.Description) {
+		titleWords[word] = true
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	ranked := make([]scored, len(candidates))
+	for i, sentence := range candidates {
+		words := contentWords(sentence)
+		var score float64
+		for _, word := range words {
+			score += frequency[word]
+			if titleWords[word] {
+				score += 2
+			}
+		}
+		if len(words) > 0 {
+			score /= math.Sqrt(float64(len(words)))
+		}
+		if i < leadSentences {
+			score *= 1.5 - float64(i)*0.1
+		}
+		ranked[i] = scored{index: i, score: score}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	var picked []int
+	length := 0
+	for _, candidate := range ranked {
+		sentence := candidates[candidate.index]
+		if length+len(sentence) > maxSummaryLength && len(picked) > 0 {
+			continue
+		}
+		picked = append(picked, candidate.index)
+		length += len(sentence) + 1
+		if len(picked) == maxSentences {
+			break
+		}
+	}
+	sort.Ints(picked)
+
+	sentences := make([]string, len(picked))
+	for i, index := range picked {
+		sentences[i] = candidates[index]
+	}
+	return strings.Join(sentences, " "), ctx.Err()
+}
+
+// contentWords returns the lowercased words of text that carry meaning
+func contentWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) >= 4 && !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// stopWords are common words of four letters or more that say nothing about a story
+var stopWords = map[string]bool{
+	"that": true, "this": true, "with": true, "from": true, "have": true, "they": true,
+	"will": true, "what": true, "when": true, "were": true, "been": true, "their": true,
+	"there": true, "would": true, "could": true, "should": true, "about": true, "after": true,
+	"which": true, "said": true, "says": true, "also": true, "into": true, "than": true,
+	"more": true, "some": true, "other": true, "just": true, "over": true, "only": true,
+}
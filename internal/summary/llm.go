@@ -0,0 +1,46 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"open-news/internal/llm"
+)
+
+// llmPrompt asks for a plain-text summary
+const llmPrompt = `Summarize the news article below in two or three sentences of plain text, at most 60 words.
+State who did what, when and where. Use only facts stated in the article, without opinions, quotes or
+a heading.`
+
+// LLMSummarizer writes summaries with an OpenAI-compatible chat completions API
+type LLMSummarizer struct {
+	client *llm.Client
+}
+
+// NewLLMSummarizer creates a summarizer that calls the chat completions endpoint at url
+func NewLLMSummarizer(url, apiKey, model string) *LLMSummarizer {
+	return &LLMSummarizer{client: llm.NewClient(url, apiKey, model)}
+}
+
+// Name identifies the summarizer and model
+func (s *LLMSummarizer) Name() string {
+	return "llm:" + s.client.Model()
+}
+
+// Summarize sends the article to the API and returns its summary
+func (s *LLMSummarizer) Summarize(ctx context.Context, input Input) (string, error) {
+	text := strings.TrimSpace(input.Text)
+	if text == "" {
+		return "", nil
+	}
+
+	reply, err := s.client.Complete(ctx, llm.Prompt{
+		System: llmPrompt,
+		User:   llm.ArticleMessage(input.Title, text),
+	})
+	if err != nil {
+		return "", fmt.Errorf("summary %w", err)
+	}
+	return strings.Join(strings.Fields(reply), " "), nil
+}
@@ -0,0 +1,87 @@
+// Package summary writes short summaries of articles to show in feeds in place
+// of their meta descriptions
+package summary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"open-news/internal/llm"
+)
+
+// maxSentences is the most sentences a summary has
+const maxSentences = 3
+
+// Input is the article text a summarizer reads
+type Input struct {
+	Title       string
+	Description string
+	Text        string
+}
+
+// Summarizer writes a summary of two or three sentences
+type Summarizer interface {
+	// Name identifies the summarizer in logs
+	Name() string
+	// Summarize returns the summary, or an empty string when the article has
+	// too little text to summarize
+	Summarize(ctx context.Context, input Input) (string, error)
+}
+
+// Config selects and configures a summarizer, read from SUMMARY_* settings
+type Config struct {
+	Driver    string // SUMMARIZER: "extractive" (default), "llm" or "none"
+	LLMURL    string // SUMMARY_LLM_URL (default: OpenAI's chat completions endpoint)
+	LLMAPIKey string // SUMMARY_LLM_API_KEY
+	LLMModel  string // SUMMARY_LLM_MODEL
+}
+
+// defaultLLMURL is the chat completions endpoint used without SUMMARY_LLM_URL
+const defaultLLMURL = llm.DefaultURL
+
+// LoadConfig reads the summarizer settings from the environment
+func LoadConfig() Config {
+	config := Config{
+		Driver:    os.Getenv("SUMMARIZER"),
+		LLMURL:    os.Getenv("SUMMARY_LLM_URL"),
+		LLMAPIKey: os.Getenv("SUMMARY_LLM_API_KEY"),
+		LLMModel:  os.Getenv("SUMMARY_LLM_MODEL"),
+	}
+	if config.Driver == "" {
+		config.Driver = "extractive"
+	}
+	if config.LLMURL == "" {
+		config.LLMURL = defaultLLMURL
+	}
+	return config
+}
+
+// New creates the summarizer a config selects, or nil when summaries are turned off
+func New(config Config) (Summarizer, error) {
+	switch config.Driver {
+	case "none":
+		return nil, nil
+	case "extractive":
+		return NewExtractiveSummarizer(), nil
+	case "llm":
+		if config.LLMAPIKey == "" || config.LLMModel == "" {
+			return nil, fmt.Errorf("SUMMARY_LLM_API_KEY and SUMMARY_LLM_MODEL are required for the llm summarizer")
+		}
+		return NewLLMSummarizer(config.LLMURL, config.LLMAPIKey, config.LLMModel), nil
+	default:
+		return nil, fmt.Errorf("unknown SUMMARIZER %q", config.Driver)
+	}
+}
+
+// FromEnv creates the summarizer SUMMARY_* selects, falling back to the
+// extractive summarizer when the settings are invalid
+func FromEnv() Summarizer {
+	summarizer, err := New(LoadConfig())
+	if err != nil {
+		log.Printf("⚠️  %v, summarizing extractively", err)
+		return NewExtractiveSummarizer()
+	}
+	return summarizer
+}
@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleText = `Photo: Reuters. ` +
+	`The European Central Bank raised interest rates by a quarter point on Thursday, its tenth increase in a row. ` +
+	`President Christine Lagarde said the bank would keep rates high until inflation returns to its target. ` +
+	`Markets in Frankfurt rose slightly after the announcement, which economists had widely expected. ` +
+	`The weather in the city was mild for the time of year, with light winds from the west. ` +
+	`Several economists expect the central bank to pause its increases at the next meeting in December.`
+
+func TestExtractiveSummarizer(t *testing.T) {
+	text, err := NewExtractiveSummarizer().Summarize(context.Background(), Input{
+		Title: "ECB raises interest rates for the tenth time",
+		Text:  sampleText,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(text, "The European Central Bank raised interest rates"), "the lead is kept: %s", text)
+	assert.NotContains(t, text, "Photo: Reuters", "short fragments are skipped")
+	assert.NotContains(t, text, "weather", "off-topic sentences are left out")
+	assert.LessOrEqual(t, len(text), maxSummaryLength)
+	assert.Equal(t, 3, strings.Count(text, ". ")+1, "three sentences")
+}
+
+func TestExtractiveSummarizer_TooShort(t *testing.T) {
+	text, err := NewExtractiveSummarizer().Summarize(context.Background(), Input{Text: "Only one sentence of text is here for now."})
+	require.NoError(t, err)
+	assert.Empty(t, text)
+}
+
+func TestLLMSummarizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{
+				"role":    "assistant",
+				"content": "  The ECB raised rates.\n\nMore may follow.  ",
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	summarizer := NewLLMSummarizer(server.URL, "test-key", "test-model")
+	assert.Equal(t, "llm:test-model", summarizer.Name())
+
+	text, err := summarizer.Summarize(context.Background(), Input{Title: "Rates", Text: sampleText})
+	require.NoError(t, err)
+	assert.Equal(t, "The ECB raised rates. More may follow.", text)
+}
+
+func TestNew(t *testing.T) {
+	summarizer, err := New(Config{Driver: "extractive"})
+	require.NoError(t, err)
+	assert.Equal(t, "extractive", summarizer.Name())
+
+	summarizer, err = New(Config{Driver: "none"})
+	require.NoError(t, err)
+	assert.Nil(t, summarizer)
+
+	_, err = New(Config{Driver: "llm", LLMURL: defaultLLMURL})
+	assert.Error(t, err, "the llm summarizer needs a key and model")
+
+	_, err = New(Config{Driver: "magic"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+// Package topics assigns coarse topic slugs (tech, science, ...) to articles
+// using keyword matching over their metadata and text.
+package topics
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Topic is a subject area articles can be tagged with
+type Topic struct {
+	Slug     string   // Stored in articles.tags, e.g. "tech"
+	Name     string   // Human-readable name, e.g. "Technology"
+	Keywords []string // Lowercase words or phrases that indicate the topic
+}
+
+// All is the set of topics the classifier knows about
+var All = []Topic{
+	{
+		Slug: "tech",
+		Name: "Technology",
+		Keywords: []string{
+			"technology", "tech", "software", "startup", "startups", "silicon valley", "artificial intelligence",
+			"ai", "machine learning", "chatbot", "openai", "google", "microsoft", "amazon", "smartphone",
+			"iphone", "android", "apps", "cybersecurity", "hackers", "hack", "data breach",
+			"semiconductor", "chips", "nvidia", "cloud computing", "encryption", "programming", "developer",
+		},
+	},
+	{
+		Slug: "science",
+		Name: "Science",
+		Keywords: []string{
+			"science", "scientists", "scientist", "research", "researchers", "physics", "chemistry",
+			"biology", "astronomy", "nasa", "telescope", "planet", "galaxy", "species", "fossil",
+			"genome", "dna", "evolution", "quantum", "experiment", "peer reviewed", "journal nature",
+		},
+	},
+	{
+		Slug: "climate",
+		Name: "Climate",
+		Keywords: []string{
+			"climate", "climate change", "global warming", "emissions", "carbon", "greenhouse", "fossil fuels",
+			"renewable", "renewables", "solar", "wind power", "heatwave", "wildfire", "wildfires", "drought",
+			"flooding", "sea level", "cop28", "cop29", "cop30", "net zero",
+		},
+	},
+	{
+		Slug: "health",
+		Name: "Health",
+		Keywords: []string{
+			"health", "medical", "medicine", "doctors", "hospital", "hospitals", "patients", "disease",
+			"vaccine", "vaccines", "virus", "pandemic", "covid", "cancer", "fda", "cdc", "drug",
+			"drugs", "mental health", "public health", "clinical trial",
+		},
+	},
+	{
+		Slug: "politics",
+		Name: "Politics",
+		Keywords: []string{
+			"politics", "political", "election", "elections", "vote", "voters", "congress", "senate",
+			"parliament", "president", "prime minister", "governor", "democrats", "republicans", "campaign",
+			"legislation", "supreme court", "white house", "policy", "minister", "government",
+		},
+	},
+	{
+		Slug: "business",
+		Name: "Business",
+		Keywords: []string{
+			"business", "economy", "economic", "markets", "stocks", "stock market", "wall street", "investors",
+			"earnings", "revenue", "profit", "inflation", "interest rates", "federal reserve", "gdp",
+			"recession", "merger", "acquisition", "ceo", "trade", "tariffs", "jobs report",
+		},
+	},
+	{
+		Slug: "sports",
+		Name: "Sports",
+		Keywords: []string{
+			"sports", "football", "soccer", "basketball", "baseball", "hockey", "tennis", "golf", "olympics",
+			"nfl", "nba", "mlb", "nhl", "fifa", "world cup", "championship", "playoffs", "tournament", "league",
+		},
+	},
+}
+
+// Tuning for the keyword scorer
+const (
+	titleWeight       = 3.0
+	descriptionWeight = 2.0
+	sectionWeight     = 3.0
+	textWeight        = 0.5
+	minScore          = 4.0  // Minimum weighted hits before a topic is assigned
+	maxTopics         = 2    // Articles get at most this many topics
+	maxTextWords      = 1500 // Only the start of the body is scanned
+)
+
+// Input is the article content the classifier looks at
+type Input struct {
+	Title       string
+	Description string
+	Text        string
+	JSONLD      string // Raw JSON-LD; articleSection and keywords are used when present
+}
+
+// Get returns the topic with the given slug
+func Get(slug string) (Topic, bool) {
+	for _, topic := range All {
+		if topic.Slug == slug {
+			return topic, true
+		}
+	}
+	return Topic{}, false
+}
+
+// IsTopic reports whether a tag is one of the known topic slugs
+func IsTopic(tag string) bool {
+	_, ok := Get(tag)
+	return ok
+}
+
+// Classify returns the slugs of the topics that best match the input, strongest first
+func Classify(input Input) []string {
+	title := normalize(input.Title)
+	description := normalize(input.Description)
+	section := normalize(strings.Join(jsonLDSections(input.JSONLD), " "))
+	text := normalize(truncateWords(input.Text, maxTextWords))
+
+	type scored struct {
+		slug  string
+		score float64
+	}
+
+	var matches []scored
+	for _, topic := range All {
+		score := 0.0
+		for _, keyword := range topic.Keywords {
+			needle := " " + keyword + " "
+			score += titleWeight * float64(strings.Count(title, needle))
+			score += descriptionWeight * float64(strings.Count(description, needle))
+			score += sectionWeight * float64(strings.Count(section, needle))
+			score += textWeight * float64(strings.Count(text, needle))
+		}
+		if score >= minScore {
+			matches = append(matches, scored{topic.Slug, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if len(matches) > maxTopics {
+		matches = matches[:maxTopics]
+	}
+
+	slugs := make([]string, len(matches))
+	for i, match := range matches {
+		slugs[i] = match.slug
+	}
+	return slugs
+}
+
+// MergeTags replaces any topic slugs in tags with the given topics, keeping other tags
+func MergeTags(tags []string, topicSlugs []string) []string {
+	merged := make([]string, 0, len(tags)+len(topicSlugs))
+	for _, tag := range tags {
+		if !IsTopic(tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return append(merged, topicSlugs...)
+}
+
+// normalize lowercases text and collapses punctuation to single spaces, padding
+// both ends so keywords can be matched on word boundaries
+func normalize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) + 2)
+	b.WriteByte(' ')
+
+	lastSpace := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastSpace = false
+		} else if !lastSpace {
+			b.WriteByte(' ')
+			lastSpace = true
+		}
+	}
+
+	if !lastSpace {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// truncateWords returns at most maxWords words of text
+func truncateWords(text string, maxWords int) string {
+	words := strings.Fields(text)
+	if len(words) > maxWords {
+		words = words[:maxWords]
+	}
+	return strings.Join(words, " ")
+}
+
+// jsonLDSections pulls articleSection and keywords values out of raw JSON-LD
+func jsonLDSections(jsonLD string) []string {
+	if jsonLD == "" {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonLD), &data); err != nil {
+		return nil
+	}
+
+	var values []string
+	var walk func(interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if key == "articleSection" || key == "keywords" {
+					values = append(values, stringValues(value)...)
+				} else if key == "@graph" {
+					walk(value)
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(data)
+
+	return values
+}
+
+// stringValues flattens a JSON-LD value that may be a string or a list of strings
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(v, ",")
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package topics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Input
+		expected []string
+	}{
+		{
+			name: "tech headline",
+			input: Input{
+				Title:       "Nvidia unveils new AI chips as software makers race to keep up",
+				Description: "The semiconductor giant says demand for artificial intelligence hardware is still growing.",
+			},
+			expected: []string{"tech"},
+		},
+		{
+			name: "science from JSON-LD section",
+			input: Input{
+				Title:  "Telescope spots a distant galaxy",
+				JSONLD: `{"@type":"NewsArticle","articleSection":"Science"}`,
+			},
+			expected: []string{"science"},
+		},
+		{
+			name: "keywords inside @graph",
+			input: Input{
+				Title:  "What the drought means for farmers",
+				JSONLD: `{"@graph":[{"@type":"NewsArticle","keywords":["climate change","emissions"]}]}`,
+			},
+			expected: []string{"climate"},
+		},
+		{
+			name: "no strong signal",
+			input: Input{
+				Title:       "A quiet weekend in the village",
+				Description: "Residents enjoyed the sunshine.",
+			},
+			expected: []string{},
+		},
+		{
+			name: "keywords match whole words only",
+			input: Input{
+				Title: "Chairman said the aid package was fair",
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Classify(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Classify() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	result := MergeTags([]string{"breaking", "tech", "local"}, []string{"science"})
+	expected := []string{"breaking", "local", "science"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("MergeTags() = %v, expected %v", result, expected)
+	}
+}
@@ -0,0 +1,133 @@
+// Package tracing sets up OpenTelemetry tracing of HTTP requests, SQL queries,
+// outbound HTTP calls, background jobs and firehose links, exported to an
+// OTLP/HTTP collector. The SDK reads the standard environment variables, e.g.:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT         Collector base URL, e.g. http://localhost:4318
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT  Collector URL for traces only
+//	OTEL_EXPORTER_OTLP_HEADERS          Comma-separated key=value headers sent with every export
+//	OTEL_SERVICE_NAME                   Service name of the traces (default open-news)
+//	OTEL_RESOURCE_ATTRIBUTES            Extra resource attributes, e.g. deployment.environment=prod
+//	OTEL_TRACES_SAMPLER                 Sampler, e.g. parentbased_traceidratio (default parentbased_always_on)
+//	OTEL_TRACES_SAMPLER_ARG             The sampler's argument, e.g. 0.1 to record a tenth of new traces
+//	OTEL_SDK_DISABLED                   true turns tracing off
+//
+// Tracing is off when no endpoint is set, and spans then cost next to nothing.
+// Context is propagated with W3C traceparent and baggage headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the service without OTEL_SERVICE_NAME, and the
+// instrumentation the app's own spans come from
+const ServiceName = "open-news"
+
+// shutdownTimeout bounds sending the last spans on exit
+const shutdownTimeout = 5 * time.Second
+
+var (
+	providerMu sync.Mutex
+	provider   *sdktrace.TracerProvider // nil when tracing is off
+
+	tracer = otel.Tracer(ServiceName)
+)
+
+// Configured reports whether the environment names a collector to export to
+func Configured() bool {
+	if disabled, _ := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); disabled {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Init installs the W3C propagators and, when a collector is configured, a
+// tracer provider batching spans to it. Call Shutdown before exiting to send
+// the last spans.
+func Init() error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !Configured() {
+		return nil
+	}
+
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to describe the service: %w", err)
+	}
+
+	// The sampler is read from OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+
+	providerMu.Lock()
+	previous := provider
+	provider = tp
+	providerMu.Unlock()
+	if previous != nil {
+		shutdown(previous)
+	}
+	log.Printf("🔭 Exporting traces over OTLP/HTTP")
+	return nil
+}
+
+// Shutdown sends the spans not exported yet and stops exporting
+func Shutdown() {
+	providerMu.Lock()
+	tp := provider
+	provider = nil
+	providerMu.Unlock()
+	if tp != nil {
+		shutdown(tp)
+	}
+}
+
+// shutdown flushes and stops a tracer provider
+func shutdown(tp *sdktrace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  Failed to send the last traces: %v", err)
+	}
+}
+
+// Start starts an internal span, such as a job run, as a child of the span in
+// ctx or the root of a new trace. It returns a context carrying the span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends a span, marking it failed with err unless err is nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
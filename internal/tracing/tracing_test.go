@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConfigured(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_SDK_DISABLED", "")
+	assert.False(t, Configured())
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://localhost:4318/v1/traces")
+	assert.True(t, Configured())
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	assert.False(t, Configured())
+}
+
+func TestStartWithoutInit(t *testing.T) {
+	ctx, span := Start(context.Background(), "untraced")
+	assert.False(t, span.IsRecording())
+	assert.NotNil(t, ctx)
+
+	// An unrecorded span is safe to use
+	End(span, errors.New("failed"))
+}
+
+// collector is an OTLP/HTTP collector recording the spans it receives
+type collector struct {
+	mu      sync.Mutex
+	headers http.Header
+	spans   []*tracepb.Span
+	service string
+}
+
+func (col *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req coltracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	col.headers = r.Header.Clone()
+	for _, resource := range req.ResourceSpans {
+		for _, attr := range resource.Resource.Attributes {
+			if attr.Key == "service.name" {
+				col.service = attr.Value.GetStringValue()
+			}
+		}
+		for _, scope := range resource.ScopeSpans {
+			col.spans = append(col.spans, scope.Spans...)
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+func TestExport(t *testing.T) {
+	col := &collector{}
+	server := httptest.NewServer(col)
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "X-Api-Key=secret")
+	t.Setenv("OTEL_SERVICE_NAME", "open-news-test")
+	t.Setenv("OTEL_SDK_DISABLED", "")
+	require.NoError(t, Init())
+	defer Shutdown()
+
+	ctx, job := Start(context.Background(), "job update_metrics", attribute.String("job.type", "update_metrics"))
+	require.True(t, job.IsRecording())
+
+	// Outbound calls are children of the span in their request's context, and
+	// pass the trace on
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Regexp(t, `^00-`+job.SpanContext().TraceID().String()+`-[0-9a-f]{16}-01$`, r.Header.Get("traceparent"))
+	}))
+	defer downstream.Close()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL+"/xrpc/app.bsky.feed.getPosts", nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: otelhttp.NewTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	End(job, errors.New("boom"))
+	Shutdown()
+
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	assert.Equal(t, "secret", col.headers.Get("X-Api-Key"))
+	assert.Equal(t, "open-news-test", col.service)
+	require.Len(t, col.spans, 2)
+
+	clientSpan, jobSpan := col.spans[0], col.spans[1]
+	assert.Equal(t, "job update_metrics", jobSpan.Name)
+	assert.Empty(t, jobSpan.ParentSpanId, "a job without a parent starts a trace")
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, jobSpan.Status.Code)
+	assert.Equal(t, "boom", jobSpan.Status.Message)
+
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, clientSpan.Kind)
+	assert.Equal(t, jobSpan.SpanId, clientSpan.ParentSpanId)
+	assert.Equal(t, jobSpan.TraceId, clientSpan.TraceId)
+}
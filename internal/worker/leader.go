@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// defaultLeaderLockID is the Postgres advisory lock that the leader holds.
+// It spells "opennews" in ASCII.
+const defaultLeaderLockID int64 = 0x6f70656e6e657773
+
+// leaderElector elects one instance among the replicas that share a database.
+// The leader holds a session-level Postgres advisory lock on a dedicated
+// connection. The lock is released when that connection closes, so a crashed
+// leader is replaced once its connection drops.
+type leaderElector struct {
+	db       *sql.DB
+	lockID   int64
+	interval time.Duration // How often followers try for the lock and the leader checks its connection
+	leading  atomic.Bool
+}
+
+// newLeaderElector creates an elector for the lock in LEADER_LOCK_ID. Separate
+// deployments that share a database can use different locks.
+func newLeaderElector(db *sql.DB, interval time.Duration) *leaderElector {
+	lockID := defaultLeaderLockID
+	if value := os.Getenv("LEADER_LOCK_ID"); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			lockID = parsed
+		} else {
+			log.Printf("Invalid LEADER_LOCK_ID %q, using %d", value, lockID)
+		}
+	}
+	return &leaderElector{db: db, lockID: lockID, interval: interval}
+}
+
+// IsLeader reports whether this instance currently holds the lock
+func (e *leaderElector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// run tries for the lock until ctx is cancelled. While this instance holds it,
+// lead runs with a context that's cancelled when leadership is lost; lead must
+// return once that happens. This instance may be elected again later.
+func (e *leaderElector) run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		if err := e.campaign(ctx, lead); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️  Leader election failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// campaign tries once for the lock, and runs lead for as long as it's held
+func (e *leaderElector) campaign(ctx context.Context, lead func(ctx context.Context)) error {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.lockID).Scan(&acquired); err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+
+	log.Println("👑 Elected leader, starting singleton workers")
+	e.leading.Store(true)
+	defer e.leading.Store(false)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx)
+	}()
+
+	// The lock is only held while its connection is alive
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	var lost error
+	for lost == nil {
+		select {
+		case <-done:
+			lost = context.Canceled
+		case <-leaderCtx.Done():
+			lost = leaderCtx.Err()
+		case <-ticker.C:
+			if _, err := conn.ExecContext(leaderCtx, "SELECT 1"); err != nil {
+				lost = err
+			}
+		}
+	}
+	cancel()
+	<-done
+
+	if ctx.Err() == nil {
+		log.Printf("⚠️  Lost leadership (%v), stopped singleton workers", lost)
+	}
+
+	// Unlock for a quick handover. If that fails, the connection is discarded
+	// instead of going back to the pool, which releases the lock on the server.
+	unlockCtx, cancelUnlock := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelUnlock()
+	if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", e.lockID); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	return nil
+}
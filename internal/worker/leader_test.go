@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"open-news/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLeaderElector_LockID(t *testing.T) {
+	t.Setenv("LEADER_LOCK_ID", "")
+	assert.Equal(t, defaultLeaderLockID, newLeaderElector(nil, time.Second).lockID)
+
+	t.Setenv("LEADER_LOCK_ID", "42")
+	assert.Equal(t, int64(42), newLeaderElector(nil, time.Second).lockID)
+
+	t.Setenv("LEADER_LOCK_ID", "staging")
+	assert.Equal(t, defaultLeaderLockID, newLeaderElector(nil, time.Second).lockID)
+}
+
+func TestLeaderElector_SingleLeader(t *testing.T) {
+	os.Setenv("DB_USER", "mterenzi")
+	os.Setenv("DB_NAME", "open_news_test")
+	if err := database.Connect(database.LoadConfig()); err != nil {
+		t.Skipf("Skipping test - PostgreSQL test database not available: %v", err)
+	}
+	sqlDB, err := database.DB.DB()
+	require.NoError(t, err)
+
+	t.Setenv("LEADER_LOCK_ID", "7")
+	first := newLeaderElector(sqlDB, 50*time.Millisecond)
+	second := newLeaderElector(sqlDB, 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	firstCtx, stepDown := context.WithCancel(ctx)
+	led := make(chan string, 2)
+	go first.run(firstCtx, func(ctx context.Context) { led <- "first"; <-ctx.Done() })
+	require.Equal(t, "first", <-led)
+
+	go second.run(ctx, func(ctx context.Context) { led <- "second"; <-ctx.Done() })
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader(), "only one instance holds the lock")
+
+	// The other instance takes over once the leader stops
+	stepDown()
+	select {
+	case name := <-led:
+		assert.Equal(t, "second", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("leadership wasn't handed over")
+	}
+}
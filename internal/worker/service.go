@@ -2,14 +2,24 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"sync"
 	"time"
 
 	"open-news/internal/bluesky"
+	"open-news/internal/cache"
 	"open-news/internal/database"
+	"open-news/internal/errreport"
+	"open-news/internal/embeddings"
+	"open-news/internal/facts"
+	"open-news/internal/labeler"
+	"open-news/internal/mailer"
 	"open-news/internal/services"
+	"open-news/internal/summary"
 	"open-news/internal/workers"
 )
 
@@ -18,7 +28,19 @@ type WorkerService struct {
 	firehoseConsumer  *bluesky.FirehoseConsumer
 	blueskyClient     *bluesky.Client
 	followsWorker     *workers.FollowsRefreshWorker
+	profileWorker     *workers.ProfileEnrichmentWorker
+	scheduledWorkers  []*workers.ScheduledJobWorker // Digests, when configured
+	jobRunner         *workers.JobRunner
+	jobService        *services.JobService
 	userFollowsService *services.UserFollowsService
+	qualityScores     *services.QualityScoreService
+	scoreSweepInterval time.Duration // How often every score is recalculated rather than just the flagged ones
+	lastScoreSweep    time.Time
+	trackLikes        bool
+	embeddingsEnabled bool // EMBEDDINGS_PROVIDER is set
+	summariesEnabled  bool // SUMMARIZER isn't "none"
+	labelsEnabled     bool // LABELER_DID and LABELER_SIGNING_KEY are set
+	leader            *leaderElector // nil when every instance runs the singleton workers
 	ctx               context.Context
 	cancel            context.CancelFunc
 	wg                sync.WaitGroup
@@ -32,6 +54,7 @@ func NewWorkerService() *WorkerService {
 	
 	// Initialize Bluesky client
 	blueskyClient := bluesky.NewClient("https://bsky.social")
+	blueskyClient.SetCache(cache.Shared())
 	
 	// Authenticate with Bluesky if credentials are available
 	identifier := os.Getenv("BLUESKY_IDENTIFIER")
@@ -54,18 +77,138 @@ func NewWorkerService() *WorkerService {
 	// Initialize user follows service
 	userFollowsService := services.NewUserFollowsService(database.DB, blueskyClient)
 	
+	// Background tasks run as jobs, so failures are recorded and retried
+	jobService := services.NewJobService(database.DB)
+	jobRunner := workers.NewJobRunner(jobService, 10*time.Second)
+	
 	// Initialize follows refresh worker with 1 hour refresh interval
-	followsWorker := workers.NewFollowsRefreshWorker(userFollowsService, time.Hour)
+	followsWorker := workers.NewFollowsRefreshWorker(userFollowsService, jobService, time.Hour)
+	
+	// Initialize source profile enrichment, a batch every 15 minutes
+	profileWorker := workers.NewProfileEnrichmentWorker(services.NewSourceProfileService(database.DB, blueskyClient), jobService, 15*time.Minute)
 	
-	return &WorkerService{
+	// Post the top-stories digest from the bot account when enabled, and email
+	// digests to subscribers
+	var scheduledWorkers []*workers.ScheduledJobWorker
+	if digestWorker := newDigestWorker(blueskyClient, jobService); digestWorker != nil {
+		scheduledWorkers = append(scheduledWorkers, digestWorker)
+	}
+	if emailWorker := newEmailDigestWorker(jobService); emailWorker != nil {
+		scheduledWorkers = append(scheduledWorkers, emailWorker)
+	}
+	if retentionWorker := newRetentionWorker(jobService); retentionWorker != nil {
+		scheduledWorkers = append(scheduledWorkers, retentionWorker)
+	}
+	
+	ws := &WorkerService{
 		firehoseConsumer:   firehoseConsumer,
 		blueskyClient:      blueskyClient,
 		followsWorker:      followsWorker,
+		profileWorker:      profileWorker,
+		scheduledWorkers:   scheduledWorkers,
+		jobRunner:          jobRunner,
+		jobService:         jobService,
 		userFollowsService: userFollowsService,
+		qualityScores:      services.NewQualityScoreService(database.DB),
+		scoreSweepInterval: services.LoadScoreSweepInterval(),
+		trackLikes:         os.Getenv("JETSTREAM_LIKES_ENABLED") != "false",
+		leader:             newLeaderElectorFromEnv(),
 		ctx:                ctx,
 		cancel:             cancel,
 		running:            false,
 	}
+	jobService.Register(services.JobTypeUpdateMetrics, func(ctx context.Context, _ []byte) error {
+		return ws.updateMetrics(ctx)
+	})
+	// Backfills need the authenticated client for getAuthorFeed
+	articlesService := services.NewArticlesService(database.DB, blueskyClient)
+	jobService.Register(services.JobTypeBackfillSource, func(ctx context.Context, payload []byte) error {
+		var backfill services.BackfillSourcePayload
+		if err := json.Unmarshal(payload, &backfill); err != nil {
+			return fmt.Errorf("invalid backfill payload: %w", err)
+		}
+		return articlesService.WithContext(ctx).BackfillSource(ctx, backfill.SourceID)
+	})
+	jobService.Register(services.JobTypeRefetchArticle, func(ctx context.Context, payload []byte) error {
+		var refetch services.RefetchArticlePayload
+		if err := json.Unmarshal(payload, &refetch); err != nil {
+			return fmt.Errorf("invalid refetch payload: %w", err)
+		}
+		return articlesService.WithContext(ctx).RunRefetchJob(ctx, refetch)
+	})
+	verificationService := services.NewSourceVerificationService(database.DB)
+	jobService.Register(services.JobTypeVerifySources, func(ctx context.Context, _ []byte) error {
+		_, err := verificationService.WithContext(ctx).VerifyBatch(services.DefaultVerificationConfig())
+		return err
+	})
+	spamService := services.NewSpamService(database.DB)
+	jobService.Register(services.JobTypeCheckSpam, func(ctx context.Context, _ []byte) error {
+		_, err := spamService.WithContext(ctx).CheckRecent()
+		return err
+	})
+	factsService := services.NewFactsService(database.DB, facts.FromEnv())
+	jobService.Register(services.JobTypeExtractFacts, func(ctx context.Context, payload []byte) error {
+		var extract services.ExtractFactsPayload
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &extract); err != nil {
+				return fmt.Errorf("invalid fact extraction payload: %w", err)
+			}
+		}
+		return factsService.WithContext(ctx).RunJob(ctx, extract)
+	})
+	embeddingService := services.NewEmbeddingService(database.DB, embeddings.FromEnv())
+	jobService.Register(services.JobTypeEmbedArticles, func(ctx context.Context, _ []byte) error {
+		return embeddingService.WithContext(ctx).RunJob(ctx)
+	})
+	ws.embeddingsEnabled = embeddingService.Enabled()
+	summaryService := services.NewSummaryService(database.DB, summary.FromEnv())
+	jobService.Register(services.JobTypeSummarize, func(ctx context.Context, payload []byte) error {
+		var summarize services.SummarizeArticlesPayload
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &summarize); err != nil {
+				return fmt.Errorf("invalid summary payload: %w", err)
+			}
+		}
+		return summaryService.WithContext(ctx).RunJob(ctx, summarize)
+	})
+	ws.summariesEnabled = summaryService.Enabled()
+	breakingService := services.NewBreakingService(database.DB, services.LoadBreakingConfig())
+	jobService.Register(services.JobTypeDetectBreaking, func(ctx context.Context, _ []byte) error {
+		_, err := breakingService.WithContext(ctx).Detect()
+		return err
+	})
+	partitionService := services.NewPartitionService(database.DB)
+	jobService.Register(services.JobTypeMaintainPartitions, func(ctx context.Context, _ []byte) error {
+		_, err := partitionService.WithContext(ctx).Maintain(time.Now())
+		return err
+	})
+	if signer := labeler.FromEnv(); signer != nil {
+		labelService := services.NewLabelService(database.DB, signer)
+		jobService.Register(services.JobTypeSyncLabels, func(ctx context.Context, _ []byte) error {
+			_, err := labelService.WithContext(ctx).Sync()
+			return err
+		})
+		ws.labelsEnabled = true
+	}
+	siteFeedService := services.NewSiteFeedService(database.DB, services.LoadSiteFeedConfig())
+	jobService.Register(services.JobTypePollSiteFeeds, func(ctx context.Context, _ []byte) error {
+		_, err := siteFeedService.WithContext(ctx).PollDue(ctx, time.Now())
+		return err
+	})
+	accountService := services.NewUserAccountService(database.DB)
+	jobService.Register(services.JobTypeExportUserData, func(ctx context.Context, payload []byte) error {
+		var export services.ExportUserDataPayload
+		if err := json.Unmarshal(payload, &export); err != nil {
+			return fmt.Errorf("invalid export payload: %w", err)
+		}
+		return accountService.WithContext(ctx).RunExportJob(export)
+	})
+	preferencesService := services.NewPreferencesService(database.DB)
+	jobService.Register(services.JobTypeLearnTopics, func(ctx context.Context, _ []byte) error {
+		_, err := preferencesService.WithContext(ctx).LearnAllTopics()
+		return err
+	})
+	return ws
 }
 
 // Start starts all background workers
@@ -79,25 +222,25 @@ func (ws *WorkerService) Start() error {
 	
 	log.Println("Starting background workers...")
 	
-	// Start firehose consumer
+	// Start the job runner on every instance; each job is claimed by one of them
 	ws.wg.Add(1)
 	go func() {
 		defer ws.wg.Done()
-		ws.runFirehoseConsumer()
+		defer errreport.Crash(errreport.Tags{"worker": "jobs"})
+		ws.runJobRunner()
 	}()
 	
-	// Start follows refresh worker
+	// Start the singleton workers, which must only run on one instance at a time
 	ws.wg.Add(1)
 	go func() {
 		defer ws.wg.Done()
-		ws.runFollowsRefreshWorker()
-	}()
-	
-	// Start other workers here (article fetcher, feed generator, etc.)
-	ws.wg.Add(1)
-	go func() {
-		defer ws.wg.Done()
-		ws.runPeriodicTasks()
+		defer errreport.Crash(errreport.Tags{"worker": "leader"})
+		if ws.leader == nil {
+			ws.runSingletonWorkers(ws.ctx)
+			return
+		}
+		log.Println("Waiting for leader election before starting singleton workers...")
+		ws.leader.run(ws.ctx, ws.runSingletonWorkers)
 	}()
 	
 	ws.running = true
@@ -134,30 +277,86 @@ func (ws *WorkerService) IsRunning() bool {
 	return ws.running
 }
 
+// IsLeader returns whether this instance runs the singleton workers
+func (ws *WorkerService) IsLeader() bool {
+	return ws.leader == nil || ws.leader.IsLeader()
+}
+
+// newLeaderElectorFromEnv sets up leader election between replicas, unless
+// WORKER_LEADER_ELECTION=false runs the singleton workers on every instance
+func newLeaderElectorFromEnv() *leaderElector {
+	if os.Getenv("WORKER_LEADER_ELECTION") == "false" {
+		log.Println("💡 Leader election disabled, this instance runs all singleton workers")
+		return nil
+	}
+	if database.IsSQLite(database.DB) {
+		log.Println("💡 SQLite serves a single instance, which runs all singleton workers")
+		return nil
+	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		log.Printf("⚠️  Leader election unavailable, this instance runs all singleton workers: %v", err)
+		return nil
+	}
+	return newLeaderElector(sqlDB, 15*time.Second)
+}
+
+// runSingletonWorkers runs the workers that consume the firehose, refresh data
+// and regenerate feeds until ctx is cancelled. It can run again after returning,
+// so the workers are left to exit with ctx rather than being stopped.
+func (ws *WorkerService) runSingletonWorkers(ctx context.Context) {
+	var wg sync.WaitGroup
+	run := func(name string, worker func(ctx context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer errreport.Crash(errreport.Tags{"worker": name})
+			worker(ctx)
+		}()
+	}
+	
+	run("firehose", ws.runFirehoseConsumer)
+	
+	// Like counting reads every like on the network
+	if ws.trackLikes {
+		run("likes", ws.runLikesConsumer)
+	}
+	
+	run("follows_refresh", ws.runFollowsRefreshWorker)
+	run("profile_enrichment", ws.runProfileEnrichmentWorker)
+	for _, scheduled := range ws.scheduledWorkers {
+		run("scheduled", func(ctx context.Context) { ws.runScheduledWorker(ctx, scheduled) })
+	}
+	run("periodic_tasks", ws.runPeriodicTasks)
+	
+	wg.Wait()
+}
+
 // runFirehoseConsumer runs the Bluesky firehose consumer
-func (ws *WorkerService) runFirehoseConsumer() {
+func (ws *WorkerService) runFirehoseConsumer(ctx context.Context) {
 	log.Println("Starting Bluesky firehose consumer...")
 	
 	// Run with retry logic
 	for {
 		select {
-		case <-ws.ctx.Done():
+		case <-ctx.Done():
 			log.Println("Firehose consumer stopped")
 			return
 		default:
-			if err := ws.firehoseConsumer.StartConsuming(ws.ctx); err != nil {
-				if ws.ctx.Err() != nil {
+			if err := ws.firehoseConsumer.StartConsuming(ctx); err != nil {
+				if ctx.Err() != nil {
 					// Context was cancelled, this is expected
 					return
 				}
 				
 				log.Printf("Firehose consumer error: %v. Restarting in 30 seconds...", err)
+				errreport.Capture(err, errreport.Tags{"worker": "firehose"})
 				
 				// Wait before restarting
 				select {
 				case <-time.After(30 * time.Second):
 					continue
-				case <-ws.ctx.Done():
+				case <-ctx.Done():
 					return
 				}
 			}
@@ -165,36 +364,171 @@ func (ws *WorkerService) runFirehoseConsumer() {
 	}
 }
 
+// runLikesConsumer counts likes of tracked posts from Jetstream
+func (ws *WorkerService) runLikesConsumer(ctx context.Context) {
+	log.Println("Starting Bluesky likes consumer...")
+	
+	// Reconnects on its own until the context is cancelled
+	ws.firehoseConsumer.StartConsumingLikes(ctx)
+	log.Println("Likes consumer stopped")
+}
+
 // runFollowsRefreshWorker runs the follows refresh worker
-func (ws *WorkerService) runFollowsRefreshWorker() {
+func (ws *WorkerService) runFollowsRefreshWorker(ctx context.Context) {
 	log.Println("Starting follows refresh worker...")
 	
-	ws.followsWorker.Start(ws.ctx)
+	ws.followsWorker.Start(ctx)
+	
+	// Wait for context cancellation, which also stops the worker
+	<-ctx.Done()
+	
+	log.Println("Follows refresh worker stopped")
+}
+
+// runProfileEnrichmentWorker runs the source profile enrichment worker
+func (ws *WorkerService) runProfileEnrichmentWorker(ctx context.Context) {
+	ws.profileWorker.Start(ctx)
+	
+	// Wait for context cancellation, which also stops the worker
+	<-ctx.Done()
+}
+
+// runScheduledWorker runs a scheduled job worker
+func (ws *WorkerService) runScheduledWorker(ctx context.Context, scheduled *workers.ScheduledJobWorker) {
+	scheduled.Start(ctx)
+	
+	// Wait for context cancellation, which also stops the worker
+	<-ctx.Done()
+}
+
+// newDigestWorker sets up the digest worker from the DIGEST_* settings, returning
+// nil when the digest is disabled or can't be sent
+func newDigestWorker(blueskyClient *bluesky.Client, jobService *services.JobService) *workers.ScheduledJobWorker {
+	config := services.LoadDigestConfig()
+	if !config.Enabled {
+		return nil
+	}
+	if !blueskyClient.IsAuthenticated() {
+		log.Printf("⚠️  DIGEST_ENABLED is set but the Bluesky client isn't signed in; set BLUESKY_IDENTIFIER and BLUESKY_PASSWORD")
+		return nil
+	}
+
+	schedule, err := workers.ParseSchedule(config.Schedule)
+	if err != nil {
+		log.Printf("⚠️  Invalid DIGEST_SCHEDULE, digest disabled: %v", err)
+		return nil
+	}
+	digestService, err := services.NewDigestService(database.DB, blueskyClient, config)
+	if err != nil {
+		log.Printf("⚠️  Digest disabled: %v", err)
+		return nil
+	}
+	jobService.Register(services.JobTypeSendDigest, func(ctx context.Context, _ []byte) error {
+		return digestService.WithContext(ctx).Send(time.Now())
+	})
+	return workers.NewScheduledJobWorker("digest", services.JobTypeSendDigest, jobService, schedule, config.Location)
+}
+
+// newEmailDigestWorker sets up the email digest worker, which runs on
+// EMAIL_DIGEST_SCHEDULE (UTC) and mails subscribers whose digest is due. It
+// returns nil when the mail settings are invalid.
+func newEmailDigestWorker(jobService *services.JobService) *workers.ScheduledJobWorker {
+	expr := os.Getenv("EMAIL_DIGEST_SCHEDULE")
+	if expr == "" {
+		expr = "0 7 * * *"
+	}
+	schedule, err := workers.ParseSchedule(expr)
+	if err != nil {
+		log.Printf("⚠️  Invalid EMAIL_DIGEST_SCHEDULE, email digests disabled: %v", err)
+		return nil
+	}
+	m, err := mailer.New(mailer.LoadConfig())
+	if err != nil {
+		log.Printf("⚠️  Email digests disabled: %v", err)
+		return nil
+	}
+
+	emailDigestService := services.NewEmailDigestService(database.DB, m)
+	jobService.Register(services.JobTypeSendEmailDigests, func(ctx context.Context, _ []byte) error {
+		_, err := emailDigestService.WithContext(ctx).SendDue(time.Now())
+		return err
+	})
+	return workers.NewScheduledJobWorker("email digest", services.JobTypeSendEmailDigests, jobService, schedule, time.UTC)
+}
+
+// newRetentionWorker sets up the retention worker, which runs on
+// RETENTION_SCHEDULE (UTC, nightly by default) and purges data past the
+// retention periods. It returns nil when the schedule is invalid.
+func newRetentionWorker(jobService *services.JobService) *workers.ScheduledJobWorker {
+	expr := os.Getenv("RETENTION_SCHEDULE")
+	if expr == "" {
+		expr = "0 3 * * *"
+	}
+	schedule, err := workers.ParseSchedule(expr)
+	if err != nil {
+		log.Printf("⚠️  Invalid RETENTION_SCHEDULE, retention disabled: %v", err)
+		return nil
+	}
+
+	retentionService := services.NewRetentionService(database.DB, services.LoadRetentionConfig())
+	jobService.Register(services.JobTypeApplyRetention, func(ctx context.Context, _ []byte) error {
+		_, err := retentionService.WithContext(ctx).Run()
+		return err
+	})
+	return workers.NewScheduledJobWorker("retention", services.JobTypeApplyRetention, jobService, schedule, time.UTC)
+}
+
+// runJobRunner runs the job runner
+func (ws *WorkerService) runJobRunner() {
+	ws.jobRunner.Start(ws.ctx)
 	
 	// Wait for context cancellation
 	<-ws.ctx.Done()
 	
-	log.Println("Stopping follows refresh worker...")
-	ws.followsWorker.Stop()
-	log.Println("Follows refresh worker stopped")
+	ws.jobRunner.Stop()
 }
 
 // runPeriodicTasks runs periodic maintenance tasks
-func (ws *WorkerService) runPeriodicTasks() {
+func (ws *WorkerService) runPeriodicTasks(ctx context.Context) {
 	log.Println("Starting periodic tasks worker...")
 	
 	// Create tickers for different tasks
 	feedUpdateTicker := time.NewTicker(5 * time.Minute)   // Update feeds every 5 minutes
 	cleanupTicker := time.NewTicker(1 * time.Hour)       // Cleanup tasks every hour
 	metricsTicker := time.NewTicker(15 * time.Minute)    // Update metrics every 15 minutes
+	verifyTicker := time.NewTicker(1 * time.Hour)        // Verify a batch of sources every hour
+	spamTicker := time.NewTicker(15 * time.Minute)       // Check active sources for spam every 15 minutes
+	factsTicker := time.NewTicker(10 * time.Minute)      // Extract facts from new articles every 10 minutes
+	embedTicker := time.NewTicker(10 * time.Minute)      // Embed new articles every 10 minutes, when enabled
+	summaryTicker := time.NewTicker(10 * time.Minute)    // Summarize new articles every 10 minutes, when enabled
+	topicsTicker := time.NewTicker(6 * time.Hour)        // Relearn users' topic preferences every 6 hours
+	breakingTicker := time.NewTicker(2 * time.Minute)    // Look for breaking stories every 2 minutes
+	partitionTicker := time.NewTicker(24 * time.Hour)    // Create upcoming table partitions daily
+	labelTicker := time.NewTicker(5 * time.Minute)       // Issue labeler labels every 5 minutes, when enabled
+	siteFeedTicker := time.NewTicker(15 * time.Minute)   // Poll the feeds of sites due a poll every 15 minutes
 	
 	defer feedUpdateTicker.Stop()
 	defer cleanupTicker.Stop()
 	defer metricsTicker.Stop()
+	defer verifyTicker.Stop()
+	defer spamTicker.Stop()
+	defer factsTicker.Stop()
+	defer embedTicker.Stop()
+	defer summaryTicker.Stop()
+	defer topicsTicker.Stop()
+	defer breakingTicker.Stop()
+	defer partitionTicker.Stop()
+	defer labelTicker.Stop()
+	defer siteFeedTicker.Stop()
+	
+	// Partitions for the current month have to exist before anything is written
+	if err := ws.jobService.Run(ctx, services.JobTypeMaintainPartitions, nil); err != nil {
+		log.Printf("Partition maintenance failed: %v", err)
+	}
 	
 	for {
 		select {
-		case <-ws.ctx.Done():
+		case <-ctx.Done():
 			log.Println("Periodic tasks worker stopped")
 			return
 			
@@ -205,7 +539,68 @@ func (ws *WorkerService) runPeriodicTasks() {
 			ws.runCleanupTasks()
 			
 		case <-metricsTicker.C:
-			ws.updateMetrics()
+			if err := ws.jobService.Run(ctx, services.JobTypeUpdateMetrics, nil); err != nil {
+				log.Printf("Metrics update failed: %v", err)
+			}
+			
+		case <-verifyTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeVerifySources, nil); err != nil {
+				log.Printf("Source verification failed: %v", err)
+			}
+			
+		case <-spamTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeCheckSpam, nil); err != nil {
+				log.Printf("Spam check failed: %v", err)
+			}
+			
+		case <-factsTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeExtractFacts, nil); err != nil {
+				log.Printf("Fact extraction failed: %v", err)
+			}
+			
+		case <-embedTicker.C:
+			if !ws.embeddingsEnabled {
+				continue
+			}
+			if err := ws.jobService.Run(ctx, services.JobTypeEmbedArticles, nil); err != nil {
+				log.Printf("Article embedding failed: %v", err)
+			}
+			
+		case <-summaryTicker.C:
+			if !ws.summariesEnabled {
+				continue
+			}
+			if err := ws.jobService.Run(ctx, services.JobTypeSummarize, nil); err != nil {
+				log.Printf("Article summaries failed: %v", err)
+			}
+			
+		case <-topicsTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeLearnTopics, nil); err != nil {
+				log.Printf("Topic preference learning failed: %v", err)
+			}
+			
+		case <-breakingTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeDetectBreaking, nil); err != nil {
+				log.Printf("Breaking news detection failed: %v", err)
+			}
+			
+		case <-partitionTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypeMaintainPartitions, nil); err != nil {
+				log.Printf("Partition maintenance failed: %v", err)
+			}
+			
+		case <-labelTicker.C:
+			if !ws.labelsEnabled {
+				continue
+			}
+			if err := ws.jobService.Run(ctx, services.JobTypeSyncLabels, nil); err != nil {
+				log.Printf("Label sync failed: %v", err)
+			}
+			
+		case <-siteFeedTicker.C:
+			if err := ws.jobService.Run(ctx, services.JobTypePollSiteFeeds, nil); err != nil {
+				log.Printf("Site feed polling failed: %v", err)
+			}
 		}
 	}
 }
@@ -229,28 +624,50 @@ func (ws *WorkerService) runCleanupTasks() {
 	log.Println("Running cleanup tasks...")
 	
 	// TODO: Implement cleanup logic
-	// This would:
-	// 1. Remove old feed items beyond retention period
-	// 2. Clean up cached article content that's too old
-	// 3. Update source quality scores
-	// 4. Archive old engagement data
+	// Old feed items and cached article content are handled nightly by the
+	// retention worker. This would:
+	// 1. Update source quality scores
+	// 2. Archive old engagement data
 	
 	log.Println("Cleanup tasks completed")
 }
 
-// updateMetrics updates various application metrics
-func (ws *WorkerService) updateMetrics() {
+// updateMetrics updates various application metrics, running its queries with
+// ctx
+func (ws *WorkerService) updateMetrics(ctx context.Context) error {
 	log.Println("Updating metrics...")
 	
-	// Initialize quality score service
-	qualityService := services.NewQualityScoreService(database.DB)
+	// Rescore the articles and sources flagged since the last run, and
+	// everything now and then in case a change wasn't flagged
+	var failures []error
+	qualityScores := ws.qualityScores.WithContext(ctx)
+	if time.Since(ws.lastScoreSweep) >= ws.scoreSweepInterval {
+		if err := qualityScores.UpdateAllQualityScores(); err != nil {
+			log.Printf("Failed to update quality scores: %v", err)
+			failures = append(failures, fmt.Errorf("failed to update quality scores: %w", err))
+		} else {
+			ws.lastScoreSweep = time.Now()
+		}
+	} else if err := qualityScores.UpdateChangedQualityScores(); err != nil {
+		log.Printf("Failed to update changed quality scores: %v", err)
+		failures = append(failures, fmt.Errorf("failed to update changed quality scores: %w", err))
+	}
+	
+	// Snapshot the new scores of recent articles for their score history
+	if _, err := services.NewScoreHistoryService(database.DB.WithContext(ctx)).Record(time.Now()); err != nil {
+		log.Printf("Failed to record score history: %v", err)
+		failures = append(failures, err)
+	}
 	
-	// Update all quality scores
-	if err := qualityService.UpdateAllQualityScores(); err != nil {
-		log.Printf("Failed to update quality scores: %v", err)
+	// Tag any articles that haven't been through topic classification yet
+	topicsService := services.NewTopicsService(database.DB.WithContext(ctx))
+	if _, err := topicsService.ClassifyUnclassifiedArticles(500); err != nil {
+		log.Printf("Failed to classify article topics: %v", err)
+		failures = append(failures, fmt.Errorf("failed to classify article topics: %w", err))
 	}
 	
 	log.Println("Metrics update completed")
+	return errors.Join(failures...)
 }
 
 // Graceful shutdown helpers
@@ -270,11 +687,18 @@ func (ws *WorkerService) GetStatus() map[string]interface{} {
 	
 	status := map[string]interface{}{
 		"running":           ws.running,
+		"leader_election":   ws.leader != nil,
+		"leader":            ws.IsLeader(),
 		"firehose_enabled":  true,
 		"periodic_tasks":    true,
 		"uptime":           time.Since(time.Now()), // This would be tracked properly in a real implementation
 	}
 	
+	// Add how the Jetstream consumer is keeping up while it's consuming
+	if ingest := ws.firehoseConsumer.IngestStats(); ingest != nil {
+		status["firehose"] = ingest
+	}
+
 	// Add follows worker statistics if available
 	if ws.followsWorker != nil {
 		followsStats, err := ws.followsWorker.GetStats()
@@ -5,37 +5,42 @@ import (
 	"log"
 	"time"
 
+	"open-news/internal/pool"
 	"open-news/internal/services"
 )
 
 // FollowsRefreshWorker handles periodic refresh of user follows
 type FollowsRefreshWorker struct {
 	followsService *services.UserFollowsService
+	jobs           *services.JobService
 	config         services.RefreshConfig
 	ticker         *time.Ticker
 	stopChan       chan bool
 }
 
 // NewFollowsRefreshWorker creates a new follows refresh worker
-func NewFollowsRefreshWorker(followsService *services.UserFollowsService, refreshInterval time.Duration) *FollowsRefreshWorker {
-	return &FollowsRefreshWorker{
-		followsService: followsService,
-		config: services.RefreshConfig{
-			RefreshInterval: refreshInterval,
-			BatchSize:       10,
-			RateLimit:       time.Second,
-		},
-		stopChan: make(chan bool),
-	}
+func NewFollowsRefreshWorker(followsService *services.UserFollowsService, jobs *services.JobService, refreshInterval time.Duration) *FollowsRefreshWorker {
+	return NewFollowsRefreshWorkerWithConfig(followsService, jobs, services.RefreshConfig{
+		RefreshInterval: refreshInterval,
+		BatchSize:       10,
+		RateLimit:       time.Second,
+		Concurrency:     pool.Shared().PerDomain,
+	})
 }
 
-// NewFollowsRefreshWorkerWithConfig creates a worker with custom config
-func NewFollowsRefreshWorkerWithConfig(followsService *services.UserFollowsService, config services.RefreshConfig) *FollowsRefreshWorker {
-	return &FollowsRefreshWorker{
+// NewFollowsRefreshWorkerWithConfig creates a worker with custom config. Each batch
+// runs as a refresh_follows job, so failed batches are recorded and retried.
+func NewFollowsRefreshWorkerWithConfig(followsService *services.UserFollowsService, jobs *services.JobService, config services.RefreshConfig) *FollowsRefreshWorker {
+	w := &FollowsRefreshWorker{
 		followsService: followsService,
+		jobs:           jobs,
 		config:         config,
 		stopChan:       make(chan bool),
 	}
+	jobs.Register(services.JobTypeRefreshFollows, func(ctx context.Context, _ []byte) error {
+		return w.followsService.WithContext(ctx).RefreshBatch(w.config)
+	})
+	return w
 }
 
 // Start begins the periodic refresh process
@@ -50,7 +55,7 @@ func (w *FollowsRefreshWorker) Start(ctx context.Context) {
 
 	// Run an initial check immediately
 	go func() {
-		if err := w.followsService.RefreshBatch(w.config); err != nil {
+		if err := w.jobs.Run(ctx, services.JobTypeRefreshFollows, nil); err != nil {
 			log.Printf("❌ Error in initial follows refresh: %v", err)
 		}
 	}()
@@ -66,7 +71,7 @@ func (w *FollowsRefreshWorker) Start(ctx context.Context) {
 				log.Printf("🛑 Follows refresh worker stopping")
 				return
 			case <-w.ticker.C:
-				if err := w.followsService.RefreshBatch(w.config); err != nil {
+				if err := w.jobs.Run(ctx, services.JobTypeRefreshFollows, nil); err != nil {
 					log.Printf("❌ Error in periodic follows refresh: %v", err)
 				}
 			}
@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"open-news/internal/errreport"
+	"open-news/internal/services"
+)
+
+const (
+	// jobRunnerBatchSize is how many due jobs are claimed per poll
+	jobRunnerBatchSize = 10
+
+	// jobRetention is how long succeeded jobs are kept for inspection
+	jobRetention = 7 * 24 * time.Hour
+)
+
+// JobRunner polls the jobs table and runs retries and queued jobs as they come due
+type JobRunner struct {
+	jobs     *services.JobService
+	interval time.Duration
+	stopChan chan bool
+}
+
+// NewJobRunner creates a runner that checks for due jobs every interval
+func NewJobRunner(jobs *services.JobService, interval time.Duration) *JobRunner {
+	return &JobRunner{
+		jobs:     jobs,
+		interval: interval,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins polling for due jobs
+func (r *JobRunner) Start(ctx context.Context) {
+	log.Printf("🔄 Starting job runner (every %v)", r.interval)
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		pruneTicker := time.NewTicker(time.Hour)
+		defer pruneTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("🛑 Job runner stopping due to context cancellation")
+				return
+			case <-r.stopChan:
+				log.Printf("🛑 Job runner stopping")
+				return
+			case <-ticker.C:
+				r.runDue(ctx)
+			case <-pruneTicker.C:
+				r.prune()
+			}
+		}
+	}()
+}
+
+// runDue runs due jobs until none are left or the batch comes back short. Each
+// job is traced on its own, and stops with ctx.
+func (r *JobRunner) runDue(ctx context.Context) {
+	for {
+		count, err := r.jobs.RunDue(ctx, jobRunnerBatchSize)
+		if err != nil {
+			log.Printf("❌ Error running due jobs: %v", err)
+			errreport.Capture(err, errreport.Tags{"worker": "jobs"})
+			return
+		}
+		if count < jobRunnerBatchSize {
+			return
+		}
+	}
+}
+
+// prune deletes succeeded jobs past the retention period
+func (r *JobRunner) prune() {
+	deleted, err := r.jobs.PruneSucceeded(time.Now().Add(-jobRetention))
+	if err != nil {
+		log.Printf("❌ Error pruning jobs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Pruned %d succeeded jobs", deleted)
+	}
+}
+
+// Stop stops the runner
+func (r *JobRunner) Stop() {
+	close(r.stopChan)
+	log.Printf("✅ Job runner stopped")
+}
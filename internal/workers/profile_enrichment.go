@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"open-news/internal/services"
+)
+
+// ProfileEnrichmentWorker periodically refreshes source profiles (follower counts, bios, labels)
+type ProfileEnrichmentWorker struct {
+	profileService *services.SourceProfileService
+	jobs           *services.JobService
+	config         services.EnrichmentConfig
+	interval       time.Duration
+	stopChan       chan bool
+}
+
+// NewProfileEnrichmentWorker creates a worker that enriches a batch of sources every
+// interval, each batch running as an enrich_profiles job
+func NewProfileEnrichmentWorker(profileService *services.SourceProfileService, jobs *services.JobService, interval time.Duration) *ProfileEnrichmentWorker {
+	w := &ProfileEnrichmentWorker{
+		profileService: profileService,
+		jobs:           jobs,
+		config:         services.DefaultEnrichmentConfig(),
+		interval:       interval,
+		stopChan:       make(chan bool),
+	}
+	jobs.Register(services.JobTypeEnrichProfiles, func(ctx context.Context, _ []byte) error {
+		_, err := w.profileService.WithContext(ctx).EnrichBatch(w.config)
+		return err
+	})
+	return w
+}
+
+// Start begins the periodic enrichment process
+func (w *ProfileEnrichmentWorker) Start(ctx context.Context) {
+	log.Printf("🔄 Starting profile enrichment worker (every %v)", w.interval)
+	log.Printf("   📅 Refresh interval: %v", w.config.RefreshInterval)
+	log.Printf("   📦 Batch size: %d sources", w.config.BatchSize)
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.run(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("🛑 Profile enrichment worker stopping due to context cancellation")
+				return
+			case <-w.stopChan:
+				log.Printf("🛑 Profile enrichment worker stopping")
+				return
+			case <-ticker.C:
+				w.run(ctx)
+			}
+		}
+	}()
+}
+
+// run enriches one batch of sources
+func (w *ProfileEnrichmentWorker) run(ctx context.Context) {
+	if err := w.jobs.Run(ctx, services.JobTypeEnrichProfiles, nil); err != nil {
+		log.Printf("❌ Error in profile enrichment: %v", err)
+	}
+}
+
+// Stop stops the worker
+func (w *ProfileEnrichmentWorker) Stop() {
+	close(w.stopChan)
+	log.Printf("✅ Profile enrichment worker stopped")
+}
@@ -0,0 +1,149 @@
+package workers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression: minute, hour, day of month, month and
+// day of week, each a *, a value, a range (a-b), a step (*/n or a-b/n) or a
+// comma-separated list of those. @hourly, @daily and @weekly are accepted too.
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// Cron matches either day field when both are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+// scheduleAliases are the named schedules ParseSchedule accepts
+var scheduleAliases = map[string]string{
+	"@hourly": "0 * * * *",
+	"@daily":  "0 0 * * *",
+	"@weekly": "0 0 * * 0",
+}
+
+// ParseSchedule parses a five-field cron expression
+func ParseSchedule(expr string) (*Schedule, error) {
+	if alias, ok := scheduleAliases[strings.TrimSpace(expr)]; ok {
+		expr = alias
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q must have 5 fields (minute hour day month weekday)", expr)
+	}
+
+	s := &Schedule{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	bounds := []struct {
+		target   *uint64
+		min, max int
+	}{
+		{&s.minutes, 0, 59},
+		{&s.hours, 0, 23},
+		{&s.days, 1, 31},
+		{&s.months, 1, 12},
+		{&s.weekdays, 0, 7},
+	}
+	for i, field := range fields {
+		bits, err := parseScheduleField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", expr, err)
+		}
+		*bounds[i].target = bits
+	}
+	// 7 is another name for Sunday
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+	return s, nil
+}
+
+// parseScheduleField returns the set of values a field matches as a bit set
+func parseScheduleField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if step > 1 {
+				high = max // a/n means from a to the end
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t that the schedule matches, in t's location
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Every valid schedule matches within a few years (Feb 29 at worst)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay checks the day of month and day of week fields
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
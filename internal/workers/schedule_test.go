@@ -0,0 +1,53 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "0 8 * *", "60 * * * *", "0 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"0 8 * * *", time.Date(2026, 3, 5, 8, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 9, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"30 7 * * 1,5", time.Date(2026, 3, 6, 7, 30, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{"0 6 15 * 4", time.Date(2026, 3, 5, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(from))
+		})
+	}
+}
+
+func TestSchedule_NextSkipsCurrentMinute(t *testing.T) {
+	schedule, err := ParseSchedule("0 8 * * *")
+	require.NoError(t, err)
+
+	at := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, at.AddDate(0, 0, 1), schedule.Next(at))
+}
@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"open-news/internal/services"
+)
+
+// ScheduledJobWorker runs a job on a cron-style schedule, e.g. the daily digests
+type ScheduledJobWorker struct {
+	name     string
+	jobType  string
+	jobs     *services.JobService
+	schedule *Schedule
+	location *time.Location
+	stopChan chan bool
+}
+
+// NewScheduledJobWorker creates a worker that runs a job of jobType, whose handler
+// must be registered with jobs, each time the schedule matches in location
+func NewScheduledJobWorker(name, jobType string, jobs *services.JobService, schedule *Schedule, location *time.Location) *ScheduledJobWorker {
+	return &ScheduledJobWorker{
+		name:     name,
+		jobType:  jobType,
+		jobs:     jobs,
+		schedule: schedule,
+		location: location,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start waits for each scheduled time and runs the job
+func (w *ScheduledJobWorker) Start(ctx context.Context) {
+	go func() {
+		for {
+			next := w.schedule.Next(time.Now().In(w.location))
+			if next.IsZero() {
+				log.Printf("⚠️  %s schedule never matches, worker stopping", w.name)
+				return
+			}
+			log.Printf("📅 Next %s at %s", w.name, next.Format(time.RFC1123))
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Printf("🛑 %s worker stopping due to context cancellation", w.name)
+				return
+			case <-w.stopChan:
+				timer.Stop()
+				log.Printf("🛑 %s worker stopping", w.name)
+				return
+			case <-timer.C:
+				w.run(ctx)
+			}
+		}
+	}()
+}
+
+// run runs the job once
+func (w *ScheduledJobWorker) run(ctx context.Context) {
+	if err := w.jobs.Run(ctx, w.jobType, nil); err != nil {
+		log.Printf("❌ Error running %s: %v", w.name, err)
+	}
+}
+
+// Stop stops the worker
+func (w *ScheduledJobWorker) Stop() {
+	close(w.stopChan)
+	log.Printf("✅ %s worker stopped", w.name)
+}
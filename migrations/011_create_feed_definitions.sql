@@ -0,0 +1,26 @@
+-- Create feed_definitions table
+-- Maps Bluesky feed generator record keys (rkeys) to feed builders and their parameters
+
+CREATE TABLE IF NOT EXISTS feed_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rkey TEXT NOT NULL,
+    display_name TEXT NOT NULL,
+    description TEXT,
+    avatar TEXT,
+    builder TEXT NOT NULL,
+    topic TEXT,
+    language TEXT,
+    time_window_hours BIGINT DEFAULT 0,
+    min_quality_score NUMERIC DEFAULT 0.0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feed_definitions_rkey ON feed_definitions(rkey);
+
+-- Seed the feeds that were previously hardcoded
+INSERT INTO feed_definitions (rkey, display_name, description, builder) VALUES
+    ('open-news-global', 'Open News - Global', 'Top stories from across the Bluesky network, ranked by engagement and quality.', 'global'),
+    ('open-news-personal', 'Open News - Personal', 'Personalized news feed based on accounts you follow on Bluesky.', 'personalized')
+ON CONFLICT (rkey) DO NOTHING;
@@ -0,0 +1,15 @@
+-- Register per-topic global feeds
+-- Articles are tagged with topic slugs in articles.tags by the topic classifier
+
+INSERT INTO feed_definitions (rkey, display_name, description, builder, topic) VALUES
+    ('open-news-tech', 'Open News - Technology', 'Top technology stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'tech'),
+    ('open-news-science', 'Open News - Science', 'Top science stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'science'),
+    ('open-news-climate', 'Open News - Climate', 'Top climate stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'climate'),
+    ('open-news-health', 'Open News - Health', 'Top health stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'health'),
+    ('open-news-politics', 'Open News - Politics', 'Top politics stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'politics'),
+    ('open-news-business', 'Open News - Business', 'Top business stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'business'),
+    ('open-news-sports', 'Open News - Sports', 'Top sports stories shared across the Bluesky network, ranked by engagement and quality.', 'global', 'sports')
+ON CONFLICT (rkey) DO NOTHING;
+
+-- Speed up topic filtering on articles.tags
+CREATE INDEX IF NOT EXISTS idx_articles_tags ON articles USING GIN (tags);
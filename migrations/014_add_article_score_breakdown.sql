@@ -0,0 +1,4 @@
+-- Record how each article's quality and trending scores were calculated
+-- Stored as JSON text so the score-breakdown API can explain rankings after the fact
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS score_breakdown TEXT;
@@ -0,0 +1,5 @@
+-- Let each feed definition choose a ranking strategy
+-- Empty (or 'default') keeps ordering by the stored quality and trending scores;
+-- 'engagement', 'editorial' and 'follow-graph' rerank candidates when the feed is built
+
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS ranker TEXT;
@@ -0,0 +1,19 @@
+-- Create clicks table
+-- Records readers following feed items through the /r/:article_id redirect
+
+CREATE TABLE IF NOT EXISTS clicks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    article_id UUID NOT NULL,
+    source_id UUID,
+    feed TEXT,
+    surface TEXT,
+    position INTEGER DEFAULT 0,
+    referrer TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_clicks_article_id ON clicks(article_id);
+CREATE INDEX IF NOT EXISTS idx_clicks_source_id ON clicks(source_id);
+CREATE INDEX IF NOT EXISTS idx_clicks_feed ON clicks(feed);
+CREATE INDEX IF NOT EXISTS idx_clicks_created_at ON clicks(created_at);
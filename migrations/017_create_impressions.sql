@@ -0,0 +1,21 @@
+-- Create impressions table
+-- Records feed items served by getFeedSkeleton and the web feeds. Anonymous requests
+-- are sampled at IMPRESSION_SAMPLE_RATE; sample_rate keeps estimates unbiased.
+
+CREATE TABLE IF NOT EXISTS impressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    article_id UUID NOT NULL,
+    source_id UUID,
+    user_id UUID,
+    feed TEXT,
+    surface TEXT,
+    position INTEGER DEFAULT 0,
+    sample_rate NUMERIC DEFAULT 1.0,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impressions_article_id ON impressions(article_id);
+CREATE INDEX IF NOT EXISTS idx_impressions_source_id ON impressions(source_id);
+CREATE INDEX IF NOT EXISTS idx_impressions_user_id ON impressions(user_id);
+CREATE INDEX IF NOT EXISTS idx_impressions_feed ON impressions(feed);
+CREATE INDEX IF NOT EXISTS idx_impressions_created_at ON impressions(created_at);
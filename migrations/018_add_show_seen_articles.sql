@@ -0,0 +1,7 @@
+-- Let users opt out of seen-article filtering in personal feeds
+-- user_feed_preferences is created by AutoMigrate, so only alter it if it exists
+
+ALTER TABLE IF EXISTS user_feed_preferences ADD COLUMN IF NOT EXISTS show_seen_articles BOOLEAN DEFAULT FALSE;
+
+-- Seen filtering looks up a user's recent impressions per feed
+CREATE INDEX IF NOT EXISTS idx_impressions_user_feed_created ON impressions(user_id, feed, created_at);
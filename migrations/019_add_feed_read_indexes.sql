@@ -0,0 +1,15 @@
+-- Composite indexes for feed reads
+-- Feed pages read feed items in position order and join each article to its sources
+
+-- Feed items are paged by position within a feed (and per user for personalized feeds)
+CREATE INDEX IF NOT EXISTS idx_feed_items_feed_position ON feed_items(feed_id, position);
+CREATE INDEX IF NOT EXISTS idx_feed_items_feed_user_position ON feed_items(feed_id, user_id, position);
+
+-- Primary source and share context lookups start from the article
+CREATE INDEX IF NOT EXISTS idx_source_articles_article_source ON source_articles(article_id, source_id);
+
+-- Canonical URLs are unique (AutoMigrate creates the same index from the model)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_url ON articles(url);
+
+-- Feed regeneration and filtered feeds scan recent articles by quality
+CREATE INDEX IF NOT EXISTS idx_articles_created_quality ON articles(created_at, quality_score);
@@ -0,0 +1,9 @@
+-- Store profile details fetched by the source profile enrichment worker
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS follows_count INTEGER DEFAULT 0;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS posts_count INTEGER DEFAULT 0;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS labels TEXT[];
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS profile_refreshed_at TIMESTAMPTZ;
+
+-- The enrichment worker picks the sources refreshed longest ago
+CREATE INDEX IF NOT EXISTS idx_sources_profile_refreshed_at ON sources(profile_refreshed_at);
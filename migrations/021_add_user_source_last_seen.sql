@@ -0,0 +1,7 @@
+-- Track when each follow was last listed by a follow refresh, so unfollowed
+-- accounts can be removed once a grace period has passed
+
+ALTER TABLE user_sources ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+
+-- Existing follows start their grace period now rather than at creation
+UPDATE user_sources SET last_seen_at = NOW() WHERE last_seen_at IS NULL;
@@ -0,0 +1,4 @@
+-- Track Bluesky account status for sources, updated from Jetstream account events
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT TRUE;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS account_status VARCHAR(32);
@@ -0,0 +1,3 @@
+-- Let admins mark articles as not news, keeping them out of feeds and re-ingestion
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS is_not_news BOOLEAN DEFAULT FALSE;
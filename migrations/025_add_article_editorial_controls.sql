@@ -0,0 +1,19 @@
+-- Editorial controls for articles: pinning to the top of the global feed and a
+-- quality boost or penalty, with an audit trail of who changed them
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS is_pinned BOOLEAN DEFAULT FALSE;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMPTZ;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS editorial_boost DOUBLE PRECISION DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS article_audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    article_id UUID NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_audit_logs_article_id ON article_audit_logs(article_id);
+CREATE INDEX IF NOT EXISTS idx_article_audit_logs_created_at ON article_audit_logs(created_at);
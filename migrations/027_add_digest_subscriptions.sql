@@ -0,0 +1,3 @@
+-- Let users receive the top-stories digest as a direct message
+
+ALTER TABLE user_feed_preferences ADD COLUMN IF NOT EXISTS digest_subscribed BOOLEAN DEFAULT FALSE;
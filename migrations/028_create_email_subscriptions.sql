@@ -0,0 +1,17 @@
+-- Create email digest subscriptions
+-- Addresses receive the top stories daily or weekly once confirmed; the token
+-- authorizes the confirm and unsubscribe links
+
+CREATE TABLE IF NOT EXISTS email_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL UNIQUE,
+    frequency TEXT NOT NULL DEFAULT 'daily',
+    topics TEXT[],
+    token TEXT NOT NULL UNIQUE,
+    confirmed_at TIMESTAMPTZ,
+    last_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_subscriptions_due ON email_subscriptions(frequency, last_sent_at) WHERE confirmed_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Automatic source verification: the news domain a source was matched to, how it
+-- was matched, and the admin review status
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS verification_status TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS verification_method TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS verified_domain TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS verification_checked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sources_verification_status ON sources(verification_status);
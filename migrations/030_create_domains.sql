@@ -0,0 +1,22 @@
+-- Create domains table
+-- Reputation of news sites, replacing the publisher lists hardcoded in scoring.
+-- Articles are matched on their URL's host, including subdomains.
+
+CREATE TABLE IF NOT EXISTS domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    domain TEXT NOT NULL UNIQUE,
+    name TEXT,
+    score DOUBLE PRECISION NOT NULL,
+    category TEXT,
+    country TEXT,
+    is_blocked BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_category ON domains(category);
+CREATE INDEX IF NOT EXISTS idx_domains_country ON domains(country);
+
+-- Feed definitions can narrow their articles to a publisher category or country
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS domain_category TEXT;
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS country TEXT;
@@ -0,0 +1,8 @@
+-- Spam and engagement-bait flags on sources, with the admin review status
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS spam_status TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS spam_reasons TEXT[];
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS spam_flagged_at TIMESTAMPTZ;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS spam_reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sources_spam_status ON sources(spam_status);
@@ -0,0 +1,10 @@
+-- Moderation labels on shared posts, and a safe-mode option on feeds
+-- Self-labels and labeler labels are stored per share; shares labeled porn,
+-- sexual, nudity or graphic-media are marked sensitive.
+
+ALTER TABLE source_articles ADD COLUMN IF NOT EXISTS labels TEXT[];
+ALTER TABLE source_articles ADD COLUMN IF NOT EXISTS is_sensitive BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_source_articles_is_sensitive ON source_articles(is_sensitive);
+
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS safe_mode BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- Track when facts were extracted from an article
+-- Articles without facts_extracted_at are picked up by the extract_facts job;
+-- refetching an article with new text clears it.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS facts_extracted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_articles_facts_extracted_at ON articles(facts_extracted_at);
@@ -0,0 +1,60 @@
+-- Create the entity index
+-- People, organizations and places named in article facts, linked to the
+-- articles that name them. Entities already in article_facts are indexed here;
+-- new ones are indexed as facts are extracted.
+
+CREATE TABLE IF NOT EXISTS entities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    type TEXT NOT NULL,
+    article_count INTEGER DEFAULT 0,
+    first_seen_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entities_slug_type ON entities(slug, type);
+CREATE INDEX IF NOT EXISTS idx_entities_article_count ON entities(article_count);
+
+CREATE TABLE IF NOT EXISTS article_entities (
+    article_id UUID NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    confidence DOUBLE PRECISION DEFAULT 0.0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (article_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_entities_entity_id ON article_entities(entity_id);
+
+-- Index the entities of facts extracted before this migration
+WITH named AS (
+    SELECT article_facts.article_id,
+           trim(article_facts.fact_text) AS name,
+           lower(regexp_replace(trim(article_facts.fact_text), '[\s_-]+', '-', 'g')) AS slug,
+           article_facts.fact_type AS type,
+           article_facts.confidence,
+           COALESCE(articles.published_at, articles.created_at) AS seen_at
+    FROM article_facts
+    JOIN articles ON articles.id = article_facts.article_id
+    WHERE article_facts.fact_type IN ('person', 'organization', 'place')
+      AND trim(article_facts.fact_text) <> ''
+)
+INSERT INTO entities (name, slug, type, first_seen_at, last_seen_at)
+SELECT min(name), slug, type, min(seen_at), max(seen_at)
+FROM named
+GROUP BY slug, type
+ON CONFLICT (slug, type) DO NOTHING;
+
+INSERT INTO article_entities (article_id, entity_id, confidence)
+SELECT article_facts.article_id, entities.id, max(article_facts.confidence)
+FROM article_facts
+JOIN entities ON entities.type = article_facts.fact_type
+    AND entities.slug = lower(regexp_replace(trim(article_facts.fact_text), '[\s_-]+', '-', 'g'))
+GROUP BY article_facts.article_id, entities.id
+ON CONFLICT DO NOTHING;
+
+UPDATE entities SET article_count = (
+    SELECT COUNT(*) FROM article_entities WHERE article_entities.entity_id = entities.id
+);
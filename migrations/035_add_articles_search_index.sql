@@ -0,0 +1,6 @@
+-- Index the text search document of articles for related-article lookups
+-- The expression must match articleDocumentSQL in internal/services/related.go
+-- for the planner to use it.
+
+CREATE INDEX IF NOT EXISTS idx_articles_search ON articles
+    USING GIN (to_tsvector('english', coalesce(title, '') || ' ' || coalesce(description, '')));
@@ -0,0 +1,19 @@
+-- Store article embeddings for similarity search
+-- Requires the pgvector extension (https://github.com/pgvector/pgvector). Without
+-- it, skip this migration and leave EMBEDDINGS_PROVIDER unset.
+--
+-- The embedding column has no fixed size, so vectors of any provider fit.
+-- Searches compare the embeddings of the current provider's model.
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS article_embeddings (
+    article_id UUID PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    embedding vector NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_embeddings_model ON article_embeddings(model);
@@ -0,0 +1,9 @@
+-- Store article summaries
+-- Feeds show the summary in place of the description when it isn't empty.
+-- Articles without summarized_at are picked up by the summarize_articles job;
+-- refetching an article with new text clears it.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS summarized_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_articles_summarized_at ON articles(summarized_at);
@@ -0,0 +1,7 @@
+-- Store the reading level and tone of articles
+-- reading_grade is the Flesch-Kincaid grade level of the article text, and
+-- sentiment its tone from -1 (negative) to 1 (positive). Both are computed when
+-- the text is extracted; articles fetched earlier get them on their next refetch.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS reading_grade DOUBLE PRECISION DEFAULT 0;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS sentiment DOUBLE PRECISION DEFAULT 0;
@@ -0,0 +1,17 @@
+-- Learn users' topic preferences
+-- Clicks record the signed-in reader, and each user's topic weights are
+-- learned from their follows' shares and their clicks to boost personal feeds.
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS user_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_user_id ON clicks(user_id);
+
+CREATE TABLE IF NOT EXISTS user_topic_affinities (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic TEXT NOT NULL,
+    shares INTEGER DEFAULT 0,
+    clicks INTEGER DEFAULT 0,
+    weight DOUBLE PRECISION DEFAULT 0.0,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, topic)
+);
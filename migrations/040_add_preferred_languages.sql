@@ -0,0 +1,5 @@
+-- Add preferred languages to user feed preferences
+-- Personal feeds leave out articles in languages the user didn't choose;
+-- preferred_topics now lists the topics the user follows.
+
+ALTER TABLE user_feed_preferences ADD COLUMN IF NOT EXISTS preferred_languages TEXT[];
@@ -0,0 +1,14 @@
+-- Add diversity limits to feeds and feed definitions
+-- Caps the items per page from one source, one site and one story cluster;
+-- 0 is unlimited. The global feed starts with 3 per source and site and 2 per story.
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS max_per_source INTEGER DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS max_per_domain INTEGER DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS max_per_cluster INTEGER DEFAULT 0;
+
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS max_per_source INTEGER DEFAULT 0;
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS max_per_domain INTEGER DEFAULT 0;
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS max_per_cluster INTEGER DEFAULT 0;
+
+UPDATE feeds SET max_per_source = 3, max_per_domain = 3, max_per_cluster = 2
+WHERE feed_type = 'global' AND name = 'Top Stories';
@@ -0,0 +1,8 @@
+-- Add breaking news detection to articles
+-- Stories shared by many distinct sources within a short window are marked
+-- breaking and boosted in rankings for a few hours.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS breaking_at TIMESTAMPTZ;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS breaking_sources INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_articles_breaking_at ON articles(breaking_at);
@@ -0,0 +1,35 @@
+-- Add the data retention job's tables
+-- Feed items older than the retention period are moved to feed_items_archive,
+-- and each run of the job records how many rows it purged.
+
+CREATE TABLE IF NOT EXISTS feed_items_archive (
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL,
+    article_id UUID NOT NULL,
+    user_id UUID,
+    position INTEGER,
+    score DOUBLE PRECISION,
+    relevance DOUBLE PRECISION,
+    added_at TIMESTAMPTZ,
+    last_shown_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_items_archive_feed_id ON feed_items_archive(feed_id);
+CREATE INDEX IF NOT EXISTS idx_feed_items_archive_article_id ON feed_items_archive(article_id);
+CREATE INDEX IF NOT EXISTS idx_feed_items_archive_archived_at ON feed_items_archive(archived_at);
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    html_purged BIGINT DEFAULT 0,
+    articles_deleted BIGINT DEFAULT 0,
+    feed_items_archived BIGINT DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_started_at ON retention_runs(started_at);
+
+-- Find cached HTML and unreachable articles past their retention period
+CREATE INDEX IF NOT EXISTS idx_feed_items_added_at ON feed_items(added_at);
@@ -0,0 +1,78 @@
+-- Partition source_articles and impressions by month
+-- source_articles is partitioned on posted_at and impressions on created_at, so
+-- queries over a time window only scan the months they cover. The worker
+-- creates the partitions for the coming months (PartitionService); rows outside
+-- every monthly partition land in the table's _default partition.
+--
+-- Postgres requires the partition column in primary keys and unique indexes,
+-- so the primary keys become (id, <column>) and the unique index on shares
+-- becomes (post_uri, article_id, posted_at). A post's posted_at never changes,
+-- so a post still can't be stored twice for the same article.
+--
+-- The tables are copied, so run this during a quiet period.
+
+BEGIN;
+
+SET LOCAL timezone = 'UTC';
+
+ALTER TABLE source_articles RENAME TO source_articles_unpartitioned;
+ALTER TABLE impressions RENAME TO impressions_unpartitioned;
+
+UPDATE source_articles_unpartitioned SET posted_at = created_at WHERE posted_at IS NULL;
+UPDATE impressions_unpartitioned SET created_at = NOW() WHERE created_at IS NULL;
+
+CREATE TABLE source_articles (LIKE source_articles_unpartitioned INCLUDING DEFAULTS) PARTITION BY RANGE (posted_at);
+CREATE TABLE impressions (LIKE impressions_unpartitioned INCLUDING DEFAULTS) PARTITION BY RANGE (created_at);
+
+CREATE TABLE source_articles_default PARTITION OF source_articles DEFAULT;
+CREATE TABLE impressions_default PARTITION OF impressions DEFAULT;
+
+-- Monthly partitions from the oldest row (but not before Bluesky existed)
+-- through two months from now, named like source_articles_y2025m03
+DO $$
+DECLARE
+    parent TEXT;
+    first_month TIMESTAMPTZ;
+    month TIMESTAMPTZ;
+BEGIN
+    FOREACH parent IN ARRAY ARRAY['source_articles', 'impressions'] LOOP
+        IF parent = 'source_articles' THEN
+            SELECT MIN(posted_at) INTO first_month FROM source_articles_unpartitioned;
+        ELSE
+            SELECT MIN(created_at) INTO first_month FROM impressions_unpartitioned;
+        END IF;
+        first_month := date_trunc('month', GREATEST(COALESCE(first_month, NOW()), '2023-01-01'::TIMESTAMPTZ));
+
+        FOR month IN SELECT generate_series(first_month, date_trunc('month', NOW()) + INTERVAL '2 months', INTERVAL '1 month') LOOP
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                parent || '_' || to_char(month, '"y"YYYY"m"MM'), parent, month, month + INTERVAL '1 month');
+        END LOOP;
+    END LOOP;
+END $$;
+
+INSERT INTO source_articles SELECT * FROM source_articles_unpartitioned;
+INSERT INTO impressions SELECT * FROM impressions_unpartitioned;
+
+DROP TABLE source_articles_unpartitioned;
+DROP TABLE impressions_unpartitioned;
+
+-- Keys and indexes on the parent tables are created on every partition
+ALTER TABLE source_articles ADD PRIMARY KEY (id, posted_at);
+CREATE UNIQUE INDEX idx_source_articles_unique ON source_articles (post_uri, article_id, posted_at);
+CREATE INDEX idx_source_articles_source_id ON source_articles (source_id);
+CREATE INDEX idx_source_articles_article_id ON source_articles (article_id);
+CREATE INDEX idx_source_articles_article_source ON source_articles (article_id, source_id);
+CREATE INDEX idx_source_articles_is_sensitive ON source_articles (is_sensitive);
+CREATE INDEX idx_source_articles_posted_at ON source_articles (posted_at);
+ALTER TABLE source_articles ADD CONSTRAINT fk_sources_source_articles FOREIGN KEY (source_id) REFERENCES sources(id);
+ALTER TABLE source_articles ADD CONSTRAINT fk_articles_source_articles FOREIGN KEY (article_id) REFERENCES articles(id);
+
+ALTER TABLE impressions ADD PRIMARY KEY (id, created_at);
+CREATE INDEX idx_impressions_article_id ON impressions (article_id);
+CREATE INDEX idx_impressions_source_id ON impressions (source_id);
+CREATE INDEX idx_impressions_user_id ON impressions (user_id);
+CREATE INDEX idx_impressions_feed ON impressions (feed);
+CREATE INDEX idx_impressions_created_at ON impressions (created_at);
+CREATE INDEX idx_impressions_user_feed_created ON impressions (user_id, feed, created_at);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Make follows unique per user and source
+-- Follow imports running at the same time could both insert the same follow.
+-- Duplicates are removed, keeping the oldest, and a unique index lets imports
+-- insert with ON CONFLICT DO NOTHING instead of checking first. articles.url
+-- and the source_articles post index were already unique.
+
+DELETE FROM user_sources AS duplicate USING user_sources AS kept
+WHERE duplicate.user_id = kept.user_id AND duplicate.source_id = kept.source_id
+AND (duplicate.created_at, duplicate.id) > (kept.created_at, kept.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sources_user_source ON user_sources(user_id, source_id);
@@ -0,0 +1,5 @@
+-- Add a per-site NewsArticle schema requirement to domains
+-- Overrides NEWSARTICLE_SCHEMA for the site's pages: require, prefer or ignore.
+-- Empty uses the global setting.
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS schema_requirement VARCHAR(20) DEFAULT '';
@@ -0,0 +1,6 @@
+-- Add the schema.org article type to articles
+-- The highest weighted type in an article's JSON-LD (NewsArticle,
+-- ReportageNewsArticle, AnalysisNewsArticle, LiveBlogPosting, BlogPosting or
+-- Article), which adds to its content quality score.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS schema_type VARCHAR(50) DEFAULT '';
@@ -0,0 +1,7 @@
+-- Add the list of an article's authors
+-- JSON-LD author arrays and bylines such as "By Jane Roe and John Doe" are
+-- split into names; author keeps the whole byline for display.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS authors TEXT[];
+
+UPDATE articles SET authors = ARRAY[author] WHERE authors IS NULL AND author <> '';
@@ -0,0 +1,7 @@
+-- Add how far an article's publication date can be trusted
+-- "high" for JSON-LD datePublished, "medium" for article:published_time and
+-- Dublin Core meta tags or a <time> marked as the publication date, and
+-- "low" for other <time> elements and the Last-Modified header. Empty for
+-- articles fetched before it was recorded.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS date_confidence VARCHAR(10) DEFAULT '';
@@ -0,0 +1,12 @@
+-- Store articles from news sites' RSS and Atom feeds and sitemaps before
+-- anyone shares them, for sites an admin opts in and a verified source speaks
+-- for. feed_urls lists the feeds to poll; when empty, those the site's home
+-- page advertises are used. Articles stored this way are unshared, and kept
+-- out of feeds, until a share of them arrives.
+
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS ingest_feeds BOOLEAN DEFAULT FALSE;
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS feed_urls TEXT[];
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS feeds_polled_at TIMESTAMPTZ;
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS is_unshared BOOLEAN DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_domains_ingest_feeds ON domains(ingest_feeds) WHERE ingest_feeds = TRUE;
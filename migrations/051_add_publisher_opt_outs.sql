@@ -0,0 +1,21 @@
+-- Publishers' requests to keep their sites out of open.news. Requests made
+-- through the API are pending until the token is found on the site's home page
+-- or in its DNS TXT records; admins add active opt-outs directly.
+
+CREATE TABLE IF NOT EXISTS publisher_opt_outs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    domain VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL,
+    contact TEXT,
+    reason TEXT,
+    token VARCHAR(64),
+    verified_by VARCHAR(20),
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_publisher_opt_outs_status ON publisher_opt_outs(status);
+
+-- Pages that asked with noarchive or noai for their content not to be kept
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS no_archive BOOLEAN DEFAULT FALSE;
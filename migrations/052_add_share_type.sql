@@ -0,0 +1,13 @@
+-- How a share links to its article: an original post, a quote of another post,
+-- a repost or a reply. Stored shares are classified from what was recorded;
+-- replies weren't told apart before, so they stay posts.
+
+ALTER TABLE source_articles ADD COLUMN IF NOT EXISTS share_type VARCHAR(10) DEFAULT 'post';
+
+UPDATE source_articles SET share_type = CASE
+    WHEN post_uri LIKE '%/app.bsky.feed.repost/%' THEN 'repost'
+    WHEN original_uri <> '' THEN 'quote'
+    ELSE 'post'
+END;
+
+UPDATE source_articles SET is_repost = (share_type = 'repost');
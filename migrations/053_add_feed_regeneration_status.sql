@@ -0,0 +1,6 @@
+-- When each stored feed was last regenerated and why the last failed
+-- regeneration failed, for the admin feed health report
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS regenerated_at TIMESTAMPTZ;
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS last_error_at TIMESTAMPTZ;
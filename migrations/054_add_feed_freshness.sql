@@ -0,0 +1,6 @@
+-- How fresh the articles a precomputed feed is regenerated with are: a maximum
+-- age (0 keeps the 7-day default) and whether articles on a story newer
+-- coverage replaced are left out
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS max_age_hours INTEGER DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS prune_superseded BOOLEAN DEFAULT TRUE;
@@ -0,0 +1,8 @@
+-- Flags set when a source's or article's engagement or shares change, so the
+-- quality score worker only rescores what changed between full sweeps
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS needs_rescore BOOLEAN DEFAULT FALSE;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS needs_rescore BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_articles_needs_rescore ON articles (id) WHERE needs_rescore;
+CREATE INDEX IF NOT EXISTS idx_sources_needs_rescore ON sources (id) WHERE needs_rescore;
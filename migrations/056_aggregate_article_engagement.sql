@@ -0,0 +1,7 @@
+-- Roll the engagement of stored shares up into their articles, which are kept
+-- current as shares arrive from now on
+
+UPDATE articles SET
+	shares_count = (SELECT COUNT(DISTINCT source_articles.source_id) FROM source_articles WHERE source_articles.article_id = articles.id),
+	likes_count = (SELECT COALESCE(SUM(source_articles.likes_count), 0) FROM source_articles WHERE source_articles.article_id = articles.id),
+	reposts_count = (SELECT COALESCE(SUM(source_articles.reposts_count), 0) FROM source_articles WHERE source_articles.article_id = articles.id);
@@ -0,0 +1,15 @@
+-- Snapshots of recent articles' scores, recorded after each quality score
+-- update, and how many the retention job deleted
+
+CREATE TABLE IF NOT EXISTS score_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    article_id UUID NOT NULL,
+    quality_score DOUBLE PRECISION,
+    trending_score DOUBLE PRECISION,
+    recorded_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_history_article_recorded ON score_history(article_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_score_history_recorded_at ON score_history(recorded_at);
+
+ALTER TABLE retention_runs ADD COLUMN IF NOT EXISTS score_history_purged BIGINT DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Archives of a feed user's data, built by a worker when they request an export
+
+CREATE TABLE IF NOT EXISTS user_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    data TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_exports_user_id ON user_exports(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_exports_expires_at ON user_exports(expires_at);
@@ -0,0 +1,7 @@
+-- What personalized feeds serve requests without a valid token; the default
+-- personal feed serves the global feed instead of refusing them
+
+ALTER TABLE feed_definitions ADD COLUMN IF NOT EXISTS anonymous_fallback VARCHAR(20) DEFAULT '';
+
+UPDATE feed_definitions SET anonymous_fallback = 'global'
+WHERE rkey = 'open-news-personal' AND builder = 'personalized' AND owner_id IS NULL AND anonymous_fallback = '';
@@ -34,9 +34,15 @@ FEED_GENERATOR_DID="did:web:$DOMAIN"
 GLOBAL_FEED_URI="at://$FEED_GENERATOR_DID/app.bsky.feed.generator/open-news-global"
 PERSONAL_FEED_URI="at://$FEED_GENERATOR_DID/app.bsky.feed.generator/open-news-personal"
 
+# Topic feeds (one per topic in internal/topics)
+TOPIC_FEEDS="tech science climate health politics business sports"
+
 echo "🔗 Feed URIs to register:"
 echo "   Global: $GLOBAL_FEED_URI"
 echo "   Personal: $PERSONAL_FEED_URI"
+for topic in $TOPIC_FEEDS; do
+    echo "   Topic ($topic): at://$FEED_GENERATOR_DID/app.bsky.feed.generator/open-news-$topic"
+done
 echo ""
 
 # Verify feeds are accessible
@@ -76,6 +82,16 @@ else
     exit 1
 fi
 
+for topic in $TOPIC_FEEDS; do
+    TOPIC_FEED_URI="at://$FEED_GENERATOR_DID/app.bsky.feed.generator/open-news-$topic"
+    if curl -f -s "https://$DOMAIN/xrpc/app.bsky.feed.describeFeedGenerator?feed=$TOPIC_FEED_URI" > /dev/null; then
+        echo "✅ Topic feed endpoint working: open-news-$topic"
+    else
+        echo "❌ Topic feed endpoint not responding: open-news-$topic"
+        exit 1
+    fi
+done
+
 echo ""
 echo "🎯 Feed Registration Methods"
 echo "==========================="
@@ -109,6 +125,19 @@ echo '       "description": "Top stories from across the Bluesky network, ranked
 echo '       "createdAt": "'$(date -u +%Y-%m-%dT%H:%M:%S.000Z)'"'
 echo "     }'"
 echo ""
+echo "   # Topic Feeds (repeat for each topic: $TOPIC_FEEDS)"
+echo "   at-cli put --pds https://bsky.social \\"
+echo "     --repo \$BLUESKY_HANDLE \\"
+echo "     --collection app.bsky.feed.generator \\"
+echo "     --rkey open-news-tech \\"
+echo "     --record '{"
+echo '       "$type": "app.bsky.feed.generator",'
+echo '       "did": "'$FEED_GENERATOR_DID'",'
+echo '       "displayName": "Open News - Technology",'
+echo '       "description": "Top technology stories shared across the Bluesky network, ranked by engagement and quality.",'
+echo '       "createdAt": "'$(date -u +%Y-%m-%dT%H:%M:%S.000Z)'"'
+echo "     }'"
+echo ""
 
 echo "Method 3: Manual Testing"
 echo "------------------------"
@@ -20,7 +20,7 @@ createdb open_news_test 2>/dev/null || echo "Test database already exists"
 export ENV_FILE=.env.test.integration
 
 echo "Running database migrations on test database..."
-go run cmd/migrate.go
+go run ./cmd/opennews migrate
 
 echo "Integration test environment ready!"
 echo "To run integration tests: make test-integration"
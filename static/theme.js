@@ -0,0 +1,47 @@
+/*
+ * open.news theme switcher
+ *
+ * Applies the saved theme before first paint and wires up any
+ * [data-theme-toggle] button to cycle light -> dark -> auto.
+ * The choice is stored in a cookie so server-rendered pages match.
+ */
+(function () {
+    'use strict';
+
+    var THEMES = ['light', 'dark', 'auto'];
+    var LABELS = { light: '☀️ Light', dark: '🌙 Dark', auto: '🌓 Auto' };
+
+    function savedTheme() {
+        var match = document.cookie.match(/(?:^|;\s*)theme=(light|dark|auto)/);
+        return match ? match[1] : null;
+    }
+
+    function applyTheme(theme) {
+        document.documentElement.setAttribute('data-theme', theme);
+        var toggles = document.querySelectorAll('[data-theme-toggle]');
+        for (var i = 0; i < toggles.length; i++) {
+            toggles[i].textContent = LABELS[theme];
+            toggles[i].setAttribute('title', 'Theme: ' + theme);
+        }
+    }
+
+    function saveTheme(theme) {
+        document.cookie = 'theme=' + theme + '; path=/; max-age=31536000; SameSite=Lax';
+    }
+
+    var current = savedTheme() || document.documentElement.getAttribute('data-theme') || 'auto';
+    document.documentElement.setAttribute('data-theme', current);
+
+    document.addEventListener('DOMContentLoaded', function () {
+        applyTheme(current);
+        document.addEventListener('click', function (event) {
+            var toggle = event.target.closest && event.target.closest('[data-theme-toggle]');
+            if (!toggle) {
+                return;
+            }
+            current = THEMES[(THEMES.indexOf(current) + 1) % THEMES.length];
+            saveTheme(current);
+            applyTheme(current);
+        });
+    });
+})();
@@ -0,0 +1,183 @@
+/*
+ * open.news embeddable widget
+ *
+ * Usage:
+ *   <div data-open-news-widget data-limit="5" data-theme="light"></div>
+ *   <script src="https://opennews.social/static/widget.js" async></script>
+ *
+ * Options (data attributes):
+ *   data-feed      Feed to show (currently "global")
+ *   data-limit     Number of stories, 1-50 (default 5)
+ *   data-theme     "light", "dark" or "auto" (default "auto")
+ *   data-refresh   Poll interval in seconds, 0 disables (default 300, minimum 30)
+ *   data-key       Optional API key for third-party embedders
+ */
+(function () {
+    'use strict';
+
+    var script = document.currentScript;
+    var baseURL = script ? new URL(script.src).origin : window.location.origin;
+
+    var STYLE_ID = 'open-news-widget-styles';
+    var CSS = [
+        '.onw{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;border-radius:8px;padding:12px;border:1px solid var(--onw-border);background:var(--onw-bg);color:var(--onw-text)}',
+        '.onw{--onw-bg:#fff;--onw-text:#1a1a1a;--onw-muted:#666;--onw-border:#e5e5e5;--onw-link:#0066cc}',
+        '.onw[data-theme="dark"]{--onw-bg:#1a1a1a;--onw-text:#f0f0f0;--onw-muted:#999;--onw-border:#333;--onw-link:#4da3ff}',
+        '@media (prefers-color-scheme:dark){.onw[data-theme="auto"]{--onw-bg:#1a1a1a;--onw-text:#f0f0f0;--onw-muted:#999;--onw-border:#333;--onw-link:#4da3ff}}',
+        '.onw-header{font-weight:600;font-size:14px;margin-bottom:8px}',
+        '.onw-list{list-style:none;margin:0;padding:0}',
+        '.onw-item{padding:8px 0;border-top:1px solid var(--onw-border)}',
+        '.onw-item:first-child{border-top:none}',
+        '.onw-title{color:var(--onw-link);text-decoration:none;font-size:14px;line-height:1.35}',
+        '.onw-title:hover{text-decoration:underline}',
+        '.onw-meta{color:var(--onw-muted);font-size:12px;margin-top:2px}',
+        '.onw-footer{font-size:11px;margin-top:8px;color:var(--onw-muted)}',
+        '.onw-footer a{color:var(--onw-muted)}'
+    ].join('\n');
+
+    function injectStyles() {
+        if (document.getElementById(STYLE_ID)) {
+            return;
+        }
+        var style = document.createElement('style');
+        style.id = STYLE_ID;
+        style.textContent = CSS;
+        document.head.appendChild(style);
+    }
+
+    function el(tag, className, text) {
+        var node = document.createElement(tag);
+        if (className) {
+            node.className = className;
+        }
+        if (text) {
+            node.textContent = text;
+        }
+        return node;
+    }
+
+    function relativeTime(iso) {
+        if (!iso) {
+            return '';
+        }
+        var seconds = Math.floor((Date.now() - new Date(iso).getTime()) / 1000);
+        if (seconds < 3600) {
+            return Math.max(1, Math.floor(seconds / 60)) + 'm ago';
+        }
+        if (seconds < 86400) {
+            return Math.floor(seconds / 3600) + 'h ago';
+        }
+        return Math.floor(seconds / 86400) + 'd ago';
+    }
+
+    function render(container, data) {
+        var list = el('ul', 'onw-list');
+        data.items.forEach(function (item) {
+            var li = el('li', 'onw-item');
+
+            var link = el('a', 'onw-title', item.title || item.url);
+            link.href = item.click_url ? baseURL + item.click_url : item.url;
+            link.target = '_blank';
+            link.rel = 'noopener';
+            li.appendChild(link);
+
+            var meta = [];
+            if (item.site_name) {
+                meta.push(item.site_name);
+            }
+            if (item.source && item.source.handle) {
+                meta.push('@' + item.source.handle);
+            }
+            if (item.published_at) {
+                meta.push(relativeTime(item.published_at));
+            }
+            li.appendChild(el('div', 'onw-meta', meta.join(' · ')));
+
+            list.appendChild(li);
+        });
+
+        var footer = el('div', 'onw-footer');
+        var credit = el('a', '', 'open.news');
+        credit.href = baseURL + '/feeds';
+        credit.target = '_blank';
+        credit.rel = 'noopener';
+        footer.appendChild(document.createTextNode('Powered by '));
+        footer.appendChild(credit);
+
+        container.textContent = '';
+        container.appendChild(el('div', 'onw-header', 'Top Stories'));
+        container.appendChild(list);
+        container.appendChild(footer);
+    }
+
+    function mount(container) {
+        var feed = container.getAttribute('data-feed') || 'global';
+        var limit = parseInt(container.getAttribute('data-limit'), 10) || 5;
+        var refresh = parseInt(container.getAttribute('data-refresh'), 10);
+        var key = container.getAttribute('data-key');
+        var version = '';
+
+        if (isNaN(refresh)) {
+            refresh = 300;
+        }
+
+        container.classList.add('onw');
+        container.setAttribute('data-theme', container.getAttribute('data-theme') || 'auto');
+        container.textContent = 'Loading…';
+
+        function load() {
+            var url = baseURL + '/api/widget/' + encodeURIComponent(feed) + '?limit=' + limit;
+            if (version) {
+                url += '&version=' + encodeURIComponent(version);
+            }
+            if (key) {
+                url += '&key=' + encodeURIComponent(key);
+            }
+
+            fetch(url)
+                .then(function (response) {
+                    if (!response.ok) {
+                        throw new Error('HTTP ' + response.status);
+                    }
+                    return response.json();
+                })
+                .then(function (data) {
+                    if (data.unchanged) {
+                        return;
+                    }
+                    version = data.version;
+                    render(container, data);
+                })
+                .catch(function (err) {
+                    if (!version) {
+                        container.textContent = 'Unable to load stories.';
+                    }
+                    if (window.console) {
+                        console.warn('open.news widget:', err);
+                    }
+                });
+        }
+
+        load();
+        if (refresh > 0) {
+            setInterval(load, Math.max(refresh, 30) * 1000);
+        }
+    }
+
+    function init() {
+        injectStyles();
+        var containers = document.querySelectorAll('[data-open-news-widget]');
+        for (var i = 0; i < containers.length; i++) {
+            if (!containers[i].getAttribute('data-open-news-mounted')) {
+                containers[i].setAttribute('data-open-news-mounted', 'true');
+                mount(containers[i]);
+            }
+        }
+    }
+
+    if (document.readyState === 'loading') {
+        document.addEventListener('DOMContentLoaded', init);
+    } else {
+        init();
+    }
+})();
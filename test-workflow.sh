@@ -19,13 +19,13 @@ psql postgresql://mterenzi:@localhost:5432/open_news -c "
 echo ""
 echo "2. This workflow would:"
 echo "   a) ./dev reset        # Clear all data"
-echo "   b) go run cmd/seed.go -handle your.handle.bsky.social"
+echo "   b) go run ./cmd/opennews seed -handle your.handle.bsky.social"
 echo "      # Create user + import real follows"
 echo ""
 
 echo "✅ Development workflow ready!"
 echo ""
 echo "💡 Usage examples:"
-echo "  ./dev reset && go run cmd/seed.go -handle librenews.bsky.social"
-echo "  ./dev reset && go run cmd/seed.go -handle some.other.handle"
-echo "  ./dev reset && go run cmd/seed.go -handle test.user  # Uses mock data"
+echo "  ./dev reset && go run ./cmd/opennews seed -handle librenews.bsky.social"
+echo "  ./dev reset && go run ./cmd/opennews seed -handle some.other.handle"
+echo "  ./dev reset && go run ./cmd/opennews seed -handle test.user  # Uses mock data"